@@ -71,6 +71,7 @@ type params struct {
 	logLevel       zapcore.Level
 	nIntroductions int
 	nPuts          int
+	nSearches      int
 	nUploads       int
 }
 
@@ -259,7 +260,12 @@ func writeBenchmarkResults(t *testing.T, benchmarks []*benchmarkObs) {
 		errors.MaybePanic(err)
 	}()
 	assert.Nil(t, err)
-	maxNameLen := len("Introduce")
+	maxNameLen := 0
+	for _, benchmark := range benchmarks {
+		if nameLen := len(benchmarkName(benchmark.name, benchmark.procs)); nameLen > maxNameLen {
+			maxNameLen = nameLen
+		}
+	}
 
 	for _, benchmark := range benchmarks {
 		name := benchmarkName(benchmark.name, benchmark.procs)
@@ -349,6 +355,10 @@ func newConfig(
 	subscribeToParams := subscribe.NewDefaultToParameters()
 	subscribeToParams.FPRate = 0.9
 
+	// all librarians share this process's memory
+	hotCacheParams := server.NewDefaultHotCacheParameters()
+	hotCacheParams.MaxBytes = 4 * 1024 * 1024
+
 	localAddr, err := server.ParseAddr("localhost", port)
 	errors.MaybePanic(err) // should never happen
 	peerDataDir := filepath.Join(dataDir, server.NameFromAddr(localAddr))
@@ -367,7 +377,8 @@ func newConfig(
 		WithRouting(rtParams).
 		WithIntroduce(introParams).
 		WithSearch(searchParams).
-		WithSubscribeTo(subscribeToParams)
+		WithSubscribeTo(subscribeToParams).
+		WithHotCache(hotCacheParams)
 }
 
 func newTestDocument(rng *rand.Rand, entrySize int) (*api.Document, id.ID) {
@@ -27,9 +27,11 @@ const (
 	putPageSize = 1024 * 1024
 
 	// for benchmark naming
-	introduceName = "Introduce"
-	putName       = "Put"
-	getName       = "Get"
+	introduceName    = "Introduce"
+	putName          = "Put"
+	getName          = "Get"
+	searchName       = "Search"
+	serialSearchName = "SearchSerial"
 )
 
 var grpcLogNoise = []string{
@@ -62,6 +64,7 @@ func TestLibrarianCluster(t *testing.T) {
 		logLevel:       zapcore.InfoLevel,
 		nIntroductions: 32,
 		nPuts:          128,
+		nSearches:      32,
 		nUploads:       16,
 	}
 	state := setUp(params)
@@ -79,6 +82,9 @@ func TestLibrarianCluster(t *testing.T) {
 	// get that same data from random peers
 	testGet(t, params, state)
 
+	// search for that same data from the test client
+	testSearch(t, params, state)
+
 	// upload a bunch of random documents
 	testUpload(t, params, state)
 	//checkPublications(t, params, state)  // TODO (drausin) figure out why can be flakey
@@ -152,7 +158,7 @@ func testPut(t *testing.T, params *params, state *state) {
 	librarians, err := client.NewUniformBalancer(librarianAddrs)
 	assert.Nil(t, err)
 	putters := client.NewUniformPutterBalancer(librarians)
-	rlc := lclient.NewRetryPutter(putters, store.DefaultQueryTimeout)
+	rlc := lclient.NewRetryPutter(putters, state.client.signer, store.DefaultQueryTimeout)
 
 	// create a bunch of random putDocs to put
 	for c := 0; c < params.nPuts; c++ {
@@ -201,7 +207,7 @@ func testGet(t *testing.T, params *params, state *state) {
 	librarians, err := client.NewUniformBalancer(librarianAddrs)
 	assert.Nil(t, err)
 	getters := client.NewUniformGetterBalancer(librarians)
-	rlc := lclient.NewRetryGetter(getters, search.DefaultQueryTimeout)
+	rlc := lclient.NewRetryGetter(getters, state.client.signer, search.DefaultQueryTimeout)
 
 	// create a bunch of random values to put
 	for c := 0; c < len(state.putDocs); c++ {
@@ -239,6 +245,89 @@ func testGet(t *testing.T, params *params, state *state) {
 	})
 }
 
+func testSearch(t *testing.T, params *params, state *state) {
+	seeds := introduceSeeds(t, state)
+	searcher := search.NewDefaultSearcher(state.client.signer)
+
+	// the default lookup, with parallel queries and hedged stragglers
+	hedgedParams := search.NewDefaultParameters()
+
+	// the lookup with just one query at a time and no hedging
+	serialParams := search.NewDefaultParameters()
+	serialParams.Concurrency, serialParams.HedgePercentile = 1, 0
+
+	nSearches := params.nSearches
+	if nSearches > len(state.putDocs) {
+		nSearches = len(state.putDocs)
+	}
+	names := []string{searchName, serialSearchName}
+	allParams := []*search.Parameters{hedgedParams, serialParams}
+	benchResults := [][]testing.BenchmarkResult{
+		make([]testing.BenchmarkResult, nSearches),
+		make([]testing.BenchmarkResult, nSearches),
+	}
+	for c := 0; c < nSearches; c++ {
+		key, err := api.GetKey(state.putDocs[c])
+		assert.Nil(t, err)
+
+		// alternate which lookup goes first so neither always gets the peers & connections
+		// warmed up by the other
+		order := []int{0, 1}
+		if c%2 == 1 {
+			order = []int{1, 0}
+		}
+		for _, i := range order {
+			start := time.Now()
+			s := search.NewSearch(state.client.selfID, key, allParams[i])
+			err = searcher.Search(s, seeds)
+			benchResults[i][c] = testing.BenchmarkResult{
+				N: 1,
+				T: time.Now().Sub(start),
+			}
+
+			// check everything went fine
+			assert.Nil(t, err)
+			assert.True(t, s.FoundValue())
+			state.client.logger.Debug("finished search",
+				zap.String("benchmark", names[i]),
+				zap.String("key", key.String()),
+				zap.Bool("first", i == order[0]),
+				zap.Int("n_responded", len(s.Result.Responded)),
+			)
+		}
+	}
+
+	for i, name := range names {
+		state.benchResults = append(state.benchResults, &benchmarkObs{
+			name:    name,
+			procs:   runtime.NumCPU(),
+			results: benchResults[i],
+		})
+	}
+}
+
+// introduceSeeds introduces the test client to a random peer and returns the peers it learns of.
+func introduceSeeds(t *testing.T, state *state) []peer.Peer {
+	i := state.rng.Int31n(int32(len(state.peers)))
+	conn := peer.NewConnector(state.peerConfigs[i].PublicAddr)
+	rq := lclient.NewIntroduceRequest(state.client.selfID, state.client.selfAPI, 8)
+	ctx, cancel, err := lclient.NewSignedTimeoutContext(state.client.signer, rq,
+		search.DefaultQueryTimeout)
+	assert.Nil(t, err)
+	introducer, err := lclient.NewIntroducerCreator().Create(conn)
+	assert.Nil(t, err)
+	rp, err := introducer.Introduce(ctx, rq)
+	cancel()
+	assert.Nil(t, err)
+
+	fromer := peer.NewFromer()
+	seeds := []peer.Peer{fromer.FromAPI(rp.Self)}
+	for _, pa := range rp.Peers {
+		seeds = append(seeds, fromer.FromAPI(pa))
+	}
+	return seeds
+}
+
 func testUpload(t *testing.T, params *params, state *state) {
 
 	contents := make([][]byte, params.nUploads)
@@ -256,7 +345,8 @@ func testUpload(t *testing.T, params *params, state *state) {
 		}
 
 		// upload the contents
-		_, envKeys[i], err = state.authors[0].Upload(bytes.NewReader(contents[i]), mediaType)
+		_, envKeys[i], _, err = state.authors[0].Upload(bytes.NewReader(contents[i]), mediaType,
+			nil)
 		assert.Nil(t, err)
 	}
 	state.uploadedDocContents = contents
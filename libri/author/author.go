@@ -1,10 +1,12 @@
 package author
 
 import (
+	"bytes"
 	"crypto/ecdsa"
 	"io"
 	"time"
 
+	"github.com/drausin/libri/libri/author/group"
 	"github.com/drausin/libri/libri/author/io/enc"
 	"github.com/drausin/libri/libri/author/io/pack"
 	"github.com/drausin/libri/libri/author/io/page"
@@ -24,13 +26,17 @@ import (
 
 var (
 	healthcheckTimeout = 2 * time.Second
+
+	// number of librarians to get a pointer from when resolving it, so an out-of-date replica
+	// returned by one of them can't roll the pointer back
+	nResolveResponses = 3
 )
 
 // Author is the main client of the libri network. It can upload, download, and share documents with
 // other author clients.
 type Author struct {
 	// selfID is ID of this author client
-	clientID ecid.ID
+	clientID ecid.Identity
 
 	// Config holds the configuration parameters of the server
 	config *Config
@@ -58,6 +64,9 @@ type Author struct {
 	// SLD for locally stored documents
 	documentSLD storage.DocumentSLD
 
+	// SL for reader groups
+	groups group.StorerLoader
+
 	// load balancer for librarian clients
 	librarians client.Balancer
 
@@ -74,6 +83,17 @@ type Author struct {
 
 	receiver ship.Receiver
 
+	// load balancers for librarian Put and Get clients
+	putters client.PutterBalancer
+	getters client.GetterBalancer
+
+	// publishes and acquires single documents (e.g., pointers) to and from libri
+	publisher publish.Publisher
+	acquirer  publish.Acquirer
+
+	// acquires multiple documents (e.g., pages) from libri, storing them locally
+	msAcquirer publish.MultiStoreAcquirer
+
 	// stores Pages in chan to local storage
 	pageSL page.StorerLoader
 
@@ -105,7 +125,7 @@ func NewAuthor(
 	documentSL := storage.NewDocumentSLD(rdb)
 
 	// get client ID and immediately save it so subsequent restarts have it
-	clientID, err := loadOrCreateClientID(logger, clientSL)
+	clientID, err := loadOrCreateClientID(logger, clientSL, config.KeyType)
 	if err != nil {
 		return nil, err
 	}
@@ -126,19 +146,20 @@ func NewAuthor(
 	if err != nil {
 		return nil, err
 	}
-	signer := client.NewSigner(clientID.Key())
+	signer := client.NewFormatSigner(clientID.Signer(), config.SignatureFormat)
 
 	publisher := publish.NewPublisher(clientID, signer, config.Publish)
 	acquirer := publish.NewAcquirer(clientID, signer, config.Publish)
 	slPublisher := publish.NewSingleLoadPublisher(publisher, documentSL)
 	ssAcquirer := publish.NewSingleStoreAcquirer(acquirer, documentSL)
-	mlPublisher := publish.NewMultiLoadPublisher(slPublisher, config.Publish)
-	msAcquirer := publish.NewMultiStoreAcquirer(ssAcquirer, config.Publish)
-	shipper := ship.NewShipper(putters, publisher, mlPublisher)
-	receiver := ship.NewReceiver(getters, allKeys, acquirer, msAcquirer, documentSL)
+	mlPublisher := publish.NewMultiLoadPublisher(slPublisher, signer, config.Publish)
+	msAcquirer := publish.NewMultiStoreAcquirer(ssAcquirer, signer, config.Publish)
+	shipper := ship.NewShipper(putters, publisher, mlPublisher, config.Publish)
+	receiver := ship.NewReceiver(getters, allKeys, acquirer, msAcquirer, documentSL,
+		config.AllowUnsignedEntries)
 
 	mdEncDec := enc.NewMetadataEncrypterDecrypter()
-	entryPacker := pack.NewEntryPacker(config.Print, mdEncDec, documentSL)
+	entryPacker := pack.NewEntryPacker(config.Print, mdEncDec, authorKeys, documentSL)
 	entryUnpacker := pack.NewEntryUnpacker(config.Print, mdEncDec, documentSL)
 
 	author := &Author{
@@ -151,12 +172,18 @@ func NewAuthor(
 		db:               rdb,
 		clientSL:         clientSL,
 		documentSLD:      documentSL,
+		groups:           group.NewStorerLoader(clientSL),
 		librarians:       librarians,
 		librarianHealths: librarianHealths,
 		entryPacker:      entryPacker,
 		entryUnpacker:    entryUnpacker,
 		shipper:          shipper,
 		receiver:         receiver,
+		putters:          putters,
+		getters:          getters,
+		publisher:        publisher,
+		acquirer:         acquirer,
+		msAcquirer:       msAcquirer,
 		pageSL:           page.NewStorerLoader(documentSL),
 		signer:           signer,
 		logger:           clientLogger,
@@ -204,31 +231,48 @@ func (a *Author) Healthcheck() (bool, map[string]healthpb.HealthCheckResponse_Se
 }
 
 // Upload compresses, encrypts, and splits the content into pages and then stores them in the
-// libri network. It returns the uploaded envelope for self-storage and its key.
-func (a *Author) Upload(content io.Reader, mediaType string) (*api.Document, id.ID, error) {
+// libri network. Besides the envelope for self-storage, it also creates an envelope for each of
+// the (optional) readers. It returns the uploaded envelope for self-storage, its key, and the keys
+// of the envelopes shared with the readers.
+func (a *Author) Upload(content io.Reader, mediaType string, readers *Readers) (
+	*api.Document, id.ID, []id.ID, error) {
 	startTime := time.Now()
 	a.logger.Debug("uploading document")
 
 	authorPub, readerPub, kek, eek, err := a.envKeys.sample()
 	if err != nil {
-		return nil, nil, a.logAndReturnErr("error sampling keys", err)
+		return nil, nil, nil, a.logAndReturnErr("error sampling keys", err)
 	}
 
 	a.logger.Debug("packing content", packingContentFields(authorPub)...)
 	entry, metadata, err := a.entryPacker.Pack(content, mediaType, eek, authorPub)
 	if err != nil {
-		return nil, nil, a.logAndReturnErr("error packing content", err)
+		return nil, nil, nil, a.logAndReturnErr("error packing content", err)
 	}
 
 	a.logger.Debug("shipping entry", shippingEntryFields(authorPub, readerPub)...)
 	env, envKey, err := a.shipper.ShipEntry(entry, authorPub, readerPub, kek, eek)
 	if err != nil {
-		return nil, nil, a.logAndReturnErr("error shipping entry", err)
+		return nil, nil, nil, a.logAndReturnErr("error shipping entry", err)
+	}
+
+	var sharedEnvKeys []id.ID
+	if readers != nil {
+		authorKey, in := a.authorKeys.Get(authorPub)
+		if !in {
+			return nil, nil, nil, a.logAndReturnErr("error getting author key",
+				keychain.ErrUnexpectedMissingKey)
+		}
+		entryKey := id.FromBytes(env.Contents.(*api.Document_Envelope).Envelope.EntryKey)
+		_, sharedEnvKeys, err = a.shipToReaders(authorKey, eek, entryKey, readers)
+		if err != nil {
+			return nil, nil, nil, a.logAndReturnErr("error sharing with readers", err)
+		}
 	}
 
 	elapsedTime := time.Since(startTime)
 	a.logger.Info("uploaded document", uploadedDocFields(envKey, env, metadata, elapsedTime)...)
-	return env, envKey, nil
+	return env, envKey, sharedEnvKeys, nil
 }
 
 // Download downloads, join, decrypts, and decompressed the content, writing it to a unified output
@@ -285,6 +329,9 @@ func (a *Author) Share(envKey id.ID, readerPub *ecdsa.PublicKey) (*api.Document,
 	if err != nil {
 		return nil, nil, a.logAndReturnErr("error shipping envelope", err)
 	}
+	if err := a.saveSharedEnvelopeKey(entryKey, readKeyBs, sharedEnvKey); err != nil {
+		return nil, nil, a.logAndReturnErr("error saving shared envelope key", err)
+	}
 
 	a.logger.Info("successfully shared document",
 		sharedDocFields(envKey, entryKey, authKeyBs, readKeyBs)...,
@@ -292,6 +339,181 @@ func (a *Author) Share(envKey id.ID, readerPub *ecdsa.PublicKey) (*api.Document,
 	return sharedEnv, sharedEnvKey, nil
 }
 
+// Rekey revokes access to the entry of the envelope from all readers except the author and
+// keepReaders. It downloads and re-encrypts the entry under a fresh EEK and then uploads the new
+// pages and entry along with new envelopes for the author and keepReaders. Revoked readers can
+// still decrypt the old entry but not the new one. It returns the new envelope keys (with the
+// author's own first) and a mapping from the (string) old envelope keys to the new ones for the
+// author and those keepReaders the entry was previously shared with.
+func (a *Author) Rekey(envKey id.ID, keepReaders *Readers) ([]id.ID, map[string]id.ID, error) {
+	startTime := time.Now()
+	a.logger.Debug("rekeying document", downloadingDocFields(envKey)...)
+	env, err := a.receiver.ReceiveEnvelope(envKey)
+	if err != nil {
+		return nil, nil, a.logAndReturnErr("error receiving envelope", err)
+	}
+	if _, in := a.authorKeys.Get(env.AuthorPublicKey); !in {
+		// can only rekey entries we authored
+		return nil, nil, a.logAndReturnErr("error getting author key",
+			keychain.ErrUnexpectedMissingKey)
+	}
+	entry, oldEEK, err := a.receiver.ReceiveEntry(envKey)
+	if err != nil {
+		return nil, nil, a.logAndReturnErr("error receiving entry", err)
+	}
+	content := new(bytes.Buffer)
+	metadata, err := a.entryUnpacker.Unpack(content, entry, oldEEK)
+	if err != nil {
+		return nil, nil, a.logAndReturnErr("error unpacking content", err)
+	}
+	mediaType, _ := metadata.GetMediaType()
+
+	// Upload samples a fresh EEK for the new entry
+	_, newEnvKey, newSharedEnvKeys, err := a.Upload(content, mediaType, keepReaders)
+	if err != nil {
+		return nil, nil, err
+	}
+	newEnvKeys := append([]id.ID{newEnvKey}, newSharedEnvKeys...)
+
+	// each share may have used a different author key, so look up the envelope each kept reader
+	// was actually sent, skipping those who never had one
+	readerPubs, err := a.getReaderPubs(keepReaders)
+	if err != nil {
+		return nil, nil, a.logAndReturnErr("error getting reader public keys", err)
+	}
+	oldEntryKey := id.FromBytes(env.EntryKey)
+	rekeyed := map[string]id.ID{envKey.String(): newEnvKey}
+	for i, readerPub := range readerPubs {
+		oldEnvKey, err := a.loadSharedEnvelopeKey(oldEntryKey, ecid.ToPublicKeyBytes(readerPub))
+		if err != nil {
+			return nil, nil, a.logAndReturnErr("error loading old envelope key", err)
+		}
+		if oldEnvKey != nil {
+			rekeyed[oldEnvKey.String()] = newSharedEnvKeys[i]
+		}
+	}
+
+	elapsedTime := time.Since(startTime)
+	a.logger.Info("rekeyed document", rekeyedDocFields(envKey, newEnvKey, len(readerPubs),
+		elapsedTime)...)
+	return newEnvKeys, rekeyed, nil
+}
+
+// Publish creates and uploads a new version of the pointer with the given name, pointing it to
+// the target document key. The pointer is signed by the author key with the given public key, and
+// its sequence number is one more than that of the current version (if one exists). It returns
+// the uploaded pointer document and its key.
+func (a *Author) Publish(authorPub []byte, name string, targetKey id.ID) (
+	*api.Document, id.ID, error) {
+	a.logger.Debug("publishing pointer", publishingPointerFields(authorPub, name, targetKey)...)
+	authorKey, in := a.authorKeys.Get(authorPub)
+	if !in {
+		return nil, nil, a.logAndReturnErr("error getting author key",
+			keychain.ErrUnexpectedMissingKey)
+	}
+	sequence := uint64(1)
+	current, err := a.getPointer(authorPub, name)
+	if err == nil {
+		sequence = current.Sequence + 1
+	} else if err != publish.ErrDocumentNotFound {
+		return nil, nil, a.logAndReturnErr("error getting current pointer", err)
+	}
+	pointerDoc, pointerKey, err := a.publishPointer(authorKey, name, targetKey, sequence)
+	if err != nil {
+		return nil, nil, a.logAndReturnErr("error publishing pointer", err)
+	}
+
+	a.logger.Info("published pointer", pointerFields(pointerKey, pointerDoc.GetPointer())...)
+	return pointerDoc, pointerKey, nil
+}
+
+// publishPointer creates the pointer with the given name, target key, and sequence number, signs it
+// with the author key, and uploads it to libri.
+func (a *Author) publishPointer(authorKey ecid.ID, name string, targetKey id.ID, sequence uint64) (
+	*api.Document, id.ID, error) {
+	pointerDoc, err := pack.NewPointerDoc(authorKey, name, targetKey, sequence)
+	if err != nil {
+		return nil, nil, err
+	}
+	lc, err := a.putters.Next()
+	if err != nil {
+		return nil, nil, err
+	}
+	pointerKey, err := a.publisher.Publish(pointerDoc, authorKey.PublicKeyBytes(), lc)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pointerDoc, pointerKey, nil
+}
+
+// Resolve gets the latest version of the pointer with the given author public key and name,
+// verifies its signature, and returns the key of the document it points to.
+func (a *Author) Resolve(authorPub []byte, name string) (id.ID, error) {
+	pointer, err := a.getPointer(authorPub, name)
+	if err != nil {
+		return nil, a.logAndReturnErr("error getting pointer", err)
+	}
+	pointerKey := api.GetPointerKey(authorPub, name)
+	a.logger.Info("resolved pointer", pointerFields(pointerKey, pointer)...)
+	return id.FromBytes(pointer.TargetKey), nil
+}
+
+// getPointer gets the pointer with the given author public key and name from several librarians
+// and returns the verified version with the highest sequence number. If none of them return a
+// valid pointer, it returns the first error other than publish.ErrDocumentNotFound, if any.
+func (a *Author) getPointer(authorPub []byte, name string) (*api.Pointer, error) {
+	pointerKey := api.GetPointerKey(authorPub, name)
+	type response struct {
+		pointer *api.Pointer
+		err     error
+	}
+	responses := make(chan response, nResolveResponses)
+	for c := 0; c < nResolveResponses; c++ {
+		go func() {
+			pointer, err := a.getPointerFrom(pointerKey, authorPub)
+			responses <- response{pointer: pointer, err: err}
+		}()
+	}
+	var latest *api.Pointer
+	err := publish.ErrDocumentNotFound
+	for c := 0; c < nResolveResponses; c++ {
+		rp := <-responses
+		if rp.err != nil {
+			if err == publish.ErrDocumentNotFound {
+				err = rp.err
+			}
+			continue
+		}
+		if latest == nil || rp.pointer.Sequence > latest.Sequence {
+			latest = rp.pointer
+		}
+	}
+	if latest == nil {
+		return nil, err
+	}
+	return latest, nil
+}
+
+// getPointerFrom gets the pointer with the given key from the next librarian and verifies it.
+func (a *Author) getPointerFrom(pointerKey id.ID, authorPub []byte) (*api.Pointer, error) {
+	lc, err := a.getters.Next()
+	if err != nil {
+		return nil, err
+	}
+	doc, err := a.acquirer.Acquire(pointerKey, authorPub, lc)
+	if err != nil {
+		return nil, err
+	}
+	pointer, ok := doc.Contents.(*api.Document_Pointer)
+	if !ok {
+		return nil, api.ErrUnexpectedDocumentType
+	}
+	if err := api.VerifyPointer(pointerKey.Bytes(), pointer.Pointer); err != nil {
+		return nil, err
+	}
+	return pointer.Pointer, nil
+}
+
 func (a *Author) logAndReturnErr(msg string, err error) error {
 	a.logger.Error(msg, zap.Error(err))
 	return err
@@ -14,8 +14,10 @@ import (
 
 	"os"
 
+	"github.com/drausin/libri/libri/author/group"
 	"github.com/drausin/libri/libri/author/io/common"
 	"github.com/drausin/libri/libri/author/io/enc"
+	"github.com/drausin/libri/libri/author/io/pack"
 	"github.com/drausin/libri/libri/author/io/page"
 	"github.com/drausin/libri/libri/author/io/publish"
 	"github.com/drausin/libri/libri/author/io/ship"
@@ -139,10 +141,26 @@ func TestAuthor_Upload_ok(t *testing.T) {
 	}
 
 	// since everything is mocked, inputs don't really matter
-	actualEnvelope, actualEnvelopeKey, err := a.Upload(nil, "")
+	actualEnvelope, actualEnvelopeKey, sharedEnvKeys, err := a.Upload(nil, "", nil)
 	assert.Nil(t, err)
 	assert.NotNil(t, actualEnvelope)
 	assert.Equal(t, expectedEnvKey, actualEnvelopeKey)
+	assert.Nil(t, sharedEnvKeys)
+
+	// check envelopes are shared with distinct readers, given directly and via groups
+	readerPubs := []*ecdsa.PublicKey{
+		&ecid.NewPseudoRandom(rng).Key().PublicKey,
+		&ecid.NewPseudoRandom(rng).Key().PublicKey,
+		&ecid.NewPseudoRandom(rng).Key().PublicKey,
+	}
+	err = a.SetReaderGroup("some group", readerPubs[1:])
+	assert.Nil(t, err)
+	readers := &Readers{PublicKeys: readerPubs[:2], Groups: []string{"some group"}}
+	actualEnvelope, actualEnvelopeKey, sharedEnvKeys, err = a.Upload(nil, "", readers)
+	assert.Nil(t, err)
+	assert.NotNil(t, actualEnvelope)
+	assert.Equal(t, expectedEnvKey, actualEnvelopeKey)
+	assert.Len(t, sharedEnvKeys, len(readerPubs))
 
 	err = a.CloseAndRemove()
 	assert.Nil(t, err)
@@ -154,7 +172,7 @@ func TestAuthor_Upload_err(t *testing.T) {
 	a.shipper = &fixedShipper{}
 
 	// check pack error bubbles up
-	actualEnvelope, actualEnvelopeKey, err := a.Upload(nil, "")
+	actualEnvelope, actualEnvelopeKey, _, err := a.Upload(nil, "", nil)
 	assert.NotNil(t, err)
 	assert.Nil(t, actualEnvelope)
 	assert.Nil(t, actualEnvelopeKey)
@@ -163,11 +181,26 @@ func TestAuthor_Upload_err(t *testing.T) {
 	a.shipper = &fixedShipper{err: errors.New("some Ship error")}
 
 	// check pack error bubbles up
-	actualEnvelope, actualEnvelopeKey, err = a.Upload(nil, "")
+	actualEnvelope, actualEnvelopeKey, _, err = a.Upload(nil, "", nil)
 	assert.NotNil(t, err)
 	assert.Nil(t, actualEnvelope)
 	assert.Nil(t, actualEnvelopeKey)
 
+	rng := rand.New(rand.NewSource(0))
+	a.shipper = &fixedShipper{
+		envelope: &api.Document{
+			Contents: &api.Document_Envelope{Envelope: api.NewTestEnvelope(rng)},
+		},
+	}
+
+	// check missing reader group error bubbles up
+	readers := &Readers{Groups: []string{"some missing group"}}
+	actualEnvelope, actualEnvelopeKey, sharedEnvKeys, err := a.Upload(nil, "", readers)
+	assert.Equal(t, group.ErrMissingGroup, err)
+	assert.Nil(t, actualEnvelope)
+	assert.Nil(t, actualEnvelopeKey)
+	assert.Nil(t, sharedEnvKeys)
+
 	err = a.CloseAndRemove()
 	assert.Nil(t, err)
 }
@@ -218,20 +251,7 @@ func TestAuthor_Download_err(t *testing.T) {
 func TestAuthor_UploadDownload(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	a := newTestAuthor()
-
-	// just mock interaction with libri network
-	pubAcq := &memPublisherAcquirer{
-		docs: make(map[string]*api.Document),
-	}
-
-	// but need to re-init shipper & receiver via publishers/acquirers
-	slPublisher := publish.NewSingleLoadPublisher(pubAcq, a.documentSLD)
-	ssAcquirer := publish.NewSingleStoreAcquirer(pubAcq, a.documentSLD)
-	mlPublisher := publish.NewMultiLoadPublisher(slPublisher, a.config.Publish)
-	msAcquirer := publish.NewMultiStoreAcquirer(ssAcquirer, a.config.Publish)
-	a.shipper = ship.NewShipper(&fixedPutterBalancer{}, pubAcq, mlPublisher)
-	a.receiver = ship.NewReceiver(&fixedGetterBalancer{}, a.selfReaderKeys, pubAcq,
-		msAcquirer, a.documentSLD)
+	mockLibri(a)
 
 	page.MinSize = 64 // just for testing
 	pageSizes := []uint32{128, 256, 512}
@@ -245,7 +265,7 @@ func TestAuthor_UploadDownload(t *testing.T) {
 		content1 := common.NewCompressableBytes(rng, c.uncompressedSize)
 		content1Bytes := content1.Bytes()
 
-		envelope, envelopeKey, err := a.Upload(content1, c.mediaType)
+		envelope, envelopeKey, _, err := a.Upload(content1, c.mediaType, nil)
 		assert.Nil(t, err)
 		assert.NotNil(t, envelope)
 		assert.NotNil(t, envelopeKey)
@@ -262,6 +282,164 @@ func TestAuthor_UploadDownload(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestAuthor_Rekey_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	a := newTestAuthor()
+	defer func() { cerrors.MaybePanic(a.CloseAndRemove()) }()
+	mockLibri(a)
+	page.MinSize = 64 // just for testing
+	a.config.Print.PageSize = 128
+
+	keepReaderPub := &ecid.NewPseudoRandom(rng).Key().PublicKey
+	revokeReaderPub := &ecid.NewPseudoRandom(rng).Key().PublicKey
+	content1 := common.NewCompressableBytes(rng, 1024)
+	content1Bytes := content1.Bytes()
+	readers := &Readers{PublicKeys: []*ecdsa.PublicKey{keepReaderPub, revokeReaderPub}}
+	_, oldEnvKey, oldSharedEnvKeys, err := a.Upload(content1, "application/x-pdf", readers)
+	assert.Nil(t, err)
+	assert.Len(t, oldSharedEnvKeys, 2)
+
+	keepReaders := &Readers{PublicKeys: []*ecdsa.PublicKey{keepReaderPub}}
+	newEnvKeys, rekeyed, err := a.Rekey(oldEnvKey, keepReaders)
+	assert.Nil(t, err)
+	assert.Len(t, newEnvKeys, 2)
+
+	// check old envelope keys of author and kept reader map to new ones, but not revoked reader's
+	assert.Len(t, rekeyed, 2)
+	assert.Equal(t, newEnvKeys[0], rekeyed[oldEnvKey.String()])
+	assert.Equal(t, newEnvKeys[1], rekeyed[oldSharedEnvKeys[0].String()])
+	_, in := rekeyed[oldSharedEnvKeys[1].String()]
+	assert.False(t, in)
+
+	// check new envelope has a new entry with the same content
+	oldEnv, err := a.receiver.ReceiveEnvelope(oldEnvKey)
+	assert.Nil(t, err)
+	newEnv, err := a.receiver.ReceiveEnvelope(newEnvKeys[0])
+	assert.Nil(t, err)
+	assert.NotEqual(t, oldEnv.EntryKey, newEnv.EntryKey)
+	content2 := new(bytes.Buffer)
+	err = a.Download(content2, newEnvKeys[0])
+	assert.Nil(t, err)
+	assert.Equal(t, content1Bytes, content2.Bytes())
+}
+
+func TestAuthor_Rekey_shared(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	a := newTestAuthor()
+	defer func() { cerrors.MaybePanic(a.CloseAndRemove()) }()
+	mockLibri(a)
+	page.MinSize = 64 // just for testing
+	a.config.Print.PageSize = 128
+
+	content1 := common.NewCompressableBytes(rng, 1024)
+	content1Bytes := content1.Bytes()
+	_, oldEnvKey, _, err := a.Upload(content1, "application/x-pdf", nil)
+	assert.Nil(t, err)
+
+	// share with several of our own self reader keys, so we can check they can decrypt, each
+	// share sampling its own author key
+	readerKeys := a.selfReaderKeys.(keychain.Sampler)
+	readerPubs := make([]*ecdsa.PublicKey, 0)
+	seen := make(map[string]struct{})
+	for len(readerPubs) < 6 {
+		readerKey, err := readerKeys.Sample()
+		assert.Nil(t, err)
+		if _, in := seen[readerKey.ID().String()]; !in {
+			seen[readerKey.ID().String()] = struct{}{}
+			readerPubs = append(readerPubs, &readerKey.Key().PublicKey)
+		}
+	}
+	keepReaderPubs, revokeReaderPub, unsharedReaderPub := readerPubs[:4], readerPubs[4],
+		readerPubs[5]
+	oldSharedEnvKeys := make([]id.ID, 0)
+	authorPubs := make(map[string]struct{})
+	for _, readerPub := range append(keepReaderPubs, revokeReaderPub) {
+		sharedEnv, sharedEnvKey, err := a.Share(oldEnvKey, readerPub)
+		assert.Nil(t, err)
+		oldSharedEnvKeys = append(oldSharedEnvKeys, sharedEnvKey)
+		authorPubs[string(sharedEnv.GetEnvelope().AuthorPublicKey)] = struct{}{}
+	}
+	assert.True(t, len(authorPubs) > 1)
+
+	keepReaders := &Readers{PublicKeys: append(keepReaderPubs, unsharedReaderPub)}
+	newEnvKeys, rekeyed, err := a.Rekey(oldEnvKey, keepReaders)
+	assert.Nil(t, err)
+	assert.Len(t, newEnvKeys, 1+len(keepReaders.PublicKeys))
+
+	// check each kept reader's old envelope maps to a new one they can decrypt, but not the
+	// revoked reader's or any for the reader who never had one
+	assert.Len(t, rekeyed, 1+len(keepReaderPubs))
+	for _, oldSharedEnvKey := range oldSharedEnvKeys[:len(keepReaderPubs)] {
+		newSharedEnvKey, in := rekeyed[oldSharedEnvKey.String()]
+		assert.True(t, in)
+		content2 := new(bytes.Buffer)
+		err = a.Download(content2, newSharedEnvKey)
+		assert.Nil(t, err)
+		assert.Equal(t, content1Bytes, content2.Bytes())
+	}
+	_, in := rekeyed[oldSharedEnvKeys[len(keepReaderPubs)].String()]
+	assert.False(t, in)
+}
+
+func TestAuthor_Rekey_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	envKey := id.NewPseudoRandom(rng)
+	authorKeys := keychain.New(1)
+	authorKey, err := authorKeys.Sample()
+	assert.Nil(t, err)
+	env := api.NewTestEnvelope(rng)
+	env.AuthorPublicKey = authorKey.PublicKeyBytes()
+
+	// check ReceiveEnvelope error bubbles up
+	a1 := &Author{
+		receiver: &fixedReceiver{
+			receiveEnvelopeErr: errors.New("some ReceiveEnvelope error"),
+		},
+		logger: clogging.NewDevLogger(zapcore.DebugLevel),
+	}
+	newEnvKeys, rekeyed, err := a1.Rekey(envKey, nil)
+	assert.NotNil(t, err)
+	assert.Nil(t, newEnvKeys)
+	assert.Nil(t, rekeyed)
+
+	// check envelope from another author triggers error
+	a2 := &Author{
+		receiver:   &fixedReceiver{envelope: api.NewTestEnvelope(rng)},
+		authorKeys: authorKeys,
+		logger:     clogging.NewDevLogger(zapcore.DebugLevel),
+	}
+	newEnvKeys, rekeyed, err = a2.Rekey(envKey, nil)
+	assert.Equal(t, keychain.ErrUnexpectedMissingKey, err)
+	assert.Nil(t, newEnvKeys)
+	assert.Nil(t, rekeyed)
+
+	// check ReceiveEntry error bubbles up
+	a3 := &Author{
+		receiver: &fixedReceiver{
+			envelope:        env,
+			receiveEntryErr: errors.New("some ReceiveEntry error"),
+		},
+		authorKeys: authorKeys,
+		logger:     clogging.NewDevLogger(zapcore.DebugLevel),
+	}
+	newEnvKeys, rekeyed, err = a3.Rekey(envKey, nil)
+	assert.NotNil(t, err)
+	assert.Nil(t, newEnvKeys)
+	assert.Nil(t, rekeyed)
+
+	// check Unpack error bubbles up
+	a4 := &Author{
+		receiver:      &fixedReceiver{envelope: env},
+		authorKeys:    authorKeys,
+		entryUnpacker: &fixedUnpacker{err: errors.New("some Unpack error")},
+		logger:        clogging.NewDevLogger(zapcore.DebugLevel),
+	}
+	newEnvKeys, rekeyed, err = a4.Rekey(envKey, nil)
+	assert.NotNil(t, err)
+	assert.Nil(t, newEnvKeys)
+	assert.Nil(t, rekeyed)
+}
+
 func TestAuthor_Share_ok(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	a := newTestAuthor()
@@ -362,6 +540,304 @@ func TestAuthor_Share_err(t *testing.T) {
 	assert.Nil(t, envID)
 }
 
+func TestAuthor_ShareMany_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	a := newTestAuthor()
+	defer func() { cerrors.MaybePanic(a.CloseAndRemove()) }()
+	a.receiver = &fixedReceiver{
+		envelope: api.NewTestEnvelope(rng),
+		eek:      enc.NewPseudoRandomEEK(rng),
+	}
+	expectedSharedEnvKey := id.NewPseudoRandom(rng)
+	a.shipper = &fixedShipper{
+		envelope: &api.Document{
+			Contents: &api.Document_Envelope{
+				Envelope: api.NewTestEnvelope(rng),
+			},
+		},
+		envelopeKey: expectedSharedEnvKey,
+	}
+	nReaders := 40
+	readerPubs := make([]*ecdsa.PublicKey, nReaders)
+	for i := range readerPubs {
+		readerPubs[i] = &ecid.NewPseudoRandom(rng).Key().PublicKey
+	}
+	err := a.SetReaderGroup("some team", readerPubs)
+	assert.Nil(t, err)
+
+	// since everything is mocked, inputs don't really matter
+	origEnvKey := id.NewPseudoRandom(rng)
+	readers := &Readers{Groups: []string{"some team"}}
+	sharedEnvs, sharedEnvKeys, err := a.ShareMany(origEnvKey, readers)
+	assert.Nil(t, err)
+	assert.Len(t, sharedEnvs, nReaders)
+	assert.Len(t, sharedEnvKeys, nReaders)
+	for _, sharedEnvKey := range sharedEnvKeys {
+		assert.Equal(t, expectedSharedEnvKey, sharedEnvKey)
+	}
+}
+
+func TestAuthor_ShareMany_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	origEnvKey := id.NewPseudoRandom(rng)
+	readers := &Readers{
+		PublicKeys: []*ecdsa.PublicKey{&ecid.NewPseudoRandom(rng).Key().PublicKey},
+	}
+
+	// check ReceiveEnvelope error bubbles up
+	a1 := &Author{
+		receiver: &fixedReceiver{
+			receiveEnvelopeErr: errors.New("some ReceiveEnvelope error"),
+		},
+		logger: clogging.NewDevLogger(zapcore.DebugLevel),
+	}
+	envs, envKeys, err := a1.ShareMany(origEnvKey, readers)
+	assert.NotNil(t, err)
+	assert.Nil(t, envs)
+	assert.Nil(t, envKeys)
+
+	// check GetEEK error bubbles up
+	a2 := &Author{
+		receiver: &fixedReceiver{
+			getErrkErr: errors.New("some GetEEK error"),
+		},
+		logger: clogging.NewDevLogger(zapcore.DebugLevel),
+	}
+	envs, envKeys, err = a2.ShareMany(origEnvKey, readers)
+	assert.NotNil(t, err)
+	assert.Nil(t, envs)
+	assert.Nil(t, envKeys)
+
+	// check Sample error bubbles up
+	a3 := &Author{
+		receiver: &fixedReceiver{},
+		authorKeys: &fixedKeychain{
+			sampleErr: errors.New("some Sample error"),
+		},
+		logger: clogging.NewDevLogger(zapcore.DebugLevel),
+	}
+	envs, envKeys, err = a3.ShareMany(origEnvKey, readers)
+	assert.NotNil(t, err)
+	assert.Nil(t, envs)
+	assert.Nil(t, envKeys)
+
+	// check missing reader group error bubbles up
+	a4 := &Author{
+		receiver: &fixedReceiver{
+			envelope: api.NewTestEnvelope(rng),
+		},
+		authorKeys: keychain.New(1),
+		groups:     group.NewStorerLoader(&fixedStorerLoader{}),
+		logger:     clogging.NewDevLogger(zapcore.DebugLevel),
+	}
+	envs, envKeys, err = a4.ShareMany(origEnvKey, &Readers{Groups: []string{"some group"}})
+	assert.Equal(t, group.ErrMissingGroup, err)
+	assert.Nil(t, envs)
+	assert.Nil(t, envKeys)
+
+	// check NewKEK error bubbles up
+	badCurvePK, err := ecdsa.GenerateKey(elliptic.P256(), rng)
+	assert.Nil(t, err)
+	a5 := &Author{
+		receiver: &fixedReceiver{
+			envelope: api.NewTestEnvelope(rng),
+		},
+		authorKeys: &fixedKeychain{
+			sampleID: ecid.FromPrivateKey(badCurvePK),
+		},
+		logger: clogging.NewDevLogger(zapcore.DebugLevel),
+	}
+	envs, envKeys, err = a5.ShareMany(origEnvKey, readers)
+	assert.NotNil(t, err)
+	assert.Nil(t, envs)
+	assert.Nil(t, envKeys)
+
+	// check ShipEnvelopes error bubbles up
+	a6 := &Author{
+		receiver: &fixedReceiver{
+			envelope: api.NewTestEnvelope(rng),
+		},
+		authorKeys: keychain.New(1),
+		shipper: &fixedShipper{
+			err: errors.New("some ShipEnvelopes error"),
+		},
+		logger: clogging.NewDevLogger(zapcore.DebugLevel),
+	}
+	envs, envKeys, err = a6.ShareMany(origEnvKey, readers)
+	assert.NotNil(t, err)
+	assert.Nil(t, envs)
+	assert.Nil(t, envKeys)
+
+	// check no readers triggers error
+	a7 := &Author{
+		receiver: &fixedReceiver{
+			envelope: api.NewTestEnvelope(rng),
+		},
+		authorKeys: keychain.New(1),
+		logger:     clogging.NewDevLogger(zapcore.DebugLevel),
+	}
+	envs, envKeys, err = a7.ShareMany(origEnvKey, &Readers{})
+	assert.Equal(t, ErrNoReaders, err)
+	assert.Nil(t, envs)
+	assert.Nil(t, envKeys)
+}
+
+func TestAuthor_SetGetReaderGroup(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	a := newTestAuthor()
+	defer func() { cerrors.MaybePanic(a.CloseAndRemove()) }()
+	readerPubs := []*ecdsa.PublicKey{
+		&ecid.NewPseudoRandom(rng).Key().PublicKey,
+		&ecid.NewPseudoRandom(rng).Key().PublicKey,
+	}
+
+	err := a.SetReaderGroup("some group", readerPubs)
+	assert.Nil(t, err)
+	readerPubs2, err := a.GetReaderGroup("some group")
+	assert.Nil(t, err)
+	assert.Equal(t, readerPubs, readerPubs2)
+
+	// check invalid group triggers error
+	err = a.SetReaderGroup("", readerPubs)
+	assert.Equal(t, group.ErrEmptyName, err)
+
+	// check missing group triggers error
+	readerPubs3, err := a.GetReaderGroup("some missing group")
+	assert.Equal(t, group.ErrMissingGroup, err)
+	assert.Nil(t, readerPubs3)
+}
+
+func TestAuthor_PublishResolve(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	a := newTestAuthor()
+	defer func() { cerrors.MaybePanic(a.CloseAndRemove()) }()
+
+	// just mock interaction with libri network
+	pubAcq := &memPublisherAcquirer{
+		docs: make(map[string]*api.Document),
+	}
+	a.publisher, a.acquirer = pubAcq, pubAcq
+	a.putters, a.getters = &fixedPutterBalancer{}, &fixedGetterBalancer{}
+
+	authorKey, err := a.authorKeys.Sample()
+	assert.Nil(t, err)
+	authorPub := authorKey.PublicKeyBytes()
+	name := "some name"
+
+	// check missing pointer can't be resolved
+	targetKey, err := a.Resolve(authorPub, name)
+	assert.Equal(t, publish.ErrDocumentNotFound, err)
+	assert.Nil(t, targetKey)
+
+	for i := uint64(1); i <= 3; i++ {
+		expectedTargetKey := id.NewPseudoRandom(rng)
+		pointerDoc, pointerKey, err := a.Publish(authorPub, name, expectedTargetKey)
+		assert.Nil(t, err)
+		assert.Equal(t, api.GetPointerKey(authorPub, name), pointerKey)
+		assert.Equal(t, i, pointerDoc.GetPointer().Sequence)
+
+		// check each published version replaces the previous
+		targetKey, err := a.Resolve(authorPub, name)
+		assert.Nil(t, err)
+		assert.Equal(t, expectedTargetKey, targetKey)
+	}
+}
+
+func TestAuthor_Resolve_latest(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	a := newTestAuthor()
+	defer func() { cerrors.MaybePanic(a.CloseAndRemove()) }()
+	a.getters = &fixedGetterBalancer{}
+	authorKey, err := a.authorKeys.Sample()
+	assert.Nil(t, err)
+	name := "some name"
+
+	targetKeys := make([]id.ID, 3)
+	versions := make([]*api.Document, 3)
+	for i := range versions {
+		targetKeys[i] = id.NewPseudoRandom(rng)
+		versions[i], err = pack.NewPointerDoc(authorKey, name, targetKeys[i], uint64(i+1))
+		assert.Nil(t, err)
+	}
+	tampered, err := pack.NewPointerDoc(authorKey, name, id.NewPseudoRandom(rng), 4)
+	assert.Nil(t, err)
+	tampered.GetPointer().Sequence = 9 // invalidates signature
+
+	// librarians return out-of-date, latest, and tampered versions
+	nResolveResponses = 4
+	defer func() { nResolveResponses = 3 }()
+	a.acquirer = &sequenceAcquirer{
+		docs: []*api.Document{versions[0], versions[2], tampered, versions[1]},
+	}
+	targetKey, err := a.Resolve(authorKey.PublicKeyBytes(), name)
+	assert.Nil(t, err)
+	assert.Equal(t, targetKeys[2], targetKey)
+
+	// publishing increments the latest sequence number
+	a.publisher = &memPublisherAcquirer{docs: make(map[string]*api.Document)}
+	a.putters = &fixedPutterBalancer{}
+	pointerDoc, _, err := a.Publish(authorKey.PublicKeyBytes(), name, targetKeys[0])
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(4), pointerDoc.GetPointer().Sequence)
+}
+
+func TestAuthor_Publish_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	a := newTestAuthor()
+	defer func() { cerrors.MaybePanic(a.CloseAndRemove()) }()
+	pubAcq := &memPublisherAcquirer{
+		docs: make(map[string]*api.Document),
+	}
+	a.publisher, a.acquirer = pubAcq, pubAcq
+	a.putters, a.getters = &fixedPutterBalancer{}, &fixedGetterBalancer{}
+	authorKey, err := a.authorKeys.Sample()
+	assert.Nil(t, err)
+	targetKey := id.NewPseudoRandom(rng)
+
+	// check missing author key error bubbles up
+	otherPub := ecid.NewPseudoRandom(rng).PublicKeyBytes()
+	_, _, err = a.Publish(otherPub, "some name", targetKey)
+	assert.Equal(t, keychain.ErrUnexpectedMissingKey, err)
+
+	// check getter balancer error bubbles up
+	a.getters = &fixedGetterBalancer{err: errors.New("some Next error")}
+	_, _, err = a.Publish(authorKey.PublicKeyBytes(), "some name", targetKey)
+	assert.NotNil(t, err)
+
+	// check putter balancer error bubbles up
+	a.getters = &fixedGetterBalancer{}
+	a.putters = &fixedPutterBalancer{err: errors.New("some Next error")}
+	_, _, err = a.Publish(authorKey.PublicKeyBytes(), "some name", targetKey)
+	assert.NotNil(t, err)
+}
+
+func TestAuthor_Resolve_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	a := newTestAuthor()
+	defer func() { cerrors.MaybePanic(a.CloseAndRemove()) }()
+	pubAcq := &memPublisherAcquirer{
+		docs: make(map[string]*api.Document),
+	}
+	a.acquirer = pubAcq
+	a.getters = &fixedGetterBalancer{}
+
+	// check non-pointer document under pointer key returns error
+	pointer := api.NewTestPointer(rng)
+	pointerKey := api.GetPointerKey(pointer.AuthorPublicKey, pointer.Name)
+	entry, _ := api.NewTestDocument(rng)
+	pubAcq.docs[pointerKey.String()] = entry
+	_, err := a.Resolve(pointer.AuthorPublicKey, pointer.Name)
+	assert.Equal(t, api.ErrUnexpectedDocumentType, err)
+
+	// check badly signed pointer returns error
+	pointer.Sequence++
+	pubAcq.docs[pointerKey.String()] = &api.Document{
+		Contents: &api.Document_Pointer{Pointer: pointer},
+	}
+	_, err = a.Resolve(pointer.AuthorPublicKey, pointer.Name)
+	assert.Equal(t, api.ErrInvalidSignature, err)
+}
+
 type fixedEntryPacker struct {
 	entry    *api.Document
 	metadata *api.Metadata
@@ -392,6 +868,20 @@ func (f *fixedShipper) ShipEnvelope(
 	return f.envelope, f.envelopeKey, f.err
 }
 
+func (f *fixedShipper) ShipEnvelopes(
+	keks []*enc.KEK, eek *enc.EEK, entryKey id.ID, authorPub []byte, readerPubs [][]byte,
+) ([]*api.Document, []id.ID, error) {
+	if f.err != nil {
+		return nil, nil, f.err
+	}
+	envelopes := make([]*api.Document, len(readerPubs))
+	envelopeKeys := make([]id.ID, len(readerPubs))
+	for i := range readerPubs {
+		envelopes[i], envelopeKeys[i] = f.envelope, f.envelopeKey
+	}
+	return envelopes, envelopeKeys, nil
+}
+
 type fixedReceiver struct {
 	entry              *api.Document
 	keys               *enc.EEK
@@ -431,7 +921,7 @@ type memPublisherAcquirer struct {
 
 func (p *memPublisherAcquirer) Publish(doc *api.Document, authorPub []byte, lc api.Putter) (
 	id.ID, error) {
-	docKey, err := api.GetKey(doc)
+	docKey, err := api.GetDocumentKey(doc)
 	if err != nil {
 		panic(err)
 	}
@@ -445,7 +935,27 @@ func (p *memPublisherAcquirer) Acquire(docKey id.ID, authorPub []byte, lc api.Ge
 	*api.Document, error) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	return p.docs[docKey.String()], nil
+	doc, in := p.docs[docKey.String()]
+	if !in {
+		return nil, publish.ErrDocumentNotFound
+	}
+	return doc, nil
+}
+
+// sequenceAcquirer returns each of its documents in turn.
+type sequenceAcquirer struct {
+	docs []*api.Document
+	i    int
+	mu   sync.Mutex
+}
+
+func (p *sequenceAcquirer) Acquire(docKey id.ID, authorPub []byte, lc api.Getter) (
+	*api.Document, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	doc := p.docs[p.i%len(p.docs)]
+	p.i++
+	return doc, nil
 }
 
 type fixedGetterBalancer struct {
@@ -502,6 +1012,27 @@ func caseCrossProduct(
 	}
 	return cases
 }
+
+// mockLibri replaces the author's interaction with the libri network with an in-memory
+// publisher/acquirer.
+func mockLibri(a *Author) {
+	pubAcq := &memPublisherAcquirer{
+		docs: make(map[string]*api.Document),
+	}
+
+	// need to re-init shipper & receiver via publishers/acquirers
+	slPublisher := publish.NewSingleLoadPublisher(pubAcq, a.documentSLD)
+	ssAcquirer := publish.NewSingleStoreAcquirer(pubAcq, a.documentSLD)
+	mlPublisher := publish.NewMultiLoadPublisher(slPublisher, a.signer, a.config.Publish)
+	msAcquirer := publish.NewMultiStoreAcquirer(ssAcquirer, a.signer,
+		a.config.Publish)
+	a.shipper = ship.NewShipper(&fixedPutterBalancer{}, pubAcq, mlPublisher, a.config.Publish)
+	a.receiver = ship.NewReceiver(&fixedGetterBalancer{}, a.selfReaderKeys, pubAcq,
+		msAcquirer, a.documentSLD, false)
+	a.publisher, a.acquirer, a.msAcquirer = pubAcq, pubAcq, msAcquirer
+	a.putters, a.getters = &fixedPutterBalancer{}, &fixedGetterBalancer{}
+}
+
 func newTestAuthor() *Author {
 	config := newTestConfig()
 	logger := clogging.NewDevLogger(zapcore.DebugLevel)
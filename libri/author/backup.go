@@ -0,0 +1,205 @@
+package author
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"github.com/drausin/libri/libri/author/io/enc"
+	"github.com/drausin/libri/libri/author/io/pack"
+	"github.com/drausin/libri/libri/author/io/publish"
+	"github.com/drausin/libri/libri/author/io/ship"
+	"github.com/drausin/libri/libri/author/keychain"
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// keychainBackupPointerName is the name of the pointer to the envelope of the latest keychain
+	// backup, signed by the backup key.
+	keychainBackupPointerName = "keychain-backup"
+
+	keychainBackupMediaType = "application/x-protobuf"
+
+	// keychainBackupSaltPrefix prefixes the account name in the scrypt salt deriving the backup
+	// secrets.
+	keychainBackupSaltPrefix = "libri keychain backup:"
+
+	// keychainBackupKeyLength is the number of derived bytes of the backup private key.
+	keychainBackupKeyLength = 32
+
+	keychainBackupScryptR = 8
+)
+
+var (
+	// ErrMissingKeychainBackup indicates when no keychain backup exists for a passphrase and
+	// account.
+	ErrMissingKeychainBackup = errors.New("missing keychain backup")
+)
+
+// BackupKeychains uploads the private keys of the author and self-reader keychains to libri,
+// encrypted with a new random EEK shared only with a backup key derived from the passphrase and
+// account name. Any keys in an existing backup for the passphrase and account
+// are first added to the given keychains, so keys added on different devices are merged. The
+// same scrypt parameters must be used to later restore the backup. It returns the key of the new
+// backup envelope.
+func (a *Author) BackupKeychains(
+	authorKeys, selfReaderKeys keychain.Keychain,
+	passphrase, account string,
+	scryptN, scryptP int,
+) (id.ID, error) {
+	startTime := time.Now()
+	backupKey, err := deriveKeychainBackupKey(passphrase, account, scryptN, scryptP)
+	if err != nil {
+		return nil, a.logAndReturnErr("error deriving backup key", err)
+	}
+	backupPub := backupKey.PublicKeyBytes()
+
+	// merge in the keys of the current backup, if it exists
+	sequence := uint64(1)
+	current, err := a.getPointer(backupPub, keychainBackupPointerName)
+	if err == nil {
+		existing, err := a.receiveKeychainsBackup(backupKey, current)
+		if err != nil {
+			return nil, a.logAndReturnErr("error receiving current keychain backup", err)
+		}
+		if err := mergeKeychainsBackup(existing, authorKeys, selfReaderKeys); err != nil {
+			return nil, a.logAndReturnErr("error merging current keychain backup", err)
+		}
+		sequence = current.Sequence + 1
+	} else if err != publish.ErrDocumentNotFound {
+		return nil, a.logAndReturnErr("error getting current keychain backup pointer", err)
+	}
+
+	content, err := proto.Marshal(&keychain.KeychainsBackup{
+		AuthorPrivateKeys:     keychain.PrivateKeys(authorKeys),
+		SelfReaderPrivateKeys: keychain.PrivateKeys(selfReaderKeys),
+	})
+	if err != nil {
+		return nil, a.logAndReturnErr("error marshaling keychain backup", err)
+	}
+
+	// each push gets its own random EEK, even when concurrent or retried pushes use the same
+	// sequence, so page IVs are never reused with the same key
+	eek, err := enc.NewEEK()
+	if err != nil {
+		return nil, a.logAndReturnErr("error creating backup EEK", err)
+	}
+	kek, err := enc.NewKEK(backupKey.Key(), &backupKey.Key().PublicKey)
+	if err != nil {
+		return nil, a.logAndReturnErr("error creating backup KEK", err)
+	}
+	backupPacker := pack.NewEntryPacker(a.config.Print, enc.NewMetadataEncrypterDecrypter(),
+		keychain.FromECIDs([]ecid.ID{backupKey}), a.documentSLD)
+	entry, _, err := backupPacker.Pack(bytes.NewReader(content), keychainBackupMediaType, eek,
+		backupPub)
+	if err != nil {
+		return nil, a.logAndReturnErr("error packing keychain backup", err)
+	}
+	_, envKey, err := a.shipper.ShipEntry(entry, backupPub, backupPub, kek, eek)
+	if err != nil {
+		return nil, a.logAndReturnErr("error shipping keychain backup", err)
+	}
+	_, _, err = a.publishPointer(backupKey, keychainBackupPointerName, envKey, sequence)
+	if err != nil {
+		return nil, a.logAndReturnErr("error publishing keychain backup pointer", err)
+	}
+
+	elapsedTime := time.Since(startTime)
+	a.logger.Info("backed up keychains", keychainBackupFields(envKey, account, sequence,
+		len(authorKeys.Keys()), len(selfReaderKeys.Keys()), elapsedTime)...)
+	return envKey, nil
+}
+
+// RestoreKeychains downloads and decrypts the latest keychain backup for the passphrase and
+// account name, returning the author and self-reader keychains.
+func (a *Author) RestoreKeychains(passphrase, account string, scryptN, scryptP int) (
+	keychain.Keychain, keychain.Keychain, error) {
+	startTime := time.Now()
+	backupKey, err := deriveKeychainBackupKey(passphrase, account, scryptN, scryptP)
+	if err != nil {
+		return nil, nil, a.logAndReturnErr("error deriving backup key", err)
+	}
+	current, err := a.getPointer(backupKey.PublicKeyBytes(), keychainBackupPointerName)
+	if err == publish.ErrDocumentNotFound {
+		return nil, nil, a.logAndReturnErr("error getting keychain backup pointer",
+			ErrMissingKeychainBackup)
+	} else if err != nil {
+		return nil, nil, a.logAndReturnErr("error getting keychain backup pointer", err)
+	}
+	backup, err := a.receiveKeychainsBackup(backupKey, current)
+	if err != nil {
+		return nil, nil, a.logAndReturnErr("error receiving keychain backup", err)
+	}
+	authorKeys, err := keychain.FromPrivateKeys(backup.AuthorPrivateKeys)
+	if err != nil {
+		return nil, nil, a.logAndReturnErr("error restoring author keychain", err)
+	}
+	selfReaderKeys, err := keychain.FromPrivateKeys(backup.SelfReaderPrivateKeys)
+	if err != nil {
+		return nil, nil, a.logAndReturnErr("error restoring self-reader keychain", err)
+	}
+
+	elapsedTime := time.Since(startTime)
+	a.logger.Info("restored keychains", keychainBackupFields(id.FromBytes(current.TargetKey),
+		account, current.Sequence, len(authorKeys.Keys()), len(selfReaderKeys.Keys()),
+		elapsedTime)...)
+	return authorKeys, selfReaderKeys, nil
+}
+
+// receiveKeychainsBackup downloads and decrypts the keychain backup the pointer targets.
+func (a *Author) receiveKeychainsBackup(backupKey ecid.ID, pointer *api.Pointer) (
+	*keychain.KeychainsBackup, error) {
+	backupReceiver := ship.NewReceiver(a.getters, keychain.FromECIDs([]ecid.ID{backupKey}),
+		a.acquirer, a.msAcquirer, a.documentSLD, false)
+	entry, eek, err := backupReceiver.ReceiveEntry(id.FromBytes(pointer.TargetKey))
+	if err != nil {
+		return nil, err
+	}
+	content := new(bytes.Buffer)
+	if _, err := a.entryUnpacker.Unpack(content, entry, eek); err != nil {
+		return nil, err
+	}
+	backup := &keychain.KeychainsBackup{}
+	if err := proto.Unmarshal(content.Bytes(), backup); err != nil {
+		return nil, err
+	}
+	return backup, nil
+}
+
+// mergeKeychainsBackup adds the keys in the backup to the author and self-reader keychains.
+func mergeKeychainsBackup(
+	backup *keychain.KeychainsBackup, authorKeys, selfReaderKeys keychain.Keychain,
+) error {
+	backupAuthorKeys, err := keychain.FromPrivateKeys(backup.AuthorPrivateKeys)
+	if err != nil {
+		return err
+	}
+	backupSelfReaderKeys, err := keychain.FromPrivateKeys(backup.SelfReaderPrivateKeys)
+	if err != nil {
+		return err
+	}
+	authorKeys.Add(backupAuthorKeys.Keys()...)
+	selfReaderKeys.Add(backupSelfReaderKeys.Keys()...)
+	return nil
+}
+
+// deriveKeychainBackupKey deterministically derives the backup key, which both authors and reads
+// the backup, from the passphrase and account name.
+func deriveKeychainBackupKey(passphrase, account string, scryptN, scryptP int) (ecid.ID, error) {
+	salt := []byte(keychainBackupSaltPrefix + account)
+	secret, err := scrypt.Key([]byte(passphrase), salt, scryptN, keychainBackupScryptR, scryptP,
+		keychainBackupKeyLength)
+	if err != nil {
+		return nil, err
+	}
+	backupKeys, err := keychain.FromPrivateKeys([][]byte{secret})
+	if err != nil {
+		// only when the derived private key is zero or not less than the curve order
+		return nil, err
+	}
+	return backupKeys.Keys()[0], nil
+}
@@ -0,0 +1,151 @@
+package author
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/drausin/libri/libri/author/keychain"
+	"github.com/drausin/libri/libri/common/ecid"
+	cerrors "github.com/drausin/libri/libri/common/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testBackupPassphrase = "some backup passphrase"
+	testBackupAccount    = "some account"
+)
+
+func TestAuthor_BackupRestoreKeychains(t *testing.T) {
+	a := newTestAuthor()
+	defer func() { cerrors.MaybePanic(a.CloseAndRemove()) }()
+	mockLibri(a)
+
+	// check restoring before any backup gives error
+	authorKeys, selfReaderKeys, err := a.RestoreKeychains(testBackupPassphrase,
+		testBackupAccount, veryLightScryptN, veryLightScryptP)
+	assert.Equal(t, ErrMissingKeychainBackup, err)
+	assert.Nil(t, authorKeys)
+	assert.Nil(t, selfReaderKeys)
+
+	// back up from first device
+	authorKeys1, selfReaderKeys1 := keychain.New(3), keychain.New(3)
+	envKey, err := a.BackupKeychains(authorKeys1, selfReaderKeys1, testBackupPassphrase,
+		testBackupAccount, veryLightScryptN, veryLightScryptP)
+	assert.Nil(t, err)
+	assert.NotNil(t, envKey)
+
+	// restore on second device, which then adds a key and backs up
+	authorKeys2, selfReaderKeys2, err := a.RestoreKeychains(testBackupPassphrase,
+		testBackupAccount, veryLightScryptN, veryLightScryptP)
+	assert.Nil(t, err)
+	assertSameKeys(t, authorKeys1, authorKeys2)
+	assertSameKeys(t, selfReaderKeys1, selfReaderKeys2)
+	authorKeys2.Add(ecid.NewRandom())
+	_, err = a.BackupKeychains(authorKeys2, selfReaderKeys2, testBackupPassphrase,
+		testBackupAccount, veryLightScryptN, veryLightScryptP)
+	assert.Nil(t, err)
+
+	// first device adds a different key and backs up, merging in the second device's key
+	selfReaderKeys1.Add(ecid.NewRandom())
+	_, err = a.BackupKeychains(authorKeys1, selfReaderKeys1, testBackupPassphrase,
+		testBackupAccount, veryLightScryptN, veryLightScryptP)
+	assert.Nil(t, err)
+	assert.Len(t, authorKeys1.Keys(), 4)
+	assert.Len(t, selfReaderKeys1.Keys(), 4)
+
+	authorKeys3, selfReaderKeys3, err := a.RestoreKeychains(testBackupPassphrase,
+		testBackupAccount, veryLightScryptN, veryLightScryptP)
+	assert.Nil(t, err)
+	assertSameKeys(t, authorKeys1, authorKeys3)
+	assertSameKeys(t, selfReaderKeys1, selfReaderKeys3)
+
+	// check other account and passphrase have no backup
+	_, _, err = a.RestoreKeychains(testBackupPassphrase, "other account", veryLightScryptN,
+		veryLightScryptP)
+	assert.Equal(t, ErrMissingKeychainBackup, err)
+	_, _, err = a.RestoreKeychains("other passphrase", testBackupAccount, veryLightScryptN,
+		veryLightScryptP)
+	assert.Equal(t, ErrMissingKeychainBackup, err)
+}
+
+func TestAuthor_BackupKeychains_err(t *testing.T) {
+	a := newTestAuthor()
+	defer func() { cerrors.MaybePanic(a.CloseAndRemove()) }()
+	mockLibri(a)
+	authorKeys, selfReaderKeys := keychain.New(3), keychain.New(3)
+
+	// check bad scrypt params trigger error
+	envKey, err := a.BackupKeychains(authorKeys, selfReaderKeys, testBackupPassphrase,
+		testBackupAccount, -1, -1)
+	assert.NotNil(t, err)
+	assert.Nil(t, envKey)
+
+	// check getter balancer error bubbles up
+	a.getters = &fixedGetterBalancer{err: errors.New("some Next error")}
+	envKey, err = a.BackupKeychains(authorKeys, selfReaderKeys, testBackupPassphrase,
+		testBackupAccount, veryLightScryptN, veryLightScryptP)
+	assert.NotNil(t, err)
+	assert.Nil(t, envKey)
+	a.getters = &fixedGetterBalancer{}
+
+	// check putter balancer error bubbles up
+	a.putters = &fixedPutterBalancer{err: errors.New("some Next error")}
+	envKey, err = a.BackupKeychains(authorKeys, selfReaderKeys, testBackupPassphrase,
+		testBackupAccount, veryLightScryptN, veryLightScryptP)
+	assert.NotNil(t, err)
+	assert.Nil(t, envKey)
+	a.putters = &fixedPutterBalancer{}
+
+	// check shipper error bubbles up
+	shipper := a.shipper
+	a.shipper = &fixedShipper{err: errors.New("some ShipEntry error")}
+	envKey, err = a.BackupKeychains(authorKeys, selfReaderKeys, testBackupPassphrase,
+		testBackupAccount, veryLightScryptN, veryLightScryptP)
+	assert.NotNil(t, err)
+	assert.Nil(t, envKey)
+	a.shipper = shipper
+}
+
+func TestAuthor_RestoreKeychains_err(t *testing.T) {
+	a := newTestAuthor()
+	defer func() { cerrors.MaybePanic(a.CloseAndRemove()) }()
+	mockLibri(a)
+
+	// check bad scrypt params trigger error
+	_, _, err := a.RestoreKeychains(testBackupPassphrase, testBackupAccount, -1, -1)
+	assert.NotNil(t, err)
+
+	// check getter balancer error bubbles up
+	a.getters = &fixedGetterBalancer{err: errors.New("some Next error")}
+	_, _, err = a.RestoreKeychains(testBackupPassphrase, testBackupAccount, veryLightScryptN,
+		veryLightScryptP)
+	assert.NotNil(t, err)
+	assert.NotEqual(t, ErrMissingKeychainBackup, err)
+}
+
+func TestDeriveKeychainBackupKey(t *testing.T) {
+	backupKey1, err := deriveKeychainBackupKey(testBackupPassphrase, testBackupAccount,
+		veryLightScryptN, veryLightScryptP)
+	assert.Nil(t, err)
+
+	// check derivation is deterministic
+	backupKey2, err := deriveKeychainBackupKey(testBackupPassphrase, testBackupAccount,
+		veryLightScryptN, veryLightScryptP)
+	assert.Nil(t, err)
+	assert.Equal(t, backupKey1.PublicKeyBytes(), backupKey2.PublicKeyBytes())
+
+	// check different accounts and passphrases give different keys
+	backupKey3, err := deriveKeychainBackupKey(testBackupPassphrase, "other account",
+		veryLightScryptN, veryLightScryptP)
+	assert.Nil(t, err)
+	assert.NotEqual(t, backupKey1.PublicKeyBytes(), backupKey3.PublicKeyBytes())
+
+	backupKey4, err := deriveKeychainBackupKey("other passphrase", testBackupAccount,
+		veryLightScryptN, veryLightScryptP)
+	assert.Nil(t, err)
+	assert.NotEqual(t, backupKey1.PublicKeyBytes(), backupKey4.PublicKeyBytes())
+}
+
+func assertSameKeys(t *testing.T, expected, actual keychain.Keychain) {
+	assert.Equal(t, keychain.PrivateKeys(expected), keychain.PrivateKeys(actual))
+}
@@ -7,6 +7,8 @@ import (
 
 	"github.com/drausin/libri/libri/author/io/print"
 	"github.com/drausin/libri/libri/author/io/publish"
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/librarian/client"
 	"github.com/drausin/libri/libri/librarian/server"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -53,6 +55,16 @@ type Config struct {
 	// Publish defines parameters for publishing pages to libri.
 	Publish *publish.Parameters
 
+	// AllowUnsignedEntries indicates whether to accept legacy entries without an author
+	// signature when downloading.
+	AllowUnsignedEntries bool
+
+	// KeyType is the key type of the client ID, used when creating a new one.
+	KeyType ecid.KeyType
+
+	// SignatureFormat is the wire format of the signatures on requests to librarians.
+	SignatureFormat client.SignatureFormat
+
 	// LogLevel is the log level
 	LogLevel zapcore.Level
 }
@@ -69,6 +81,8 @@ func NewDefaultConfig() *Config {
 	config.WithDefaultLibrarianAddrs()
 	config.WithDefaultPrint()
 	config.WithDefaultPublish()
+	config.WithDefaultKeyType()
+	config.WithDefaultSignatureFormat()
 	config.WithDefaultLogLevel()
 
 	return config
@@ -178,6 +192,36 @@ func (c *Config) WithDefaultPublish() *Config {
 	return c
 }
 
+// WithAllowUnsignedEntries sets whether to accept legacy entries without an author signature.
+func (c *Config) WithAllowUnsignedEntries(allowUnsignedEntries bool) *Config {
+	c.AllowUnsignedEntries = allowUnsignedEntries
+	return c
+}
+
+// WithKeyType sets the key type of new client IDs to the given value.
+func (c *Config) WithKeyType(keyType ecid.KeyType) *Config {
+	c.KeyType = keyType
+	return c
+}
+
+// WithDefaultKeyType sets the key type of new client IDs to secp256k1.
+func (c *Config) WithDefaultKeyType() *Config {
+	c.KeyType = ecid.KeyTypeSecp256k1
+	return c
+}
+
+// WithSignatureFormat sets the wire format of request signatures to the given value.
+func (c *Config) WithSignatureFormat(format client.SignatureFormat) *Config {
+	c.SignatureFormat = format
+	return c
+}
+
+// WithDefaultSignatureFormat sets the wire format of request signatures to the default.
+func (c *Config) WithDefaultSignatureFormat() *Config {
+	c.SignatureFormat = client.DefaultSignatureFormat
+	return c
+}
+
 // WithLogLevel sets the log level to the given value, though this doesn't have any direct effect
 // on the creation of the logger instance.
 func (c *Config) WithLogLevel(logLevel zapcore.Level) *Config {
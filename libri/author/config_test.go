@@ -75,6 +75,13 @@ func TestConfig_WithPublish(t *testing.T) {
 	)
 }
 
+func TestConfig_WithAllowUnsignedEntries(t *testing.T) {
+	c := &Config{}
+	assert.False(t, c.AllowUnsignedEntries)
+	assert.True(t, c.WithAllowUnsignedEntries(true).AllowUnsignedEntries)
+	assert.False(t, c.WithAllowUnsignedEntries(false).AllowUnsignedEntries)
+}
+
 func TestConfig_WithLogLevel(t *testing.T) {
 	c1, c2, c3 := &Config{}, &Config{}, &Config{}
 	c1.WithDefaultLogLevel()
@@ -0,0 +1,121 @@
+package group
+
+import (
+	"crypto/ecdsa"
+	"errors"
+
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/storage"
+	"github.com/golang/protobuf/proto"
+)
+
+var (
+	// ErrMissingGroup indicates when a reader group with the given name does not exist.
+	ErrMissingGroup = errors.New("missing reader group")
+
+	// ErrEmptyName indicates when a reader group name is empty.
+	ErrEmptyName = errors.New("reader group name must not be empty")
+
+	// ErrEmptyGroup indicates when a reader group has no readers.
+	ErrEmptyGroup = errors.New("reader group must have at least one reader")
+)
+
+// keyPrefix is prepended to the group name to create the storage key for the group.
+var keyPrefix = []byte("ReaderGroup/")
+
+// New creates a new *ReaderGroup with the given name and reader public keys.
+func New(name string, readerPubs []*ecdsa.PublicKey) (*ReaderGroup, error) {
+	g := &ReaderGroup{
+		Name:             name,
+		ReaderPublicKeys: make([][]byte, len(readerPubs)),
+	}
+	for i, readerPub := range readerPubs {
+		g.ReaderPublicKeys[i] = ecid.ToPublicKeyBytes(readerPub)
+	}
+	if err := Validate(g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Validate checks that the reader group has a name and at least one reader and that all reader
+// public keys are valid.
+func Validate(g *ReaderGroup) error {
+	if g.Name == "" {
+		return ErrEmptyName
+	}
+	if len(g.ReaderPublicKeys) == 0 {
+		return ErrEmptyGroup
+	}
+	for _, readerPubBytes := range g.ReaderPublicKeys {
+		if _, err := ecid.FromPublicKeyBytes(readerPubBytes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReaderPublicKeys returns the parsed public keys of the readers in the group.
+func ReaderPublicKeys(g *ReaderGroup) ([]*ecdsa.PublicKey, error) {
+	readerPubs := make([]*ecdsa.PublicKey, len(g.ReaderPublicKeys))
+	for i, readerPubBytes := range g.ReaderPublicKeys {
+		readerPub, err := ecid.FromPublicKeyBytes(readerPubBytes)
+		if err != nil {
+			return nil, err
+		}
+		readerPubs[i] = readerPub
+	}
+	return readerPubs, nil
+}
+
+// StorerLoader stores and loads reader groups.
+type StorerLoader interface {
+	// Store saves the reader group, replacing any existing group with the same name.
+	Store(g *ReaderGroup) error
+
+	// Load gets the reader group with the given name, returning ErrMissingGroup if it doesn't
+	// exist.
+	Load(name string) (*ReaderGroup, error)
+}
+
+type storerLoader struct {
+	nsl storage.NamespaceSL
+}
+
+// NewStorerLoader creates a new StorerLoader that saves reader groups in the given namespace.
+func NewStorerLoader(nsl storage.NamespaceSL) StorerLoader {
+	return &storerLoader{nsl: nsl}
+}
+
+func (sl *storerLoader) Store(g *ReaderGroup) error {
+	if err := Validate(g); err != nil {
+		return err
+	}
+	gBytes, err := proto.Marshal(g)
+	if err != nil {
+		return err
+	}
+	return sl.nsl.Store(groupKey(g.Name), gBytes)
+}
+
+func (sl *storerLoader) Load(name string) (*ReaderGroup, error) {
+	if name == "" {
+		return nil, ErrEmptyName
+	}
+	gBytes, err := sl.nsl.Load(groupKey(name))
+	if err != nil {
+		return nil, err
+	}
+	if gBytes == nil {
+		return nil, ErrMissingGroup
+	}
+	g := &ReaderGroup{}
+	if err := proto.Unmarshal(gBytes, g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func groupKey(name string) []byte {
+	return append(append([]byte{}, keyPrefix...), []byte(name)...)
+}
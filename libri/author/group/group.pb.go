@@ -0,0 +1,74 @@
+// Code generated by protoc-gen-go.
+// source: libri/author/group/group.proto
+// DO NOT EDIT!
+
+/*
+Package group is a generated protocol buffer package.
+
+It is generated from these files:
+	libri/author/group/group.proto
+
+It has these top-level messages:
+	ReaderGroup
+*/
+package group
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+// ReaderGroup is a named collection of reader public keys that documents can be shared with.
+type ReaderGroup struct {
+	// name of the group, unique within an author client
+	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	// 65-byte public keys of the readers in the group
+	ReaderPublicKeys [][]byte `protobuf:"bytes,2,rep,name=reader_public_keys,json=readerPublicKeys,proto3" json:"reader_public_keys,omitempty"`
+}
+
+func (m *ReaderGroup) Reset()                    { *m = ReaderGroup{} }
+func (m *ReaderGroup) String() string            { return proto.CompactTextString(m) }
+func (*ReaderGroup) ProtoMessage()               {}
+func (*ReaderGroup) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+func (m *ReaderGroup) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ReaderGroup) GetReaderPublicKeys() [][]byte {
+	if m != nil {
+		return m.ReaderPublicKeys
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*ReaderGroup)(nil), "group.ReaderGroup")
+}
+
+func init() { proto.RegisterFile("libri/author/group/group.proto", fileDescriptor0) }
+
+var fileDescriptor0 = []byte{
+	// 123 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x92, 0xcb, 0xc9, 0x4c, 0x2a,
+	0xca, 0xd4, 0x4f, 0x2c, 0x2d, 0xc9, 0xc8, 0x2f, 0xd2, 0x4f, 0x2f, 0xca, 0x2f, 0x2d, 0x80, 0x90,
+	0x7a, 0x05, 0x45, 0xf9, 0x25, 0xf9, 0x42, 0xac, 0x60, 0x8e, 0x92, 0x3f, 0x17, 0x77, 0x50, 0x6a,
+	0x62, 0x4a, 0x6a, 0x91, 0x3b, 0x88, 0x2b, 0x24, 0xc4, 0xc5, 0x92, 0x97, 0x98, 0x9b, 0x2a, 0xc1,
+	0xa8, 0xc0, 0xa8, 0xc1, 0x19, 0x04, 0x66, 0x0b, 0xe9, 0x70, 0x09, 0x15, 0x81, 0x95, 0xc4, 0x17,
+	0x94, 0x26, 0xe5, 0x64, 0x26, 0xc7, 0x67, 0xa7, 0x56, 0x16, 0x4b, 0x30, 0x29, 0x30, 0x6b, 0xf0,
+	0x04, 0x09, 0x40, 0x64, 0x02, 0xc0, 0x12, 0xde, 0xa9, 0x95, 0xc5, 0x49, 0x6c, 0x60, 0xe3, 0x8d,
+	0x01, 0x03, 0x00, 0x08, 0xdf, 0x7a, 0x7d, 0x80, 0x00, 0x00, 0x00,
+}
@@ -0,0 +1,147 @@
+package group
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	readerPubs := newReaderPubs(rng, 3)
+
+	g, err := New("some group", readerPubs)
+	assert.Nil(t, err)
+	assert.Equal(t, "some group", g.Name)
+	assert.Len(t, g.ReaderPublicKeys, 3)
+
+	readerPubs2, err := ReaderPublicKeys(g)
+	assert.Nil(t, err)
+	assert.Equal(t, readerPubs, readerPubs2)
+}
+
+func TestNew_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+
+	g, err := New("", newReaderPubs(rng, 3))
+	assert.Equal(t, ErrEmptyName, err)
+	assert.Nil(t, g)
+
+	g, err = New("some group", nil)
+	assert.Equal(t, ErrEmptyGroup, err)
+	assert.Nil(t, g)
+}
+
+func TestValidate_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	g := &ReaderGroup{
+		Name:             "some group",
+		ReaderPublicKeys: [][]byte{api.RandBytes(rng, api.ECPubKeyLength)},
+	}
+	assert.NotNil(t, Validate(g))
+
+	readerPubs, err := ReaderPublicKeys(g)
+	assert.NotNil(t, err)
+	assert.Nil(t, readerPubs)
+}
+
+func TestStorerLoader_StoreLoad_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	sl := NewStorerLoader(&fixedNamespaceSL{stored: make(map[string][]byte)})
+
+	g1, err := New("some group", newReaderPubs(rng, 3))
+	assert.Nil(t, err)
+	err = sl.Store(g1)
+	assert.Nil(t, err)
+
+	g2, err := sl.Load("some group")
+	assert.Nil(t, err)
+	assert.Equal(t, g1, g2)
+
+	// check storing group with same name replaces existing
+	g3, err := New("some group", newReaderPubs(rng, 2))
+	assert.Nil(t, err)
+	err = sl.Store(g3)
+	assert.Nil(t, err)
+
+	g4, err := sl.Load("some group")
+	assert.Nil(t, err)
+	assert.Equal(t, g3, g4)
+}
+
+func TestStorerLoader_Store_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	g, err := New("some group", newReaderPubs(rng, 3))
+	assert.Nil(t, err)
+
+	// check invalid group triggers error
+	sl1 := NewStorerLoader(&fixedNamespaceSL{stored: make(map[string][]byte)})
+	err = sl1.Store(&ReaderGroup{Name: "some group"})
+	assert.Equal(t, ErrEmptyGroup, err)
+
+	// check store error bubbles up
+	sl2 := NewStorerLoader(&fixedNamespaceSL{storeErr: errors.New("some Store error")})
+	err = sl2.Store(g)
+	assert.NotNil(t, err)
+}
+
+func TestStorerLoader_Load_err(t *testing.T) {
+	// check empty name triggers error
+	sl1 := NewStorerLoader(&fixedNamespaceSL{stored: make(map[string][]byte)})
+	g, err := sl1.Load("")
+	assert.Equal(t, ErrEmptyName, err)
+	assert.Nil(t, g)
+
+	// check missing group triggers error
+	g, err = sl1.Load("some group")
+	assert.Equal(t, ErrMissingGroup, err)
+	assert.Nil(t, g)
+
+	// check load error bubbles up
+	sl2 := NewStorerLoader(&fixedNamespaceSL{loadErr: errors.New("some Load error")})
+	g, err = sl2.Load("some group")
+	assert.NotNil(t, err)
+	assert.Nil(t, g)
+
+	// check unmarshal error bubbles up
+	sl3 := NewStorerLoader(&fixedNamespaceSL{
+		stored: map[string][]byte{string(groupKey("some group")): []byte("not a group")},
+	})
+	g, err = sl3.Load("some group")
+	assert.NotNil(t, err)
+	assert.Nil(t, g)
+}
+
+func newReaderPubs(rng *rand.Rand, n int) []*ecdsa.PublicKey {
+	readerPubs := make([]*ecdsa.PublicKey, n)
+	for i := range readerPubs {
+		readerPubs[i] = &ecid.NewPseudoRandom(rng).Key().PublicKey
+	}
+	return readerPubs
+}
+
+type fixedNamespaceSL struct {
+	stored   map[string][]byte
+	storeErr error
+	loadErr  error
+}
+
+func (f *fixedNamespaceSL) Store(key []byte, value []byte) error {
+	if f.storeErr != nil {
+		return f.storeErr
+	}
+	f.stored[string(key)] = value
+	return nil
+}
+
+func (f *fixedNamespaceSL) Load(key []byte) ([]byte, error) {
+	if f.loadErr != nil {
+		return nil, f.loadErr
+	}
+	return f.stored[string(key)], nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/drausin/libri/libri/author/io/enc"
 	"github.com/drausin/libri/libri/author/io/page"
 	"github.com/drausin/libri/libri/author/io/print"
+	"github.com/drausin/libri/libri/author/keychain"
 	"github.com/drausin/libri/libri/common/id"
 	"github.com/drausin/libri/libri/common/storage"
 	"github.com/drausin/libri/libri/librarian/api"
@@ -16,7 +17,7 @@ import (
 // EntryPacker creates entry documents from raw content.
 type EntryPacker interface {
 	// Pack prints pages from the content, encrypts their metadata, and binds them together
-	// into an entry *api.Document.
+	// into an entry *api.Document signed by the author key.
 	Pack(content io.Reader, mediaType string, keys *enc.EEK, authorPub []byte) (
 		*api.Document, *api.Metadata, error)
 }
@@ -25,12 +26,14 @@ type EntryPacker interface {
 func NewEntryPacker(
 	params *print.Parameters,
 	metadataEnc enc.MetadataEncrypter,
+	authorKeys keychain.Getter,
 	docSL storage.DocumentSLD,
 ) EntryPacker {
 	pageS := page.NewStorerLoader(docSL)
 	return &entryPacker{
 		params:      params,
 		metadataEnc: metadataEnc,
+		authorKeys:  authorKeys,
 		printer:     print.NewPrinter(params, pageS),
 		pageS:       pageS,
 		docL:        docSL,
@@ -40,6 +43,7 @@ func NewEntryPacker(
 type entryPacker struct {
 	params      *print.Parameters
 	metadataEnc enc.MetadataEncrypter
+	authorKeys  keychain.Getter
 	printer     print.Printer
 	pageS       page.Storer
 	docL        storage.DocumentLoader
@@ -60,7 +64,17 @@ func (p *entryPacker) Pack(content io.Reader, mediaType string, keys *enc.EEK, a
 		return nil, nil, err
 	}
 	doc, err := newEntryDoc(authorPub, pageKeys, encMetadata, p.docL)
-	return doc, metadata, err
+	if err != nil {
+		return nil, nil, err
+	}
+	authorKey, in := p.authorKeys.Get(authorPub)
+	if !in {
+		return nil, nil, keychain.ErrUnexpectedMissingKey
+	}
+	if err := api.SignEntry(doc.Contents.(*api.Document_Entry).Entry, authorKey.Key()); err != nil {
+		return nil, nil, err
+	}
+	return doc, metadata, nil
 }
 
 // EntryUnpacker writes individual pages to the content io.Writer.
@@ -12,6 +12,7 @@ import (
 	"github.com/drausin/libri/libri/author/io/enc"
 	"github.com/drausin/libri/libri/author/io/page"
 	"github.com/drausin/libri/libri/author/io/print"
+	"github.com/drausin/libri/libri/author/keychain"
 	"github.com/drausin/libri/libri/common/id"
 	"github.com/drausin/libri/libri/librarian/api"
 	"github.com/stretchr/testify/assert"
@@ -25,8 +26,11 @@ func TestEntryPacker_Pack_ok(t *testing.T) {
 	docSL := &fixedDocSLD{
 		stored: make(map[string]*api.Document),
 	}
-	p := NewEntryPacker(params, enc.NewMetadataEncrypterDecrypter(), docSL)
-	authorPub := api.RandBytes(rng, 65)
+	authorKeys := keychain.New(1)
+	p := NewEntryPacker(params, enc.NewMetadataEncrypterDecrypter(), authorKeys, docSL)
+	authorKey, err := authorKeys.Sample()
+	assert.Nil(t, err)
+	authorPub := authorKey.PublicKeyBytes()
 	keys := enc.NewPseudoRandomEEK(rng)
 	mediaType := "application/x-pdf"
 
@@ -40,6 +44,7 @@ func TestEntryPacker_Pack_ok(t *testing.T) {
 	origSize, in := metadata.GetUncompressedSize()
 	assert.True(t, in)
 	assert.Equal(t, uint64(uncompressedSize1), origSize)
+	assert.Nil(t, api.VerifyEntry(doc.Contents.(*api.Document_Entry).Entry))
 
 	// test works with multi-page content
 	uncompressedSize2 := int(params.PageSize * 5)
@@ -54,6 +59,7 @@ func TestEntryPacker_Pack_ok(t *testing.T) {
 	pageKeys, err := api.GetEntryPageKeys(doc)
 	assert.Nil(t, err)
 	assert.True(t, len(pageKeys) > 1)
+	assert.Nil(t, api.VerifyEntry(doc.Contents.(*api.Document_Entry).Entry))
 }
 
 func TestEntryPacker_Pack_err(t *testing.T) {
@@ -62,10 +68,13 @@ func TestEntryPacker_Pack_err(t *testing.T) {
 	docSL := &fixedDocSLD{
 		stored: make(map[string]*api.Document),
 	}
-	p := NewEntryPacker(params, enc.NewMetadataEncrypterDecrypter(), docSL)
+	authorKeys := keychain.New(1)
+	p := NewEntryPacker(params, enc.NewMetadataEncrypterDecrypter(), authorKeys, docSL)
 	mediaType := "application/x-pdf"
 	content := common.NewCompressableBytes(rng, int(params.PageSize/2))
-	authorPub := api.RandBytes(rng, 65)
+	authorKey, err := authorKeys.Sample()
+	assert.Nil(t, err)
+	authorPub := authorKey.PublicKeyBytes()
 	keys := enc.NewPseudoRandomEEK(rng)
 
 	// check error from bad mediaType bubbles up
@@ -84,7 +93,7 @@ func TestEntryPacker_Pack_err(t *testing.T) {
 		stored:  make(map[string]*api.Document),
 		loadErr: errors.New("some Load error"),
 	}
-	p2 := NewEntryPacker(params, enc.NewMetadataEncrypterDecrypter(), errDocSL)
+	p2 := NewEntryPacker(params, enc.NewMetadataEncrypterDecrypter(), authorKeys, errDocSL)
 
 	// check error from missing page bubbles up
 	doc, metadata, err = p2.Pack(content, mediaType, keys, []byte{})
@@ -92,6 +101,12 @@ func TestEntryPacker_Pack_err(t *testing.T) {
 	assert.Nil(t, doc)
 	assert.Nil(t, metadata)
 
+	// check missing author key triggers error
+	content = common.NewCompressableBytes(rng, int(params.PageSize/2))
+	doc, metadata, err = p.Pack(content, mediaType, keys, api.RandBytes(rng, 65))
+	assert.Equal(t, keychain.ErrUnexpectedMissingKey, err)
+	assert.Nil(t, doc)
+	assert.Nil(t, metadata)
 }
 
 func TestEntryUnpacker_Unpack_ok(t *testing.T) {
@@ -163,7 +178,10 @@ func TestEntryUnpacker_Unpack_err(t *testing.T) {
 func TestEntryPackUnpack(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	page.MinSize = 64 // just for testing
-	authorPub := api.RandBytes(rng, 65)
+	authorKeys := keychain.New(1)
+	authorKey, err := authorKeys.Sample()
+	assert.Nil(t, err)
+	authorPub := authorKey.PublicKeyBytes()
 	keys := enc.NewPseudoRandomEEK(rng)
 	metadataEncDec := enc.NewMetadataEncrypterDecrypter()
 
@@ -189,7 +207,7 @@ func TestEntryPackUnpack(t *testing.T) {
 		packParams, err := print.NewParameters(comp.MinBufferSize, c.pageSize,
 			c.packParallelism)
 		assert.Nil(t, err)
-		p := NewEntryPacker(packParams, metadataEncDec, docSL)
+		p := NewEntryPacker(packParams, metadataEncDec, authorKeys, docSL)
 		unpackParams, err := print.NewParameters(comp.MinBufferSize, c.pageSize,
 			c.unpackParallelism)
 		assert.Nil(t, err)
@@ -0,0 +1,31 @@
+package pack
+
+import (
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+)
+
+// NewPointerDoc returns a new pointer document with the given name, target document key, and
+// sequence number, signed by the author key.
+func NewPointerDoc(
+	authorKey ecid.ID,
+	name string,
+	targetKey id.ID,
+	sequence uint64,
+) (*api.Document, error) {
+	pointer := &api.Pointer{
+		AuthorPublicKey: authorKey.PublicKeyBytes(),
+		Name:            name,
+		TargetKey:       targetKey.Bytes(),
+		Sequence:        sequence,
+	}
+	if err := api.SignPointer(pointer, authorKey.Key()); err != nil {
+		return nil, err
+	}
+	return &api.Document{
+		Contents: &api.Document_Pointer{
+			Pointer: pointer,
+		},
+	}, nil
+}
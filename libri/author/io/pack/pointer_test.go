@@ -0,0 +1,28 @@
+package pack
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPointerDoc(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	authorKey := ecid.NewPseudoRandom(rng)
+	targetKey := id.NewPseudoRandom(rng)
+
+	doc, err := NewPointerDoc(authorKey, "some name", targetKey, 2)
+	assert.Nil(t, err)
+	pointer := doc.Contents.(*api.Document_Pointer).Pointer
+	assert.Equal(t, authorKey.PublicKeyBytes(), pointer.AuthorPublicKey)
+	assert.Equal(t, "some name", pointer.Name)
+	assert.Equal(t, targetKey.Bytes(), pointer.TargetKey)
+	assert.Equal(t, uint64(2), pointer.Sequence)
+
+	key := api.GetPointerKey(authorKey.PublicKeyBytes(), "some name")
+	assert.Nil(t, api.VerifyPointer(key.Bytes(), pointer))
+}
@@ -19,13 +19,13 @@ type Acquirer interface {
 }
 
 type acquirer struct {
-	clientID ecid.ID
+	clientID ecid.Identity
 	signer   client.Signer
 	params   *Parameters
 }
 
 // NewAcquirer creates a new Acquirer with the given clientID signer, and params.
-func NewAcquirer(clientID ecid.ID, signer client.Signer, params *Parameters) Acquirer {
+func NewAcquirer(clientID ecid.Identity, signer client.Signer, params *Parameters) Acquirer {
 	return &acquirer{
 		clientID: clientID,
 		signer:   signer,
@@ -35,6 +35,7 @@ func NewAcquirer(clientID ecid.ID, signer client.Signer, params *Parameters) Acq
 
 func (a *acquirer) Acquire(docKey id.ID, authorPub []byte, lc api.Getter) (*api.Document, error) {
 	rq := client.NewGetRequest(a.clientID, docKey)
+	rq.NMatchingValues = a.params.GetNMatchingValues
 	ctx, cancel, err := client.NewSignedTimeoutContext(a.signer, rq, a.params.GetTimeout)
 	if err != nil {
 		return nil, err
@@ -47,6 +48,9 @@ func (a *acquirer) Acquire(docKey id.ID, authorPub []byte, lc api.Getter) (*api.
 	if !bytes.Equal(rq.Metadata.RequestId, rp.Metadata.RequestId) {
 		return nil, client.ErrUnexpectedRequestID
 	}
+	if rp.Value == nil {
+		return nil, ErrDocumentNotFound
+	}
 	if err := api.ValidateDocument(rp.Value); err != nil {
 		return nil, err
 	}
@@ -94,14 +98,18 @@ type MultiStoreAcquirer interface {
 
 type multiStoreAcquirer struct {
 	inner  SingleStoreAcquirer
+	signer client.Signer
 	params *Parameters
 }
 
-// NewMultiStoreAcquirer creates a new MultiStoreAcquirer from the inner SingleStoreAcquirer and
-// params.
-func NewMultiStoreAcquirer(inner SingleStoreAcquirer, params *Parameters) MultiStoreAcquirer {
+// NewMultiStoreAcquirer creates a new MultiStoreAcquirer from the inner SingleStoreAcquirer,
+// signer for retried requests, and params.
+func NewMultiStoreAcquirer(
+	inner SingleStoreAcquirer, signer client.Signer, params *Parameters,
+) MultiStoreAcquirer {
 	return &multiStoreAcquirer{
 		inner:  inner,
+		signer: signer,
 		params: params,
 	}
 }
@@ -110,7 +118,7 @@ func (a *multiStoreAcquirer) Acquire(
 	docKeys []id.ID, authorPub []byte, cb client.GetterBalancer,
 ) error {
 
-	rlc := lclient.NewRetryGetter(cb, a.params.GetTimeout)
+	rlc := lclient.NewRetryGetter(cb, a.signer, a.params.GetTimeout)
 	docKeysChan := make(chan id.ID, a.params.PutParallelism)
 	go loadChan(docKeys, docKeysChan)
 	wg := new(sync.WaitGroup)
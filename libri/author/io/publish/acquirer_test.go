@@ -36,6 +36,13 @@ func TestAcquirer_Acquire_ok(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, actualDoc, expectedDoc)
 	assert.Equal(t, docKey.Bytes(), lc.request.Key)
+	assert.Zero(t, lc.request.NMatchingValues)
+
+	// check requested number of matching values is sent
+	params.GetNMatchingValues = 2
+	_, err = acq.Acquire(docKey, authorPub, lc)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(2), lc.request.NMatchingValues)
 }
 
 func TestAcquirer_Acquire_err(t *testing.T) {
@@ -71,6 +78,13 @@ func TestAcquirer_Acquire_err(t *testing.T) {
 	actualDoc, err = acq3.Acquire(docKey, authorPub, lc3)
 	assert.NotNil(t, err)
 	assert.Nil(t, actualDoc)
+
+	// check that missing value causes error
+	lc4 := &fixedGetter{}
+	acq4 := NewAcquirer(clientID, signer, params)
+	actualDoc, err = acq4.Acquire(docKey, authorPub, lc4)
+	assert.Equal(t, ErrDocumentNotFound, err)
+	assert.Nil(t, actualDoc)
 }
 
 func TestSingleStoreAcquirer_Acquire_ok(t *testing.T) {
@@ -127,7 +141,7 @@ func TestMultiStoreAcquirer_Acquire_ok(t *testing.T) {
 			params, err := NewParameters(DefaultPutTimeout, DefaultGetTimeout,
 				DefaultPutParallelism, getParallelism)
 			assert.Nil(t, err)
-			msAcq := NewMultiStoreAcquirer(slAcq, params)
+			msAcq := NewMultiStoreAcquirer(slAcq, &fixedSigner{}, params)
 
 			err = msAcq.Acquire(docKeys, authorKey, cb)
 			assert.Nil(t, err)
@@ -157,7 +171,7 @@ func TestMultiStoreAcquirer_Acquire_err(t *testing.T) {
 			params, err := NewParameters(DefaultPutTimeout, DefaultGetTimeout,
 				DefaultPutParallelism, getParallelism)
 			assert.Nil(t, err)
-			mlAcq := NewMultiStoreAcquirer(slAcq, params)
+			mlAcq := NewMultiStoreAcquirer(slAcq, &fixedSigner{}, params)
 
 			err = mlAcq.Acquire(docKeys, authorKey, cb)
 			assert.NotNil(t, err)
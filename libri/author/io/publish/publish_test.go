@@ -64,6 +64,21 @@ func TestPublisher_Publish_ok(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, expectedDocKey, actualDocKey)
 	assert.Equal(t, doc, lc.request.Value)
+
+	// check pointer is published under its derived key
+	pointer := api.NewTestPointer(rng)
+	doc = &api.Document{Contents: &api.Document_Pointer{Pointer: pointer}}
+	actualDocKey, err = pub.Publish(doc, pointer.AuthorPublicKey, lc)
+	assert.Nil(t, err)
+	assert.Equal(t, api.GetPointerKey(pointer.AuthorPublicKey, pointer.Name), actualDocKey)
+	assert.Equal(t, actualDocKey.Bytes(), lc.request.Key)
+	assert.Zero(t, lc.request.NReplicas)
+
+	// check requested number of replicas is sent
+	params.PutNReplicas = 5
+	_, err = pub.Publish(doc, pointer.AuthorPublicKey, lc)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(5), lc.request.NReplicas)
 }
 
 func TestPublisher_Publish_err(t *testing.T) {
@@ -196,7 +211,7 @@ func TestMultiLoadPublisher_Publish_ok(t *testing.T) {
 				params, err := NewParameters(DefaultPutTimeout, DefaultGetTimeout,
 					putParallelism, DefaultGetParallelism)
 				assert.Nil(t, err)
-				mlPub := NewMultiLoadPublisher(slPub, params)
+				mlPub := NewMultiLoadPublisher(slPub, &fixedSigner{}, params)
 
 				err = mlPub.Publish(docKeys, authorKey, cb, deleteDoc)
 				assert.Nil(t, err)
@@ -228,7 +243,7 @@ func TestMultiLoadPublisher_Publish_err(t *testing.T) {
 			params, err := NewParameters(DefaultPutTimeout, DefaultGetTimeout,
 				putParallelism, DefaultGetParallelism)
 			assert.Nil(t, err)
-			mlPub := NewMultiLoadPublisher(slPub, params)
+			mlPub := NewMultiLoadPublisher(slPub, &fixedSigner{}, params)
 
 			err = mlPub.Publish(docKeys, authorKey, cb, false)
 			assert.NotNil(t, err)
@@ -259,6 +274,7 @@ func TestMultiAcquirePublish(t *testing.T) {
 		}
 		mlP := NewMultiLoadPublisher(
 			NewSingleLoadPublisher(pubAcq, docSL1),
+			&fixedSigner{},
 			params,
 		)
 		docSL2 := &fixedDocSLD{
@@ -266,6 +282,7 @@ func TestMultiAcquirePublish(t *testing.T) {
 		}
 		msA := NewMultiStoreAcquirer(
 			NewSingleStoreAcquirer(pubAcq, docSL2),
+			&fixedSigner{},
 			params,
 		)
 		docs := make([]*api.Document, c.numDocs)
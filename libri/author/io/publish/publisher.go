@@ -37,6 +37,10 @@ var (
 	// document storer loader.
 	ErrUnexpectedMissingDocument = errors.New("unexpected missing document")
 
+	// ErrDocumentNotFound indicates when a librarian does not find a document in the libri
+	// network.
+	ErrDocumentNotFound = errors.New("document not found")
+
 	// ErrPutTimeoutZeroValue indicates when the PutTimeout parameter has the zero value.
 	ErrPutTimeoutZeroValue = errors.New("PutTimeout must be greater than zero")
 
@@ -71,6 +75,14 @@ type Parameters struct {
 	// GetParallelism is the number of simultaneous Ge requests (for different documents) that
 	// can occur.
 	GetParallelism uint32
+
+	// PutNReplicas is the number of replicas Put requests require librarians to store, or zero
+	// for the librarians' default. Librarians clamp it to their configured bounds.
+	PutNReplicas uint32
+
+	// GetNMatchingValues is the number of peers Get requests require to return the same value,
+	// or zero for the librarians' default. Librarians clamp it to their configured bounds.
+	GetNMatchingValues uint32
 }
 
 // NewParameters validates the parameters and returns a new *Parameters instance.
@@ -119,13 +131,13 @@ type Publisher interface {
 }
 
 type publisher struct {
-	clientID ecid.ID
+	clientID ecid.Identity
 	signer   client.Signer
 	params   *Parameters
 }
 
 // NewPublisher creates a new Publisher with a given client ID, signer, and params.
-func NewPublisher(clientID ecid.ID, signer client.Signer, params *Parameters) Publisher {
+func NewPublisher(clientID ecid.Identity, signer client.Signer, params *Parameters) Publisher {
 	return &publisher{
 		clientID: clientID,
 		signer:   signer,
@@ -134,7 +146,7 @@ func NewPublisher(clientID ecid.ID, signer client.Signer, params *Parameters) Pu
 }
 
 func (p *publisher) Publish(doc *api.Document, authorPub []byte, lc api.Putter) (id.ID, error) {
-	docKey, err := api.GetKey(doc)
+	docKey, err := api.GetDocumentKey(doc)
 	if err != nil {
 		return nil, err
 	}
@@ -142,6 +154,7 @@ func (p *publisher) Publish(doc *api.Document, authorPub []byte, lc api.Putter)
 		return nil, ErrInconsistentAuthorPubKey
 	}
 	rq := client.NewPutRequest(p.clientID, docKey, doc)
+	rq.NReplicas = p.params.PutNReplicas
 	ctx, cancel, err := client.NewSignedTimeoutContext(p.signer, rq, p.params.PutTimeout)
 	if err != nil {
 		return nil, err
@@ -208,13 +221,17 @@ type MultiLoadPublisher interface {
 
 type multiLoadPublisher struct {
 	inner  SingleLoadPublisher
+	signer client.Signer
 	params *Parameters
 }
 
-// NewMultiLoadPublisher creates a new MultiLoadPublisher.
-func NewMultiLoadPublisher(inner SingleLoadPublisher, params *Parameters) MultiLoadPublisher {
+// NewMultiLoadPublisher creates a new MultiLoadPublisher with a signer for retried requests.
+func NewMultiLoadPublisher(
+	inner SingleLoadPublisher, signer client.Signer, params *Parameters,
+) MultiLoadPublisher {
 	return &multiLoadPublisher{
 		inner:  inner,
+		signer: signer,
 		params: params,
 	}
 }
@@ -223,7 +240,7 @@ func (p *multiLoadPublisher) Publish(
 	docKeys []id.ID, authorPub []byte, cb client.PutterBalancer, delete bool,
 ) error {
 
-	rlc := lclient.NewRetryPutter(cb, p.params.PutTimeout)
+	rlc := lclient.NewRetryPutter(cb, p.signer, p.params.PutTimeout)
 	docKeysChan := make(chan id.ID, p.params.PutParallelism)
 	go loadChan(docKeys, docKeysChan)
 	wg := new(sync.WaitGroup)
@@ -1,6 +1,8 @@
 package ship
 
 import (
+	"errors"
+
 	"github.com/drausin/libri/libri/author/io/enc"
 	"github.com/drausin/libri/libri/author/io/publish"
 	"github.com/drausin/libri/libri/author/keychain"
@@ -11,6 +13,10 @@ import (
 	"github.com/drausin/libri/libri/librarian/client"
 )
 
+// ErrUnsignedEntry indicates when an entry does not have an author signature and unsigned
+// entries are not allowed.
+var ErrUnsignedEntry = errors.New("unsigned entry")
+
 // Receiver downloads the envelope, entry, and pages from the libri network.
 type Receiver interface {
 	// ReceiveEntry gets (from libri) the envelope, entry, and pages implied by the envelope key. It
@@ -29,23 +35,29 @@ type receiver struct {
 	acquirer   publish.Acquirer
 	msAcquirer publish.MultiStoreAcquirer
 	docS       storage.DocumentStorer
+
+	// allowUnsigned indicates whether legacy entries without author signatures are accepted
+	allowUnsigned bool
 }
 
 // NewReceiver creates a new Receiver from the librarian balancer, keychain of reader keys,
-// acquirers, and storage.DocumentStorer.
+// acquirers, and storage.DocumentStorer. Received entries must be signed by their author unless
+// allowUnsigned is true, in which case legacy entries without signatures are also accepted.
 func NewReceiver(
 	librarians client.GetterBalancer,
 	readerKeys keychain.Getter,
 	acquirer publish.Acquirer,
 	msAcquirer publish.MultiStoreAcquirer,
 	docS storage.DocumentStorer,
+	allowUnsigned bool,
 ) Receiver {
 	return &receiver{
-		librarians: librarians,
-		readerKeys: readerKeys,
-		acquirer:   acquirer,
-		msAcquirer: msAcquirer,
-		docS:       docS,
+		librarians:    librarians,
+		readerKeys:    readerKeys,
+		acquirer:      acquirer,
+		msAcquirer:    msAcquirer,
+		docS:          docS,
+		allowUnsigned: allowUnsigned,
 	}
 }
 
@@ -68,7 +80,11 @@ func (r *receiver) ReceiveEntry(envelopeKey id.ID) (*api.Document, *enc.EEK, err
 	if err != nil {
 		return nil, nil, err
 	}
-	if err := r.getPages(entryDoc, envelope.AuthorPublicKey); err != nil {
+	if err := r.checkEntry(entryDoc); err != nil {
+		return nil, nil, err
+	}
+	authorPub := entryDoc.Contents.(*api.Document_Entry).Entry.AuthorPublicKey
+	if err := r.getPages(entryDoc, authorPub); err != nil {
 		return nil, nil, err
 	}
 	return entryDoc, eek, nil
@@ -107,6 +123,22 @@ func (r *receiver) GetEEK(envelope *api.Envelope) (*enc.EEK, error) {
 	return eek, err
 }
 
+// checkEntry verifies that the entry was signed by its author. The envelope author may differ
+// from the entry author, e.g., when the entry was shared by someone who received it.
+func (r *receiver) checkEntry(entry *api.Document) error {
+	entryContents, ok := entry.Contents.(*api.Document_Entry)
+	if !ok {
+		return api.ErrUnexpectedDocumentType
+	}
+	if entryContents.Entry.AuthorSignature == nil {
+		if r.allowUnsigned {
+			return nil
+		}
+		return ErrUnsignedEntry
+	}
+	return api.VerifyEntry(entryContents.Entry)
+}
+
 func (r *receiver) getPages(entry *api.Document, authorPubBytes []byte) error {
 	if _, ok := entry.Contents.(*api.Document_Entry); !ok {
 		return api.ErrUnexpectedDocumentType
@@ -29,16 +29,12 @@ func TestReceiver_ReceiveEntry_ok(t *testing.T) {
 	cb := &fixedGetterBalancer{}
 
 	entries := []*api.Document{
-		{
-			Contents: &api.Document_Entry{
-				Entry: api.NewTestSinglePageEntry(rng),
-			},
-		},
-		{
-			Contents: &api.Document_Entry{
-				Entry: api.NewTestMultiPageEntry(rng),
-			},
-		},
+		newSignedEntryDoc(t, api.NewTestSinglePageEntry(rng), authorKey),
+		newSignedEntryDoc(t, api.NewTestMultiPageEntry(rng), authorKey),
+
+		// entry shared by someone other than its author, so its author key differs from the
+		// envelope's
+		newSignedEntryDoc(t, api.NewTestSinglePageEntry(rng), ecid.NewPseudoRandom(rng)),
 	}
 	for _, entry1 := range entries {
 		pageKeys, err := api.GetEntryPageKeys(entry1)
@@ -64,7 +60,7 @@ func TestReceiver_ReceiveEntry_ok(t *testing.T) {
 		acq.docs[envelopeKey.String()] = envelope
 		msAcq := &fixedMultiStoreAcquirer{}
 		docS := &fixedStorer{}
-		r := NewReceiver(cb, readerKeys, acq, msAcq, docS)
+		r := NewReceiver(cb, readerKeys, acq, msAcq, docS, false)
 
 		entry2, eek2, err := r.ReceiveEntry(envelopeKey)
 		assert.Nil(t, err)
@@ -118,7 +114,7 @@ func TestReceiver_ReceiveEntry_err(t *testing.T) {
 
 	// check clientBalancer.Next() error bubbles up
 	cb1 := &fixedGetterBalancer{err: errors.New("some Next error")}
-	r1 := NewReceiver(cb1, readerKeys, acq, msAcq, docS)
+	r1 := NewReceiver(cb1, readerKeys, acq, msAcq, docS, false)
 	receivedDoc, receivedKeys, err := r1.ReceiveEntry(envelopeKey)
 	assert.NotNil(t, err)
 	assert.Nil(t, receivedDoc)
@@ -126,7 +122,7 @@ func TestReceiver_ReceiveEntry_err(t *testing.T) {
 
 	// check acquire error bubbles up
 	acq2 := &fixedAcquirer{err: errors.New("some Acquire error")}
-	r2 := NewReceiver(cb, readerKeys, acq2, msAcq, docS)
+	r2 := NewReceiver(cb, readerKeys, acq2, msAcq, docS, false)
 	receivedDoc, receivedKeys, err = r2.ReceiveEntry(envelopeKey)
 	assert.NotNil(t, err)
 	assert.Nil(t, receivedDoc)
@@ -135,7 +131,7 @@ func TestReceiver_ReceiveEntry_err(t *testing.T) {
 	// check wrong doc type error bubbles up
 	acq3 := &fixedAcquirer{docs: make(map[string]*api.Document)}
 	acq3.docs[envelopeKey.String()] = entry // wrong doc type
-	r3 := NewReceiver(cb, readerKeys, acq3, msAcq, docS)
+	r3 := NewReceiver(cb, readerKeys, acq3, msAcq, docS, false)
 	receivedDoc, receivedKeys, err = r3.ReceiveEntry(envelopeKey)
 	assert.NotNil(t, err)
 	assert.Nil(t, receivedDoc)
@@ -146,7 +142,7 @@ func TestReceiver_ReceiveEntry_err(t *testing.T) {
 	// readerKeys4 will cause GetEEK to fail b/c can't find readerKey
 	// in the different keychain
 	readerKeys4 := keychain.New(1)
-	r4 := NewReceiver(cb, readerKeys4, acq, msAcq, docS)
+	r4 := NewReceiver(cb, readerKeys4, acq, msAcq, docS, false)
 	receivedDoc, receivedKeys, err = r4.ReceiveEntry(envelopeKey)
 	assert.NotNil(t, err)
 	assert.Nil(t, receivedDoc)
@@ -157,7 +153,7 @@ func TestReceiver_ReceiveEntry_err(t *testing.T) {
 	// acq5 doesn't have entryKey, which will trigger error
 	acq5 := &fixedAcquirer{docs: make(map[string]*api.Document)}
 	acq5.docs[envelopeKey.String()] = envelope
-	r5 := NewReceiver(cb, readerKeys, acq5, msAcq, docS)
+	r5 := NewReceiver(cb, readerKeys, acq5, msAcq, docS, false)
 	receivedDoc, receivedKeys, err = r5.ReceiveEntry(envelopeKey)
 	assert.NotNil(t, err)
 	assert.Nil(t, receivedDoc)
@@ -167,11 +163,79 @@ func TestReceiver_ReceiveEntry_err(t *testing.T) {
 	acq6 := &fixedAcquirer{docs: make(map[string]*api.Document)}
 	acq6.docs[envelopeKey.String()] = envelope
 	acq6.docs[entryKey.String()] = envelope // wrong doc type
-	r6 := NewReceiver(cb, readerKeys, acq6, msAcq, docS)
+	r6 := NewReceiver(cb, readerKeys, acq6, msAcq, docS, false)
 	receivedDoc, receivedKeys, err = r6.ReceiveEntry(envelopeKey)
 	assert.NotNil(t, err)
 	assert.Nil(t, receivedDoc)
 	assert.Nil(t, receivedKeys)
+
+	// check unsigned entry triggers error
+	unsignedEntry := api.NewTestSinglePageEntry(rng)
+	unsignedEntry.AuthorPublicKey = authorKey.PublicKeyBytes()
+	acq8 := &fixedAcquirer{docs: make(map[string]*api.Document)}
+	acq8.docs[envelopeKey.String()] = envelope
+	acq8.docs[entryKey.String()] = &api.Document{
+		Contents: &api.Document_Entry{Entry: unsignedEntry},
+	}
+	r8 := NewReceiver(cb, readerKeys, acq8, msAcq, docS, false)
+	receivedDoc, receivedKeys, err = r8.ReceiveEntry(envelopeKey)
+	assert.Equal(t, ErrUnsignedEntry, err)
+	assert.Nil(t, receivedDoc)
+	assert.Nil(t, receivedKeys)
+
+	// check entry signed by different key triggers error
+	otherKey := ecid.NewPseudoRandom(rng)
+	badlySignedEntry := newSignedEntryDoc(t, api.NewTestSinglePageEntry(rng), otherKey)
+	badlySignedEntry.Contents.(*api.Document_Entry).Entry.AuthorPublicKey =
+		authorKey.PublicKeyBytes()
+	acq9 := &fixedAcquirer{docs: make(map[string]*api.Document)}
+	acq9.docs[envelopeKey.String()] = envelope
+	acq9.docs[entryKey.String()] = badlySignedEntry
+	r9 := NewReceiver(cb, readerKeys, acq9, msAcq, docS, false)
+	receivedDoc, receivedKeys, err = r9.ReceiveEntry(envelopeKey)
+	assert.Equal(t, api.ErrInvalidSignature, err)
+	assert.Nil(t, receivedDoc)
+	assert.Nil(t, receivedKeys)
+}
+
+func TestReceiver_ReceiveEntry_allowUnsigned(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	authorKeys, readerKeys := keychain.New(1), keychain.New(1)
+	authorKey, err := authorKeys.Sample()
+	assert.Nil(t, err)
+	readerKey, err := readerKeys.Sample()
+	assert.Nil(t, err)
+	kek, err := enc.NewKEK(authorKey.Key(), &readerKey.Key().PublicKey)
+	assert.Nil(t, err)
+
+	// legacy entry without author signature
+	entry := api.NewTestSinglePageEntry(rng)
+	entry.AuthorPublicKey = authorKey.PublicKeyBytes()
+	entry.Contents.(*api.Entry_Page).Page.AuthorPublicKey = entry.AuthorPublicKey
+	entryDoc := &api.Document{Contents: &api.Document_Entry{Entry: entry}}
+	entryKey, err := api.GetKey(entryDoc)
+	assert.Nil(t, err)
+	eekCiphertext, eekCiphertextMAC, err := kek.Encrypt(enc.NewPseudoRandomEEK(rng))
+	assert.Nil(t, err)
+	envelope := pack.NewEnvelopeDoc(
+		entryKey,
+		authorKey.PublicKeyBytes(),
+		readerKey.PublicKeyBytes(),
+		eekCiphertext,
+		eekCiphertextMAC,
+	)
+	envelopeKey, err := api.GetKey(envelope)
+	assert.Nil(t, err)
+	acq := &fixedAcquirer{docs: make(map[string]*api.Document)}
+	acq.docs[entryKey.String()] = entryDoc
+	acq.docs[envelopeKey.String()] = envelope
+	r := NewReceiver(&fixedGetterBalancer{}, readerKeys, acq, &fixedMultiStoreAcquirer{},
+		&fixedStorer{}, true)
+
+	receivedEntry, eek, err := r.ReceiveEntry(envelopeKey)
+	assert.Nil(t, err)
+	assert.Equal(t, entryDoc, receivedEntry)
+	assert.NotNil(t, eek)
 }
 
 func TestReceiver_GetEEK_err(t *testing.T) {
@@ -183,7 +247,7 @@ func TestReceiver_GetEEK_err(t *testing.T) {
 
 	// check readerKeys.Get() error bubbles up
 	readerKeys1 := &fixedKeychain{in: false}
-	r1 := NewReceiver(cb, readerKeys1, acq, msAcq, docS).(*receiver)
+	r1 := NewReceiver(cb, readerKeys1, acq, msAcq, docS, false).(*receiver)
 	env1 := &api.Envelope{}
 	eek, err := r1.GetEEK(env1)
 	assert.Equal(t, keychain.ErrUnexpectedMissingKey, err)
@@ -191,7 +255,7 @@ func TestReceiver_GetEEK_err(t *testing.T) {
 
 	// check ecid.FromPublicKeyButes error bubbles up
 	readerKeys2 := &fixedKeychain{in: true} // allows us to not err on readerKeys.Get()
-	r2 := NewReceiver(cb, readerKeys2, acq, msAcq, docS).(*receiver)
+	r2 := NewReceiver(cb, readerKeys2, acq, msAcq, docS, false).(*receiver)
 	env2 := &api.Envelope{
 		AuthorPublicKey: api.RandBytes(rng, 16), // bad authorPubBytes
 	}
@@ -210,7 +274,7 @@ func TestReceiver_GetEEK_err(t *testing.T) {
 	env3 := &api.Envelope{
 		AuthorPublicKey: wrongCurveKeyPubBytes,
 	}
-	r3 := NewReceiver(cb, readerKeys3, acq, msAcq, docS).(*receiver)
+	r3 := NewReceiver(cb, readerKeys3, acq, msAcq, docS, false).(*receiver)
 	eek, err = r3.GetEEK(env3)
 	assert.Equal(t, ecid.ErrKeyPointOffCurve, err)
 	assert.Nil(t, eek)
@@ -227,12 +291,22 @@ func TestReceiver_GetEEK_err(t *testing.T) {
 		EekCiphertext:    api.RandBytes(rng, api.EEKLength),
 		EekCiphertextMac: api.RandBytes(rng, api.HMAC256Length), // does't match ciphertext
 	}
-	r4 := NewReceiver(cb, readerKeys4, acq, msAcq, docS).(*receiver)
+	r4 := NewReceiver(cb, readerKeys4, acq, msAcq, docS, false).(*receiver)
 	eek, err = r4.GetEEK(env4)
 	assert.Equal(t, enc.ErrUnexpectedCiphertextMAC, err)
 	assert.Nil(t, eek)
 }
 
+func newSignedEntryDoc(t *testing.T, entry *api.Entry, authorKey ecid.ID) *api.Document {
+	entry.AuthorPublicKey = authorKey.PublicKeyBytes()
+	if page := entry.GetPage(); page != nil {
+		page.AuthorPublicKey = entry.AuthorPublicKey
+	}
+	err := api.SignEntry(entry, authorKey.Key())
+	assert.Nil(t, err)
+	return &api.Document{Contents: &api.Document_Entry{Entry: entry}}
+}
+
 type fixedAcquirer struct {
 	docs map[string]*api.Document
 	err  error
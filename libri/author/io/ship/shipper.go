@@ -1,6 +1,9 @@
 package ship
 
 import (
+	"errors"
+	"sync"
+
 	"github.com/drausin/libri/libri/author/io/enc"
 	"github.com/drausin/libri/libri/author/io/pack"
 	"github.com/drausin/libri/libri/author/io/publish"
@@ -9,6 +12,10 @@ import (
 	"github.com/drausin/libri/libri/librarian/client"
 )
 
+// ErrInconsistentReaderKeys indicates when the number of KEKs differs from the number of reader
+// public keys.
+var ErrInconsistentReaderKeys = errors.New("inconsistent number of KEKs and reader public keys")
+
 // Shipper publishes documents to libri.
 type Shipper interface {
 	// ShipEntry publishes (to libri) the entry document, its page document keys (if more than one),
@@ -20,24 +27,34 @@ type Shipper interface {
 
 	ShipEnvelope(kek *enc.KEK, eek *enc.EEK, entryKey id.ID, authorPub, readerPub []byte) (
 		*api.Document, id.ID, error)
+
+	// ShipEnvelopes publishes (to libri) an envelope for the entry for each reader public key,
+	// using the KEK at the same index. Envelopes are published in parallel. It returns the
+	// published envelope documents and their keys in the same order as the reader public keys.
+	ShipEnvelopes(keks []*enc.KEK, eek *enc.EEK, entryKey id.ID, authorPub []byte,
+		readerPubs [][]byte) ([]*api.Document, []id.ID, error)
 }
 
 type shipper struct {
 	librarians  client.PutterBalancer
 	publisher   publish.Publisher
 	mlPublisher publish.MultiLoadPublisher
+	params      *publish.Parameters
 	deletePages bool
 }
 
-// NewShipper creates a new Shipper from a librarian api.Balancer and two publisher variants.
+// NewShipper creates a new Shipper from a librarian api.Balancer, two publisher variants, and
+// publish parameters.
 func NewShipper(
 	librarians client.PutterBalancer,
 	publisher publish.Publisher,
-	mlPublisher publish.MultiLoadPublisher) Shipper {
+	mlPublisher publish.MultiLoadPublisher,
+	params *publish.Parameters) Shipper {
 	return &shipper{
 		librarians:  librarians,
 		publisher:   publisher,
 		mlPublisher: mlPublisher,
+		params:      params,
 		deletePages: true,
 	}
 }
@@ -88,3 +105,53 @@ func (s *shipper) ShipEnvelope(
 	}
 	return envelope, envelopeKey, nil
 }
+
+func (s *shipper) ShipEnvelopes(
+	keks []*enc.KEK, eek *enc.EEK, entryKey id.ID, authorPub []byte, readerPubs [][]byte,
+) ([]*api.Document, []id.ID, error) {
+
+	if len(keks) != len(readerPubs) {
+		return nil, nil, ErrInconsistentReaderKeys
+	}
+	if s.params.PutParallelism == 0 {
+		// no workers would ship the envelopes
+		return nil, nil, publish.ErrPutParallelismZeroValue
+	}
+	envelopes := make([]*api.Document, len(readerPubs))
+	envelopeKeys := make([]id.ID, len(readerPubs))
+	idxs := make(chan int, s.params.PutParallelism)
+	go func() {
+		for i := range readerPubs {
+			idxs <- i
+		}
+		close(idxs)
+	}()
+	wg := new(sync.WaitGroup)
+	shipErrs := make(chan error, s.params.PutParallelism)
+	for c := uint32(0); c < s.params.PutParallelism; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var err error
+			for i := range idxs {
+				if err != nil {
+					// drain remaining indices after an error
+					continue
+				}
+				envelopes[i], envelopeKeys[i], err = s.ShipEnvelope(keks[i], eek, entryKey,
+					authorPub, readerPubs[i])
+			}
+			if err != nil {
+				shipErrs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(shipErrs)
+
+	// receiving from the closed channel yields nil when no errors occurred
+	if err := <-shipErrs; err != nil {
+		return nil, nil, err
+	}
+	return envelopes, envelopeKeys, nil
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/drausin/libri/libri/author/io/enc"
 	"github.com/drausin/libri/libri/author/io/publish"
 	"github.com/drausin/libri/libri/author/keychain"
+	"github.com/drausin/libri/libri/common/ecid"
 	"github.com/drausin/libri/libri/common/id"
 	"github.com/drausin/libri/libri/librarian/api"
 	"github.com/drausin/libri/libri/librarian/client"
@@ -24,6 +25,7 @@ func TestShipper_Ship_ok(t *testing.T) {
 		&fixedPutterBalancer{},
 		&fixedPublisher{},
 		mlPub,
+		publish.NewDefaultParameters(),
 	)
 	entry := &api.Document{
 		Contents: &api.Document_Entry{
@@ -72,6 +74,7 @@ func TestShipper_Ship_err(t *testing.T) {
 		&fixedPutterBalancer{},
 		&fixedPublisher{},
 		&fixedMultiLoadPublisher{err: errors.New("some Publish error")},
+		publish.NewDefaultParameters(),
 	)
 
 	// check GetEntryPageKeys error bubbles up
@@ -96,6 +99,7 @@ func TestShipper_Ship_err(t *testing.T) {
 		&fixedPutterBalancer{err: errors.New("some Next error")},
 		&fixedPublisher{},
 		&fixedMultiLoadPublisher{},
+		publish.NewDefaultParameters(),
 	)
 	envelope, entryKey, err = s.ShipEntry(entry, authorPub, readerPub, kek, eek)
 	assert.NotNil(t, err)
@@ -107,6 +111,7 @@ func TestShipper_Ship_err(t *testing.T) {
 		&fixedPutterBalancer{},
 		&fixedPublisher{[]error{errors.New("some Publish error")}},
 		&fixedMultiLoadPublisher{},
+		publish.NewDefaultParameters(),
 	)
 	envelope, entryKey, err = s.ShipEntry(entry, authorPub, readerPub, kek, eek)
 	assert.NotNil(t, err)
@@ -118,6 +123,7 @@ func TestShipper_Ship_err(t *testing.T) {
 		&fixedPutterBalancer{},
 		&fixedPublisher{},
 		&fixedMultiLoadPublisher{},
+		publish.NewDefaultParameters(),
 	)
 	envelope, entryKey, err = s.ShipEntry(entry, authorPub, readerPub, &enc.KEK{}, eek)
 	assert.NotNil(t, err)
@@ -129,6 +135,7 @@ func TestShipper_Ship_err(t *testing.T) {
 		&fixedPutterBalancer{},
 		&fixedPublisher{[]error{nil, errors.New("some Publish error")}},
 		&fixedMultiLoadPublisher{},
+		publish.NewDefaultParameters(),
 	)
 	envelope, entryKey, err = s.ShipEntry(entry, authorPub, readerPub, kek, eek)
 	assert.NotNil(t, err)
@@ -136,6 +143,101 @@ func TestShipper_Ship_err(t *testing.T) {
 	assert.Nil(t, entryKey)
 }
 
+func TestShipper_ShipEnvelopes_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	eek := enc.NewPseudoRandomEEK(rng)
+	entryKey := id.NewPseudoRandom(rng)
+	for _, nReaders := range []int{1, 3, 8} {
+		for _, putParallelism := range []uint32{1, 2, 3} {
+			keks := make([]*enc.KEK, nReaders)
+			readerPubs := make([][]byte, nReaders)
+			var authorPub []byte
+			for i := range keks {
+				keks[i], authorPub, readerPubs[i] = enc.NewPseudoRandomKEK(rng)
+			}
+			params, err := publish.NewParameters(publish.DefaultPutTimeout,
+				publish.DefaultGetTimeout, putParallelism, publish.DefaultGetParallelism)
+			assert.Nil(t, err)
+			s := NewShipper(
+				&fixedPutterBalancer{},
+				&fixedPublisher{},
+				&fixedMultiLoadPublisher{},
+				params,
+			)
+
+			envelopes, envelopeKeys, err := s.ShipEnvelopes(keks, eek, entryKey, authorPub,
+				readerPubs)
+			assert.Nil(t, err)
+			assert.Len(t, envelopes, nReaders)
+			assert.Len(t, envelopeKeys, nReaders)
+			for i, envelope := range envelopes {
+				env := envelope.Contents.(*api.Document_Envelope).Envelope
+				assert.Equal(t, entryKey.Bytes(), env.EntryKey)
+				assert.Equal(t, authorPub, env.AuthorPublicKey)
+				assert.Equal(t, readerPubs[i], env.ReaderPublicKey)
+				envelopeKey, err := api.GetKey(envelope)
+				assert.Nil(t, err)
+				assert.Equal(t, envelopeKey, envelopeKeys[i])
+			}
+		}
+	}
+}
+
+func TestShipper_ShipEnvelopes_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	eek := enc.NewPseudoRandomEEK(rng)
+	entryKey := id.NewPseudoRandom(rng)
+	kek1, authorPub, readerPub1 := enc.NewPseudoRandomKEK(rng)
+	kek2, _, readerPub2 := enc.NewPseudoRandomKEK(rng)
+	keks, readerPubs := []*enc.KEK{kek1, kek2}, [][]byte{readerPub1, readerPub2}
+	params, err := publish.NewParameters(publish.DefaultPutTimeout, publish.DefaultGetTimeout,
+		1, publish.DefaultGetParallelism)
+	assert.Nil(t, err)
+
+	// check inconsistent KEKs and reader keys triggers error
+	s := NewShipper(&fixedPutterBalancer{}, &fixedPublisher{}, &fixedMultiLoadPublisher{},
+		params)
+	envelopes, envelopeKeys, err := s.ShipEnvelopes(keks[:1], eek, entryKey, authorPub,
+		readerPubs)
+	assert.Equal(t, ErrInconsistentReaderKeys, err)
+	assert.Nil(t, envelopes)
+	assert.Nil(t, envelopeKeys)
+
+	// check zero put parallelism triggers error
+	zeroParams := *params
+	zeroParams.PutParallelism = 0
+	s = NewShipper(&fixedPutterBalancer{}, &fixedPublisher{}, &fixedMultiLoadPublisher{},
+		&zeroParams)
+	envelopes, envelopeKeys, err = s.ShipEnvelopes(keks, eek, entryKey, authorPub, readerPubs)
+	assert.Equal(t, publish.ErrPutParallelismZeroValue, err)
+	assert.Nil(t, envelopes)
+	assert.Nil(t, envelopeKeys)
+
+	// check getting next librarian error bubbles up
+	s = NewShipper(
+		&fixedPutterBalancer{err: errors.New("some Next error")},
+		&fixedPublisher{},
+		&fixedMultiLoadPublisher{},
+		params,
+	)
+	envelopes, envelopeKeys, err = s.ShipEnvelopes(keks, eek, entryKey, authorPub, readerPubs)
+	assert.NotNil(t, err)
+	assert.Nil(t, envelopes)
+	assert.Nil(t, envelopeKeys)
+
+	// check envelope publish error bubbles up
+	s = NewShipper(
+		&fixedPutterBalancer{},
+		&fixedPublisher{[]error{nil, errors.New("some Publish error")}},
+		&fixedMultiLoadPublisher{},
+		params,
+	)
+	envelopes, envelopeKeys, err = s.ShipEnvelopes(keks, eek, entryKey, authorPub, readerPubs)
+	assert.NotNil(t, err)
+	assert.Nil(t, envelopes)
+	assert.Nil(t, envelopeKeys)
+}
+
 func TestShipReceive(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	getterBalancer := &fixedGetterBalancer{}
@@ -175,6 +277,8 @@ func TestShipReceive(t *testing.T) {
 				entry.Contents.(*api.Entry_PageKeys).PageKeys.Keys = pageKeys
 			}
 			entry.AuthorPublicKey = authorPub
+			err = api.SignEntry(entry, authorKey.Key())
+			assert.Nil(t, err)
 			docs[i] = &api.Document{
 				Contents: &api.Document_Entry{
 					Entry: entry,
@@ -196,9 +300,10 @@ func TestShipReceive(t *testing.T) {
 		}
 		mlP := publish.NewMultiLoadPublisher(
 			publish.NewSingleLoadPublisher(pubAcq, docSL1),
+			client.NewSigner(ecid.NewPseudoRandom(rng).Key()),
 			params,
 		)
-		s := NewShipper(putterBalancer, pubAcq, mlP).(*shipper)
+		s := NewShipper(putterBalancer, pubAcq, mlP, params).(*shipper)
 		s.deletePages = false // so we can check them at the end
 		eek := enc.NewPseudoRandomEEK(rng)
 		envelopeKeys := make([]id.ID, nDocs)
@@ -215,9 +320,10 @@ func TestShipReceive(t *testing.T) {
 		}
 		msA := publish.NewMultiStoreAcquirer(
 			publish.NewSingleStoreAcquirer(pubAcq, docSL2),
+			client.NewSigner(ecid.NewPseudoRandom(rng).Key()),
 			params,
 		)
-		r := NewReceiver(getterBalancer, readerKeys, pubAcq, msA, docSL2)
+		r := NewReceiver(getterBalancer, readerKeys, pubAcq, msA, docSL2, false)
 		for i := uint32(0); i < nDocs; i++ {
 			entry, _, err := r.ReceiveEntry(envelopeKeys[i])
 			assert.Equal(t, docs[i], entry)
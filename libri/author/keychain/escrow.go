@@ -0,0 +1,234 @@
+package keychain
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"strings"
+
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/shamir"
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// privateKeyLength is the length of a serialized private key.
+	privateKeyLength = 32
+
+	// shareSaltLength is the length of the scrypt salt when encrypting a share.
+	shareSaltLength = 32
+
+	// shareKeyLength is the length of the AES-256 key encrypting a share.
+	shareKeyLength = 32
+
+	// scryptR is the scrypt r (block size) parameter.
+	scryptR = 8
+)
+
+var (
+	// ErrTooFewShares indicates when fewer shares than the threshold are given to reconstruct a
+	// keychain.
+	ErrTooFewShares = errors.New("too few shares to reconstruct keychain")
+
+	// ErrInconsistentShares indicates when shares given to reconstruct a keychain come from
+	// different escrows.
+	ErrInconsistentShares = errors.New("shares are from different keychain escrows")
+
+	// ErrInvalidShares indicates when shares do not reconstruct the keychain they were split from.
+	ErrInvalidShares = errors.New("shares do not reconstruct keychain")
+)
+
+// Escrow splits the private keys of the keychain into nShares Shamir shares, any threshold of
+// which can reconstruct the keychain.
+func Escrow(kc Keychain, threshold, nShares int) ([]*KeychainShare, error) {
+	keys := kc.Keys()
+	if len(keys) == 0 {
+		return nil, ErrEmptyKeychain
+	}
+	secret := make([]byte, 0, len(keys)*privateKeyLength)
+	for _, key := range keys {
+		secret = append(secret, toPrivateKeyBytes(key)...)
+	}
+	rawShares, err := shamir.Split(secret, nShares, threshold, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	keychainHash := sha256.Sum256(secret)
+	shares := make([]*KeychainShare, nShares)
+	for i, rawShare := range rawShares {
+		shares[i] = &KeychainShare{
+			Threshold:    uint32(threshold),
+			NShares:      uint32(nShares),
+			Share:        rawShare,
+			KeychainHash: keychainHash[:],
+		}
+	}
+	return shares, nil
+}
+
+// Reconstruct rebuilds a keychain from at least the threshold number of its shares.
+func Reconstruct(shares []*KeychainShare) (Keychain, error) {
+	if len(shares) == 0 {
+		return nil, ErrTooFewShares
+	}
+	first := shares[0]
+	rawShares := make([][]byte, len(shares))
+	for i, share := range shares {
+		if share.Threshold != first.Threshold || share.NShares != first.NShares ||
+			!bytes.Equal(share.KeychainHash, first.KeychainHash) {
+			return nil, ErrInconsistentShares
+		}
+		rawShares[i] = share.Share
+	}
+	if len(shares) < int(first.Threshold) {
+		return nil, ErrTooFewShares
+	}
+	secret, err := shamir.Combine(rawShares)
+	if err != nil {
+		return nil, err
+	}
+	if keychainHash := sha256.Sum256(secret); !bytes.Equal(keychainHash[:], first.KeychainHash) {
+		return nil, ErrInvalidShares
+	}
+	ecids := make([]ecid.ID, len(secret)/privateKeyLength)
+	for i := range ecids {
+		ecids[i] = fromPrivateKeyBytes(secret[i*privateKeyLength : (i+1)*privateKeyLength])
+	}
+	return FromECIDs(ecids), nil
+}
+
+// EncryptShare encrypts a share with AES-256-GCM using a key derived from the authentication
+// passphrase with the given scrypt difficulty parameters.
+func EncryptShare(share *KeychainShare, auth string, scryptN, scryptP int) (
+	*EncryptedKeychainShare, error) {
+	shareBytes, err := proto.Marshal(share)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, shareSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	gcm, err := newShareGCM(auth, salt, scryptN, scryptP)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return &EncryptedKeychainShare{
+		Salt:       salt,
+		ScryptN:    uint32(scryptN),
+		ScryptP:    uint32(scryptP),
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, shareBytes, nil),
+	}, nil
+}
+
+// DecryptShare decrypts a share encrypted with the authentication passphrase.
+func DecryptShare(encrypted *EncryptedKeychainShare, auth string) (*KeychainShare, error) {
+	gcm, err := newShareGCM(auth, encrypted.Salt, int(encrypted.ScryptN),
+		int(encrypted.ScryptP))
+	if err != nil {
+		return nil, err
+	}
+	if len(encrypted.Nonce) != gcm.NonceSize() {
+		return nil, ErrInvalidShares
+	}
+	shareBytes, err := gcm.Open(nil, encrypted.Nonce, encrypted.Ciphertext, nil)
+	if err != nil {
+		return nil, err
+	}
+	share := &KeychainShare{}
+	if err := proto.Unmarshal(shareBytes, share); err != nil {
+		return nil, err
+	}
+	return share, nil
+}
+
+// SaveShare encrypts and saves a share to a file.
+func SaveShare(filepath, auth string, share *KeychainShare, scryptN, scryptP int) error {
+	encrypted, err := EncryptShare(share, auth, scryptN, scryptP)
+	if err != nil {
+		return err
+	}
+	buf, err := proto.Marshal(encrypted)
+	if err != nil {
+		return err
+	}
+	const filePerm = 0600 // only user can read
+	return ioutil.WriteFile(filepath, buf, filePerm)
+}
+
+// LoadShare loads and decrypts a share from a file.
+func LoadShare(filepath, auth string) (*KeychainShare, error) {
+	buf, err := ioutil.ReadFile(filepath)
+	if err != nil {
+		return nil, err
+	}
+	encrypted := &EncryptedKeychainShare{}
+	if err := proto.Unmarshal(buf, encrypted); err != nil {
+		return nil, err
+	}
+	return DecryptShare(encrypted, auth)
+}
+
+// ShareToText encodes an (unencrypted) share as printable hex text.
+func ShareToText(share *KeychainShare) (string, error) {
+	shareBytes, err := proto.Marshal(share)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(shareBytes), nil
+}
+
+// ShareFromText decodes a share from the printable hex text given by ShareToText, ignoring any
+// whitespace.
+func ShareFromText(text string) (*KeychainShare, error) {
+	shareBytes, err := hex.DecodeString(strings.Join(strings.Fields(text), ""))
+	if err != nil {
+		return nil, err
+	}
+	share := &KeychainShare{}
+	if err := proto.Unmarshal(shareBytes, share); err != nil {
+		return nil, err
+	}
+	return share, nil
+}
+
+func newShareGCM(auth string, salt []byte, scryptN, scryptP int) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(auth), salt, scryptN, scryptR, scryptP, shareKeyLength)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// toPrivateKeyBytes returns the 32-byte big-endian private key.
+func toPrivateKeyBytes(key ecid.ID) []byte {
+	priv := make([]byte, privateKeyLength)
+	d := key.Key().D.Bytes()
+	copy(priv[privateKeyLength-len(d):], d)
+	return priv
+}
+
+// fromPrivateKeyBytes creates a key from its 32-byte big-endian private key.
+func fromPrivateKeyBytes(d []byte) ecid.ID {
+	priv := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(d)}
+	priv.PublicKey.Curve = ecid.Curve
+	priv.PublicKey.X, priv.PublicKey.Y = ecid.Curve.ScalarBaseMult(d)
+	return ecid.FromPrivateKey(priv)
+}
@@ -0,0 +1,137 @@
+package keychain
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/drausin/libri/libri/common/shamir"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscrowReconstruct_ok(t *testing.T) {
+	kc1 := New(4)
+	shares, err := Escrow(kc1, 3, 5)
+	assert.Nil(t, err)
+	assert.Len(t, shares, 5)
+	for _, share := range shares {
+		assert.Equal(t, uint32(3), share.Threshold)
+		assert.Equal(t, uint32(5), share.NShares)
+		assert.Len(t, share.Share, 4*privateKeyLength+1)
+	}
+
+	// check different subsets of threshold shares reconstruct keychain
+	for _, subset := range [][]*KeychainShare{shares[:3], shares[2:], shares[:5]} {
+		kc2, err := Reconstruct(subset)
+		assert.Nil(t, err)
+		assert.Equal(t, kc1, kc2)
+	}
+}
+
+func TestEscrow_err(t *testing.T) {
+	shares, err := Escrow(New(0), 2, 3)
+	assert.Equal(t, ErrEmptyKeychain, err)
+	assert.Nil(t, shares)
+
+	shares, err = Escrow(New(2), 4, 3)
+	assert.Equal(t, shamir.ErrInvalidThreshold, err)
+	assert.Nil(t, shares)
+}
+
+func TestReconstruct_err(t *testing.T) {
+	shares1, err := Escrow(New(2), 3, 5)
+	assert.Nil(t, err)
+	shares2, err := Escrow(New(2), 3, 5)
+	assert.Nil(t, err)
+
+	kc, err := Reconstruct(nil)
+	assert.Equal(t, ErrTooFewShares, err)
+	assert.Nil(t, kc)
+
+	kc, err = Reconstruct(shares1[:2])
+	assert.Equal(t, ErrTooFewShares, err)
+	assert.Nil(t, kc)
+
+	kc, err = Reconstruct([]*KeychainShare{shares1[0], shares1[1], shares2[2]})
+	assert.Equal(t, ErrInconsistentShares, err)
+	assert.Nil(t, kc)
+
+	// check duplicate share error from Combine bubbles up
+	kc, err = Reconstruct([]*KeychainShare{shares1[0], shares1[1], shares1[1]})
+	assert.Equal(t, shamir.ErrInconsistentShares, err)
+	assert.Nil(t, kc)
+
+	// check corrupted share triggers error
+	shares1[0].Share[0]++
+	kc, err = Reconstruct(shares1[:3])
+	assert.Equal(t, ErrInvalidShares, err)
+	assert.Nil(t, kc)
+}
+
+func TestEncryptDecryptShare(t *testing.T) {
+	shares, err := Escrow(New(2), 2, 3)
+	assert.Nil(t, err)
+	auth := "test passphrase"
+
+	encrypted, err := EncryptShare(shares[0], auth, veryLightScryptN, veryLightScryptP)
+	assert.Nil(t, err)
+
+	share, err := DecryptShare(encrypted, auth)
+	assert.Nil(t, err)
+	assert.Equal(t, shares[0], share)
+
+	// check wrong passphrase triggers error
+	share, err = DecryptShare(encrypted, "wrong passphrase")
+	assert.NotNil(t, err)
+	assert.Nil(t, share)
+
+	// check bad scrypt params trigger error
+	encrypted, err = EncryptShare(shares[0], auth, -1, -1)
+	assert.NotNil(t, err)
+	assert.Nil(t, encrypted)
+}
+
+func TestSaveLoadShare(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keychain-test")
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+	assert.Nil(t, err)
+	shares, err := Escrow(New(2), 2, 3)
+	assert.Nil(t, err)
+	auth, filepath := "test passphrase", path.Join(dir, "share")
+
+	err = SaveShare(filepath, auth, shares[1], veryLightScryptN, veryLightScryptP)
+	assert.Nil(t, err)
+	share, err := LoadShare(filepath, auth)
+	assert.Nil(t, err)
+	assert.Equal(t, shares[1], share)
+
+	// check missing file triggers error
+	share, err = LoadShare(filepath+"-missing", auth)
+	assert.NotNil(t, err)
+	assert.Nil(t, share)
+
+	// check bad file triggers error
+	err = ioutil.WriteFile(filepath, []byte("not a share"), 0600)
+	assert.Nil(t, err)
+	share, err = LoadShare(filepath, auth)
+	assert.NotNil(t, err)
+	assert.Nil(t, share)
+}
+
+func TestShareToFromText(t *testing.T) {
+	shares, err := Escrow(New(2), 2, 3)
+	assert.Nil(t, err)
+
+	text, err := ShareToText(shares[2])
+	assert.Nil(t, err)
+
+	// check whitespace (e.g., from transcribing) is ignored
+	share, err := ShareFromText(text[:10] + " \n" + text[10:] + "\n")
+	assert.Nil(t, err)
+	assert.Equal(t, shares[2], share)
+
+	share, err = ShareFromText("not hex")
+	assert.NotNil(t, err)
+	assert.Nil(t, share)
+}
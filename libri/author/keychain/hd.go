@@ -0,0 +1,133 @@
+package keychain
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/drausin/libri/libri/common/ecid"
+)
+
+const (
+	// HDPurpose is the (hardened) BIP-43 purpose level of keychain derivation paths.
+	HDPurpose = 44
+
+	// HDCoinType is the (hardened) coin type level of keychain derivation paths, "libr" in ASCII.
+	HDCoinType = 0x6c696272
+
+	// hardenedOffset is added to a child index to derive a hardened child.
+	hardenedOffset = 1 << 31
+
+	// hdKeyLength is the length of an extended private key and chain code.
+	hdKeyLength = 32
+)
+
+var (
+	// errInvalidHDKey indicates when a derived key is zero or not less than the curve order, which
+	// happens with probability less than 2^-127.
+	errInvalidHDKey = errors.New("invalid derived key")
+
+	// errInvalidHDIndex indicates when a child index is too large to be hardened.
+	errInvalidHDIndex = errors.New("child index must be less than 2^31")
+
+	masterKeySalt = []byte("Bitcoin seed")
+)
+
+// FromMnemonic creates a Keychain with n keys deterministically derived from the BIP-39 mnemonic
+// seed phrase. The i-th key is derived along the hardened BIP-32 path
+//
+//	m/44'/1818845810'/account'/i'
+//
+// on secp256k1 (ecid.Curve), skipping any (vanishingly unlikely) invalid indices.
+func FromMnemonic(mnemonic string, account uint32, n int) (Keychain, error) {
+	if err := ValidateMnemonic(mnemonic); err != nil {
+		return nil, err
+	}
+	master, err := newMasterKey(mnemonicToSeed(mnemonic, ""))
+	if err != nil {
+		return nil, err
+	}
+	accountKey, err := master.derivePath(HDPurpose, HDCoinType, account)
+	if err != nil {
+		return nil, err
+	}
+	ecids := make([]ecid.ID, 0, n)
+	for i := uint32(0); len(ecids) < n; i++ {
+		child, err := accountKey.hardenedChild(i)
+		if err == errInvalidHDKey {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		ecids = append(ecids, child.ecid())
+	}
+	return FromECIDs(ecids), nil
+}
+
+// extendedKey is a BIP-32 extended private key.
+type extendedKey struct {
+	key       []byte
+	chainCode []byte
+}
+
+// newMasterKey creates the BIP-32 master extended key from a seed.
+func newMasterKey(seed []byte) (*extendedKey, error) {
+	mac := hmac.New(sha512.New, masterKeySalt)
+	_, _ = mac.Write(seed)
+	sum := mac.Sum(nil)
+	if !validHDKey(new(big.Int).SetBytes(sum[:hdKeyLength])) {
+		return nil, errInvalidHDKey
+	}
+	return &extendedKey{key: sum[:hdKeyLength], chainCode: sum[hdKeyLength:]}, nil
+}
+
+// derivePath derives the descendant along the given hardened child indices.
+func (k *extendedKey) derivePath(indices ...uint32) (*extendedKey, error) {
+	var err error
+	for _, i := range indices {
+		if k, err = k.hardenedChild(i); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
+
+// hardenedChild derives the i-th hardened child extended key.
+func (k *extendedKey) hardenedChild(i uint32) (*extendedKey, error) {
+	if i >= hardenedOffset {
+		return nil, errInvalidHDIndex
+	}
+	data := make([]byte, 1+hdKeyLength+4)
+	copy(data[1:], k.key)
+	binary.BigEndian.PutUint32(data[1+hdKeyLength:], i+hardenedOffset)
+	mac := hmac.New(sha512.New, k.chainCode)
+	_, _ = mac.Write(data)
+	sum := mac.Sum(nil)
+
+	n := ecid.Curve.Params().N
+	tweak := new(big.Int).SetBytes(sum[:hdKeyLength])
+	if tweak.Cmp(n) >= 0 {
+		return nil, errInvalidHDKey
+	}
+	child := tweak.Add(tweak, new(big.Int).SetBytes(k.key))
+	child.Mod(child, n)
+	if !validHDKey(child) {
+		return nil, errInvalidHDKey
+	}
+	childKey := make([]byte, hdKeyLength)
+	childBytes := child.Bytes()
+	copy(childKey[hdKeyLength-len(childBytes):], childBytes)
+	return &extendedKey{key: childKey, chainCode: sum[hdKeyLength:]}, nil
+}
+
+// ecid returns the ECDSA key of the extended key.
+func (k *extendedKey) ecid() ecid.ID {
+	return fromPrivateKeyBytes(k.key)
+}
+
+func validHDKey(key *big.Int) bool {
+	return key.Sign() > 0 && key.Cmp(ecid.Curve.Params().N) < 0
+}
@@ -0,0 +1,77 @@
+package keychain
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtendedKey_hardenedChild(t *testing.T) {
+	// BIP-32 test vector 1
+	seed, err := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	assert.Nil(t, err)
+	master, err := newMasterKey(seed)
+	assert.Nil(t, err)
+	assert.Equal(t, "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35",
+		hex.EncodeToString(master.key))
+	assert.Equal(t, "873dff81c02f525623fd1fe5167eac3a55a049de3d314bb42ee227ffed37d508",
+		hex.EncodeToString(master.chainCode))
+
+	child, err := master.derivePath(0)
+	assert.Nil(t, err)
+	assert.Equal(t, "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea",
+		hex.EncodeToString(child.key))
+	assert.Equal(t, "47fdacbd0f1097043b78c63c20c34ef4ed9a111d980047ad16282c7ae6236141",
+		hex.EncodeToString(child.chainCode))
+
+	// check too large index triggers error
+	child, err = master.hardenedChild(hardenedOffset)
+	assert.Equal(t, errInvalidHDIndex, err)
+	assert.Nil(t, child)
+}
+
+func TestFromMnemonic_ok(t *testing.T) {
+	mnemonic, err := NewMnemonic()
+	assert.Nil(t, err)
+
+	kc1, err := FromMnemonic(mnemonic, 0, 4)
+	assert.Nil(t, err)
+	assert.Len(t, kc1.Keys(), 4)
+	for _, key := range kc1.Keys() {
+		assert.Nil(t, validateKey(key))
+	}
+
+	// check same mnemonic and account gives same keys, regardless of whitespace
+	kc2, err := FromMnemonic(" "+mnemonic+"\n", 0, 4)
+	assert.Nil(t, err)
+	assert.Equal(t, kc1, kc2)
+
+	// check first keys are the same when deriving more
+	kc3, err := FromMnemonic(mnemonic, 0, 8)
+	assert.Nil(t, err)
+	for _, key := range kc1.Keys() {
+		_, in := kc3.Get(key.PublicKeyBytes())
+		assert.True(t, in)
+	}
+
+	// check different account gives different keys
+	kc4, err := FromMnemonic(mnemonic, 1, 4)
+	assert.Nil(t, err)
+	for _, key := range kc1.Keys() {
+		_, in := kc4.Get(key.PublicKeyBytes())
+		assert.False(t, in)
+	}
+}
+
+func TestFromMnemonic_err(t *testing.T) {
+	kc, err := FromMnemonic("not a mnemonic", 0, 4)
+	assert.Equal(t, ErrInvalidMnemonic, err)
+	assert.Nil(t, kc)
+
+	mnemonic, err := NewMnemonic()
+	assert.Nil(t, err)
+	kc, err = FromMnemonic(mnemonic, hardenedOffset, 4)
+	assert.Equal(t, errInvalidHDIndex, err)
+	assert.Nil(t, kc)
+}
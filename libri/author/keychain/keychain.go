@@ -1,9 +1,11 @@
 package keychain
 
 import (
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"math/big"
 	"math/rand"
 	"sort"
 
@@ -35,8 +37,18 @@ var (
 
 	// ErrUnexpectedMissingKey indicates a unexpectedly missing key
 	ErrUnexpectedMissingKey = errors.New("missing key")
+
+	// ErrDuplicateKey indicates when a stored keychain contains the same key more than once.
+	ErrDuplicateKey = errors.New("duplicate key in keychain")
+
+	// ErrInvalidKey indicates when a private key's public key is not on its curve or doesn't
+	// match the private key.
+	ErrInvalidKey = errors.New("invalid key")
 )
 
+// fingerprintLength is the number of public key hash bytes in a key fingerprint.
+const fingerprintLength = 8
+
 // Getter is a collection of ECDSA keys that can be looked up by their public key.
 type Getter interface {
 	// Get returns the key with the given public key, if it exists. Otherwise, it returns nil.
@@ -56,7 +68,18 @@ type GetterSampler interface {
 	Sampler
 }
 
-// Getter represents a collection of ECDSA private keys.
+// Keychain is a collection of ECDSA keys that can be looked up, sampled, listed, and added to.
+type Keychain interface {
+	GetterSampler
+
+	// Add adds the given keys to the keychain, ignoring those already present.
+	Add(keys ...ecid.ID)
+
+	// Keys returns the keys in the keychain, ordered by their public keys.
+	Keys() []ecid.ID
+}
+
+// keychain represents a collection of ECDSA private keys.
 type keychain struct {
 	// private keys indexed by the hex of the 65-byte public key representation
 	privs map[string]ecid.ID
@@ -68,8 +91,8 @@ type keychain struct {
 	rng *rand.Rand
 }
 
-// New creates a new (plaintext) Getter with n individual keys.
-func New(n int) GetterSampler {
+// New creates a new (plaintext) Keychain with n individual keys.
+func New(n int) Keychain {
 	ecids := make([]ecid.ID, n)
 	for i := 0; i < n; i++ {
 		ecids[i] = ecid.NewRandom()
@@ -77,20 +100,42 @@ func New(n int) GetterSampler {
 	return FromECIDs(ecids)
 }
 
-// FromECIDs creates a Getter instance from a map of ECDSA private keys.
-func FromECIDs(ecids []ecid.ID) GetterSampler {
-	pubs := make([]string, len(ecids))
-	privs := make(map[string]ecid.ID)
-	for i, priv := range ecids {
-		pubs[i] = pubKeyString(priv.PublicKeyBytes())
-		privs[pubs[i]] = priv
+// FromECIDs creates a Keychain instance from a list of ECDSA private keys.
+func FromECIDs(ecids []ecid.ID) Keychain {
+	kc := &keychain{
+		privs: make(map[string]ecid.ID),
+		pubs:  make([]string, 0, len(ecids)),
+	}
+	kc.Add(ecids...)
+	kc.rng = rand.New(rand.NewSource(int64(len(kc.privs))))
+	return kc
+}
+
+// FromPrivateKeys creates a Keychain instance from a list of 32-byte big-endian private keys.
+func FromPrivateKeys(privs [][]byte) (Keychain, error) {
+	ecids := make([]ecid.ID, len(privs))
+	for i, priv := range privs {
+		if len(priv) != privateKeyLength {
+			return nil, ErrInvalidKey
+		}
+		d := new(big.Int).SetBytes(priv)
+		if d.Sign() == 0 || d.Cmp(ecid.Curve.Params().N) >= 0 {
+			return nil, ErrInvalidKey
+		}
+		ecids[i] = fromPrivateKeyBytes(priv)
 	}
-	sort.Strings(pubs)
-	return &keychain{
-		privs: privs,
-		pubs:  pubs,
-		rng:   rand.New(rand.NewSource(int64(len(privs)))),
+	return FromECIDs(ecids), nil
+}
+
+// PrivateKeys returns the 32-byte big-endian private keys of the keychain, ordered by their public
+// keys.
+func PrivateKeys(kc Keychain) [][]byte {
+	keys := kc.Keys()
+	privs := make([][]byte, len(keys))
+	for i, key := range keys {
+		privs[i] = toPrivateKeyBytes(key)
 	}
+	return privs
 }
 
 // Sample returns a uniformly random key from the keychain.
@@ -107,6 +152,26 @@ func (kc *keychain) Get(publicKey []byte) (ecid.ID, bool) {
 	return value, in
 }
 
+func (kc *keychain) Add(keys ...ecid.ID) {
+	for _, key := range keys {
+		pub := pubKeyString(key.PublicKeyBytes())
+		if _, in := kc.privs[pub]; in {
+			continue
+		}
+		kc.privs[pub] = key
+		kc.pubs = append(kc.pubs, pub)
+	}
+	sort.Strings(kc.pubs)
+}
+
+func (kc *keychain) Keys() []ecid.ID {
+	keys := make([]ecid.ID, len(kc.pubs))
+	for i, pub := range kc.pubs {
+		keys[i] = kc.privs[pub]
+	}
+	return keys
+}
+
 type keychains struct {
 	kcs []Getter
 }
@@ -126,7 +191,7 @@ func (kcs *keychains) Get(publicKey []byte) (ecid.ID, bool) {
 }
 
 // Save saves and encrypts a keychain to a file.
-func Save(filepath, auth string, kc GetterSampler, scryptN, scryptP int) error {
+func Save(filepath, auth string, kc Keychain, scryptN, scryptP int) error {
 	stored, err := encryptToStored(kc, auth, scryptN, scryptP)
 	if err != nil {
 		return err
@@ -140,7 +205,48 @@ func Save(filepath, auth string, kc GetterSampler, scryptN, scryptP int) error {
 }
 
 // Load loads and decrypts a keychain from a file.
-func Load(filepath, auth string) (GetterSampler, error) {
+func Load(filepath, auth string) (Keychain, error) {
+	stored, err := loadStored(filepath)
+	if err != nil {
+		return nil, err
+	}
+	return decryptFromStored(stored, auth)
+}
+
+// Verify loads and decrypts a keychain from a file, checking that it is non-empty, has no
+// duplicate keys, and that each private key matches its public key. It returns the number of
+// keys in the keychain.
+func Verify(filepath, auth string) (int, error) {
+	stored, err := loadStored(filepath)
+	if err != nil {
+		return 0, err
+	}
+	kc, err := decryptFromStored(stored, auth)
+	if err != nil {
+		return 0, err
+	}
+	keys := kc.Keys()
+	if len(keys) == 0 {
+		return 0, ErrEmptyKeychain
+	}
+	if len(keys) != len(stored.PrivateKeys) {
+		return 0, ErrDuplicateKey
+	}
+	for _, key := range keys {
+		if err := validateKey(key); err != nil {
+			return 0, err
+		}
+	}
+	return len(keys), nil
+}
+
+// Fingerprint returns a short hex fingerprint of a public key, suitable for comparing keys by eye.
+func Fingerprint(pubKey []byte) string {
+	hash := sha256.Sum256(pubKey)
+	return fmt.Sprintf("%x", hash[:fingerprintLength])
+}
+
+func loadStored(filepath string) (*StoredKeychain, error) {
 	buf, err := ioutil.ReadFile(filepath)
 	if err != nil {
 		return nil, err
@@ -149,7 +255,19 @@ func Load(filepath, auth string) (GetterSampler, error) {
 	if err := proto.Unmarshal(buf, stored); err != nil {
 		return nil, err
 	}
-	return decryptFromStored(stored, auth)
+	return stored, nil
+}
+
+func validateKey(key ecid.ID) error {
+	priv := key.Key()
+	if !priv.Curve.IsOnCurve(priv.X, priv.Y) {
+		return ErrInvalidKey
+	}
+	x, y := priv.Curve.ScalarBaseMult(priv.D.Bytes())
+	if x.Cmp(priv.X) != 0 || y.Cmp(priv.Y) != 0 {
+		return ErrInvalidKey
+	}
+	return nil
 }
 
 func pubKeyString(pubKey []byte) string {
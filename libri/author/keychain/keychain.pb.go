@@ -10,6 +10,9 @@ It is generated from these files:
 
 It has these top-level messages:
 	StoredKeychain
+	KeychainShare
+	EncryptedKeychainShare
+	KeychainsBackup
 */
 package keychain
 
@@ -28,6 +31,7 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
+// StoredKeychain contains the encrypted private keys of a keychain, which are always secp256k1.
 type StoredKeychain struct {
 	PrivateKeys [][]byte `protobuf:"bytes,1,rep,name=privateKeys,proto3" json:"privateKeys,omitempty"`
 }
@@ -44,19 +48,152 @@ func (m *StoredKeychain) GetPrivateKeys() [][]byte {
 	return nil
 }
 
+// KeychainShare is one Shamir share of the private keys in a keychain.
+type KeychainShare struct {
+	// number of shares needed to reconstruct the keychain
+	Threshold uint32 `protobuf:"varint,1,opt,name=threshold" json:"threshold,omitempty"`
+	// total number of shares the keychain was split into
+	NShares uint32 `protobuf:"varint,2,opt,name=nShares" json:"nShares,omitempty"`
+	// share of the concatenated private keys, with the share's x-coordinate as the last byte
+	Share []byte `protobuf:"bytes,3,opt,name=share,proto3" json:"share,omitempty"`
+	// SHA-256 hash of the concatenated private keys, for checking reconstruction
+	KeychainHash []byte `protobuf:"bytes,4,opt,name=keychainHash,proto3" json:"keychainHash,omitempty"`
+}
+
+func (m *KeychainShare) Reset()                    { *m = KeychainShare{} }
+func (m *KeychainShare) String() string            { return proto.CompactTextString(m) }
+func (*KeychainShare) ProtoMessage()               {}
+func (*KeychainShare) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *KeychainShare) GetThreshold() uint32 {
+	if m != nil {
+		return m.Threshold
+	}
+	return 0
+}
+
+func (m *KeychainShare) GetNShares() uint32 {
+	if m != nil {
+		return m.NShares
+	}
+	return 0
+}
+
+func (m *KeychainShare) GetShare() []byte {
+	if m != nil {
+		return m.Share
+	}
+	return nil
+}
+
+func (m *KeychainShare) GetKeychainHash() []byte {
+	if m != nil {
+		return m.KeychainHash
+	}
+	return nil
+}
+
+// EncryptedKeychainShare is a KeychainShare encrypted with a key derived from a passphrase.
+type EncryptedKeychainShare struct {
+	Salt       []byte `protobuf:"bytes,1,opt,name=salt,proto3" json:"salt,omitempty"`
+	ScryptN    uint32 `protobuf:"varint,2,opt,name=scryptN" json:"scryptN,omitempty"`
+	ScryptP    uint32 `protobuf:"varint,3,opt,name=scryptP" json:"scryptP,omitempty"`
+	Nonce      []byte `protobuf:"bytes,4,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	Ciphertext []byte `protobuf:"bytes,5,opt,name=ciphertext,proto3" json:"ciphertext,omitempty"`
+}
+
+func (m *EncryptedKeychainShare) Reset()                    { *m = EncryptedKeychainShare{} }
+func (m *EncryptedKeychainShare) String() string            { return proto.CompactTextString(m) }
+func (*EncryptedKeychainShare) ProtoMessage()               {}
+func (*EncryptedKeychainShare) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *EncryptedKeychainShare) GetSalt() []byte {
+	if m != nil {
+		return m.Salt
+	}
+	return nil
+}
+
+func (m *EncryptedKeychainShare) GetScryptN() uint32 {
+	if m != nil {
+		return m.ScryptN
+	}
+	return 0
+}
+
+func (m *EncryptedKeychainShare) GetScryptP() uint32 {
+	if m != nil {
+		return m.ScryptP
+	}
+	return 0
+}
+
+func (m *EncryptedKeychainShare) GetNonce() []byte {
+	if m != nil {
+		return m.Nonce
+	}
+	return nil
+}
+
+func (m *EncryptedKeychainShare) GetCiphertext() []byte {
+	if m != nil {
+		return m.Ciphertext
+	}
+	return nil
+}
+
+// KeychainsBackup contains the private keys of an author's keychains, for backing them up to libri.
+type KeychainsBackup struct {
+	AuthorPrivateKeys     [][]byte `protobuf:"bytes,1,rep,name=authorPrivateKeys,proto3" json:"authorPrivateKeys,omitempty"`
+	SelfReaderPrivateKeys [][]byte `protobuf:"bytes,2,rep,name=selfReaderPrivateKeys,proto3" json:"selfReaderPrivateKeys,omitempty"`
+}
+
+func (m *KeychainsBackup) Reset()                    { *m = KeychainsBackup{} }
+func (m *KeychainsBackup) String() string            { return proto.CompactTextString(m) }
+func (*KeychainsBackup) ProtoMessage()               {}
+func (*KeychainsBackup) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *KeychainsBackup) GetAuthorPrivateKeys() [][]byte {
+	if m != nil {
+		return m.AuthorPrivateKeys
+	}
+	return nil
+}
+
+func (m *KeychainsBackup) GetSelfReaderPrivateKeys() [][]byte {
+	if m != nil {
+		return m.SelfReaderPrivateKeys
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*StoredKeychain)(nil), "keychain.StoredKeychain")
+	proto.RegisterType((*KeychainShare)(nil), "keychain.KeychainShare")
+	proto.RegisterType((*EncryptedKeychainShare)(nil), "keychain.EncryptedKeychainShare")
+	proto.RegisterType((*KeychainsBackup)(nil), "keychain.KeychainsBackup")
 }
 
 func init() { proto.RegisterFile("libri/author/keychain/keychain.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 101 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xe2, 0x52, 0xc9, 0xc9, 0x4c, 0x2a,
-	0xca, 0xd4, 0x4f, 0x2c, 0x2d, 0xc9, 0xc8, 0x2f, 0xd2, 0xcf, 0x4e, 0xad, 0x4c, 0xce, 0x48, 0xcc,
-	0xcc, 0x83, 0x33, 0xf4, 0x0a, 0x8a, 0xf2, 0x4b, 0xf2, 0x85, 0x38, 0x60, 0x7c, 0x25, 0x23, 0x2e,
-	0xbe, 0xe0, 0x92, 0xfc, 0xa2, 0xd4, 0x14, 0x6f, 0xa8, 0x88, 0x90, 0x02, 0x17, 0x77, 0x41, 0x51,
-	0x66, 0x59, 0x62, 0x49, 0xaa, 0x77, 0x6a, 0x65, 0xb1, 0x04, 0xa3, 0x02, 0xb3, 0x06, 0x4f, 0x10,
-	0xb2, 0x50, 0x12, 0x1b, 0xd8, 0x10, 0x63, 0x40, 0x00, 0x00, 0x00, 0xff, 0xff, 0x04, 0xa8, 0x22,
-	0x2a, 0x6c, 0x00, 0x00, 0x00,
+	// 286 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x91, 0xd1, 0x4a, 0xf3, 0x30,
+	0x14, 0xc7, 0xc9, 0xba, 0x7d, 0x9f, 0x1e, 0x3b, 0xc5, 0xa0, 0x92, 0x0b, 0x91, 0x52, 0xbc, 0xe8,
+	0x85, 0x38, 0x50, 0x9f, 0x40, 0x10, 0x84, 0x81, 0x94, 0xee, 0x09, 0xb2, 0xf6, 0x48, 0xca, 0x4a,
+	0x53, 0x92, 0x54, 0xec, 0xa5, 0x2f, 0xe1, 0xf3, 0x4a, 0x93, 0xc6, 0x76, 0xcc, 0xbb, 0xf3, 0xff,
+	0xff, 0x4e, 0xe8, 0x8f, 0x1e, 0xb8, 0xad, 0xca, 0xad, 0x2a, 0x57, 0xbc, 0x35, 0x42, 0xaa, 0xd5,
+	0x0e, 0xbb, 0x5c, 0xf0, 0xb2, 0xfe, 0x1d, 0xee, 0x1b, 0x25, 0x8d, 0xa4, 0x47, 0x3e, 0xc7, 0x0f,
+	0x70, 0xba, 0x31, 0x52, 0x61, 0xb1, 0x1e, 0x1a, 0x1a, 0xc1, 0x49, 0xa3, 0xca, 0x0f, 0x6e, 0x70,
+	0x8d, 0x9d, 0x66, 0x24, 0x0a, 0x92, 0x30, 0x9b, 0x56, 0xf1, 0x17, 0x81, 0xa5, 0x5f, 0xdf, 0x08,
+	0xae, 0x90, 0x5e, 0xc3, 0xb1, 0x11, 0x0a, 0xb5, 0x90, 0x55, 0xc1, 0x48, 0x44, 0x92, 0x65, 0x36,
+	0x16, 0x94, 0xc1, 0x7f, 0xb7, 0xa7, 0xd9, 0xcc, 0x32, 0x1f, 0xe9, 0x05, 0x2c, 0x74, 0x3f, 0xb1,
+	0x20, 0x22, 0x49, 0x98, 0xb9, 0x40, 0x63, 0x08, 0xbd, 0xdf, 0x2b, 0xd7, 0x82, 0xcd, 0x2d, 0xdc,
+	0xeb, 0xe2, 0x6f, 0x02, 0x57, 0x2f, 0x75, 0xae, 0xba, 0xc6, 0x8c, 0xee, 0x4e, 0x86, 0xc2, 0x5c,
+	0xf3, 0xca, 0x58, 0x8f, 0x30, 0xb3, 0x73, 0xaf, 0xa0, 0xed, 0xf2, 0x9b, 0x57, 0x18, 0xe2, 0x48,
+	0x52, 0x16, 0x4c, 0x49, 0xda, 0xcb, 0xd5, 0xb2, 0xce, 0x71, 0xf8, 0xbe, 0x0b, 0xf4, 0x06, 0x20,
+	0x2f, 0x1b, 0x81, 0xca, 0xe0, 0xa7, 0x61, 0x0b, 0x8b, 0x26, 0x4d, 0xdc, 0xc2, 0x99, 0xd7, 0xd1,
+	0xcf, 0x3c, 0xdf, 0xb5, 0x0d, 0xbd, 0x83, 0x73, 0x77, 0x8f, 0xf4, 0xe0, 0xbf, 0x1e, 0x02, 0xfa,
+	0x04, 0x97, 0x1a, 0xab, 0xf7, 0x0c, 0x79, 0x81, 0x7b, 0x2f, 0x66, 0xf6, 0xc5, 0xdf, 0x70, 0xfb,
+	0xcf, 0x1e, 0xf6, 0xf1, 0x67, 0x00, 0x4a, 0x36, 0x77, 0x0c, 0x00, 0x02, 0x00, 0x00,
 }
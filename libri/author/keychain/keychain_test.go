@@ -1,6 +1,7 @@
 package keychain
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"testing"
@@ -8,6 +9,7 @@ import (
 	"math/rand"
 
 	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/golang/protobuf/proto"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -60,6 +62,66 @@ func TestUnionGetter_Get(t *testing.T) {
 	assert.Nil(t, k)
 }
 
+func TestKeychain_AddKeys(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	kc := New(3)
+	keys1 := kc.Keys()
+	assert.Len(t, keys1, 3)
+
+	// check adding new keys and an existing key
+	newKeys := []ecid.ID{ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng)}
+	kc.Add(append(newKeys, keys1[0])...)
+	keys2 := kc.Keys()
+	assert.Len(t, keys2, 5)
+	for _, key := range append(newKeys, keys1...) {
+		key2, in := kc.Get(key.PublicKeyBytes())
+		assert.True(t, in)
+		assert.Equal(t, key, key2)
+	}
+
+	// check keys are ordered by public key
+	for i := 1; i < len(keys2); i++ {
+		prev := pubKeyString(keys2[i-1].PublicKeyBytes())
+		assert.True(t, prev < pubKeyString(keys2[i].PublicKeyBytes()))
+	}
+}
+
+func TestFromPrivateKeys_ok(t *testing.T) {
+	kc1 := New(3)
+	kc2, err := FromPrivateKeys(PrivateKeys(kc1))
+	assert.Nil(t, err)
+	assert.Len(t, kc2.Keys(), 3)
+	for _, key := range kc1.Keys() {
+		key2, in := kc2.Get(key.PublicKeyBytes())
+		assert.True(t, in)
+		assert.Equal(t, key.Key().D, key2.Key().D)
+	}
+}
+
+func TestFromPrivateKeys_err(t *testing.T) {
+	cases := [][]byte{
+		make([]byte, privateKeyLength-1), // wrong length
+		make([]byte, privateKeyLength),   // zero
+		ecid.Curve.Params().N.Bytes(),    // not less than curve order
+	}
+	for i, c := range cases {
+		kc, err := FromPrivateKeys([][]byte{c})
+		assert.Equal(t, ErrInvalidKey, err, fmt.Sprintf("case %d", i))
+		assert.Nil(t, kc)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	pub1 := ecid.NewPseudoRandom(rng).PublicKeyBytes()
+	pub2 := ecid.NewPseudoRandom(rng).PublicKeyBytes()
+
+	fp1 := Fingerprint(pub1)
+	assert.Len(t, fp1, 2*fingerprintLength)
+	assert.Equal(t, fp1, Fingerprint(pub1))
+	assert.NotEqual(t, fp1, Fingerprint(pub2))
+}
+
 func TestSave_err(t *testing.T) {
 	file, err := ioutil.TempFile("", "kechain-test")
 	defer func() { assert.Nil(t, os.Remove(file.Name())) }()
@@ -103,5 +165,70 @@ func TestSaveLoad(t *testing.T) {
 	kc3, err := Load(file.Name(), "wrong passphrase")
 	assert.NotNil(t, err)
 	assert.Nil(t, kc3)
+}
+
+func TestVerify_ok(t *testing.T) {
+	file, err := ioutil.TempFile("", "kechain-test")
+	defer func() { assert.Nil(t, os.Remove(file.Name())) }()
+	assert.Nil(t, err)
+	assert.Nil(t, file.Close())
+
+	auth := "test passphrase"
+	err = Save(file.Name(), auth, New(3), veryLightScryptN, veryLightScryptP)
+	assert.Nil(t, err)
+
+	n, err := Verify(file.Name(), auth)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, n)
+}
 
+func TestVerify_err(t *testing.T) {
+	file, err := ioutil.TempFile("", "kechain-test")
+	defer func() { assert.Nil(t, os.Remove(file.Name())) }()
+	assert.Nil(t, err)
+	assert.Nil(t, file.Close())
+	auth := "test passphrase"
+
+	// check missing file triggers error
+	n, err := Verify(file.Name()+"-missing", auth)
+	assert.NotNil(t, err)
+	assert.Zero(t, n)
+
+	// check wrong passphrase triggers error
+	err = Save(file.Name(), auth, New(3), veryLightScryptN, veryLightScryptP)
+	assert.Nil(t, err)
+	n, err = Verify(file.Name(), "wrong passphrase")
+	assert.NotNil(t, err)
+	assert.Zero(t, n)
+
+	// check empty keychain triggers error
+	err = Save(file.Name(), auth, New(0), veryLightScryptN, veryLightScryptP)
+	assert.Nil(t, err)
+	n, err = Verify(file.Name(), auth)
+	assert.Equal(t, ErrEmptyKeychain, err)
+	assert.Zero(t, n)
+
+	// check duplicate key triggers error
+	stored, err := encryptToStored(New(1), auth, veryLightScryptN, veryLightScryptP)
+	assert.Nil(t, err)
+	stored.PrivateKeys = append(stored.PrivateKeys, stored.PrivateKeys[0])
+	buf, err := proto.Marshal(stored)
+	assert.Nil(t, err)
+	err = ioutil.WriteFile(file.Name(), buf, 0600)
+	assert.Nil(t, err)
+	n, err = Verify(file.Name(), auth)
+	assert.Equal(t, ErrDuplicateKey, err)
+	assert.Zero(t, n)
+}
+
+func TestValidateKey(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	key := ecid.NewPseudoRandom(rng)
+	assert.Nil(t, validateKey(key))
+
+	// check mismatched private and public keys trigger error
+	other := ecid.NewPseudoRandom(rng).Key()
+	priv := *key.Key()
+	priv.D = other.D
+	assert.Equal(t, ErrInvalidKey, validateKey(ecid.FromPrivateKey(&priv)))
 }
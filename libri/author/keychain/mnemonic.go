@@ -0,0 +1,112 @@
+package keychain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// mnemonicEntropyLength is the number of random entropy bytes in a new mnemonic, giving 24
+	// words.
+	mnemonicEntropyLength = 32
+
+	// bitsPerWord is the number of bits each mnemonic word encodes.
+	bitsPerWord = 11
+
+	// mnemonicSeedIterations is the number of PBKDF2 iterations when stretching a mnemonic into a
+	// seed.
+	mnemonicSeedIterations = 2048
+
+	// mnemonicSeedLength is the length of the seed stretched from a mnemonic.
+	mnemonicSeedLength = 64
+)
+
+// ErrInvalidMnemonic indicates when a mnemonic has the wrong number of words, a word not in the
+// wordlist, or a bad checksum.
+var ErrInvalidMnemonic = errors.New("invalid mnemonic")
+
+// wordIndices maps each word in the wordlist to its index.
+var wordIndices = func() map[string]int {
+	indices := make(map[string]int, len(wordlist))
+	for i, word := range wordlist {
+		indices[word] = i
+	}
+	return indices
+}()
+
+// NewMnemonic returns a new random 24-word BIP-39 mnemonic seed phrase.
+func NewMnemonic() (string, error) {
+	entropy := make([]byte, mnemonicEntropyLength)
+	if _, err := rand.Read(entropy); err != nil {
+		return "", err
+	}
+	return entropyToMnemonic(entropy), nil
+}
+
+// ValidateMnemonic checks that a mnemonic has a valid number of words from the wordlist and a
+// correct checksum.
+func ValidateMnemonic(mnemonic string) error {
+	_, err := mnemonicToEntropy(mnemonic)
+	return err
+}
+
+// entropyToMnemonic encodes 16-32 bytes of entropy, followed by the first len(entropy)/4 bits of
+// its SHA-256 hash as a checksum, as mnemonic words of 11 bits each.
+func entropyToMnemonic(entropy []byte) string {
+	nChecksumBits := uint(len(entropy) / 4)
+	hash := sha256.Sum256(entropy)
+	bits := new(big.Int).SetBytes(entropy)
+	bits.Lsh(bits, nChecksumBits)
+	bits.Or(bits, big.NewInt(int64(hash[0]>>(8-nChecksumBits))))
+
+	nWords := (len(entropy)*8 + int(nChecksumBits)) / bitsPerWord
+	words := make([]string, nWords)
+	mask := big.NewInt(1<<bitsPerWord - 1)
+	for i := nWords - 1; i >= 0; i-- {
+		words[i] = wordlist[new(big.Int).And(bits, mask).Int64()]
+		bits.Rsh(bits, bitsPerWord)
+	}
+	return strings.Join(words, " ")
+}
+
+// mnemonicToEntropy decodes the entropy from a mnemonic, checking its checksum.
+func mnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return nil, ErrInvalidMnemonic
+	}
+	bits := new(big.Int)
+	for _, word := range words {
+		i, in := wordIndices[word]
+		if !in {
+			return nil, ErrInvalidMnemonic
+		}
+		bits.Lsh(bits, bitsPerWord)
+		bits.Or(bits, big.NewInt(int64(i)))
+	}
+	nChecksumBits := uint(len(words) / 3)
+	checksum := new(big.Int).And(bits, big.NewInt(1<<nChecksumBits-1))
+	bits.Rsh(bits, nChecksumBits)
+
+	entropy := make([]byte, nChecksumBits*4)
+	bitsBytes := bits.Bytes()
+	copy(entropy[len(entropy)-len(bitsBytes):], bitsBytes)
+	hash := sha256.Sum256(entropy)
+	if checksum.Int64() != int64(hash[0]>>(8-nChecksumBits)) {
+		return nil, ErrInvalidMnemonic
+	}
+	return entropy, nil
+}
+
+// mnemonicToSeed stretches a mnemonic and optional passphrase into a 64-byte seed.
+func mnemonicToSeed(mnemonic, passphrase string) []byte {
+	normalized := strings.Join(strings.Fields(mnemonic), " ")
+	return pbkdf2.Key([]byte(normalized), []byte("mnemonic"+passphrase), mnemonicSeedIterations,
+		mnemonicSeedLength, sha512.New)
+}
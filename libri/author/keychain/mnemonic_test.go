@@ -0,0 +1,72 @@
+package keychain
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMnemonic(t *testing.T) {
+	m1, err := NewMnemonic()
+	assert.Nil(t, err)
+	assert.Len(t, strings.Fields(m1), 24)
+	assert.Nil(t, ValidateMnemonic(m1))
+
+	m2, err := NewMnemonic()
+	assert.Nil(t, err)
+	assert.NotEqual(t, m1, m2)
+}
+
+func TestEntropyMnemonic(t *testing.T) {
+	// BIP-39 test vectors
+	cases := []struct {
+		entropy  string
+		mnemonic string
+		seed     string
+	}{
+		{
+			entropy:  "00000000000000000000000000000000",
+			mnemonic: strings.Repeat("abandon ", 11) + "about",
+			seed: "c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a69875" +
+				"99d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+		},
+		{
+			entropy:  "0000000000000000000000000000000000000000000000000000000000000000",
+			mnemonic: strings.Repeat("abandon ", 23) + "art",
+			seed: "bda85446c68413707090a52022edd26a1c9462295029f2e60cd7c4f2bbd3097170af7a4d73" +
+				"245cafa9c3cca8d561a7c3de6f5d4a10be8ed2a5e608d68f92fcc8",
+		},
+	}
+	for _, c := range cases {
+		entropy, err := hex.DecodeString(c.entropy)
+		assert.Nil(t, err)
+		assert.Equal(t, c.mnemonic, entropyToMnemonic(entropy))
+
+		entropy2, err := mnemonicToEntropy(c.mnemonic)
+		assert.Nil(t, err)
+		assert.Equal(t, entropy, entropy2)
+
+		assert.Equal(t, c.seed, hex.EncodeToString(mnemonicToSeed(c.mnemonic, "TREZOR")))
+	}
+}
+
+func TestValidateMnemonic_err(t *testing.T) {
+	cases := []string{
+		"",                                       // no words
+		strings.Repeat("abandon ", 12),           // bad checksum
+		strings.Repeat("abandon ", 11) + "ab",    // word not in wordlist
+		strings.Repeat("abandon ", 10) + "about", // wrong number of words
+	}
+	for i, c := range cases {
+		assert.Equal(t, ErrInvalidMnemonic, ValidateMnemonic(c), "case %d", i)
+	}
+}
+
+func TestWordlist(t *testing.T) {
+	assert.Len(t, wordIndices, 1<<bitsPerWord)
+	for i := 1; i < len(wordlist); i++ {
+		assert.True(t, wordlist[i-1] < wordlist[i])
+	}
+}
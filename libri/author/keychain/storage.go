@@ -11,14 +11,14 @@ import (
 
 // encryptToStored encrypts the contents of Keychain using the authentication passphrase and scrypt
 // difficulty parameters.
-func encryptToStored(kc GetterSampler, auth string, scryptN, scryptP int) (*StoredKeychain, error) {
+func encryptToStored(kc Keychain, auth string, scryptN, scryptP int) (*StoredKeychain, error) {
 	storedPrivateKeys := make([][]byte, 0)
 	var mu sync.Mutex
 	var wg sync.WaitGroup
 	errs, done := make(chan error, 1), make(chan struct{}, 1)
 
 	// encrypt all keys in parallel b/c each can be intensive, thanks to scrypt
-	for _, key1 := range kc.(*keychain).privs {
+	for _, key1 := range kc.Keys() {
 		wg.Add(1)
 		go func(key2 ecid.ID) {
 			var err error
@@ -49,7 +49,7 @@ func encryptToStored(kc GetterSampler, auth string, scryptN, scryptP int) (*Stor
 }
 
 // decryptFromStored decrypts the contents of a StoredKeychain using the authentication passphrase.
-func decryptFromStored(stored *StoredKeychain, auth string) (GetterSampler, error) {
+func decryptFromStored(stored *StoredKeychain, auth string) (Keychain, error) {
 	ecids := make([]ecid.ID, 0)
 	var mu sync.Mutex
 	var wg sync.WaitGroup
@@ -84,6 +84,22 @@ func decryptFromStored(stored *StoredKeychain, auth string) (GetterSampler, erro
 	}
 }
 
+// ExportKey encrypts an individual key to Ethereum keystore JSON using the authentication
+// passphrase and scrypt difficulty parameters.
+func ExportKey(key ecid.ID, auth string, scryptN, scryptP int) ([]byte, error) {
+	return encryptKey(key.Key(), auth, scryptN, scryptP)
+}
+
+// ImportKey decrypts an individual key from Ethereum keystore JSON using the authentication
+// passphrase.
+func ImportKey(keyJSON []byte, auth string) (ecid.ID, error) {
+	priv, err := decryptKey(keyJSON, auth)
+	if err != nil {
+		return nil, err
+	}
+	return ecid.FromPrivateKey(priv), nil
+}
+
 func encryptKey(key *ecdsa.PrivateKey, auth string, scryptN, scryptP int) ([]byte, error) {
 	ethKey := &ethkeystore.Key{
 		// Address is not not used by libri, but required for encryption & decryption
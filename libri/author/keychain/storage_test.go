@@ -1,8 +1,10 @@
 package keychain
 
 import (
+	"math/rand"
 	"testing"
 
+	"github.com/drausin/libri/libri/common/ecid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -46,3 +48,27 @@ func TestToFromStored_err(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Nil(t, kc2)
 }
+
+func TestExportImportKey(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	key1 := ecid.NewPseudoRandom(rng)
+	auth := "test passphrase"
+
+	keyJSON, err := ExportKey(key1, auth, veryLightScryptN, veryLightScryptP)
+	assert.Nil(t, err)
+
+	key2, err := ImportKey(keyJSON, auth)
+	assert.Nil(t, err)
+	assert.Equal(t, key1.Key().D, key2.Key().D)
+	assert.Equal(t, key1.PublicKeyBytes(), key2.PublicKeyBytes())
+
+	// check wrong passphrase triggers error
+	key3, err := ImportKey(keyJSON, "wrong passphrase")
+	assert.NotNil(t, err)
+	assert.Nil(t, key3)
+
+	// check bad scrypt params trigger error
+	keyJSON, err = ExportKey(key1, auth, -1, -1)
+	assert.NotNil(t, err)
+	assert.Nil(t, keyJSON)
+}
@@ -13,14 +13,25 @@ import (
 )
 
 const (
-	logClientIDShort  = "client_id_short"
-	logEntryKey       = "entry_key"
-	logEnvelopeKey    = "envelope_key"
-	logAuthorPubShort = "author_pub_short"
-	logReaderPubShort = "reader_pub_short"
-	logNPages         = "n_pages"
-	logMetadata       = "metadata"
-	logSpeedMbps      = "speed_Mbps"
+	logClientIDShort   = "client_id_short"
+	logEntryKey        = "entry_key"
+	logEnvelopeKey     = "envelope_key"
+	logAuthorPubShort  = "author_pub_short"
+	logReaderPubShort  = "reader_pub_short"
+	logNPages          = "n_pages"
+	logMetadata        = "metadata"
+	logSpeedMbps       = "speed_Mbps"
+	logPointerKey      = "pointer_key"
+	logPointerName     = "pointer_name"
+	logTargetKey       = "target_key"
+	logSequence        = "sequence"
+	logGroupName       = "group_name"
+	logNReaders        = "n_readers"
+	logElapsed         = "elapsed"
+	logNewEnvelopeKey  = "new_envelope_key"
+	logAccount         = "account"
+	logNAuthorKeys     = "n_author_keys"
+	logNSelfReaderKeys = "n_self_reader_keys"
 )
 
 func packingContentFields(authorPub []byte) []zapcore.Field {
@@ -92,3 +103,66 @@ func sharedDocFields(envKey, entryKey fmt.Stringer, authorPub, readerPub []byte)
 		zap.String(logReaderPubShort, id.ShortHex(readerPub[1:9])),
 	}
 }
+
+func sharedManyDocFields(
+	envKey, entryKey fmt.Stringer, nReaders int, elapsed time.Duration,
+) []zapcore.Field {
+	return []zapcore.Field{
+		zap.Stringer(logEnvelopeKey, envKey),
+		zap.Stringer(logEntryKey, entryKey),
+		zap.Int(logNReaders, nReaders),
+		zap.Duration(logElapsed, elapsed),
+	}
+}
+
+func rekeyedDocFields(
+	envKey, newEnvKey fmt.Stringer, nReaders int, elapsed time.Duration,
+) []zapcore.Field {
+	return []zapcore.Field{
+		zap.Stringer(logEnvelopeKey, envKey),
+		zap.Stringer(logNewEnvelopeKey, newEnvKey),
+		zap.Int(logNReaders, nReaders),
+		zap.Duration(logElapsed, elapsed),
+	}
+}
+
+func readerGroupFields(name string, nReaders int) []zapcore.Field {
+	return []zapcore.Field{
+		zap.String(logGroupName, name),
+		zap.Int(logNReaders, nReaders),
+	}
+}
+
+func keychainBackupFields(
+	envKey fmt.Stringer,
+	account string,
+	sequence uint64,
+	nAuthorKeys, nSelfReaderKeys int,
+	elapsed time.Duration,
+) []zapcore.Field {
+	return []zapcore.Field{
+		zap.Stringer(logEnvelopeKey, envKey),
+		zap.String(logAccount, account),
+		zap.Uint64(logSequence, sequence),
+		zap.Int(logNAuthorKeys, nAuthorKeys),
+		zap.Int(logNSelfReaderKeys, nSelfReaderKeys),
+		zap.Duration(logElapsed, elapsed),
+	}
+}
+
+func publishingPointerFields(authorPub []byte, name string, targetKey fmt.Stringer) []zapcore.Field {
+	return []zapcore.Field{
+		zap.String(logAuthorPubShort, id.ShortHex(authorPub[1:9])),
+		zap.String(logPointerName, name),
+		zap.Stringer(logTargetKey, targetKey),
+	}
+}
+
+func pointerFields(pointerKey fmt.Stringer, pointer *api.Pointer) []zapcore.Field {
+	return []zapcore.Field{
+		zap.Stringer(logPointerKey, pointerKey),
+		zap.String(logPointerName, pointer.Name),
+		zap.Stringer(logTargetKey, id.FromBytes(pointer.TargetKey)),
+		zap.Uint64(logSequence, pointer.Sequence),
+	}
+}
@@ -0,0 +1,180 @@
+package author
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/drausin/libri/libri/author/group"
+	"github.com/drausin/libri/libri/author/io/enc"
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+)
+
+// ErrNoReaders indicates when a document is shared without any readers.
+var ErrNoReaders = errors.New("no readers to share with")
+
+// sharedEnvelopePrefix is prepended to the entry key and reader public key when hashing them into
+// the client storage key of the envelope shared with that reader.
+var sharedEnvelopePrefix = []byte("SharedEnvelope/")
+
+// Readers are the recipients of a document, given by their public keys and/or the names of reader
+// groups containing their public keys.
+type Readers struct {
+	// PublicKeys are individual reader public keys.
+	PublicKeys []*ecdsa.PublicKey
+
+	// Groups are the names of reader groups.
+	Groups []string
+}
+
+// SetReaderGroup creates (or replaces) the reader group with the given name and reader public
+// keys.
+func (a *Author) SetReaderGroup(name string, readerPubs []*ecdsa.PublicKey) error {
+	g, err := group.New(name, readerPubs)
+	if err != nil {
+		return a.logAndReturnErr("error creating reader group", err)
+	}
+	if err := a.groups.Store(g); err != nil {
+		return a.logAndReturnErr("error storing reader group", err)
+	}
+	a.logger.Info("saved reader group", readerGroupFields(name, len(readerPubs))...)
+	return nil
+}
+
+// GetReaderGroup returns the reader public keys of the reader group with the given name.
+func (a *Author) GetReaderGroup(name string) ([]*ecdsa.PublicKey, error) {
+	g, err := a.groups.Load(name)
+	if err != nil {
+		return nil, a.logAndReturnErr("error loading reader group", err)
+	}
+	return group.ReaderPublicKeys(g)
+}
+
+// ShareMany creates and uploads a new envelope for each of the readers. The new envelopes have the
+// same entry and entry encryption key as that of envelopeKey and are published in parallel. It
+// returns the shared envelopes and their keys.
+func (a *Author) ShareMany(envKey id.ID, readers *Readers) ([]*api.Document, []id.ID, error) {
+	startTime := time.Now()
+	a.logger.Debug("sharing document with readers", downloadingDocFields(envKey)...)
+	env, err := a.receiver.ReceiveEnvelope(envKey)
+	if err != nil {
+		return nil, nil, a.logAndReturnErr("error receiving envelope", err)
+	}
+	eek, err := a.receiver.GetEEK(env)
+	if err != nil {
+		return nil, nil, a.logAndReturnErr("error getting EEK", err)
+	}
+	authorKey, err := a.authorKeys.Sample()
+	if err != nil {
+		return nil, nil, a.logAndReturnErr("error sampling author keys", err)
+	}
+	entryKey := id.FromBytes(env.EntryKey)
+	sharedEnvs, sharedEnvKeys, err := a.shipToReaders(authorKey, eek, entryKey, readers)
+	if err != nil {
+		return nil, nil, a.logAndReturnErr("error sharing with readers", err)
+	}
+	if len(sharedEnvKeys) == 0 {
+		return nil, nil, a.logAndReturnErr("error sharing with readers", ErrNoReaders)
+	}
+
+	elapsedTime := time.Since(startTime)
+	a.logger.Info("successfully shared document with readers",
+		sharedManyDocFields(envKey, entryKey, len(sharedEnvKeys), elapsedTime)...,
+	)
+	return sharedEnvs, sharedEnvKeys, nil
+}
+
+// shipToReaders ships an envelope for the entry to each of the readers, returning no envelopes if
+// there are no readers.
+func (a *Author) shipToReaders(authorKey ecid.ID, eek *enc.EEK, entryKey id.ID, readers *Readers) (
+	[]*api.Document, []id.ID, error) {
+	readerPubs, err := a.getReaderPubs(readers)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(readerPubs) == 0 {
+		return nil, nil, nil
+	}
+	keks := make([]*enc.KEK, len(readerPubs))
+	readerPubsBytes := make([][]byte, len(readerPubs))
+	for i, readerPub := range readerPubs {
+		keks[i], err = enc.NewKEK(authorKey.Key(), readerPub)
+		if err != nil {
+			return nil, nil, err
+		}
+		readerPubsBytes[i] = ecid.ToPublicKeyBytes(readerPub)
+	}
+	envs, envKeys, err := a.shipper.ShipEnvelopes(keks, eek, entryKey,
+		authorKey.PublicKeyBytes(), readerPubsBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, envKey := range envKeys {
+		if err := a.saveSharedEnvelopeKey(entryKey, readerPubsBytes[i], envKey); err != nil {
+			return nil, nil, err
+		}
+	}
+	return envs, envKeys, nil
+}
+
+// saveSharedEnvelopeKey saves the key of the envelope sharing the entry with the reader, since
+// the author key used for it can't be recovered from the entry and reader alone.
+func (a *Author) saveSharedEnvelopeKey(entryKey id.ID, readerPub []byte, envKey id.ID) error {
+	return a.clientSL.Store(sharedEnvelopeStorageKey(entryKey, readerPub), envKey.Bytes())
+}
+
+// loadSharedEnvelopeKey loads the key of the envelope sharing the entry with the reader, returning
+// nil if the entry hasn't been shared with the reader.
+func (a *Author) loadSharedEnvelopeKey(entryKey id.ID, readerPub []byte) (id.ID, error) {
+	envKeyBytes, err := a.clientSL.Load(sharedEnvelopeStorageKey(entryKey, readerPub))
+	if err != nil || envKeyBytes == nil {
+		return nil, err
+	}
+	return id.FromBytes(envKeyBytes), nil
+}
+
+func sharedEnvelopeStorageKey(entryKey id.ID, readerPub []byte) []byte {
+	preimage := make([]byte, 0, len(sharedEnvelopePrefix)+id.Length+len(readerPub))
+	preimage = append(preimage, sharedEnvelopePrefix...)
+	preimage = append(preimage, entryKey.Bytes()...)
+	preimage = append(preimage, readerPub...)
+	storageKey := sha256.Sum256(preimage)
+	return storageKey[:]
+}
+
+// getReaderPubs returns the distinct reader public keys given individually or via reader groups.
+func (a *Author) getReaderPubs(readers *Readers) ([]*ecdsa.PublicKey, error) {
+	if readers == nil {
+		return nil, nil
+	}
+	readerPubs := make([]*ecdsa.PublicKey, 0, len(readers.PublicKeys))
+	seen := make(map[string]struct{})
+	add := func(readerPub *ecdsa.PublicKey) {
+		readerPubHex := hex.EncodeToString(ecid.ToPublicKeyBytes(readerPub))
+		if _, in := seen[readerPubHex]; !in {
+			seen[readerPubHex] = struct{}{}
+			readerPubs = append(readerPubs, readerPub)
+		}
+	}
+	for _, readerPub := range readers.PublicKeys {
+		add(readerPub)
+	}
+	for _, name := range readers.Groups {
+		g, err := a.groups.Load(name)
+		if err != nil {
+			return nil, err
+		}
+		groupReaderPubs, err := group.ReaderPublicKeys(g)
+		if err != nil {
+			return nil, err
+		}
+		for _, readerPub := range groupReaderPubs {
+			add(readerPub)
+		}
+	}
+	return readerPubs, nil
+}
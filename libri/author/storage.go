@@ -31,6 +31,9 @@ const (
 	// LoggerClientID is a client ID.
 	LoggerClientID = "clientId"
 
+	// LoggerKeyType is an ID key type.
+	LoggerKeyType = "keyType"
+
 	// LoggerKeychainFilepath is a keychain filepath.
 	LoggerKeychainFilepath = "keychainFilepath"
 
@@ -47,13 +50,20 @@ const (
 
 	// nInitialKeys is the number of keys to generate on a keychain
 	nInitialKeys = 64
+
+	// authorKeychainAccount is the HD account of the author keychain.
+	authorKeychainAccount = 0
+
+	// selfReaderKeychainAccount is the HD account of the self reader keychain.
+	selfReaderKeychainAccount = 1
 )
 
 var (
 	clientIDKey = []byte("ClientID")
 )
 
-func loadOrCreateClientID(logger *zap.Logger, nsl storage.NamespaceSL) (ecid.ID, error) {
+func loadOrCreateClientID(logger *zap.Logger, nsl storage.NamespaceSL, keyType ecid.KeyType) (
+	ecid.Identity, error) {
 	bytes, err := nsl.Load(clientIDKey)
 	if err != nil {
 		logger.Error("error loading client ID", zap.Error(err))
@@ -77,13 +87,17 @@ func loadOrCreateClientID(logger *zap.Logger, nsl storage.NamespaceSL) (ecid.ID,
 	}
 
 	// return new client ID
-	clientID := ecid.NewRandom()
-	logger.Info("created new client ID", zap.String(LoggerClientID, clientID.String()))
+	clientID, err := ecid.NewRandomIdentity(keyType)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("created new client ID", zap.String(LoggerClientID, clientID.String()),
+		zap.Stringer(LoggerKeyType, keyType))
 
 	return clientID, saveClientID(nsl, clientID)
 }
 
-func saveClientID(ns storage.NamespaceStorer, clientID ecid.ID) error {
+func saveClientID(ns storage.NamespaceStorer, clientID ecid.Identity) error {
 	bytes, err := proto.Marshal(ecid.ToStored(clientID))
 	if err != nil {
 		return err
@@ -114,11 +128,8 @@ func LoadKeychains(keychainDir, auth string) (
 // CreateKeychains creates the author and self reader keychains in the given keychain directory with
 // the given authentication passphrase and Scrypt parameters.
 func CreateKeychains(logger *zap.Logger, keychainDir, auth string, scryptN, scryptP int) error {
-	if _, err := os.Stat(keychainDir); os.IsNotExist(err) {
-		err := os.MkdirAll(keychainDir, os.ModePerm)
-		if err != nil {
-			return err
-		}
+	if err := mkKeychainDir(keychainDir); err != nil {
+		return err
 	}
 	authorKeychainFP := path.Join(keychainDir, AuthorKeychainFilename)
 	if err := CreateKeychain(logger, authorKeychainFP, auth, scryptN, scryptP); err != nil {
@@ -128,21 +139,61 @@ func CreateKeychains(logger *zap.Logger, keychainDir, auth string, scryptN, scry
 	return CreateKeychain(logger, selfReaderKeysFP, auth, scryptN, scryptP)
 }
 
+// CreateHDKeychains creates the author and self reader keychains in the given keychain directory
+// with keys derived from the given mnemonic seed phrase, so the same keychains can later be
+// recovered from just the mnemonic. The keychains are encrypted with the given authentication
+// passphrase and Scrypt parameters.
+func CreateHDKeychains(
+	logger *zap.Logger, keychainDir, mnemonic, auth string, scryptN, scryptP int,
+) error {
+	authorKeys, err := keychain.FromMnemonic(mnemonic, authorKeychainAccount, nInitialKeys)
+	if err != nil {
+		return err
+	}
+	selfReaderKeys, err := keychain.FromMnemonic(mnemonic, selfReaderKeychainAccount,
+		nInitialKeys)
+	if err != nil {
+		return err
+	}
+	if err := mkKeychainDir(keychainDir); err != nil {
+		return err
+	}
+	authorKeychainFP := path.Join(keychainDir, AuthorKeychainFilename)
+	err = saveNewKeychain(logger, authorKeychainFP, auth, authorKeys, scryptN, scryptP)
+	if err != nil {
+		return err
+	}
+	selfReaderKeysFP := path.Join(keychainDir, SelfReaderKeychainFilename)
+	return saveNewKeychain(logger, selfReaderKeysFP, auth, selfReaderKeys, scryptN, scryptP)
+}
+
 // CreateKeychain creates a keychain in the given filepath with the given auth and Scrypt params.
 func CreateKeychain(logger *zap.Logger, filepath, auth string, scryptN, scryptP int) error {
+	keys := keychain.New(nInitialKeys)
+	return saveNewKeychain(logger, filepath, auth, keys, scryptN, scryptP)
+}
+
+func saveNewKeychain(
+	logger *zap.Logger, filepath, auth string, keys keychain.Keychain, scryptN, scryptP int,
+) error {
 	if info, _ := os.Stat(filepath); info != nil {
 		logger.Error("keychain already exists",
 			zap.String(LoggerKeychainFilepath, filepath))
 		return ErrKeychainExists
 	}
-
-	keys := keychain.New(nInitialKeys)
 	err := keychain.Save(filepath, auth, keys, scryptN, scryptP)
 	if err != nil {
 		return err
 	}
 	logger.Info("saved new keychain", zap.String(LoggerKeychainFilepath, filepath),
-		zap.Int(LoggerKeychainNKeys, nInitialKeys))
+		zap.Int(LoggerKeychainNKeys, len(keys.Keys())))
+	return nil
+}
+
+func mkKeychainDir(keychainDir string) error {
+	if _, err := os.Stat(keychainDir); os.IsNotExist(err) {
+		return os.MkdirAll(keychainDir, os.ModePerm)
+	}
 	return nil
 }
 
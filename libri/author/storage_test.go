@@ -8,6 +8,7 @@ import (
 	"path"
 	"testing"
 
+	"github.com/drausin/libri/libri/author/keychain"
 	"github.com/drausin/libri/libri/common/ecid"
 	clogging "github.com/drausin/libri/libri/common/logging"
 	"github.com/golang/protobuf/proto"
@@ -22,7 +23,8 @@ const (
 func TestLoadOrCreateClientID_ok(t *testing.T) {
 
 	// create new client ID
-	id1, err := loadOrCreateClientID(clogging.NewDevInfoLogger(), &fixedStorerLoader{})
+	id1, err := loadOrCreateClientID(clogging.NewDevInfoLogger(), &fixedStorerLoader{},
+		ecid.KeyTypeSecp256k1)
 	assert.NotNil(t, id1)
 	assert.Nil(t, err)
 
@@ -32,7 +34,8 @@ func TestLoadOrCreateClientID_ok(t *testing.T) {
 	bytes, err := proto.Marshal(ecid.ToStored(peerID2))
 	assert.Nil(t, err)
 
-	id2, err := loadOrCreateClientID(clogging.NewDevInfoLogger(), &fixedStorerLoader{loadBytes: bytes})
+	id2, err := loadOrCreateClientID(clogging.NewDevInfoLogger(),
+		&fixedStorerLoader{loadBytes: bytes}, ecid.KeyTypeSecp256k1)
 
 	assert.Equal(t, peerID2, id2)
 	assert.Nil(t, err)
@@ -41,13 +44,13 @@ func TestLoadOrCreateClientID_ok(t *testing.T) {
 func TestLoadOrCreatePeerID_err(t *testing.T) {
 	id1, err := loadOrCreateClientID(clogging.NewDevInfoLogger(), &fixedStorerLoader{
 		loadErr: errors.New("some load error"),
-	})
+	}, ecid.KeyTypeSecp256k1)
 	assert.Nil(t, id1)
 	assert.NotNil(t, err)
 
 	id2, err := loadOrCreateClientID(clogging.NewDevInfoLogger(), &fixedStorerLoader{
 		loadBytes: []byte("the wrong bytes"),
-	})
+	}, ecid.KeyTypeSecp256k1)
 	assert.Nil(t, id2)
 	assert.NotNil(t, err)
 }
@@ -131,6 +134,60 @@ func TestCreateKeychains_err(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestCreateHDKeychains_ok(t *testing.T) {
+	testKeychainDir, err := ioutil.TempDir("", "author-test-keychains")
+	defer rmDir(testKeychainDir)
+	assert.Nil(t, err)
+	auth := "some secret passphrase"
+	mnemonic, err := keychain.NewMnemonic()
+	assert.Nil(t, err)
+
+	testKeychainSubDir1 := path.Join(testKeychainDir, "sub1")
+	err = CreateHDKeychains(clogging.NewDevInfoLogger(), testKeychainSubDir1, mnemonic, auth,
+		veryLightScryptN, veryLightScryptP)
+	assert.Nil(t, err)
+	authorKeys1, selfReaderKeys1, err := LoadKeychains(testKeychainSubDir1, auth)
+	assert.Nil(t, err)
+
+	// check same mnemonic recovers identical keychains, even with different passphrase
+	testKeychainSubDir2 := path.Join(testKeychainDir, "sub2")
+	err = CreateHDKeychains(clogging.NewDevInfoLogger(), testKeychainSubDir2, mnemonic,
+		"other passphrase", veryLightScryptN, veryLightScryptP)
+	assert.Nil(t, err)
+	authorKeys2, selfReaderKeys2, err := LoadKeychains(testKeychainSubDir2, "other passphrase")
+	assert.Nil(t, err)
+	assert.Equal(t, authorKeys1, authorKeys2)
+	assert.Equal(t, selfReaderKeys1, selfReaderKeys2)
+	assert.NotEqual(t, authorKeys1, selfReaderKeys1)
+}
+
+func TestCreateHDKeychains_err(t *testing.T) {
+	testKeychainDir, err := ioutil.TempDir("", "author-test-keychains")
+	defer rmDir(testKeychainDir)
+	assert.Nil(t, err)
+	auth := "some secret passphrase"
+	mnemonic, err := keychain.NewMnemonic()
+	assert.Nil(t, err)
+
+	// check invalid mnemonic triggers error
+	err = CreateHDKeychains(clogging.NewDevInfoLogger(), testKeychainDir, "not a mnemonic",
+		auth, veryLightScryptN, veryLightScryptP)
+	assert.Equal(t, keychain.ErrInvalidMnemonic, err)
+
+	// check existing self reader keychain triggers error
+	selfReaderKeysFP := path.Join(testKeychainDir, SelfReaderKeychainFilename)
+	err = ioutil.WriteFile(selfReaderKeysFP, []byte("some random stuff"), os.ModePerm)
+	assert.Nil(t, err)
+	err = CreateHDKeychains(clogging.NewDevInfoLogger(), testKeychainDir, mnemonic, auth,
+		veryLightScryptN, veryLightScryptP)
+	assert.Equal(t, ErrKeychainExists, err)
+
+	// check existing author keychain triggers error
+	err = CreateHDKeychains(clogging.NewDevInfoLogger(), testKeychainDir, mnemonic, auth,
+		veryLightScryptN, veryLightScryptP)
+	assert.Equal(t, ErrKeychainExists, err)
+}
+
 func TestCreateKeychain(t *testing.T) {
 	testKeychainDir, err := ioutil.TempDir("", "author-test-keychains")
 	defer rmDir(testKeychainDir)
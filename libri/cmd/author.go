@@ -26,6 +26,9 @@ const (
 	passphraseVar        = "passphrase"
 	authorLibrariansFlag = "authorLibrarians"
 	timeoutFlag          = "timeout"
+	allowUnsignedFlag    = "allowUnsignedEntries"
+	replicasFlag         = "replicas"
+	matchingValuesFlag   = "matchingValues"
 )
 
 // authorCmd represents the author command
@@ -43,6 +46,12 @@ func init() {
 		"comma-separated addresses (IPv4:Port) of librarian(s)")
 	authorCmd.PersistentFlags().Int(timeoutFlag, 5,
 		"timeout (seconds) for requests to librarians")
+	authorCmd.PersistentFlags().Bool(allowUnsignedFlag, false,
+		"accept legacy entries without author signatures when downloading")
+	authorCmd.PersistentFlags().Uint(replicasFlag, 0,
+		"number of replicas librarians must store for each document (0 for their default)")
+	authorCmd.PersistentFlags().Uint(matchingValuesFlag, 0,
+		"number of librarians that must return the same document (0 for their default)")
 
 	// bind viper flags
 	viper.SetEnvPrefix(envVarPrefix) // look for env vars with "LIBRI_" prefix
@@ -88,12 +97,27 @@ func (*authorConfigGetterImpl) get(librariansFlag string) (*author.Config, *zap.
 	config := author.NewDefaultConfig().
 		WithDataDir(viper.GetString(dataDirFlag)).
 		WithDefaultDBDir(). // depends on DataDir
-		WithLogLevel(getLogLevel())
+		WithLogLevel(getLogLevel()).
+		WithAllowUnsignedEntries(viper.GetBool(allowUnsignedFlag))
 	timeout := time.Duration(viper.GetInt(timeoutFlag) * 1e9)
 	config.Publish.PutTimeout = timeout
 	config.Publish.GetTimeout = timeout
+	config.Publish.PutNReplicas = uint32(viper.GetInt(replicasFlag))
+	config.Publish.GetNMatchingValues = uint32(viper.GetInt(matchingValuesFlag))
 
 	logger := clogging.NewDevLogger(config.LogLevel)
+	keyType, err := getKeyType()
+	if err != nil {
+		logger.Error("unable to parse key type", zap.Error(err))
+		return nil, logger, err
+	}
+	config.WithKeyType(keyType)
+	signatureFormat, err := getSignatureFormat()
+	if err != nil {
+		logger.Error("unable to parse signature format", zap.Error(err))
+		return nil, logger, err
+	}
+	config.WithSignatureFormat(signatureFormat)
 	librarianNetAddrs, err := server.ParseAddrs(viper.GetStringSlice(librariansFlag))
 	if err != nil {
 		logger.Error("unable to parse librarian address", zap.Error(err))
@@ -105,7 +129,12 @@ func (*authorConfigGetterImpl) get(librariansFlag string) (*author.Config, *zap.
 		zap.String(librariansFlag, fmt.Sprintf("%v", config.LibrarianAddrs)),
 		zap.String(dataDirFlag, config.DataDir),
 		zap.Stringer(logLevelFlag, config.LogLevel),
+		zap.Stringer(keyTypeFlag, config.KeyType),
+		zap.Stringer(signatureFormatFlag, config.SignatureFormat),
 		zap.Int(timeoutFlag, int(timeout.Seconds())),
+		zap.Bool(allowUnsignedFlag, config.AllowUnsignedEntries),
+		zap.Uint32(replicasFlag, config.Publish.PutNReplicas),
+		zap.Uint32(matchingValuesFlag, config.Publish.GetNMatchingValues),
 	)
 	return config, logger, nil
 }
@@ -131,7 +160,7 @@ type authorUploaderImpl struct{}
 
 func (*authorUploaderImpl) upload(author *lauthor.Author, content io.Reader, mediaType string) (
 	id.ID, error) {
-	_, envelopeKey, err := author.Upload(content, mediaType)
+	_, envelopeKey, _, err := author.Upload(content, mediaType, nil)
 	return envelopeKey, err
 }
 
@@ -11,7 +11,9 @@ import (
 
 	"github.com/drausin/libri/libri/author"
 	"github.com/drausin/libri/libri/author/keychain"
+	"github.com/drausin/libri/libri/common/ecid"
 	"github.com/drausin/libri/libri/common/logging"
+	"github.com/drausin/libri/libri/librarian/client"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -66,12 +68,24 @@ func TestAuthorConfigGetter_get_ok(t *testing.T) {
 	viper.Set(dataDirFlag, dataDir)
 	viper.Set(logLevelFlag, logLevel)
 	viper.Set(authorLibrariansFlag, libAddrsArg)
+	viper.Set(allowUnsignedFlag, true)
+	viper.Set(replicasFlag, 5)
+	viper.Set(matchingValuesFlag, 2)
+	viper.Set(keyTypeFlag, ecid.KeyTypeP256.String())
+	defer viper.Set(keyTypeFlag, ecid.KeyTypeSecp256k1.String())
+	viper.Set(signatureFormatFlag, client.JWTSignatures.String())
+	defer viper.Set(signatureFormatFlag, client.DefaultSignatureFormat.String())
 	acg := &authorConfigGetterImpl{}
 
 	config, logger, err := acg.get(authorLibrariansFlag)
 
 	assert.Nil(t, err)
 	assert.Equal(t, logLevel, config.LogLevel)
+	assert.True(t, config.AllowUnsignedEntries)
+	assert.Equal(t, uint32(5), config.Publish.PutNReplicas)
+	assert.Equal(t, uint32(2), config.Publish.GetNMatchingValues)
+	assert.Equal(t, ecid.KeyTypeP256, config.KeyType)
+	assert.Equal(t, client.JWTSignatures, config.SignatureFormat)
 	assert.Equal(t, len(libAddrs), len(config.LibrarianAddrs))
 	for i, la := range config.LibrarianAddrs {
 		assert.Equal(t, libAddrs[i], la.String())
@@ -92,6 +106,22 @@ func TestAuthorConfigGetter_get_err(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Nil(t, config)
 	assert.NotNil(t, logger) // still should have been created
+
+	viper.Set(authorLibrariansFlag, "127.0.0.1:1234")
+	viper.Set(keyTypeFlag, "bad key type")
+	defer viper.Set(keyTypeFlag, ecid.KeyTypeSecp256k1.String())
+	config, logger, err = acg.get(authorLibrariansFlag)
+	assert.Equal(t, ecid.ErrUnknownKeyType, err)
+	assert.Nil(t, config)
+	assert.NotNil(t, logger)
+
+	viper.Set(keyTypeFlag, ecid.KeyTypeSecp256k1.String())
+	viper.Set(signatureFormatFlag, "bad format")
+	defer viper.Set(signatureFormatFlag, client.DefaultSignatureFormat.String())
+	config, logger, err = acg.get(authorLibrariansFlag)
+	assert.Equal(t, client.ErrUnknownSignatureFormat, err)
+	assert.Nil(t, config)
+	assert.NotNil(t, logger)
 }
 
 type fixedAuthorConfigGetter struct {
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"os"
+
+	lauthor "github.com/drausin/libri/libri/author"
+	"github.com/drausin/libri/libri/author/keychain"
+	cerrors "github.com/drausin/libri/libri/common/errors"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	accountFlag = "account"
+
+	// backupScryptN and backupScryptP are the scrypt parameters for deriving the keychain backup
+	// secrets. They must be the same on every device, so they are not configurable.
+	backupScryptN = keychain.StandardScryptN
+	backupScryptP = keychain.StandardScryptP
+)
+
+var errMissingAccount = errors.New("missing keychain backup account name")
+
+// keychainPushCmd represents the keychain push command
+var keychainPushCmd = &cobra.Command{
+	Use:   "push",
+	Short: "back up the author keychains to the libri network",
+	Long: `Back up the author keychains to the libri network, encrypted with keys derived from the
+keychains passphrase and an account name. Keys in an existing backup for the passphrase and account
+are merged into both the backup and the local keychains, so keys added on different devices are
+combined. Changing the keychains passphrase with passwd means a subsequent push creates a new
+backup.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newKeychainManager().push()
+	},
+}
+
+// keychainPullCmd represents the keychain pull command
+var keychainPullCmd = &cobra.Command{
+	Use:   "pull",
+	Short: "restore the author keychains from a backup in the libri network",
+	Long: `Restore the author keychains from the backup for a passphrase and account name. If local
+keychains already exist, the backed up keys are merged into them; otherwise, new local keychains
+are created and encrypted with the same passphrase.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newKeychainManager().pull()
+	},
+}
+
+func init() {
+	keychainCmd.AddCommand(keychainPushCmd)
+	keychainCmd.AddCommand(keychainPullCmd)
+
+	for _, cmd := range []*cobra.Command{keychainPushCmd, keychainPullCmd} {
+		cmd.Flags().String(accountFlag, "", "account name of the keychain backup")
+	}
+
+	// bind viper flags
+	viper.SetEnvPrefix(envVarPrefix) // look for env vars with "LIBRI_" prefix
+	viper.AutomaticEnv()             // read in environment variables that match
+	cerrors.MaybePanic(viper.BindPFlags(keychainPushCmd.Flags()))
+	cerrors.MaybePanic(viper.BindPFlags(keychainPullCmd.Flags()))
+}
+
+func (m *keychainManagerImpl) push() error {
+	account := viper.GetString(accountFlag)
+	if account == "" {
+		return errMissingAccount
+	}
+	keychainDir, passphrase, err := m.getDirPassphrase()
+	if err != nil {
+		return err
+	}
+	kcs, err := loadKeychains(keychainDir, passphrase)
+	if err != nil {
+		return err
+	}
+	author, logger, err := m.ag.get(kcs[0], kcs[1])
+	if err != nil {
+		return err
+	}
+	envKey, err := m.kb.backup(author, kcs[0], kcs[1], passphrase, account, m.backupScryptN,
+		m.backupScryptP)
+	if err != nil {
+		return err
+	}
+
+	// the backup may have merged in keys from other devices
+	if err := saveKeychains(keychainDir, passphrase, kcs, m.scryptN, m.scryptP); err != nil {
+		return err
+	}
+	logger.Info("pushed keychains",
+		zap.String(accountFlag, account),
+		zap.Stringer("envelope_key", envKey),
+	)
+	return nil
+}
+
+func (m *keychainManagerImpl) pull() error {
+	account := viper.GetString(accountFlag)
+	if account == "" {
+		return errMissingAccount
+	}
+	keychainDir := viper.GetString(keychainDirFlag)
+	if keychainDir == "" {
+		return errMissingKeychainDir
+	}
+	missing, err := lauthor.MissingKeychains(keychainDir)
+	if err != nil {
+		return err
+	}
+	passphrase, err := m.getPassphrase()
+	if err != nil {
+		return err
+	}
+	var kcs []keychain.Keychain
+	if missing {
+		kcs = []keychain.Keychain{keychain.New(0), keychain.New(0)}
+	} else if kcs, err = loadKeychains(keychainDir, passphrase); err != nil {
+		return err
+	}
+
+	// restoring only needs the keys derived from the passphrase and account
+	author, logger, err := m.ag.get(keychain.New(0), keychain.New(0))
+	if err != nil {
+		return err
+	}
+	authorKeys, selfReaderKeys, err := m.kb.restore(author, passphrase, account,
+		m.backupScryptN, m.backupScryptP)
+	if err != nil {
+		return err
+	}
+	kcs[0].Add(authorKeys.Keys()...)
+	kcs[1].Add(selfReaderKeys.Keys()...)
+
+	if err := os.MkdirAll(keychainDir, os.ModePerm); err != nil {
+		return err
+	}
+	if err := saveKeychains(keychainDir, passphrase, kcs, m.scryptN, m.scryptP); err != nil {
+		return err
+	}
+	for i, name := range keychainNames {
+		kcFilepath := keychainFilepath(keychainDir, name)
+		logger.Info("pulled keychain",
+			zap.String(accountFlag, account),
+			zap.String(lauthor.LoggerKeychainFilepath, kcFilepath),
+			zap.Int(lauthor.LoggerKeychainNKeys, len(kcs[i].Keys())),
+		)
+	}
+	return nil
+}
+
+// loadKeychains loads the author and self-reader keychains, in that order.
+func loadKeychains(keychainDir, passphrase string) ([]keychain.Keychain, error) {
+	kcs := make([]keychain.Keychain, len(keychainNames))
+	for i, name := range keychainNames {
+		kc, err := keychain.Load(keychainFilepath(keychainDir, name), passphrase)
+		if err != nil {
+			return nil, err
+		}
+		kcs[i] = kc
+	}
+	return kcs, nil
+}
+
+// saveKeychains saves the author and self-reader keychains, in that order. Both are written to
+// temporary files before either replaces its existing file, so a failed write leaves both
+// unchanged. Any temporary file left by a failed save is overwritten by the next one.
+func saveKeychains(
+	keychainDir, auth string, kcs []keychain.Keychain, scryptN, scryptP int,
+) error {
+	tmpFilepaths := make([]string, len(keychainNames))
+	for i, name := range keychainNames {
+		tmpFilepaths[i] = keychainFilepath(keychainDir, name) + ".tmp"
+		if err := keychain.Save(tmpFilepaths[i], auth, kcs[i], scryptN, scryptP); err != nil {
+			return err
+		}
+	}
+	for i, name := range keychainNames {
+		if err := os.Rename(tmpFilepaths[i], keychainFilepath(keychainDir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// authorKeychainBackuper just wraps *author.Author BackupKeychains and RestoreKeychains calls for
+// the same reason as authorUploader.
+type authorKeychainBackuper interface {
+	backup(
+		author *lauthor.Author,
+		authorKeys, selfReaderKeys keychain.Keychain,
+		passphrase, account string,
+		scryptN, scryptP int,
+	) (id.ID, error)
+
+	restore(author *lauthor.Author, passphrase, account string, scryptN, scryptP int) (
+		keychain.Keychain, keychain.Keychain, error)
+}
+
+type authorKeychainBackuperImpl struct{}
+
+func (*authorKeychainBackuperImpl) backup(
+	author *lauthor.Author,
+	authorKeys, selfReaderKeys keychain.Keychain,
+	passphrase, account string,
+	scryptN, scryptP int,
+) (id.ID, error) {
+	return author.BackupKeychains(authorKeys, selfReaderKeys, passphrase, account, scryptN,
+		scryptP)
+}
+
+func (*authorKeychainBackuperImpl) restore(
+	author *lauthor.Author, passphrase, account string, scryptN, scryptP int,
+) (keychain.Keychain, keychain.Keychain, error) {
+	return author.RestoreKeychains(passphrase, account, scryptN, scryptP)
+}
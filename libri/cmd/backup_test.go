@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/drausin/libri/libri/author"
+	"github.com/drausin/libri/libri/author/keychain"
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/common/logging"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+const testBackupAccount = "some account"
+
+func TestKeychainPushCmd_err(t *testing.T) {
+	viper.Set(accountFlag, "")
+	err := keychainPushCmd.RunE(keychainPushCmd, []string{})
+	assert.Equal(t, errMissingAccount, err)
+}
+
+func TestKeychainPullCmd_err(t *testing.T) {
+	viper.Set(accountFlag, "")
+	err := keychainPullCmd.RunE(keychainPullCmd, []string{})
+	assert.Equal(t, errMissingAccount, err)
+}
+
+func TestKeychainManager_push_ok(t *testing.T) {
+	keychainDir, passphrase := setUpKeychains(t)
+	defer tearDownKeychains(t, keychainDir)
+	viper.Set(accountFlag, testBackupAccount)
+	defer viper.Set(accountFlag, "")
+
+	// backup merges in a key from another device
+	rng := rand.New(rand.NewSource(0))
+	kb := &fixedAuthorKeychainBackuper{
+		envKey:    id.NewPseudoRandom(rng),
+		mergedKey: ecid.NewPseudoRandom(rng),
+	}
+	m := newTestKeychainManager()
+	m.ag = &fixedAuthorGetter{logger: server.NewDevInfoLogger()}
+	m.kb = kb
+	err := m.push()
+	assert.Nil(t, err)
+	assert.Equal(t, passphrase, kb.passphrase)
+	assert.Equal(t, testBackupAccount, kb.account)
+
+	// check merged key is saved in local author keychain
+	authorKeys, err := keychain.Load(keychainFilepath(keychainDir, authorKeychainName),
+		passphrase)
+	assert.Nil(t, err)
+	assert.Len(t, authorKeys.Keys(), 65)
+	_, in := authorKeys.Get(kb.mergedKey.PublicKeyBytes())
+	assert.True(t, in)
+}
+
+func TestKeychainManager_push_err(t *testing.T) {
+	keychainDir, _ := setUpKeychains(t)
+	defer tearDownKeychains(t, keychainDir)
+	m := newTestKeychainManager()
+	m.ag = &fixedAuthorGetter{logger: server.NewDevInfoLogger()}
+	m.kb = &fixedAuthorKeychainBackuper{}
+
+	// check missing account triggers error
+	viper.Set(accountFlag, "")
+	err := m.push()
+	assert.Equal(t, errMissingAccount, err)
+	viper.Set(accountFlag, testBackupAccount)
+	defer viper.Set(accountFlag, "")
+
+	// check missing keychains trigger error
+	viper.Set(keychainDirFlag, path.Join(keychainDir, "missing"))
+	err = m.push()
+	assert.Equal(t, errKeychainsNotExist, err)
+	viper.Set(keychainDirFlag, keychainDir)
+
+	// check author getter error bubbles up
+	m.ag = &fixedAuthorGetter{err: errors.New("some get error")}
+	err = m.push()
+	assert.NotNil(t, err)
+	m.ag = &fixedAuthorGetter{logger: server.NewDevInfoLogger()}
+
+	// check backup error bubbles up
+	m.kb = &fixedAuthorKeychainBackuper{err: errors.New("some backup error")}
+	err = m.push()
+	assert.NotNil(t, err)
+}
+
+func TestKeychainManager_pull_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	restoredAuthorKeys := keychain.FromECIDs([]ecid.ID{ecid.NewPseudoRandom(rng)})
+	restoredSelfReaderKeys := keychain.FromECIDs([]ecid.ID{ecid.NewPseudoRandom(rng)})
+	kb := &fixedAuthorKeychainBackuper{
+		authorKeys:     restoredAuthorKeys,
+		selfReaderKeys: restoredSelfReaderKeys,
+	}
+	viper.Set(accountFlag, testBackupAccount)
+	defer viper.Set(accountFlag, "")
+
+	// check pulling without local keychains creates them
+	parentDir, err := ioutil.TempDir("", "test-keychains")
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, os.RemoveAll(parentDir)) }()
+	keychainDir, passphrase := path.Join(parentDir, "keychains"), "some test passphrase"
+	viper.Set(keychainDirFlag, keychainDir)
+	viper.Set(passphraseVar, passphrase)
+	defer viper.Set(passphraseVar, "")
+
+	m := newTestKeychainManager()
+	m.ag = &fixedAuthorGetter{logger: server.NewDevInfoLogger()}
+	m.kb = kb
+	err = m.pull()
+	assert.Nil(t, err)
+	assert.Equal(t, passphrase, kb.passphrase)
+	assert.Equal(t, testBackupAccount, kb.account)
+	kcs, err := loadKeychains(keychainDir, passphrase)
+	assert.Nil(t, err)
+	assert.Len(t, kcs[0].Keys(), 1)
+	assert.Len(t, kcs[1].Keys(), 1)
+
+	// check pulling with local keychains merges into them
+	localDir, localPassphrase := setUpKeychains(t)
+	defer tearDownKeychains(t, localDir)
+	err = m.pull()
+	assert.Nil(t, err)
+	kcs, err = loadKeychains(localDir, localPassphrase)
+	assert.Nil(t, err)
+	assert.Len(t, kcs[0].Keys(), 65)
+	assert.Len(t, kcs[1].Keys(), 65)
+	_, in := kcs[0].Get(restoredAuthorKeys.Keys()[0].PublicKeyBytes())
+	assert.True(t, in)
+}
+
+func TestKeychainManager_pull_err(t *testing.T) {
+	keychainDir, passphrase := setUpKeychains(t)
+	defer tearDownKeychains(t, keychainDir)
+	m := newTestKeychainManager()
+	m.ag = &fixedAuthorGetter{logger: server.NewDevInfoLogger()}
+	m.kb = &fixedAuthorKeychainBackuper{}
+
+	// check missing account triggers error
+	viper.Set(accountFlag, "")
+	err := m.pull()
+	assert.Equal(t, errMissingAccount, err)
+	viper.Set(accountFlag, testBackupAccount)
+	defer viper.Set(accountFlag, "")
+
+	// check missing keychain dir triggers error
+	viper.Set(keychainDirFlag, "")
+	err = m.pull()
+	assert.Equal(t, errMissingKeychainDir, err)
+	viper.Set(keychainDirFlag, keychainDir)
+
+	// check passphrase getter error bubbles up
+	viper.Set(passphraseVar, "")
+	m.pg = &fixedPassphraseGetter{err: errors.New("some get error")}
+	err = m.pull()
+	assert.NotNil(t, err)
+
+	// check wrong passphrase for local keychains triggers error
+	viper.Set(passphraseVar, passphrase+" wrong")
+	err = m.pull()
+	assert.NotNil(t, err)
+	viper.Set(passphraseVar, passphrase)
+
+	// check author getter error bubbles up
+	m.ag = &fixedAuthorGetter{err: errors.New("some get error")}
+	err = m.pull()
+	assert.NotNil(t, err)
+	m.ag = &fixedAuthorGetter{logger: server.NewDevInfoLogger()}
+
+	// check restore error bubbles up
+	m.kb = &fixedAuthorKeychainBackuper{err: author.ErrMissingKeychainBackup}
+	err = m.pull()
+	assert.Equal(t, author.ErrMissingKeychainBackup, err)
+}
+
+type fixedAuthorKeychainBackuper struct {
+	envKey         id.ID
+	mergedKey      ecid.ID
+	authorKeys     keychain.Keychain
+	selfReaderKeys keychain.Keychain
+	err            error
+
+	passphrase string
+	account    string
+}
+
+func (f *fixedAuthorKeychainBackuper) backup(
+	author *author.Author,
+	authorKeys, selfReaderKeys keychain.Keychain,
+	passphrase, account string,
+	scryptN, scryptP int,
+) (id.ID, error) {
+	f.passphrase, f.account = passphrase, account
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.mergedKey != nil {
+		authorKeys.Add(f.mergedKey)
+	}
+	return f.envKey, nil
+}
+
+func (f *fixedAuthorKeychainBackuper) restore(
+	author *author.Author, passphrase, account string, scryptN, scryptP int,
+) (keychain.Keychain, keychain.Keychain, error) {
+	f.passphrase, f.account = passphrase, account
+	return f.authorKeys, f.selfReaderKeys, f.err
+}
@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	lauthor "github.com/drausin/libri/libri/author"
+	"github.com/drausin/libri/libri/author/keychain"
+	cerrors "github.com/drausin/libri/libri/common/errors"
+	clogging "github.com/drausin/libri/libri/common/logging"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	thresholdFlag  = "threshold"
+	nSharesFlag    = "shares"
+	sharesDirFlag  = "sharesDir"
+	shareFilesFlag = "shareFiles"
+
+	// shareTextComment starts the header lines around printed shares
+	shareTextComment = "#"
+)
+
+var errEmptySharePassphrase = errors.New("share passphrase cannot be empty")
+
+// keychainEscrowCmd represents the keychain escrow command
+var keychainEscrowCmd = &cobra.Command{
+	Use:   "escrow",
+	Short: "split a keychain into Shamir shares for recovery by multiple parties",
+	Long: `Split the private keys of a keychain into n shares, any t of which can reconstruct it.
+With --sharesDir, each share is written to its own file encrypted with its own passphrase;
+otherwise, the shares are printed as text.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newKeychainManager().escrow(os.Stdout)
+	},
+}
+
+// keychainReconstructCmd represents the keychain reconstruct command
+var keychainReconstructCmd = &cobra.Command{
+	Use:   "reconstruct",
+	Short: "reconstruct a keychain from its Shamir shares",
+	Long: `Reconstruct a keychain from enough of the shares created by escrow, given either as
+encrypted share files with --shareFiles or as share text lines on stdin.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newKeychainManager().reconstruct(os.Stdin)
+	},
+}
+
+func init() {
+	keychainCmd.AddCommand(keychainEscrowCmd)
+	keychainCmd.AddCommand(keychainReconstructCmd)
+
+	keychainEscrowCmd.Flags().IntP(thresholdFlag, "t", 2,
+		"number of shares needed to reconstruct the keychain")
+	keychainEscrowCmd.Flags().IntP(nSharesFlag, "n", 3, "number of shares to create")
+	keychainEscrowCmd.Flags().String(sharesDirFlag, "",
+		"directory to write encrypted share files to (default print shares as text)")
+	keychainReconstructCmd.Flags().StringSlice(shareFilesFlag, nil,
+		"comma-separated paths of encrypted share files (default read share text from stdin)")
+
+	// bind viper flags
+	viper.SetEnvPrefix(envVarPrefix) // look for env vars with "LIBRI_" prefix
+	viper.AutomaticEnv()             // read in environment variables that match
+	cerrors.MaybePanic(viper.BindPFlags(keychainEscrowCmd.Flags()))
+	cerrors.MaybePanic(viper.BindPFlags(keychainReconstructCmd.Flags()))
+}
+
+func (m *keychainManagerImpl) escrow(w io.Writer) error {
+	kcFilepath, _, kc, err := m.loadSelected()
+	if err != nil {
+		return err
+	}
+	shares, err := keychain.Escrow(kc, viper.GetInt(thresholdFlag), viper.GetInt(nSharesFlag))
+	if err != nil {
+		return err
+	}
+	name := viper.GetString(keychainFlag)
+	sharesDir := viper.GetString(sharesDirFlag)
+	if sharesDir == "" {
+		return printShares(w, name, shares)
+	}
+	if err := os.MkdirAll(sharesDir, 0700); err != nil {
+		return err
+	}
+	logger := clogging.NewDevLogger(getLogLevel())
+	for i, share := range shares {
+		fmt.Printf("Enter passphrase for share %d of %d: ", i+1, len(shares))
+		sharePassphrase, err := m.sharePG.get()
+		if err != nil {
+			return err
+		}
+		fmt.Println()
+		if sharePassphrase == "" {
+			return errEmptySharePassphrase
+		}
+		shareFilepath := path.Join(sharesDir,
+			fmt.Sprintf("%s-share-%d-of-%d", name, i+1, len(shares)))
+		err = keychain.SaveShare(shareFilepath, sharePassphrase, share, m.scryptN, m.scryptP)
+		if err != nil {
+			return err
+		}
+		logger.Info("saved keychain share",
+			zap.String(lauthor.LoggerKeychainFilepath, kcFilepath),
+			zap.String(shareFilesFlag, shareFilepath),
+		)
+	}
+	return nil
+}
+
+func (m *keychainManagerImpl) reconstruct(r io.Reader) error {
+	name := viper.GetString(keychainFlag)
+	if name != authorKeychainName && name != selfReaderKeychainName {
+		return errUnknownKeychain
+	}
+	keychainDir := viper.GetString(keychainDirFlag)
+	if keychainDir == "" {
+		return errMissingKeychainDir
+	}
+	kcFilepath := keychainFilepath(keychainDir, name)
+	if info, _ := os.Stat(kcFilepath); info != nil {
+		return lauthor.ErrKeychainExists
+	}
+
+	var shares []*keychain.KeychainShare
+	var err error
+	if shareFilepaths := viper.GetStringSlice(shareFilesFlag); len(shareFilepaths) > 0 {
+		shares, err = m.loadShares(shareFilepaths)
+	} else {
+		shares, err = readShares(r)
+	}
+	if err != nil {
+		return err
+	}
+	kc, err := keychain.Reconstruct(shares)
+	if err != nil {
+		return err
+	}
+	passphrase, err := m.ps.set()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(keychainDir, os.ModePerm); err != nil {
+		return err
+	}
+	if err := saveKeychain(kcFilepath, passphrase, kc, m.scryptN, m.scryptP); err != nil {
+		return err
+	}
+	logger := clogging.NewDevLogger(getLogLevel())
+	logger.Info("reconstructed keychain",
+		zap.String(lauthor.LoggerKeychainFilepath, kcFilepath),
+		zap.Int(lauthor.LoggerKeychainNKeys, len(kc.Keys())),
+	)
+	return nil
+}
+
+func (m *keychainManagerImpl) loadShares(shareFilepaths []string) ([]*keychain.KeychainShare,
+	error) {
+	shares := make([]*keychain.KeychainShare, len(shareFilepaths))
+	for i, shareFilepath := range shareFilepaths {
+		fmt.Printf("Enter passphrase for share file %s: ", shareFilepath)
+		sharePassphrase, err := m.sharePG.get()
+		if err != nil {
+			return nil, err
+		}
+		fmt.Println()
+		if shares[i], err = keychain.LoadShare(shareFilepath, sharePassphrase); err != nil {
+			return nil, err
+		}
+	}
+	return shares, nil
+}
+
+func printShares(w io.Writer, name string, shares []*keychain.KeychainShare) error {
+	for i, share := range shares {
+		text, err := keychain.ShareToText(share)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s %s keychain share %d of %d (any %d reconstruct)\n%s\n\n",
+			shareTextComment, name, i+1, len(shares), share.Threshold, text)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readShares reads shares from text with one share per line, ignoring blank and comment lines.
+func readShares(r io.Reader) ([]*keychain.KeychainShare, error) {
+	shares := make([]*keychain.KeychainShare, 0)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, bufio.MaxScanTokenSize), 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, shareTextComment) {
+			continue
+		}
+		share, err := keychain.ShareFromText(line)
+		if err != nil {
+			return nil, err
+		}
+		shares = append(shares, share)
+	}
+	return shares, scanner.Err()
+}
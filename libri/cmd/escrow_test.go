@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/drausin/libri/libri/author"
+	"github.com/drausin/libri/libri/author/keychain"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeychainManager_escrowReconstruct_text(t *testing.T) {
+	keychainDir, passphrase := setUpKeychains(t)
+	defer tearDownKeychains(t, keychainDir)
+	authorKeys1, _, err := author.LoadKeychains(keychainDir, passphrase)
+	assert.Nil(t, err)
+
+	m := newTestKeychainManager()
+	viper.Set(keychainFlag, authorKeychainName)
+	viper.Set(thresholdFlag, 2)
+	viper.Set(nSharesFlag, 3)
+	viper.Set(sharesDirFlag, "")
+	out := new(bytes.Buffer)
+	err = m.escrow(out)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, strings.Count(out.String(), shareTextComment+" author keychain share"))
+
+	// drop first share and reconstruct into new keychain dir
+	shareTexts := strings.SplitN(out.String(), "\n\n", 2)[1]
+	newKeychainDir := path.Join(keychainDir, "reconstructed")
+	viper.Set(keychainDirFlag, newKeychainDir)
+	viper.Set(shareFilesFlag, nil)
+	m.ps = &fixedPassphraseSetter{passphrase: passphrase}
+	err = m.reconstruct(strings.NewReader(shareTexts))
+	assert.Nil(t, err)
+
+	authorKeys2, err := keychain.Load(path.Join(newKeychainDir, author.AuthorKeychainFilename),
+		passphrase)
+	assert.Nil(t, err)
+	assert.Equal(t, authorKeys1, authorKeys2)
+}
+
+func TestKeychainManager_escrowReconstruct_files(t *testing.T) {
+	keychainDir, passphrase := setUpKeychains(t)
+	defer tearDownKeychains(t, keychainDir)
+	_, selfReaderKeys1, err := author.LoadKeychains(keychainDir, passphrase)
+	assert.Nil(t, err)
+
+	m := newTestKeychainManager()
+	m.sharePG = &fixedPassphraseGetter{passphrase: "some share passphrase"}
+	sharesDir := path.Join(keychainDir, "shares")
+	viper.Set(keychainFlag, selfReaderKeychainName)
+	viper.Set(thresholdFlag, 2)
+	viper.Set(nSharesFlag, 3)
+	viper.Set(sharesDirFlag, sharesDir)
+	err = m.escrow(new(bytes.Buffer))
+	assert.Nil(t, err)
+	shareFiles, err := ioutil.ReadDir(sharesDir)
+	assert.Nil(t, err)
+	assert.Len(t, shareFiles, 3)
+
+	// remove self-reader keychain and reconstruct it from last two shares
+	err = os.Remove(path.Join(keychainDir, author.SelfReaderKeychainFilename))
+	assert.Nil(t, err)
+	viper.Set(shareFilesFlag, []string{
+		path.Join(sharesDir, "self-reader-share-2-of-3"),
+		path.Join(sharesDir, "self-reader-share-3-of-3"),
+	})
+	m.ps = &fixedPassphraseSetter{passphrase: passphrase}
+	err = m.reconstruct(new(bytes.Buffer))
+	assert.Nil(t, err)
+	viper.Set(shareFilesFlag, nil)
+
+	_, selfReaderKeys2, err := author.LoadKeychains(keychainDir, passphrase)
+	assert.Nil(t, err)
+	assert.Equal(t, selfReaderKeys1, selfReaderKeys2)
+}
+
+func TestKeychainManager_escrow_err(t *testing.T) {
+	keychainDir, _ := setUpKeychains(t)
+	defer tearDownKeychains(t, keychainDir)
+	m := newTestKeychainManager()
+	viper.Set(keychainFlag, authorKeychainName)
+	viper.Set(sharesDirFlag, "")
+
+	// check unknown keychain triggers error
+	viper.Set(keychainFlag, "some other keychain")
+	err := m.escrow(new(bytes.Buffer))
+	assert.Equal(t, errUnknownKeychain, err)
+
+	// check bad threshold triggers error
+	viper.Set(keychainFlag, authorKeychainName)
+	viper.Set(thresholdFlag, 4)
+	viper.Set(nSharesFlag, 3)
+	err = m.escrow(new(bytes.Buffer))
+	assert.NotNil(t, err)
+
+	// check share passphrase getter error bubbles up
+	viper.Set(thresholdFlag, 2)
+	viper.Set(sharesDirFlag, path.Join(keychainDir, "shares"))
+	m.sharePG = &fixedPassphraseGetter{err: errors.New("some get error")}
+	err = m.escrow(new(bytes.Buffer))
+	assert.NotNil(t, err)
+
+	// check empty share passphrase triggers error
+	m.sharePG = &fixedPassphraseGetter{passphrase: ""}
+	err = m.escrow(new(bytes.Buffer))
+	assert.Equal(t, errEmptySharePassphrase, err)
+	viper.Set(sharesDirFlag, "")
+}
+
+func TestKeychainManager_reconstruct_err(t *testing.T) {
+	keychainDir, _ := setUpKeychains(t)
+	defer tearDownKeychains(t, keychainDir)
+	m := newTestKeychainManager()
+	viper.Set(shareFilesFlag, nil)
+
+	// check unknown keychain triggers error
+	viper.Set(keychainFlag, "some other keychain")
+	err := m.reconstruct(new(bytes.Buffer))
+	assert.Equal(t, errUnknownKeychain, err)
+
+	// check missing keychain dir triggers error
+	viper.Set(keychainFlag, authorKeychainName)
+	viper.Set(keychainDirFlag, "")
+	err = m.reconstruct(new(bytes.Buffer))
+	assert.Equal(t, errMissingKeychainDir, err)
+
+	// check existing keychain triggers error
+	viper.Set(keychainDirFlag, keychainDir)
+	err = m.reconstruct(new(bytes.Buffer))
+	assert.Equal(t, author.ErrKeychainExists, err)
+
+	// check bad share text triggers error
+	newKeychainDir := path.Join(keychainDir, "reconstructed")
+	viper.Set(keychainDirFlag, newKeychainDir)
+	err = m.reconstruct(strings.NewReader("not a share\n"))
+	assert.NotNil(t, err)
+
+	// check too few shares triggers error
+	err = m.reconstruct(strings.NewReader("# no shares\n"))
+	assert.Equal(t, keychain.ErrTooFewShares, err)
+
+	// check missing share file triggers error
+	m.sharePG = &fixedPassphraseGetter{passphrase: "some share passphrase"}
+	viper.Set(shareFilesFlag, []string{path.Join(keychainDir, "missing-share")})
+	err = m.reconstruct(new(bytes.Buffer))
+	assert.NotNil(t, err)
+
+	// check passphrase setter error bubbles up
+	shares, err := keychain.Escrow(keychain.New(2), 2, 2)
+	assert.Nil(t, err)
+	shareText := new(bytes.Buffer)
+	err = printShares(shareText, authorKeychainName, shares)
+	assert.Nil(t, err)
+	viper.Set(shareFilesFlag, nil)
+	m.ps = &fixedPassphraseSetter{err: errors.New("some set error")}
+	err = m.reconstruct(shareText)
+	assert.NotNil(t, err)
+}
@@ -42,9 +42,10 @@ type keychainCreator interface {
 func newKeychainCreator() keychainCreator {
 	return &keychainCreatorImpl{
 		ps: &passphraseSetterImpl{
-			pg1:    &terminalPassphraseGetter{},
-			pg2:    &terminalPassphraseGetter{},
-			reader: bufio.NewReader(os.Stdin),
+			passphraseVar: passphraseVar,
+			pg1:           &terminalPassphraseGetter{},
+			pg2:           &terminalPassphraseGetter{},
+			reader:        bufio.NewReader(os.Stdin),
 		},
 		scryptN: keychain.LightScryptN,
 		scryptP: keychain.LightScryptP,
@@ -74,9 +75,28 @@ func (c *keychainCreatorImpl) create() error {
 		return err
 	}
 
+	mnemonic, err := keychain.NewMnemonic()
+	if err != nil {
+		return err
+	}
+
 	logger := clogging.NewDevLogger(getLogLevel())
 	logger.Info("creating keychains")
-	return author.CreateKeychains(logger, keychainDir, passphrase, c.scryptN, c.scryptP)
+	err = author.CreateHDKeychains(logger, keychainDir, mnemonic, passphrase, c.scryptN,
+		c.scryptP)
+	if err != nil {
+		return err
+	}
+	printMnemonic(mnemonic)
+	return nil
+}
+
+// printMnemonic displays the keychains' seed phrase, which is never stored, for the user to record.
+func printMnemonic(mnemonic string) {
+	fmt.Println("\nThe recovery seed phrase for your keychains is:")
+	fmt.Printf("\n    %s\n\n", mnemonic)
+	fmt.Println("Write it down and keep it somewhere safe. It won't be shown again! With it, you can")
+	fmt.Println(`recreate your keychains on any machine via "libri author recover".`)
 }
 
 type passphraseSetter interface {
@@ -84,13 +104,14 @@ type passphraseSetter interface {
 }
 
 type passphraseSetterImpl struct {
-	pg1    passphraseGetter
-	pg2    passphraseGetter
-	reader *bufio.Reader
+	passphraseVar string
+	pg1           passphraseGetter
+	pg2           passphraseGetter
+	reader        *bufio.Reader
 }
 
 func (s *passphraseSetterImpl) set() (string, error) {
-	passphrase := viper.GetString(passphraseVar) // intentionally not bound to flag for a tad
+	passphrase := viper.GetString(s.passphraseVar) // intentionally not bound to flag for a tad
 	if passphrase != "" {
 		return passphrase, nil
 	}
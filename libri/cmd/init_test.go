@@ -106,7 +106,7 @@ func TestPassphraseSetter_set_ok(t *testing.T) {
 	viper.Set(passphraseVar, setPassphrase)
 
 	// check can get passphrase from viper
-	ps1 := &passphraseSetterImpl{}
+	ps1 := &passphraseSetterImpl{passphraseVar: passphraseVar}
 	pass1, err := ps1.set()
 	assert.Nil(t, err)
 	assert.Equal(t, setPassphrase, pass1)
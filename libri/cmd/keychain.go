@@ -0,0 +1,441 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+
+	lauthor "github.com/drausin/libri/libri/author"
+	"github.com/drausin/libri/libri/author/keychain"
+	"github.com/drausin/libri/libri/common/ecid"
+	cerrors "github.com/drausin/libri/libri/common/errors"
+	clogging "github.com/drausin/libri/libri/common/logging"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	keychainFlag       = "keychain"
+	nKeysFlag          = "nKeys"
+	scryptNFlag        = "scryptN"
+	scryptPFlag        = "scryptP"
+	importFilepathFlag = "importFilepath"
+	exportFilepathFlag = "exportFilepath"
+	exportKeyFlag      = "exportKey"
+	newPassphraseVar   = "newPassphrase"
+
+	authorKeychainName     = "author"
+	selfReaderKeychainName = "self-reader"
+)
+
+var (
+	errUnknownKeychain = fmt.Errorf("keychain must be either %s or %s", authorKeychainName,
+		selfReaderKeychainName)
+	errInvalidNKeys     = errors.New("number of keys to add must be positive")
+	errMissingExportKey = errors.New("missing public key or fingerprint of key to export")
+	errKeyNotInKeychain = errors.New("no key with given public key or fingerprint in keychain")
+
+	keychainNames = []string{authorKeychainName, selfReaderKeychainName}
+)
+
+// keychainCmd represents the keychain command
+var keychainCmd = &cobra.Command{
+	Use:   "keychain",
+	Short: "manage author keychains",
+	Long: `Manage the author and self-reader keychains created by init. Both are stored in the
+keychain directory, encrypted with the keychain passphrase. Keys can be listed, added, imported,
+exported, and verified; the passphrase can be changed with passwd; and the keychains can be
+backed up to and restored from libri with push and pull, or split into escrow shares and
+reconstructed from them.`,
+}
+
+// keychainLsCmd represents the keychain ls command
+var keychainLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "list the public keys and their fingerprints in the author keychains",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newKeychainManager().list(os.Stdout)
+	},
+}
+
+// keychainAddCmd represents the keychain add command
+var keychainAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "add new keys to a keychain",
+	Long: `Add new random keys to a keychain. Unlike the keys created by init, these keys are not
+derived from the keychains' seed phrase and so cannot be recovered from it.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newKeychainManager().add()
+	},
+}
+
+// keychainImportCmd represents the keychain import command
+var keychainImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "import an encrypted key file into a keychain",
+	Long: `Import an individual key, encrypted as Ethereum keystore JSON with the keychain
+passphrase, into a keychain.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newKeychainManager().importKey()
+	},
+}
+
+// keychainExportCmd represents the keychain export command
+var keychainExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "export an encrypted key from a keychain",
+	Long: `Export an individual key from a keychain, encrypted as Ethereum keystore JSON with the
+keychain passphrase.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newKeychainManager().exportKey(os.Stdout)
+	},
+}
+
+// keychainPasswdCmd represents the keychain passwd command
+var keychainPasswdCmd = &cobra.Command{
+	Use:   "passwd",
+	Short: "re-encrypt the author keychains with a new passphrase and scrypt parameters",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newKeychainManager().passwd()
+	},
+}
+
+// keychainVerifyCmd represents the keychain verify command
+var keychainVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "verify the author keychains decrypt and contain only valid keys",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newKeychainManager().verify(os.Stdout)
+	},
+}
+
+func init() {
+	authorCmd.AddCommand(keychainCmd)
+	keychainCmd.AddCommand(keychainLsCmd)
+	keychainCmd.AddCommand(keychainAddCmd)
+	keychainCmd.AddCommand(keychainImportCmd)
+	keychainCmd.AddCommand(keychainExportCmd)
+	keychainCmd.AddCommand(keychainPasswdCmd)
+	keychainCmd.AddCommand(keychainVerifyCmd)
+
+	keychainCmd.PersistentFlags().String(keychainFlag, authorKeychainName,
+		fmt.Sprintf("keychain to modify (%s or %s)", authorKeychainName,
+			selfReaderKeychainName))
+	keychainCmd.PersistentFlags().Int(scryptNFlag, keychain.LightScryptN,
+		"scrypt N parameter for encrypting keys")
+	keychainCmd.PersistentFlags().Int(scryptPFlag, keychain.LightScryptP,
+		"scrypt P parameter for encrypting keys")
+	keychainAddCmd.Flags().IntP(nKeysFlag, "n", 1, "number of new keys to add")
+	keychainImportCmd.Flags().StringP(importFilepathFlag, "f", "",
+		"path of encrypted key file to import")
+	keychainExportCmd.Flags().StringP(exportKeyFlag, "p", "",
+		"hex public key or fingerprint of key to export")
+	keychainExportCmd.Flags().StringP(exportFilepathFlag, "f", "",
+		"path of file to export encrypted key to (default stdout)")
+
+	// bind viper flags
+	viper.SetEnvPrefix(envVarPrefix) // look for env vars with "LIBRI_" prefix
+	viper.AutomaticEnv()             // read in environment variables that match
+	cerrors.MaybePanic(viper.BindPFlags(keychainCmd.PersistentFlags()))
+	cerrors.MaybePanic(viper.BindPFlags(keychainAddCmd.Flags()))
+	cerrors.MaybePanic(viper.BindPFlags(keychainImportCmd.Flags()))
+	cerrors.MaybePanic(viper.BindPFlags(keychainExportCmd.Flags()))
+}
+
+type keychainManager interface {
+	// list writes the public keys and fingerprints of both keychains.
+	list(w io.Writer) error
+
+	// add adds new keys to a keychain.
+	add() error
+
+	// importKey adds a key from an encrypted key file to a keychain.
+	importKey() error
+
+	// exportKey writes a key from a keychain to an encrypted key file or to w.
+	exportKey(w io.Writer) error
+
+	// passwd re-encrypts both keychains with a new passphrase and scrypt parameters.
+	passwd() error
+
+	// verify checks both keychains and writes the number of valid keys in each.
+	verify(w io.Writer) error
+
+	// escrow splits a keychain into shares, writing them to encrypted files or as text to w.
+	escrow(w io.Writer) error
+
+	// reconstruct rebuilds a keychain from encrypted share files or share text read from r.
+	reconstruct(r io.Reader) error
+
+	// push backs up both keychains to libri, merging in the keys of any existing backup.
+	push() error
+
+	// pull restores both keychains from a backup in libri, merging them into any local keychains.
+	pull() error
+}
+
+func newKeychainManager() keychainManager {
+	return &keychainManagerImpl{
+		pg:      &terminalPassphraseGetter{},
+		sharePG: &terminalPassphraseGetter{},
+		ps: &passphraseSetterImpl{
+			passphraseVar: newPassphraseVar,
+			pg1:           &terminalPassphraseGetter{},
+			pg2:           &terminalPassphraseGetter{},
+			reader:        bufio.NewReader(os.Stdin),
+		},
+		ag:            newAuthorGetter(),
+		kb:            &authorKeychainBackuperImpl{},
+		scryptN:       viper.GetInt(scryptNFlag),
+		scryptP:       viper.GetInt(scryptPFlag),
+		backupScryptN: backupScryptN,
+		backupScryptP: backupScryptP,
+	}
+}
+
+type keychainManagerImpl struct {
+	pg            passphraseGetter
+	sharePG       passphraseGetter
+	ps            passphraseSetter
+	ag            authorGetter
+	kb            authorKeychainBackuper
+	scryptN       int
+	scryptP       int
+	backupScryptN int
+	backupScryptP int
+}
+
+func (m *keychainManagerImpl) list(w io.Writer) error {
+	keychainDir, passphrase, err := m.getDirPassphrase()
+	if err != nil {
+		return err
+	}
+	for _, name := range keychainNames {
+		kc, err := keychain.Load(keychainFilepath(keychainDir, name), passphrase)
+		if err != nil {
+			return err
+		}
+		keys := kc.Keys()
+		if _, err := fmt.Fprintf(w, "%s keychain (%d keys)\n", name, len(keys)); err != nil {
+			return err
+		}
+		for _, key := range keys {
+			pub := key.PublicKeyBytes()
+			_, err := fmt.Fprintf(w, "  %s  %x\n", keychain.Fingerprint(pub), pub)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *keychainManagerImpl) add() error {
+	nKeys := viper.GetInt(nKeysFlag)
+	if nKeys < 1 {
+		return errInvalidNKeys
+	}
+	kcFilepath, passphrase, kc, err := m.loadSelected()
+	if err != nil {
+		return err
+	}
+	newKeys := make([]ecid.ID, nKeys)
+	for i := range newKeys {
+		newKeys[i] = ecid.NewRandom()
+	}
+	kc.Add(newKeys...)
+	if err := saveKeychain(kcFilepath, passphrase, kc, m.scryptN, m.scryptP); err != nil {
+		return err
+	}
+	logger := clogging.NewDevLogger(getLogLevel())
+	logger.Info("added keys to keychain",
+		zap.String(lauthor.LoggerKeychainFilepath, kcFilepath),
+		zap.Int(nKeysFlag, nKeys),
+		zap.Int(lauthor.LoggerKeychainNKeys, len(kc.Keys())),
+	)
+	return nil
+}
+
+func (m *keychainManagerImpl) importKey() error {
+	importFilepath := viper.GetString(importFilepathFlag)
+	if importFilepath == "" {
+		return errMissingFilepath
+	}
+	keyJSON, err := ioutil.ReadFile(importFilepath)
+	if err != nil {
+		return err
+	}
+	kcFilepath, passphrase, kc, err := m.loadSelected()
+	if err != nil {
+		return err
+	}
+	key, err := keychain.ImportKey(keyJSON, passphrase)
+	if err != nil {
+		return err
+	}
+	kc.Add(key)
+	if err := saveKeychain(kcFilepath, passphrase, kc, m.scryptN, m.scryptP); err != nil {
+		return err
+	}
+	logger := clogging.NewDevLogger(getLogLevel())
+	logger.Info("imported key into keychain",
+		zap.String(lauthor.LoggerKeychainFilepath, kcFilepath),
+		zap.String(exportKeyFlag, keychain.Fingerprint(key.PublicKeyBytes())),
+		zap.Int(lauthor.LoggerKeychainNKeys, len(kc.Keys())),
+	)
+	return nil
+}
+
+func (m *keychainManagerImpl) exportKey(w io.Writer) error {
+	keyStr := viper.GetString(exportKeyFlag)
+	if keyStr == "" {
+		return errMissingExportKey
+	}
+	_, passphrase, kc, err := m.loadSelected()
+	if err != nil {
+		return err
+	}
+	key, err := findKey(kc, keyStr)
+	if err != nil {
+		return err
+	}
+	keyJSON, err := keychain.ExportKey(key, passphrase, m.scryptN, m.scryptP)
+	if err != nil {
+		return err
+	}
+	if exportFilepath := viper.GetString(exportFilepathFlag); exportFilepath != "" {
+		const filePerm = 0600 // only user can read
+		return ioutil.WriteFile(exportFilepath, keyJSON, filePerm)
+	}
+	_, err = w.Write(keyJSON)
+	return err
+}
+
+func (m *keychainManagerImpl) passwd() error {
+	keychainDir, passphrase, err := m.getDirPassphrase()
+	if err != nil {
+		return err
+	}
+
+	// load both keychains before saving either so a bad passphrase leaves neither changed
+	kcs, err := loadKeychains(keychainDir, passphrase)
+	if err != nil {
+		return err
+	}
+	newPassphrase, err := m.ps.set()
+	if err != nil {
+		return err
+	}
+	if err := saveKeychains(keychainDir, newPassphrase, kcs, m.scryptN, m.scryptP); err != nil {
+		return err
+	}
+	logger := clogging.NewDevLogger(getLogLevel())
+	for _, name := range keychainNames {
+		kcFilepath := keychainFilepath(keychainDir, name)
+		logger.Info("re-encrypted keychain",
+			zap.String(lauthor.LoggerKeychainFilepath, kcFilepath),
+			zap.Int(scryptNFlag, m.scryptN),
+			zap.Int(scryptPFlag, m.scryptP),
+		)
+	}
+	return nil
+}
+
+func (m *keychainManagerImpl) verify(w io.Writer) error {
+	keychainDir, passphrase, err := m.getDirPassphrase()
+	if err != nil {
+		return err
+	}
+	for _, name := range keychainNames {
+		nKeys, err := keychain.Verify(keychainFilepath(keychainDir, name), passphrase)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s keychain", name)
+		}
+		if _, err := fmt.Fprintf(w, "%s keychain: %d valid keys\n", name, nKeys); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadSelected loads the keychain selected by the keychain flag, returning its filepath and
+// passphrase along with it.
+func (m *keychainManagerImpl) loadSelected() (string, string, keychain.Keychain, error) {
+	name := viper.GetString(keychainFlag)
+	if name != authorKeychainName && name != selfReaderKeychainName {
+		return "", "", nil, errUnknownKeychain
+	}
+	keychainDir, passphrase, err := m.getDirPassphrase()
+	if err != nil {
+		return "", "", nil, err
+	}
+	kcFilepath := keychainFilepath(keychainDir, name)
+	kc, err := keychain.Load(kcFilepath, passphrase)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return kcFilepath, passphrase, kc, nil
+}
+
+func (m *keychainManagerImpl) getDirPassphrase() (string, string, error) {
+	keychainDir := viper.GetString(keychainDirFlag)
+	if keychainDir == "" {
+		return "", "", errMissingKeychainDir
+	}
+	missing, err := lauthor.MissingKeychains(keychainDir)
+	if err != nil {
+		return "", "", err
+	}
+	if missing {
+		return "", "", errKeychainsNotExist
+	}
+	passphrase, err := m.getPassphrase()
+	if err != nil {
+		return "", "", err
+	}
+	return keychainDir, passphrase, nil
+}
+
+func (m *keychainManagerImpl) getPassphrase() (string, error) {
+	passphrase := viper.GetString(passphraseVar) // intentionally not bound to flag
+	if passphrase == "" {
+		// get passphrase from terminal
+		fmt.Print("Enter keychains passphrase: ")
+		return m.pg.get()
+	}
+	return passphrase, nil
+}
+
+// findKey returns the key in the keychain with the given hex public key or fingerprint.
+func findKey(kc keychain.Keychain, keyStr string) (ecid.ID, error) {
+	for _, key := range kc.Keys() {
+		pub := key.PublicKeyBytes()
+		if keyStr == hex.EncodeToString(pub) || keyStr == keychain.Fingerprint(pub) {
+			return key, nil
+		}
+	}
+	return nil, errKeyNotInKeychain
+}
+
+func keychainFilepath(keychainDir, name string) string {
+	if name == selfReaderKeychainName {
+		return path.Join(keychainDir, lauthor.SelfReaderKeychainFilename)
+	}
+	return path.Join(keychainDir, lauthor.AuthorKeychainFilename)
+}
+
+// saveKeychain saves the keychain to a temporary file before moving it to the given filepath, so
+// an interrupted save never leaves a partially written keychain.
+func saveKeychain(filepath, auth string, kc keychain.Keychain, scryptN, scryptP int) error {
+	tmpFilepath := filepath + ".tmp"
+	if err := keychain.Save(tmpFilepath, auth, kc, scryptN, scryptP); err != nil {
+		return err
+	}
+	return os.Rename(tmpFilepath, filepath)
+}
@@ -0,0 +1,326 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/drausin/libri/libri/author"
+	"github.com/drausin/libri/libri/author/keychain"
+	"github.com/drausin/libri/libri/common/logging"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeychainLsCmd_err(t *testing.T) {
+	viper.Set(keychainDirFlag, "")
+	err := keychainLsCmd.RunE(keychainLsCmd, []string{})
+	assert.NotNil(t, err)
+}
+
+func TestKeychainManager_list_ok(t *testing.T) {
+	keychainDir, passphrase := setUpKeychains(t)
+	defer tearDownKeychains(t, keychainDir)
+	authorKeys, _, err := author.LoadKeychains(keychainDir, passphrase)
+	assert.Nil(t, err)
+
+	m := newTestKeychainManager()
+	out := new(bytes.Buffer)
+	err = m.list(out)
+	assert.Nil(t, err)
+	assert.Contains(t, out.String(), "author keychain (64 keys)")
+	assert.Contains(t, out.String(), "self-reader keychain (64 keys)")
+
+	// check each author key is listed with its fingerprint
+	for _, key := range authorKeys.(keychain.Keychain).Keys() {
+		pub := key.PublicKeyBytes()
+		assert.Contains(t, out.String(), keychain.Fingerprint(pub)+"  "+hex.EncodeToString(pub))
+	}
+}
+
+func TestKeychainManager_list_err(t *testing.T) {
+	keychainDir, passphrase := setUpKeychains(t)
+	defer tearDownKeychains(t, keychainDir)
+
+	// check missing keychain dir triggers error
+	m := newTestKeychainManager()
+	viper.Set(keychainDirFlag, "")
+	err := m.list(new(bytes.Buffer))
+	assert.Equal(t, errMissingKeychainDir, err)
+
+	// check missing keychains trigger error
+	viper.Set(keychainDirFlag, path.Join(keychainDir, "missing"))
+	err = m.list(new(bytes.Buffer))
+	assert.Equal(t, errKeychainsNotExist, err)
+
+	// check passphrase getter error bubbles up
+	viper.Set(keychainDirFlag, keychainDir)
+	viper.Set(passphraseVar, "")
+	m.pg = &fixedPassphraseGetter{err: errors.New("some get error")}
+	err = m.list(new(bytes.Buffer))
+	assert.NotNil(t, err)
+
+	// check wrong passphrase triggers error
+	m.pg = &fixedPassphraseGetter{passphrase: passphrase + " wrong"}
+	err = m.list(new(bytes.Buffer))
+	assert.NotNil(t, err)
+}
+
+func TestKeychainManager_add_ok(t *testing.T) {
+	keychainDir, passphrase := setUpKeychains(t)
+	defer tearDownKeychains(t, keychainDir)
+
+	m := newTestKeychainManager()
+	viper.Set(keychainFlag, selfReaderKeychainName)
+	viper.Set(nKeysFlag, 2)
+	err := m.add()
+	assert.Nil(t, err)
+
+	authorKeys, selfReaderKeys, err := author.LoadKeychains(keychainDir, passphrase)
+	assert.Nil(t, err)
+	assert.Len(t, authorKeys.(keychain.Keychain).Keys(), 64)
+	assert.Len(t, selfReaderKeys.(keychain.Keychain).Keys(), 66)
+}
+
+func TestKeychainManager_add_err(t *testing.T) {
+	keychainDir, passphrase := setUpKeychains(t)
+	defer tearDownKeychains(t, keychainDir)
+	m := newTestKeychainManager()
+
+	// check non-positive number of keys triggers error
+	viper.Set(keychainFlag, authorKeychainName)
+	viper.Set(nKeysFlag, 0)
+	err := m.add()
+	assert.Equal(t, errInvalidNKeys, err)
+
+	// check unknown keychain triggers error
+	viper.Set(keychainFlag, "some other keychain")
+	viper.Set(nKeysFlag, 2)
+	err = m.add()
+	assert.Equal(t, errUnknownKeychain, err)
+
+	// check wrong passphrase triggers error
+	viper.Set(keychainFlag, authorKeychainName)
+	viper.Set(passphraseVar, passphrase+" wrong")
+	err = m.add()
+	assert.NotNil(t, err)
+
+	// check save error bubbles up
+	viper.Set(passphraseVar, passphrase)
+	m.scryptN, m.scryptP = -1, -1
+	err = m.add()
+	assert.NotNil(t, err)
+}
+
+func TestKeychainManager_exportImportKey_ok(t *testing.T) {
+	keychainDir, passphrase := setUpKeychains(t)
+	defer tearDownKeychains(t, keychainDir)
+	authorKeys, _, err := author.LoadKeychains(keychainDir, passphrase)
+	assert.Nil(t, err)
+	key := authorKeys.(keychain.Keychain).Keys()[0]
+	pub := key.PublicKeyBytes()
+	keyFilepath := path.Join(keychainDir, "exported.json")
+	m := newTestKeychainManager()
+
+	// check export by fingerprint to file
+	viper.Set(keychainFlag, authorKeychainName)
+	viper.Set(exportKeyFlag, keychain.Fingerprint(pub))
+	viper.Set(exportFilepathFlag, keyFilepath)
+	err = m.exportKey(new(bytes.Buffer))
+	assert.Nil(t, err)
+
+	// check export by public key to writer
+	viper.Set(exportKeyFlag, hex.EncodeToString(pub))
+	viper.Set(exportFilepathFlag, "")
+	out := new(bytes.Buffer)
+	err = m.exportKey(out)
+	assert.Nil(t, err)
+	exported, err := keychain.ImportKey(out.Bytes(), passphrase)
+	assert.Nil(t, err)
+	assert.Equal(t, pub, exported.PublicKeyBytes())
+
+	// check import into self-reader keychain
+	viper.Set(keychainFlag, selfReaderKeychainName)
+	viper.Set(importFilepathFlag, keyFilepath)
+	err = m.importKey()
+	assert.Nil(t, err)
+
+	_, selfReaderKeys, err := author.LoadKeychains(keychainDir, passphrase)
+	assert.Nil(t, err)
+	imported, in := selfReaderKeys.Get(pub)
+	assert.True(t, in)
+	assert.Equal(t, key.Key().D, imported.Key().D)
+	assert.Len(t, selfReaderKeys.(keychain.Keychain).Keys(), 65)
+}
+
+func TestKeychainManager_exportKey_err(t *testing.T) {
+	keychainDir, passphrase := setUpKeychains(t)
+	defer tearDownKeychains(t, keychainDir)
+	_, selfReaderKeys, err := author.LoadKeychains(keychainDir, passphrase)
+	assert.Nil(t, err)
+	selfReaderPub := selfReaderKeys.(keychain.Keychain).Keys()[0].PublicKeyBytes()
+	m := newTestKeychainManager()
+	viper.Set(keychainFlag, authorKeychainName)
+	viper.Set(exportFilepathFlag, "")
+
+	// check missing key triggers error
+	viper.Set(exportKeyFlag, "")
+	err = m.exportKey(new(bytes.Buffer))
+	assert.Equal(t, errMissingExportKey, err)
+
+	// check key not in selected keychain triggers error
+	viper.Set(exportKeyFlag, keychain.Fingerprint(selfReaderPub))
+	err = m.exportKey(new(bytes.Buffer))
+	assert.Equal(t, errKeyNotInKeychain, err)
+
+	// check unknown keychain triggers error
+	viper.Set(keychainFlag, "some other keychain")
+	err = m.exportKey(new(bytes.Buffer))
+	assert.Equal(t, errUnknownKeychain, err)
+
+	// check encrypt error bubbles up
+	viper.Set(keychainFlag, selfReaderKeychainName)
+	m.scryptN, m.scryptP = -1, -1
+	err = m.exportKey(new(bytes.Buffer))
+	assert.NotNil(t, err)
+}
+
+func TestKeychainManager_importKey_err(t *testing.T) {
+	keychainDir, _ := setUpKeychains(t)
+	defer tearDownKeychains(t, keychainDir)
+	m := newTestKeychainManager()
+	viper.Set(keychainFlag, authorKeychainName)
+
+	// check missing filepath triggers error
+	viper.Set(importFilepathFlag, "")
+	err := m.importKey()
+	assert.Equal(t, errMissingFilepath, err)
+
+	// check missing file triggers error
+	keyFilepath := path.Join(keychainDir, "key.json")
+	viper.Set(importFilepathFlag, keyFilepath)
+	err = m.importKey()
+	assert.NotNil(t, err)
+
+	// check bad key file triggers error
+	err = ioutil.WriteFile(keyFilepath, []byte("not a key"), 0600)
+	assert.Nil(t, err)
+	err = m.importKey()
+	assert.NotNil(t, err)
+}
+
+func TestKeychainManager_passwd_ok(t *testing.T) {
+	keychainDir, passphrase := setUpKeychains(t)
+	defer tearDownKeychains(t, keychainDir)
+	authorKeys1, selfReaderKeys1, err := author.LoadKeychains(keychainDir, passphrase)
+	assert.Nil(t, err)
+
+	newPassphrase := "some new passphrase"
+	m := newTestKeychainManager()
+	m.ps = &fixedPassphraseSetter{passphrase: newPassphrase}
+	err = m.passwd()
+	assert.Nil(t, err)
+
+	// check keychains only decrypt with new passphrase
+	_, _, err = author.LoadKeychains(keychainDir, passphrase)
+	assert.NotNil(t, err)
+	authorKeys2, selfReaderKeys2, err := author.LoadKeychains(keychainDir, newPassphrase)
+	assert.Nil(t, err)
+	assert.Equal(t, authorKeys1, authorKeys2)
+	assert.Equal(t, selfReaderKeys1, selfReaderKeys2)
+}
+
+func TestKeychainManager_passwd_err(t *testing.T) {
+	keychainDir, passphrase := setUpKeychains(t)
+	defer tearDownKeychains(t, keychainDir)
+	m := newTestKeychainManager()
+
+	// check wrong passphrase triggers error
+	viper.Set(passphraseVar, passphrase+" wrong")
+	err := m.passwd()
+	assert.NotNil(t, err)
+
+	// check passphrase setter error bubbles up
+	viper.Set(passphraseVar, passphrase)
+	m.ps = &fixedPassphraseSetter{err: errors.New("some set error")}
+	err = m.passwd()
+	assert.NotNil(t, err)
+
+	// check save error bubbles up and leaves keychains unchanged
+	m.ps = &fixedPassphraseSetter{passphrase: "some new passphrase"}
+	m.scryptN, m.scryptP = -1, -1
+	err = m.passwd()
+	assert.NotNil(t, err)
+	_, _, err = author.LoadKeychains(keychainDir, passphrase)
+	assert.Nil(t, err)
+
+	// check failing to save the second keychain leaves the first unchanged too
+	m.scryptN, m.scryptP = veryLightScryptN, veryLightScryptP
+	selfReaderFilepath := path.Join(keychainDir, author.SelfReaderKeychainFilename)
+	err = os.Mkdir(selfReaderFilepath+".tmp", os.ModePerm)
+	assert.Nil(t, err)
+	err = m.passwd()
+	assert.NotNil(t, err)
+	_, _, err = author.LoadKeychains(keychainDir, passphrase)
+	assert.Nil(t, err)
+}
+
+func TestKeychainManager_verify_ok(t *testing.T) {
+	keychainDir, _ := setUpKeychains(t)
+	defer tearDownKeychains(t, keychainDir)
+
+	m := newTestKeychainManager()
+	out := new(bytes.Buffer)
+	err := m.verify(out)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, strings.Count(out.String(), "64 valid keys"))
+}
+
+func TestKeychainManager_verify_err(t *testing.T) {
+	keychainDir, passphrase := setUpKeychains(t)
+	defer tearDownKeychains(t, keychainDir)
+	m := newTestKeychainManager()
+
+	// check wrong passphrase triggers error
+	viper.Set(passphraseVar, passphrase+" wrong")
+	err := m.verify(new(bytes.Buffer))
+	assert.NotNil(t, err)
+
+	// check corrupted keychain triggers error
+	viper.Set(passphraseVar, passphrase)
+	selfReaderFilepath := path.Join(keychainDir, author.SelfReaderKeychainFilename)
+	err = ioutil.WriteFile(selfReaderFilepath, []byte("not a keychain"), 0600)
+	assert.Nil(t, err)
+	err = m.verify(new(bytes.Buffer))
+	assert.NotNil(t, err)
+}
+
+func setUpKeychains(t *testing.T) (string, string) {
+	keychainDir, err := ioutil.TempDir("", "test-keychains")
+	assert.Nil(t, err)
+	passphrase := "some test passphrase"
+	logger := server.NewDevInfoLogger()
+	err = author.CreateKeychains(logger, keychainDir, passphrase, veryLightScryptN,
+		veryLightScryptP)
+	assert.Nil(t, err)
+	viper.Set(keychainDirFlag, keychainDir)
+	viper.Set(passphraseVar, passphrase)
+	return keychainDir, passphrase
+}
+
+func tearDownKeychains(t *testing.T, keychainDir string) {
+	viper.Set(passphraseVar, "")
+	assert.Nil(t, os.RemoveAll(keychainDir))
+}
+
+func newTestKeychainManager() *keychainManagerImpl {
+	return &keychainManagerImpl{
+		scryptN: veryLightScryptN,
+		scryptP: veryLightScryptP,
+	}
+}
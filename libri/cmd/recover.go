@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/drausin/libri/libri/author"
+	"github.com/drausin/libri/libri/author/keychain"
+	clogging "github.com/drausin/libri/libri/common/logging"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	mnemonicVar = "mnemonic"
+)
+
+// recoverCmd represents the recover command
+var recoverCmd = &cobra.Command{
+	Use:   "recover",
+	Short: "recover author keychains from their seed phrase",
+	Long: `Recreate the author and self-reader keychains created by init from the recovery seed
+phrase init displayed, encrypting them with a (possibly new) passphrase.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newKeychainRecoverer().recover()
+	},
+}
+
+func init() {
+	authorCmd.AddCommand(recoverCmd)
+}
+
+type keychainRecoverer interface {
+	recover() error
+}
+
+func newKeychainRecoverer() keychainRecoverer {
+	return &keychainRecovererImpl{
+		mg: &terminalPassphraseGetter{},
+		ps: &passphraseSetterImpl{
+			passphraseVar: passphraseVar,
+			pg1:           &terminalPassphraseGetter{},
+			pg2:           &terminalPassphraseGetter{},
+			reader:        bufio.NewReader(os.Stdin),
+		},
+		scryptN: keychain.LightScryptN,
+		scryptP: keychain.LightScryptP,
+	}
+}
+
+type keychainRecovererImpl struct {
+	// mg gets the seed phrase from the terminal without echoing it
+	mg      passphraseGetter
+	ps      passphraseSetter
+	scryptN int
+	scryptP int
+}
+
+func (r *keychainRecovererImpl) recover() error {
+	keychainDir := viper.GetString(keychainDirFlag)
+	if keychainDir == "" {
+		return errMissingKeychainDir
+	}
+	missing, err := author.MissingKeychains(keychainDir)
+	if err != nil {
+		return err
+	}
+	if !missing {
+		return errKeychainsExist
+	}
+	mnemonic := viper.GetString(mnemonicVar) // intentionally not bound to flag
+	if mnemonic == "" {
+		fmt.Print("Enter recovery seed phrase: ")
+		mnemonic, err = r.mg.get()
+		if err != nil {
+			return err
+		}
+		fmt.Println()
+	}
+	if err := keychain.ValidateMnemonic(mnemonic); err != nil {
+		return err
+	}
+	passphrase, err := r.ps.set()
+	if err != nil {
+		return err
+	}
+
+	logger := clogging.NewDevLogger(getLogLevel())
+	logger.Info("recovering keychains")
+	return author.CreateHDKeychains(logger, keychainDir, mnemonic, passphrase, r.scryptN,
+		r.scryptP)
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/drausin/libri/libri/author"
+	"github.com/drausin/libri/libri/author/keychain"
+	"github.com/drausin/libri/libri/common/logging"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoverCmd_err(t *testing.T) {
+	viper.Set(keychainDirFlag, "")
+	err := recoverCmd.RunE(recoverCmd, []string{})
+	assert.NotNil(t, err)
+}
+
+func TestKeychainRecoverer_recover_ok(t *testing.T) {
+	keychainDir, err := ioutil.TempDir("", "test-keychains")
+	defer func() { err = os.RemoveAll(keychainDir) }()
+	assert.Nil(t, err)
+	passphrase := "some test passphrase"
+	mnemonic, err := keychain.NewMnemonic()
+	assert.Nil(t, err)
+	origKeychainDir := path.Join(keychainDir, "orig")
+	err = author.CreateHDKeychains(server.NewDevInfoLogger(), origKeychainDir, mnemonic,
+		passphrase, veryLightScryptN, veryLightScryptP)
+	assert.Nil(t, err)
+
+	recoveredKeychainDir := path.Join(keychainDir, "recovered")
+	viper.Set(keychainDirFlag, recoveredKeychainDir)
+	viper.Set(mnemonicVar, "")
+	r := &keychainRecovererImpl{
+		mg:      &fixedPassphraseGetter{passphrase: mnemonic},
+		ps:      &fixedPassphraseSetter{passphrase: passphrase},
+		scryptN: veryLightScryptN,
+		scryptP: veryLightScryptP,
+	}
+	err = r.recover()
+	assert.Nil(t, err)
+
+	// check recovered keychains are identical to originals
+	authorKeys1, selfReaderKeys1, err := author.LoadKeychains(origKeychainDir, passphrase)
+	assert.Nil(t, err)
+	authorKeys2, selfReaderKeys2, err := author.LoadKeychains(recoveredKeychainDir, passphrase)
+	assert.Nil(t, err)
+	assert.Equal(t, authorKeys1, authorKeys2)
+	assert.Equal(t, selfReaderKeys1, selfReaderKeys2)
+}
+
+func TestKeychainRecoverer_recover_err(t *testing.T) {
+	keychainDir, err := ioutil.TempDir("", "test-keychains")
+	defer func() { err = os.RemoveAll(keychainDir) }()
+	assert.Nil(t, err)
+	passphrase := "some test passphrase"
+	mnemonic, err := keychain.NewMnemonic()
+	assert.Nil(t, err)
+	viper.Set(mnemonicVar, "")
+
+	// check missing keychain dir triggers error
+	viper.Set(keychainDirFlag, "")
+	r1 := &keychainRecovererImpl{}
+	err = r1.recover()
+	assert.Equal(t, errMissingKeychainDir, err)
+
+	// check existing keychains trigger error
+	existingKeychainDir := path.Join(keychainDir, "existing")
+	err = author.CreateKeychains(server.NewDevInfoLogger(), existingKeychainDir, passphrase,
+		veryLightScryptN, veryLightScryptP)
+	assert.Nil(t, err)
+	viper.Set(keychainDirFlag, existingKeychainDir)
+	r2 := &keychainRecovererImpl{}
+	err = r2.recover()
+	assert.Equal(t, errKeychainsExist, err)
+
+	// check mnemonic getter error bubbles up
+	viper.Set(keychainDirFlag, path.Join(keychainDir, "recovered"))
+	r3 := &keychainRecovererImpl{
+		mg: &fixedPassphraseGetter{err: errors.New("some get error")},
+	}
+	err = r3.recover()
+	assert.NotNil(t, err)
+
+	// check invalid mnemonic triggers error
+	viper.Set(mnemonicVar, "not a valid mnemonic")
+	r4 := &keychainRecovererImpl{}
+	err = r4.recover()
+	assert.Equal(t, keychain.ErrInvalidMnemonic, err)
+
+	// check passphrase setter error bubbles up
+	viper.Set(mnemonicVar, mnemonic)
+	r5 := &keychainRecovererImpl{
+		ps: &fixedPassphraseSetter{err: errors.New("some set error")},
+	}
+	err = r5.recover()
+	assert.NotNil(t, err)
+	viper.Set(mnemonicVar, "")
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/drausin/libri/libri/common/ecid"
 	"github.com/drausin/libri/libri/common/errors"
+	"github.com/drausin/libri/libri/librarian/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
@@ -12,9 +14,11 @@ import (
 )
 
 const (
-	dataDirFlag  = "dataDir"
-	logLevelFlag = "logLevel"
-	envVarPrefix = "LIBRI"
+	dataDirFlag         = "dataDir"
+	logLevelFlag        = "logLevel"
+	keyTypeFlag         = "keyType"
+	signatureFormatFlag = "signatureFormat"
+	envVarPrefix        = "LIBRI"
 )
 
 // RootCmd represents the base command when called without any subcommands
@@ -37,6 +41,10 @@ func init() {
 		"local data directory")
 	RootCmd.PersistentFlags().StringP(logLevelFlag, "l", zap.InfoLevel.String(),
 		"log level")
+	RootCmd.PersistentFlags().String(keyTypeFlag, ecid.KeyTypeSecp256k1.String(),
+		"key type (secp256k1, P-256, or ed25519) of new peer and client IDs")
+	RootCmd.PersistentFlags().String(signatureFormatFlag, client.DefaultSignatureFormat.String(),
+		"request signature format (jwt, both, or compact); peers accept any of them")
 
 	// bind viper flags
 	viper.SetEnvPrefix("LIBRI") // look for env vars with "LIBRI_" prefix
@@ -49,3 +57,11 @@ func getLogLevel() zapcore.Level {
 	errors.MaybePanic(ll.Set(viper.GetString(logLevelFlag)))
 	return ll
 }
+
+func getKeyType() (ecid.KeyType, error) {
+	return ecid.ParseKeyType(viper.GetString(keyTypeFlag))
+}
+
+func getSignatureFormat() (client.SignatureFormat, error) {
+	return client.ParseSignatureFormat(viper.GetString(signatureFormatFlag))
+}
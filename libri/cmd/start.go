@@ -7,21 +7,35 @@ import (
 	clogging "github.com/drausin/libri/libri/common/logging"
 	"github.com/drausin/libri/libri/common/subscribe"
 	"github.com/drausin/libri/libri/librarian/server"
+	"github.com/drausin/libri/libri/librarian/server/routing"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
 
 const (
-	bootstrapsFlag       = "bootstraps"
-	localHostFlag        = "localHost"
-	localPortFlag        = "localPort"
-	localMetricsPortFlag = "localMetricsPort"
-	publicHostFlag       = "publicHost"
-	publicNameFlag       = "publicName"
-	publicPortFlag       = "publicPort"
-	nSubscriptionsFlag   = "nSubscriptions"
-	fpRateFlag           = "fpRate"
+	bootstrapsFlag        = "bootstraps"
+	localHostFlag         = "localHost"
+	localPortFlag         = "localPort"
+	localMetricsPortFlag  = "localMetricsPort"
+	publicHostFlag        = "publicHost"
+	publicNameFlag        = "publicName"
+	publicPortFlag        = "publicPort"
+	nSubscriptionsFlag    = "nSubscriptions"
+	fpRateFlag            = "fpRate"
+	refreshIntervalFlag   = "refreshInterval"
+	refreshConcFlag       = "refreshConcurrency"
+	idDifficultyFlag      = "idDifficulty"
+	pathCacheFlag         = "pathCache"
+	pathCacheMaxBytesFlag = "pathCacheMaxBytes"
+	hotCacheMaxBytesFlag  = "hotCacheMaxBytes"
+	readRepairFlag        = "readRepair"
+	maxReplicasFlag       = "maxReplicas"
+	maxMatchingFlag       = "maxMatchingValues"
+	allowUnstampedFlag    = "allowUnstamped"
+	maxPeerRequestsFlag   = "maxPeerRequests"
+	authorQuotaFlag       = "authorQuota"
+	authorQuotasFlag      = "authorQuotas"
 
 	logLocalAddr        = "localAddr"
 	logLocalMetricsAddr = "localMetricsAddr"
@@ -67,6 +81,32 @@ func init() {
 		"number of active subscriptions to other peers to maintain")
 	startLibrarianCmd.Flags().Float32P(fpRateFlag, "f", subscribe.DefaultFPRate,
 		"false positive rate for subscriptions to other peers")
+	startLibrarianCmd.Flags().Duration(refreshIntervalFlag, server.DefaultRefreshInterval,
+		"time since their latest lookup after which routing table buckets are refreshed")
+	startLibrarianCmd.Flags().Uint(refreshConcFlag, server.DefaultRefreshConcurrency,
+		"number of routing table bucket refreshes to run at once")
+	startLibrarianCmd.Flags().Uint(idDifficultyFlag, routing.DefaultIDDifficulty,
+		"number of leading zero bits required in the hash of each peer ID")
+	startLibrarianCmd.Flags().Bool(pathCacheFlag, server.DefaultPathCacheEnabled,
+		"cache found documents on peers along lookup paths")
+	startLibrarianCmd.Flags().Uint64(pathCacheMaxBytesFlag, server.DefaultPathCacheMaxBytes,
+		"maximum bytes of cache copies to keep, beyond which the oldest are evicted")
+	startLibrarianCmd.Flags().Uint64(hotCacheMaxBytesFlag, server.DefaultHotCacheMaxBytes,
+		"maximum bytes of recently loaded documents to keep in memory (0 disables)")
+	startLibrarianCmd.Flags().Bool(readRepairFlag, server.DefaultReadRepairEnabled,
+		"send found documents to the closest peers that lacked them during gets")
+	startLibrarianCmd.Flags().Uint(maxReplicasFlag, server.DefaultMaxNReplicas,
+		"maximum number of replicas a put request may require")
+	startLibrarianCmd.Flags().Uint(maxMatchingFlag, server.DefaultMaxNMatchingValues,
+		"maximum number of matching values a get request may require")
+	startLibrarianCmd.Flags().Bool(allowUnstampedFlag, server.DefaultAllowUnstamped,
+		"temporarily accept requests without timestamps from clients that predate them")
+	startLibrarianCmd.Flags().Uint(maxPeerRequestsFlag, server.DefaultMaxRequestsPerPeer,
+		"maximum number of recent request IDs remembered per peer to reject replays")
+	startLibrarianCmd.Flags().Uint64(authorQuotaFlag, server.DefaultAuthorQuota,
+		"document bytes peers may store or put for each author per quota period")
+	startLibrarianCmd.Flags().StringSlice(authorQuotasFlag, nil,
+		"comma-separated author quota overrides (<hex author public key>=<bytes>)")
 
 	// bind viper flags
 	viper.SetEnvPrefix("LIBRI") // look for env vars with "LIBRI_" prefix
@@ -102,6 +142,16 @@ func getLibrarianConfig() (*server.Config, *zap.Logger, error) {
 		logger.Error("fatal error parsing public address", zap.Error(err))
 		return nil, nil, err
 	}
+	keyType, err := getKeyType()
+	if err != nil {
+		logger.Error("fatal error parsing key type", zap.Error(err))
+		return nil, nil, err
+	}
+	signatureFormat, err := getSignatureFormat()
+	if err != nil {
+		logger.Error("fatal error parsing signature format", zap.Error(err))
+		return nil, nil, err
+	}
 	config := server.NewDefaultConfig().
 		WithLocalAddr(localAddr).
 		WithLocalMetricsAddr(localMetricsAddr).
@@ -109,9 +159,29 @@ func getLibrarianConfig() (*server.Config, *zap.Logger, error) {
 		WithPublicName(viper.GetString(publicNameFlag)).
 		WithDataDir(viper.GetString(dataDirFlag)).
 		WithDefaultDBDir(). // depends on DataDir
+		WithKeyType(keyType).
+		WithSignatureFormat(signatureFormat).
 		WithLogLevel(logLevel)
 	config.SubscribeTo.NSubscriptions = uint32(viper.GetInt(nSubscriptionsFlag))
 	config.SubscribeTo.FPRate = float32(viper.GetFloat64(fpRateFlag))
+	config.Refresh.Interval = viper.GetDuration(refreshIntervalFlag)
+	config.Refresh.Concurrency = uint(viper.GetInt(refreshConcFlag))
+	config.Routing.IDDifficulty = uint(viper.GetInt(idDifficultyFlag))
+	config.PathCache.Enabled = viper.GetBool(pathCacheFlag)
+	config.PathCache.MaxBytes = uint64(viper.GetInt64(pathCacheMaxBytesFlag))
+	config.HotCache.MaxBytes = uint64(viper.GetInt64(hotCacheMaxBytesFlag))
+	config.ReadRepair.Enabled = viper.GetBool(readRepairFlag)
+	config.Consistency.MaxNReplicas = uint(viper.GetInt(maxReplicasFlag))
+	config.Consistency.MaxNMatchingValues = uint(viper.GetInt(maxMatchingFlag))
+	config.Verify.AllowUnstamped = viper.GetBool(allowUnstampedFlag)
+	config.Verify.MaxRequestsPerPeer = uint(viper.GetInt(maxPeerRequestsFlag))
+	config.Limits.AuthorQuota = uint64(viper.GetInt64(authorQuotaFlag))
+	authorQuotas, err := server.ParseAuthorQuotas(viper.GetStringSlice(authorQuotasFlag))
+	if err != nil {
+		logger.Error("unable to parse author quotas", zap.Error(err))
+		return nil, nil, err
+	}
+	config.Limits.AuthorQuotas = authorQuotas
 
 	bootstrapNetAddrs, err := server.ParseAddrs(viper.GetStringSlice(bootstrapsFlag))
 	if err != nil {
@@ -129,8 +199,23 @@ func getLibrarianConfig() (*server.Config, *zap.Logger, error) {
 		zap.String(publicNameFlag, config.PublicName),
 		zap.String(dataDirFlag, config.DataDir),
 		zap.Stringer(logLevelFlag, config.LogLevel),
+		zap.Stringer(keyTypeFlag, config.KeyType),
+		zap.Stringer(signatureFormatFlag, config.SignatureFormat),
 		zap.Uint32(nSubscriptionsFlag, config.SubscribeTo.NSubscriptions),
 		zap.Float32(fpRateFlag, config.SubscribeTo.FPRate),
+		zap.Duration(refreshIntervalFlag, config.Refresh.Interval),
+		zap.Uint(refreshConcFlag, config.Refresh.Concurrency),
+		zap.Uint(idDifficultyFlag, config.Routing.IDDifficulty),
+		zap.Bool(pathCacheFlag, config.PathCache.Enabled),
+		zap.Uint64(pathCacheMaxBytesFlag, config.PathCache.MaxBytes),
+		zap.Uint64(hotCacheMaxBytesFlag, config.HotCache.MaxBytes),
+		zap.Bool(readRepairFlag, config.ReadRepair.Enabled),
+		zap.Uint(maxReplicasFlag, config.Consistency.MaxNReplicas),
+		zap.Uint(maxMatchingFlag, config.Consistency.MaxNMatchingValues),
+		zap.Bool(allowUnstampedFlag, config.Verify.AllowUnstamped),
+		zap.Uint(maxPeerRequestsFlag, config.Verify.MaxRequestsPerPeer),
+		zap.Uint64(authorQuotaFlag, config.Limits.AuthorQuota),
+		zap.Int(authorQuotasFlag, len(config.Limits.AuthorQuotas)),
 	)
 	return config, logger, nil
 }
@@ -4,7 +4,10 @@ import (
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/librarian/client"
 	"github.com/drausin/libri/libri/librarian/server"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -38,6 +41,9 @@ func TestGetLibrarianConfig_ok(t *testing.T) {
 	dataDir := "some/data/dir"
 	logLevel := "debug"
 	nSubscriptions, fpRate := 5, 0.5
+	refreshInterval, refreshConcurrency := "30m", 5
+	idDifficulty, hotCacheMaxBytes := 2, 1024
+	maxReplicas, maxMatchingValues := 6, 4
 	bootstraps := "1.2.3.5:1000 1.2.3.6:1000"
 
 	viper.Set(logLevelFlag, logLevel)
@@ -50,7 +56,25 @@ func TestGetLibrarianConfig_ok(t *testing.T) {
 	viper.Set(dataDirFlag, dataDir)
 	viper.Set(nSubscriptionsFlag, nSubscriptions)
 	viper.Set(fpRateFlag, fpRate)
+	viper.Set(refreshIntervalFlag, refreshInterval)
+	viper.Set(refreshConcFlag, refreshConcurrency)
+	viper.Set(idDifficultyFlag, idDifficulty)
+	viper.Set(pathCacheFlag, true)
+	viper.Set(pathCacheMaxBytesFlag, 1024)
+	viper.Set(hotCacheMaxBytesFlag, hotCacheMaxBytes)
+	viper.Set(readRepairFlag, true)
+	viper.Set(maxReplicasFlag, maxReplicas)
+	viper.Set(maxMatchingFlag, maxMatchingValues)
+	viper.Set(allowUnstampedFlag, true)
+	viper.Set(maxPeerRequestsFlag, 128)
+	viper.Set(authorQuotaFlag, 2048)
+	viper.Set(authorQuotasFlag, "0a1b=4096 ff=0")
+	defer viper.Set(authorQuotasFlag, nil)
 	viper.Set(bootstrapsFlag, bootstraps)
+	viper.Set(keyTypeFlag, ecid.KeyTypeEd25519.String())
+	defer viper.Set(keyTypeFlag, ecid.KeyTypeSecp256k1.String())
+	viper.Set(signatureFormatFlag, client.CompactSignatures.String())
+	defer viper.Set(signatureFormatFlag, client.DefaultSignatureFormat.String())
 
 	config, logger, err := getLibrarianConfig()
 	assert.Nil(t, err)
@@ -64,7 +88,22 @@ func TestGetLibrarianConfig_ok(t *testing.T) {
 	assert.Equal(t, logLevel, config.LogLevel.String())
 	assert.Equal(t, uint32(nSubscriptions), config.SubscribeTo.NSubscriptions)
 	assert.Equal(t, float32(fpRate), config.SubscribeTo.FPRate)
+	assert.Equal(t, 30*time.Minute, config.Refresh.Interval)
+	assert.Equal(t, uint(refreshConcurrency), config.Refresh.Concurrency)
+	assert.Equal(t, uint(idDifficulty), config.Routing.IDDifficulty)
+	assert.True(t, config.PathCache.Enabled)
+	assert.Equal(t, uint64(1024), config.PathCache.MaxBytes)
+	assert.Equal(t, uint64(hotCacheMaxBytes), config.HotCache.MaxBytes)
+	assert.True(t, config.ReadRepair.Enabled)
+	assert.Equal(t, uint(maxReplicas), config.Consistency.MaxNReplicas)
+	assert.Equal(t, uint(maxMatchingValues), config.Consistency.MaxNMatchingValues)
+	assert.True(t, config.Verify.AllowUnstamped)
+	assert.Equal(t, uint(128), config.Verify.MaxRequestsPerPeer)
+	assert.Equal(t, uint64(2048), config.Limits.AuthorQuota)
+	assert.Equal(t, map[string]uint64{"0a1b": 4096, "ff": 0}, config.Limits.AuthorQuotas)
 	assert.Equal(t, 2, len(config.BootstrapAddrs))
+	assert.Equal(t, ecid.KeyTypeEd25519, config.KeyType)
+	assert.Equal(t, client.CompactSignatures, config.SignatureFormat)
 
 	assert.Nil(t, os.RemoveAll(config.DataDir))
 }
@@ -97,6 +136,33 @@ func TestGetLibrarianConfig_err(t *testing.T) {
 	// reset to ok value
 	viper.Set(publicHostFlag, "1.2.3.4")
 
+	viper.Set(keyTypeFlag, "bad key type")
+	config, logger, err = getLibrarianConfig()
+	assert.Equal(t, ecid.ErrUnknownKeyType, err)
+	assert.Nil(t, config)
+	assert.Nil(t, logger)
+
+	// reset to ok value
+	viper.Set(keyTypeFlag, ecid.KeyTypeSecp256k1.String())
+
+	viper.Set(authorQuotasFlag, "0a1b")
+	config, logger, err = getLibrarianConfig()
+	assert.Equal(t, server.ErrInvalidAuthorQuota, err)
+	assert.Nil(t, config)
+	assert.Nil(t, logger)
+
+	// reset to ok value
+	viper.Set(authorQuotasFlag, nil)
+
+	viper.Set(signatureFormatFlag, "bad format")
+	config, logger, err = getLibrarianConfig()
+	assert.Equal(t, client.ErrUnknownSignatureFormat, err)
+	assert.Nil(t, config)
+	assert.Nil(t, logger)
+
+	// reset to ok value
+	viper.Set(signatureFormatFlag, client.DefaultSignatureFormat.String())
+
 	viper.Set(bootstrapsFlag, "bad bootstrap")
 	config, logger, err = getLibrarianConfig()
 	assert.NotNil(t, err)
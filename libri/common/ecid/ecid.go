@@ -1,6 +1,7 @@
 package ecid
 
 import (
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	crand "crypto/rand"
@@ -9,14 +10,14 @@ import (
 	"math/big"
 	mrand "math/rand"
 
-	"fmt"
-
 	"github.com/drausin/libri/libri/common/id"
 	"github.com/ethereum/go-ethereum/crypto/secp256k1"
 )
 
 // Curve defines the elliptic curve public & private keys use. Curve S256 implies 32-byte private
-// and 65-byte public keys, though the X value of the public key point is 32 bytes.
+// and 65-byte public keys, though the X value of the public key point is 32 bytes. Author and
+// document keys always use it, since their ECDH and hierarchical derivation are secp256k1-only;
+// only peer Identity keys may use other key types.
 var Curve = secp256k1.S256()
 
 // CurveName gives the name of the elliptic curve used for the private key.
@@ -29,10 +30,7 @@ var ErrKeyPointOffCurve = errors.New("key point is off the expected curve")
 // point on the curve. When coupled with the private key, this allows something (e.g., a libri
 // peer) to sign messages that a receiver can verify.
 type ID interface {
-	fmt.Stringer
-
-	// Bytes returns the byte representation
-	Bytes() []byte
+	Identity
 
 	// Int returns the big.Int representation
 	Int() *big.Int
@@ -45,12 +43,6 @@ type ID interface {
 
 	// Key returns the ECDSA private key (which includes public key as well)
 	Key() *ecdsa.PrivateKey
-
-	// ID returns the underlying ID object
-	ID() id.ID
-
-	// PublicKeyBytes returns a byte slice of the encoded public key
-	PublicKeyBytes() []byte
 }
 
 type ecid struct {
@@ -112,6 +104,17 @@ func (x *ecid) ID() id.ID {
 	return x.id
 }
 
+func (x *ecid) KeyType() KeyType {
+	if x.key.Curve == elliptic.P256() {
+		return KeyTypeP256
+	}
+	return KeyTypeSecp256k1
+}
+
+func (x *ecid) Signer() crypto.Signer {
+	return x.key
+}
+
 // FromPrivateKey creates a new ID from an ECDSA private key.
 func FromPrivateKey(priv *ecdsa.PrivateKey) ID {
 	return &ecid{
@@ -135,5 +138,5 @@ func FromPublicKeyBytes(buf []byte) (*ecdsa.PublicKey, error) {
 
 // ToPublicKeyBytes marshals the public key of the ID to a byte representation.
 func ToPublicKeyBytes(pub *ecdsa.PublicKey) []byte {
-	return elliptic.Marshal(Curve, pub.X, pub.Y)
+	return elliptic.Marshal(pub.Curve, pub.X, pub.Y)
 }
@@ -0,0 +1,195 @@
+package ecid
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	crand "crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	mrand "math/rand"
+
+	"github.com/drausin/libri/libri/common/id"
+	"golang.org/x/crypto/ed25519"
+)
+
+// KeyType identifies the signature scheme (and curve) of an Identity key. Its values match those
+// of api.KeyType. Key types only apply to the peer identities signing requests; author and
+// document keys are always secp256k1 ID keys.
+type KeyType int32
+
+const (
+	// KeyTypeSecp256k1 is ECDSA over secp256k1, the original (and default) libri key type.
+	KeyTypeSecp256k1 KeyType = iota
+
+	// KeyTypeP256 is ECDSA over NIST P-256.
+	KeyTypeP256
+
+	// KeyTypeEd25519 is EdDSA over Curve25519.
+	KeyTypeEd25519
+)
+
+const (
+	// P256CurveName gives the name of the NIST P-256 elliptic curve.
+	P256CurveName = "P-256"
+
+	// Ed25519CurveName gives the name of the Ed25519 signature scheme.
+	Ed25519CurveName = "ed25519"
+)
+
+var (
+	// ErrUnknownKeyType indicates when a key type is not one of the supported key types.
+	ErrUnknownKeyType = errors.New("unknown key type")
+
+	// ErrInvalidPublicKey indicates when a public key has the wrong length for its key type.
+	ErrInvalidPublicKey = errors.New("invalid public key")
+
+	keyTypeNames = map[KeyType]string{
+		KeyTypeSecp256k1: CurveName,
+		KeyTypeP256:      P256CurveName,
+		KeyTypeEd25519:   Ed25519CurveName,
+	}
+)
+
+// String returns the name of the key type's curve.
+func (kt KeyType) String() string {
+	if name, in := keyTypeNames[kt]; in {
+		return name
+	}
+	return fmt.Sprintf("KeyType(%d)", int32(kt))
+}
+
+// ParseKeyType returns the key type with the given curve name.
+func ParseKeyType(name string) (KeyType, error) {
+	for kt, ktName := range keyTypeNames {
+		if name == ktName {
+			return kt, nil
+		}
+	}
+	return 0, ErrUnknownKeyType
+}
+
+// Identity is a key pair of any supported key type that identifies something (e.g., a libri peer
+// or author client) and signs the requests it makes.
+type Identity interface {
+	fmt.Stringer
+
+	// Bytes returns the byte representation
+	Bytes() []byte
+
+	// ID returns the underlying ID object
+	ID() id.ID
+
+	// PublicKeyBytes returns a byte slice of the encoded public key
+	PublicKeyBytes() []byte
+
+	// KeyType returns the key type of the key pair
+	KeyType() KeyType
+
+	// Signer returns the private key for signing
+	Signer() crypto.Signer
+}
+
+type edid struct {
+	key ed25519.PrivateKey
+	id  id.ID
+}
+
+// NewRandomIdentity creates a new Identity of the given key type using a crypto.Reader source of
+// entropy.
+func NewRandomIdentity(kt KeyType) (Identity, error) {
+	return newRandomIdentity(kt, crand.Reader)
+}
+
+// NewPseudoRandomIdentity creates a new Identity of the given key type using a math.Rand source of
+// entropy.
+func NewPseudoRandomIdentity(rng *mrand.Rand, kt KeyType) (Identity, error) {
+	return newRandomIdentity(kt, rng)
+}
+
+func newRandomIdentity(kt KeyType, reader io.Reader) (Identity, error) {
+	switch kt {
+	case KeyTypeSecp256k1:
+		return newRandom(reader), nil
+	case KeyTypeP256:
+		key, err := ecdsa.GenerateKey(elliptic.P256(), reader)
+		if err != nil {
+			return nil, err
+		}
+		return FromPrivateKey(key), nil
+	case KeyTypeEd25519:
+		_, key, err := ed25519.GenerateKey(reader)
+		if err != nil {
+			return nil, err
+		}
+		return FromEd25519PrivateKey(key), nil
+	}
+	return nil, ErrUnknownKeyType
+}
+
+// FromEd25519PrivateKey creates a new Identity from an Ed25519 private key, whose ID is its
+// 32-byte public key.
+func FromEd25519PrivateKey(key ed25519.PrivateKey) Identity {
+	return &edid{
+		key: key,
+		id:  id.FromBytes(key.Public().(ed25519.PublicKey)),
+	}
+}
+
+func (x *edid) String() string {
+	return x.id.String()
+}
+
+func (x *edid) Bytes() []byte {
+	return x.id.Bytes()
+}
+
+func (x *edid) ID() id.ID {
+	return x.id
+}
+
+func (x *edid) PublicKeyBytes() []byte {
+	return []byte(x.key.Public().(ed25519.PublicKey))
+}
+
+func (x *edid) KeyType() KeyType {
+	return KeyTypeEd25519
+}
+
+func (x *edid) Signer() crypto.Signer {
+	return x.key
+}
+
+// ParsePublicKey parses the encoded public key of the given key type, returning an
+// *ecdsa.PublicKey or ed25519.PublicKey.
+func ParsePublicKey(kt KeyType, buf []byte) (crypto.PublicKey, error) {
+	switch kt {
+	case KeyTypeSecp256k1:
+		return FromPublicKeyBytes(buf)
+	case KeyTypeP256:
+		x, y := elliptic.Unmarshal(elliptic.P256(), buf) // also checks (x, y) is on curve
+		if x == nil {
+			return nil, ErrKeyPointOffCurve
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	case KeyTypeEd25519:
+		if len(buf) != ed25519.PublicKeySize {
+			return nil, ErrInvalidPublicKey
+		}
+		return ed25519.PublicKey(buf), nil
+	}
+	return nil, ErrUnknownKeyType
+}
+
+// PublicKeyID returns the ID of a public key parsed by ParsePublicKey: the x-value of ECDSA public
+// keys and the whole of Ed25519 public keys.
+func PublicKeyID(pub crypto.PublicKey) (id.ID, error) {
+	switch pub := pub.(type) {
+	case *ecdsa.PublicKey:
+		return id.FromPublicKey(pub), nil
+	case ed25519.PublicKey:
+		return id.FromBytes(pub), nil
+	}
+	return nil, ErrUnknownKeyType
+}
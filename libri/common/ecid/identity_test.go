@@ -0,0 +1,84 @@
+package ecid
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyType_StringParse(t *testing.T) {
+	for _, kt := range []KeyType{KeyTypeSecp256k1, KeyTypeP256, KeyTypeEd25519} {
+		parsed, err := ParseKeyType(kt.String())
+		assert.Nil(t, err)
+		assert.Equal(t, kt, parsed)
+	}
+	assert.Equal(t, "KeyType(3)", KeyType(3).String())
+
+	parsed, err := ParseKeyType("some other curve")
+	assert.Equal(t, ErrUnknownKeyType, err)
+	assert.Equal(t, KeyTypeSecp256k1, parsed)
+}
+
+func TestNewRandomIdentity(t *testing.T) {
+	for _, kt := range []KeyType{KeyTypeSecp256k1, KeyTypeP256, KeyTypeEd25519} {
+		i1, err := NewRandomIdentity(kt)
+		assert.Nil(t, err)
+		assert.Equal(t, kt, i1.KeyType())
+		assert.Equal(t, id.Length, len(i1.Bytes()))
+		assert.Equal(t, i1.ID().String(), i1.String())
+
+		i2, err := NewRandomIdentity(kt)
+		assert.Nil(t, err)
+		assert.NotEqual(t, i1.Bytes(), i2.Bytes())
+	}
+
+	i, err := NewRandomIdentity(KeyType(-1))
+	assert.Equal(t, ErrUnknownKeyType, err)
+	assert.Nil(t, i)
+}
+
+func TestParsePublicKey_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	for _, kt := range []KeyType{KeyTypeSecp256k1, KeyTypeP256, KeyTypeEd25519} {
+		i, err := NewPseudoRandomIdentity(rng, kt)
+		assert.Nil(t, err)
+		pub, err := ParsePublicKey(kt, i.PublicKeyBytes())
+		assert.Nil(t, err)
+		assert.Equal(t, i.Signer().Public(), pub)
+
+		pubID, err := PublicKeyID(pub)
+		assert.Nil(t, err)
+		assert.Equal(t, i.ID(), pubID)
+	}
+}
+
+func TestParsePublicKey_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	ecPub := NewPseudoRandom(rng).PublicKeyBytes()
+	p256ID, err := NewPseudoRandomIdentity(rng, KeyTypeP256)
+	assert.Nil(t, err)
+	edID, err := NewPseudoRandomIdentity(rng, KeyTypeEd25519)
+	assert.Nil(t, err)
+
+	cases := []struct {
+		kt  KeyType
+		buf []byte
+	}{
+		{KeyTypeSecp256k1, p256ID.PublicKeyBytes()}, // off secp256k1 curve
+		{KeyTypeP256, ecPub},                        // off P-256 curve
+		{KeyTypeEd25519, ecPub},                     // wrong length
+		{KeyTypeSecp256k1, edID.PublicKeyBytes()},   // wrong length
+		{KeyType(-1), ecPub},                        // unknown key type
+	}
+	for i, c := range cases {
+		pub, err := ParsePublicKey(c.kt, c.buf)
+		assert.NotNil(t, err, i)
+		assert.Nil(t, pub, i)
+	}
+
+	pubID, err := PublicKeyID("not a public key")
+	assert.Equal(t, ErrUnknownKeyType, err)
+	assert.Nil(t, pubID)
+}
@@ -0,0 +1,58 @@
+package ecid
+
+import (
+	crand "crypto/rand"
+	"crypto/sha256"
+	"io"
+	"math/bits"
+	mrand "math/rand"
+
+	"github.com/drausin/libri/libri/common/id"
+)
+
+// IDDifficulty returns the number of leading zero bits in the SHA-256 hash of the ID, i.e., the
+// difficulty of the static puzzle it solves. Since an ID is derived from its public key (and for
+// Ed25519 keys is the public key), the only way to get an ID of a given difficulty is to generate
+// keys until one solves the puzzle.
+func IDDifficulty(x id.ID) uint {
+	hash := sha256.Sum256(x.Bytes())
+	difficulty := uint(0)
+	for _, b := range hash {
+		difficulty += uint(bits.LeadingZeros8(b))
+		if b != 0 {
+			break
+		}
+	}
+	return difficulty
+}
+
+// SolvesIDPuzzle returns whether the ID's difficulty is at least the given difficulty.
+func SolvesIDPuzzle(x id.ID, difficulty uint) bool {
+	return difficulty == 0 || IDDifficulty(x) >= difficulty
+}
+
+// NewRandomPuzzleIdentity creates a new Identity of the given key type whose ID solves the puzzle
+// of the given difficulty, using a crypto.Reader source of entropy. Creating an Identity takes
+// about 2^difficulty key generations.
+func NewRandomPuzzleIdentity(kt KeyType, difficulty uint) (Identity, error) {
+	return newPuzzleIdentity(kt, difficulty, crand.Reader)
+}
+
+// NewPseudoRandomPuzzleIdentity creates a new Identity of the given key type whose ID solves the
+// puzzle of the given difficulty, using a math.Rand source of entropy.
+func NewPseudoRandomPuzzleIdentity(rng *mrand.Rand, kt KeyType, difficulty uint) (Identity,
+	error) {
+	return newPuzzleIdentity(kt, difficulty, rng)
+}
+
+func newPuzzleIdentity(kt KeyType, difficulty uint, reader io.Reader) (Identity, error) {
+	for {
+		i, err := newRandomIdentity(kt, reader)
+		if err != nil {
+			return nil, err
+		}
+		if SolvesIDPuzzle(i.ID(), difficulty) {
+			return i, nil
+		}
+	}
+}
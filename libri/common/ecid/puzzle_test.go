@@ -0,0 +1,46 @@
+package ecid
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIDDifficulty(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	counts := make(map[uint]int)
+	for c := 0; c < 256; c++ {
+		x := id.NewPseudoRandom(rng)
+		difficulty := IDDifficulty(x)
+		counts[difficulty]++
+		assert.True(t, SolvesIDPuzzle(x, 0))
+		assert.True(t, SolvesIDPuzzle(x, difficulty))
+		assert.False(t, SolvesIDPuzzle(x, difficulty+1))
+	}
+
+	// about half of IDs have each additional leading zero bit
+	assert.True(t, counts[0] > 96)
+	assert.True(t, counts[1] > 32)
+}
+
+func TestNewPuzzleIdentity(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	for _, kt := range []KeyType{KeyTypeSecp256k1, KeyTypeP256, KeyTypeEd25519} {
+		for _, difficulty := range []uint{0, 4, 8} {
+			i, err := NewPseudoRandomPuzzleIdentity(rng, kt, difficulty)
+			assert.Nil(t, err)
+			assert.Equal(t, kt, i.KeyType())
+			assert.True(t, IDDifficulty(i.ID()) >= difficulty)
+		}
+	}
+
+	i, err := NewRandomPuzzleIdentity(KeyTypeSecp256k1, 2)
+	assert.Nil(t, err)
+	assert.True(t, SolvesIDPuzzle(i.ID(), 2))
+
+	i, err = NewRandomPuzzleIdentity(KeyType(-1), 2)
+	assert.Equal(t, ErrUnknownKeyType, err)
+	assert.Nil(t, i)
+}
@@ -1,48 +1,83 @@
 package ecid
 
 import (
+	"bytes"
 	"crypto/ecdsa"
+	"crypto/elliptic"
 	"fmt"
 	"math/big"
 
 	"github.com/drausin/libri/libri/common/id"
 	"github.com/ethereum/go-ethereum/crypto/secp256k1"
+	"golang.org/x/crypto/ed25519"
 )
 
-// FromStored creates a new ID instance from a ECID instance.
-func FromStored(stored *ECDSAPrivateKey) (ID, error) {
+// FromStored creates a new Identity instance from a stored private key, whose curve name gives its
+// key type.
+func FromStored(stored *ECDSAPrivateKey) (Identity, error) {
 	key := new(ecdsa.PrivateKey)
-
 	switch stored.Curve {
-	case "secp256k1":
+	case CurveName:
 		key.PublicKey.Curve = secp256k1.S256()
+	case P256CurveName:
+		key.PublicKey.Curve = elliptic.P256()
+	case Ed25519CurveName:
+		return fromStoredEd25519(stored)
 	default:
 		return nil, fmt.Errorf("unrecognized curve %v", stored.Curve)
 	}
-
 	key.PublicKey.X = new(big.Int).SetBytes(stored.X)
 	key.PublicKey.Y = new(big.Int).SetBytes(stored.Y)
 	key.D = new(big.Int).SetBytes(stored.D)
-
 	if !key.Curve.IsOnCurve(key.PublicKey.X, key.PublicKey.Y) {
 		// redundancy check: should never hit this, but here just in case
 		return nil, fmt.Errorf("public key (x = %v, y = %v) is not on curve %v",
 			key.PublicKey.X, key.PublicKey.Y, key.PublicKey.Curve.Params().Name)
 	}
-
 	return &ecid{
 		key: key,
 		id:  id.FromInt(key.X),
 	}, nil
 }
 
-// ToStored creates a new ECID instance from an ID instance.
-func ToStored(ecid ID) *ECDSAPrivateKey {
-	key := ecid.Key()
-	return &ECDSAPrivateKey{
-		Curve: CurveName,
-		X:     key.X.Bytes(),
-		Y:     key.Y.Bytes(),
-		D:     key.D.Bytes(),
+// ToStored creates a new stored private key from an Identity instance. ECDSA keys store their
+// private key and public key point, while Ed25519 keys store their private key seed as D and their
+// public key as X.
+func ToStored(identity Identity) *ECDSAPrivateKey {
+	switch signer := identity.Signer().(type) {
+	case ed25519.PrivateKey:
+		return &ECDSAPrivateKey{
+			Curve: Ed25519CurveName,
+			X:     identity.PublicKeyBytes(),
+			D:     signer[:ed25519.PublicKeySize],
+		}
+	case *ecdsa.PrivateKey:
+		return &ECDSAPrivateKey{
+			Curve: identity.KeyType().String(),
+			X:     signer.X.Bytes(),
+			Y:     signer.Y.Bytes(),
+			D:     signer.D.Bytes(),
+		}
+	}
+	// should never get here
+	panic(ErrUnknownKeyType)
+}
+
+func fromStoredEd25519(stored *ECDSAPrivateKey) (Identity, error) {
+	if len(stored.D) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid %v private key seed length %d", Ed25519CurveName,
+			len(stored.D))
+	}
+	// key generation just expands the seed read from the reader
+	_, key, err := ed25519.GenerateKey(bytes.NewReader(stored.D))
+	if err != nil {
+		return nil, err
+	}
+	identity := FromEd25519PrivateKey(key)
+	if !bytes.Equal(identity.PublicKeyBytes(), stored.X) {
+		// redundancy check: should never hit this, but here just in case
+		return nil, fmt.Errorf("public key %x does not match %v private key", stored.X,
+			Ed25519CurveName)
 	}
+	return identity, nil
 }
@@ -29,9 +29,10 @@ var _ = math.Inf
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
 // ECDSAPrivateKey represents an ECDSA key-pair, whose public key x-value is used as the peer ID
-// to outside world.
+// to outside world. Ed25519 key-pairs are also stored as ECDSAPrivateKeys, with their private key
+// seed as D and their public key as X.
 type ECDSAPrivateKey struct {
-	// name of the curve used
+	// name of the curve used (secp256k1, P-256, or ed25519), giving the key type
 	Curve string `protobuf:"bytes,1,opt,name=curve" json:"curve,omitempty"`
 	// private key
 	D []byte `protobuf:"bytes,2,opt,name=D,proto3" json:"D,omitempty"`
@@ -81,14 +82,14 @@ func init() {
 func init() { proto.RegisterFile("libri/common/ecid/storage.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 132 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xe2, 0x92, 0xcf, 0xc9, 0x4c, 0x2a,
+	// 129 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x92, 0xcf, 0xc9, 0x4c, 0x2a,
 	0xca, 0xd4, 0x4f, 0xce, 0xcf, 0xcd, 0xcd, 0xcf, 0xd3, 0x4f, 0x4d, 0xce, 0x4c, 0xd1, 0x2f, 0x2e,
 	0xc9, 0x2f, 0x4a, 0x4c, 0x4f, 0xd5, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x01, 0x89, 0x29,
 	0x05, 0x72, 0xf1, 0xbb, 0x3a, 0xbb, 0x04, 0x3b, 0x06, 0x14, 0x65, 0x96, 0x25, 0x96, 0xa4, 0x7a,
 	0xa7, 0x56, 0x0a, 0x89, 0x70, 0xb1, 0x26, 0x97, 0x16, 0x95, 0xa5, 0x4a, 0x30, 0x2a, 0x30, 0x6a,
 	0x70, 0x06, 0x41, 0x38, 0x42, 0x3c, 0x5c, 0x8c, 0x2e, 0x12, 0x4c, 0x0a, 0x8c, 0x1a, 0x3c, 0x41,
 	0x8c, 0x2e, 0x20, 0x5e, 0x84, 0x04, 0x33, 0x84, 0x17, 0x01, 0xe2, 0x45, 0x4a, 0xb0, 0x40, 0x78,
-	0x91, 0x49, 0x6c, 0x60, 0xf3, 0x8d, 0x01, 0x01, 0x00, 0x00, 0xff, 0xff, 0xb6, 0x4a, 0x7c, 0x21,
-	0x82, 0x00, 0x00, 0x00,
+	0x91, 0x49, 0x6c, 0x60, 0xf3, 0x8d, 0x01, 0x03, 0x00, 0xb6, 0x4a, 0x7c, 0x21, 0x82, 0x00, 0x00,
+	0x00,
 }
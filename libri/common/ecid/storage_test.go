@@ -16,7 +16,7 @@ func TestToFromStored_ok(t *testing.T) {
 
 		assert.Nil(t, err)
 		assert.Equal(t, original.Bytes(), retrieved.Bytes())
-		assert.Equal(t, original.Key().D.Bytes(), retrieved.Key().D.Bytes())
+		assert.Equal(t, original.Key().D.Bytes(), retrieved.(ID).Key().D.Bytes())
 	}
 }
 
@@ -41,3 +41,40 @@ func TestToFromStored_pubKeyErr(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Nil(t, retrieved)
 }
+
+func TestToFromStored_keyTypes(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	for _, kt := range []KeyType{KeyTypeSecp256k1, KeyTypeP256, KeyTypeEd25519} {
+		original, err := NewPseudoRandomIdentity(rng, kt)
+		assert.Nil(t, err)
+		stored := ToStored(original)
+		assert.Equal(t, kt.String(), stored.Curve)
+		retrieved, err := FromStored(stored)
+
+		assert.Nil(t, err)
+		assert.Equal(t, kt, retrieved.KeyType())
+		assert.Equal(t, original.Bytes(), retrieved.Bytes())
+		assert.Equal(t, original.PublicKeyBytes(), retrieved.PublicKeyBytes())
+		assert.Equal(t, original.Signer(), retrieved.Signer())
+	}
+}
+
+func TestFromStored_ed25519Err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	original, err := NewPseudoRandomIdentity(rng, KeyTypeEd25519)
+	assert.Nil(t, err)
+
+	// check wrong seed length triggers error
+	stored := ToStored(original)
+	stored.D = stored.D[1:]
+	retrieved, err := FromStored(stored)
+	assert.NotNil(t, err)
+	assert.Nil(t, retrieved)
+
+	// check mismatched public key triggers error
+	stored = ToStored(original)
+	stored.X = []byte("the wrong public key")
+	retrieved, err = FromStored(stored)
+	assert.NotNil(t, err)
+	assert.Nil(t, retrieved)
+}
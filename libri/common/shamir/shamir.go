@@ -0,0 +1,157 @@
+// Package shamir implements Shamir's secret sharing over GF(2^8), splitting each byte of a secret
+// independently.
+package shamir
+
+import (
+	"errors"
+	"io"
+)
+
+const (
+	// MaxShares is the maximum number of shares a secret can be split into, limited by the number
+	// of distinct non-zero x-coordinates in GF(2^8).
+	MaxShares = 255
+
+	// MinThreshold is the minimum number of shares needed to reconstruct a secret.
+	MinThreshold = 2
+)
+
+var (
+	// ErrInvalidThreshold indicates when the threshold is less than MinThreshold or greater than
+	// the number of shares.
+	ErrInvalidThreshold = errors.New("threshold must be between 2 and the number of shares")
+
+	// ErrTooManyShares indicates when more than MaxShares shares are requested.
+	ErrTooManyShares = errors.New("number of shares must be at most 255")
+
+	// ErrEmptySecret indicates when the secret to split is empty.
+	ErrEmptySecret = errors.New("secret must not be empty")
+
+	// ErrTooFewShares indicates when fewer than MinThreshold shares are combined.
+	ErrTooFewShares = errors.New("at least two shares are needed to reconstruct a secret")
+
+	// ErrInconsistentShares indicates when combined shares have different lengths or the same
+	// x-coordinate.
+	ErrInconsistentShares = errors.New("shares have different lengths or duplicate x-coordinates")
+)
+
+// Split splits the secret into n shares, any t of which can reconstruct it. Each share is the
+// length of the secret plus a final byte holding the share's x-coordinate. The random polynomial
+// coefficients are read from rng.
+func Split(secret []byte, n, t int, rng io.Reader) ([][]byte, error) {
+	if len(secret) == 0 {
+		return nil, ErrEmptySecret
+	}
+	if n > MaxShares {
+		return nil, ErrTooManyShares
+	}
+	if t < MinThreshold || t > n {
+		return nil, ErrInvalidThreshold
+	}
+	shares := make([][]byte, n)
+	for i := range shares {
+		shares[i] = make([]byte, len(secret)+1)
+		shares[i][len(secret)] = byte(i + 1)
+	}
+
+	// coeffs[0] is the secret byte, the rest are random
+	coeffs := make([]byte, t)
+	for j, secretByte := range secret {
+		coeffs[0] = secretByte
+		if _, err := io.ReadFull(rng, coeffs[1:]); err != nil {
+			return nil, err
+		}
+		for _, share := range shares {
+			share[j] = evaluate(coeffs, share[len(secret)])
+		}
+	}
+	return shares, nil
+}
+
+// Combine reconstructs a secret from shares created by Split. It needs at least the threshold
+// number of shares given to Split; fewer yield a random secret rather than an error.
+func Combine(shares [][]byte) ([]byte, error) {
+	if len(shares) < MinThreshold {
+		return nil, ErrTooFewShares
+	}
+	shareLen := len(shares[0])
+	if shareLen < 2 {
+		return nil, ErrInconsistentShares
+	}
+	xs := make([]byte, len(shares))
+	seen := make(map[byte]struct{})
+	for i, share := range shares {
+		if len(share) != shareLen {
+			return nil, ErrInconsistentShares
+		}
+		xs[i] = share[shareLen-1]
+		if _, in := seen[xs[i]]; in || xs[i] == 0 {
+			return nil, ErrInconsistentShares
+		}
+		seen[xs[i]] = struct{}{}
+	}
+
+	// Lagrange basis polynomials evaluated at x = 0
+	basis := make([]byte, len(shares))
+	for i := range shares {
+		basis[i] = 1
+		for m := range shares {
+			if m != i {
+				basis[i] = mul(basis[i], div(xs[m], add(xs[m], xs[i])))
+			}
+		}
+	}
+
+	secret := make([]byte, shareLen-1)
+	for j := range secret {
+		for i, share := range shares {
+			secret[j] = add(secret[j], mul(share[j], basis[i]))
+		}
+	}
+	return secret, nil
+}
+
+// evaluate evaluates the polynomial with the given coefficients at x using Horner's method.
+func evaluate(coeffs []byte, x byte) byte {
+	y := byte(0)
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		y = add(mul(y, x), coeffs[i])
+	}
+	return y
+}
+
+// add adds (and subtracts) two elements of GF(2^8).
+func add(a, b byte) byte {
+	return a ^ b
+}
+
+// mul multiplies two elements of GF(2^8) modulo the AES polynomial x^8 + x^4 + x^3 + x + 1.
+func mul(a, b byte) byte {
+	p := byte(0)
+	for b > 0 {
+		if b&1 == 1 {
+			p ^= a
+		}
+		carry := a & 0x80
+		a <<= 1
+		if carry != 0 {
+			a ^= 0x1b
+		}
+		b >>= 1
+	}
+	return p
+}
+
+// div divides a by non-zero b in GF(2^8).
+func div(a, b byte) byte {
+	return mul(a, inverse(b))
+}
+
+// inverse returns the multiplicative inverse of non-zero a in GF(2^8), which is a^254.
+func inverse(a byte) byte {
+	inv := byte(1)
+	for i := 0; i < 254; i++ {
+		inv = mul(inv, a)
+	}
+	return inv
+}
@@ -0,0 +1,114 @@
+package shamir
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitCombine_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	for _, c := range []struct{ n, t int }{{2, 2}, {3, 2}, {5, 3}, {10, 10}, {255, 4}} {
+		secret := make([]byte, 64)
+		_, err := rng.Read(secret)
+		assert.Nil(t, err)
+
+		shares, err := Split(secret, c.n, c.t, rng)
+		assert.Nil(t, err)
+		assert.Len(t, shares, c.n)
+
+		// check any t shares reconstruct the secret
+		for k := 0; k < 5; k++ {
+			perm := rng.Perm(c.n)
+			subset := make([][]byte, c.t)
+			for i := range subset {
+				subset[i] = shares[perm[i]]
+			}
+			combined, err := Combine(subset)
+			assert.Nil(t, err)
+			assert.Equal(t, secret, combined)
+		}
+
+		// check all shares reconstruct the secret
+		combined, err := Combine(shares)
+		assert.Nil(t, err)
+		assert.Equal(t, secret, combined)
+
+		// check fewer than t shares don't reconstruct the secret
+		if c.t > MinThreshold {
+			combined, err := Combine(shares[:c.t-1])
+			assert.Nil(t, err)
+			assert.NotEqual(t, secret, combined)
+		}
+	}
+}
+
+func TestSplit_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	secret := []byte("some secret")
+	cases := []struct {
+		secret []byte
+		n, t   int
+		err    error
+	}{
+		{secret: nil, n: 3, t: 2, err: ErrEmptySecret},
+		{secret: secret, n: 256, t: 2, err: ErrTooManyShares},
+		{secret: secret, n: 3, t: 1, err: ErrInvalidThreshold},
+		{secret: secret, n: 3, t: 4, err: ErrInvalidThreshold},
+	}
+	for i, c := range cases {
+		shares, err := Split(c.secret, c.n, c.t, rng)
+		assert.Equal(t, c.err, err, "case %d", i)
+		assert.Nil(t, shares, "case %d", i)
+	}
+
+	// check rng error bubbles up
+	shares, err := Split(secret, 3, 2, &errReader{})
+	assert.NotNil(t, err)
+	assert.Nil(t, shares)
+}
+
+func TestCombine_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	shares, err := Split([]byte("some secret"), 3, 2, rng)
+	assert.Nil(t, err)
+
+	cases := []struct {
+		shares [][]byte
+		err    error
+	}{
+		{shares: shares[:1], err: ErrTooFewShares},
+		{shares: [][]byte{{1}, {2}}, err: ErrInconsistentShares},
+		{shares: [][]byte{shares[0], shares[1][1:]}, err: ErrInconsistentShares},
+		{shares: [][]byte{shares[0], shares[0]}, err: ErrInconsistentShares},
+		{shares: [][]byte{shares[0], {1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 0}},
+			err: ErrInconsistentShares},
+	}
+	for i, c := range cases {
+		secret, err := Combine(c.shares)
+		assert.Equal(t, c.err, err, "case %d", i)
+		assert.Nil(t, secret, "case %d", i)
+	}
+}
+
+func TestGF256(t *testing.T) {
+	// check a * a^-1 = 1 and (a * b) / b = a for all non-zero a, b
+	for a := 1; a < 256; a++ {
+		assert.Equal(t, byte(1), mul(byte(a), inverse(byte(a))))
+		for b := 1; b < 256; b++ {
+			assert.Equal(t, byte(a), div(mul(byte(a), byte(b)), byte(b)))
+		}
+	}
+
+	// AES spec example: {57} * {83} = {c1}
+	assert.Equal(t, byte(0xc1), mul(0x57, 0x83))
+	assert.Equal(t, byte(0), mul(0, 0x83))
+}
+
+type errReader struct{}
+
+func (errReader) Read(p []byte) (int, error) {
+	return 0, errors.New("some Read error")
+}
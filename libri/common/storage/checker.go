@@ -5,6 +5,9 @@ import (
 	"crypto/sha256"
 	"errors"
 	"fmt"
+
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/golang/protobuf/proto"
 )
 
 // Checker checks that a key or value is value.
@@ -105,3 +108,23 @@ func (hc *hashChecker) Check(key []byte, value []byte) error {
 	}
 	return nil
 }
+
+type pointerChecker struct{}
+
+// NewPointerKeyValueChecker returns a new KeyValueChecker that checks that the value is a Pointer
+// document signed by its author and that the key is derived from its author public key and name.
+func NewPointerKeyValueChecker() KeyValueChecker {
+	return &pointerChecker{}
+}
+
+func (pc *pointerChecker) Check(key []byte, value []byte) error {
+	doc := &api.Document{}
+	if err := proto.Unmarshal(value, doc); err != nil {
+		return err
+	}
+	pointer, ok := doc.Contents.(*api.Document_Pointer)
+	if !ok {
+		return api.ErrUnexpectedDocumentType
+	}
+	return api.VerifyPointer(key, pointer.Pointer)
+}
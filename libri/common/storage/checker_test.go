@@ -3,8 +3,11 @@ package storage
 import (
 	"bytes"
 	"crypto/sha256"
+	"math/rand"
 	"testing"
 
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/golang/protobuf/proto"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -107,3 +110,42 @@ func TestHashChecker_Check_err(t *testing.T) {
 	c := NewHashKeyValueChecker()
 	assert.NotNil(t, c.Check([]byte{0, 1, 2}, []byte{0, 1, 2}))
 }
+
+func TestPointerChecker_Check_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	c := NewPointerKeyValueChecker()
+	p := api.NewTestPointer(rng)
+	v, err := proto.Marshal(&api.Document{Contents: &api.Document_Pointer{Pointer: p}})
+	assert.Nil(t, err)
+	k := api.GetPointerKey(p.AuthorPublicKey, p.Name)
+	assert.Nil(t, c.Check(k.Bytes(), v))
+}
+
+func TestPointerChecker_Check_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	c := NewPointerKeyValueChecker()
+
+	// bad value bytes
+	assert.NotNil(t, c.Check(api.RandBytes(rng, 32), []byte{255, 255, 255}))
+
+	// not a pointer
+	doc, k1 := api.NewTestDocument(rng)
+	v1, err := proto.Marshal(doc)
+	assert.Nil(t, err)
+	assert.Equal(t, api.ErrUnexpectedDocumentType, c.Check(k1.Bytes(), v1))
+
+	// key not derived from pointer
+	p2 := api.NewTestPointer(rng)
+	v2, err := proto.Marshal(&api.Document{Contents: &api.Document_Pointer{Pointer: p2}})
+	assert.Nil(t, err)
+	hash := sha256.Sum256(v2)
+	assert.NotNil(t, c.Check(hash[:], v2))
+
+	// bad signature
+	p3 := api.NewTestPointer(rng)
+	p3.Sequence++
+	v3, err := proto.Marshal(&api.Document{Contents: &api.Document_Pointer{Pointer: p3}})
+	assert.Nil(t, err)
+	k3 := api.GetPointerKey(p3.AuthorPublicKey, p3.Name)
+	assert.NotNil(t, c.Check(k3.Bytes(), v3))
+}
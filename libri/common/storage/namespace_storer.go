@@ -1,6 +1,11 @@
 package storage
 
 import (
+	"bytes"
+	"errors"
+	"sync"
+	"time"
+
 	"github.com/drausin/libri/libri/common/db"
 	"github.com/drausin/libri/libri/common/id"
 	"github.com/drausin/libri/libri/librarian/api"
@@ -21,9 +26,24 @@ const (
 	// on top of the value to account for Entry values other than the actual ciphertext (which
 	// we want to be <= 2MB).
 	MaxEntriesValueLength = 2*1024*1024 + 1024
+
+	// MaxCachedValueLength is the maximum length of cached document values, which wrap an entry
+	// value with its expiry.
+	MaxCachedValueLength = MaxEntriesValueLength + 32
+
+	// cachedDocumentIndexKey is the server namespace key of the index of cache copies.
+	cachedDocumentIndexKey = "CachedDocumentIndex"
 )
 
 var (
+	// ErrStalePointer indicates when a Pointer document does not have a larger sequence number
+	// than the existing Pointer it would replace.
+	ErrStalePointer = errors.New("pointer sequence number not larger than existing")
+
+	// ErrCacheCapExceeded indicates when a cache copy is larger than the total capacity of the
+	// cache.
+	ErrCacheCapExceeded = errors.New("cached value larger than cache capacity")
+
 	// Server namespace contains values relevant to a server.
 	Server Namespace = []byte("server")
 
@@ -32,6 +52,9 @@ var (
 
 	// Documents namespace contains all libri p2p stored values.
 	Documents Namespace = []byte("documents")
+
+	// Cache namespace contains cache copies of libri p2p stored values.
+	Cache Namespace = []byte("cache")
 )
 
 // Namespace denotes a storage namespace, which reduces to a key prefix.
@@ -146,6 +169,10 @@ type DocumentSLD interface {
 type documentSLD struct {
 	sld NamespaceSLD
 	c   KeyValueChecker
+	pc  KeyValueChecker
+
+	// serializes Pointer replacements
+	mu sync.Mutex
 }
 
 // NewDocumentSLD creates a new NamespaceSL for the "entries" namespace
@@ -160,11 +187,14 @@ func NewDocumentSLD(kvdb db.KVDB) DocumentSLD {
 				NewMaxLengthChecker(MaxEntriesValueLength),
 			),
 		},
-		c: NewHashKeyValueChecker(),
+		c:  NewHashKeyValueChecker(),
+		pc: NewPointerKeyValueChecker(),
 	}
 }
 
-// Store checks that the key equals the SHA256 hash of the value before storing it.
+// Store checks that the key equals the SHA256 hash of the value before storing it. Pointer
+// documents are instead checked with the pointer checker and only replace an existing Pointer
+// when they have a larger sequence number.
 func (dsld *documentSLD) Store(key id.ID, value *api.Document) error {
 	if err := api.ValidateDocument(value); err != nil {
 		return err
@@ -174,12 +204,38 @@ func (dsld *documentSLD) Store(key id.ID, value *api.Document) error {
 		return err
 	}
 	keyBytes := key.Bytes()
-	if err := dsld.c.Check(keyBytes, valueBytes); err != nil {
+	if err := dsld.checker(value).Check(keyBytes, valueBytes); err != nil {
 		return err
 	}
+	if _, ok := value.Contents.(*api.Document_Pointer); ok {
+		return dsld.storePointer(key, value, valueBytes)
+	}
 	return dsld.sld.Store(keyBytes, valueBytes)
 }
 
+func (dsld *documentSLD) storePointer(key id.ID, value *api.Document, valueBytes []byte) error {
+	dsld.mu.Lock()
+	defer dsld.mu.Unlock()
+	existingBytes, err := dsld.sld.Load(key.Bytes())
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(existingBytes, valueBytes) {
+		// storing the same pointer again is a no-op
+		return nil
+	}
+	if existingBytes != nil {
+		existing := &api.Document{}
+		if err := proto.Unmarshal(existingBytes, existing); err != nil {
+			return err
+		}
+		if !api.IsNewerPointer(value, existing) {
+			return ErrStalePointer
+		}
+	}
+	return dsld.sld.Store(key.Bytes(), valueBytes)
+}
+
 func (dsld *documentSLD) Load(key id.ID) (*api.Document, error) {
 	keyBytes := key.Bytes()
 	valueBytes, err := dsld.sld.Load(keyBytes)
@@ -189,14 +245,14 @@ func (dsld *documentSLD) Load(key id.ID) (*api.Document, error) {
 	if valueBytes == nil {
 		return nil, nil
 	}
-	if err := dsld.c.Check(keyBytes, valueBytes); err != nil {
-		// should never happen b/c we check on Store, but being defensive just in case
-		return nil, err
-	}
 	doc := &api.Document{}
 	if err := proto.Unmarshal(valueBytes, doc); err != nil {
 		return nil, err
 	}
+	if err := dsld.checker(doc).Check(keyBytes, valueBytes); err != nil {
+		// should never happen b/c we check on Store, but being defensive just in case
+		return nil, err
+	}
 	if err := api.ValidateDocument(doc); err != nil {
 		// should never happen b/c we check on Store, but being defensive just in case
 		return nil, err
@@ -207,3 +263,221 @@ func (dsld *documentSLD) Load(key id.ID) (*api.Document, error) {
 func (dsld *documentSLD) Delete(key id.ID) error {
 	return dsld.sld.Delete(key.Bytes())
 }
+
+// checker returns the KeyValueChecker for the type of document.
+func (dsld *documentSLD) checker(value *api.Document) KeyValueChecker {
+	if _, ok := value.Contents.(*api.Document_Pointer); ok {
+		return dsld.pc
+	}
+	return dsld.c
+}
+
+// DocumentCache stores, loads, & deletes cache copies of api.Document values, which are kept
+// separately from primary replicas and only until they expire.
+type DocumentCache interface {
+	DocumentLD
+
+	// Cache stores a copy of the api.Document value under the given key until the expiry. Only
+	// documents whose keys are the hash of their values may be cached. The oldest cache copies
+	// are evicted to keep the cache within its capacity.
+	Cache(key id.ID, value *api.Document, expiry time.Time) error
+
+	// Sweep deletes all expired cache copies, returning the number deleted.
+	Sweep() (int, error)
+}
+
+type documentCache struct {
+	sld      NamespaceSLD
+	c        KeyValueChecker
+	indexSL  NamespaceSLD
+	maxBytes uint64
+	maxCount int
+
+	// index refers to the cache copies from oldest to newest
+	index  []*CachedDocumentRef
+	nBytes uint64
+	mu     sync.Mutex
+}
+
+// LoadDocumentCache loads a DocumentCache for the "cache" namespace backed by a db.KVDB instance,
+// which keeps at most maxCount cache copies taking at most maxBytes in total, evicting the oldest
+// copies to make room for new ones.
+func LoadDocumentCache(kvdb db.KVDB, maxBytes uint64, maxCount uint) (DocumentCache, error) {
+	dc := &documentCache{
+		sld: &namespaceSLD{
+			ns: Cache,
+			sld: NewKVDBStorerLoaderDeleter(
+				kvdb,
+				NewExactLengthChecker(EntriesKeyLength),
+				NewMaxLengthChecker(MaxCachedValueLength),
+			),
+		},
+		c: NewHashKeyValueChecker(),
+		indexSL: &namespaceSLD{
+			ns: Server,
+			sld: NewKVDBStorerLoaderDeleter(
+				kvdb,
+				NewMaxLengthChecker(MaxNamespaceKeyLength),
+				NewMaxLengthChecker(MaxNamespaceValueLength),
+			),
+		},
+		maxBytes: maxBytes,
+		maxCount: int(maxCount),
+	}
+	indexBytes, err := dc.indexSL.Load([]byte(cachedDocumentIndexKey))
+	if err != nil {
+		return nil, err
+	}
+	if indexBytes == nil {
+		return dc, nil
+	}
+	stored := &CachedDocumentIndex{}
+	if err := proto.Unmarshal(indexBytes, stored); err != nil {
+		return nil, err
+	}
+	dc.index = stored.Documents
+	for _, ref := range dc.index {
+		dc.nBytes += ref.Size
+	}
+	return dc, nil
+}
+
+func (dc *documentCache) Cache(key id.ID, value *api.Document, expiry time.Time) error {
+	if err := api.ValidateDocument(value); err != nil {
+		return err
+	}
+	valueBytes, err := proto.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if err := dc.c.Check(key.Bytes(), valueBytes); err != nil {
+		return err
+	}
+	cachedBytes, err := proto.Marshal(&CachedDocument{
+		Document: valueBytes,
+		Expiry:   expiry.Unix(),
+	})
+	if err != nil {
+		return err
+	}
+	size := uint64(len(cachedBytes))
+	if size > dc.maxBytes {
+		return ErrCacheCapExceeded
+	}
+
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	dc.removeRef(key.Bytes())
+	for len(dc.index) > 0 &&
+		(len(dc.index) >= dc.maxCount || dc.nBytes+size > dc.maxBytes) {
+		if err := dc.sld.Delete(dc.index[0].Key); err != nil {
+			return err
+		}
+		dc.nBytes -= dc.index[0].Size
+		dc.index = dc.index[1:]
+	}
+	if err := dc.sld.Store(key.Bytes(), cachedBytes); err != nil {
+		return err
+	}
+	dc.index = append(dc.index, &CachedDocumentRef{
+		Key:    key.Bytes(),
+		Expiry: expiry.Unix(),
+		Size:   size,
+	})
+	dc.nBytes += size
+	return dc.saveIndex()
+}
+
+// Load returns the cached api.Document value with the given key, or nil if it is missing or
+// expired. Expired values are deleted.
+func (dc *documentCache) Load(key id.ID) (*api.Document, error) {
+	keyBytes := key.Bytes()
+	cachedBytes, err := dc.sld.Load(keyBytes)
+	if err != nil {
+		return nil, err
+	}
+	if cachedBytes == nil {
+		return nil, nil
+	}
+	cached := &CachedDocument{}
+	if err := proto.Unmarshal(cachedBytes, cached); err != nil {
+		return nil, err
+	}
+	if !time.Now().Before(time.Unix(cached.Expiry, 0)) {
+		return nil, dc.Delete(key)
+	}
+	doc := &api.Document{}
+	if err := proto.Unmarshal(cached.Document, doc); err != nil {
+		return nil, err
+	}
+	if err := dc.c.Check(keyBytes, cached.Document); err != nil {
+		// should never happen b/c we check on Cache, but being defensive just in case
+		return nil, err
+	}
+	if err := api.ValidateDocument(doc); err != nil {
+		// should never happen b/c we check on Cache, but being defensive just in case
+		return nil, err
+	}
+	return doc, nil
+}
+
+func (dc *documentCache) Delete(key id.ID) error {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	if err := dc.sld.Delete(key.Bytes()); err != nil {
+		return err
+	}
+	if !dc.removeRef(key.Bytes()) {
+		return nil
+	}
+	return dc.saveIndex()
+}
+
+func (dc *documentCache) Sweep() (int, error) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	now := time.Now().Unix()
+	kept := make([]*CachedDocumentRef, 0, len(dc.index))
+	nSwept := 0
+	for _, ref := range dc.index {
+		if now < ref.Expiry {
+			kept = append(kept, ref)
+			continue
+		}
+		if err := dc.sld.Delete(ref.Key); err != nil {
+			return nSwept, err
+		}
+		dc.nBytes -= ref.Size
+		nSwept++
+	}
+	dc.index = kept
+	if nSwept == 0 {
+		return 0, nil
+	}
+	return nSwept, dc.saveIndex()
+}
+
+// removeRef removes the reference to the cache copy with the given key from the index, returning
+// whether it was there.
+func (dc *documentCache) removeRef(key []byte) bool {
+	for i, ref := range dc.index {
+		if bytes.Equal(ref.Key, key) {
+			dc.nBytes -= ref.Size
+			dc.index = append(dc.index[:i], dc.index[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (dc *documentCache) saveIndex() error {
+	if len(dc.index) == 0 {
+		// empty index marshals to zero bytes, which can't be stored
+		return dc.indexSL.Delete([]byte(cachedDocumentIndexKey))
+	}
+	indexBytes, err := proto.Marshal(&CachedDocumentIndex{Documents: dc.index})
+	if err != nil {
+		return err
+	}
+	return dc.indexSL.Store([]byte(cachedDocumentIndexKey), indexBytes)
+}
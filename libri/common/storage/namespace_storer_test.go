@@ -5,8 +5,10 @@ import (
 	"errors"
 	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/drausin/libri/libri/common/db"
+	"github.com/drausin/libri/libri/common/ecid"
 	"github.com/drausin/libri/libri/common/id"
 	"github.com/drausin/libri/libri/librarian/api"
 	"github.com/golang/protobuf/proto"
@@ -114,6 +116,17 @@ func TestDocumentNamespaceStorerLoader_StoreLoad_ok(t *testing.T) {
 	value2, err := dsl.Load(key)
 	assert.Nil(t, err)
 	assert.Equal(t, value1, value2)
+
+	// check signed entry
+	value3 := &api.Document{Contents: &api.Document_Entry{Entry: api.NewTestSignedEntry(rng)}}
+	key3, err := api.GetKey(value3)
+	assert.Nil(t, err)
+	err = dsl.Store(key3, value3)
+	assert.Nil(t, err)
+
+	value4, err := dsl.Load(key3)
+	assert.Nil(t, err)
+	assert.Equal(t, value3, value4)
 }
 
 func TestDocumentNamespaceStorerLoader_Store_err(t *testing.T) {
@@ -138,6 +151,67 @@ func TestDocumentNamespaceStorerLoader_Store_err(t *testing.T) {
 	key2 := id.NewPseudoRandom(rng)
 	err = dsl.Store(key2, value2)
 	assert.NotNil(t, err)
+
+	// check entry with bad author signature returns error
+	entry3 := api.NewTestSignedEntry(rng)
+	entry3.AuthorSignature = api.NewTestSignedEntry(rng).AuthorSignature
+	value3 := &api.Document{Contents: &api.Document_Entry{Entry: entry3}}
+	key3, err := api.GetKey(value3)
+	assert.Nil(t, err)
+	err = dsl.Store(key3, value3)
+	assert.Equal(t, api.ErrInvalidSignature, err)
+}
+
+func TestDocumentNamespaceStorerLoader_StoreLoad_pointer(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	kvdb, cleanup, err := db.NewTempDirRocksDB()
+	defer cleanup()
+	defer kvdb.Close()
+	assert.Nil(t, err)
+	dsl := NewDocumentSLD(kvdb)
+
+	authorKey := ecid.NewPseudoRandom(rng)
+	newPointerDoc := func(sequence uint64) *api.Document {
+		p := &api.Pointer{
+			AuthorPublicKey: authorKey.PublicKeyBytes(),
+			Name:            "some name",
+			TargetKey:       api.RandBytes(rng, api.DocumentKeyLength),
+			Sequence:        sequence,
+		}
+		err := api.SignPointer(p, authorKey.Key())
+		assert.Nil(t, err)
+		return &api.Document{Contents: &api.Document_Pointer{Pointer: p}}
+	}
+	key := api.GetPointerKey(authorKey.PublicKeyBytes(), "some name")
+
+	value1 := newPointerDoc(2)
+	err = dsl.Store(key, value1)
+	assert.Nil(t, err)
+
+	// storing same pointer again is fine
+	err = dsl.Store(key, value1)
+	assert.Nil(t, err)
+
+	// check pointers with smaller or equal sequence numbers don't replace existing
+	err = dsl.Store(key, newPointerDoc(1))
+	assert.Equal(t, ErrStalePointer, err)
+	err = dsl.Store(key, newPointerDoc(2))
+	assert.Equal(t, ErrStalePointer, err)
+	loaded, err := dsl.Load(key)
+	assert.Nil(t, err)
+	assert.Equal(t, value1, loaded)
+
+	// check pointer with larger sequence number replaces existing
+	value3 := newPointerDoc(3)
+	err = dsl.Store(key, value3)
+	assert.Nil(t, err)
+	loaded, err = dsl.Load(key)
+	assert.Nil(t, err)
+	assert.Equal(t, value3, loaded)
+
+	// check pointer with non-derived key returns error
+	err = dsl.Store(id.NewPseudoRandom(rng), newPointerDoc(4))
+	assert.NotNil(t, err)
 }
 
 func TestDocumentStorerLoader_Load_empty(t *testing.T) {
@@ -212,6 +286,190 @@ func TestDocumentStorerLoader_Load_validateDocumentErr(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestDocumentCache_CacheLoad(t *testing.T) {
+	kvdb, cleanup, err := db.NewTempDirRocksDB()
+	defer cleanup()
+	defer kvdb.Close()
+	assert.Nil(t, err)
+	dc, err := LoadDocumentCache(kvdb, 1<<30, 1024)
+	assert.Nil(t, err)
+	dsl := NewDocumentSLD(kvdb)
+
+	rng := rand.New(rand.NewSource(0))
+	value1, key1 := api.NewTestDocument(rng)
+	err = dc.Cache(key1, value1, time.Now().Add(time.Hour))
+	assert.Nil(t, err)
+
+	value2, err := dc.Load(key1)
+	assert.Nil(t, err)
+	assert.Equal(t, value1, value2)
+
+	// cache copies are kept separately from primary replicas
+	value3, err := dsl.Load(key1)
+	assert.Nil(t, err)
+	assert.Nil(t, value3)
+
+	// expired cache copies aren't loaded and are deleted
+	value4, key4 := api.NewTestDocument(rng)
+	err = dc.Cache(key4, value4, time.Now().Add(-time.Second))
+	assert.Nil(t, err)
+	value5, err := dc.Load(key4)
+	assert.Nil(t, err)
+	assert.Nil(t, value5)
+	cachedBytes, err := kvdb.Get(append(Cache, key4.Bytes()...))
+	assert.Nil(t, err)
+	assert.Nil(t, cachedBytes)
+
+	err = dc.Delete(key1)
+	assert.Nil(t, err)
+	value6, err := dc.Load(key1)
+	assert.Nil(t, err)
+	assert.Nil(t, value6)
+}
+
+func TestDocumentCache_Cache_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	kvdb, cleanup, err := db.NewTempDirRocksDB()
+	defer cleanup()
+	defer kvdb.Close()
+	assert.Nil(t, err)
+	dc, err := LoadDocumentCache(kvdb, 1<<30, 1024)
+	assert.Nil(t, err)
+	expiry := time.Now().Add(time.Hour)
+
+	// invalid document
+	value1, key1 := api.NewTestDocument(rng)
+	value1.Contents.(*api.Document_Entry).Entry.AuthorPublicKey = nil
+	assert.NotNil(t, dc.Cache(key1, value1, expiry))
+
+	// key isn't hash of value
+	value2, _ := api.NewTestDocument(rng)
+	assert.NotNil(t, dc.Cache(id.NewPseudoRandom(rng), value2, expiry))
+
+	// value larger than cache capacity
+	dc, err = LoadDocumentCache(kvdb, 16, 1024)
+	assert.Nil(t, err)
+	value3, key3 := api.NewTestDocument(rng)
+	assert.Equal(t, ErrCacheCapExceeded, dc.Cache(key3, value3, expiry))
+
+	// store error
+	dc = &documentCache{
+		sld:      &fixedNamespaceSLD{storeErr: errors.New("some store error")},
+		c:        NewHashKeyValueChecker(),
+		maxBytes: 1 << 30,
+		maxCount: 1024,
+	}
+	assert.NotNil(t, dc.Cache(key3, value3, expiry))
+}
+
+func TestDocumentCache_Cache_evict(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	kvdb, cleanup, err := db.NewTempDirRocksDB()
+	defer cleanup()
+	defer kvdb.Close()
+	assert.Nil(t, err)
+	expiry := time.Now().Add(time.Hour)
+	nDocs := 8
+	values, keys := make([]*api.Document, nDocs), make([]id.ID, nDocs)
+	for i := range values {
+		values[i], keys[i] = api.NewTestDocument(rng)
+	}
+
+	// count cap evicts the oldest copies
+	dc, err := LoadDocumentCache(kvdb, 1<<30, 4)
+	assert.Nil(t, err)
+	for i := range values {
+		assert.Nil(t, dc.Cache(keys[i], values[i], expiry))
+	}
+	for i := range values {
+		value, err := dc.Load(keys[i])
+		assert.Nil(t, err)
+		if i < nDocs-4 {
+			assert.Nil(t, value)
+		} else {
+			assert.Equal(t, values[i], value)
+		}
+	}
+
+	// index is reloaded with remaining copies, & byte cap evicts the oldest of them
+	maxBytes := dc.(*documentCache).nBytes / 2
+	dc, err = LoadDocumentCache(kvdb, maxBytes, 4)
+	assert.Nil(t, err)
+	assert.Len(t, dc.(*documentCache).index, 4)
+	assert.Nil(t, dc.Cache(keys[0], values[0], expiry))
+	assert.True(t, dc.(*documentCache).nBytes <= maxBytes)
+	value, err := dc.Load(keys[nDocs-4])
+	assert.Nil(t, err)
+	assert.Nil(t, value)
+	value, err = dc.Load(keys[0])
+	assert.Nil(t, err)
+	assert.Equal(t, values[0], value)
+}
+
+func TestDocumentCache_Sweep(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	kvdb, cleanup, err := db.NewTempDirRocksDB()
+	defer cleanup()
+	defer kvdb.Close()
+	assert.Nil(t, err)
+	dc, err := LoadDocumentCache(kvdb, 1<<30, 1024)
+	assert.Nil(t, err)
+
+	value1, key1 := api.NewTestDocument(rng)
+	assert.Nil(t, dc.Cache(key1, value1, time.Now().Add(-time.Second)))
+	value2, key2 := api.NewTestDocument(rng)
+	assert.Nil(t, dc.Cache(key2, value2, time.Now().Add(time.Hour)))
+
+	nSwept, err := dc.Sweep()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, nSwept)
+	cachedBytes, err := kvdb.Get(append(Cache, key1.Bytes()...))
+	assert.Nil(t, err)
+	assert.Nil(t, cachedBytes)
+	value, err := dc.Load(key2)
+	assert.Nil(t, err)
+	assert.Equal(t, value2, value)
+
+	// swept copies are gone from the saved index too
+	dc, err = LoadDocumentCache(kvdb, 1<<30, 1024)
+	assert.Nil(t, err)
+	assert.Len(t, dc.(*documentCache).index, 1)
+	nSwept, err = dc.Sweep()
+	assert.Nil(t, err)
+	assert.Zero(t, nSwept)
+}
+
+func TestDocumentCache_Load_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	key := id.NewPseudoRandom(rng)
+
+	// load error
+	dc := &documentCache{
+		sld: &fixedNamespaceSLD{loadErr: errors.New("some load error")},
+		c:   NewHashKeyValueChecker(),
+	}
+	_, err := dc.Load(key)
+	assert.NotNil(t, err)
+
+	// bad cached bytes
+	dc.sld = &fixedNamespaceSLD{loadValue: []byte("not a cached document")}
+	_, err = dc.Load(key)
+	assert.NotNil(t, err)
+
+	// key isn't hash of cached value
+	value, _ := api.NewTestDocument(rng)
+	valueBytes, err := proto.Marshal(value)
+	assert.Nil(t, err)
+	cachedBytes, err := proto.Marshal(&CachedDocument{
+		Document: valueBytes,
+		Expiry:   time.Now().Add(time.Hour).Unix(),
+	})
+	assert.Nil(t, err)
+	dc.sld = &fixedNamespaceSLD{loadValue: cachedBytes}
+	_, err = dc.Load(key)
+	assert.NotNil(t, err)
+}
+
 type fixedNamespaceSLD struct {
 	loadValue []byte
 	storeErr  error
@@ -14,6 +14,12 @@ It has these top-level messages:
 	QueryTypeOutcomes
 	Peer
 	RoutingTable
+	RoutingBucket
+	BlacklistedPeer
+	Blacklist
+	CachedDocument
+	CachedDocumentRef
+	CachedDocumentIndex
 */
 package storage
 
@@ -62,6 +68,8 @@ func (m *Address) GetPort() uint32 {
 type QueryOutcomes struct {
 	Requests  *QueryTypeOutcomes `protobuf:"bytes,1,opt,name=requests" json:"requests,omitempty"`
 	Responses *QueryTypeOutcomes `protobuf:"bytes,2,opt,name=responses" json:"responses,omitempty"`
+	// epoch time (seconds since 1970 UTC) of the latest successful query to or from the peer
+	LastSeen int64 `protobuf:"varint,3,opt,name=last_seen,json=lastSeen" json:"last_seen,omitempty"`
 }
 
 func (m *QueryOutcomes) Reset()                    { *m = QueryOutcomes{} }
@@ -83,6 +91,13 @@ func (m *QueryOutcomes) GetResponses() *QueryTypeOutcomes {
 	return nil
 }
 
+func (m *QueryOutcomes) GetLastSeen() int64 {
+	if m != nil {
+		return m.LastSeen
+	}
+	return 0
+}
+
 // Responses contains statistics about a Peer's query history.
 type QueryTypeOutcomes struct {
 	// epoch time (seconds since 1970 UTC) of the earliest response from the peer
@@ -93,6 +108,10 @@ type QueryTypeOutcomes struct {
 	NQueries uint64 `protobuf:"varint,3,opt,name=n_queries,json=nQueries" json:"n_queries,omitempty"`
 	// number of queries that errored
 	NErrors uint64 `protobuf:"varint,4,opt,name=n_errors,json=nErrors" json:"n_errors,omitempty"`
+	// exponentially-weighted moving average of query round-trip latency (nanoseconds)
+	Latency int64 `protobuf:"varint,5,opt,name=latency" json:"latency,omitempty"`
+	// exponentially-weighted moving average of the fraction of queries that errored
+	ErrorRate float64 `protobuf:"fixed64,6,opt,name=error_rate,json=errorRate" json:"error_rate,omitempty"`
 }
 
 func (m *QueryTypeOutcomes) Reset()                    { *m = QueryTypeOutcomes{} }
@@ -128,6 +147,20 @@ func (m *QueryTypeOutcomes) GetNErrors() uint64 {
 	return 0
 }
 
+func (m *QueryTypeOutcomes) GetLatency() int64 {
+	if m != nil {
+		return m.Latency
+	}
+	return 0
+}
+
+func (m *QueryTypeOutcomes) GetErrorRate() float64 {
+	if m != nil {
+		return m.ErrorRate
+	}
+	return 0
+}
+
 // Peer is the basic information associated with each peer in the network.
 type Peer struct {
 	// big-endian byte representation of 32-byte ID
@@ -177,8 +210,12 @@ func (m *Peer) GetQueryOutcomes() *QueryOutcomes {
 type RoutingTable struct {
 	// big-endian byte representation of 32-byte self ID
 	SelfId []byte `protobuf:"bytes,1,opt,name=self_id,json=selfId,proto3" json:"self_id,omitempty"`
-	// array of peers in table
+	// array of peers in table, only used by version 0
 	Peers []*Peer `protobuf:"bytes,2,rep,name=peers" json:"peers,omitempty"`
+	// version of the stored format: 0 stores a flat list of peers, 1 stores them by bucket
+	Version uint32 `protobuf:"varint,3,opt,name=version" json:"version,omitempty"`
+	// buckets of peers, ordered by lower bound
+	Buckets []*RoutingBucket `protobuf:"bytes,4,rep,name=buckets" json:"buckets,omitempty"`
 }
 
 func (m *RoutingTable) Reset()                    { *m = RoutingTable{} }
@@ -200,39 +237,256 @@ func (m *RoutingTable) GetPeers() []*Peer {
 	return nil
 }
 
+func (m *RoutingTable) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *RoutingTable) GetBuckets() []*RoutingBucket {
+	if m != nil {
+		return m.Buckets
+	}
+	return nil
+}
+
+// RoutingBucket contains the peers and state of a single routing table bucket.
+type RoutingBucket struct {
+	// big-endian byte representation of 32-byte (inclusive) lower bound of IDs in the bucket
+	LowerBound []byte `protobuf:"bytes,1,opt,name=lower_bound,json=lowerBound,proto3" json:"lower_bound,omitempty"`
+	// bit depth of the bucket in the routing table/tree
+	Depth uint32 `protobuf:"varint,2,opt,name=depth" json:"depth,omitempty"`
+	// active peers in the bucket
+	Peers []*Peer `protobuf:"bytes,3,rep,name=peers" json:"peers,omitempty"`
+	// replacement cache peers, ordered from least to most recently seen
+	Replacements []*Peer `protobuf:"bytes,4,rep,name=replacements" json:"replacements,omitempty"`
+	// epoch time (seconds since 1970 UTC) of the latest lookup targeting the bucket
+	Touched int64 `protobuf:"varint,5,opt,name=touched" json:"touched,omitempty"`
+}
+
+func (m *RoutingBucket) Reset()                    { *m = RoutingBucket{} }
+func (m *RoutingBucket) String() string            { return proto.CompactTextString(m) }
+func (*RoutingBucket) ProtoMessage()               {}
+func (*RoutingBucket) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *RoutingBucket) GetLowerBound() []byte {
+	if m != nil {
+		return m.LowerBound
+	}
+	return nil
+}
+
+func (m *RoutingBucket) GetDepth() uint32 {
+	if m != nil {
+		return m.Depth
+	}
+	return 0
+}
+
+func (m *RoutingBucket) GetPeers() []*Peer {
+	if m != nil {
+		return m.Peers
+	}
+	return nil
+}
+
+func (m *RoutingBucket) GetReplacements() []*Peer {
+	if m != nil {
+		return m.Replacements
+	}
+	return nil
+}
+
+func (m *RoutingBucket) GetTouched() int64 {
+	if m != nil {
+		return m.Touched
+	}
+	return 0
+}
+
+// BlacklistedPeer is a peer excluded from the routing table for misbehavior.
+type BlacklistedPeer struct {
+	// big-endian byte representation of 32-byte ID
+	Id []byte `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// epoch time (seconds since 1970 UTC) when the peer is no longer blacklisted
+	Until int64 `protobuf:"varint,2,opt,name=until" json:"until,omitempty"`
+}
+
+func (m *BlacklistedPeer) Reset()                    { *m = BlacklistedPeer{} }
+func (m *BlacklistedPeer) String() string            { return proto.CompactTextString(m) }
+func (*BlacklistedPeer) ProtoMessage()               {}
+func (*BlacklistedPeer) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *BlacklistedPeer) GetId() []byte {
+	if m != nil {
+		return m.Id
+	}
+	return nil
+}
+
+func (m *BlacklistedPeer) GetUntil() int64 {
+	if m != nil {
+		return m.Until
+	}
+	return 0
+}
+
+// Blacklist contains the peers currently blacklisted for misbehavior.
+type Blacklist struct {
+	Peers []*BlacklistedPeer `protobuf:"bytes,1,rep,name=peers" json:"peers,omitempty"`
+}
+
+func (m *Blacklist) Reset()                    { *m = Blacklist{} }
+func (m *Blacklist) String() string            { return proto.CompactTextString(m) }
+func (*Blacklist) ProtoMessage()               {}
+func (*Blacklist) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *Blacklist) GetPeers() []*BlacklistedPeer {
+	if m != nil {
+		return m.Peers
+	}
+	return nil
+}
+
+// CachedDocument is a cache copy of a document, kept separately from primary replicas until it
+// expires.
+type CachedDocument struct {
+	// serialized api.Document
+	Document []byte `protobuf:"bytes,1,opt,name=document,proto3" json:"document,omitempty"`
+	// epoch time (seconds since 1970 UTC) when the cache copy expires
+	Expiry int64 `protobuf:"varint,2,opt,name=expiry" json:"expiry,omitempty"`
+}
+
+func (m *CachedDocument) Reset()                    { *m = CachedDocument{} }
+func (m *CachedDocument) String() string            { return proto.CompactTextString(m) }
+func (*CachedDocument) ProtoMessage()               {}
+func (*CachedDocument) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *CachedDocument) GetDocument() []byte {
+	if m != nil {
+		return m.Document
+	}
+	return nil
+}
+
+func (m *CachedDocument) GetExpiry() int64 {
+	if m != nil {
+		return m.Expiry
+	}
+	return 0
+}
+
+// CachedDocumentRef refers to a cache copy of a document, so expired and excess copies can be
+// found and deleted.
+type CachedDocumentRef struct {
+	// big-endian byte representation of the 32-byte document key
+	Key []byte `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// epoch time (seconds since 1970 UTC) when the cache copy expires
+	Expiry int64 `protobuf:"varint,2,opt,name=expiry" json:"expiry,omitempty"`
+	// number of bytes the cache copy takes in storage
+	Size uint64 `protobuf:"varint,3,opt,name=size" json:"size,omitempty"`
+}
+
+func (m *CachedDocumentRef) Reset()                    { *m = CachedDocumentRef{} }
+func (m *CachedDocumentRef) String() string            { return proto.CompactTextString(m) }
+func (*CachedDocumentRef) ProtoMessage()               {}
+func (*CachedDocumentRef) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *CachedDocumentRef) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *CachedDocumentRef) GetExpiry() int64 {
+	if m != nil {
+		return m.Expiry
+	}
+	return 0
+}
+
+func (m *CachedDocumentRef) GetSize() uint64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+// CachedDocumentIndex lists the cache copies of documents from oldest to newest.
+type CachedDocumentIndex struct {
+	Documents []*CachedDocumentRef `protobuf:"bytes,1,rep,name=documents" json:"documents,omitempty"`
+}
+
+func (m *CachedDocumentIndex) Reset()                    { *m = CachedDocumentIndex{} }
+func (m *CachedDocumentIndex) String() string            { return proto.CompactTextString(m) }
+func (*CachedDocumentIndex) ProtoMessage()               {}
+func (*CachedDocumentIndex) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *CachedDocumentIndex) GetDocuments() []*CachedDocumentRef {
+	if m != nil {
+		return m.Documents
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*Address)(nil), "storage.Address")
 	proto.RegisterType((*QueryOutcomes)(nil), "storage.QueryOutcomes")
 	proto.RegisterType((*QueryTypeOutcomes)(nil), "storage.QueryTypeOutcomes")
 	proto.RegisterType((*Peer)(nil), "storage.Peer")
 	proto.RegisterType((*RoutingTable)(nil), "storage.RoutingTable")
+	proto.RegisterType((*RoutingBucket)(nil), "storage.RoutingBucket")
+	proto.RegisterType((*BlacklistedPeer)(nil), "storage.BlacklistedPeer")
+	proto.RegisterType((*Blacklist)(nil), "storage.Blacklist")
+	proto.RegisterType((*CachedDocument)(nil), "storage.CachedDocument")
+	proto.RegisterType((*CachedDocumentRef)(nil), "storage.CachedDocumentRef")
+	proto.RegisterType((*CachedDocumentIndex)(nil), "storage.CachedDocumentIndex")
 }
 
 func init() { proto.RegisterFile("libri/common/storage/storage.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 367 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x7c, 0x52, 0x3f, 0x8f, 0x9b, 0x30,
-	0x1c, 0x15, 0x84, 0x06, 0xf2, 0x23, 0x44, 0xad, 0x87, 0x94, 0xa6, 0x4b, 0x44, 0x17, 0x96, 0x26,
-	0x12, 0x95, 0xda, 0x2e, 0x1d, 0x3a, 0x74, 0xa8, 0x54, 0xa9, 0x17, 0x2b, 0x3b, 0x82, 0xf0, 0xbb,
-	0xc8, 0x12, 0xd8, 0x8e, 0x6d, 0x86, 0x4c, 0xa7, 0xfb, 0x2a, 0xf7, 0x49, 0x4f, 0x98, 0x3f, 0x77,
-	0xa7, 0x93, 0x6e, 0xc2, 0x4f, 0xef, 0xc1, 0xfb, 0x63, 0x20, 0xa9, 0x59, 0xa9, 0xd8, 0xfe, 0x24,
-	0x9a, 0x46, 0xf0, 0xbd, 0x36, 0x42, 0x15, 0x67, 0x1c, 0x9f, 0x3b, 0xa9, 0x84, 0x11, 0xc4, 0x1f,
-	0x60, 0xf2, 0x15, 0xfc, 0xdf, 0x55, 0xa5, 0x50, 0x6b, 0xb2, 0x02, 0x97, 0xc9, 0xd8, 0xdd, 0x3a,
-	0xe9, 0x82, 0xba, 0x4c, 0x12, 0x02, 0x9e, 0x14, 0xca, 0xc4, 0xb3, 0xad, 0x93, 0x46, 0xd4, 0x9e,
-	0x93, 0x7b, 0x07, 0xa2, 0x43, 0x8b, 0xea, 0xfa, 0xbf, 0x35, 0x27, 0xd1, 0xa0, 0x26, 0xdf, 0x21,
-	0x50, 0x78, 0x69, 0x51, 0x1b, 0x1d, 0x3b, 0x5b, 0x27, 0x0d, 0xb3, 0xcd, 0x6e, 0xf4, 0xb2, 0xca,
-	0xe3, 0x55, 0xe2, 0xa8, 0xa6, 0x93, 0x96, 0xfc, 0x84, 0x85, 0x42, 0x2d, 0x05, 0xd7, 0xa8, 0xad,
-	0xe9, 0xdb, 0x2f, 0x3e, 0x89, 0x93, 0x3b, 0xf8, 0xf0, 0x8a, 0x27, 0x1b, 0x08, 0xb0, 0x50, 0x35,
-	0x43, 0x6d, 0x6c, 0x8c, 0x19, 0x9d, 0x30, 0x59, 0xc3, 0xbc, 0x2e, 0x4c, 0xc7, 0xb8, 0x96, 0x19,
-	0x10, 0xf9, 0x0c, 0x0b, 0x9e, 0x5f, 0x5a, 0x54, 0x0c, 0xb5, 0x6d, 0xe9, 0xd1, 0x80, 0x1f, 0x7a,
-	0x4c, 0x3e, 0x41, 0xc0, 0x73, 0x54, 0x4a, 0x28, 0x1d, 0x7b, 0x96, 0xf3, 0xf9, 0x1f, 0x0b, 0x93,
-	0x07, 0x07, 0xbc, 0x1b, 0x44, 0x65, 0x17, 0xab, 0xac, 0xdd, 0x92, 0xba, 0xac, 0xea, 0x16, 0xe3,
-	0x45, 0x83, 0xc3, 0x86, 0xf6, 0x4c, 0x7e, 0xc0, 0x4a, 0xb6, 0x65, 0xcd, 0x4e, 0x79, 0xd1, 0xef,
-	0x6c, 0x9d, 0xc2, 0xec, 0xfd, 0x54, 0x76, 0xd8, 0x9f, 0x46, 0xbd, 0x6e, 0xbc, 0x8e, 0x5f, 0xb0,
-	0xea, 0xb2, 0x5d, 0x73, 0x31, 0x74, 0xb4, 0x31, 0xc2, 0x6c, 0xfd, 0x72, 0xa5, 0x69, 0xa1, 0xe8,
-	0xf2, 0x1c, 0x26, 0xff, 0x60, 0x49, 0x45, 0x6b, 0x18, 0x3f, 0x1f, 0x8b, 0xb2, 0x46, 0xf2, 0x11,
-	0x7c, 0x8d, 0xf5, 0x6d, 0x3e, 0x05, 0x9e, 0x77, 0xf0, 0x6f, 0x45, 0xbe, 0xc0, 0x3b, 0x89, 0xa8,
-	0xba, 0x4b, 0x98, 0xa5, 0x61, 0x16, 0x4d, 0x9f, 0xef, 0x2a, 0xd2, 0x9e, 0x2b, 0xe7, 0xf6, 0xb7,
-	0xf9, 0xf6, 0x18, 0x00, 0x00, 0xff, 0xff, 0x62, 0x73, 0x70, 0xa4, 0x5c, 0x02, 0x00, 0x00,
+	// 636 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x54, 0xcd, 0x6e, 0xd4, 0x30,
+	0x10, 0x56, 0x36, 0xfb, 0x3b, 0xdb, 0x2c, 0xad, 0xa9, 0x4a, 0x28, 0x42, 0xac, 0xc2, 0x65, 0x2f,
+	0xb4, 0xb0, 0x48, 0x14, 0x09, 0x71, 0xa0, 0x94, 0x43, 0x4f, 0xa5, 0xa6, 0xf7, 0x28, 0x9b, 0x4c,
+	0x5b, 0xab, 0x59, 0x3b, 0xb5, 0x1d, 0xe8, 0xf2, 0x24, 0x9c, 0x38, 0xf0, 0x14, 0x3c, 0x1e, 0xb2,
+	0xe3, 0x64, 0x49, 0xcb, 0xcf, 0x29, 0xfe, 0x3c, 0xdf, 0xcc, 0x7c, 0xf3, 0xe3, 0x40, 0x94, 0xb3,
+	0x85, 0x64, 0xfb, 0xa9, 0x58, 0x2e, 0x05, 0xdf, 0x57, 0x5a, 0xc8, 0xe4, 0x02, 0xeb, 0xef, 0x5e,
+	0x21, 0x85, 0x16, 0x64, 0xe0, 0x60, 0xf4, 0x0c, 0x06, 0xef, 0xb2, 0x4c, 0xa2, 0x52, 0x64, 0x02,
+	0x1d, 0x56, 0x84, 0x9d, 0xa9, 0x37, 0x1b, 0xd1, 0x0e, 0x2b, 0x08, 0x81, 0x6e, 0x21, 0xa4, 0x0e,
+	0xfd, 0xa9, 0x37, 0x0b, 0xa8, 0x3d, 0x47, 0xdf, 0x3d, 0x08, 0x4e, 0x4b, 0x94, 0xab, 0x93, 0x52,
+	0xa7, 0x62, 0x89, 0x8a, 0xbc, 0x82, 0xa1, 0xc4, 0xeb, 0x12, 0x95, 0x56, 0xa1, 0x37, 0xf5, 0x66,
+	0xe3, 0xf9, 0xee, 0x5e, 0x9d, 0xcb, 0x32, 0xcf, 0x56, 0x05, 0xd6, 0x6c, 0xda, 0x70, 0xc9, 0x6b,
+	0x18, 0x49, 0x54, 0x85, 0xe0, 0x0a, 0x55, 0xd8, 0xf9, 0xaf, 0xe3, 0x9a, 0x4c, 0x1e, 0xc1, 0x28,
+	0x4f, 0x94, 0x8e, 0x15, 0x22, 0xb7, 0xe2, 0x7c, 0x3a, 0x34, 0x17, 0x9f, 0x10, 0x79, 0xf4, 0xd3,
+	0x83, 0xad, 0x3b, 0xde, 0x64, 0x17, 0x86, 0x98, 0xc8, 0x9c, 0xa1, 0xd2, 0x56, 0xa4, 0x4f, 0x1b,
+	0x4c, 0x76, 0xa0, 0x9f, 0x27, 0xda, 0x58, 0x3a, 0xd6, 0xe2, 0x90, 0x49, 0xc3, 0xe3, 0xeb, 0x12,
+	0x25, 0x43, 0x65, 0xd3, 0x74, 0xe9, 0x90, 0x9f, 0x56, 0x98, 0x3c, 0x84, 0x21, 0x8f, 0x51, 0x4a,
+	0x21, 0x55, 0xd8, 0xb5, 0xb6, 0x01, 0xff, 0x60, 0x21, 0x09, 0x61, 0x60, 0x22, 0xf0, 0x74, 0x15,
+	0xf6, 0x6c, 0xc0, 0x1a, 0x92, 0xc7, 0x00, 0xd6, 0x25, 0x96, 0x89, 0xc6, 0xb0, 0x3f, 0xf5, 0x66,
+	0x1e, 0x1d, 0xd9, 0x1b, 0x9a, 0x68, 0x8c, 0x7e, 0x78, 0xd0, 0xfd, 0x88, 0x28, 0xed, 0x20, 0x32,
+	0xab, 0x73, 0x83, 0x76, 0x58, 0x66, 0x06, 0xc1, 0x93, 0x25, 0xba, 0xd1, 0xd8, 0x33, 0x39, 0x80,
+	0x49, 0x51, 0x2e, 0x72, 0x96, 0xc6, 0x49, 0x35, 0x3e, 0x2b, 0x71, 0x3c, 0xdf, 0x6c, 0x7a, 0xe8,
+	0xc6, 0x4a, 0x83, 0x8a, 0xe7, 0x20, 0x79, 0x0b, 0x13, 0x53, 0xd4, 0x2a, 0x16, 0xae, 0x39, 0x56,
+	0xff, 0x78, 0xbe, 0xd3, 0x6e, 0x7e, 0xd3, 0xf8, 0xe0, 0xfa, 0x77, 0x18, 0x7d, 0xf3, 0x60, 0x83,
+	0x8a, 0x52, 0x33, 0x7e, 0x71, 0x96, 0x2c, 0x72, 0x24, 0x0f, 0x60, 0xa0, 0x30, 0x3f, 0x8f, 0x1b,
+	0xc5, 0x7d, 0x03, 0x8f, 0x33, 0xf2, 0x14, 0x7a, 0x05, 0xa2, 0x34, 0xc3, 0xf5, 0x67, 0xe3, 0x79,
+	0xd0, 0xc4, 0x37, 0x35, 0xd2, 0xca, 0x66, 0x9a, 0xf5, 0x19, 0xa5, 0x62, 0x82, 0xbb, 0x35, 0xab,
+	0x21, 0x79, 0x0e, 0x83, 0x45, 0x99, 0x5e, 0xa1, 0x36, 0x02, 0xfd, 0x96, 0x40, 0x97, 0xff, 0xd0,
+	0x9a, 0x69, 0x4d, 0x33, 0xa3, 0x0f, 0x5a, 0x26, 0xf2, 0x04, 0xc6, 0xb9, 0xf8, 0x82, 0x32, 0x5e,
+	0x88, 0x92, 0xd7, 0xfa, 0xc0, 0x5e, 0x1d, 0x9a, 0x1b, 0xb2, 0x0d, 0xbd, 0x0c, 0x0b, 0x7d, 0x69,
+	0x5b, 0x1b, 0xd0, 0x0a, 0xac, 0x95, 0xfb, 0xff, 0x50, 0xfe, 0x02, 0x36, 0x24, 0x16, 0x79, 0x92,
+	0xe2, 0x12, 0x79, 0x23, 0xf2, 0x16, 0xb7, 0x45, 0x31, 0xc5, 0x6a, 0x51, 0xa6, 0x97, 0x98, 0xd5,
+	0x9b, 0xe1, 0x60, 0x74, 0x00, 0xf7, 0x0e, 0xf3, 0x24, 0xbd, 0xca, 0x99, 0xd2, 0x98, 0xfd, 0x71,
+	0x09, 0xb6, 0xa1, 0x57, 0x72, 0xcd, 0x72, 0xb7, 0xa5, 0x15, 0x88, 0xde, 0xc0, 0xa8, 0x71, 0x24,
+	0x7b, 0xb5, 0x6e, 0xcf, 0x6a, 0x09, 0x1b, 0x2d, 0xb7, 0x62, 0xbb, 0x12, 0xa2, 0x23, 0x98, 0xbc,
+	0x4f, 0x4c, 0xfe, 0x23, 0x91, 0x96, 0x46, 0xa2, 0x79, 0x27, 0x99, 0x3b, 0xbb, 0xd4, 0x0d, 0x36,
+	0xef, 0x04, 0x6f, 0x0a, 0x26, 0x57, 0xf5, 0x3b, 0xa9, 0x50, 0x74, 0x0a, 0x5b, 0xed, 0x28, 0x14,
+	0xcf, 0xc9, 0x26, 0xf8, 0x57, 0xb8, 0x72, 0x31, 0xcc, 0xf1, 0x6f, 0xee, 0x66, 0xb9, 0x15, 0xfb,
+	0x8a, 0xee, 0x85, 0xd9, 0x73, 0x74, 0x02, 0xf7, 0xdb, 0x21, 0x8f, 0x79, 0x86, 0x37, 0xe6, 0x97,
+	0x51, 0xab, 0xa9, 0x6b, 0x5c, 0xff, 0x32, 0xee, 0x68, 0xa0, 0x6b, 0xf2, 0xa2, 0x6f, 0xff, 0x7a,
+	0x2f, 0x7f, 0x0d, 0x00, 0x70, 0x9b, 0xdb, 0xe6, 0x1b, 0x05, 0x00, 0x00,
 }
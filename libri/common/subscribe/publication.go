@@ -104,8 +104,9 @@ type pubValueReceipt struct {
 	receipt *PubReceipt
 }
 
-func newPublicationValueReceipt(key []byte, value *api.Publication, fromPub []byte) (
-	*pubValueReceipt, error) {
+func newPublicationValueReceipt(
+	key []byte, value *api.Publication, fromPub []byte, fromKeyType api.KeyType,
+) (*pubValueReceipt, error) {
 
 	valueKey, err := api.GetKey(value)
 	if err != nil {
@@ -114,7 +115,7 @@ func newPublicationValueReceipt(key []byte, value *api.Publication, fromPub []by
 	if !bytes.Equal(valueKey.Bytes(), key) {
 		return nil, api.ErrUnexpectedKey
 	}
-	if err := api.ValidatePublicKey(fromPub); err != nil {
+	if err := api.ValidatePeerPublicKey(fromKeyType, fromPub); err != nil {
 		return nil, err
 	}
 	return &pubValueReceipt{
@@ -39,31 +39,31 @@ func TestRecentPublications_Add(t *testing.T) {
 	assert.Nil(t, err)
 
 	// value1 shouldn't be in cache
-	pvr1, err := newPublicationValueReceipt(key1.Bytes(), value1, fromPub1)
+	pvr1, err := newPublicationValueReceipt(key1.Bytes(), value1, fromPub1, api.KeyType_SECP256K1)
 	assert.Nil(t, err)
 	in := rp.Add(pvr1)
 	assert.False(t, in)
 
 	// value1 now should be in cache
-	pvr2, err := newPublicationValueReceipt(key1.Bytes(), value1, fromPub2)
+	pvr2, err := newPublicationValueReceipt(key1.Bytes(), value1, fromPub2, api.KeyType_SECP256K1)
 	assert.Nil(t, err)
 	in = rp.Add(pvr2)
 	assert.True(t, in)
 
 	// value 2 shouldn't be in cache
-	pvr3, err := newPublicationValueReceipt(key2.Bytes(), value2, fromPub1)
+	pvr3, err := newPublicationValueReceipt(key2.Bytes(), value2, fromPub1, api.KeyType_SECP256K1)
 	assert.Nil(t, err)
 	in = rp.Add(pvr3)
 	assert.False(t, in)
 
 	// value 3 shouldn't be in cache
-	pvr4, err := newPublicationValueReceipt(key3.Bytes(), value3, fromPub2)
+	pvr4, err := newPublicationValueReceipt(key3.Bytes(), value3, fromPub2, api.KeyType_SECP256K1)
 	assert.Nil(t, err)
 	in = rp.Add(pvr4)
 	assert.False(t, in)
 
 	// value1 should have been ejected on value3 add and so shouldn't currently be in cache
-	pvr5, err := newPublicationValueReceipt(key1.Bytes(), value1, fromPub3)
+	pvr5, err := newPublicationValueReceipt(key1.Bytes(), value1, fromPub3, api.KeyType_SECP256K1)
 	assert.Nil(t, err)
 	in = rp.Add(pvr5)
 	assert.False(t, in)
@@ -84,7 +84,7 @@ func TestRecentPublications_Get(t *testing.T) {
 	assert.Nil(t, prs)
 
 	// add value
-	pvr1, err := newPublicationValueReceipt(key.Bytes(), value, fromPub)
+	pvr1, err := newPublicationValueReceipt(key.Bytes(), value, fromPub, api.KeyType_SECP256K1)
 	assert.Nil(t, err)
 	in = rp.Add(pvr1)
 	assert.False(t, in)
@@ -109,19 +109,19 @@ func TestRecentPublications_Len(t *testing.T) {
 	assert.Nil(t, err)
 
 	// check adding receipt for new value increments length
-	pvr1, err := newPublicationValueReceipt(key1.Bytes(), value1, fromPub1)
+	pvr1, err := newPublicationValueReceipt(key1.Bytes(), value1, fromPub1, api.KeyType_SECP256K1)
 	assert.Nil(t, err)
 	rp.Add(pvr1)
 	assert.Equal(t, 1, rp.Len())
 
 	// check adding receipt for existing value does not increment length
-	pvr2, err := newPublicationValueReceipt(key1.Bytes(), value1, fromPub2)
+	pvr2, err := newPublicationValueReceipt(key1.Bytes(), value1, fromPub2, api.KeyType_SECP256K1)
 	assert.Nil(t, err)
 	rp.Add(pvr2)
 	assert.Equal(t, 1, rp.Len())
 
 	// check adding receipt for new value increments length
-	pvr3, err := newPublicationValueReceipt(key2.Bytes(), value2, fromPub1)
+	pvr3, err := newPublicationValueReceipt(key2.Bytes(), value2, fromPub1, api.KeyType_SECP256K1)
 	assert.Nil(t, err)
 	rp.Add(pvr3)
 	assert.Equal(t, 2, rp.Len())
@@ -134,7 +134,7 @@ func TestNewPublicationValueReceipt_ok(t *testing.T) {
 	assert.Nil(t, err)
 	fromPub := api.RandBytes(rng, api.ECPubKeyLength)
 
-	pvr, err := newPublicationValueReceipt(key.Bytes(), value, fromPub)
+	pvr, err := newPublicationValueReceipt(key.Bytes(), value, fromPub, api.KeyType_SECP256K1)
 	assert.Nil(t, err)
 	assert.Equal(t, value, pvr.pub.Value)
 	assert.Equal(t, key, pvr.pub.Key)
@@ -150,17 +150,18 @@ func TestNewPublicationValueReceipt_err(t *testing.T) {
 	fromPub := api.RandBytes(rng, api.ECPubKeyLength)
 
 	// check GetKey error bubbles up
-	pvr, err := newPublicationValueReceipt(nil, value, fromPub)
+	pvr, err := newPublicationValueReceipt(nil, value, fromPub, api.KeyType_SECP256K1)
 	assert.NotNil(t, err)
 	assert.Nil(t, pvr)
 
 	// check bad key throws error
-	pvr, err = newPublicationValueReceipt(api.RandBytes(rng, id.Length), value, fromPub)
+	pvr, err = newPublicationValueReceipt(api.RandBytes(rng, id.Length), value, fromPub,
+		api.KeyType_SECP256K1)
 	assert.Equal(t, api.ErrUnexpectedKey, err)
 	assert.Nil(t, pvr)
 
 	// check bad fromPub throws error
-	pvr, err = newPublicationValueReceipt(key.Bytes(), value, nil)
+	pvr, err = newPublicationValueReceipt(key.Bytes(), value, nil, api.KeyType_SECP256K1)
 	assert.NotNil(t, err)
 	assert.Nil(t, pvr)
 }
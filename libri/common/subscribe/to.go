@@ -112,7 +112,7 @@ type To interface {
 type to struct {
 	params   *ToParameters
 	logger   *zap.Logger
-	clientID ecid.ID
+	clientID ecid.Identity
 	csb      client.SetBalancer
 	sb       subscriptionBeginner
 	recent   RecentPublications
@@ -126,7 +126,7 @@ type to struct {
 func NewTo(
 	params *ToParameters,
 	logger *zap.Logger,
-	clientID ecid.ID,
+	clientID ecid.Identity,
 	csb client.SetBalancer,
 	signer client.Signer,
 	recent RecentPublications,
@@ -238,7 +238,8 @@ func (t *to) Send(pub *api.Publication) error {
 		zap.String("publication_key", key.String()),
 	)
 	t.logger.Debug("publication value", getLoggerValues(pub)...)
-	pvr, err := newPublicationValueReceipt(key.Bytes(), pub, t.clientID.PublicKeyBytes())
+	pvr, err := newPublicationValueReceipt(key.Bytes(), pub, t.clientID.PublicKeyBytes(),
+		api.KeyType(t.clientID.KeyType()))
 	if err != nil {
 		return err
 	}
@@ -285,7 +286,7 @@ type subscriptionBeginner interface {
 }
 
 type subscriptionBeginnerImpl struct {
-	clientID ecid.ID
+	clientID ecid.Identity
 	signer   client.Signer
 	params   *ToParameters
 }
@@ -319,7 +320,8 @@ func (sb *subscriptionBeginnerImpl) begin(
 			// receiving channel has already closed
 			return nil
 		}
-		pvr, err := newPublicationValueReceipt(rp.Key, rp.Value, rp.Metadata.PubKey)
+		pvr, err := newPublicationValueReceipt(rp.Key, rp.Value, rp.Metadata.PubKey,
+			rp.Metadata.KeyType)
 		if err != nil {
 			return err
 		}
@@ -60,7 +60,7 @@ func TestTo_BeginEnd(t *testing.T) {
 	fromPub2 := api.RandBytes(rng, api.ECPubKeyLength)
 
 	// new
-	pvr1, err := newPublicationValueReceipt(key1.Bytes(), value1, fromPub1)
+	pvr1, err := newPublicationValueReceipt(key1.Bytes(), value1, fromPub1, api.KeyType_SECP256K1)
 	assert.Nil(t, err)
 	received <- pvr1
 	errs <- nil
@@ -69,7 +69,7 @@ func TestTo_BeginEnd(t *testing.T) {
 	assert.Equal(t, pvr1.pub, newPub)
 
 	// not new
-	pvr2, err := newPublicationValueReceipt(key1.Bytes(), value1, fromPub2)
+	pvr2, err := newPublicationValueReceipt(key1.Bytes(), value1, fromPub2, api.KeyType_SECP256K1)
 	assert.Nil(t, err)
 	received <- pvr2
 	errs <- nil
@@ -84,7 +84,7 @@ func TestTo_BeginEnd(t *testing.T) {
 	assert.False(t, ended)
 
 	// new
-	pvr3, err := newPublicationValueReceipt(key2.Bytes(), value2, fromPub1)
+	pvr3, err := newPublicationValueReceipt(key2.Bytes(), value2, fromPub1, api.KeyType_SECP256K1)
 	assert.Nil(t, err)
 	received <- pvr3
 	errs <- nil
@@ -93,7 +93,7 @@ func TestTo_BeginEnd(t *testing.T) {
 	assert.False(t, ended)
 
 	// new
-	pvr4, err := newPublicationValueReceipt(key3.Bytes(), value3, fromPub2)
+	pvr4, err := newPublicationValueReceipt(key3.Bytes(), value3, fromPub2, api.KeyType_SECP256K1)
 	assert.Nil(t, err)
 	received <- pvr4
 	errs <- nil
@@ -385,14 +385,14 @@ func TestDedup(t *testing.T) {
 	go toImpl.dedup()
 
 	// new
-	pvr1in, err := newPublicationValueReceipt(key1.Bytes(), value1, fromPub1)
+	pvr1in, err := newPublicationValueReceipt(key1.Bytes(), value1, fromPub1, api.KeyType_SECP256K1)
 	assert.Nil(t, err)
 	receivedPVRs <- pvr1in
 	pv1out := <-newPVRs
 	assert.Equal(t, pvr1in.pub, pv1out)
 
 	// not new
-	pvr2in, err := newPublicationValueReceipt(key1.Bytes(), value1, fromPub2)
+	pvr2in, err := newPublicationValueReceipt(key1.Bytes(), value1, fromPub2, api.KeyType_SECP256K1)
 	assert.Nil(t, err)
 	receivedPVRs <- pvr2in
 	var pv2out *KeyedPub
@@ -403,7 +403,7 @@ func TestDedup(t *testing.T) {
 	assert.Nil(t, pv2out)
 
 	// new
-	pvr3in, err := newPublicationValueReceipt(key2.Bytes(), value2, fromPub1)
+	pvr3in, err := newPublicationValueReceipt(key2.Bytes(), value2, fromPub1, api.KeyType_SECP256K1)
 	assert.Nil(t, err)
 	receivedPVRs <- pvr3in
 	pv3out := <-newPVRs
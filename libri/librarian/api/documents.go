@@ -16,6 +16,9 @@ const (
 	// (uncompressed) to a byte string.
 	ECPubKeyLength = 65
 
+	// Ed25519PubKeyLength is the length of an Ed25519 public key.
+	Ed25519PubKeyLength = 32
+
 	// DocumentKeyLength is the byte length a document's key.
 	DocumentKeyLength = id.Length
 
@@ -47,6 +50,13 @@ const (
 
 	// HMAC256Length is the byte length of an HMAC-256.
 	HMAC256Length = sha256.Size
+
+	// MaxECDSASignatureLength is the max byte length of an ASN.1 DER-encoded 256-bit ECDSA
+	// signature.
+	MaxECDSASignatureLength = 72
+
+	// MaxPointerNameLength is the max byte length of a Pointer name.
+	MaxPointerNameLength = 256
 )
 
 var (
@@ -82,6 +92,8 @@ func GetAuthorPub(d *Document) []byte {
 		return c.Page.AuthorPublicKey
 	case *Document_Envelope:
 		return c.Envelope.AuthorPublicKey
+	case *Document_Pointer:
+		return c.Pointer.AuthorPublicKey
 	}
 	panic(ErrUnknownDocumentType)
 }
@@ -134,6 +146,8 @@ func ValidateDocument(d *Document) error {
 		return ValidateEntry(c.Entry)
 	case *Document_Page:
 		return ValidatePage(c.Page)
+	case *Document_Pointer:
+		return ValidatePointer(c.Pointer)
 	}
 	return ErrUnknownDocumentType
 }
@@ -163,7 +177,7 @@ func ValidateEnvelope(e *Envelope) error {
 }
 
 // ValidateEntry checks that all fields of an Entry are populated and have the expected byte
-// lengths.
+// lengths. If the Entry has an author signature, it also checks that the signature is valid.
 func ValidateEntry(e *Entry) error {
 	if e == nil {
 		return errors.New("Entry may not be nil")
@@ -183,6 +197,15 @@ func ValidateEntry(e *Entry) error {
 	if err := validateEntryContents(e); err != nil {
 		return err
 	}
+	if e.AuthorSignature != nil {
+		// legacy entries without signatures are still valid
+		if err := ValidateSignature(e.AuthorSignature); err != nil {
+			return err
+		}
+		if err := VerifyEntry(e); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -238,11 +261,48 @@ func ValidatePageKeys(pk *PageKeys) error {
 	return nil
 }
 
-// ValidatePublicKey checks that a value can be a 256-bit elliptic curve public key.
+// ValidatePointer checks that all fields of a Pointer are populated and have the expected lengths.
+// It does not verify the signature; see VerifyPointer for that.
+func ValidatePointer(p *Pointer) error {
+	if p == nil {
+		return errors.New("Pointer may not be nil")
+	}
+	if err := ValidatePublicKey(p.AuthorPublicKey); err != nil {
+		return err
+	}
+	if p.Name == "" {
+		return errors.New("Name must be populated")
+	}
+	if len(p.Name) > MaxPointerNameLength {
+		return fmt.Errorf("Name must have length <= %d, found length %d",
+			MaxPointerNameLength, len(p.Name))
+	}
+	if err := ValidateBytes(p.TargetKey, DocumentKeyLength, "TargetKey"); err != nil {
+		return err
+	}
+	if p.Sequence == 0 {
+		return errors.New("Sequence must be populated")
+	}
+	if err := ValidateSignature(p.Signature); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ValidatePublicKey checks that a value can be a 256-bit elliptic curve public key. Author and
+// reader keys in documents are always secp256k1, so they have no key type to dispatch on.
 func ValidatePublicKey(value []byte) error {
 	return ValidateBytes(value, ECPubKeyLength, "PublicKey")
 }
 
+// ValidatePeerPublicKey checks that a value can be a public key of the given peer key type.
+func ValidatePeerPublicKey(keyType KeyType, value []byte) error {
+	if keyType == KeyType_ED25519 {
+		return ValidateBytes(value, Ed25519PubKeyLength, "PublicKey")
+	}
+	return ValidatePublicKey(value)
+}
+
 // ValidateAESKey checks the a value can be a 256-bit AES key.
 func ValidateAESKey(value []byte) error {
 	return ValidateBytes(value, AESKeyLength, "AESKey")
@@ -268,6 +328,18 @@ func ValidateHMAC256(value []byte) error {
 	return ValidateBytes(value, HMAC256Length, "HMAC256")
 }
 
+// ValidateSignature checks that a value can be an ASN.1 DER-encoded ECDSA signature.
+func ValidateSignature(value []byte) error {
+	if err := ValidateNotEmpty(value, "Signature"); err != nil {
+		return err
+	}
+	if len(value) > MaxECDSASignatureLength {
+		return fmt.Errorf("Signature must have length <= %d, found length %d",
+			MaxECDSASignatureLength, len(value))
+	}
+	return nil
+}
+
 // ValidateBytes returns whether the byte slice is not empty and has an expected length.
 func ValidateBytes(value []byte, expectedLen int, name string) error {
 	if err := ValidateNotEmpty(value, name); err != nil {
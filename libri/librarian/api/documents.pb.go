@@ -16,6 +16,7 @@ It has these top-level messages:
 	Metadata
 	PageKeys
 	Page
+	Pointer
 	RequestMetadata
 	ResponseMetadata
 	PingRequest
@@ -54,12 +55,13 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
-// Document contains either an Envelope, Entry, or Page message.
+// Document contains either an Envelope, Entry, Page, or Pointer message.
 type Document struct {
 	// Types that are valid to be assigned to Contents:
 	//	*Document_Envelope
 	//	*Document_Entry
 	//	*Document_Page
+	//	*Document_Pointer
 	Contents isDocument_Contents `protobuf_oneof:"contents"`
 }
 
@@ -81,10 +83,14 @@ type Document_Entry struct {
 type Document_Page struct {
 	Page *Page `protobuf:"bytes,3,opt,name=page,oneof"`
 }
+type Document_Pointer struct {
+	Pointer *Pointer `protobuf:"bytes,4,opt,name=pointer,oneof"`
+}
 
 func (*Document_Envelope) isDocument_Contents() {}
 func (*Document_Entry) isDocument_Contents()    {}
 func (*Document_Page) isDocument_Contents()     {}
+func (*Document_Pointer) isDocument_Contents()  {}
 
 func (m *Document) GetContents() isDocument_Contents {
 	if m != nil {
@@ -114,12 +120,20 @@ func (m *Document) GetPage() *Page {
 	return nil
 }
 
+func (m *Document) GetPointer() *Pointer {
+	if x, ok := m.GetContents().(*Document_Pointer); ok {
+		return x.Pointer
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*Document) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _Document_OneofMarshaler, _Document_OneofUnmarshaler, _Document_OneofSizer, []interface{}{
 		(*Document_Envelope)(nil),
 		(*Document_Entry)(nil),
 		(*Document_Page)(nil),
+		(*Document_Pointer)(nil),
 	}
 }
 
@@ -142,6 +156,11 @@ func _Document_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.Page); err != nil {
 			return err
 		}
+	case *Document_Pointer:
+		b.EncodeVarint(4<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Pointer); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("Document.Contents has unexpected type %T", x)
@@ -176,6 +195,14 @@ func _Document_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffe
 		err := b.DecodeMessage(msg)
 		m.Contents = &Document_Page{msg}
 		return true, err
+	case 4: // contents.pointer
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Pointer)
+		err := b.DecodeMessage(msg)
+		m.Contents = &Document_Pointer{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -200,6 +227,11 @@ func _Document_OneofSizer(msg proto.Message) (n int) {
 		n += proto.SizeVarint(3<<3 | proto.WireBytes)
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *Document_Pointer:
+		s := proto.Size(x.Pointer)
+		n += proto.SizeVarint(4<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -290,6 +322,9 @@ type Entry struct {
 	// 32-byte MAC of metatadata ciphertext, encrypted with the 32-byte Entry AES-256 key and
 	// 12-byte metadata block cipher IV
 	MetadataCiphertextMac []byte `protobuf:"bytes,6,opt,name=metadata_ciphertext_mac,json=metadataCiphertextMac,proto3" json:"metadata_ciphertext_mac,omitempty"`
+	// ASN.1 DER ECDSA signature of the entry (without the signature) by the author private key;
+	// entries created before signatures existed do not have one
+	AuthorSignature []byte `protobuf:"bytes,7,opt,name=author_signature,json=authorSignature,proto3" json:"author_signature,omitempty"`
 }
 
 func (m *Entry) Reset()                    { *m = Entry{} }
@@ -360,6 +395,13 @@ func (m *Entry) GetMetadataCiphertextMac() []byte {
 	return nil
 }
 
+func (m *Entry) GetAuthorSignature() []byte {
+	if m != nil {
+		return m.AuthorSignature
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*Entry) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _Entry_OneofMarshaler, _Entry_OneofUnmarshaler, _Entry_OneofSizer, []interface{}{
@@ -514,6 +556,64 @@ func (m *Page) GetCiphertextMac() []byte {
 	return nil
 }
 
+// Pointer is a mutable, named reference from an author to an Envelope or Entry document. Unlike
+// other documents, its key is not the hash of its contents but is instead derived from the
+// author public key and name, so that newer versions (with larger sequence numbers) can replace
+// older ones under the same key.
+type Pointer struct {
+	// ECDSA public key of the pointer author
+	AuthorPublicKey []byte `protobuf:"bytes,1,opt,name=author_public_key,json=authorPublicKey,proto3" json:"author_public_key,omitempty"`
+	// name of the pointer, unique among the author's pointers
+	Name string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	// 32-byte key of the Envelope or Entry document the pointer targets
+	TargetKey []byte `protobuf:"bytes,3,opt,name=target_key,json=targetKey,proto3" json:"target_key,omitempty"`
+	// sequence number of this version of the pointer, which must be larger than that of any
+	// version it replaces
+	Sequence uint64 `protobuf:"varint,4,opt,name=sequence" json:"sequence,omitempty"`
+	// ASN.1 DER ECDSA signature of the pointer (without the signature) by the author private key
+	Signature []byte `protobuf:"bytes,5,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (m *Pointer) Reset()                    { *m = Pointer{} }
+func (m *Pointer) String() string            { return proto.CompactTextString(m) }
+func (*Pointer) ProtoMessage()               {}
+func (*Pointer) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *Pointer) GetAuthorPublicKey() []byte {
+	if m != nil {
+		return m.AuthorPublicKey
+	}
+	return nil
+}
+
+func (m *Pointer) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Pointer) GetTargetKey() []byte {
+	if m != nil {
+		return m.TargetKey
+	}
+	return nil
+}
+
+func (m *Pointer) GetSequence() uint64 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *Pointer) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*Document)(nil), "api.Document")
 	proto.RegisterType((*Envelope)(nil), "api.Envelope")
@@ -521,41 +621,48 @@ func init() {
 	proto.RegisterType((*Metadata)(nil), "api.Metadata")
 	proto.RegisterType((*PageKeys)(nil), "api.PageKeys")
 	proto.RegisterType((*Page)(nil), "api.Page")
+	proto.RegisterType((*Pointer)(nil), "api.Pointer")
 }
 
 func init() { proto.RegisterFile("libri/librarian/api/documents.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 493 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x94, 0x93, 0xdf, 0x6b, 0xdb, 0x30,
-	0x10, 0xc7, 0x6b, 0x3b, 0x2e, 0xce, 0x25, 0x59, 0x3b, 0xad, 0x63, 0x66, 0x63, 0x5d, 0xe7, 0x31,
-	0x28, 0x5b, 0x49, 0xa0, 0x83, 0x31, 0x06, 0x7d, 0xd9, 0x0f, 0x28, 0x94, 0x40, 0x30, 0x7b, 0x37,
-	0x8a, 0x7c, 0xb4, 0x22, 0xb1, 0x2d, 0x14, 0xa5, 0xd4, 0xff, 0xc1, 0xde, 0xf6, 0xbe, 0xbf, 0x6b,
-	0x7f, 0xd0, 0xd0, 0x59, 0xce, 0x9c, 0x2e, 0x7b, 0xd8, 0x4b, 0x22, 0xdd, 0xf7, 0x73, 0xd2, 0xe9,
-	0x7b, 0x67, 0x78, 0xb5, 0x94, 0x73, 0x2d, 0x27, 0xf6, 0x97, 0x6b, 0xc9, 0xcb, 0x09, 0x57, 0x72,
-	0x92, 0x57, 0x62, 0x5d, 0x60, 0x69, 0x56, 0x63, 0xa5, 0x2b, 0x53, 0xb1, 0x80, 0x2b, 0x99, 0x7c,
-	0xf7, 0x20, 0xfa, 0xe2, 0x04, 0xf6, 0x16, 0x22, 0x2c, 0x6f, 0x71, 0x59, 0x29, 0x8c, 0xbd, 0x13,
-	0xef, 0x74, 0x70, 0x3e, 0x1a, 0x73, 0x25, 0xc7, 0x5f, 0x5d, 0xf0, 0x72, 0x2f, 0xdd, 0x00, 0x2c,
-	0x81, 0x10, 0x4b, 0xa3, 0xeb, 0xd8, 0x27, 0x12, 0x1c, 0x69, 0x74, 0x7d, 0xb9, 0x97, 0x36, 0x12,
-	0x7b, 0x01, 0x3d, 0xc5, 0xaf, 0x31, 0x0e, 0x08, 0xe9, 0x13, 0x32, 0xe3, 0xd7, 0xf6, 0x20, 0x12,
-	0x3e, 0x01, 0x44, 0xa2, 0x2a, 0x8d, 0xad, 0x2a, 0xf9, 0xe5, 0x41, 0xd4, 0xde, 0xc4, 0x9e, 0x41,
-	0x9f, 0x8e, 0xc8, 0x16, 0x58, 0x53, 0x2d, 0x43, 0x7b, 0xb5, 0xd1, 0xf5, 0x15, 0xd6, 0xec, 0x0d,
-	0x3c, 0xe4, 0x6b, 0x73, 0x53, 0xe9, 0x4c, 0xad, 0xe7, 0x4b, 0x29, 0x08, 0xf2, 0x09, 0x3a, 0x68,
-	0x84, 0x19, 0xc5, 0x1d, 0xab, 0x91, 0xe7, 0xb8, 0xc5, 0x06, 0x0d, 0xdb, 0x08, 0x7f, 0xd8, 0xd7,
-	0xf0, 0x00, 0x71, 0x91, 0x09, 0xa9, 0x6e, 0x50, 0x1b, 0xbc, 0x33, 0x71, 0x8f, 0xc0, 0x11, 0xe2,
-	0xe2, 0xf3, 0x26, 0xc8, 0xce, 0x80, 0x6d, 0x63, 0x59, 0xc1, 0x45, 0x1c, 0x12, 0x7a, 0xb8, 0x85,
-	0x4e, 0xb9, 0x48, 0x7e, 0xfa, 0x10, 0x92, 0x2d, 0xbb, 0xcb, 0xf6, 0x76, 0x97, 0xdd, 0x3a, 0xe7,
-	0xff, 0xc3, 0x39, 0x76, 0x06, 0x7d, 0xfb, 0x6f, 0xcf, 0x58, 0x39, 0x7f, 0x47, 0x1b, 0xea, 0x0a,
-	0xeb, 0x95, 0x6d, 0x96, 0x72, 0x6b, 0xf6, 0x12, 0x86, 0x42, 0x23, 0x37, 0x98, 0x67, 0x46, 0x16,
-	0x48, 0xef, 0x0a, 0xd2, 0x81, 0x8b, 0x7d, 0x93, 0x05, 0xb2, 0x09, 0x3c, 0x2a, 0xd0, 0xf0, 0x9c,
-	0x1b, 0xde, 0x75, 0xa0, 0x79, 0x16, 0x6b, 0xa5, 0x8e, 0x0d, 0xef, 0xe1, 0xc9, 0x8e, 0x04, 0xf2,
-	0x62, 0x9f, 0x92, 0x1e, 0xff, 0x9d, 0x34, 0xe5, 0x62, 0xab, 0xe7, 0x76, 0xfc, 0xa6, 0x8e, 0x62,
-	0x17, 0x00, 0x4a, 0x57, 0x0a, 0xb5, 0x91, 0xb8, 0x8a, 0xbd, 0x93, 0xe0, 0x74, 0x70, 0xfe, 0x9c,
-	0xde, 0xd4, 0x22, 0xe3, 0xd9, 0x46, 0x27, 0x4b, 0xd3, 0x4e, 0xc2, 0xd3, 0x0b, 0x38, 0xb8, 0x27,
-	0xb3, 0x43, 0x08, 0x5a, 0x8f, 0xfb, 0xa9, 0x5d, 0xb2, 0x23, 0x08, 0x6f, 0xf9, 0x72, 0x8d, 0x6e,
-	0x5c, 0x9a, 0xcd, 0x47, 0xff, 0x83, 0x97, 0x1c, 0x43, 0xd4, 0x5a, 0xc7, 0x18, 0xf4, 0xc8, 0x57,
-	0x5b, 0xc3, 0x30, 0xa5, 0x75, 0xf2, 0xc3, 0x83, 0x9e, 0x05, 0xfe, 0xab, 0x8d, 0x47, 0x10, 0xca,
-	0x32, 0xc7, 0x3b, 0xba, 0x6e, 0x94, 0x36, 0x1b, 0x76, 0x0c, 0xd0, 0x71, 0xb8, 0x19, 0xc6, 0x4e,
-	0xc4, 0xce, 0xe1, 0x3d, 0x43, 0xdd, 0x1c, 0x8a, 0xae, 0x91, 0xf3, 0x7d, 0xfa, 0x8e, 0xdf, 0xfd,
-	0x0e, 0x00, 0x00, 0xff, 0xff, 0xe8, 0x54, 0x8a, 0x40, 0xee, 0x03, 0x00, 0x00,
+	// 589 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x94, 0xdf, 0x6e, 0xd3, 0x30,
+	0x14, 0xc6, 0x97, 0x26, 0x5d, 0x93, 0xd3, 0x96, 0x15, 0x33, 0x44, 0x34, 0xd8, 0x18, 0x41, 0x48,
+	0x05, 0xa6, 0x56, 0x1a, 0x12, 0x42, 0x48, 0xbb, 0xe1, 0x8f, 0x54, 0x69, 0xaa, 0x54, 0x05, 0xee,
+	0x2b, 0x37, 0x3d, 0xea, 0xac, 0x36, 0x89, 0x71, 0x9c, 0x69, 0x79, 0x03, 0xee, 0x78, 0x07, 0x1e,
+	0x81, 0x77, 0xe0, 0x0d, 0x78, 0x20, 0x64, 0x3b, 0x69, 0xd3, 0x51, 0x2e, 0x76, 0xd3, 0xda, 0xe7,
+	0xfc, 0x8e, 0xf3, 0xf9, 0x3b, 0x27, 0x81, 0xe7, 0x2b, 0x36, 0x13, 0x6c, 0xa8, 0x7e, 0xa9, 0x60,
+	0x34, 0x19, 0x52, 0xce, 0x86, 0xf3, 0x34, 0xca, 0x63, 0x4c, 0x64, 0x36, 0xe0, 0x22, 0x95, 0x29,
+	0xb1, 0x29, 0x67, 0xc1, 0x2f, 0x0b, 0xdc, 0x4f, 0x65, 0x82, 0xbc, 0x06, 0x17, 0x93, 0x6b, 0x5c,
+	0xa5, 0x1c, 0x7d, 0xeb, 0xd4, 0xea, 0xb7, 0xcf, 0xbb, 0x03, 0xca, 0xd9, 0xe0, 0x73, 0x19, 0x1c,
+	0xed, 0x85, 0x6b, 0x80, 0x04, 0xd0, 0xc4, 0x44, 0x8a, 0xc2, 0x6f, 0x68, 0x12, 0x4a, 0x52, 0x8a,
+	0x62, 0xb4, 0x17, 0x9a, 0x14, 0x79, 0x0a, 0x0e, 0xa7, 0x0b, 0xf4, 0x6d, 0x8d, 0x78, 0x1a, 0x99,
+	0xd0, 0x85, 0x3a, 0x48, 0x27, 0x48, 0x1f, 0x5a, 0x3c, 0x65, 0x89, 0x44, 0xe1, 0x3b, 0x9a, 0xe9,
+	0x18, 0xc6, 0xc4, 0x46, 0x7b, 0x61, 0x95, 0xfe, 0x00, 0xe0, 0x46, 0x69, 0x22, 0x95, 0xfe, 0xe0,
+	0x8f, 0x05, 0x6e, 0xa5, 0x89, 0x3c, 0x06, 0x4f, 0x3f, 0x6c, 0xba, 0xc4, 0x42, 0xab, 0xee, 0x28,
+	0x91, 0x52, 0x14, 0x97, 0x58, 0x90, 0x57, 0x70, 0x9f, 0xe6, 0xf2, 0x2a, 0x15, 0x53, 0x9e, 0xcf,
+	0x56, 0x2c, 0xd2, 0x50, 0x43, 0x43, 0x07, 0x26, 0x31, 0xd1, 0xf1, 0x92, 0x15, 0x48, 0xe7, 0xb8,
+	0xc5, 0xda, 0x86, 0x35, 0x89, 0x0d, 0xfb, 0x02, 0xee, 0x21, 0x2e, 0xa7, 0x11, 0xe3, 0x57, 0x28,
+	0x24, 0xde, 0x48, 0x2d, 0xbf, 0x13, 0x76, 0x11, 0x97, 0x1f, 0xd7, 0x41, 0x72, 0x06, 0x64, 0x1b,
+	0x9b, 0xc6, 0x34, 0xf2, 0x9b, 0x1a, 0xed, 0x6d, 0xa1, 0x63, 0x1a, 0x05, 0xbf, 0x1b, 0xd0, 0xd4,
+	0x06, 0xee, 0x96, 0x6d, 0xed, 0x96, 0x5d, 0x79, 0xdc, 0xf8, 0x9f, 0xc7, 0x67, 0xe0, 0xa9, 0x7f,
+	0x75, 0x46, 0xe6, 0xdb, 0xb5, 0xb6, 0x2a, 0xea, 0x12, 0x8b, 0x4c, 0xb5, 0x95, 0x97, 0x6b, 0xf2,
+	0x0c, 0x3a, 0x91, 0x40, 0x2a, 0x71, 0x3e, 0x95, 0x2c, 0x46, 0x7d, 0x2f, 0x3b, 0x6c, 0x97, 0xb1,
+	0xaf, 0x2c, 0x46, 0x32, 0x84, 0x07, 0x31, 0x4a, 0x3a, 0xa7, 0x92, 0xd6, 0x1d, 0x30, 0xd7, 0x22,
+	0x55, 0xaa, 0x66, 0xc3, 0x5b, 0x78, 0xb4, 0xa3, 0x40, 0x7b, 0xb1, 0xaf, 0x8b, 0x1e, 0xfe, 0x5b,
+	0x34, 0xa6, 0x11, 0x79, 0x09, 0xbd, 0xd2, 0x86, 0x8c, 0x2d, 0x12, 0x2a, 0x73, 0x81, 0x7e, 0xab,
+	0xee, 0xc2, 0x97, 0x2a, 0xbc, 0x35, 0x1e, 0xdf, 0x2d, 0x70, 0xc7, 0xe5, 0x81, 0xe4, 0x02, 0x80,
+	0x8b, 0x94, 0xa3, 0x90, 0x0c, 0x33, 0xdf, 0x3a, 0xb5, 0xfb, 0xed, 0xf3, 0x63, 0x7d, 0xfd, 0x0a,
+	0x19, 0x4c, 0xd6, 0x79, 0xed, 0x7e, 0x58, 0x2b, 0x38, 0xba, 0x80, 0x83, 0x5b, 0x69, 0xd2, 0x03,
+	0xbb, 0x6a, 0x87, 0x17, 0xaa, 0x25, 0x39, 0x84, 0xe6, 0x35, 0x5d, 0xe5, 0x58, 0x4e, 0x96, 0xd9,
+	0xbc, 0x6f, 0xbc, 0xb3, 0x82, 0x13, 0x70, 0x2b, 0x97, 0x09, 0x01, 0x47, 0xb7, 0x40, 0x69, 0xe8,
+	0x84, 0x7a, 0x1d, 0xfc, 0xb0, 0xc0, 0x51, 0xc0, 0x9d, 0x3a, 0x7e, 0x08, 0x4d, 0x96, 0xcc, 0xf1,
+	0x46, 0x3f, 0xae, 0x1b, 0x9a, 0x0d, 0x39, 0x01, 0xa8, 0x35, 0xc3, 0xcc, 0x6d, 0x2d, 0xa2, 0x46,
+	0xf6, 0x96, 0xf7, 0xe5, 0xc8, 0x46, 0x5b, 0x43, 0xf8, 0xd3, 0x82, 0x56, 0xf9, 0xfa, 0xdd, 0x49,
+	0x14, 0x01, 0x27, 0xa1, 0xb1, 0xb1, 0xc0, 0x0b, 0xf5, 0x9a, 0x1c, 0x03, 0x48, 0x2a, 0x16, 0x28,
+	0x6b, 0xaf, 0x92, 0x67, 0x22, 0xaa, 0xe4, 0x08, 0xdc, 0x0c, 0xbf, 0xe5, 0x98, 0x44, 0x66, 0xcc,
+	0x9c, 0x70, 0xbd, 0x27, 0x4f, 0xc0, 0xdb, 0xf4, 0xdc, 0x4c, 0xd6, 0x26, 0x30, 0xdb, 0xd7, 0x5f,
+	0xb0, 0x37, 0x7f, 0x07, 0x00, 0xed, 0x37, 0xdd, 0x9d, 0xe8, 0x04, 0x00, 0x00,
 }
@@ -36,6 +36,10 @@ func TestGetAuthorPub(t *testing.T) {
 	envelope := NewTestEnvelope(rng)
 	envelope.AuthorPublicKey = expected
 	assert.Equal(t, expected, GetAuthorPub(&Document{&Document_Envelope{Envelope: envelope}}))
+
+	pointer := NewTestPointer(rng)
+	pointer.AuthorPublicKey = expected
+	assert.Equal(t, expected, GetAuthorPub(&Document{&Document_Pointer{Pointer: pointer}}))
 }
 
 func TestGetEntryPageKeys_ok(t *testing.T) {
@@ -100,6 +104,9 @@ func TestValidateDocument_ok(t *testing.T) {
 
 	d3 := &Document{&Document_Page{NewTestPage(rng)}}
 	assert.Nil(t, ValidateDocument(d3))
+
+	d4 := &Document{&Document_Pointer{NewTestPointer(rng)}}
+	assert.Nil(t, ValidateDocument(d4))
 }
 
 func TestValidateEnvelope_ok(t *testing.T) {
@@ -156,6 +163,9 @@ func TestValidateEntry_ok(t *testing.T) {
 		},
 	}
 	assert.Nil(t, ValidateEntry(e2))
+
+	e3 := NewTestSignedEntry(rng)
+	assert.Nil(t, ValidateEntry(e3))
 }
 
 func TestValidateEntry_err(t *testing.T) {
@@ -186,6 +196,19 @@ func TestValidateEntry_err(t *testing.T) {
 		c(badEntry)
 		assert.NotNil(t, ValidateEntry(badEntry), fmt.Sprintf("case %d", i))
 	}
+
+	// each of the cases takes a valid signed *Entry and changes it in some way to make it invalid
+	signedCases := []func(e *Entry){
+		func(e *Entry) { e.AuthorSignature = empty },  // 0) can't be zero-length
+		func(e *Entry) { e.AuthorSignature = zeros },  // 1) can't be all zeros
+		func(e *Entry) { e.AuthorSignature = badLen }, // 2) length must be <= 72
+		func(e *Entry) { e.CreatedTime++ },            // 3) signature must match contents
+	}
+	for i, c := range signedCases {
+		badEntry := NewTestSignedEntry(rng)
+		c(badEntry)
+		assert.NotNil(t, ValidateEntry(badEntry), fmt.Sprintf("signed case %d", i))
+	}
 }
 
 func TestValidatePage_ok(t *testing.T) {
@@ -221,6 +244,43 @@ func TestValidatePage_err(t *testing.T) {
 	}
 }
 
+func TestValidatePointer_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	p := NewTestPointer(rng)
+	assert.Nil(t, ValidatePointer(p))
+}
+
+func TestValidatePointer_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	badLen := RandBytes(rng, 100)
+	empty, zeros := []byte{}, []byte{0, 0, 0}
+	longName := string(RandBytes(rng, MaxPointerNameLength+1))
+
+	// each of the cases takes a valid *Pointer and changes it in some way to make it invalid
+	cases := []func(p *Pointer){
+		func(p *Pointer) { p.AuthorPublicKey = nil },    // 0) can't be nil
+		func(p *Pointer) { p.AuthorPublicKey = empty },  // 1) can't be zero-length
+		func(p *Pointer) { p.AuthorPublicKey = zeros },  // 2) can't be all zeros
+		func(p *Pointer) { p.AuthorPublicKey = badLen }, // 3) length must be 65
+		func(p *Pointer) { p.Name = "" },                // 4) can't be empty
+		func(p *Pointer) { p.Name = longName },          // 5) can't be too long
+		func(p *Pointer) { p.TargetKey = nil },          // 6) can't be nil
+		func(p *Pointer) { p.TargetKey = zeros },        // 7) can't be all zeros
+		func(p *Pointer) { p.TargetKey = badLen },       // 8) length must be 32
+		func(p *Pointer) { p.Sequence = 0 },             // 9) must be populated
+		func(p *Pointer) { p.Signature = nil },          // 10) can't be nil
+		func(p *Pointer) { p.Signature = zeros },        // 11) can't be all zeros
+		func(p *Pointer) { p.Signature = badLen },       // 12) can't be too long
+	}
+
+	assert.NotNil(t, ValidatePointer(nil))
+	for i, c := range cases {
+		badPointer := NewTestPointer(rng)
+		c(badPointer)
+		assert.NotNil(t, ValidatePointer(badPointer), fmt.Sprintf("case %d", i))
+	}
+}
+
 func TestValidatePageKeys_ok(t *testing.T) {
 	pk := &PageKeys{
 		Keys: [][]byte{[]byte{0, 1, 2}, []byte{1, 2, 3}},
@@ -18,6 +18,35 @@ var _ = proto.Marshal
 var _ = fmt.Errorf
 var _ = math.Inf
 
+// KeyType is the signature scheme (and curve) of a peer's public key. Author and reader keys in
+// documents and keychains are always SECP256K1.
+type KeyType int32
+
+const (
+	// ECDSA over secp256k1, the default for peers that predate key types
+	KeyType_SECP256K1 KeyType = 0
+	// ECDSA over NIST P-256
+	KeyType_P256 KeyType = 1
+	// EdDSA over Curve25519
+	KeyType_ED25519 KeyType = 2
+)
+
+var KeyType_name = map[int32]string{
+	0: "SECP256K1",
+	1: "P256",
+	2: "ED25519",
+}
+var KeyType_value = map[string]int32{
+	"SECP256K1": 0,
+	"P256":      1,
+	"ED25519":   2,
+}
+
+func (x KeyType) String() string {
+	return proto.EnumName(KeyType_name, int32(x))
+}
+func (KeyType) EnumDescriptor() ([]byte, []int) { return fileDescriptor1, []int{0} }
+
 type PutOperation int32
 
 const (
@@ -39,14 +68,19 @@ var PutOperation_value = map[string]int32{
 func (x PutOperation) String() string {
 	return proto.EnumName(PutOperation_name, int32(x))
 }
-func (PutOperation) EnumDescriptor() ([]byte, []int) { return fileDescriptor1, []int{0} }
+func (PutOperation) EnumDescriptor() ([]byte, []int) { return fileDescriptor1, []int{1} }
 
 // RequestMetadata defines metadata associated with every request.
 type RequestMetadata struct {
 	// 32-byte unique request ID
 	RequestId []byte `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
-	// peer's ECDSA public key
+	// peer's public key
 	PubKey []byte `protobuf:"bytes,2,opt,name=pub_key,json=pubKey,proto3" json:"pub_key,omitempty"`
+	// type of the peer's public key
+	KeyType KeyType `protobuf:"varint,3,opt,name=key_type,json=keyType,enum=api.KeyType" json:"key_type,omitempty"`
+	// Unix time (in nanoseconds) the request was created, which librarians use to reject stale
+	// (and hence possibly replayed) requests
+	Timestamp int64 `protobuf:"varint,4,opt,name=timestamp" json:"timestamp,omitempty"`
 }
 
 func (m *RequestMetadata) Reset()                    { *m = RequestMetadata{} }
@@ -68,11 +102,27 @@ func (m *RequestMetadata) GetPubKey() []byte {
 	return nil
 }
 
+func (m *RequestMetadata) GetKeyType() KeyType {
+	if m != nil {
+		return m.KeyType
+	}
+	return KeyType_SECP256K1
+}
+
+func (m *RequestMetadata) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
 type ResponseMetadata struct {
 	// 32-byte request ID that generated this response
 	RequestId []byte `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
-	// peer's ECDSA public key
+	// peer's public key
 	PubKey []byte `protobuf:"bytes,2,opt,name=pub_key,json=pubKey,proto3" json:"pub_key,omitempty"`
+	// type of the peer's public key
+	KeyType KeyType `protobuf:"varint,3,opt,name=key_type,json=keyType,enum=api.KeyType" json:"key_type,omitempty"`
 }
 
 func (m *ResponseMetadata) Reset()                    { *m = ResponseMetadata{} }
@@ -94,6 +144,13 @@ func (m *ResponseMetadata) GetPubKey() []byte {
 	return nil
 }
 
+func (m *ResponseMetadata) GetKeyType() KeyType {
+	if m != nil {
+		return m.KeyType
+	}
+	return KeyType_SECP256K1
+}
+
 type PingRequest struct {
 }
 
@@ -124,6 +181,9 @@ type IntroduceRequest struct {
 	Self *PeerAddress `protobuf:"bytes,2,opt,name=self" json:"self,omitempty"`
 	// number of peer librarians to request info for
 	NumPeers uint32 `protobuf:"varint,3,opt,name=num_peers,json=numPeers" json:"num_peers,omitempty"`
+	// number of leading zero bits the SHA-256 hash of a peer ID must have for the requester to
+	// accept the peer
+	IdDifficulty uint32 `protobuf:"varint,4,opt,name=id_difficulty,json=idDifficulty" json:"id_difficulty,omitempty"`
 }
 
 func (m *IntroduceRequest) Reset()                    { *m = IntroduceRequest{} }
@@ -152,12 +212,22 @@ func (m *IntroduceRequest) GetNumPeers() uint32 {
 	return 0
 }
 
+func (m *IntroduceRequest) GetIdDifficulty() uint32 {
+	if m != nil {
+		return m.IdDifficulty
+	}
+	return 0
+}
+
 type IntroduceResponse struct {
 	Metadata *ResponseMetadata `protobuf:"bytes,1,opt,name=metadata" json:"metadata,omitempty"`
 	// info about the peer receiving the introduction
 	Self *PeerAddress `protobuf:"bytes,2,opt,name=self" json:"self,omitempty"`
 	// info about other peers
 	Peers []*PeerAddress `protobuf:"bytes,3,rep,name=peers" json:"peers,omitempty"`
+	// number of leading zero bits the SHA-256 hash of a peer ID must have for the responder to
+	// accept the peer
+	IdDifficulty uint32 `protobuf:"varint,4,opt,name=id_difficulty,json=idDifficulty" json:"id_difficulty,omitempty"`
 }
 
 func (m *IntroduceResponse) Reset()                    { *m = IntroduceResponse{} }
@@ -186,6 +256,13 @@ func (m *IntroduceResponse) GetPeers() []*PeerAddress {
 	return nil
 }
 
+func (m *IntroduceResponse) GetIdDifficulty() uint32 {
+	if m != nil {
+		return m.IdDifficulty
+	}
+	return 0
+}
+
 type FindRequest struct {
 	Metadata *RequestMetadata `protobuf:"bytes,1,opt,name=metadata" json:"metadata,omitempty"`
 	// 32-byte target to find peers around
@@ -304,6 +381,8 @@ type StoreRequest struct {
 	Key []byte `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
 	// value to store for key
 	Value *Document `protobuf:"bytes,3,opt,name=value" json:"value,omitempty"`
+	// whether the value is a cache copy from along a lookup path rather than a primary replica
+	Cache bool `protobuf:"varint,4,opt,name=cache" json:"cache,omitempty"`
 }
 
 func (m *StoreRequest) Reset()                    { *m = StoreRequest{} }
@@ -332,6 +411,13 @@ func (m *StoreRequest) GetValue() *Document {
 	return nil
 }
 
+func (m *StoreRequest) GetCache() bool {
+	if m != nil {
+		return m.Cache
+	}
+	return false
+}
+
 type StoreResponse struct {
 	Metadata *ResponseMetadata `protobuf:"bytes,1,opt,name=metadata" json:"metadata,omitempty"`
 }
@@ -352,6 +438,9 @@ type GetRequest struct {
 	Metadata *RequestMetadata `protobuf:"bytes,1,opt,name=metadata" json:"metadata,omitempty"`
 	// 32-byte
 	Key []byte `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	// number of peers that must return the same value before it is accepted, or zero for the
+	// librarian's default; librarians clamp it to their configured bounds
+	NMatchingValues uint32 `protobuf:"varint,3,opt,name=n_matching_values,json=nMatchingValues" json:"n_matching_values,omitempty"`
 }
 
 func (m *GetRequest) Reset()                    { *m = GetRequest{} }
@@ -373,6 +462,13 @@ func (m *GetRequest) GetKey() []byte {
 	return nil
 }
 
+func (m *GetRequest) GetNMatchingValues() uint32 {
+	if m != nil {
+		return m.NMatchingValues
+	}
+	return 0
+}
+
 type GetResponse struct {
 	Metadata *ResponseMetadata `protobuf:"bytes,1,opt,name=metadata" json:"metadata,omitempty"`
 	// value to store for key
@@ -404,6 +500,9 @@ type PutRequest struct {
 	Key []byte `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
 	// value to store for key
 	Value *Document `protobuf:"bytes,3,opt,name=value" json:"value,omitempty"`
+	// number of replicas that must acknowledge storing the value, or zero for the librarian's
+	// default; librarians clamp it to their configured bounds
+	NReplicas uint32 `protobuf:"varint,4,opt,name=n_replicas,json=nReplicas" json:"n_replicas,omitempty"`
 }
 
 func (m *PutRequest) Reset()                    { *m = PutRequest{} }
@@ -432,6 +531,13 @@ func (m *PutRequest) GetValue() *Document {
 	return nil
 }
 
+func (m *PutRequest) GetNReplicas() uint32 {
+	if m != nil {
+		return m.NReplicas
+	}
+	return 0
+}
+
 type PutResponse struct {
 	Metadata *ResponseMetadata `protobuf:"bytes,1,opt,name=metadata" json:"metadata,omitempty"`
 	// result of the put operation
@@ -624,6 +730,7 @@ func init() {
 	proto.RegisterType((*Publication)(nil), "api.Publication")
 	proto.RegisterType((*Subscription)(nil), "api.Subscription")
 	proto.RegisterType((*BloomFilter)(nil), "api.BloomFilter")
+	proto.RegisterEnum("api.KeyType", KeyType_name, KeyType_value)
 	proto.RegisterEnum("api.PutOperation", PutOperation_name, PutOperation_value)
 }
 
@@ -640,7 +747,7 @@ const _ = grpc.SupportPackageIsVersion4
 type LibrarianClient interface {
 	// Ping confirms simple request/response connectivity.
 	Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error)
-	// Identify identifies the node by name and ID.
+	// Introduce identifies the node by name and ID.
 	Introduce(ctx context.Context, in *IntroduceRequest, opts ...grpc.CallOption) (*IntroduceResponse, error)
 	// Find returns the value for a key or the closest peers to it.
 	Find(ctx context.Context, in *FindRequest, opts ...grpc.CallOption) (*FindResponse, error)
@@ -753,7 +860,7 @@ func (x *librarianSubscribeClient) Recv() (*SubscribeResponse, error) {
 type LibrarianServer interface {
 	// Ping confirms simple request/response connectivity.
 	Ping(context.Context, *PingRequest) (*PingResponse, error)
-	// Identify identifies the node by name and ID.
+	// Introduce identifies the node by name and ID.
 	Introduce(context.Context, *IntroduceRequest) (*IntroduceResponse, error)
 	// Find returns the value for a key or the closest peers to it.
 	Find(context.Context, *FindRequest) (*FindResponse, error)
@@ -942,58 +1049,67 @@ var _Librarian_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("libri/librarian/api/librarian.proto", fileDescriptor1) }
 
 var fileDescriptor1 = []byte{
-	// 841 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xbc, 0x56, 0xcd, 0x8e, 0x23, 0x35,
-	0x10, 0x9e, 0x4e, 0x32, 0xb3, 0xe9, 0xea, 0x64, 0xa7, 0x63, 0xf1, 0x13, 0x05, 0x21, 0x2d, 0x5e,
-	0xb4, 0x8c, 0x46, 0x9a, 0x1f, 0x82, 0xb8, 0xa1, 0x95, 0x58, 0xed, 0xcc, 0x28, 0xec, 0xb2, 0x1b,
-	0x75, 0xe6, 0xc0, 0x2d, 0x72, 0xd2, 0xc5, 0xd0, 0x22, 0xed, 0x36, 0xb6, 0x7b, 0xd1, 0x88, 0x0b,
-	0x37, 0x6e, 0x88, 0x03, 0xaf, 0xc0, 0x03, 0xf2, 0x06, 0xa8, 0x6d, 0xf7, 0x4f, 0x92, 0xd5, 0x08,
-	0xb2, 0x23, 0x2e, 0x51, 0x5c, 0xf5, 0xd9, 0xf5, 0x7d, 0xe5, 0x72, 0x55, 0xc3, 0xe3, 0x55, 0xb2,
-	0x90, 0xc9, 0x59, 0xf1, 0xcb, 0x64, 0xc2, 0xf8, 0x19, 0x13, 0x8d, 0xd5, 0xa9, 0x90, 0x99, 0xce,
-	0x48, 0x9b, 0x89, 0x64, 0xf4, 0x56, 0x64, 0x9c, 0x2d, 0xf3, 0x14, 0xb9, 0x56, 0x16, 0x49, 0x27,
-	0x70, 0x18, 0xe1, 0x4f, 0x39, 0x2a, 0xfd, 0x2d, 0x6a, 0x16, 0x33, 0xcd, 0xc8, 0xc7, 0x00, 0xd2,
-	0x9a, 0xe6, 0x49, 0x3c, 0xf4, 0x1e, 0x79, 0x47, 0xbd, 0xc8, 0x77, 0x96, 0x49, 0x4c, 0x3e, 0x84,
-	0x07, 0x22, 0x5f, 0xcc, 0x7f, 0xc4, 0xdb, 0x61, 0xcb, 0xf8, 0x0e, 0x44, 0xbe, 0x78, 0x81, 0xb7,
-	0xf4, 0x1b, 0x08, 0x23, 0x54, 0x22, 0xe3, 0x0a, 0xdf, 0xf9, 0xac, 0x3e, 0x04, 0xd3, 0x84, 0xdf,
-	0x38, 0x6a, 0xf4, 0x08, 0x7a, 0x76, 0x69, 0x8f, 0x27, 0x43, 0x78, 0x90, 0xa2, 0x52, 0xec, 0x06,
-	0xcd, 0x99, 0x7e, 0x54, 0x2e, 0xe9, 0x6f, 0x1e, 0x84, 0x13, 0xae, 0x65, 0x16, 0xe7, 0x4b, 0x74,
-	0xdb, 0xc9, 0x39, 0x74, 0x53, 0xc7, 0xc8, 0xe0, 0x83, 0xf1, 0x7b, 0xa7, 0x4c, 0x24, 0xa7, 0x1b,
-	0xca, 0xa3, 0x0a, 0x45, 0x3e, 0x85, 0x8e, 0xc2, 0xd5, 0xf7, 0x86, 0x55, 0x30, 0x0e, 0x0d, 0x7a,
-	0x8a, 0x28, 0xbf, 0x8e, 0x63, 0x89, 0x4a, 0x45, 0xc6, 0x4b, 0x3e, 0x02, 0x9f, 0xe7, 0xe9, 0x5c,
-	0x20, 0x4a, 0x35, 0x6c, 0x3f, 0xf2, 0x8e, 0xfa, 0x51, 0x97, 0xe7, 0x69, 0x01, 0x54, 0xf4, 0x4f,
-	0x0f, 0x06, 0x0d, 0x26, 0x8e, 0xf9, 0xe7, 0x5b, 0x54, 0xde, 0x77, 0x54, 0xd6, 0x33, 0xf7, 0x9f,
-	0xb9, 0x3c, 0x81, 0xfd, 0x92, 0x47, 0xfb, 0xad, 0x30, 0xeb, 0xa6, 0x1c, 0x82, 0xcb, 0x84, 0xc7,
-	0xbb, 0xa7, 0x26, 0x84, 0x76, 0x7d, 0x5f, 0xc5, 0xdf, 0xbb, 0xd3, 0xf0, 0xbb, 0x07, 0x3d, 0x1b,
-	0x70, 0xf7, 0x0c, 0x54, 0xda, 0x5a, 0x77, 0x6a, 0x23, 0x8f, 0x61, 0xff, 0x0d, 0x5b, 0xe5, 0x68,
-	0x48, 0x04, 0xe3, 0xbe, 0xc1, 0x3d, 0x77, 0x15, 0x1f, 0x59, 0x1f, 0xbd, 0x81, 0xa0, 0xb1, 0xd5,
-	0x94, 0x20, 0xa2, 0xac, 0xcb, 0xf3, 0xa0, 0x58, 0x4e, 0xe2, 0x42, 0x95, 0x71, 0x70, 0x96, 0xa2,
-	0x51, 0xeb, 0x47, 0xdd, 0xc2, 0xf0, 0x8a, 0xa5, 0x48, 0x1e, 0x42, 0x2b, 0x11, 0x26, 0x8c, 0x1f,
-	0xb5, 0x12, 0x41, 0x08, 0x74, 0x44, 0x26, 0xf5, 0xb0, 0x63, 0xd4, 0x9b, 0xff, 0xf4, 0x67, 0xe8,
-	0xcd, 0x74, 0x26, 0xf1, 0x3e, 0x53, 0xfd, 0xaf, 0x14, 0x3e, 0x83, 0xbe, 0x0b, 0xbc, 0x73, 0xca,
-	0xe9, 0x14, 0xe0, 0x0a, 0xf5, 0x3d, 0x52, 0xa7, 0x08, 0x81, 0x39, 0x71, 0xf7, 0x32, 0xa8, 0xc4,
-	0xb7, 0xee, 0x10, 0x9f, 0x03, 0x4c, 0x73, 0xfd, 0xbf, 0xe7, 0xfc, 0x0f, 0x0f, 0x02, 0x13, 0x77,
-	0x77, 0x79, 0x67, 0xe0, 0x67, 0x02, 0x25, 0xd3, 0x49, 0xc6, 0x4d, 0xfc, 0x87, 0xe3, 0x81, 0xad,
-	0xf4, 0x5c, 0xbf, 0x2e, 0x1d, 0x51, 0x8d, 0x29, 0x9a, 0x2b, 0x9f, 0x4b, 0x14, 0xab, 0x64, 0xc9,
-	0xca, 0x87, 0xe7, 0xf3, 0xc8, 0x19, 0xe8, 0x2f, 0x10, 0xce, 0xf2, 0x85, 0x5a, 0xca, 0x64, 0xf1,
-	0x0e, 0x35, 0xf8, 0x25, 0xf4, 0x94, 0x3d, 0x45, 0x54, 0xc4, 0x02, 0x47, 0x6c, 0xd6, 0x70, 0x44,
-	0x6b, 0x30, 0xfa, 0xab, 0x07, 0x83, 0x46, 0xf4, 0xdd, 0xb3, 0xb2, 0x7d, 0x1f, 0x4f, 0xd6, 0xef,
-	0xc3, 0x75, 0x83, 0x7c, 0x51, 0xa8, 0x36, 0x4c, 0xdc, 0x95, 0xfc, 0x65, 0xae, 0xa4, 0x32, 0x93,
-	0x4f, 0xa0, 0x87, 0xfc, 0x0d, 0xae, 0x32, 0x81, 0x66, 0xe2, 0xd8, 0xe7, 0x1e, 0x94, 0xb6, 0x17,
-	0xb6, 0x93, 0x21, 0xd7, 0xf2, 0xb6, 0x31, 0x91, 0xba, 0xc6, 0x50, 0x38, 0x8f, 0x61, 0xc0, 0x72,
-	0xfd, 0x43, 0x26, 0xe7, 0xc2, 0x9c, 0x6a, 0x40, 0x6d, 0x03, 0x3a, 0xb4, 0x0e, 0x1b, 0xcd, 0x61,
-	0x25, 0xb2, 0x18, 0xd7, 0xb0, 0x1d, 0x8b, 0xb5, 0x8e, 0x0a, 0x6b, 0x3a, 0x64, 0x33, 0x93, 0xe4,
-	0x29, 0x90, 0xad, 0x40, 0xca, 0xe5, 0xcb, 0xaa, 0x7d, 0xb6, 0xca, 0xb2, 0xf4, 0x32, 0x59, 0x69,
-	0x94, 0x51, 0xb8, 0x11, 0x5b, 0x15, 0xfb, 0xb7, 0x82, 0xab, 0xb5, 0xf1, 0xb1, 0xb6, 0x7f, 0x83,
-	0x8f, 0xa2, 0x9f, 0x41, 0xd0, 0x00, 0x14, 0xc3, 0x16, 0xf9, 0x32, 0x8b, 0xb1, 0xec, 0x90, 0xe5,
-	0xf2, 0xf8, 0x04, 0x7a, 0xcd, 0xda, 0x24, 0x00, 0x07, 0xb3, 0xeb, 0xd7, 0xd1, 0xc5, 0xf3, 0x70,
-	0x8f, 0x0c, 0xa0, 0xff, 0xf2, 0xe2, 0xf2, 0x7a, 0x7e, 0xf1, 0xdd, 0x64, 0x76, 0x3d, 0x79, 0x75,
-	0x15, 0x7a, 0xe3, 0xbf, 0x5b, 0xe0, 0xbf, 0x2c, 0xbf, 0x46, 0xc8, 0x09, 0x74, 0x8a, 0x99, 0x4e,
-	0xdc, 0xfd, 0xd5, 0xd3, 0x7e, 0x34, 0x68, 0x58, 0x6c, 0x5d, 0xd0, 0x3d, 0xf2, 0x15, 0xf8, 0xd5,
-	0x34, 0x25, 0xb6, 0x6a, 0x36, 0xe7, 0xfc, 0xe8, 0x83, 0x4d, 0x73, 0xb5, 0xfb, 0x04, 0x3a, 0xc5,
-	0x10, 0x72, 0xc1, 0x1a, 0x03, 0xd0, 0x05, 0x6b, 0x4e, 0x28, 0xba, 0x47, 0xce, 0x61, 0xdf, 0x74,
-	0x50, 0xe2, 0xea, 0xbc, 0xd1, 0xc6, 0x47, 0xa4, 0x69, 0xaa, 0x76, 0x1c, 0x43, 0xfb, 0x0a, 0x35,
-	0x39, 0x34, 0xce, 0xba, 0x73, 0x8e, 0xc2, 0xda, 0xd0, 0xc4, 0x4e, 0xf3, 0x12, 0x5b, 0x37, 0xab,
-	0x51, 0x58, 0x1b, 0x2a, 0xec, 0x53, 0xf0, 0xab, 0x67, 0xe4, 0x64, 0x6f, 0x3e, 0x6a, 0x27, 0x7b,
-	0xeb, 0xb5, 0xd1, 0xbd, 0x73, 0x6f, 0x71, 0x60, 0x3e, 0xf3, 0xbe, 0xf8, 0x27, 0x00, 0x00, 0xff,
-	0xff, 0x29, 0xb8, 0x98, 0x95, 0x37, 0x0a, 0x00, 0x00,
+	// 988 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x56, 0xdd, 0x6e, 0xe3, 0x44,
+	0x14, 0xae, 0x93, 0xb4, 0x8d, 0x8f, 0x93, 0xd6, 0x19, 0x2d, 0x4b, 0x14, 0x58, 0xa9, 0x78, 0xd1,
+	0x6e, 0x55, 0xa9, 0x7f, 0x41, 0x45, 0x42, 0x42, 0x2b, 0xb1, 0x34, 0xad, 0xa2, 0xec, 0x4f, 0xe4,
+	0x54, 0x88, 0x3b, 0xcb, 0x89, 0x4f, 0xdb, 0x51, 0x63, 0x7b, 0xf0, 0x8c, 0x57, 0x8a, 0xe0, 0x82,
+	0x17, 0x00, 0x71, 0xc1, 0x2b, 0x70, 0xc7, 0x0b, 0xf0, 0x58, 0xbc, 0x01, 0xf2, 0xcc, 0xd8, 0x71,
+	0xdd, 0x65, 0x59, 0xb2, 0xab, 0xbd, 0x89, 0x3c, 0xdf, 0xf9, 0x26, 0xe7, 0x3b, 0x67, 0xce, 0x9c,
+	0x39, 0xf0, 0x70, 0x4e, 0xa7, 0x09, 0x3d, 0xcc, 0x7e, 0xfd, 0x84, 0xfa, 0xd1, 0xa1, 0xcf, 0x4a,
+	0xab, 0x03, 0x96, 0xc4, 0x22, 0x26, 0x75, 0x9f, 0xd1, 0xde, 0x6b, 0x99, 0x41, 0x3c, 0x4b, 0x43,
+	0x8c, 0x04, 0x57, 0x4c, 0xe7, 0x37, 0x03, 0xb6, 0x5d, 0xfc, 0x21, 0x45, 0x2e, 0x9e, 0xa3, 0xf0,
+	0x03, 0x5f, 0xf8, 0xe4, 0x01, 0x40, 0xa2, 0x20, 0x8f, 0x06, 0x5d, 0x63, 0xc7, 0xd8, 0x6d, 0xb9,
+	0xa6, 0x46, 0x86, 0x01, 0xf9, 0x18, 0x36, 0x59, 0x3a, 0xf5, 0x6e, 0x70, 0xd1, 0xad, 0x49, 0xdb,
+	0x06, 0x4b, 0xa7, 0x23, 0x5c, 0x90, 0xc7, 0xd0, 0xbc, 0xc1, 0x85, 0x27, 0x16, 0x0c, 0xbb, 0xf5,
+	0x1d, 0x63, 0x77, 0xab, 0xdf, 0x3a, 0xf0, 0x19, 0x3d, 0x18, 0xe1, 0xe2, 0x62, 0xc1, 0xd0, 0xdd,
+	0xbc, 0x51, 0x1f, 0xe4, 0x53, 0x30, 0x05, 0x0d, 0x91, 0x0b, 0x3f, 0x64, 0xdd, 0xc6, 0x8e, 0xb1,
+	0x5b, 0x77, 0x97, 0x80, 0xc3, 0xc1, 0x76, 0x91, 0xb3, 0x38, 0xe2, 0xf8, 0xc1, 0x24, 0x39, 0x6d,
+	0xb0, 0xc6, 0x34, 0xba, 0xd2, 0xa9, 0x70, 0x76, 0xa1, 0xa5, 0x96, 0x4a, 0x07, 0xe9, 0xc2, 0x66,
+	0x88, 0x9c, 0xfb, 0x57, 0x28, 0x9d, 0x9b, 0x6e, 0xbe, 0x74, 0xfe, 0x34, 0xc0, 0x1e, 0x46, 0x22,
+	0x89, 0x83, 0x74, 0x86, 0x7a, 0x3b, 0x39, 0x82, 0x66, 0xa8, 0xa5, 0x4b, 0xbe, 0xd5, 0xbf, 0x27,
+	0xdd, 0x56, 0x32, 0xed, 0x16, 0x2c, 0xf2, 0x39, 0x34, 0x38, 0xce, 0x2f, 0xa5, 0x7c, 0xab, 0x6f,
+	0x4b, 0xf6, 0x18, 0x31, 0xf9, 0x26, 0x08, 0x12, 0xe4, 0xdc, 0x95, 0x56, 0xf2, 0x09, 0x98, 0x51,
+	0x1a, 0x7a, 0x0c, 0x31, 0xe1, 0x32, 0x9e, 0xb6, 0xdb, 0x8c, 0xd2, 0x30, 0x23, 0x72, 0xf2, 0x10,
+	0xda, 0x34, 0xf0, 0x02, 0x7a, 0x79, 0x49, 0x67, 0xe9, 0x5c, 0x2c, 0x64, 0x66, 0xdb, 0x6e, 0x8b,
+	0x06, 0xa7, 0x05, 0xe6, 0xfc, 0x65, 0x40, 0xa7, 0x24, 0x57, 0x87, 0x77, 0x7c, 0x47, 0xef, 0x47,
+	0x5a, 0xef, 0xed, 0x73, 0xf8, 0xdf, 0x82, 0x1f, 0xc1, 0x7a, 0x2e, 0xb6, 0xfe, 0x5a, 0xda, 0x3a,
+	0x7b, 0x7b, 0xed, 0x11, 0x58, 0x67, 0x34, 0x0a, 0x56, 0x4f, 0xb2, 0x0d, 0xf5, 0x65, 0x89, 0x64,
+	0x9f, 0x6f, 0x4c, 0xa8, 0xf3, 0xab, 0x01, 0x2d, 0xe5, 0x70, 0xf5, 0x34, 0x15, 0x09, 0xa8, 0xfd,
+	0x57, 0x02, 0xd6, 0x5f, 0xf9, 0xf3, 0x54, 0x55, 0xa9, 0xd5, 0x6f, 0x4b, 0xde, 0xa9, 0xbe, 0xac,
+	0xae, 0xb2, 0x39, 0x57, 0x60, 0x95, 0xb6, 0xca, 0xaa, 0x47, 0x4c, 0x96, 0x37, 0x62, 0x23, 0x5b,
+	0x0e, 0x83, 0x2c, 0x2a, 0x69, 0x88, 0xfc, 0x10, 0x65, 0xb4, 0xa6, 0xdb, 0xcc, 0x80, 0x17, 0x7e,
+	0x88, 0x64, 0x0b, 0x6a, 0x94, 0x49, 0x37, 0xa6, 0x5b, 0xa3, 0x8c, 0x10, 0x68, 0xb0, 0x38, 0x11,
+	0x3a, 0xe3, 0xf2, 0xdb, 0xf9, 0xc5, 0x80, 0xd6, 0x44, 0xc4, 0x09, 0xbe, 0xcf, 0x5c, 0xbf, 0x4d,
+	0x88, 0xe4, 0x1e, 0xac, 0xcf, 0xfc, 0xd9, 0x35, 0x4a, 0x39, 0x4d, 0x57, 0x2d, 0x9c, 0xa7, 0xd0,
+	0xd6, 0x72, 0x56, 0x3e, 0x09, 0xe7, 0x27, 0x80, 0x73, 0x14, 0xef, 0x33, 0xa0, 0x3d, 0xe8, 0x44,
+	0x5e, 0xe8, 0x8b, 0xd9, 0x35, 0x8d, 0xae, 0x3c, 0xa9, 0x3f, 0x2f, 0xa2, 0xed, 0xe8, 0xb9, 0xc6,
+	0xbf, 0x93, 0xb0, 0x83, 0x60, 0x49, 0xef, 0xab, 0x57, 0x52, 0x91, 0xbe, 0xda, 0x1b, 0x2a, 0xe4,
+	0x77, 0x03, 0x60, 0x9c, 0x8a, 0x0f, 0x7e, 0x6c, 0x0f, 0x00, 0x22, 0x2f, 0x41, 0x36, 0xa7, 0x33,
+	0x9f, 0xeb, 0x52, 0x32, 0x23, 0x57, 0x03, 0xd9, 0x2b, 0x63, 0x49, 0x59, 0xab, 0x87, 0x7f, 0x08,
+	0x66, 0xcc, 0x30, 0xf1, 0x05, 0x8d, 0x23, 0x29, 0x6f, 0xab, 0xdf, 0x51, 0x97, 0x29, 0x15, 0x2f,
+	0x73, 0x83, 0xbb, 0xe4, 0x54, 0x24, 0xd5, 0xab, 0x92, 0x7e, 0x04, 0x7b, 0x92, 0x4e, 0xf9, 0x2c,
+	0xa1, 0xd3, 0x77, 0xa8, 0xf2, 0x13, 0x68, 0x71, 0xf5, 0x2f, 0xac, 0x10, 0x66, 0x69, 0x61, 0x93,
+	0x92, 0xc1, 0xbd, 0x45, 0x73, 0x7e, 0x36, 0xa0, 0x53, 0xf2, 0xbe, 0x7a, 0x56, 0xee, 0x1e, 0xd7,
+	0xa3, 0xdb, 0xc7, 0xa5, 0x1b, 0x4e, 0x3a, 0xcd, 0xa2, 0x96, 0x4a, 0x74, 0xa5, 0xfc, 0x21, 0x8f,
+	0xa4, 0x80, 0xc9, 0x67, 0xd0, 0xc2, 0xe8, 0x15, 0xce, 0x63, 0x86, 0xf2, 0x1d, 0x55, 0x1d, 0xc5,
+	0xca, 0xb1, 0x91, 0x6a, 0x96, 0x18, 0x89, 0x64, 0x51, 0x7a, 0x67, 0x9b, 0x12, 0x18, 0xa9, 0xcb,
+	0xe0, 0xa7, 0xe2, 0x3a, 0x4e, 0x3c, 0x26, 0xff, 0x55, 0x92, 0xea, 0x92, 0xb4, 0xad, 0x0c, 0xca,
+	0x9b, 0xe6, 0x26, 0xe8, 0x07, 0x78, 0x8b, 0xdb, 0x50, 0x5c, 0x65, 0x28, 0xb8, 0xb2, 0x09, 0x97,
+	0x33, 0x49, 0x9e, 0x00, 0xb9, 0xe3, 0x88, 0x77, 0x8d, 0x52, 0xb4, 0x4f, 0xe7, 0x71, 0x1c, 0x9e,
+	0xd1, 0xb9, 0xc0, 0xc4, 0xb5, 0x2b, 0xbe, 0x79, 0xb6, 0xff, 0x8e, 0x73, 0xde, 0xad, 0xfd, 0xdb,
+	0xfe, 0x8a, 0x1e, 0xee, 0x3c, 0x06, 0xab, 0x44, 0xc8, 0x26, 0x03, 0x8c, 0x66, 0x71, 0x80, 0x79,
+	0x13, 0xce, 0x97, 0x7b, 0x87, 0xb0, 0xa9, 0xc7, 0x0c, 0xd2, 0x06, 0x73, 0x32, 0xf8, 0x76, 0xdc,
+	0x3f, 0xf9, 0x72, 0x74, 0x6c, 0xaf, 0x91, 0x26, 0x34, 0xb2, 0x6f, 0xdb, 0x20, 0x16, 0x6c, 0x0e,
+	0x4e, 0xfb, 0x27, 0x27, 0xc7, 0x5f, 0xd9, 0xb5, 0xbd, 0x7d, 0x68, 0x95, 0x8b, 0x99, 0x00, 0x6c,
+	0x4c, 0x2e, 0x5e, 0xba, 0x83, 0x53, 0x7b, 0x8d, 0x74, 0xa0, 0xfd, 0x6c, 0x70, 0x76, 0xe1, 0x0d,
+	0xbe, 0x1f, 0x4e, 0x2e, 0x86, 0x2f, 0xce, 0x6d, 0xa3, 0xff, 0x77, 0x0d, 0xcc, 0x67, 0xf9, 0x70,
+	0x47, 0xf6, 0xa1, 0x91, 0x4d, 0x2c, 0x44, 0x1f, 0xf8, 0x72, 0x96, 0xe9, 0x75, 0x4a, 0x88, 0x2a,
+	0x24, 0x67, 0x8d, 0x7c, 0x0d, 0x66, 0x31, 0x06, 0x10, 0x55, 0x66, 0xd5, 0x29, 0xa6, 0x77, 0xbf,
+	0x0a, 0x17, 0xbb, 0xf7, 0xa1, 0x91, 0x3d, 0x8c, 0xda, 0x59, 0xe9, 0x51, 0xee, 0x75, 0x4a, 0x48,
+	0x41, 0x3f, 0x82, 0x75, 0xd9, 0xbe, 0x89, 0xbe, 0x18, 0xa5, 0x97, 0xa5, 0x47, 0xca, 0x50, 0xb1,
+	0x63, 0x0f, 0xea, 0xe7, 0x28, 0xc8, 0xb6, 0x34, 0x2e, 0xdb, 0x76, 0xcf, 0x5e, 0x02, 0x65, 0xee,
+	0x38, 0xcd, 0xb9, 0xe3, 0xb4, 0xc2, 0x2d, 0xb5, 0x1d, 0x67, 0x8d, 0x3c, 0x01, 0xb3, 0xb8, 0x77,
+	0x3a, 0xec, 0x6a, 0x17, 0xe8, 0xdd, 0xaf, 0xc2, 0xf9, 0xee, 0x23, 0x63, 0xba, 0x21, 0xa7, 0xe6,
+	0x2f, 0xfe, 0x19, 0x00, 0x6a, 0x07, 0x62, 0x18, 0x86, 0x0b, 0x00, 0x00,
 }
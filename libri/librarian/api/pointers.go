@@ -0,0 +1,87 @@
+package api
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+
+	"github.com/drausin/libri/libri/common/id"
+)
+
+// GetPointerKey returns the key of the pointer with the given author public key and name, which is
+// the SHA-256 hash of the author public key followed by the name.
+func GetPointerKey(authorPub []byte, name string) id.ID {
+	h := sha256.New()
+	_, err := h.Write(authorPub)
+	if err != nil {
+		// should never happen
+		panic(err)
+	}
+	_, err = h.Write([]byte(name))
+	if err != nil {
+		// should never happen
+		panic(err)
+	}
+	return id.FromBytes(h.Sum(nil))
+}
+
+// GetDocumentKey returns the key for a document. Pointer keys are derived from their author public
+// key and name via GetPointerKey, and all other document keys are the hash of the document via
+// GetKey.
+func GetDocumentKey(d *Document) (id.ID, error) {
+	if p := d.GetPointer(); p != nil {
+		return GetPointerKey(p.AuthorPublicKey, p.Name), nil
+	}
+	return GetKey(d)
+}
+
+// SignPointer signs the pointer (without its signature) with the author private key and sets its
+// Signature field.
+func SignPointer(p *Pointer, authorKey *ecdsa.PrivateKey) error {
+	hash, err := getPointerHash(p)
+	if err != nil {
+		return err
+	}
+	sig, err := sign(authorKey, hash)
+	if err != nil {
+		return err
+	}
+	p.Signature = sig
+	return nil
+}
+
+// VerifyPointer checks that the pointer is valid, that the key is derived from its author public
+// key and name, and that it was signed by its author.
+func VerifyPointer(key []byte, p *Pointer) error {
+	if err := ValidatePointer(p); err != nil {
+		return err
+	}
+	if !bytes.Equal(key, GetPointerKey(p.AuthorPublicKey, p.Name).Bytes()) {
+		return ErrUnexpectedKey
+	}
+	hash, err := getPointerHash(p)
+	if err != nil {
+		return err
+	}
+	return verify(p.AuthorPublicKey, hash, p.Signature)
+}
+
+// IsNewerPointer returns whether the new document is a Pointer that should replace the old one,
+// i.e., whether both are Pointers and the new one has the larger sequence number.
+func IsNewerPointer(new, old *Document) bool {
+	newPointer, ok := new.Contents.(*Document_Pointer)
+	if !ok {
+		return false
+	}
+	oldPointer, ok := old.Contents.(*Document_Pointer)
+	if !ok {
+		return false
+	}
+	return newPointer.Pointer.Sequence > oldPointer.Pointer.Sequence
+}
+
+func getPointerHash(p *Pointer) ([]byte, error) {
+	unsigned := *p
+	unsigned.Signature = nil
+	return getMessageHash(&unsigned)
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPointerKey(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	authorPub1 := ecid.NewPseudoRandom(rng).PublicKeyBytes()
+	authorPub2 := ecid.NewPseudoRandom(rng).PublicKeyBytes()
+
+	key := GetPointerKey(authorPub1, "name1")
+	assert.Nil(t, ValidateBytes(key.Bytes(), DocumentKeyLength, "key"))
+
+	// same author & name always gives the same key
+	assert.Equal(t, key, GetPointerKey(authorPub1, "name1"))
+
+	// different author or name gives a different key
+	assert.NotEqual(t, key, GetPointerKey(authorPub1, "name2"))
+	assert.NotEqual(t, key, GetPointerKey(authorPub2, "name1"))
+}
+
+func TestGetDocumentKey(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+
+	// pointer key is derived from author public key and name
+	p := NewTestPointer(rng)
+	key, err := GetDocumentKey(&Document{&Document_Pointer{Pointer: p}})
+	assert.Nil(t, err)
+	assert.Equal(t, GetPointerKey(p.AuthorPublicKey, p.Name), key)
+
+	// other document keys are their hashes
+	doc, expected := NewTestDocument(rng)
+	key, err = GetDocumentKey(doc)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, key)
+}
+
+func TestSignVerifyPointer_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	authorKey := ecid.NewPseudoRandom(rng)
+	p := &Pointer{
+		AuthorPublicKey: authorKey.PublicKeyBytes(),
+		Name:            "some name",
+		TargetKey:       RandBytes(rng, DocumentKeyLength),
+		Sequence:        2,
+	}
+	err := SignPointer(p, authorKey.Key())
+	assert.Nil(t, err)
+	assert.NotNil(t, p.Signature)
+
+	key := GetPointerKey(p.AuthorPublicKey, p.Name)
+	assert.Nil(t, VerifyPointer(key.Bytes(), p))
+}
+
+func TestVerifyPointer_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+
+	// invalid pointer
+	p1 := NewTestPointer(rng)
+	p1.Sequence = 0
+	key1 := GetPointerKey(p1.AuthorPublicKey, p1.Name)
+	assert.NotNil(t, VerifyPointer(key1.Bytes(), p1))
+
+	// key not derived from author public key & name
+	p2 := NewTestPointer(rng)
+	assert.Equal(t, ErrUnexpectedKey, VerifyPointer(RandBytes(rng, DocumentKeyLength), p2))
+
+	// changed after signing
+	p3 := NewTestPointer(rng)
+	p3.Sequence++
+	key3 := GetPointerKey(p3.AuthorPublicKey, p3.Name)
+	assert.Equal(t, ErrInvalidSignature, VerifyPointer(key3.Bytes(), p3))
+
+	// signed by different key
+	p4 := NewTestPointer(rng)
+	err := SignPointer(p4, ecid.NewPseudoRandom(rng).Key())
+	assert.Nil(t, err)
+	key4 := GetPointerKey(p4.AuthorPublicKey, p4.Name)
+	assert.Equal(t, ErrInvalidSignature, VerifyPointer(key4.Bytes(), p4))
+
+	// malformed signature
+	p5 := NewTestPointer(rng)
+	p5.Signature = RandBytes(rng, MaxECDSASignatureLength)
+	key5 := GetPointerKey(p5.AuthorPublicKey, p5.Name)
+	assert.Equal(t, ErrInvalidSignature, VerifyPointer(key5.Bytes(), p5))
+}
+
+func TestIsNewerPointer(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	p1, p2 := NewTestPointer(rng), NewTestPointer(rng)
+	p2.Sequence = p1.Sequence + 1
+	d1 := &Document{&Document_Pointer{Pointer: p1}}
+	d2 := &Document{&Document_Pointer{Pointer: p2}}
+	entry, _ := NewTestDocument(rng)
+
+	assert.True(t, IsNewerPointer(d2, d1))
+	assert.False(t, IsNewerPointer(d1, d2))
+	assert.False(t, IsNewerPointer(d1, d1))
+	assert.False(t, IsNewerPointer(entry, d1))
+	assert.False(t, IsNewerPointer(d1, entry))
+}
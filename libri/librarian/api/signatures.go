@@ -0,0 +1,95 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/golang/protobuf/proto"
+)
+
+var (
+	// ErrInvalidSignature indicates when a signature was not created by the private key
+	// corresponding to the expected public key.
+	ErrInvalidSignature = errors.New("invalid signature")
+
+	// ErrMissingSignature indicates when a signature is unexpectedly missing.
+	ErrMissingSignature = errors.New("missing signature")
+)
+
+// ecdsaSignature is the ASN.1 structure of an ECDSA signature.
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// SignEntry signs the entry (without its signature) with the author private key and sets its
+// AuthorSignature field.
+func SignEntry(e *Entry, authorKey *ecdsa.PrivateKey) error {
+	hash, err := getEntryHash(e)
+	if err != nil {
+		return err
+	}
+	sig, err := sign(authorKey, hash)
+	if err != nil {
+		return err
+	}
+	e.AuthorSignature = sig
+	return nil
+}
+
+// VerifyEntry checks that the entry has a signature created by its author.
+func VerifyEntry(e *Entry) error {
+	if e.AuthorSignature == nil {
+		return ErrMissingSignature
+	}
+	hash, err := getEntryHash(e)
+	if err != nil {
+		return err
+	}
+	return verify(e.AuthorPublicKey, hash, e.AuthorSignature)
+}
+
+// sign returns the ASN.1 DER ECDSA signature of the hash.
+func sign(key *ecdsa.PrivateKey, hash []byte) ([]byte, error) {
+	r, s, err := ecdsa.Sign(crand.Reader, key, hash)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(ecdsaSignature{R: r, S: s})
+}
+
+// verify checks that the ASN.1 DER ECDSA signature of the hash was created by the private key
+// corresponding to the public key.
+func verify(pubBytes []byte, hash []byte, sigBytes []byte) error {
+	pub, err := ecid.FromPublicKeyBytes(pubBytes)
+	if err != nil {
+		return err
+	}
+	sig := &ecdsaSignature{}
+	if rest, err := asn1.Unmarshal(sigBytes, sig); err != nil || len(rest) > 0 {
+		return ErrInvalidSignature
+	}
+	if !ecdsa.Verify(pub, hash, sig.R, sig.S) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func getEntryHash(e *Entry) ([]byte, error) {
+	unsigned := *e
+	unsigned.AuthorSignature = nil
+	return getMessageHash(&unsigned)
+}
+
+func getMessageHash(m proto.Message) ([]byte, error) {
+	mBytes, err := proto.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(mBytes)
+	return hash[:], nil
+}
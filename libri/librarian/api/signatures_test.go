@@ -0,0 +1,51 @@
+package api
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignVerifyEntry_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	authorKey := ecid.NewPseudoRandom(rng)
+	e := NewTestMultiPageEntry(rng)
+	e.AuthorPublicKey = authorKey.PublicKeyBytes()
+
+	err := SignEntry(e, authorKey.Key())
+	assert.Nil(t, err)
+	assert.NotNil(t, e.AuthorSignature)
+	assert.Nil(t, VerifyEntry(e))
+}
+
+func TestVerifyEntry_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+
+	// check missing signature triggers error
+	e1 := NewTestSignedEntry(rng)
+	e1.AuthorSignature = nil
+	assert.Equal(t, ErrMissingSignature, VerifyEntry(e1))
+
+	// check bad public key triggers error
+	e2 := NewTestSignedEntry(rng)
+	e2.AuthorPublicKey = RandBytes(rng, ECPubKeyLength)
+	assert.NotNil(t, VerifyEntry(e2))
+
+	// check malformed signature triggers error
+	e3 := NewTestSignedEntry(rng)
+	e3.AuthorSignature = RandBytes(rng, 64)
+	assert.Equal(t, ErrInvalidSignature, VerifyEntry(e3))
+
+	// check signature from different key triggers error
+	e4 := NewTestSignedEntry(rng)
+	err := SignEntry(e4, ecid.NewPseudoRandom(rng).Key())
+	assert.Nil(t, err)
+	assert.Equal(t, ErrInvalidSignature, VerifyEntry(e4))
+
+	// check modified contents trigger error
+	e5 := NewTestSignedEntry(rng)
+	e5.MetadataCiphertext = RandBytes(rng, 64)
+	assert.Equal(t, ErrInvalidSignature, VerifyEntry(e5))
+}
@@ -55,6 +55,19 @@ func NewTestMultiPageEntry(rng *rand.Rand) *Entry {
 	}
 }
 
+// NewTestSignedEntry generates a dummy Entry document with a single Page, signed by a random author
+// key, for use in testing.
+func NewTestSignedEntry(rng *rand.Rand) *Entry {
+	authorKey := ecid.NewPseudoRandom(rng)
+	e := NewTestSinglePageEntry(rng)
+	e.AuthorPublicKey = authorKey.PublicKeyBytes()
+	e.Contents.(*Entry_Page).Page.AuthorPublicKey = e.AuthorPublicKey
+	if err := SignEntry(e, authorKey.Key()); err != nil {
+		panic(err)
+	}
+	return e
+}
+
 // NewTestPage generates a dummy Page for use in testing.
 func NewTestPage(rng *rand.Rand) *Page {
 	return &Page{
@@ -64,6 +77,21 @@ func NewTestPage(rng *rand.Rand) *Page {
 	}
 }
 
+// NewTestPointer generates a dummy Pointer, signed by a random author key, for use in testing.
+func NewTestPointer(rng *rand.Rand) *Pointer {
+	authorKey := ecid.NewPseudoRandom(rng)
+	p := &Pointer{
+		AuthorPublicKey: authorKey.PublicKeyBytes(),
+		Name:            "some pointer name",
+		TargetKey:       RandBytes(rng, DocumentKeyLength),
+		Sequence:        1,
+	}
+	if err := SignPointer(p, authorKey.Key()); err != nil {
+		panic(err)
+	}
+	return p
+}
+
 // NewTestPublication generates a dummy Publication for use in testing.
 func NewTestPublication(rng *rand.Rand) *Publication {
 	return &Publication{
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/errors"
+	"github.com/drausin/libri/libri/common/id"
+	"golang.org/x/net/context"
+)
+
+var signatureBenchmarkCases = []struct {
+	name    string
+	keyType ecid.KeyType
+	compact bool
+}{
+	{"jwt-secp256k1", ecid.KeyTypeSecp256k1, false},
+	{"compact-secp256k1", ecid.KeyTypeSecp256k1, true},
+	{"jwt-ed25519", ecid.KeyTypeEd25519, false},
+	{"compact-ed25519", ecid.KeyTypeEd25519, true},
+}
+
+func BenchmarkNewSignedTimeoutContext(b *testing.B) {
+	for _, c := range signatureBenchmarkCases {
+		b.Run(c.name, func(b *testing.B) {
+			benchmarkNewSignedTimeoutContext(b, c.keyType, c.compact)
+		})
+	}
+}
+
+func BenchmarkFromSignatureContextVerify(b *testing.B) {
+	for _, c := range signatureBenchmarkCases {
+		b.Run(c.name, func(b *testing.B) {
+			benchmarkFromSignatureContextVerify(b, c.keyType, c.compact)
+		})
+	}
+}
+
+func benchmarkNewSignedTimeoutContext(b *testing.B, keyType ecid.KeyType, compact bool) {
+	b.StopTimer()
+	peerID, signer := newBenchmarkSigner(keyType, compact)
+	rng := rand.New(rand.NewSource(0))
+	rq := NewFindRequest(peerID, id.NewPseudoRandom(rng), 8)
+
+	b.StartTimer()
+	for n := 0; n < b.N; n++ {
+		_, cancel, err := NewSignedTimeoutContext(signer, rq, time.Second)
+		errors.MaybePanic(err)
+		cancel()
+	}
+}
+
+func benchmarkFromSignatureContextVerify(b *testing.B, keyType ecid.KeyType, compact bool) {
+	b.StopTimer()
+	peerID, signer := newBenchmarkSigner(keyType, compact)
+	rng := rand.New(rand.NewSource(0))
+	rq := NewFindRequest(peerID, id.NewPseudoRandom(rng), 8)
+	pubKey, err := ecid.ParsePublicKey(keyType, peerID.PublicKeyBytes())
+	errors.MaybePanic(err)
+	verifier := NewVerifier()
+
+	var ctx context.Context
+	if compact {
+		sig, err := signer.(CompactSigner).SignCompact(rq)
+		errors.MaybePanic(err)
+		ctx = NewIncomingCompactSignatureContext(context.Background(), sig)
+	} else {
+		encToken, err := signer.Sign(rq)
+		errors.MaybePanic(err)
+		ctx = NewIncomingSignatureContext(context.Background(), encToken)
+	}
+
+	b.StartTimer()
+	for n := 0; n < b.N; n++ {
+		if compact {
+			sig, err := FromCompactSignatureContext(ctx)
+			errors.MaybePanic(err)
+			errors.MaybePanic(verifier.VerifyCompact(sig, pubKey, rq))
+		} else {
+			encToken, err := FromSignatureContext(ctx)
+			errors.MaybePanic(err)
+			errors.MaybePanic(verifier.Verify(encToken, pubKey, rq))
+		}
+	}
+}
+
+func newBenchmarkSigner(keyType ecid.KeyType, compact bool) (ecid.Identity, Signer) {
+	rng := rand.New(rand.NewSource(0))
+	peerID, err := ecid.NewPseudoRandomIdentity(rng, keyType)
+	errors.MaybePanic(err)
+	if compact {
+		return peerID, NewFormatSigner(peerID.Signer(), CompactSignatures)
+	}
+	return peerID, NewJWTSigner(peerID.Signer())
+}
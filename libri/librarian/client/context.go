@@ -11,11 +11,19 @@ import (
 
 const (
 	signatureKey = "signature"
+
+	// compactSignatureKey is the metadata key of version 2 (compact binary) signatures. The -bin
+	// suffix tells gRPC to base-64 encode the value on the wire.
+	compactSignatureKey = "signature-v2-bin"
 )
 
 var (
 	errContextMissingMetadata  = errors.New("context unexpectedly missing metadata")
 	errContextMissingSignature = errors.New("metadata signature key unexpectedly does not exist")
+
+	// ErrContextMissingCompactSignature indicates when a context has no compact signature.
+	ErrContextMissingCompactSignature = errors.New("metadata compact signature key does not " +
+		"exist")
 )
 
 // NewSignatureContext creates a new context with the signed JSON web token (JWT) string.
@@ -42,18 +50,63 @@ func FromSignatureContext(ctx context.Context) (string, error) {
 	return signedJWTs[0], nil
 }
 
-// NewSignedContext creates a new context with a request signature.
+// NewCompactSignatureContext creates a new context with the compact binary signature.
+func NewCompactSignatureContext(ctx context.Context, sig []byte) context.Context {
+	return metadata.NewOutgoingContext(ctx, metadata.Pairs(compactSignatureKey, string(sig)))
+}
+
+// NewIncomingCompactSignatureContext creates a new context with the compact binary signature in
+// the incoming metadata field. This function should only be used for testing.
+func NewIncomingCompactSignatureContext(ctx context.Context, sig []byte) context.Context {
+	return metadata.NewIncomingContext(ctx, metadata.Pairs(compactSignatureKey, string(sig)))
+}
+
+// FromCompactSignatureContext extracts the compact binary signature from the context, returning
+// ErrContextMissingCompactSignature if the context has none (e.g., because the requester signed
+// a JWT instead).
+func FromCompactSignatureContext(ctx context.Context) ([]byte, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, errContextMissingMetadata
+	}
+	sigs, exists := md[compactSignatureKey]
+	if !exists {
+		return nil, ErrContextMissingCompactSignature
+	}
+	return []byte(sigs[0]), nil
+}
+
+// NewSignedContext creates a new context with the request signature. Signers that aren't
+// CompactSigners sign a JWT; CompactSigners sign a JWT, a compact signature, or both, per their
+// SignatureFormat.
 func NewSignedContext(signer Signer, request proto.Message) (context.Context, error) {
+	return newSignedContext(context.Background(), signer, request)
+}
 
-	ctx := context.Background()
+func newSignedContext(ctx context.Context, signer Signer, request proto.Message) (
+	context.Context, error) {
 
-	// sign the message
+	cs, ok := signer.(CompactSigner)
+	if !ok || cs.Format() == JWTSignatures {
+		signedJWT, err := signer.Sign(request)
+		if err != nil {
+			return nil, err
+		}
+		return NewSignatureContext(ctx, signedJWT), nil
+	}
+	sig, err := cs.SignCompact(request)
+	if err != nil {
+		return nil, err
+	}
+	if cs.Format() == CompactSignatures {
+		return NewCompactSignatureContext(ctx, sig), nil
+	}
 	signedJWT, err := signer.Sign(request)
 	if err != nil {
 		return nil, err
 	}
-	ctx = NewSignatureContext(ctx, signedJWT)
-	return ctx, nil
+	md := metadata.Pairs(signatureKey, signedJWT, compactSignatureKey, string(sig))
+	return metadata.NewOutgoingContext(ctx, md), nil
 }
 
 // NewSignedTimeoutContext creates a new context with a timeout and request signature.
@@ -46,6 +46,84 @@ func TestFromSignatureContext_missingSignatureErr(t *testing.T) {
 	assert.Zero(t, signedToken)
 	assert.NotNil(t, err)
 }
+func TestNewFromCompactSignatureContext(t *testing.T) {
+	ctx := context.Background()
+	sig1 := []byte{0, 1, 2, 255}
+	md, ok := metadata.FromOutgoingContext(NewCompactSignatureContext(ctx, sig1))
+	assert.True(t, ok)
+	assert.Equal(t, []string{string(sig1)}, md[compactSignatureKey])
+
+	sig2, err := FromCompactSignatureContext(NewIncomingCompactSignatureContext(ctx, sig1))
+	assert.Nil(t, err)
+	assert.Equal(t, sig1, sig2)
+}
+
+func TestFromCompactSignatureContext_err(t *testing.T) {
+	sig, err := FromCompactSignatureContext(context.Background())
+	assert.Nil(t, sig)
+	assert.Equal(t, errContextMissingMetadata, err)
+
+	// JWT-signed context has no compact signature
+	ctx := NewIncomingSignatureContext(context.Background(), "some.signed.token")
+	sig, err = FromCompactSignatureContext(ctx)
+	assert.Nil(t, sig)
+	assert.Equal(t, ErrContextMissingCompactSignature, err)
+}
+
+func TestNewSignedContext_compact(t *testing.T) {
+	rng := rand.New(rand.NewSource(int64(0)))
+	peerID := ecid.NewPseudoRandom(rng)
+	rq := NewFindRequest(peerID, id.NewPseudoRandom(rng), 20)
+
+	ctx, err := NewSignedContext(NewSigner(peerID.Key()), rq)
+	assert.Nil(t, err)
+	md, in := metadata.FromOutgoingContext(ctx)
+	assert.True(t, in)
+	assert.Len(t, md[compactSignatureKey][0], 64)
+	assert.NotNil(t, md[signatureKey])
+
+	ctx, err = NewSignedContext(NewJWTSigner(peerID.Key()), rq)
+	assert.Nil(t, err)
+	md, in = metadata.FromOutgoingContext(ctx)
+	assert.True(t, in)
+	assert.NotNil(t, md[signatureKey])
+	assert.Nil(t, md[compactSignatureKey])
+
+	// check each format sends just the signatures it should
+	cases := map[SignatureFormat]struct{ jwt, compact bool }{
+		JWTSignatures:     {jwt: true},
+		BothSignatures:    {jwt: true, compact: true},
+		CompactSignatures: {compact: true},
+	}
+	for format, c := range cases {
+		ctx, err = NewSignedContext(NewFormatSigner(peerID.Key(), format), rq)
+		assert.Nil(t, err, format.String())
+		md, in = metadata.FromOutgoingContext(ctx)
+		assert.True(t, in)
+		assert.Equal(t, c.jwt, md[signatureKey] != nil, format.String())
+		assert.Equal(t, c.compact, md[compactSignatureKey] != nil, format.String())
+	}
+
+	ctx, err = NewSignedContext(NewSigner(peerID.Key()), nil)
+	assert.NotNil(t, err)
+	assert.Nil(t, ctx)
+}
+
+func TestNewSignedContext_jwtVerifier(t *testing.T) {
+	rng := rand.New(rand.NewSource(int64(0)))
+	peerID := ecid.NewPseudoRandom(rng)
+	rq := NewFindRequest(peerID, id.NewPseudoRandom(rng), 20)
+
+	// check a verifier that predates compact signatures accepts a request from an upgraded client
+	ctx, err := NewSignedContext(NewSigner(peerID.Key()), rq)
+	assert.Nil(t, err)
+	md, in := metadata.FromOutgoingContext(ctx)
+	assert.True(t, in)
+	encToken, err := FromSignatureContext(metadata.NewIncomingContext(context.Background(), md))
+	assert.Nil(t, err)
+	assert.Nil(t, NewVerifier().Verify(encToken, &peerID.Key().PublicKey, rq))
+}
+
 func TestNewSignedTimeoutContext_ok(t *testing.T) {
 	rng := rand.New(rand.NewSource(int64(0)))
 	ctx, cancel, err := NewSignedTimeoutContext(
@@ -67,3 +67,24 @@ func (*storerCreator) Create(c peer.Connector) (api.Storer, error) {
 	}
 	return lc.(api.Storer), nil
 }
+
+// PingerCreator creates api.Pingers.
+type PingerCreator interface {
+	// Create creates an api.Pinger from the api.Connector.
+	Create(conn peer.Connector) (api.Pinger, error)
+}
+
+type pingerCreator struct{}
+
+// NewPingerCreator creates a new PingerCreator.
+func NewPingerCreator() PingerCreator {
+	return &pingerCreator{}
+}
+
+func (*pingerCreator) Create(c peer.Connector) (api.Pinger, error) {
+	lc, err := c.Connect()
+	if err != nil {
+		return nil, err
+	}
+	return lc.(api.Pinger), nil
+}
@@ -42,3 +42,15 @@ func TestStorerCreator_Create_err(t *testing.T) {
 	_, err := sc.Create(&peer.TestErrConnector{})
 	assert.NotNil(t, err)
 }
+
+func TestPingerCreator_Create_ok(t *testing.T) {
+	pc := NewPingerCreator()
+	_, err := pc.Create(&peer.TestConnector{Client: api.NewLibrarianClient(nil)})
+	assert.Nil(t, err)
+}
+
+func TestPingerCreator_Create_err(t *testing.T) {
+	pc := NewPingerCreator()
+	_, err := pc.Create(&peer.TestErrConnector{})
+	assert.NotNil(t, err)
+}
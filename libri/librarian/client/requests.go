@@ -2,6 +2,7 @@ package client
 
 import (
 	"errors"
+	"time"
 
 	"github.com/drausin/libri/libri/common/ecid"
 	"github.com/drausin/libri/libri/common/id"
@@ -12,17 +13,20 @@ import (
 // request.
 var ErrUnexpectedRequestID = errors.New("response contains unexpected RequestID")
 
-// NewRequestMetadata creates a RequestMetadata object from the peer ID and a random request ID.
-func NewRequestMetadata(peerID ecid.ID) *api.RequestMetadata {
+// NewRequestMetadata creates a RequestMetadata object from the peer ID, a random request ID, and
+// the current time.
+func NewRequestMetadata(peerID ecid.Identity) *api.RequestMetadata {
 	return &api.RequestMetadata{
 		RequestId: id.NewRandom().Bytes(),
 		PubKey:    peerID.PublicKeyBytes(),
+		KeyType:   api.KeyType(peerID.KeyType()),
+		Timestamp: time.Now().UnixNano(),
 	}
 }
 
 // NewIntroduceRequest creates an IntroduceRequest object.
 func NewIntroduceRequest(
-	peerID ecid.ID, apiSelf *api.PeerAddress, nPeers uint,
+	peerID ecid.Identity, apiSelf *api.PeerAddress, nPeers uint,
 ) *api.IntroduceRequest {
 	return &api.IntroduceRequest{
 		Metadata: NewRequestMetadata(peerID),
@@ -32,7 +36,7 @@ func NewIntroduceRequest(
 }
 
 // NewFindRequest creates a FindRequest object.
-func NewFindRequest(peerID ecid.ID, key id.ID, nPeers uint) *api.FindRequest {
+func NewFindRequest(peerID ecid.Identity, key id.ID, nPeers uint) *api.FindRequest {
 	return &api.FindRequest{
 		Metadata: NewRequestMetadata(peerID),
 		Key:      key.Bytes(),
@@ -41,7 +45,7 @@ func NewFindRequest(peerID ecid.ID, key id.ID, nPeers uint) *api.FindRequest {
 }
 
 // NewStoreRequest creates a StoreRequest object.
-func NewStoreRequest(peerID ecid.ID, key id.ID, value *api.Document) *api.StoreRequest {
+func NewStoreRequest(peerID ecid.Identity, key id.ID, value *api.Document) *api.StoreRequest {
 	return &api.StoreRequest{
 		Metadata: NewRequestMetadata(peerID),
 		Key:      key.Bytes(),
@@ -50,7 +54,7 @@ func NewStoreRequest(peerID ecid.ID, key id.ID, value *api.Document) *api.StoreR
 }
 
 // NewGetRequest creates a GetRequest object.
-func NewGetRequest(peerID ecid.ID, key id.ID) *api.GetRequest {
+func NewGetRequest(peerID ecid.Identity, key id.ID) *api.GetRequest {
 	return &api.GetRequest{
 		Metadata: NewRequestMetadata(peerID),
 		Key:      key.Bytes(),
@@ -58,7 +62,7 @@ func NewGetRequest(peerID ecid.ID, key id.ID) *api.GetRequest {
 }
 
 // NewPutRequest creates a PutRequest object.
-func NewPutRequest(peerID ecid.ID, key id.ID, value *api.Document) *api.PutRequest {
+func NewPutRequest(peerID ecid.Identity, key id.ID, value *api.Document) *api.PutRequest {
 	return &api.PutRequest{
 		Metadata: NewRequestMetadata(peerID),
 		Key:      key.Bytes(),
@@ -67,7 +71,9 @@ func NewPutRequest(peerID ecid.ID, key id.ID, value *api.Document) *api.PutReque
 }
 
 // NewSubscribeRequest creates a SubscribeRequest object.
-func NewSubscribeRequest(peerID ecid.ID, subscription *api.Subscription) *api.SubscribeRequest {
+func NewSubscribeRequest(
+	peerID ecid.Identity, subscription *api.Subscription,
+) *api.SubscribeRequest {
 	return &api.SubscribeRequest{
 		Metadata:     NewRequestMetadata(peerID),
 		Subscription: subscription,
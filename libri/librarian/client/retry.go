@@ -1,10 +1,13 @@
 package client
 
 import (
+	"bytes"
 	"time"
 
 	cbackoff "github.com/cenkalti/backoff"
+	"github.com/drausin/libri/libri/common/id"
 	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/golang/protobuf/proto"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 )
@@ -18,13 +21,16 @@ const (
 
 type retryFinder struct {
 	inner   api.Finder
+	signer  Signer
 	timeout time.Duration
 }
 
-// NewRetryFinder creates a new api.Finder with exponential backoff retries.
-func NewRetryFinder(inner api.Finder, timeout time.Duration) api.Finder {
+// NewRetryFinder creates a new api.Finder with exponential backoff retries. Each retry re-signs the
+// request with a fresh request ID and timestamp.
+func NewRetryFinder(inner api.Finder, signer Signer, timeout time.Duration) api.Finder {
 	return &retryFinder{
 		inner:   inner,
+		signer:  signer,
 		timeout: timeout,
 	}
 }
@@ -32,10 +38,22 @@ func NewRetryFinder(inner api.Finder, timeout time.Duration) api.Finder {
 func (r *retryFinder) Find(ctx context.Context, rq *api.FindRequest, opts ...grpc.CallOption) (
 	*api.FindResponse, error) {
 	var rp *api.FindResponse
+	attemptCtx, attemptRq := ctx, rq
 	operation := func() error {
 		var err error
-		rp, err = r.inner.Find(ctx, rq, opts...)
-		return err
+		rp, err = r.inner.Find(attemptCtx, attemptRq, opts...)
+		if err != nil {
+			// librarians reject requests they've already seen, so retry with a re-signed copy
+			var signErr error
+			attemptRq = proto.Clone(rq).(*api.FindRequest)
+			attemptCtx, signErr = resign(ctx, r.signer, attemptRq.Metadata, attemptRq)
+			if signErr != nil {
+				return signErr
+			}
+			return err
+		}
+		restoreRequestID(rp.Metadata, rq.Metadata, attemptRq.Metadata)
+		return nil
 	}
 
 	backoff := newExpBackoff(r.timeout)
@@ -47,13 +65,16 @@ func (r *retryFinder) Find(ctx context.Context, rq *api.FindRequest, opts ...grp
 
 type retryStorer struct {
 	inner   api.Storer
+	signer  Signer
 	timeout time.Duration
 }
 
-// NewRetryStorer creates a new api.Storer with exponential backoff retries.
-func NewRetryStorer(inner api.Storer, timeout time.Duration) api.Storer {
+// NewRetryStorer creates a new api.Storer with exponential backoff retries. Each retry re-signs the
+// request with a fresh request ID and timestamp.
+func NewRetryStorer(inner api.Storer, signer Signer, timeout time.Duration) api.Storer {
 	return &retryStorer{
 		inner:   inner,
+		signer:  signer,
 		timeout: timeout,
 	}
 }
@@ -62,10 +83,22 @@ func (r *retryStorer) Store(ctx context.Context, rq *api.StoreRequest, opts ...g
 	*api.StoreResponse, error) {
 
 	var rp *api.StoreResponse
+	attemptCtx, attemptRq := ctx, rq
 	operation := func() error {
 		var err error
-		rp, err = r.inner.Store(ctx, rq, opts...)
-		return err
+		rp, err = r.inner.Store(attemptCtx, attemptRq, opts...)
+		if err != nil {
+			// librarians reject requests they've already seen, so retry with a re-signed copy
+			var signErr error
+			attemptRq = proto.Clone(rq).(*api.StoreRequest)
+			attemptCtx, signErr = resign(ctx, r.signer, attemptRq.Metadata, attemptRq)
+			if signErr != nil {
+				return signErr
+			}
+			return err
+		}
+		restoreRequestID(rp.Metadata, rq.Metadata, attemptRq.Metadata)
+		return nil
 	}
 
 	backoff := newExpBackoff(r.timeout)
@@ -77,14 +110,17 @@ func (r *retryStorer) Store(ctx context.Context, rq *api.StoreRequest, opts ...g
 
 type retryGetter struct {
 	cb      GetterBalancer
+	signer  Signer
 	timeout time.Duration
 }
 
 // NewRetryGetter wraps a client balancer with an exponential backoff, returning an api.Getter. Each
-// backoff attempt samples a (possibly) different api.Getter to use for the query.
-func NewRetryGetter(cb GetterBalancer, timeout time.Duration) api.Getter {
+// backoff attempt samples a (possibly) different api.Getter to use for the query, and each retry
+// re-signs the request with a fresh request ID and timestamp.
+func NewRetryGetter(cb GetterBalancer, signer Signer, timeout time.Duration) api.Getter {
 	return &retryGetter{
 		cb:      cb,
+		signer:  signer,
 		timeout: timeout,
 	}
 }
@@ -93,14 +129,26 @@ func (r *retryGetter) Get(ctx context.Context, in *api.GetRequest, opts ...grpc.
 	*api.GetResponse, error) {
 
 	var rp *api.GetResponse
+	attemptCtx, attemptIn := ctx, in
 	operation := func() error {
 		var err error
 		lc, err := r.cb.Next()
 		if err != nil {
 			return err
 		}
-		rp, err = lc.Get(ctx, in, opts...)
-		return err
+		rp, err = lc.Get(attemptCtx, attemptIn, opts...)
+		if err != nil {
+			// librarians reject requests they've already seen, so retry with a re-signed copy
+			var signErr error
+			attemptIn = proto.Clone(in).(*api.GetRequest)
+			attemptCtx, signErr = resign(ctx, r.signer, attemptIn.Metadata, attemptIn)
+			if signErr != nil {
+				return signErr
+			}
+			return err
+		}
+		restoreRequestID(rp.Metadata, in.Metadata, attemptIn.Metadata)
+		return nil
 	}
 	if err := cbackoff.Retry(operation, newExpBackoff(r.timeout)); err != nil {
 		return nil, err
@@ -110,13 +158,16 @@ func (r *retryGetter) Get(ctx context.Context, in *api.GetRequest, opts ...grpc.
 
 type retryPutter struct {
 	cb      PutterBalancer
+	signer  Signer
 	timeout time.Duration
 }
 
 // NewRetryPutter wraps a client balancer with an exponential backoff, returning an api.Putter.
-func NewRetryPutter(cb PutterBalancer, timeout time.Duration) api.Putter {
+// Each retry re-signs the request with a fresh request ID and timestamp.
+func NewRetryPutter(cb PutterBalancer, signer Signer, timeout time.Duration) api.Putter {
 	return &retryPutter{
 		cb:      cb,
+		signer:  signer,
 		timeout: timeout,
 	}
 }
@@ -125,14 +176,26 @@ func (r *retryPutter) Put(ctx context.Context, in *api.PutRequest, opts ...grpc.
 	*api.PutResponse, error) {
 
 	var rp *api.PutResponse
+	attemptCtx, attemptIn := ctx, in
 	operation := func() error {
 		var err error
 		lc, err := r.cb.Next()
 		if err != nil {
 			return err
 		}
-		rp, err = lc.Put(ctx, in, opts...)
-		return err
+		rp, err = lc.Put(attemptCtx, attemptIn, opts...)
+		if err != nil {
+			// librarians reject requests they've already seen, so retry with a re-signed copy
+			var signErr error
+			attemptIn = proto.Clone(in).(*api.PutRequest)
+			attemptCtx, signErr = resign(ctx, r.signer, attemptIn.Metadata, attemptIn)
+			if signErr != nil {
+				return signErr
+			}
+			return err
+		}
+		restoreRequestID(rp.Metadata, in.Metadata, attemptIn.Metadata)
+		return nil
 	}
 	if err := cbackoff.Retry(operation, newExpBackoff(r.timeout)); err != nil {
 		return nil, err
@@ -140,6 +203,23 @@ func (r *retryPutter) Put(ctx context.Context, in *api.PutRequest, opts ...grpc.
 	return rp, nil
 }
 
+// resign gives the request metadata a fresh request ID and timestamp and returns a context derived
+// from ctx with the request's new signature.
+func resign(ctx context.Context, signer Signer, meta *api.RequestMetadata, rq proto.Message) (
+	context.Context, error) {
+	meta.RequestId = id.NewRandom().Bytes()
+	meta.Timestamp = time.Now().UnixNano()
+	return newSignedContext(ctx, signer, rq)
+}
+
+// restoreRequestID sets the request ID of a response to a re-signed request back to that of the
+// original request, so callers can still match the response to the request they sent.
+func restoreRequestID(rp *api.ResponseMetadata, original, resigned *api.RequestMetadata) {
+	if rp != nil && bytes.Equal(rp.RequestId, resigned.RequestId) {
+		rp.RequestId = original.RequestId
+	}
+}
+
 func newExpBackoff(timeout time.Duration) *cbackoff.ExponentialBackOff {
 	b := &cbackoff.ExponentialBackOff{
 		InitialInterval:     defaultExpBackoffInitialInterval,
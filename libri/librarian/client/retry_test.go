@@ -6,16 +6,22 @@ import (
 	"testing"
 	"time"
 
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
 	"github.com/drausin/libri/libri/librarian/api"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
 func TestRetryFinder_Find_ok(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	timeout := 100 * time.Millisecond
+	peerID := ecid.NewPseudoRandom(rng)
+	signer := NewSigner(peerID.Key())
+	rq := NewFindRequest(peerID, id.NewPseudoRandom(rng), 8)
 	doc, _ := api.NewTestDocument(rng)
 	err := errors.New("some Find error")
 
@@ -42,8 +48,8 @@ func TestRetryFinder_Find_ok(t *testing.T) {
 	}
 	for i, c := range cases {
 		info := fmt.Sprintf("case %d", i)
-		rg := NewRetryFinder(c, timeout)
-		rp, err := rg.Find(nil, nil) // since .Find() is mocked, inputs don't matter
+		rg := NewRetryFinder(c, signer, timeout)
+		rp, err := rg.Find(context.Background(), rq)
 		assert.Nil(t, err, info)
 		assert.Equal(t, doc, rp.Value, info)
 	}
@@ -52,6 +58,9 @@ func TestRetryFinder_Find_ok(t *testing.T) {
 func TestRetryFinder_Find_err(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	timeout := 100 * time.Millisecond
+	peerID := ecid.NewPseudoRandom(rng)
+	signer := NewSigner(peerID.Key())
+	rq := NewFindRequest(peerID, id.NewPseudoRandom(rng), 8)
 	doc, _ := api.NewTestDocument(rng)
 	err := errors.New("some Find error")
 
@@ -85,15 +94,63 @@ func TestRetryFinder_Find_err(t *testing.T) {
 	}
 	for i, c := range cases {
 		info := fmt.Sprintf("case %d", i)
-		rg := NewRetryFinder(c, timeout)
-		rp, err := rg.Find(nil, nil) // since .Find() is mocked, inputs don't matter
+		rg := NewRetryFinder(c, signer, timeout)
+		rp, err := rg.Find(context.Background(), rq)
 		assert.NotNil(t, err, info)
 		assert.Nil(t, rp, info)
 	}
 }
 
+func TestRetryFinder_Find_resign(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	timeout := 100 * time.Millisecond
+	peerID := ecid.NewPseudoRandom(rng)
+	signer := NewSigner(peerID.Key())
+	rq := NewFindRequest(peerID, id.NewPseudoRandom(rng), 8)
+	rqID, rqTimestamp := rq.Metadata.RequestId, rq.Metadata.Timestamp
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	f := &fixedFinder{
+		responses: []*api.FindResponse{nil, nil, {Metadata: &api.ResponseMetadata{}}},
+		errs:      []error{errors.New("some Find error"), errors.New("some Find error"), nil},
+	}
+
+	rp, err := NewRetryFinder(f, signer, timeout).Find(ctx, rq)
+	assert.Nil(t, err)
+	assert.Len(t, f.requests, 3)
+
+	// first attempt sends original request, and each retry a re-signed copy with fresh ID
+	assert.Equal(t, rq, f.requests[0])
+	assert.Equal(t, ctx, f.ctxs[0])
+	seenIDs := make(map[string]struct{})
+	for i, attemptRq := range f.requests {
+		seenIDs[string(attemptRq.Metadata.RequestId)] = struct{}{}
+		assert.Equal(t, rq.Key, attemptRq.Key)
+		if i > 0 {
+			assert.True(t, attemptRq.Metadata.Timestamp >= rqTimestamp)
+			deadline, ok := f.ctxs[i].Deadline()
+			assert.True(t, ok)
+			expected, _ := ctx.Deadline()
+			assert.Equal(t, expected, deadline)
+			assert.Nil(t, NewVerifier().VerifyCompact(incomingCompactSignature(f.ctxs[i], t),
+				&peerID.Key().PublicKey, attemptRq))
+		}
+	}
+	assert.Len(t, seenIDs, 3)
+
+	// original request is unchanged, and the response matches it
+	assert.Equal(t, rqID, rq.Metadata.RequestId)
+	assert.Equal(t, rqTimestamp, rq.Metadata.Timestamp)
+	assert.Equal(t, rqID, rp.Metadata.RequestId)
+}
+
 func TestRetryStorer_Store_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
 	timeout := 100 * time.Millisecond
+	peerID := ecid.NewPseudoRandom(rng)
+	signer := NewSigner(peerID.Key())
+	doc, key := api.NewTestDocument(rng)
+	rq := NewStoreRequest(peerID, key, doc)
 	err := errors.New("some Store error")
 
 	// check each case ultimately succeeds despite possible initial failures
@@ -119,15 +176,20 @@ func TestRetryStorer_Store_ok(t *testing.T) {
 	}
 	for i, c := range cases {
 		info := fmt.Sprintf("case %d", i)
-		rg := NewRetryStorer(c, timeout)
-		rp, err := rg.Store(nil, nil) // since .Store() is mocked, inputs don't matter
+		rg := NewRetryStorer(c, signer, timeout)
+		rp, err := rg.Store(context.Background(), rq)
 		assert.Nil(t, err, info)
 		assert.Equal(t, &api.StoreResponse{}, rp, info)
 	}
 }
 
 func TestRetryStorer_Store_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
 	timeout := 100 * time.Millisecond
+	peerID := ecid.NewPseudoRandom(rng)
+	signer := NewSigner(peerID.Key())
+	doc, key := api.NewTestDocument(rng)
+	rq := NewStoreRequest(peerID, key, doc)
 	err := errors.New("some Stor error")
 
 	// check each case ultimately fails
@@ -160,8 +222,8 @@ func TestRetryStorer_Store_err(t *testing.T) {
 	}
 	for i, c := range cases {
 		info := fmt.Sprintf("case %d", i)
-		rg := NewRetryStorer(c, timeout)
-		rp, err := rg.Store(nil, nil) // since .Store() is mocked, inputs don't matter
+		rg := NewRetryStorer(c, signer, timeout)
+		rp, err := rg.Store(context.Background(), rq)
 		assert.NotNil(t, err, info)
 		assert.Nil(t, rp, info)
 	}
@@ -170,6 +232,9 @@ func TestRetryStorer_Store_err(t *testing.T) {
 func TestRetryGetter_Get_ok(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	timeout := 100 * time.Millisecond
+	peerID := ecid.NewPseudoRandom(rng)
+	signer := NewSigner(peerID.Key())
+	rq := NewGetRequest(peerID, id.NewPseudoRandom(rng))
 	doc, _ := api.NewTestDocument(rng)
 
 	// check each case ultimately succeeds despite possible initial failures
@@ -201,8 +266,8 @@ func TestRetryGetter_Get_ok(t *testing.T) {
 	}
 	for i, c := range cases {
 		info := fmt.Sprintf("case %d", i)
-		rg := NewRetryGetter(c, timeout)
-		rp, err := rg.Get(nil, nil) // since .Get() is mocked, inputs don't matter
+		rg := NewRetryGetter(c, signer, timeout)
+		rp, err := rg.Get(context.Background(), rq)
 		assert.Nil(t, err, info)
 		assert.Equal(t, doc, rp.Value, info)
 	}
@@ -211,6 +276,9 @@ func TestRetryGetter_Get_ok(t *testing.T) {
 func TestRetryGetter_Get_err(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	timeout := 100 * time.Millisecond
+	peerID := ecid.NewPseudoRandom(rng)
+	signer := NewSigner(peerID.Key())
+	rq := NewGetRequest(peerID, id.NewPseudoRandom(rng))
 	doc, _ := api.NewTestDocument(rng)
 
 	// check each case ultimately fails
@@ -259,15 +327,44 @@ func TestRetryGetter_Get_err(t *testing.T) {
 	}
 	for i, c := range cases {
 		info := fmt.Sprintf("case %d", i)
-		rg := NewRetryGetter(c, timeout)
-		rp, err := rg.Get(nil, nil) // since .Get() is mocked, inputs don't matter
+		rg := NewRetryGetter(c, signer, timeout)
+		rp, err := rg.Get(context.Background(), rq)
 		assert.NotNil(t, err, info)
 		assert.Nil(t, rp, info)
 	}
 }
 
+func TestRetryGetter_Get_resign(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	timeout := 100 * time.Millisecond
+	peerID := ecid.NewPseudoRandom(rng)
+	signer := NewSigner(peerID.Key())
+	rq := NewGetRequest(peerID, id.NewPseudoRandom(rng))
+	rqID := rq.Metadata.RequestId
+	getters := []*fixedGetter{
+		{err: errors.New("some Get error")},
+		{},
+	}
+	cb := &fixedGetterBalancer{clients: []api.Getter{getters[0], getters[1]}}
+
+	rp, err := NewRetryGetter(cb, signer, timeout).Get(context.Background(), rq)
+	assert.Nil(t, err)
+
+	// retry sends a re-signed copy with a fresh ID, and the response matches original request
+	assert.Equal(t, rq, getters[0].request)
+	assert.NotEqual(t, rqID, getters[1].request.Metadata.RequestId)
+	assert.Equal(t, rq.Key, getters[1].request.Key)
+	assert.Equal(t, rqID, rq.Metadata.RequestId)
+	assert.Equal(t, rqID, rp.Metadata.RequestId)
+}
+
 func TestRetryPutter_Put_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
 	timeout := 100 * time.Millisecond
+	peerID := ecid.NewPseudoRandom(rng)
+	signer := NewSigner(peerID.Key())
+	doc, key := api.NewTestDocument(rng)
+	rq := NewPutRequest(peerID, key, doc)
 	response := &api.PutResponse{NReplicas: 3}
 
 	// check each case ultimately succeeds despite possible initial failures
@@ -299,15 +396,20 @@ func TestRetryPutter_Put_ok(t *testing.T) {
 	}
 	for i, c := range cases {
 		info := fmt.Sprintf("case %d", i)
-		rg := NewRetryPutter(c, timeout)
-		rp, err := rg.Put(nil, nil) // since .Put() is mocked, inputs don't matter
+		rg := NewRetryPutter(c, signer, timeout)
+		rp, err := rg.Put(context.Background(), rq)
 		assert.Nil(t, err, info)
 		assert.Equal(t, response, rp, info)
 	}
 }
 
 func TestRetryPutter_Put_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
 	timeout := 100 * time.Millisecond
+	peerID := ecid.NewPseudoRandom(rng)
+	signer := NewSigner(peerID.Key())
+	doc, key := api.NewTestDocument(rng)
+	rq := NewPutRequest(peerID, key, doc)
 	response := &api.PutResponse{NReplicas: 3}
 
 	// check each case ultimately fails
@@ -356,8 +458,8 @@ func TestRetryPutter_Put_err(t *testing.T) {
 	}
 	for i, c := range cases {
 		info := fmt.Sprintf("case %d", i)
-		rg := NewRetryPutter(c, timeout)
-		rp, err := rg.Put(nil, nil) // since .Put() is mocked, inputs don't matter
+		rg := NewRetryPutter(c, signer, timeout)
+		rp, err := rg.Put(context.Background(), rq)
 		assert.NotNil(t, err, info)
 		assert.Nil(t, rp, info)
 	}
@@ -367,14 +469,21 @@ type fixedFinder struct {
 	responses []*api.FindResponse
 	sleep     time.Duration
 	errs      []error
+	requests  []*api.FindRequest
+	ctxs      []context.Context
 }
 
 func (f *fixedFinder) Find(ctx context.Context, rq *api.FindRequest, opts ...grpc.CallOption) (
 	*api.FindResponse, error) {
+	f.requests = append(f.requests, rq)
+	f.ctxs = append(f.ctxs, ctx)
 	if len(f.responses) == 0 {
 		return nil, errors.New("no more responses")
 	}
 	nextRP := f.responses[0]
+	if nextRP != nil && nextRP.Metadata != nil {
+		nextRP.Metadata.RequestId = rq.Metadata.RequestId
+	}
 	nextErr := f.errs[0]
 	f.responses = f.responses[1:]
 	f.errs = f.errs[1:]
@@ -433,12 +542,18 @@ type fixedGetter struct {
 	responseValue *api.Document
 	sleep         time.Duration
 	err           error
+	request       *api.GetRequest
 }
 
 func (f *fixedGetter) Get(ctx context.Context, in *api.GetRequest, opts ...grpc.CallOption) (
 	*api.GetResponse, error) {
+	f.request = in
 	time.Sleep(f.sleep)
-	return &api.GetResponse{Value: f.responseValue}, f.err
+	rp := &api.GetResponse{
+		Metadata: &api.ResponseMetadata{RequestId: in.Metadata.RequestId},
+		Value:    f.responseValue,
+	}
+	return rp, f.err
 }
 
 type fixedPutter struct {
@@ -453,3 +568,12 @@ func (f *fixedPutter) Put(
 	time.Sleep(f.sleep)
 	return f.response, f.err
 }
+
+// incomingCompactSignature gets the compact signature of an outgoing context as the receiver would.
+func incomingCompactSignature(ctx context.Context, t *testing.T) []byte {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	assert.True(t, ok)
+	sig, err := FromCompactSignatureContext(metadata.NewIncomingContext(ctx, md))
+	assert.Nil(t, err)
+	return sig
+}
@@ -2,16 +2,78 @@ package client
 
 import (
 	"bytes"
+	"crypto"
 	"crypto/ecdsa"
+	crand "crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"math/big"
 	"regexp"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/golang/protobuf/proto"
+	"golang.org/x/crypto/ed25519"
 )
 
+var (
+	// ErrInvalidCompactSignature indicates when a compact signature is malformed or does not
+	// verify.
+	ErrInvalidCompactSignature = errors.New("invalid compact signature")
+
+	// ErrUnsupportedKey indicates when a key is neither an ECDSA nor an Ed25519 key.
+	ErrUnsupportedKey = errors.New("unsupported signing key type")
+
+	// ErrUnknownSignatureFormat indicates when a signature format is not one of the supported
+	// formats.
+	ErrUnknownSignatureFormat = errors.New("unknown signature format")
+)
+
+// SignatureFormat is the wire format of the request signatures a CompactSigner sends. Peers
+// accept requests in any of them.
+type SignatureFormat int
+
+const (
+	// JWTSignatures sends only JWT signatures, which all peers verify.
+	JWTSignatures SignatureFormat = iota
+
+	// BothSignatures sends both JWT and compact signatures, for networks still migrating from
+	// peers that predate compact signatures and only verify JWTs.
+	BothSignatures
+
+	// CompactSignatures sends only compact signatures, which are much smaller and cheaper to
+	// create and verify, once all peers verify them.
+	CompactSignatures
+)
+
+// DefaultSignatureFormat is the default signature format, which sends both formats while
+// networks migrate to compact signatures.
+const DefaultSignatureFormat = BothSignatures
+
+var signatureFormatNames = map[SignatureFormat]string{
+	JWTSignatures:     "jwt",
+	BothSignatures:    "both",
+	CompactSignatures: "compact",
+}
+
+func (f SignatureFormat) String() string {
+	if name, in := signatureFormatNames[f]; in {
+		return name
+	}
+	return fmt.Sprintf("SignatureFormat(%d)", int(f))
+}
+
+// ParseSignatureFormat returns the signature format with the given name.
+func ParseSignatureFormat(name string) (SignatureFormat, error) {
+	for f, fName := range signatureFormatNames {
+		if fName == name {
+			return f, nil
+		}
+	}
+	return 0, ErrUnknownSignatureFormat
+}
+
 // regex pattern for a base-64 url-encoded string for a 256-bit number
 var b64url256bit *regexp.Regexp
 
@@ -22,6 +84,9 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+	jwt.RegisterSigningMethod(SigningMethodEdDSA.Alg(), func() jwt.SigningMethod {
+		return SigningMethodEdDSA
+	})
 }
 
 // Claims holds the claims associated with a message signature.
@@ -53,43 +118,115 @@ type Signer interface {
 	Sign(m proto.Message) (string, error)
 }
 
-type ecdsaSigner struct {
-	key *ecdsa.PrivateKey
+// CompactSigner can sign a message with a compact binary signature, which is much smaller and
+// cheaper to verify than a JWT.
+type CompactSigner interface {
+	Signer
+
+	// SignCompact returns the raw signature on the SHA-256 hash of the message: r || s (each
+	// left-padded to the curve byte size) for ECDSA keys and the 64-byte signature for Ed25519
+	// keys.
+	SignCompact(m proto.Message) ([]byte, error)
+
+	// Format returns the format of the signatures sent in signed contexts.
+	Format() SignatureFormat
 }
 
-// NewSigner returns a new Signer instance using the given private key.
-func NewSigner(key *ecdsa.PrivateKey) Signer {
-	return &ecdsaSigner{key}
+type signer struct {
+	key    crypto.Signer
+	method jwt.SigningMethod
 }
 
-func (s *ecdsaSigner) Sign(m proto.Message) (string, error) {
+// NewSigner returns a new CompactSigner instance using the given *ecdsa.PrivateKey (for secp256k1
+// or P-256 keys) or ed25519.PrivateKey. Signed contexts created with it carry signatures in the
+// default format.
+func NewSigner(key crypto.Signer) CompactSigner {
+	return NewFormatSigner(key, DefaultSignatureFormat)
+}
+
+// NewFormatSigner returns a new CompactSigner instance like NewSigner, whose signed contexts carry
+// signatures in the given format.
+func NewFormatSigner(key crypto.Signer, format SignatureFormat) CompactSigner {
+	return &compactSigner{signer: newJWTSigner(key), format: format}
+}
+
+// NewJWTSigner returns a new Signer instance that only signs JWTs, for requests to peers that
+// predate compact signatures. ECDSA keys sign ES256 tokens and Ed25519 keys sign EdDSA tokens.
+func NewJWTSigner(key crypto.Signer) Signer {
+	return newJWTSigner(key)
+}
+
+func newJWTSigner(key crypto.Signer) *signer {
+	var method jwt.SigningMethod = jwt.SigningMethodES256
+	if _, ok := key.(ed25519.PrivateKey); ok {
+		method = SigningMethodEdDSA
+	}
+	return &signer{key: key, method: method}
+}
+
+func (s *signer) Sign(m proto.Message) (string, error) {
 	hash, err := hashMessage(m)
 	if err != nil {
 		return "", err
 	}
 
 	// create token
-	token := jwt.NewWithClaims(jwt.SigningMethodES256, NewSignatureClaims(hash))
+	token := jwt.NewWithClaims(s.method, NewSignatureClaims(hash))
 
 	// sign with key, yield encoded token string like XXXXXX.YYYYYY.ZZZZZZ
 	return token.SignedString(s.key)
 }
 
+type compactSigner struct {
+	*signer
+	format SignatureFormat
+}
+
+func (cs *compactSigner) Format() SignatureFormat {
+	return cs.format
+}
+
+func (cs *compactSigner) SignCompact(m proto.Message) ([]byte, error) {
+	hash, err := hashMessage(m)
+	if err != nil {
+		return nil, err
+	}
+	switch key := cs.key.(type) {
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(crand.Reader, key, hash[:])
+		if err != nil {
+			return nil, err
+		}
+		size := curveByteSize(&key.PublicKey)
+		sig := make([]byte, 2*size)
+		copy(sig[size-len(r.Bytes()):size], r.Bytes())
+		copy(sig[2*size-len(s.Bytes()):], s.Bytes())
+		return sig, nil
+	case ed25519.PrivateKey:
+		return ed25519.Sign(key, hash[:]), nil
+	}
+	return nil, ErrUnsupportedKey
+}
+
 // Verifier verifies the signature on a message.
 type Verifier interface {
-	// Verify verifies that the encoded token is well formed and has been signed by the peer.
-	Verify(encToken string, fromPubKey *ecdsa.PublicKey, m proto.Message) error
+	// Verify verifies that the encoded token is well formed and has been signed by the peer
+	// with the given *ecdsa.PublicKey or ed25519.PublicKey.
+	Verify(encToken string, fromPubKey crypto.PublicKey, m proto.Message) error
+
+	// VerifyCompact verifies that the compact signature has been signed by the peer with the
+	// given *ecdsa.PublicKey or ed25519.PublicKey.
+	VerifyCompact(sig []byte, fromPubKey crypto.PublicKey, m proto.Message) error
 }
 
-type ecsdaVerifier struct{}
+type verifier struct{}
 
 // NewVerifier creates a new Verifier instance.
 func NewVerifier() Verifier {
-	return &ecsdaVerifier{}
+	return &verifier{}
 }
 
-func (v *ecsdaVerifier) Verify(encToken string, fromPubKey *ecdsa.PublicKey,
-	m proto.Message) error {
+func (v *verifier) Verify(encToken string, fromPubKey crypto.PublicKey, m proto.Message) error {
 	token, err := jwt.ParseWithClaims(encToken, &Claims{}, func(token *jwt.Token) (
 		interface{}, error) {
 		return fromPubKey, nil
@@ -107,6 +244,36 @@ func (v *ecsdaVerifier) Verify(encToken string, fromPubKey *ecdsa.PublicKey,
 	return verifyMessageHash(m, claims.Hash)
 }
 
+func (v *verifier) VerifyCompact(sig []byte, fromPubKey crypto.PublicKey, m proto.Message) error {
+	hash, err := hashMessage(m)
+	if err != nil {
+		return err
+	}
+	switch pub := fromPubKey.(type) {
+	case *ecdsa.PublicKey:
+		size := curveByteSize(pub)
+		if len(sig) != 2*size {
+			return ErrInvalidCompactSignature
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(pub, hash[:], r, s) {
+			return ErrInvalidCompactSignature
+		}
+		return nil
+	case ed25519.PublicKey:
+		if len(sig) != ed25519.SignatureSize || !ed25519.Verify(pub, hash[:], sig) {
+			return ErrInvalidCompactSignature
+		}
+		return nil
+	}
+	return ErrUnsupportedKey
+}
+
+func curveByteSize(pub *ecdsa.PublicKey) int {
+	return (pub.Curve.Params().BitSize + 7) / 8
+}
+
 func verifyMessageHash(m proto.Message, encClaimedHash string) error {
 	messageHash, err := hashMessage(m)
 	if err != nil {
@@ -131,3 +298,35 @@ func hashMessage(m proto.Message) ([sha256.Size]byte, error) {
 	}
 	return sha256.Sum256(buf), nil
 }
+
+// SigningMethodEdDSA signs and verifies JWTs with Ed25519 keys.
+var SigningMethodEdDSA = &signingMethodEdDSA{}
+
+type signingMethodEdDSA struct{}
+
+func (*signingMethodEdDSA) Alg() string {
+	return "EdDSA"
+}
+
+func (*signingMethodEdDSA) Sign(signingString string, key interface{}) (string, error) {
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return "", jwt.ErrInvalidKeyType
+	}
+	return jwt.EncodeSegment(ed25519.Sign(priv, []byte(signingString))), nil
+}
+
+func (*signingMethodEdDSA) Verify(signingString, signature string, key interface{}) error {
+	pub, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return jwt.ErrInvalidKeyType
+	}
+	sig, err := jwt.DecodeSegment(signature)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(pub, []byte(signingString), sig) {
+		return jwt.ErrSignatureInvalid
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"crypto"
 	"math/rand"
 	"testing"
 
@@ -109,6 +110,147 @@ func TestEcdsaVerifer_Verify_err(t *testing.T) {
 	})
 }
 
+func TestSignerVerifier_SignVerify_keyTypes(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	value, key := api.NewTestDocument(rng)
+	verifier := NewVerifier()
+	keyTypes := []ecid.KeyType{ecid.KeyTypeSecp256k1, ecid.KeyTypeP256, ecid.KeyTypeEd25519}
+	for _, kt := range keyTypes {
+		peerID, err := ecid.NewPseudoRandomIdentity(rng, kt)
+		assert.Nil(t, err)
+		signer := NewSigner(peerID.Signer())
+		message := NewStoreRequest(peerID, key, value)
+
+		encToken, err := signer.Sign(message)
+		assert.Nil(t, err)
+		pubKey, err := ecid.ParsePublicKey(kt, peerID.PublicKeyBytes())
+		assert.Nil(t, err)
+		assert.Nil(t, verifier.Verify(encToken, pubKey, message), kt.String())
+
+		// check different message doesn't verify
+		assert.NotNil(t, verifier.Verify(encToken, pubKey, NewGetRequest(peerID, key)))
+	}
+}
+
+func TestSignerVerifier_SignVerify_keyTypeMismatch(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	key := id.NewPseudoRandom(rng)
+	verifier := NewVerifier()
+	edID, err := ecid.NewPseudoRandomIdentity(rng, ecid.KeyTypeEd25519)
+	assert.Nil(t, err)
+	ecID := ecid.NewPseudoRandom(rng)
+	message := NewGetRequest(ecID, key)
+
+	// check EdDSA token doesn't verify with ECDSA public key
+	encToken, err := NewSigner(edID.Signer()).Sign(message)
+	assert.Nil(t, err)
+	assert.NotNil(t, verifier.Verify(encToken, &ecID.Key().PublicKey, message))
+
+	// check ES256 token doesn't verify with Ed25519 public key
+	encToken, err = NewSigner(ecID.Key()).Sign(message)
+	assert.Nil(t, err)
+	edPub, err := ecid.ParsePublicKey(ecid.KeyTypeEd25519, edID.PublicKeyBytes())
+	assert.Nil(t, err)
+	assert.NotNil(t, verifier.Verify(encToken, edPub, message))
+}
+
+func TestSigningMethodEdDSA_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	ecID := ecid.NewPseudoRandom(rng)
+
+	// check non-Ed25519 keys trigger errors
+	_, err := SigningMethodEdDSA.Sign("some signing string", ecID.Key())
+	assert.NotNil(t, err)
+	err = SigningMethodEdDSA.Verify("some signing string", "c29tZSBzaWc", &ecID.Key().PublicKey)
+	assert.NotNil(t, err)
+
+	// check bad signature triggers error
+	edID, err := ecid.NewPseudoRandomIdentity(rng, ecid.KeyTypeEd25519)
+	assert.Nil(t, err)
+	edPub, err := ecid.ParsePublicKey(ecid.KeyTypeEd25519, edID.PublicKeyBytes())
+	assert.Nil(t, err)
+	err = SigningMethodEdDSA.Verify("some signing string", "c29tZSBzaWc", edPub)
+	assert.NotNil(t, err)
+	err = SigningMethodEdDSA.Verify("some signing string", "not base-64 *&*&", edPub)
+	assert.NotNil(t, err)
+}
+
+func TestCompactSignerVerifier_SignVerify_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	value, key := api.NewTestDocument(rng)
+	verifier := NewVerifier()
+	keyTypes := []ecid.KeyType{ecid.KeyTypeSecp256k1, ecid.KeyTypeP256, ecid.KeyTypeEd25519}
+	for _, kt := range keyTypes {
+		peerID, err := ecid.NewPseudoRandomIdentity(rng, kt)
+		assert.Nil(t, err)
+		signer := NewSigner(peerID.Signer())
+		pubKey, err := ecid.ParsePublicKey(kt, peerID.PublicKeyBytes())
+		assert.Nil(t, err)
+
+		cases := []proto.Message{
+			NewFindRequest(peerID, key, 20),
+			NewStoreRequest(peerID, key, value),
+			NewGetRequest(peerID, key),
+			NewPutRequest(peerID, key, value),
+		}
+		for _, c := range cases {
+			sig, err := signer.SignCompact(c)
+			assert.Nil(t, err)
+			assert.Len(t, sig, 64)
+			assert.Nil(t, verifier.VerifyCompact(sig, pubKey, c), kt.String())
+		}
+	}
+}
+
+func TestCompactSigner_SignCompact_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID := ecid.NewPseudoRandom(rng)
+
+	cs := NewSigner(peerID.Key())
+	sig, err := cs.SignCompact(nil)
+	assert.NotNil(t, err) // protobuf needs to be not-nil
+	assert.Nil(t, sig)
+
+	noKeySigner := &compactSigner{signer: &signer{}}
+	sig, err = noKeySigner.SignCompact(NewGetRequest(peerID, id.NewPseudoRandom(rng)))
+	assert.Equal(t, ErrUnsupportedKey, err)
+	assert.Nil(t, sig)
+}
+
+func TestCompactVerifier_VerifyCompact_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID := ecid.NewPseudoRandom(rng)
+	edID, err := ecid.NewPseudoRandomIdentity(rng, ecid.KeyTypeEd25519)
+	assert.Nil(t, err)
+	edPub, err := ecid.ParsePublicKey(ecid.KeyTypeEd25519, edID.PublicKeyBytes())
+	assert.Nil(t, err)
+	key := id.NewPseudoRandom(rng)
+
+	verifier := NewVerifier()
+	message := NewFindRequest(peerID, key, 20)
+	sig, err := NewSigner(peerID.Key()).SignCompact(message)
+	assert.Nil(t, err)
+	pubKey := &peerID.Key().PublicKey
+
+	// none of these should verify
+	errCases := []struct {
+		sig    []byte
+		pubKey crypto.PublicKey
+		m      proto.Message
+	}{
+		{sig, pubKey, nil},                                         // nil message
+		{nil, pubKey, message},                                     // nil signature
+		{sig[1:], pubKey, message},                                 // wrong length
+		{sig, pubKey, NewFindRequest(peerID, key, 10)},             // different message
+		{sig, &ecid.NewPseudoRandom(rng).Key().PublicKey, message}, // different peer
+		{sig, edPub, message},                                      // different key type
+		{sig, "not a public key", message},                         // unsupported key
+	}
+	for i, c := range errCases {
+		assert.NotNil(t, verifier.VerifyCompact(c.sig, c.pubKey, c.m), i)
+	}
+}
+
 func TestTestNoOpSigner_Sign(t *testing.T) {
 	s := &TestNoOpSigner{}
 	token, err := s.Sign(nil)
@@ -122,3 +264,14 @@ func TestTestErrSigner_Sign(t *testing.T) {
 	assert.Equal(t, "", token)
 	assert.NotNil(t, err)
 }
+
+func TestParseSignatureFormat(t *testing.T) {
+	for _, format := range []SignatureFormat{JWTSignatures, BothSignatures, CompactSignatures} {
+		parsed, err := ParseSignatureFormat(format.String())
+		assert.Nil(t, err)
+		assert.Equal(t, format, parsed)
+	}
+	_, err := ParseSignatureFormat("bad format")
+	assert.Equal(t, ErrUnknownSignatureFormat, err)
+	assert.Equal(t, "SignatureFormat(5)", SignatureFormat(5).String())
+}
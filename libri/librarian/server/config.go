@@ -7,9 +7,12 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/drausin/libri/libri/common/ecid"
 	"github.com/drausin/libri/libri/common/errors"
 	"github.com/drausin/libri/libri/common/subscribe"
+	"github.com/drausin/libri/libri/librarian/client"
 	"github.com/drausin/libri/libri/librarian/server/introduce"
+	"github.com/drausin/libri/libri/librarian/server/peer"
 	"github.com/drausin/libri/libri/librarian/server/routing"
 	"github.com/drausin/libri/libri/librarian/server/search"
 	"github.com/drausin/libri/libri/librarian/server/store"
@@ -80,6 +83,36 @@ type Config struct {
 	// SubscribeFrom defines parameters for subscriptions to other peers.
 	SubscribeFrom *subscribe.FromParameters
 
+	// Verify defines parameters for verifying requests to the server.
+	Verify *VerifyParameters
+
+	// Limits defines the request rate limits and byte quotas applied to peers.
+	Limits *LimitParameters
+
+	// Reputation defines how peers are scored and blacklisted for misbehavior.
+	Reputation *peer.ReputationParameters
+
+	// Refresh defines how routing table buckets without recent lookups are refreshed.
+	Refresh *RefreshParameters
+
+	// PathCache defines how found documents are cached on peers along lookup paths.
+	PathCache *PathCacheParameters
+
+	// HotCache defines the memory limits of the in-memory cache of recently loaded documents.
+	HotCache *HotCacheParameters
+
+	// ReadRepair defines how missing replicas found during Gets are repaired.
+	ReadRepair *ReadRepairParameters
+
+	// Consistency defines the bounds on the consistency levels Put and Get requests may ask for.
+	Consistency *ConsistencyParameters
+
+	// KeyType is the key type of the peer ID, used when creating a new one.
+	KeyType ecid.KeyType
+
+	// SignatureFormat is the wire format of the signatures on requests to other peers.
+	SignatureFormat client.SignatureFormat
+
 	// LogLevel is the log level
 	LogLevel zapcore.Level
 }
@@ -103,6 +136,16 @@ func NewDefaultConfig() *Config {
 	config.WithDefaultStore()
 	config.WithDefaultSubscribeTo()
 	config.WithDefaultSubscribeFrom()
+	config.WithDefaultVerify()
+	config.WithDefaultLimits()
+	config.WithDefaultReputation()
+	config.WithDefaultRefresh()
+	config.WithDefaultPathCache()
+	config.WithDefaultHotCache()
+	config.WithDefaultReadRepair()
+	config.WithDefaultConsistency()
+	config.WithDefaultKeyType()
+	config.WithDefaultSignatureFormat()
 	config.WithDefaultLogLevel()
 
 	return config
@@ -334,6 +377,156 @@ func (c *Config) WithDefaultSubscribeFrom() *Config {
 	return c
 }
 
+// WithVerify sets the request verification parameters to the given value or the default if it is
+// nil.
+func (c *Config) WithVerify(params *VerifyParameters) *Config {
+	if params == nil {
+		return c.WithDefaultVerify()
+	}
+	c.Verify = params
+	return c
+}
+
+// WithDefaultVerify sets the request verification parameters to the default.
+func (c *Config) WithDefaultVerify() *Config {
+	c.Verify = NewDefaultVerifyParameters()
+	return c
+}
+
+// WithLimits sets the rate limit and quota parameters to the given value or the default if it is
+// nil.
+func (c *Config) WithLimits(params *LimitParameters) *Config {
+	if params == nil {
+		return c.WithDefaultLimits()
+	}
+	c.Limits = params
+	return c
+}
+
+// WithDefaultLimits sets the rate limit and quota parameters to the default.
+func (c *Config) WithDefaultLimits() *Config {
+	c.Limits = NewDefaultLimitParameters()
+	return c
+}
+
+// WithReputation sets the peer reputation parameters to the given value or the default if it is
+// nil.
+func (c *Config) WithReputation(params *peer.ReputationParameters) *Config {
+	if params == nil {
+		return c.WithDefaultReputation()
+	}
+	c.Reputation = params
+	return c
+}
+
+// WithDefaultReputation sets the peer reputation parameters to the default.
+func (c *Config) WithDefaultReputation() *Config {
+	c.Reputation = peer.NewDefaultReputationParameters()
+	return c
+}
+
+// WithRefresh sets the routing table refresh parameters to the given value or the default if it is
+// nil.
+func (c *Config) WithRefresh(params *RefreshParameters) *Config {
+	if params == nil {
+		return c.WithDefaultRefresh()
+	}
+	c.Refresh = params
+	return c
+}
+
+// WithDefaultRefresh sets the routing table refresh parameters to the default.
+func (c *Config) WithDefaultRefresh() *Config {
+	c.Refresh = NewDefaultRefreshParameters()
+	return c
+}
+
+// WithPathCache sets the path caching parameters to the given value or the default if it is nil.
+func (c *Config) WithPathCache(params *PathCacheParameters) *Config {
+	if params == nil {
+		return c.WithDefaultPathCache()
+	}
+	c.PathCache = params
+	return c
+}
+
+// WithDefaultPathCache sets the path caching parameters to the default.
+func (c *Config) WithDefaultPathCache() *Config {
+	c.PathCache = NewDefaultPathCacheParameters()
+	return c
+}
+
+// WithHotCache sets the hot document cache parameters to the given value or the default if it is
+// nil.
+func (c *Config) WithHotCache(params *HotCacheParameters) *Config {
+	if params == nil {
+		return c.WithDefaultHotCache()
+	}
+	c.HotCache = params
+	return c
+}
+
+// WithDefaultHotCache sets the hot document cache parameters to the default.
+func (c *Config) WithDefaultHotCache() *Config {
+	c.HotCache = NewDefaultHotCacheParameters()
+	return c
+}
+
+// WithReadRepair sets the read repair parameters to the given value or the default if it is nil.
+func (c *Config) WithReadRepair(params *ReadRepairParameters) *Config {
+	if params == nil {
+		return c.WithDefaultReadRepair()
+	}
+	c.ReadRepair = params
+	return c
+}
+
+// WithDefaultReadRepair sets the read repair parameters to the default.
+func (c *Config) WithDefaultReadRepair() *Config {
+	c.ReadRepair = NewDefaultReadRepairParameters()
+	return c
+}
+
+// WithConsistency sets the consistency level bounds to the given value or the default if it is
+// nil.
+func (c *Config) WithConsistency(params *ConsistencyParameters) *Config {
+	if params == nil {
+		return c.WithDefaultConsistency()
+	}
+	c.Consistency = params
+	return c
+}
+
+// WithDefaultConsistency sets the consistency level bounds to the default.
+func (c *Config) WithDefaultConsistency() *Config {
+	c.Consistency = NewDefaultConsistencyParameters()
+	return c
+}
+
+// WithKeyType sets the key type of new peer IDs to the given value.
+func (c *Config) WithKeyType(keyType ecid.KeyType) *Config {
+	c.KeyType = keyType
+	return c
+}
+
+// WithDefaultKeyType sets the key type of new peer IDs to secp256k1.
+func (c *Config) WithDefaultKeyType() *Config {
+	c.KeyType = ecid.KeyTypeSecp256k1
+	return c
+}
+
+// WithSignatureFormat sets the wire format of request signatures to the given value.
+func (c *Config) WithSignatureFormat(format client.SignatureFormat) *Config {
+	c.SignatureFormat = format
+	return c
+}
+
+// WithDefaultSignatureFormat sets the wire format of request signatures to the default.
+func (c *Config) WithDefaultSignatureFormat() *Config {
+	c.SignatureFormat = client.DefaultSignatureFormat
+	return c
+}
+
 // WithLogLevel sets the log level to the given value, though this doesn't have any direct effect
 // on the creation of the logger instance.
 func (c *Config) WithLogLevel(logLevel zapcore.Level) *Config {
@@ -3,9 +3,11 @@ package server
 import (
 	"net"
 	"testing"
+	"time"
 
 	"github.com/drausin/libri/libri/common/subscribe"
 	"github.com/drausin/libri/libri/librarian/server/introduce"
+	"github.com/drausin/libri/libri/librarian/server/peer"
 	"github.com/drausin/libri/libri/librarian/server/routing"
 	"github.com/drausin/libri/libri/librarian/server/search"
 	"github.com/drausin/libri/libri/librarian/server/store"
@@ -28,6 +30,14 @@ func TestDefaultConfig(t *testing.T) {
 	assert.NotEmpty(t, c.Store)
 	assert.NotEmpty(t, c.SubscribeTo)
 	assert.NotEmpty(t, c.SubscribeFrom)
+	assert.NotEmpty(t, c.Verify)
+	assert.NotEmpty(t, c.Limits)
+	assert.NotEmpty(t, c.Reputation)
+	assert.NotEmpty(t, c.Refresh)
+	assert.NotEmpty(t, c.PathCache)
+	assert.NotEmpty(t, c.HotCache)
+	assert.NotEmpty(t, c.ReadRepair)
+	assert.NotEmpty(t, c.Consistency)
 	assert.NotEmpty(t, c.LogLevel)
 }
 
@@ -154,6 +164,86 @@ func TestConfig_WithSubscribeFrom(t *testing.T) {
 	)
 }
 
+func TestConfig_WithVerify(t *testing.T) {
+	c1, c2, c3 := &Config{}, &Config{}, &Config{}
+	c1.WithDefaultVerify()
+	assert.Equal(t, c1.Verify, c2.WithVerify(nil).Verify)
+	assert.NotEqual(t,
+		c1.Verify,
+		c3.WithVerify(&VerifyParameters{MaxClockSkew: time.Minute}).Verify,
+	)
+}
+
+func TestConfig_WithLimits(t *testing.T) {
+	c1, c2, c3 := &Config{}, &Config{}, &Config{}
+	c1.WithDefaultLimits()
+	assert.Equal(t, c1.Limits, c2.WithLimits(nil).Limits)
+	assert.NotEqual(t,
+		c1.Limits,
+		c3.WithLimits(&LimitParameters{AuthorQuota: 1}).Limits,
+	)
+}
+
+func TestConfig_WithReputation(t *testing.T) {
+	c1, c2, c3 := &Config{}, &Config{}, &Config{}
+	c1.WithDefaultReputation()
+	assert.Equal(t, c1.Reputation, c2.WithReputation(nil).Reputation)
+	assert.NotEqual(t,
+		c1.Reputation,
+		c3.WithReputation(&peer.ReputationParameters{BlacklistThreshold: 1}).Reputation,
+	)
+}
+
+func TestConfig_WithRefresh(t *testing.T) {
+	c1, c2, c3 := &Config{}, &Config{}, &Config{}
+	c1.WithDefaultRefresh()
+	assert.Equal(t, c1.Refresh, c2.WithRefresh(nil).Refresh)
+	assert.NotEqual(t,
+		c1.Refresh,
+		c3.WithRefresh(&RefreshParameters{Concurrency: 1}).Refresh,
+	)
+}
+
+func TestConfig_WithPathCache(t *testing.T) {
+	c1, c2, c3 := &Config{}, &Config{}, &Config{}
+	c1.WithDefaultPathCache()
+	assert.Equal(t, c1.PathCache, c2.WithPathCache(nil).PathCache)
+	assert.NotEqual(t,
+		c1.PathCache,
+		c3.WithPathCache(&PathCacheParameters{Enabled: true}).PathCache,
+	)
+}
+
+func TestConfig_WithHotCache(t *testing.T) {
+	c1, c2, c3 := &Config{}, &Config{}, &Config{}
+	c1.WithDefaultHotCache()
+	assert.Equal(t, c1.HotCache, c2.WithHotCache(nil).HotCache)
+	assert.NotEqual(t,
+		c1.HotCache,
+		c3.WithHotCache(&HotCacheParameters{MaxBytes: 1}).HotCache,
+	)
+}
+
+func TestConfig_WithReadRepair(t *testing.T) {
+	c1, c2, c3 := &Config{}, &Config{}, &Config{}
+	c1.WithDefaultReadRepair()
+	assert.Equal(t, c1.ReadRepair, c2.WithReadRepair(nil).ReadRepair)
+	assert.NotEqual(t,
+		c1.ReadRepair,
+		c3.WithReadRepair(&ReadRepairParameters{Rate: 1}).ReadRepair,
+	)
+}
+
+func TestConfig_WithConsistency(t *testing.T) {
+	c1, c2, c3 := &Config{}, &Config{}, &Config{}
+	c1.WithDefaultConsistency()
+	assert.Equal(t, c1.Consistency, c2.WithConsistency(nil).Consistency)
+	assert.NotEqual(t,
+		c1.Consistency,
+		c3.WithConsistency(&ConsistencyParameters{MaxNReplicas: 16}).Consistency,
+	)
+}
+
 func TestConfig_WithLogLevel(t *testing.T) {
 	c1, c2, c3 := &Config{}, &Config{}, &Config{}
 	c1.WithDefaultLogLevel()
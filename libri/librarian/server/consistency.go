@@ -0,0 +1,105 @@
+package server
+
+import (
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/drausin/libri/libri/librarian/server/search"
+	"github.com/drausin/libri/libri/librarian/server/store"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// DefaultMinNReplicas is the default minimum number of replicas a Put request may require.
+	DefaultMinNReplicas = uint(1)
+
+	// DefaultMaxNReplicas is the default maximum number of replicas a Put request may require.
+	DefaultMaxNReplicas = uint(8)
+
+	// DefaultMinNMatchingValues is the default minimum number of matching values a Get request
+	// may require.
+	DefaultMinNMatchingValues = uint(1)
+
+	// DefaultMaxNMatchingValues is the default maximum number of matching values a Get request
+	// may require.
+	DefaultMaxNMatchingValues = uint(5)
+
+	// logging keys
+	logMinNReplicas       = "min_n_replicas"
+	logMaxNReplicas       = "max_n_replicas"
+	logMinNMatchingValues = "min_n_matching_values"
+	logMaxNMatchingValues = "max_n_matching_values"
+)
+
+// ConsistencyParameters define the bounds on the consistency levels Put and Get requests may ask
+// for instead of the librarian's configured store and search parameters.
+type ConsistencyParameters struct {
+	// MinNReplicas is the minimum number of replicas a Put request may require.
+	MinNReplicas uint
+
+	// MaxNReplicas is the maximum number of replicas a Put request may require.
+	MaxNReplicas uint
+
+	// MinNMatchingValues is the minimum number of matching values a Get request may require.
+	MinNMatchingValues uint
+
+	// MaxNMatchingValues is the maximum number of matching values a Get request may require.
+	MaxNMatchingValues uint
+}
+
+// NewDefaultConsistencyParameters creates an instance with default parameters.
+func NewDefaultConsistencyParameters() *ConsistencyParameters {
+	return &ConsistencyParameters{
+		MinNReplicas:       DefaultMinNReplicas,
+		MaxNReplicas:       DefaultMaxNReplicas,
+		MinNMatchingValues: DefaultMinNMatchingValues,
+		MaxNMatchingValues: DefaultMaxNMatchingValues,
+	}
+}
+
+// MarshalLogObject converts the ConsistencyParameters into an object (which will become json) for
+// logging.
+func (p *ConsistencyParameters) MarshalLogObject(oe zapcore.ObjectEncoder) error {
+	oe.AddUint(logMinNReplicas, p.MinNReplicas)
+	oe.AddUint(logMaxNReplicas, p.MaxNReplicas)
+	oe.AddUint(logMinNMatchingValues, p.MinNMatchingValues)
+	oe.AddUint(logMaxNMatchingValues, p.MaxNMatchingValues)
+	return nil
+}
+
+// putStoreParameters returns the store parameters for the Put request, which are the configured
+// ones unless the request asks for a number of replicas.
+func (l *Librarian) putStoreParameters(rq *api.PutRequest) *store.Parameters {
+	if rq.NReplicas == 0 {
+		return l.config.Store
+	}
+	params := *l.config.Store // by value to avoid changing configured params
+	params.NReplicas = clamp(uint(rq.NReplicas), l.config.Consistency.MinNReplicas,
+		l.config.Consistency.MaxNReplicas)
+	return &params
+}
+
+// getSearchParameters returns the search parameters for the Get request, which are the configured
+// ones unless the request asks for a number of matching values.
+func (l *Librarian) getSearchParameters(rq *api.GetRequest) *search.Parameters {
+	if rq.NMatchingValues == 0 {
+		return l.config.Search
+	}
+	params := *l.config.Search // by value to avoid changing configured params
+	params.NMatchingValues = clamp(uint(rq.NMatchingValues),
+		l.config.Consistency.MinNMatchingValues, l.config.Consistency.MaxNMatchingValues)
+	if params.NClosestResponses < params.NMatchingValues {
+		// otherwise search may find the closest peers before enough of them return the value
+		params.NClosestResponses = params.NMatchingValues
+	}
+	return &params
+}
+
+// clamp returns the value bounded below by lower and above by upper.
+func clamp(value, lower, upper uint) uint {
+	if value < lower {
+		return lower
+	}
+	if value > upper {
+		return upper
+	}
+	return value
+}
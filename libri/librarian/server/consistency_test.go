@@ -0,0 +1,70 @@
+package server
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/drausin/libri/libri/librarian/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLibrarian_putStoreParameters(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	value, key := api.NewTestDocument(rng)
+	l := &Librarian{config: NewDefaultConfig()}
+	l.config.Consistency.MinNReplicas, l.config.Consistency.MaxNReplicas = 2, 6
+	rq := client.NewPutRequest(ecid.NewPseudoRandom(rng), key, value)
+	nReplicas := l.config.Store.NReplicas
+
+	// no requested number of replicas uses configured params
+	assert.Equal(t, l.config.Store, l.putStoreParameters(rq))
+
+	cases := map[uint32]uint{
+		1: 2, // clamped to min
+		5: 5,
+		9: 6, // clamped to max
+	}
+	for nRequested, nExpected := range cases {
+		rq.NReplicas = nRequested
+		params := l.putStoreParameters(rq)
+		assert.Equal(t, nExpected, params.NReplicas)
+		assert.Equal(t, l.config.Store.NMaxErrors, params.NMaxErrors)
+	}
+	assert.Equal(t, nReplicas, l.config.Store.NReplicas) // configured params unchanged
+}
+
+func TestLibrarian_getSearchParameters(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	l := &Librarian{config: NewDefaultConfig()}
+	l.config.Consistency.MinNMatchingValues, l.config.Consistency.MaxNMatchingValues = 1, 4
+	rq := client.NewGetRequest(ecid.NewPseudoRandom(rng), id.NewPseudoRandom(rng))
+	nClosest := l.config.Search.NClosestResponses
+
+	// no requested number of matching values uses configured params
+	assert.Equal(t, l.config.Search, l.getSearchParameters(rq))
+
+	cases := map[uint32]struct {
+		nMatching uint
+		nClosest  uint
+	}{
+		2: {nMatching: 2, nClosest: nClosest},
+		4: {nMatching: 4, nClosest: 4}, // more closest responses to find enough matches
+		9: {nMatching: 4, nClosest: 4}, // clamped to max
+	}
+	for nRequested, c := range cases {
+		rq.NMatchingValues = nRequested
+		params := l.getSearchParameters(rq)
+		assert.Equal(t, c.nMatching, params.NMatchingValues)
+		assert.Equal(t, c.nClosest, params.NClosestResponses)
+	}
+	assert.Equal(t, nClosest, l.config.Search.NClosestResponses) // configured params unchanged
+}
+
+func TestClamp(t *testing.T) {
+	assert.Equal(t, uint(2), clamp(1, 2, 4))
+	assert.Equal(t, uint(3), clamp(3, 2, 4))
+	assert.Equal(t, uint(4), clamp(5, 2, 4))
+}
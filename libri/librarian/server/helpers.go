@@ -1,23 +1,36 @@
 package server
 
 import (
+	"bytes"
+	"net"
+	"time"
+
 	"github.com/drausin/libri/libri/common/ecid"
 	"github.com/drausin/libri/libri/common/id"
 	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/drausin/libri/libri/librarian/client"
 	"github.com/drausin/libri/libri/librarian/server/peer"
+	"github.com/drausin/libri/libri/librarian/server/search"
 	"github.com/golang/protobuf/proto"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcpeer "google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
-// newStubPeerFromPublicKeyBytes creates a new stub peer with an ID coming from an ECDSA public key.
-func newIDFromPublicKeyBytes(pubKeyBytes []byte) (id.ID, error) {
-	pubKey, err := ecid.FromPublicKeyBytes(pubKeyBytes)
+// ErrBlacklisted indicates when a request comes from a blacklisted peer.
+var ErrBlacklisted = status.Error(codes.PermissionDenied, "peer is blacklisted")
+
+// newIDFromPublicKeyBytes creates a new ID from a public key of the given key type.
+func newIDFromPublicKeyBytes(keyType api.KeyType, pubKeyBytes []byte) (id.ID, error) {
+	pubKey, err := ecid.ParsePublicKey(ecid.KeyType(keyType), pubKeyBytes)
 	if err != nil {
 		return nil, err
 	}
-	return id.FromPublicKey(pubKey), nil
+	return ecid.PublicKeyID(pubKey)
 }
 
 // NewResponseMetadata creates a new api.ResponseMatadata object with the same RequestID as that
@@ -26,6 +39,7 @@ func (l *Librarian) NewResponseMetadata(m *api.RequestMetadata) *api.ResponseMet
 	return &api.ResponseMetadata{
 		RequestId: m.RequestId,
 		PubKey:    l.selfID.PublicKeyBytes(),
+		KeyType:   api.KeyType(l.selfID.KeyType()),
 	}
 }
 
@@ -33,14 +47,23 @@ func (l *Librarian) NewResponseMetadata(m *api.RequestMetadata) *api.ResponseMet
 // returns the ID of the requester or an error.
 func (l *Librarian) checkRequest(ctx context.Context, rq proto.Message, meta *api.RequestMetadata) (
 	id.ID, error) {
-	requesterID, err := newIDFromPublicKeyBytes(meta.PubKey)
+	requesterID, err := newIDFromPublicKeyBytes(meta.KeyType, meta.PubKey)
 	if err != nil {
 		return nil, err
 	}
+	if l.reps.Blacklisted(requesterID) {
+		return nil, ErrBlacklisted
+	}
 
 	// record request verification issue, if it exists
 	if err := l.rqv.Verify(ctx, rq, meta); err != nil {
 		l.record(requesterID, peer.Request, peer.Error)
+
+		// anyone can claim a peer's public key, so only penalize requests from its known
+		// address; stale requests are more likely clock skew than misbehavior
+		if err != ErrStaleRequest && l.fromRoutingAddress(ctx, requesterID) {
+			l.penalize(requesterID, peer.InvalidSignature)
+		}
 		return nil, err
 	}
 	return requesterID, nil
@@ -69,17 +92,47 @@ func (l *Librarian) checkRequestAndKeyValue(ctx context.Context, rq proto.Messag
 	if err != nil {
 		return nil, err
 	}
+	if err := api.ValidateDocument(value); err != nil {
+		l.record(requester, peer.Request, peer.Error)
+		l.penalize(requester, peer.InvalidDocument)
+		return nil, err
+	}
 	valueBytes, err := proto.Marshal(value)
 	if err != nil {
 		return nil, err
 	}
-	if err := l.kvc.Check(key, valueBytes); err != nil {
+	kvc := l.kvc
+	if _, ok := value.Contents.(*api.Document_Pointer); ok {
+		kvc = l.pkvc
+	}
+	if err := kvc.Check(key, valueBytes); err != nil {
 		l.record(requester, peer.Request, peer.Error)
+		l.penalize(requester, peer.InvalidValue)
 		return nil, err
 	}
 	return requester, nil
 }
 
+// checkLimits checks that the requester is within its rate limit for the endpoint and that the
+// value's author is within its byte quota.
+func (l *Librarian) checkLimits(requesterID id.ID, e Endpoint, value *api.Document) error {
+	if err := l.limiter.AllowRequest(requesterID, e); err != nil {
+		return err
+	}
+	return l.limiter.AllowBytes(api.GetAuthorPub(value), e, proto.Size(value))
+}
+
+// checkStoreLimits checks the limits for a Store request. Stores from librarians in the routing
+// table mostly replicate, repair, or cache documents, so they have their own request budget, but
+// since any peer can join the routing table, they are still charged author quota for the bytes
+// stored here.
+func (l *Librarian) checkStoreLimits(requesterID id.ID, value *api.Document) error {
+	if _, in := l.rt.Get(requesterID); in {
+		return l.checkLimits(requesterID, LibrarianStoreEndpoint, value)
+	}
+	return l.checkLimits(requesterID, StoreEndpoint, value)
+}
+
 // record records query outcome for a particular peer if that peer is in the routing table.
 func (l *Librarian) record(fromPeerID id.ID, t peer.QueryType, o peer.Outcome) {
 	if fromPeer, exists := l.rt.Get(fromPeerID); exists {
@@ -92,6 +145,86 @@ func (l *Librarian) record(fromPeerID id.ID, t peer.QueryType, o peer.Outcome) {
 	}
 }
 
+// penalize lowers the peer's reputation for an offense, removing it from the routing table if it
+// becomes blacklisted.
+func (l *Librarian) penalize(peerID id.ID, o peer.Offense) {
+	if !l.reps.Penalize(peerID, o) {
+		return
+	}
+	l.logger.Warn("blacklisted peer",
+		zap.String(logPeerIDShort, id.ShortHex(peerID.Bytes())),
+		zap.Stringer(logOffense, o),
+	)
+	// save now so the ban survives a crash
+	if err := l.reps.Save(l.serverSL); err != nil {
+		l.logger.Error("error saving peer blacklist", zap.Error(err))
+	}
+	if p, exists := l.rt.Get(peerID); exists {
+		// pushing a blacklisted peer removes it
+		l.rt.Push(p)
+	}
+}
+
+// penalizeSearchErrors penalizes the peers whose queries timed out or returned invalid values
+// during a search.
+func (l *Librarian) penalizeSearchErrors(errored map[string]error) {
+	for peerIDStr, err := range errored {
+		var o peer.Offense
+		switch {
+		case err == search.ErrInvalidValue:
+			o = peer.InvalidValue
+		case grpc.Code(err) == codes.DeadlineExceeded:
+			o = peer.Timeout
+		default:
+			continue
+		}
+		peerID, err := id.FromString(peerIDStr)
+		if err != nil {
+			panic(err) // should never happen b/c keys are always peer ID strings
+		}
+		l.penalize(peerID, o)
+	}
+}
+
+// fromRoutingAddress returns whether the request context comes from the IP address the routing
+// table has for the peer.
+func (l *Librarian) fromRoutingAddress(ctx context.Context, peerID id.ID) bool {
+	p, exists := l.rt.Get(peerID)
+	if !exists {
+		return false
+	}
+	from, ok := grpcpeer.FromContext(ctx)
+	if !ok {
+		return false
+	}
+	fromAddr, ok := from.Addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+	return p.Connector().Address().IP.Equal(fromAddr.IP)
+}
+
+// storeTo sends the Store request to the peer.
+func (l *Librarian) storeTo(p peer.Peer, rq *api.StoreRequest, timeout time.Duration) error {
+	storeClient, err := l.storerCreator.Create(p.Connector())
+	if err != nil {
+		return err
+	}
+	ctx, cancel, err := client.NewSignedTimeoutContext(l.signer, rq, timeout)
+	if err != nil {
+		return err
+	}
+	rp, err := storeClient.Store(ctx, rq)
+	cancel()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(rp.Metadata.RequestId, rq.Metadata.RequestId) {
+		return client.ErrUnexpectedRequestID
+	}
+	return nil
+}
+
 func logAndReturnErr(logger *zap.Logger, msg string, err error) error {
 	logger.Error(msg, zap.Error(err))
 	return err
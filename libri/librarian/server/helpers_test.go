@@ -3,37 +3,57 @@ package server
 import (
 	"errors"
 	"math/rand"
+	"net"
 	"testing"
+	"time"
 
+	"github.com/drausin/libri/libri/common/db"
 	"github.com/drausin/libri/libri/common/ecid"
 	"github.com/drausin/libri/libri/common/id"
+	clogging "github.com/drausin/libri/libri/common/logging"
 	"github.com/drausin/libri/libri/common/storage"
 	"github.com/drausin/libri/libri/librarian/api"
 	"github.com/drausin/libri/libri/librarian/client"
+	"github.com/drausin/libri/libri/librarian/server/peer"
 	"github.com/drausin/libri/libri/librarian/server/routing"
+	"github.com/drausin/libri/libri/librarian/server/search"
 	"github.com/golang/protobuf/proto"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	grpcpeer "google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
 func TestNewIDFromPublicKeyBytes_ok(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	i1 := ecid.NewPseudoRandom(rng)
-	i2, err := newIDFromPublicKeyBytes(i1.PublicKeyBytes())
+	i2, err := newIDFromPublicKeyBytes(api.KeyType_SECP256K1, i1.PublicKeyBytes())
 
 	assert.Nil(t, err)
 	assert.Equal(t, i1.ID(), i2)
+
+	for _, kt := range []ecid.KeyType{ecid.KeyTypeP256, ecid.KeyTypeEd25519} {
+		i3, err := ecid.NewPseudoRandomIdentity(rng, kt)
+		assert.Nil(t, err)
+		i4, err := newIDFromPublicKeyBytes(api.KeyType(kt), i3.PublicKeyBytes())
+		assert.Nil(t, err)
+		assert.Equal(t, i3.ID(), i4)
+	}
 }
 
 func TestNewIDFromPublicKeyBytes_err(t *testing.T) {
-	i, err := newIDFromPublicKeyBytes([]byte("not a pub key"))
+	i, err := newIDFromPublicKeyBytes(api.KeyType_SECP256K1, []byte("not a pub key"))
 	assert.NotNil(t, err)
 	assert.Nil(t, i)
 }
 
 func TestCheckRequest_ok(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
-	l := &Librarian{rqv: &alwaysRequestVerifier{}}
+	l := &Librarian{
+		rqv:  &alwaysRequestVerifier{},
+		reps: peer.NewDefaultReputations(),
+	}
 	selfID := ecid.NewPseudoRandom(rng)
 	rq := client.NewGetRequest(selfID, id.NewPseudoRandom(rng))
 	requesterID, err := l.checkRequest(nil, rq, rq.Metadata)
@@ -65,9 +85,11 @@ func TestCheckRequest_verifyErr(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	selfID := ecid.NewPseudoRandom(rng)
 	rq := client.NewGetRequest(selfID, id.NewPseudoRandom(rng))
+	reps := peer.NewDefaultReputations()
 	l := &Librarian{
-		rqv: &neverRequestVerifier{},
-		rt:  routing.NewEmpty(selfID.ID(), routing.NewDefaultParameters()),
+		rqv:  &neverRequestVerifier{},
+		rt:   routing.NewEmpty(selfID.ID(), routing.NewDefaultParameters(), reps),
+		reps: reps,
 	}
 	requesterID, err := l.checkRequest(nil, rq, rq.Metadata)
 
@@ -78,10 +100,12 @@ func TestCheckRequest_verifyErr(t *testing.T) {
 func TestCheckRequestAndKey_ok(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	selfID, key := ecid.NewPseudoRandom(rng), id.NewPseudoRandom(rng)
+	reps := peer.NewDefaultReputations()
 	l := &Librarian{
-		rqv: &alwaysRequestVerifier{},
-		kc:  storage.NewExactLengthChecker(storage.EntriesKeyLength),
-		rt:  routing.NewEmpty(selfID.ID(), routing.NewDefaultParameters()),
+		rqv:  &alwaysRequestVerifier{},
+		kc:   storage.NewExactLengthChecker(storage.EntriesKeyLength),
+		rt:   routing.NewEmpty(selfID.ID(), routing.NewDefaultParameters(), reps),
+		reps: reps,
 	}
 	rq := client.NewGetRequest(selfID, key)
 	requesterID, err := l.checkRequestAndKey(nil, rq, rq.Metadata, key.Bytes())
@@ -106,10 +130,12 @@ func TestCheckRequestAndKey_checkErr(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	selfID, key := ecid.NewPseudoRandom(rng), id.NewPseudoRandom(rng)
 	rq := client.NewGetRequest(selfID, key)
+	reps := peer.NewDefaultReputations()
 	l := &Librarian{
-		rqv: &alwaysRequestVerifier{},
-		kc:  storage.NewExactLengthChecker(storage.EntriesKeyLength),
-		rt:  routing.NewEmpty(selfID.ID(), routing.NewDefaultParameters()),
+		rqv:  &alwaysRequestVerifier{},
+		kc:   storage.NewExactLengthChecker(storage.EntriesKeyLength),
+		rt:   routing.NewEmpty(selfID.ID(), routing.NewDefaultParameters(), reps),
+		reps: reps,
 	}
 	requesterID, err := l.checkRequestAndKey(nil, rq, rq.Metadata, []byte("bad key"))
 
@@ -121,11 +147,13 @@ func TestCheckRequestAndKeyValue_ok(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	selfID := ecid.NewPseudoRandom(rng)
 	value, key := api.NewTestDocument(rng)
+	reps := peer.NewDefaultReputations()
 	l := &Librarian{
-		rt:  routing.NewEmpty(selfID.ID(), routing.NewDefaultParameters()),
-		rqv: &alwaysRequestVerifier{},
-		kc:  storage.NewExactLengthChecker(storage.EntriesKeyLength),
-		kvc: storage.NewHashKeyValueChecker(),
+		rt:   routing.NewEmpty(selfID.ID(), routing.NewDefaultParameters(), reps),
+		reps: reps,
+		rqv:  &alwaysRequestVerifier{},
+		kc:   storage.NewExactLengthChecker(storage.EntriesKeyLength),
+		kvc:  storage.NewHashKeyValueChecker(),
 	}
 	rq := client.NewGetRequest(selfID, key)
 	requesterID, err := l.checkRequestAndKeyValue(nil, rq, rq.Metadata, key.Bytes(), value)
@@ -134,6 +162,37 @@ func TestCheckRequestAndKeyValue_ok(t *testing.T) {
 	assert.Equal(t, selfID.ID(), requesterID)
 }
 
+func TestCheckRequestAndKeyValue_pointer(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	selfID := ecid.NewPseudoRandom(rng)
+	pointer := api.NewTestPointer(rng)
+	value := &api.Document{Contents: &api.Document_Pointer{Pointer: pointer}}
+	key := api.GetPointerKey(pointer.AuthorPublicKey, pointer.Name)
+	reps := peer.NewDefaultReputations()
+	l := &Librarian{
+		rt:   routing.NewEmpty(selfID.ID(), routing.NewDefaultParameters(), reps),
+		reps: reps,
+		rqv:  &alwaysRequestVerifier{},
+		kc:   storage.NewExactLengthChecker(storage.EntriesKeyLength),
+		kvc:  storage.NewHashKeyValueChecker(),
+		pkvc: storage.NewPointerKeyValueChecker(),
+	}
+
+	// check pointer is checked with pointer checker instead of hash checker
+	rq := client.NewGetRequest(selfID, key)
+	requesterID, err := l.checkRequestAndKeyValue(nil, rq, rq.Metadata, key.Bytes(), value)
+	assert.Nil(t, err)
+	assert.Equal(t, selfID.ID(), requesterID)
+
+	// check hash key fails for pointer
+	hashKey, err := api.GetKey(value)
+	assert.Nil(t, err)
+	rq = client.NewGetRequest(selfID, hashKey)
+	requesterID, err = l.checkRequestAndKeyValue(nil, rq, rq.Metadata, hashKey.Bytes(), value)
+	assert.Nil(t, requesterID)
+	assert.NotNil(t, err)
+}
+
 func TestCheckRequestAndKeyValue_checkRequestErr(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	selfID := ecid.NewPseudoRandom(rng)
@@ -153,14 +212,140 @@ func TestCheckRequestAndKeyValue_checkErr(t *testing.T) {
 	value, _ := api.NewTestDocument(rng)
 	key := id.NewPseudoRandom(rng) // bad key, not hash of value
 	rq := client.NewGetRequest(selfID, key)
+	reps := peer.NewDefaultReputations()
+	l := &Librarian{
+		rt:   routing.NewEmpty(selfID.ID(), routing.NewDefaultParameters(), reps),
+		reps: reps,
+		rqv:  &alwaysRequestVerifier{},
+		kc:   storage.NewExactLengthChecker(storage.EntriesKeyLength),
+		kvc:  storage.NewHashKeyValueChecker(),
+	}
+	requesterID, err := l.checkRequestAndKeyValue(nil, rq, rq.Metadata, key.Bytes(), value)
+
+	assert.Nil(t, requesterID)
+	assert.NotNil(t, err)
+	assert.InDelta(t, peer.DefaultPenalties[peer.InvalidValue], reps.Score(selfID.ID()), 1e-6)
+}
+
+func TestCheckRequestAndKeyValue_invalidDocErr(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	selfID, key := ecid.NewPseudoRandom(rng), id.NewPseudoRandom(rng)
+	value := &api.Document{} // invalid b/c missing contents
+	rq := client.NewGetRequest(selfID, key)
+	reps := peer.NewDefaultReputations()
 	l := &Librarian{
-		rt:  routing.NewEmpty(selfID.ID(), routing.NewDefaultParameters()),
-		rqv: &alwaysRequestVerifier{},
-		kc:  storage.NewExactLengthChecker(storage.EntriesKeyLength),
-		kvc: storage.NewHashKeyValueChecker(),
+		rt:   routing.NewEmpty(selfID.ID(), routing.NewDefaultParameters(), reps),
+		reps: reps,
+		rqv:  &alwaysRequestVerifier{},
+		kc:   storage.NewExactLengthChecker(storage.EntriesKeyLength),
+		kvc:  storage.NewHashKeyValueChecker(),
 	}
 	requesterID, err := l.checkRequestAndKeyValue(nil, rq, rq.Metadata, key.Bytes(), value)
 
 	assert.Nil(t, requesterID)
 	assert.NotNil(t, err)
+	assert.InDelta(t, peer.DefaultPenalties[peer.InvalidDocument], reps.Score(selfID.ID()), 1e-6)
+}
+
+func TestCheckRequest_blacklisted(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	selfID := ecid.NewPseudoRandom(rng)
+	params := peer.NewDefaultReputationParameters()
+	params.BlacklistThreshold = params.Penalties[peer.InvalidValue]
+	reps := peer.NewReputations(params)
+	l := &Librarian{
+		rqv:  &alwaysRequestVerifier{},
+		reps: reps,
+	}
+	assert.True(t, reps.Penalize(selfID.ID(), peer.InvalidValue))
+
+	rq := client.NewGetRequest(selfID, id.NewPseudoRandom(rng))
+	requesterID, err := l.checkRequest(nil, rq, rq.Metadata)
+	assert.Nil(t, requesterID)
+	assert.Equal(t, ErrBlacklisted, err)
+}
+
+func TestCheckRequest_penalizeInvalidSignature(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	selfID := ecid.NewPseudoRandom(rng)
+	knownAddr := &net.TCPAddr{IP: net.ParseIP("10.11.12.13"), Port: 20100}
+	otherAddr := &net.TCPAddr{IP: net.ParseIP("10.11.12.14"), Port: 20100}
+	reps := peer.NewDefaultReputations()
+	l := &Librarian{
+		rqv:  &neverRequestVerifier{},
+		reps: reps,
+		rt:   routing.NewEmpty(id.NewPseudoRandom(rng), routing.NewDefaultParameters(), reps),
+	}
+	l.rt.Push(peer.New(selfID.ID(), "requester", peer.NewConnector(knownAddr)))
+
+	// check request from other address isn't penalized
+	ctx := grpcpeer.NewContext(context.Background(), &grpcpeer.Peer{Addr: otherAddr})
+	rq := client.NewGetRequest(selfID, id.NewPseudoRandom(rng))
+	_, err := l.checkRequest(ctx, rq, rq.Metadata)
+	assert.NotNil(t, err)
+	assert.Zero(t, reps.Score(selfID.ID()))
+
+	// check request from peer's known address is penalized
+	ctx = grpcpeer.NewContext(context.Background(), &grpcpeer.Peer{Addr: knownAddr})
+	_, err = l.checkRequest(ctx, rq, rq.Metadata)
+	assert.NotNil(t, err)
+	assert.InDelta(t, peer.DefaultPenalties[peer.InvalidSignature], reps.Score(selfID.ID()), 1e-6)
+}
+
+func TestLibrarian_penalize(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	params := peer.NewDefaultReputationParameters()
+	params.BlacklistThreshold = params.Penalties[peer.InvalidValue]
+	reps := peer.NewReputations(params)
+	rt, _ := routing.NewWithPeers(id.NewPseudoRandom(rng), routing.NewDefaultParameters(), reps,
+		peer.NewTestPeers(rng, 8))
+	kvdb, cleanup, err := db.NewTempDirRocksDB()
+	defer cleanup()
+	defer kvdb.Close()
+	assert.Nil(t, err)
+	l := &Librarian{
+		reps:     reps,
+		rt:       rt,
+		serverSL: storage.NewServerSL(kvdb),
+		logger:   clogging.NewDevInfoLogger(),
+	}
+	p := rt.Peak(id.NewPseudoRandom(rng), 1)[0]
+
+	// check penalty below threshold leaves peer in table
+	l.penalize(p.ID(), peer.Timeout)
+	_, exists := rt.Get(p.ID())
+	assert.True(t, exists)
+
+	// check blacklisted peer is removed from table
+	l.penalize(p.ID(), peer.InvalidValue)
+	assert.True(t, reps.Blacklisted(p.ID()))
+	_, exists = rt.Get(p.ID())
+	assert.False(t, exists)
+	assert.Equal(t, 7, rt.NumPeers())
+
+	// check blacklist is saved right away
+	loaded, err := peer.LoadReputations(l.serverSL, params)
+	assert.Nil(t, err)
+	assert.True(t, loaded.Blacklisted(p.ID()))
+}
+
+func TestLibrarian_penalizeSearchErrors(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	repParams := peer.NewDefaultReputationParameters()
+	repParams.ScoreHalfLife = 1000 * time.Hour // so scores don't noticeably decay during test
+	reps := peer.NewReputations(repParams)
+	l := &Librarian{
+		reps: reps,
+		rt:   routing.NewEmpty(id.NewPseudoRandom(rng), routing.NewDefaultParameters(), reps),
+	}
+	timedOut, invalid, errored := id.NewPseudoRandom(rng), id.NewPseudoRandom(rng),
+		id.NewPseudoRandom(rng)
+	l.penalizeSearchErrors(map[string]error{
+		timedOut.String(): status.Error(codes.DeadlineExceeded, "deadline exceeded"),
+		invalid.String():  search.ErrInvalidValue,
+		errored.String():  errors.New("some other error"),
+	})
+	assert.InDelta(t, peer.DefaultPenalties[peer.Timeout], reps.Score(timedOut), 1e-6)
+	assert.InDelta(t, peer.DefaultPenalties[peer.InvalidValue], reps.Score(invalid), 1e-6)
+	assert.Zero(t, reps.Score(errored))
 }
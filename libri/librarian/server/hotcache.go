@@ -0,0 +1,193 @@
+package server
+
+import (
+	"sync"
+
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/common/storage"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/golang/protobuf/proto"
+	"github.com/hashicorp/golang-lru/simplelru"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// DefaultHotCacheMaxBytes is the default maximum total size of the documents kept in the hot
+	// document cache.
+	DefaultHotCacheMaxBytes = uint64(64 * 1024 * 1024) // 64 MiB
+
+	// DefaultHotCacheMaxDocuments is the default maximum number of documents kept in the hot
+	// document cache.
+	DefaultHotCacheMaxDocuments = uint(4096)
+
+	// logging keys
+	logHotCacheMaxBytes     = "hot_cache_max_bytes"
+	logHotCacheMaxDocuments = "hot_cache_max_documents"
+
+	hotCacheHit  = "hit"
+	hotCacheMiss = "miss"
+)
+
+var (
+	hotCacheLookups = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "libri",
+			Subsystem: "librarian",
+			Name:      "hot_cache_lookups_total",
+			Help:      "Local document loads by whether they hit the hot document cache.",
+		},
+		[]string{"result"},
+	)
+	hotCacheBytes = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "libri",
+			Subsystem: "librarian",
+			Name:      "hot_cache_bytes",
+			Help:      "Total size of the documents kept in the hot document cache.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(hotCacheLookups)
+	prometheus.MustRegister(hotCacheBytes)
+}
+
+// HotCacheParameters define the memory limits of the in-memory cache of recently loaded
+// documents.
+type HotCacheParameters struct {
+	// MaxBytes is the maximum total size of the documents kept, or zero to disable the cache.
+	MaxBytes uint64
+
+	// MaxDocuments is the maximum number of documents kept, or zero to disable the cache.
+	MaxDocuments uint
+}
+
+// NewDefaultHotCacheParameters creates an instance with default parameters.
+func NewDefaultHotCacheParameters() *HotCacheParameters {
+	return &HotCacheParameters{
+		MaxBytes:     DefaultHotCacheMaxBytes,
+		MaxDocuments: DefaultHotCacheMaxDocuments,
+	}
+}
+
+// MarshalLogObject converts the HotCacheParameters into an object (which will become json) for
+// logging.
+func (p *HotCacheParameters) MarshalLogObject(oe zapcore.ObjectEncoder) error {
+	oe.AddUint64(logHotCacheMaxBytes, p.MaxBytes)
+	oe.AddUint(logHotCacheMaxDocuments, p.MaxDocuments)
+	return nil
+}
+
+// hotDocument is a document kept in the hot cache along with its size.
+type hotDocument struct {
+	value *api.Document
+	size  uint64
+}
+
+// hotCache keeps the least recently loaded validated documents in memory in front of an inner
+// storage.DocumentSLD, so loads of popular documents skip reading, checking, and validating them
+// again. Loaded documents are shared between callers, who must not modify them.
+type hotCache struct {
+	inner    storage.DocumentSLD
+	maxBytes uint64
+
+	// LRU of *hotDocument values keyed by the string representation of their key
+	docs   *simplelru.LRU
+	nBytes uint64
+
+	// incremented on every invalidation, so loads that race with one don't cache stale values
+	generation uint64
+	mu         sync.Mutex
+}
+
+// NewHotCache returns a storage.DocumentSLD that caches documents loaded from the inner one in
+// memory within the given limits, or just the inner one if the cache is disabled.
+func NewHotCache(inner storage.DocumentSLD, params *HotCacheParameters) (
+	storage.DocumentSLD, error) {
+	if params.MaxBytes == 0 || params.MaxDocuments == 0 {
+		return inner, nil
+	}
+	hc := &hotCache{
+		inner:    inner,
+		maxBytes: params.MaxBytes,
+	}
+	docs, err := simplelru.NewLRU(int(params.MaxDocuments), hc.onEvict)
+	if err != nil {
+		return nil, err
+	}
+	hc.docs = docs
+	return hc, nil
+}
+
+// Store stores the value in the inner storage.DocumentSLD and invalidates any cached value, since
+// a newer Pointer may replace an existing one.
+func (hc *hotCache) Store(key id.ID, value *api.Document) error {
+	if err := hc.inner.Store(key, value); err != nil {
+		return err
+	}
+	hc.invalidate(key)
+	return nil
+}
+
+// Load returns the cached value if present and otherwise loads it from the inner
+// storage.DocumentSLD and caches it.
+func (hc *hotCache) Load(key id.ID) (*api.Document, error) {
+	hc.mu.Lock()
+	if cached, in := hc.docs.Get(key.String()); in {
+		hc.mu.Unlock()
+		hotCacheLookups.WithLabelValues(hotCacheHit).Inc()
+		return cached.(*hotDocument).value, nil
+	}
+	generation := hc.generation
+	hc.mu.Unlock()
+	hotCacheLookups.WithLabelValues(hotCacheMiss).Inc()
+
+	value, err := hc.inner.Load(key)
+	if err != nil || value == nil {
+		return value, err
+	}
+	size := uint64(proto.Size(value))
+	if size > hc.maxBytes {
+		return value, nil
+	}
+
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	if generation != hc.generation {
+		// value may have been replaced or deleted since we loaded it
+		return value, nil
+	}
+	if hc.docs.Contains(key.String()) {
+		// another load cached it in the meantime
+		return value, nil
+	}
+	hc.docs.Add(key.String(), &hotDocument{value: value, size: size})
+	hc.nBytes += size
+	for hc.nBytes > hc.maxBytes {
+		hc.docs.RemoveOldest()
+	}
+	hotCacheBytes.Set(float64(hc.nBytes))
+	return value, nil
+}
+
+// Delete deletes the value from the inner storage.DocumentSLD and invalidates any cached value.
+func (hc *hotCache) Delete(key id.ID) error {
+	err := hc.inner.Delete(key)
+	hc.invalidate(key)
+	return err
+}
+
+func (hc *hotCache) invalidate(key id.ID) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	hc.generation++
+	hc.docs.Remove(key.String())
+	hotCacheBytes.Set(float64(hc.nBytes))
+}
+
+// onEvict updates the total size of the cached documents. Callers must hold the cache's lock.
+func (hc *hotCache) onEvict(key interface{}, value interface{}) {
+	hc.nBytes -= value.(*hotDocument).size
+}
@@ -0,0 +1,186 @@
+package server
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/drausin/libri/libri/common/db"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/common/storage"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHotCache(t *testing.T) {
+	inner := &countingDocumentSLD{}
+
+	hc, err := NewHotCache(inner, NewDefaultHotCacheParameters())
+	assert.Nil(t, err)
+	assert.IsType(t, &hotCache{}, hc)
+
+	// disabled caches just return the inner storage.DocumentSLD
+	hc, err = NewHotCache(inner, &HotCacheParameters{MaxDocuments: 8})
+	assert.Nil(t, err)
+	assert.Equal(t, inner, hc)
+	hc, err = NewHotCache(inner, &HotCacheParameters{MaxBytes: 1024})
+	assert.Nil(t, err)
+	assert.Equal(t, inner, hc)
+}
+
+func TestHotCache_Load(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	inner, cleanup := newCountingDocumentSLD(t)
+	defer cleanup()
+	hc, err := NewHotCache(inner, NewDefaultHotCacheParameters())
+	assert.Nil(t, err)
+
+	value, key := api.NewTestDocument(rng)
+	err = hc.Store(key, value)
+	assert.Nil(t, err)
+
+	// first load misses and second hits
+	for c := 0; c < 2; c++ {
+		loaded, err := hc.Load(key)
+		assert.Nil(t, err)
+		assert.Equal(t, value, loaded)
+		assert.Equal(t, 1, inner.nLoads)
+	}
+
+	// missing values aren't cached
+	missingKey := id.NewPseudoRandom(rng)
+	for c := 0; c < 2; c++ {
+		loaded, err := hc.Load(missingKey)
+		assert.Nil(t, err)
+		assert.Nil(t, loaded)
+		assert.Equal(t, 2+c, inner.nLoads)
+	}
+
+	// load errors bubble up
+	inner.loadErr = errors.New("some load error")
+	loaded, err := hc.Load(missingKey)
+	assert.NotNil(t, err)
+	assert.Nil(t, loaded)
+}
+
+func TestHotCache_Load_limits(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	inner, cleanup := newCountingDocumentSLD(t)
+	defer cleanup()
+	values, keys := make([]*api.Document, 3), make([]id.ID, 3)
+	for i := range values {
+		values[i], keys[i] = api.NewTestDocument(rng)
+		err := inner.Store(keys[i], values[i])
+		assert.Nil(t, err)
+	}
+	sizes := make([]uint64, len(values))
+	for i, value := range values {
+		sizes[i] = uint64(proto.Size(value))
+	}
+
+	// limits only fit the last two values loaded
+	cases := map[string]*HotCacheParameters{
+		"max documents": {MaxDocuments: 2, MaxBytes: DefaultHotCacheMaxBytes},
+		"max bytes":     {MaxDocuments: DefaultHotCacheMaxDocuments, MaxBytes: sizes[1] + sizes[2]},
+	}
+	for desc, params := range cases {
+		hc, err := NewHotCache(inner, params)
+		assert.Nil(t, err, desc)
+		for _, key := range keys {
+			_, err = hc.Load(key)
+			assert.Nil(t, err, desc)
+		}
+		assert.Equal(t, 2, hc.(*hotCache).docs.Len(), desc)
+		assert.True(t, hc.(*hotCache).nBytes <= params.MaxBytes, desc)
+
+		// least recently loaded value was evicted
+		inner.nLoads = 0
+		for i := len(keys) - 1; i >= 0; i-- {
+			_, err = hc.Load(keys[i])
+			assert.Nil(t, err, desc)
+		}
+		assert.Equal(t, 1, inner.nLoads, desc)
+	}
+
+	// values larger than the cache aren't cached
+	hc, err := NewHotCache(inner, &HotCacheParameters{MaxDocuments: 2, MaxBytes: sizes[0] / 2})
+	assert.Nil(t, err)
+	_, err = hc.Load(keys[0])
+	assert.Nil(t, err)
+	assert.Zero(t, hc.(*hotCache).docs.Len())
+	assert.Zero(t, hc.(*hotCache).nBytes)
+}
+
+func TestHotCache_invalidate(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	inner, cleanup := newCountingDocumentSLD(t)
+	defer cleanup()
+	hc, err := NewHotCache(inner, NewDefaultHotCacheParameters())
+	assert.Nil(t, err)
+	value, key := api.NewTestDocument(rng)
+	err = hc.Store(key, value)
+	assert.Nil(t, err)
+
+	// storing invalidates cached value
+	_, err = hc.Load(key)
+	assert.Nil(t, err)
+	err = hc.Store(key, value)
+	assert.Nil(t, err)
+	_, err = hc.Load(key)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, inner.nLoads)
+
+	// deleting invalidates cached value
+	err = hc.Delete(key)
+	assert.Nil(t, err)
+	loaded, err := hc.Load(key)
+	assert.Nil(t, err)
+	assert.Nil(t, loaded)
+	assert.Zero(t, hc.(*hotCache).nBytes)
+
+	// values invalidated while being loaded aren't cached
+	err = hc.Store(key, value)
+	assert.Nil(t, err)
+	inner.onLoad = func() { hc.(*hotCache).invalidate(key) }
+	loaded, err = hc.Load(key)
+	assert.Nil(t, err)
+	assert.Equal(t, value, loaded)
+	assert.Zero(t, hc.(*hotCache).docs.Len())
+
+	// store errors don't invalidate
+	inner.onLoad = nil
+	_, err = hc.Load(key)
+	assert.Nil(t, err)
+	err = hc.Store(key, &api.Document{})
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, hc.(*hotCache).docs.Len())
+}
+
+func newCountingDocumentSLD(t *testing.T) (*countingDocumentSLD, func()) {
+	kvdb, cleanup, err := db.NewTempDirRocksDB()
+	assert.Nil(t, err)
+	return &countingDocumentSLD{DocumentSLD: storage.NewDocumentSLD(kvdb)}, func() {
+		kvdb.Close()
+		cleanup()
+	}
+}
+
+// countingDocumentSLD counts the loads from an inner storage.DocumentSLD.
+type countingDocumentSLD struct {
+	storage.DocumentSLD
+	nLoads  int
+	loadErr error
+	onLoad  func()
+}
+
+func (c *countingDocumentSLD) Load(key id.ID) (*api.Document, error) {
+	c.nLoads++
+	if c.loadErr != nil {
+		return nil, c.loadErr
+	}
+	if c.onLoad != nil {
+		c.onLoad()
+	}
+	return c.DocumentSLD.Load(key)
+}
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"fmt"
 	"sync"
+	"time"
 
+	"github.com/drausin/libri/libri/common/ecid"
 	"github.com/drausin/libri/libri/common/id"
 	"github.com/drausin/libri/libri/librarian/api"
 	"github.com/drausin/libri/libri/librarian/client"
@@ -21,25 +23,30 @@ type introducer struct {
 	signer            client.Signer
 	introducerCreator client.IntroducerCreator
 	repProcessor      ResponseProcessor
+	idDifficulty      uint
 }
 
-// NewIntroducer creates a new Introducer instance with the given signer, querier, and response
-// processor.
-func NewIntroducer(s client.Signer, c client.IntroducerCreator, rp ResponseProcessor) Introducer {
+// NewIntroducer creates a new Introducer instance with the given signer, querier, response
+// processor, and peer ID puzzle difficulty to advertise in requests.
+func NewIntroducer(
+	s client.Signer, c client.IntroducerCreator, rp ResponseProcessor, idDifficulty uint,
+) Introducer {
 	return &introducer{
 		signer:            s,
 		introducerCreator: c,
 		repProcessor:      rp,
+		idDifficulty:      idDifficulty,
 	}
 }
 
 // NewDefaultIntroducer creates a new Introducer with the given signer and default querier and
-// response processor.
-func NewDefaultIntroducer(s client.Signer, selfID id.ID) Introducer {
+// response processor, requiring peer IDs to solve the puzzle of the given difficulty.
+func NewDefaultIntroducer(s client.Signer, selfID id.ID, idDifficulty uint) Introducer {
 	return NewIntroducer(
 		s,
 		client.NewIntroducerCreator(),
-		NewResponseProcessor(peer.NewFromer(), selfID),
+		NewResponseProcessor(peer.NewFromer(), selfID, idDifficulty),
+		idDifficulty,
 	)
 }
 
@@ -75,16 +82,22 @@ func (i *introducer) introduceWork(intro *Introduction, wg *sync.WaitGroup) {
 		}
 
 		// do the query
+		start := time.Now()
 		response, err := i.query(next.Connector(), intro)
+		latency := time.Since(start)
 		if err != nil {
 			// if we had an issue querying, skip to next peer
 			intro.wrapLock(func() {
 				intro.Result.NErrors++
 				next.Recorder().Record(peer.Response, peer.Error)
+				next.Recorder().RecordLatency(latency)
 			})
 			continue
 		}
-		intro.wrapLock(func() { next.Recorder().Record(peer.Response, peer.Success) })
+		intro.wrapLock(func() {
+			next.Recorder().Record(peer.Response, peer.Success)
+			next.Recorder().RecordLatency(latency)
+		})
 
 		// process the heap's response
 		intro.wrapLock(func() {
@@ -110,6 +123,7 @@ func (i *introducer) query(pConn peer.Connector, intro *Introduction) (*api.Intr
 		return nil, err
 	}
 	rq := intro.NewRequest()
+	rq.IdDifficulty = uint32(i.idDifficulty)
 	ctx, cancel, err := client.NewSignedTimeoutContext(i.signer, rq, intro.Params.Timeout)
 	if err != nil {
 		return nil, err
@@ -142,29 +156,43 @@ type ResponseProcessor interface {
 }
 
 type responseProcessor struct {
-	fromer peer.Fromer
-	selfID id.ID
+	fromer       peer.Fromer
+	selfID       id.ID
+	idDifficulty uint
 }
 
-// NewResponseProcessor creates a new ResponseProcessor with a given peer.Fromer.
-func NewResponseProcessor(f peer.Fromer, selfID id.ID) ResponseProcessor {
+// NewResponseProcessor creates a new ResponseProcessor with a given peer.Fromer. Peers whose IDs
+// don't solve the puzzle of the given difficulty are ignored.
+func NewResponseProcessor(f peer.Fromer, selfID id.ID, idDifficulty uint) ResponseProcessor {
 	return &responseProcessor{
-		fromer: f,
-		selfID: selfID,
+		fromer:       f,
+		selfID:       selfID,
+		idDifficulty: idDifficulty,
 	}
 }
 
 func (irp *responseProcessor) Process(rp *api.IntroduceResponse, result *Result) error {
+	// ignore responses from peers whose IDs don't solve the puzzle
+	responderID := id.FromBytes(rp.Self.PeerId)
+	if !ecid.SolvesIDPuzzle(responderID, irp.idDifficulty) {
+		return nil
+	}
+	if uint(rp.IdDifficulty) > result.IDDifficulty {
+		result.IDDifficulty = uint(rp.IdDifficulty)
+	}
 
 	// add newly introduced peer to responded map
-	idStr := id.FromBytes(rp.Self.PeerId).String()
 	newPeer := irp.fromer.FromAPI(rp.Self)
-	result.Responded[idStr] = newPeer
+	result.Responded[responderID.String()] = newPeer
 
 	// add newly discovered peers to list of peers to query if they're not already there
 	selfIDStr := irp.selfID.String()
 	for _, pa := range rp.Peers {
-		newIDStr := id.FromBytes(pa.PeerId).String()
+		newID := id.FromBytes(pa.PeerId)
+		if !ecid.SolvesIDPuzzle(newID, irp.idDifficulty) {
+			continue
+		}
+		newIDStr := newID.String()
 		_, inResponded := result.Responded[newIDStr]
 		_, inUnqueried := result.Unqueried[newIDStr]
 		if !inResponded && !inUnqueried && newIDStr != selfIDStr {
@@ -22,6 +22,7 @@ func TestNewDefaultIntroducer(t *testing.T) {
 	s := NewDefaultIntroducer(
 		lclient.NewSigner(ecid.NewPseudoRandom(rng).Key()),
 		id.NewPseudoRandom(rng),
+		0,
 	)
 	assert.NotNil(t, s.(*introducer).signer)
 	assert.NotNil(t, s.(*introducer).introducerCreator)
@@ -174,7 +175,7 @@ func TestResponseProcessor_Process(t *testing.T) {
 	responder := peer.NewTestPeer(rng, nPeers)
 	peers := peer.NewTestPeers(rng, nPeers)
 	selfPeer := peer.NewTestPeer(rng, nPeers+1)
-	rp := NewResponseProcessor(peer.NewFromer(), selfPeer.ID())
+	rp := NewResponseProcessor(peer.NewFromer(), selfPeer.ID(), 0)
 	apiPeers := peer.ToAPIs(peers)
 	apiPeers = append(apiPeers, selfPeer.ToAPI())
 
@@ -220,6 +221,57 @@ func TestResponseProcessor_Process(t *testing.T) {
 	}
 }
 
+func TestResponseProcessor_Process_idPuzzle(t *testing.T) {
+	rng := rand.New(rand.NewSource(int64(0)))
+	nPeers, idDifficulty := 64, uint(2)
+	responder := peer.NewTestPeer(rng, nPeers)
+	for !ecid.SolvesIDPuzzle(responder.ID(), idDifficulty) {
+		responder = peer.NewTestPeer(rng, nPeers)
+	}
+	peers := peer.NewTestPeers(rng, nPeers)
+	nSolving := 0
+	for _, p := range peers {
+		if ecid.SolvesIDPuzzle(p.ID(), idDifficulty) {
+			nSolving++
+		}
+	}
+	rp := NewResponseProcessor(peer.NewFromer(), id.NewPseudoRandom(rng), idDifficulty)
+	result := NewInitialResult()
+
+	response := &api.IntroduceResponse{
+		Self:         responder.ToAPI(),
+		Peers:        peer.ToAPIs(peers),
+		IdDifficulty: uint32(idDifficulty + 1),
+	}
+	err := rp.Process(response, result)
+	assert.Nil(t, err)
+
+	// peers without puzzle-solving IDs are ignored
+	assert.Equal(t, 1, len(result.Responded))
+	assert.Equal(t, nSolving, len(result.Unqueried))
+	assert.True(t, nSolving > 0 && nSolving < nPeers)
+	for _, p := range result.Unqueried {
+		assert.True(t, ecid.SolvesIDPuzzle(p.ID(), idDifficulty))
+	}
+
+	// advertised network difficulty is recorded
+	assert.Equal(t, idDifficulty+1, result.IDDifficulty)
+
+	// responses from responders without puzzle-solving IDs are ignored
+	badResponder := peer.NewTestPeer(rng, nPeers+1)
+	for ecid.SolvesIDPuzzle(badResponder.ID(), idDifficulty) {
+		badResponder = peer.NewTestPeer(rng, nPeers+1)
+	}
+	response = &api.IntroduceResponse{
+		Self:  badResponder.ToAPI(),
+		Peers: peer.ToAPIs(peer.NewTestPeers(rng, nPeers)),
+	}
+	err = rp.Process(response, result)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(result.Responded))
+	assert.Equal(t, nSolving, len(result.Unqueried))
+}
+
 func newQueryTestIntroduction() *Introduction {
 	n, _ := 32, uint(8)
 	rng := rand.New(rand.NewSource(int64(n)))
@@ -245,6 +297,7 @@ func newTestIntroducer(peersMap map[string]peer.Peer, selfID id.ID) Introducer {
 			fromer: &search.TestFromer{Peers: peersMap},
 			selfID: selfID,
 		},
+		0,
 	)
 }
 
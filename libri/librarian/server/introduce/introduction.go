@@ -70,6 +70,9 @@ type Result struct {
 	// number of errors encountered while querying peers
 	NErrors uint
 
+	// maximum peer ID puzzle difficulty advertised by responding peers
+	IDDifficulty uint
+
 	// fatal error that occurred during the search
 	FatalErr error
 }
@@ -98,7 +101,7 @@ type Introduction struct {
 }
 
 // NewIntroduction creates a new Introduction instance.
-func NewIntroduction(selfID ecid.ID, apiSelf *api.PeerAddress, params *Parameters) *Introduction {
+func NewIntroduction(selfID ecid.Identity, apiSelf *api.PeerAddress, params *Parameters) *Introduction {
 	return &Introduction{
 		NewRequest: func() *api.IntroduceRequest {
 			return client.NewIntroduceRequest(selfID, apiSelf, params.NumPeersPerRequest)
@@ -13,6 +13,7 @@ import (
 	"net/http"
 
 	cbackoff "github.com/cenkalti/backoff"
+	"github.com/drausin/libri/libri/common/ecid"
 	cerrors "github.com/drausin/libri/libri/common/errors"
 	"github.com/drausin/libri/libri/librarian/api"
 	"github.com/drausin/libri/libri/librarian/server/introduce"
@@ -41,6 +42,10 @@ const (
 	// LoggerNBootstrappedPeers is the logger key used for the number of peers found
 	// during a bootstrap operation.
 	LoggerNBootstrappedPeers = "n_peers"
+
+	// LoggerNEvictedPeers is the logger key used for the number of peers evicted from the
+	// routing table.
+	LoggerNEvictedPeers = "n_evicted_peers"
 )
 
 var errNoBootstrappedPeers = errors.New("failed to bootstrap any other peers")
@@ -55,6 +60,9 @@ func Start(logger *zap.Logger, config *Config, up chan *Librarian) error {
 		return err
 	}
 
+	// discard peers loaded from a previous run that are no longer alive
+	l.probeLoadedPeers()
+
 	// populate routing table
 	if err := l.bootstrapPeers(config.BootstrapAddrs); err != nil {
 		return err
@@ -68,6 +76,18 @@ func Start(logger *zap.Logger, config *Config, up chan *Librarian) error {
 	return nil
 }
 
+// probeLoadedPeers pings all peers in the routing table, evicting those that don't respond.
+func (l *Librarian) probeLoadedPeers() {
+	if l.rt.NumPeers() == 0 {
+		return
+	}
+	nEvicted := l.prober.ProbeAll()
+	l.logger.Info("probed loaded routing table peers",
+		zap.Int(LoggerNEvictedPeers, nEvicted),
+		zap.Int(NumPeers, l.rt.NumPeers()),
+	)
+}
+
 func (l *Librarian) bootstrapPeers(bootstrapAddrs []*net.TCPAddr) error {
 	bootstraps, bootstrapAddrStrs := makeBootstrapPeers(bootstrapAddrs, l.config.PublicAddr)
 	l.logger.Info("beginning peer bootstrap", zap.Strings(LoggerSeeds, bootstrapAddrStrs))
@@ -100,6 +120,15 @@ func (l *Librarian) bootstrapPeers(bootstrapAddrs []*net.TCPAddr) error {
 		return err
 	}
 
+	if selfDifficulty := ecid.IDDifficulty(l.rt.SelfID()); intro.Result.IDDifficulty >
+		selfDifficulty {
+		// peers requiring harder IDs than ours will reject our introductions
+		l.logger.Warn("peer ID easier than advertised network ID difficulty",
+			zap.Uint(LoggerIDDifficulty, selfDifficulty),
+			zap.Uint(LoggerNetworkIDDifficulty, intro.Result.IDDifficulty),
+		)
+	}
+
 	// add bootstrapped peers to routing table
 	var prevAddress string
 	for _, p := range intro.Result.Responded {
@@ -186,6 +215,30 @@ func (l *Librarian) listenAndServe(up chan *Librarian) error {
 		}
 	}()
 
+	// long-running goroutine evicting unresponsive peers from the routing table
+	go l.prober.Begin()
+
+	// long-running goroutine refreshing routing table buckets without recent lookups
+	l.refreshing.Add(1)
+	go func() {
+		defer l.refreshing.Done()
+		l.refreshPeriodically()
+	}()
+
+	// long-running goroutine saving the routing table as it changes
+	l.checkpointing.Add(1)
+	go func() {
+		defer l.checkpointing.Done()
+		l.checkpointPeriodically()
+	}()
+
+	// long-running goroutine deleting expired cache copies
+	l.sweeping.Add(1)
+	go func() {
+		defer l.sweeping.Done()
+		l.sweepCachePeriodically()
+	}()
+
 	// notify up channel shortly after starting to serve requests
 	go func() {
 		time.Sleep(postListenNotifyWait)
@@ -222,6 +275,7 @@ func (l *Librarian) EndSubscriptions() {
 func (l *Librarian) Close() error {
 
 	l.EndSubscriptions()
+	l.prober.End()
 
 	// send stop signal to listener
 	select {
@@ -230,6 +284,12 @@ func (l *Librarian) Close() error {
 		close(l.stop)
 	}
 
+	// wait for any in-progress checkpoint, sweep, or refresh to finish before the final save and
+	// the DB closes
+	l.checkpointing.Wait()
+	l.sweeping.Wait()
+	l.refreshing.Wait()
+
 	// end metrics server
 	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
 	if err := l.metrics.Shutdown(ctx); err != nil {
@@ -251,6 +311,11 @@ func (l *Librarian) Close() error {
 		return err
 	}
 
+	// save peer blacklist
+	if err := l.reps.Save(l.serverSL); err != nil {
+		return err
+	}
+
 	// close the DB
 	l.db.Close()
 
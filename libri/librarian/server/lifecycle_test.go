@@ -111,12 +111,14 @@ func TestLibrarian_bootstrapPeers_ok(t *testing.T) {
 		fixedResult.Responded[p.ID().String()] = p
 	}
 
+	reps := peer.NewDefaultReputations()
 	l := &Librarian{
 		config: NewDefaultConfig(),
 		introducer: &fixedIntroducer{
 			result: fixedResult,
 		},
-		rt:     routing.NewEmpty(id.NewPseudoRandom(rng), routing.NewDefaultParameters()),
+		rt:     routing.NewEmpty(id.NewPseudoRandom(rng), routing.NewDefaultParameters(), reps),
+		reps:   reps,
 		logger: clogging.NewDevInfoLogger(),
 	}
 
@@ -142,13 +144,15 @@ func TestLibrarian_bootstrapPeers_introduceErr(t *testing.T) {
 		seeds[i] = peer.NewTestPublicAddr(i)
 	}
 
+	reps := peer.NewDefaultReputations()
 	l := &Librarian{
 		config: NewDefaultConfig(),
 		selfID: ecid.NewPseudoRandom(rng),
 		introducer: &fixedIntroducer{
 			err: errors.New("some fatal introduce error"),
 		},
-		rt:     routing.NewEmpty(id.NewPseudoRandom(rng), routing.NewDefaultParameters()),
+		rt:     routing.NewEmpty(id.NewPseudoRandom(rng), routing.NewDefaultParameters(), reps),
+		reps:   reps,
 		logger: clogging.NewDevInfoLogger(),
 	}
 
@@ -173,13 +177,15 @@ func TestLibrarian_bootstrapPeers_noResponsesErr(t *testing.T) {
 
 	publicAddr, err := ParseAddr(DefaultIP, DefaultPort+1)
 	assert.Nil(t, err)
+	reps := peer.NewDefaultReputations()
 	l := &Librarian{
 		config: NewDefaultConfig().WithPublicAddr(publicAddr),
 		selfID: ecid.NewPseudoRandom(rng),
 		introducer: &fixedIntroducer{
 			result: fixedResult,
 		},
-		rt:     routing.NewEmpty(id.NewPseudoRandom(rng), routing.NewDefaultParameters()),
+		rt:     routing.NewEmpty(id.NewPseudoRandom(rng), routing.NewDefaultParameters(), reps),
+		reps:   reps,
 		logger: clogging.NewDevInfoLogger(),
 	}
 
@@ -187,6 +193,44 @@ func TestLibrarian_bootstrapPeers_noResponsesErr(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestLibrarian_probeLoadedPeers(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	reps := peer.NewDefaultReputations()
+	pr := &fixedProber{nEvicted: 2}
+	l := &Librarian{
+		rt:     routing.NewEmpty(id.NewPseudoRandom(rng), routing.NewDefaultParameters(), reps),
+		prober: pr,
+		logger: clogging.NewDevInfoLogger(),
+	}
+
+	// nothing to probe in an empty table
+	l.probeLoadedPeers()
+	assert.Zero(t, pr.nProbeAlls)
+
+	rt, _, _ := routing.NewTestWithPeers(rng, 8)
+	l.rt = rt
+	l.probeLoadedPeers()
+	assert.Equal(t, 1, pr.nProbeAlls)
+}
+
+type fixedProber struct {
+	nEvicted   int
+	nProbeAlls int
+}
+
+func (p *fixedProber) Probe() int {
+	return p.nEvicted
+}
+
+func (p *fixedProber) ProbeAll() int {
+	p.nProbeAlls++
+	return p.nEvicted
+}
+
+func (p *fixedProber) Begin() {}
+
+func (p *fixedProber) End() {}
+
 type fixedIntroducer struct {
 	result *introduce.Result
 	err    error
@@ -0,0 +1,370 @@
+package server
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drausin/libri/libri/common/id"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Endpoint identifies a rate-limited librarian RPC.
+type Endpoint int
+
+const (
+	// IntroduceEndpoint is the Introduce RPC.
+	IntroduceEndpoint Endpoint = iota
+
+	// FindEndpoint is the Find RPC.
+	FindEndpoint
+
+	// StoreEndpoint is the Store RPC.
+	StoreEndpoint
+
+	// GetEndpoint is the Get RPC.
+	GetEndpoint
+
+	// PutEndpoint is the Put RPC.
+	PutEndpoint
+
+	// SubscribeEndpoint is the Subscribe RPC.
+	SubscribeEndpoint
+
+	// LibrarianStoreEndpoint is the Store RPC from librarians in the routing table, which mostly
+	// replicate, repair, or cache documents and so have a separate request budget from other
+	// Stores.
+	LibrarianStoreEndpoint
+
+	nEndpoints = iota
+)
+
+var endpointNames = [nEndpoints]string{
+	"introduce",
+	"find",
+	"store",
+	"get",
+	"put",
+	"subscribe",
+	"librarian_store",
+}
+
+// String returns the lowercase name of the endpoint.
+func (e Endpoint) String() string {
+	return endpointNames[e]
+}
+
+const (
+	// DefaultAuthorQuota is the default number of document bytes peers may store or put for each
+	// author public key per quota period.
+	DefaultAuthorQuota = uint64(1 << 30) // 1 GiB
+
+	// DefaultQuotaPeriod is the default period over which author quotas refill.
+	DefaultQuotaPeriod = 24 * time.Hour
+
+	// DefaultNLimited is the default number of peers and author public keys whose usage is
+	// tracked.
+	DefaultNLimited = 4096
+
+	// logging keys
+	logRates        = "rates"
+	logBursts       = "bursts"
+	logAuthorQuota  = "author_quota"
+	logAuthorQuotas = "n_author_quotas"
+	logQuotaPeriod  = "quota_period"
+	logNLimited     = "n_limited"
+
+	limitedRate  = "rate"
+	limitedQuota = "quota"
+)
+
+var (
+	// DefaultRates are the default sustained number of requests per second each peer may make to
+	// each endpoint.
+	DefaultRates = map[Endpoint]float64{
+		IntroduceEndpoint: 1,
+		FindEndpoint:      50,
+		StoreEndpoint:     10,
+		GetEndpoint:       20,
+		PutEndpoint:       5,
+		SubscribeEndpoint: 1.0 / 60,
+
+		LibrarianStoreEndpoint: 200,
+	}
+
+	// DefaultBursts are the default maximum number of requests each peer may make to each
+	// endpoint at once.
+	DefaultBursts = map[Endpoint]uint{
+		IntroduceEndpoint: 5,
+		FindEndpoint:      100,
+		StoreEndpoint:     20,
+		GetEndpoint:       40,
+		PutEndpoint:       10,
+		SubscribeEndpoint: 3,
+
+		LibrarianStoreEndpoint: 400,
+	}
+
+	// ErrRateLimited indicates when a peer has exceeded its request rate limit for an endpoint.
+	ErrRateLimited = status.Error(codes.ResourceExhausted, "request rate limit exceeded")
+
+	// ErrQuotaExceeded indicates when an author public key has exceeded its byte quota.
+	ErrQuotaExceeded = status.Error(codes.ResourceExhausted, "author byte quota exceeded")
+
+	// ErrInvalidAuthorQuota indicates when an author quota override is not of the form
+	// <hex author public key>=<bytes>.
+	ErrInvalidAuthorQuota = errors.New("invalid author quota override")
+)
+
+var (
+	rateLimitRates = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "libri",
+			Subsystem: "librarian",
+			Name:      "rate_limit_rate",
+			Help:      "Sustained requests per second allowed from each peer.",
+		},
+		[]string{"endpoint"},
+	)
+	rateLimitBursts = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "libri",
+			Subsystem: "librarian",
+			Name:      "rate_limit_burst",
+			Help:      "Maximum requests allowed from each peer at once.",
+		},
+		[]string{"endpoint"},
+	)
+	authorQuotaBytes = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "libri",
+			Subsystem: "librarian",
+			Name:      "author_quota_bytes",
+			Help:      "Default document bytes allowed for each author public key per period.",
+		},
+	)
+	limitedRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "libri",
+			Subsystem: "librarian",
+			Name:      "limited_requests_total",
+			Help:      "Requests rejected for exceeding a rate limit or quota.",
+		},
+		[]string{"endpoint", "limit"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(rateLimitRates)
+	prometheus.MustRegister(rateLimitBursts)
+	prometheus.MustRegister(authorQuotaBytes)
+	prometheus.MustRegister(limitedRequests)
+}
+
+// LimitParameters define the request rate limits and byte quotas librarians apply to peers.
+type LimitParameters struct {
+	// Rates are the sustained number of requests per second each peer may make to each
+	// endpoint.
+	Rates map[Endpoint]float64
+
+	// Bursts are the maximum number of requests each peer may make to each endpoint at once.
+	Bursts map[Endpoint]uint
+
+	// AuthorQuota is the number of document bytes peers may store or put for each author public
+	// key per QuotaPeriod.
+	AuthorQuota uint64
+
+	// AuthorQuotas override AuthorQuota for particular hex-encoded author public keys.
+	AuthorQuotas map[string]uint64
+
+	// QuotaPeriod is the period over which author quotas refill.
+	QuotaPeriod time.Duration
+
+	// NLimited is the number of peers and author public keys whose usage is tracked. Usage of
+	// the least recently seen is forgotten beyond this.
+	NLimited uint
+}
+
+// NewDefaultLimitParameters creates an instance with default parameters.
+func NewDefaultLimitParameters() *LimitParameters {
+	rates := make(map[Endpoint]float64, len(DefaultRates))
+	for e, rate := range DefaultRates {
+		rates[e] = rate
+	}
+	bursts := make(map[Endpoint]uint, len(DefaultBursts))
+	for e, burst := range DefaultBursts {
+		bursts[e] = burst
+	}
+	return &LimitParameters{
+		Rates:        rates,
+		Bursts:       bursts,
+		AuthorQuota:  DefaultAuthorQuota,
+		AuthorQuotas: make(map[string]uint64),
+		QuotaPeriod:  DefaultQuotaPeriod,
+		NLimited:     DefaultNLimited,
+	}
+}
+
+// MarshalLogObject converts the LimitParameters into an object (which will become json) for
+// logging.
+func (p *LimitParameters) MarshalLogObject(oe zapcore.ObjectEncoder) error {
+	oe.AddString(logRates, fmt.Sprintf("%v", p.Rates))
+	oe.AddString(logBursts, fmt.Sprintf("%v", p.Bursts))
+	oe.AddUint64(logAuthorQuota, p.AuthorQuota)
+	oe.AddInt(logAuthorQuotas, len(p.AuthorQuotas))
+	oe.AddDuration(logQuotaPeriod, p.QuotaPeriod)
+	oe.AddUint(logNLimited, p.NLimited)
+	return nil
+}
+
+// ParseAuthorQuotas parses author quota overrides from <hex author public key>=<bytes> strings.
+func ParseAuthorQuotas(overrides []string) (map[string]uint64, error) {
+	quotas := make(map[string]uint64, len(overrides))
+	for _, o := range overrides {
+		parts := strings.Split(o, "=")
+		if len(parts) != 2 {
+			return nil, ErrInvalidAuthorQuota
+		}
+		authorPub, err := hex.DecodeString(parts[0])
+		if err != nil || len(authorPub) == 0 {
+			return nil, ErrInvalidAuthorQuota
+		}
+		quota, err := strconv.ParseUint(parts[1], 10, 64)
+		if err != nil {
+			return nil, ErrInvalidAuthorQuota
+		}
+		quotas[hex.EncodeToString(authorPub)] = quota
+	}
+	return quotas, nil
+}
+
+// Limiter limits the rate of requests from each peer and the document bytes stored for each
+// author.
+type Limiter interface {
+	// AllowRequest returns ErrRateLimited if the requester has exceeded its rate limit for the
+	// endpoint.
+	AllowRequest(requesterID id.ID, e Endpoint) error
+
+	// AllowBytes returns ErrQuotaExceeded if storing the number of document bytes would exceed
+	// the author public key's quota.
+	AllowBytes(authorPub []byte, e Endpoint, nBytes int) error
+}
+
+type limiter struct {
+	params *LimitParameters
+
+	// token buckets for each requester ID, which are each arrays of buckets per endpoint
+	requests *lru.Cache
+
+	// byte token buckets for each author public key
+	bytes *lru.Cache
+
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+// NewLimiter creates a new Limiter with the given parameters and exports them as metrics.
+func NewLimiter(params *LimitParameters) Limiter {
+	requests, err := lru.New(int(params.NLimited))
+	if err != nil {
+		panic(err) // should never happen b/c NLimited should always be positive
+	}
+	bytes, err := lru.New(int(params.NLimited))
+	if err != nil {
+		panic(err)
+	}
+	for e := Endpoint(0); e < nEndpoints; e++ {
+		rateLimitRates.WithLabelValues(e.String()).Set(params.Rates[e])
+		rateLimitBursts.WithLabelValues(e.String()).Set(float64(params.Bursts[e]))
+	}
+	authorQuotaBytes.Set(float64(params.AuthorQuota))
+	return &limiter{
+		params:   params,
+		requests: requests,
+		bytes:    bytes,
+		now:      time.Now,
+	}
+}
+
+func (l *limiter) AllowRequest(requesterID id.ID, e Endpoint) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	key := requesterID.String()
+	value, in := l.requests.Get(key)
+	if !in {
+		buckets := new([nEndpoints]*tokenBucket)
+		for i := range buckets {
+			buckets[i] = newTokenBucket(l.params.Rates[Endpoint(i)],
+				float64(l.params.Bursts[Endpoint(i)]), l.now())
+		}
+		l.requests.Add(key, buckets)
+		value = buckets
+	}
+	if !value.(*[nEndpoints]*tokenBucket)[e].take(1, l.now()) {
+		limitedRequests.WithLabelValues(e.String(), limitedRate).Inc()
+		return ErrRateLimited
+	}
+	return nil
+}
+
+func (l *limiter) AllowBytes(authorPub []byte, e Endpoint, nBytes int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	key := hex.EncodeToString(authorPub)
+	value, in := l.bytes.Get(key)
+	if !in {
+		quota, in := l.params.AuthorQuotas[key]
+		if !in {
+			quota = l.params.AuthorQuota
+		}
+		rate := float64(quota) / l.params.QuotaPeriod.Seconds()
+		value = newTokenBucket(rate, float64(quota), l.now())
+		l.bytes.Add(key, value)
+	}
+	if !value.(*tokenBucket).take(float64(nBytes), l.now()) {
+		limitedRequests.WithLabelValues(e.String(), limitedQuota).Inc()
+		return ErrQuotaExceeded
+	}
+	return nil
+}
+
+// tokenBucket holds up to capacity tokens, which refill continuously at a given rate per second.
+type tokenBucket struct {
+	rate     float64
+	capacity float64
+	tokens   float64
+	last     time.Time
+}
+
+func newTokenBucket(rate, capacity float64, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		rate:     rate,
+		capacity: capacity,
+		tokens:   capacity,
+		last:     now,
+	}
+}
+
+// take removes n tokens from the bucket if it has that many, returning whether it did.
+func (b *tokenBucket) take(n float64, now time.Time) bool {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * b.rate
+		if b.tokens > b.capacity {
+			b.tokens = b.capacity
+		}
+		b.last = now
+	}
+	if n > b.tokens {
+		return false
+	}
+	b.tokens -= n
+	return true
+}
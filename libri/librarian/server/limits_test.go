@@ -0,0 +1,138 @@
+package server
+
+import (
+	"encoding/hex"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+func TestEndpoint_String(t *testing.T) {
+	assert.Equal(t, "find", FindEndpoint.String())
+	assert.Equal(t, "subscribe", SubscribeEndpoint.String())
+	for e := Endpoint(0); e < nEndpoints; e++ {
+		_, inRates := DefaultRates[e]
+		_, inBursts := DefaultBursts[e]
+		assert.True(t, inRates, e.String())
+		assert.True(t, inBursts, e.String())
+	}
+}
+
+func TestNewDefaultLimitParameters(t *testing.T) {
+	p := NewDefaultLimitParameters()
+	assert.Equal(t, DefaultRates, p.Rates)
+	assert.Equal(t, DefaultBursts, p.Bursts)
+
+	// check defaults aren't shared
+	p.Rates[FindEndpoint] = 0
+	assert.NotZero(t, DefaultRates[FindEndpoint])
+}
+
+func TestParseAuthorQuotas(t *testing.T) {
+	quotas, err := ParseAuthorQuotas([]string{"0A1b=1000", "ff=0"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]uint64{"0a1b": 1000, "ff": 0}, quotas)
+
+	quotas, err = ParseAuthorQuotas(nil)
+	assert.Nil(t, err)
+	assert.Empty(t, quotas)
+
+	for _, bad := range []string{"0a1b", "0a1b=1=2", "=1000", "zz=1000", "0a1b=-1", "0a1b=x"} {
+		quotas, err = ParseAuthorQuotas([]string{bad})
+		assert.Equal(t, ErrInvalidAuthorQuota, err, bad)
+		assert.Nil(t, quotas, bad)
+	}
+}
+
+func TestLimiter_AllowRequest(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	now := time.Unix(0, 0)
+	params := NewDefaultLimitParameters()
+	params.Rates[FindEndpoint], params.Bursts[FindEndpoint] = 2, 3
+	l := NewLimiter(params).(*limiter)
+	l.now = func() time.Time { return now }
+	peerID1, peerID2 := id.NewPseudoRandom(rng), id.NewPseudoRandom(rng)
+
+	// check burst is allowed but no more
+	for c := 0; c < 3; c++ {
+		assert.Nil(t, l.AllowRequest(peerID1, FindEndpoint))
+	}
+	err := l.AllowRequest(peerID1, FindEndpoint)
+	assert.Equal(t, ErrRateLimited, err)
+	assert.Equal(t, codes.ResourceExhausted, grpc.Code(err))
+
+	// check other endpoints and peers have separate budgets
+	assert.Nil(t, l.AllowRequest(peerID1, GetEndpoint))
+	assert.Nil(t, l.AllowRequest(peerID2, FindEndpoint))
+
+	// check budget refills at rate
+	now = now.Add(time.Second)
+	assert.Nil(t, l.AllowRequest(peerID1, FindEndpoint))
+	assert.Nil(t, l.AllowRequest(peerID1, FindEndpoint))
+	assert.Equal(t, ErrRateLimited, l.AllowRequest(peerID1, FindEndpoint))
+}
+
+func TestLimiter_AllowBytes(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	now := time.Unix(0, 0)
+	author1, author2 := make([]byte, 65), make([]byte, 65)
+	rng.Read(author1)
+	rng.Read(author2)
+	params := NewDefaultLimitParameters()
+	params.AuthorQuota, params.QuotaPeriod = 1000, 10*time.Second
+	params.AuthorQuotas[hex.EncodeToString(author2)] = 2000
+	l := NewLimiter(params).(*limiter)
+	l.now = func() time.Time { return now }
+
+	assert.Nil(t, l.AllowBytes(author1, StoreEndpoint, 600))
+	err := l.AllowBytes(author1, PutEndpoint, 600)
+	assert.Equal(t, ErrQuotaExceeded, err)
+	assert.Equal(t, codes.ResourceExhausted, grpc.Code(err))
+	assert.Nil(t, l.AllowBytes(author1, StoreEndpoint, 400))
+
+	// check author quota override
+	assert.Nil(t, l.AllowBytes(author2, StoreEndpoint, 1500))
+	assert.Equal(t, ErrQuotaExceeded, l.AllowBytes(author2, StoreEndpoint, 600))
+
+	// check quota refills over period
+	now = now.Add(5 * time.Second)
+	assert.Nil(t, l.AllowBytes(author1, StoreEndpoint, 500))
+	assert.Equal(t, ErrQuotaExceeded, l.AllowBytes(author1, StoreEndpoint, 1))
+}
+
+func TestTokenBucket_take(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newTokenBucket(1, 2, now)
+	assert.True(t, b.take(2, now))
+	assert.False(t, b.take(1, now))
+
+	// check tokens don't exceed capacity
+	now = now.Add(10 * time.Second)
+	assert.False(t, b.take(3, now))
+	assert.True(t, b.take(2, now))
+
+	// check clock going backwards doesn't add tokens
+	assert.False(t, b.take(1, now.Add(-time.Second)))
+}
+
+type fixedLimiter struct {
+	requestErr error
+	bytesErr   error
+	endpoints  []Endpoint
+	nBytes     int
+}
+
+func (f *fixedLimiter) AllowRequest(requesterID id.ID, e Endpoint) error {
+	f.endpoints = append(f.endpoints, e)
+	return f.requestErr
+}
+
+func (f *fixedLimiter) AllowBytes(authorPub []byte, e Endpoint, nBytes int) error {
+	f.nBytes += nBytes
+	return f.bytesErr
+}
@@ -17,8 +17,11 @@ const (
 	logKey             = "key"
 	logOperation       = "operation"
 	logNReplicas       = "n_replicas"
+	logNMatchingValues = "n_matching_values"
 	logSearch          = "search"
 	logStore           = "store"
+	logPeerIDShort     = "peer_id_short"
+	logOffense         = "offense"
 )
 
 func rqMetadataFields(md *api.RequestMetadata) []zapcore.Field {
@@ -75,6 +78,7 @@ func storeResponseFields(rq *api.StoreRequest, _ *api.StoreResponse) []zapcore.F
 func getRequestFields(rq *api.GetRequest) []zapcore.Field {
 	return []zapcore.Field{
 		zap.String(logKey, id.Hex(rq.Key)),
+		zap.Uint32(logNMatchingValues, rq.NMatchingValues),
 	}
 }
 
@@ -93,6 +97,7 @@ func searchDetailFields(s *search.Search) []zapcore.Field {
 func putRequestFields(rq *api.PutRequest) []zapcore.Field {
 	return []zapcore.Field{
 		zap.String(logKey, id.Hex(rq.Key)),
+		zap.Uint32(logNReplicas, rq.NReplicas),
 	}
 }
 
@@ -0,0 +1,230 @@
+package server
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/drausin/libri/libri/librarian/client"
+	"github.com/drausin/libri/libri/librarian/server/peer"
+	"github.com/drausin/libri/libri/librarian/server/search"
+	"github.com/golang/protobuf/proto"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// DefaultPathCacheEnabled is the default for whether librarians send cache copies of found
+	// documents along lookup paths and keep those sent by other peers.
+	DefaultPathCacheEnabled = false
+
+	// DefaultPathCacheMaxTTL is the default time a cache copy is kept by a librarian at least as
+	// close to its key as the closest peer it knows of.
+	DefaultPathCacheMaxTTL = 24 * time.Hour
+
+	// DefaultPathCacheMinTTL is the default minimum time a cache copy must be kept for to be
+	// worth keeping at all.
+	DefaultPathCacheMinTTL = 1 * time.Minute
+
+	// DefaultPathCacheTimeout is the default timeout for Store queries sending cache copies.
+	DefaultPathCacheTimeout = 5 * time.Second
+
+	// DefaultPathCacheMaxBytes is the default maximum total size of the cache copies kept.
+	DefaultPathCacheMaxBytes = 1024 * 1024 * 1024 // 1 GB
+
+	// DefaultPathCacheMaxDocuments is the default maximum number of cache copies kept.
+	DefaultPathCacheMaxDocuments = 4096
+
+	// cacheSweepPeriod is the time between deletions of expired cache copies.
+	cacheSweepPeriod = 5 * time.Minute
+
+	// logging keys
+	logPathCacheEnabled  = "path_cache_enabled"
+	logPathCacheMaxTTL   = "path_cache_max_ttl"
+	logPathCacheMinTTL   = "path_cache_min_ttl"
+	logPathCacheTimeout  = "path_cache_timeout"
+	logPathCacheMaxBytes = "path_cache_max_bytes"
+	logPathCacheMaxDocs  = "path_cache_max_documents"
+	logCachedTo          = "cached_to"
+	logNSwept            = "n_swept"
+)
+
+var (
+	errPathCacheDisabled = errors.New("path caching disabled")
+	errCacheCopyTooLarge = errors.New("cache copy larger than path cache capacity")
+)
+
+// PathCacheParameters define how librarians cache found documents on peers along lookup paths.
+type PathCacheParameters struct {
+	// Enabled is whether to send cache copies of found documents along lookup paths and keep
+	// those sent by other peers.
+	Enabled bool
+
+	// MaxTTL is the time a cache copy is kept by a librarian at least as close to its key as the
+	// closest peer it knows of. Cache copies kept by librarians farther from the key are kept
+	// for half as long for each extra bit of distance.
+	MaxTTL time.Duration
+
+	// MinTTL is the minimum time a cache copy must be kept for to be worth keeping at all.
+	MinTTL time.Duration
+
+	// Timeout is the timeout for Store queries sending cache copies.
+	Timeout time.Duration
+
+	// MaxBytes is the maximum total size of the cache copies kept, beyond which the oldest are
+	// evicted.
+	MaxBytes uint64
+
+	// MaxDocuments is the maximum number of cache copies kept, beyond which the oldest are
+	// evicted.
+	MaxDocuments uint
+}
+
+// NewDefaultPathCacheParameters creates an instance with default parameters.
+func NewDefaultPathCacheParameters() *PathCacheParameters {
+	return &PathCacheParameters{
+		Enabled:      DefaultPathCacheEnabled,
+		MaxTTL:       DefaultPathCacheMaxTTL,
+		MinTTL:       DefaultPathCacheMinTTL,
+		Timeout:      DefaultPathCacheTimeout,
+		MaxBytes:     DefaultPathCacheMaxBytes,
+		MaxDocuments: DefaultPathCacheMaxDocuments,
+	}
+}
+
+// MarshalLogObject converts the PathCacheParameters into an object (which will become json) for
+// logging.
+func (p *PathCacheParameters) MarshalLogObject(oe zapcore.ObjectEncoder) error {
+	oe.AddBool(logPathCacheEnabled, p.Enabled)
+	oe.AddDuration(logPathCacheMaxTTL, p.MaxTTL)
+	oe.AddDuration(logPathCacheMinTTL, p.MinTTL)
+	oe.AddDuration(logPathCacheTimeout, p.Timeout)
+	oe.AddUint64(logPathCacheMaxBytes, p.MaxBytes)
+	oe.AddUint(logPathCacheMaxDocs, p.MaxDocuments)
+	return nil
+}
+
+// cacheAlongPathAsync sends a cache copy of the search's found value along its lookup path in
+// the background, logging the outcome.
+func (l *Librarian) cacheAlongPathAsync(s *search.Search, logger *zap.Logger) {
+	go func() {
+		cachedTo, err := l.cacheAlongPath(s)
+		if err != nil {
+			logger.Debug("error caching value along lookup path", zap.Error(err))
+			return
+		}
+		if cachedTo != nil {
+			logger.Debug("cached value along lookup path",
+				zap.Stringer(logCachedTo, cachedTo.ID()))
+		}
+	}()
+}
+
+// cacheAlongPath sends a cache copy of the search's found value to the closest peer that
+// responded to the search without it and isn't getting a read repair, returning that peer or nil
+// if there isn't one.
+func (l *Librarian) cacheAlongPath(s *search.Search) (peer.Peer, error) {
+	if _, isPointer := s.Result.Value.Contents.(*api.Document_Pointer); isPointer {
+		// pointers are replaced by newer ones, so only cache immutable documents
+		return nil, nil
+	}
+	repairing := make(map[string]struct{})
+	if l.config.ReadRepair.Enabled {
+		for _, p := range readRepairTargets(s) {
+			repairing[p.ID().String()] = struct{}{}
+		}
+	}
+	var closest peer.Peer
+	var closestDist *big.Int
+	for idStr, p := range s.Result.Lacking {
+		if _, in := repairing[idStr]; in {
+			continue
+		}
+		if dist := s.Key.Distance(p.ID()); closest == nil || dist.Cmp(closestDist) < 0 {
+			closest, closestDist = p, dist
+		}
+	}
+	if closest == nil {
+		return nil, nil
+	}
+
+	rq := client.NewStoreRequest(l.selfID, s.Key, s.Result.Value)
+	rq.Cache = true
+	if err := l.storeTo(closest, rq, l.config.PathCache.Timeout); err != nil {
+		return nil, err
+	}
+	return closest, nil
+}
+
+// checkCacheable returns an error if the librarian won't accept a cache copy of the document, so
+// cache Store requests can be rejected before anything else is done with them.
+func (l *Librarian) checkCacheable(value *api.Document) error {
+	if !l.config.PathCache.Enabled {
+		return errPathCacheDisabled
+	}
+	if uint64(proto.Size(value)) > l.config.PathCache.MaxBytes {
+		return errCacheCopyTooLarge
+	}
+	return nil
+}
+
+// cacheDocument keeps a cache copy of a document sent by another peer, unless the librarian
+// already has the document or is too far from its key for the copy to be worth keeping.
+func (l *Librarian) cacheDocument(key id.ID, value *api.Document) error {
+	if !l.config.PathCache.Enabled {
+		return errPathCacheDisabled
+	}
+	existing, err := l.documentSL.Load(key)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return nil
+	}
+	ttl := l.cacheTTL(key)
+	if ttl < l.config.PathCache.MinTTL {
+		return nil
+	}
+	return l.documentCache.Cache(key, value, time.Now().Add(ttl))
+}
+
+// cacheTTL returns how long to keep a cache copy of the document with the given key, which halves
+// for each bit longer the librarian's distance to the key is than that of the closest peer in its
+// routing table.
+func (l *Librarian) cacheTTL(key id.ID) time.Duration {
+	selfDistLen := l.rt.SelfID().Distance(key).BitLen()
+	closestDistLen := selfDistLen
+	if closest := l.rt.Peak(key, 1); len(closest) > 0 {
+		closestDistLen = closest[0].ID().Distance(key).BitLen()
+	}
+	nHalvings := selfDistLen - closestDistLen
+	if nHalvings <= 0 {
+		return l.config.PathCache.MaxTTL
+	}
+	if nHalvings >= 63 {
+		return 0
+	}
+	return l.config.PathCache.MaxTTL >> uint(nHalvings)
+}
+
+// sweepCachePeriodically deletes expired cache copies every sweep period until the librarian is
+// stopped.
+func (l *Librarian) sweepCachePeriodically() {
+	ticker := time.NewTicker(cacheSweepPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			nSwept, err := l.documentCache.Sweep()
+			if err != nil {
+				l.logger.Error("error sweeping expired cache copies", zap.Error(err))
+				continue
+			}
+			l.logger.Debug("swept expired cache copies", zap.Int(logNSwept, nSwept))
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package server
+
+import (
+	"errors"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/drausin/libri/libri/common/db"
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
+	clogging "github.com/drausin/libri/libri/common/logging"
+	"github.com/drausin/libri/libri/common/storage"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/drausin/libri/libri/librarian/client"
+	"github.com/drausin/libri/libri/librarian/server/peer"
+	"github.com/drausin/libri/libri/librarian/server/routing"
+	"github.com/drausin/libri/libri/librarian/server/search"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+func TestLibrarian_cacheTTL(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	key := id.NewPseudoRandom(rng)
+	l := newPathCacheLibrarian(t, rng, nil, flipBit(key, 200))
+	maxTTL := l.config.PathCache.MaxTTL
+
+	// no closer peers known
+	assert.Equal(t, maxTTL, l.cacheTTL(key))
+
+	// closest peer known is 10 bits closer
+	l.rt.Push(peer.New(flipBit(key, 190), "", peer.NewTestConnector(0)))
+	assert.Equal(t, maxTTL>>10, l.cacheTTL(key))
+
+	// closest peer known is much closer
+	l.rt.Push(peer.New(flipBit(key, 0), "", peer.NewTestConnector(1)))
+	assert.Zero(t, l.cacheTTL(key))
+
+	// self is closer than all known peers
+	assert.Equal(t, maxTTL, l.cacheTTL(flipBit(l.rt.SelfID(), 100)))
+}
+
+func TestLibrarian_cacheDocument(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	kvdb, cleanup, err := db.NewTempDirRocksDB()
+	defer cleanup()
+	defer kvdb.Close()
+	assert.Nil(t, err)
+
+	cases := map[string]struct {
+		closestShift uint
+		stored       bool
+		cached       bool
+	}{
+		"close":        {closestShift: 10, cached: true},
+		"far":          {closestShift: 20, cached: false},
+		"have primary": {closestShift: 10, stored: true, cached: false},
+	}
+	for desc, c := range cases {
+		value, key := api.NewTestDocument(rng)
+		l := newPathCacheLibrarian(t, rng, kvdb, flipBit(key, 200))
+		closest := flipBit(key, 200-c.closestShift)
+		l.rt.Push(peer.New(closest, "", peer.NewTestConnector(0)))
+		if c.stored {
+			err = l.documentSL.Store(key, value)
+			assert.Nil(t, err, desc)
+		}
+
+		err = l.cacheDocument(key, value)
+		assert.Nil(t, err, desc)
+		cached, err := l.documentCache.Load(key)
+		assert.Nil(t, err, desc)
+		if c.cached {
+			assert.Equal(t, value, cached, desc)
+		} else {
+			assert.Nil(t, cached, desc)
+		}
+	}
+
+	// path caching disabled
+	value, key := api.NewTestDocument(rng)
+	l := newPathCacheLibrarian(t, rng, kvdb, flipBit(key, 200))
+	l.config.PathCache.Enabled = false
+	err = l.cacheDocument(key, value)
+	assert.Equal(t, errPathCacheDisabled, err)
+}
+
+func TestLibrarian_cacheAlongPath_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	value, key := api.NewTestDocument(rng)
+	l := newPathCacheLibrarian(t, rng, nil, id.NewPseudoRandom(rng))
+	apiStorer := &fixedAPIStorer{requests: make(chan *api.StoreRequest, 1)}
+	l.storerCreator = &fixedStorerCreator{storer: apiStorer}
+
+	s := search.NewSearch(l.selfID, key, search.NewDefaultParameters())
+	s.Result.Value = value
+	lacking := peer.NewTestPeers(rng, 8)
+	closest := lacking[0]
+	for _, p := range lacking {
+		s.Result.Lacking[p.ID().String()] = p
+		if key.Distance(p.ID()).Cmp(key.Distance(closest.ID())) < 0 {
+			closest = p
+		}
+	}
+
+	cachedTo, err := l.cacheAlongPath(s)
+	assert.Nil(t, err)
+	assert.Equal(t, closest, cachedTo)
+	rq := <-apiStorer.requests
+	assert.True(t, rq.Cache)
+	assert.Equal(t, key.Bytes(), rq.Key)
+	assert.Equal(t, value, rq.Value)
+
+	// no peers lacking the value
+	s.Result.Lacking = make(map[string]peer.Peer)
+	cachedTo, err = l.cacheAlongPath(s)
+	assert.Nil(t, err)
+	assert.Nil(t, cachedTo)
+
+	// pointers aren't cached
+	s.Result.Lacking[closest.ID().String()] = closest
+	s.Result.Value = &api.Document{
+		Contents: &api.Document_Pointer{Pointer: api.NewTestPointer(rng)},
+	}
+	cachedTo, err = l.cacheAlongPath(s)
+	assert.Nil(t, err)
+	assert.Nil(t, cachedTo)
+}
+
+func TestLibrarian_cacheAlongPath_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	value, key := api.NewTestDocument(rng)
+	l := newPathCacheLibrarian(t, rng, nil, id.NewPseudoRandom(rng))
+	s := search.NewSearch(l.selfID, key, search.NewDefaultParameters())
+	s.Result.Value = value
+	p := peer.NewTestPeer(rng, 0)
+	s.Result.Lacking[p.ID().String()] = p
+
+	cases := map[string]client.StorerCreator{
+		"create err": &fixedStorerCreator{err: errors.New("some create error")},
+		"store err": &fixedStorerCreator{
+			storer: &fixedAPIStorer{err: errors.New("some store error")},
+		},
+		"request ID": &fixedStorerCreator{
+			storer: &fixedAPIStorer{requestID: []byte{1, 2, 3}},
+		},
+	}
+	for desc, sc := range cases {
+		l.storerCreator = sc
+		cachedTo, err := l.cacheAlongPath(s)
+		assert.NotNil(t, err, desc)
+		assert.Nil(t, cachedTo, desc)
+	}
+}
+
+func newPathCacheLibrarian(
+	t *testing.T, rng *rand.Rand, kvdb db.KVDB, rtSelfID id.ID,
+) *Librarian {
+	peerID := ecid.NewPseudoRandom(rng)
+	config := NewDefaultConfig()
+	config.PathCache.Enabled = true
+	l := &Librarian{
+		selfID: peerID,
+		config: config,
+		rt: routing.NewEmpty(rtSelfID, routing.NewDefaultParameters(),
+			peer.NewDefaultReputations()),
+		signer: client.NewSigner(peerID.Signer()),
+		logger: clogging.NewDevInfoLogger(),
+	}
+	if kvdb != nil {
+		l.documentSL = storage.NewDocumentSLD(kvdb)
+		l.documentCache = newTestDocumentCache(t, kvdb)
+	}
+	return l
+}
+
+func newTestDocumentCache(t *testing.T, kvdb db.KVDB) storage.DocumentCache {
+	dc, err := storage.LoadDocumentCache(kvdb, DefaultPathCacheMaxBytes,
+		DefaultPathCacheMaxDocuments)
+	assert.Nil(t, err)
+	return dc
+}
+
+// flipBit returns the ID with the given bit flipped, which is at a distance of 2^bit from it.
+func flipBit(x id.ID, bit uint) id.ID {
+	return id.FromInt(new(big.Int).Xor(x.Int(), new(big.Int).Lsh(big.NewInt(1), bit)))
+}
+
+type fixedStorerCreator struct {
+	storer api.Storer
+	err    error
+}
+
+func (c *fixedStorerCreator) Create(pConn peer.Connector) (api.Storer, error) {
+	return c.storer, c.err
+}
+
+type fixedAPIStorer struct {
+	requestID []byte
+	err       error
+	requests  chan *api.StoreRequest
+}
+
+func (f *fixedAPIStorer) Store(ctx context.Context, rq *api.StoreRequest,
+	opts ...grpc.CallOption) (*api.StoreResponse, error) {
+
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.requests != nil {
+		f.requests <- rq
+	}
+	requestID := f.requestID
+	if requestID == nil {
+		requestID = rq.Metadata.RequestId
+	}
+	return &api.StoreResponse{
+		Metadata: &api.ResponseMetadata{RequestId: requestID},
+	}, nil
+}
@@ -80,8 +80,7 @@ func (p *peer) ID() id.ID {
 }
 
 func (p *peer) Before(q Peer) bool {
-	pr, qr := p.recorder.(*queryRecorder), q.(*peer).recorder.(*queryRecorder)
-	return pr.responses.latest.Before(qr.responses.latest)
+	return p.recorder.LatestResponse().Before(q.(*peer).recorder.LatestResponse())
 }
 
 func (p *peer) Merge(other Peer) error {
@@ -1,6 +1,7 @@
 package peer
 
 import (
+	"sync"
 	"time"
 
 	"github.com/drausin/libri/libri/common/storage"
@@ -28,12 +29,44 @@ const (
 	Error
 )
 
-// Recorder tracks statistics associated with queries to/from a peer.
+const (
+	// ewmaWeight is the weight of each new observation in the exponentially-weighted moving
+	// averages of response latency and error rate.
+	ewmaWeight = 0.2
+
+	// maxErrorRate caps the error rate used when inflating expected latencies so that peers
+	// that always error still have finite expected latencies.
+	maxErrorRate = 0.99
+)
+
+// Recorder tracks statistics associated with queries to/from a peer. Its methods are concurrency
+// safe.
 type Recorder interface {
 
 	// Record an outcome for a particular query type.
 	Record(t QueryType, o Outcome)
 
+	// RecordLatency records the round-trip latency of a query response from the peer, whether
+	// successful or not.
+	RecordLatency(latency time.Duration)
+
+	// Latency returns the moving average of the peer's query response latency.
+	Latency() time.Duration
+
+	// ErrorRate returns the moving average of the fraction of queries to the peer that errored.
+	ErrorRate() float64
+
+	// LatestResponse returns the time of the latest query response from the peer.
+	LatestResponse() time.Time
+
+	// LastSeen returns the time of the latest successful query to or from the peer.
+	LastSeen() time.Time
+
+	// ExpectedLatency returns the expected time to receive a successful response from the peer,
+	// i.e., its latency inflated by its error rate. Peers without any recorded latencies have an
+	// expected latency of zero.
+	ExpectedLatency() time.Duration
+
 	// Merge combines the stats of the other and current recorder instances.
 	Merge(other Recorder)
 
@@ -44,16 +77,27 @@ type Recorder interface {
 type queryRecorder struct {
 	requests  *queryTypeOutcomes
 	responses *queryTypeOutcomes
+
+	// latest successful query time to or from the peer
+	lastSeen time.Time
+
+	mu sync.Mutex
 }
 
 func newQueryRecorder() *queryRecorder {
 	return &queryRecorder{
 		requests:  newQueryTypeOutcomes(),
 		responses: newQueryTypeOutcomes(),
+		lastSeen:  time.Unix(0, 0).UTC(),
 	}
 }
 
 func (qr *queryRecorder) Record(t QueryType, o Outcome) {
+	qr.mu.Lock()
+	defer qr.mu.Unlock()
+	if o == Success {
+		qr.lastSeen = time.Now().UTC()
+	}
 	if t == Request {
 		qr.requests.Record(o)
 		return
@@ -64,15 +108,69 @@ func (qr *queryRecorder) Record(t QueryType, o Outcome) {
 	}
 }
 
+func (qr *queryRecorder) RecordLatency(latency time.Duration) {
+	qr.mu.Lock()
+	defer qr.mu.Unlock()
+	qr.responses.RecordLatency(latency)
+}
+
+func (qr *queryRecorder) Latency() time.Duration {
+	qr.mu.Lock()
+	defer qr.mu.Unlock()
+	return qr.responses.latency
+}
+
+func (qr *queryRecorder) ErrorRate() float64 {
+	qr.mu.Lock()
+	defer qr.mu.Unlock()
+	return qr.responses.errorRate
+}
+
+func (qr *queryRecorder) LatestResponse() time.Time {
+	qr.mu.Lock()
+	defer qr.mu.Unlock()
+	return qr.responses.latest
+}
+
+func (qr *queryRecorder) LastSeen() time.Time {
+	qr.mu.Lock()
+	defer qr.mu.Unlock()
+	return qr.lastSeen
+}
+
+func (qr *queryRecorder) ExpectedLatency() time.Duration {
+	qr.mu.Lock()
+	defer qr.mu.Unlock()
+	errorRate := qr.responses.errorRate
+	if errorRate > maxErrorRate {
+		errorRate = maxErrorRate
+	}
+	return time.Duration(float64(qr.responses.latency) / (1 - errorRate))
+}
+
 func (qr *queryRecorder) Merge(other Recorder) {
-	qr.requests.Merge(other.(*queryRecorder).requests)
-	qr.responses.Merge(other.(*queryRecorder).responses)
+	// copy the other's stats first so merging never holds both locks
+	oqr := other.(*queryRecorder)
+	oqr.mu.Lock()
+	requests, responses, lastSeen := *oqr.requests, *oqr.responses, oqr.lastSeen
+	oqr.mu.Unlock()
+
+	qr.mu.Lock()
+	defer qr.mu.Unlock()
+	qr.requests.Merge(&requests)
+	qr.responses.Merge(&responses)
+	if qr.lastSeen.Before(lastSeen) {
+		qr.lastSeen = lastSeen
+	}
 }
 
 func (qr *queryRecorder) ToStored() *storage.QueryOutcomes {
+	qr.mu.Lock()
+	defer qr.mu.Unlock()
 	return &storage.QueryOutcomes{
 		Requests:  qr.requests.ToStored(),
 		Responses: qr.responses.ToStored(),
+		LastSeen:  qr.lastSeen.Unix(),
 	}
 }
 
@@ -88,6 +186,12 @@ type queryTypeOutcomes struct {
 
 	// number of queries that resulted an in error
 	nErrors uint64
+
+	// moving average of query round-trip latency
+	latency time.Duration
+
+	// moving average of the fraction of queries that resulted in an error
+	errorRate float64
 }
 
 func newQueryTypeOutcomes() *queryTypeOutcomes {
@@ -100,8 +204,15 @@ func newQueryTypeOutcomes() *queryTypeOutcomes {
 }
 
 func (qto *queryTypeOutcomes) Record(o Outcome) {
+	errored := 0.0
 	if o == Error {
 		qto.nErrors++
+		errored = 1.0
+	}
+	if qto.nQueries == 0 {
+		qto.errorRate = errored
+	} else {
+		qto.errorRate = ewmaWeight*errored + (1-ewmaWeight)*qto.errorRate
 	}
 	qto.nQueries++
 	qto.latest = time.Now().UTC()
@@ -110,7 +221,30 @@ func (qto *queryTypeOutcomes) Record(o Outcome) {
 	}
 }
 
+func (qto *queryTypeOutcomes) RecordLatency(latency time.Duration) {
+	if qto.latency == 0 {
+		qto.latency = latency
+		return
+	}
+	qto.latency = time.Duration(ewmaWeight*float64(latency) +
+		(1-ewmaWeight)*float64(qto.latency))
+}
+
 func (qto *queryTypeOutcomes) Merge(other *queryTypeOutcomes) {
+	// weight moving averages by the number of queries behind each
+	if nQueries := qto.nQueries + other.nQueries; nQueries > 0 {
+		w := float64(qto.nQueries) / float64(nQueries)
+		switch {
+		case other.latency == 0:
+			// keep current latency
+		case qto.latency == 0:
+			qto.latency = other.latency
+		default:
+			qto.latency = time.Duration(w*float64(qto.latency) +
+				(1-w)*float64(other.latency))
+		}
+		qto.errorRate = w*qto.errorRate + (1-w)*other.errorRate
+	}
 	if qto.earliest.After(other.earliest) {
 		qto.earliest = other.earliest
 	}
@@ -123,9 +257,11 @@ func (qto *queryTypeOutcomes) Merge(other *queryTypeOutcomes) {
 
 func (qto *queryTypeOutcomes) ToStored() *storage.QueryTypeOutcomes {
 	return &storage.QueryTypeOutcomes{
-		Earliest: qto.earliest.Unix(),
-		Latest:   qto.latest.Unix(),
-		NQueries: qto.nQueries,
-		NErrors:  qto.nErrors,
+		Earliest:  qto.earliest.Unix(),
+		Latest:    qto.latest.Unix(),
+		NQueries:  qto.nQueries,
+		NErrors:   qto.nErrors,
+		Latency:   int64(qto.latency),
+		ErrorRate: qto.errorRate,
 	}
 }
@@ -16,6 +16,8 @@ func TestQueryRecorder_Record_success(t *testing.T) {
 	assert.True(t, r.responses.latest.Unix() > 0)
 	assert.True(t, r.responses.earliest.Unix() > 0)
 	assert.True(t, r.responses.latest.Equal(r.responses.earliest))
+	assert.Equal(t, r.responses.latest, r.LatestResponse())
+	assert.WithinDuration(t, r.responses.latest, r.LastSeen(), time.Second)
 
 	// check that requests hasn't been touched
 	assert.Equal(t, uint64(0), r.requests.nQueries)
@@ -48,6 +50,7 @@ func TestQueryRecorder_Record_error(t *testing.T) {
 	assert.True(t, r.responses.latest.Unix() > 0)
 	assert.True(t, r.responses.earliest.Unix() > 0)
 	assert.True(t, r.responses.latest.Equal(r.responses.earliest))
+	assert.Equal(t, int64(0), r.LastSeen().Unix())
 
 	// check that requests hasn't been touched
 	assert.Equal(t, uint64(0), r.requests.nQueries)
@@ -69,6 +72,9 @@ func TestQueryRecorder_Record_error(t *testing.T) {
 	assert.Equal(t, uint64(1), r.requests.nErrors)
 	assert.True(t, r.requests.latest.Unix() > 0)
 	assert.True(t, r.requests.earliest.Unix() > 0)
+
+	// only the successful response counts as being seen
+	assert.WithinDuration(t, r.responses.latest, r.LastSeen(), time.Second)
 }
 
 func TestQueryRecorder_Merge(t *testing.T) {
@@ -101,4 +107,86 @@ func TestQueryRecorder_Merge(t *testing.T) {
 
 	// r1 gets r2's latest response time
 	assert.True(t, r1.responses.latest.Equal(r2.responses.latest))
+
+	// r1 gets r2's last seen time
+	assert.True(t, r1.LastSeen().Equal(r2.LastSeen()))
+}
+
+func TestQueryRecorder_Merge_movingAverages(t *testing.T) {
+	r1 := newQueryRecorder()
+	r1.Record(Response, Success)
+	r1.Record(Response, Success)
+	r1.Record(Response, Success)
+	r1.RecordLatency(10 * time.Millisecond)
+
+	r2 := newQueryRecorder()
+	r2.Record(Response, Error)
+	r2.RecordLatency(50 * time.Millisecond)
+
+	r1.Merge(r2)
+
+	// averages weighted by number of queries of each
+	assert.Equal(t, 20*time.Millisecond, r1.Latency())
+	assert.InDelta(t, 0.25, r1.ErrorRate(), 1e-9)
+
+	// merging recorder without latencies keeps existing
+	r1.Merge(newQueryRecorder())
+	assert.Equal(t, 20*time.Millisecond, r1.Latency())
+}
+
+func TestQueryRecorder_RecordLatency(t *testing.T) {
+	r := newQueryRecorder()
+	assert.Zero(t, r.Latency())
+
+	// first latency is taken as is
+	r.RecordLatency(100 * time.Millisecond)
+	assert.Equal(t, 100*time.Millisecond, r.Latency())
+
+	// subsequent latencies move average toward them
+	r.RecordLatency(200 * time.Millisecond)
+	assert.Equal(t, 120*time.Millisecond, r.Latency())
+	for c := 0; c < 100; c++ {
+		r.RecordLatency(200 * time.Millisecond)
+	}
+	assert.InDelta(t, float64(200*time.Millisecond), float64(r.Latency()),
+		float64(time.Millisecond))
+
+	// only response latencies are tracked
+	assert.Zero(t, r.requests.latency)
+}
+
+func TestQueryRecorder_ErrorRate(t *testing.T) {
+	r := newQueryRecorder()
+	assert.Zero(t, r.ErrorRate())
+
+	r.Record(Response, Error)
+	assert.Equal(t, 1.0, r.ErrorRate())
+
+	r.Record(Response, Success)
+	assert.InDelta(t, 0.8, r.ErrorRate(), 1e-9)
+	for c := 0; c < 100; c++ {
+		r.Record(Response, Success)
+	}
+	assert.InDelta(t, 0.0, r.ErrorRate(), 1e-6)
+
+	// request errors don't affect response error rate
+	r.Record(Request, Error)
+	assert.InDelta(t, 0.0, r.ErrorRate(), 1e-6)
+}
+
+func TestQueryRecorder_ExpectedLatency(t *testing.T) {
+	r := newQueryRecorder()
+	assert.Zero(t, r.ExpectedLatency())
+
+	r.Record(Response, Success)
+	r.RecordLatency(100 * time.Millisecond)
+	assert.Equal(t, 100*time.Millisecond, r.ExpectedLatency())
+
+	// errors inflate expected latency
+	r.Record(Response, Error)
+	assert.InDelta(t, float64(125*time.Millisecond), float64(r.ExpectedLatency()), 1)
+
+	// peers that always error still have finite expected latency
+	r.responses.errorRate = 1.0
+	assert.InDelta(t, float64(10*time.Second), float64(r.ExpectedLatency()), 1)
 }
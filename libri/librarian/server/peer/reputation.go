@@ -0,0 +1,290 @@
+package peer
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/common/storage"
+	"github.com/golang/protobuf/proto"
+	lru "github.com/hashicorp/golang-lru"
+	"go.uber.org/zap/zapcore"
+)
+
+// Offense is a kind of peer misbehavior that counts against its reputation.
+type Offense int
+
+const (
+	// InvalidSignature denotes a request from the peer that failed signature verification.
+	InvalidSignature Offense = iota
+
+	// InvalidDocument denotes a document from the peer that failed api.ValidateDocument.
+	InvalidDocument
+
+	// InvalidValue denotes a value from the peer whose hash doesn't match its key.
+	InvalidValue
+
+	// Timeout denotes a query to the peer that timed out.
+	Timeout
+
+	nOffenses = iota
+)
+
+var offenseNames = [nOffenses]string{
+	"invalid_signature",
+	"invalid_document",
+	"invalid_value",
+	"timeout",
+}
+
+// String returns the lowercase name of the offense.
+func (o Offense) String() string {
+	return offenseNames[o]
+}
+
+const (
+	// DefaultScoreHalfLife is the default time for a peer's penalty score to decay by half.
+	DefaultScoreHalfLife = 1 * time.Hour
+
+	// DefaultBlacklistThreshold is the default penalty score at which peers are blacklisted.
+	DefaultBlacklistThreshold = 100.0
+
+	// DefaultBlacklistDuration is the default time peers remain blacklisted.
+	DefaultBlacklistDuration = 24 * time.Hour
+
+	// DefaultNScored is the default number of peers whose penalty scores are tracked.
+	DefaultNScored = 4096
+
+	// logging keys
+	logPenalties          = "penalties"
+	logScoreHalfLife      = "score_half_life"
+	logBlacklistThreshold = "blacklist_threshold"
+	logBlacklistDuration  = "blacklist_duration"
+	logNScored            = "n_scored"
+)
+
+// DefaultPenalties are the default amounts each offense adds to a peer's penalty score.
+var DefaultPenalties = map[Offense]float64{
+	InvalidSignature: 10,
+	InvalidDocument:  25,
+	InvalidValue:     50,
+	Timeout:          5,
+}
+
+var blacklistKey = []byte("Blacklist")
+
+// ReputationParameters define how peers are scored and blacklisted.
+type ReputationParameters struct {
+	// Penalties are the amounts each offense adds to a peer's penalty score.
+	Penalties map[Offense]float64
+
+	// ScoreHalfLife is the time for a peer's penalty score to decay by half.
+	ScoreHalfLife time.Duration
+
+	// BlacklistThreshold is the penalty score at which a peer is blacklisted.
+	BlacklistThreshold float64
+
+	// BlacklistDuration is the time a peer remains blacklisted.
+	BlacklistDuration time.Duration
+
+	// NScored is the number of peers whose penalty scores are tracked. Scores of the least
+	// recently penalized are forgotten beyond this.
+	NScored uint
+}
+
+// NewDefaultReputationParameters creates an instance with default parameters.
+func NewDefaultReputationParameters() *ReputationParameters {
+	penalties := make(map[Offense]float64, len(DefaultPenalties))
+	for o, penalty := range DefaultPenalties {
+		penalties[o] = penalty
+	}
+	return &ReputationParameters{
+		Penalties:          penalties,
+		ScoreHalfLife:      DefaultScoreHalfLife,
+		BlacklistThreshold: DefaultBlacklistThreshold,
+		BlacklistDuration:  DefaultBlacklistDuration,
+		NScored:            DefaultNScored,
+	}
+}
+
+// MarshalLogObject converts the ReputationParameters into an object (which will become json) for
+// logging.
+func (p *ReputationParameters) MarshalLogObject(oe zapcore.ObjectEncoder) error {
+	oe.AddString(logPenalties, fmt.Sprintf("%v", p.Penalties))
+	oe.AddDuration(logScoreHalfLife, p.ScoreHalfLife)
+	oe.AddFloat64(logBlacklistThreshold, p.BlacklistThreshold)
+	oe.AddDuration(logBlacklistDuration, p.BlacklistDuration)
+	oe.AddUint(logNScored, p.NScored)
+	return nil
+}
+
+// Reputations scores peers by their offenses and blacklists those whose scores get too high.
+type Reputations interface {
+	// Penalize adds the offense's penalty to the peer's score, returning whether the peer was
+	// newly blacklisted as a result.
+	Penalize(peerID id.ID, o Offense) bool
+
+	// Score returns the peer's current penalty score, which decays over time.
+	Score(peerID id.ID) float64
+
+	// Blacklisted returns whether the peer is currently blacklisted.
+	Blacklisted(peerID id.ID) bool
+
+	// Save stores the blacklist via the NamespaceStorer.
+	Save(ns storage.NamespaceStorer) error
+}
+
+type reputations struct {
+	params *ReputationParameters
+
+	// penalty scores, keyed by string encoding of the peer ID
+	scores *lru.Cache
+
+	// times when blacklisted peers are no longer blacklisted, keyed by string encoding of the
+	// peer ID
+	blacklist map[string]time.Time
+
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+// NewReputations creates a new Reputations instance with an empty blacklist.
+func NewReputations(params *ReputationParameters) Reputations {
+	scores, err := lru.New(int(params.NScored))
+	if err != nil {
+		panic(err) // should never happen b/c NScored should always be positive
+	}
+	return &reputations{
+		params:    params,
+		scores:    scores,
+		blacklist: make(map[string]time.Time),
+		now:       time.Now,
+	}
+}
+
+// NewDefaultReputations creates a new Reputations instance with default parameters.
+func NewDefaultReputations() Reputations {
+	return NewReputations(NewDefaultReputationParameters())
+}
+
+// LoadReputations creates a new Reputations instance with the blacklist from the KV DB, if one
+// has been saved.
+func LoadReputations(nl storage.NamespaceLoader, params *ReputationParameters) (Reputations,
+	error) {
+	bytes, err := nl.Load(blacklistKey)
+	if err != nil {
+		return nil, err
+	}
+	reps := NewReputations(params).(*reputations)
+	if bytes == nil {
+		return reps, nil
+	}
+	stored := &storage.Blacklist{}
+	if err := proto.Unmarshal(bytes, stored); err != nil {
+		return nil, err
+	}
+	for _, bp := range stored.Peers {
+		reps.blacklist[id.FromBytes(bp.Id).String()] = time.Unix(bp.Until, 0)
+	}
+	return reps, nil
+}
+
+// score is a penalty score as of a given time.
+type score struct {
+	value float64
+	as    time.Time
+}
+
+func (r *reputations) Penalize(peerID id.ID, o Offense) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	key := peerID.String()
+	if r.blacklisted(key, now) {
+		return false
+	}
+	s := r.decayedScore(key, now)
+	s.value += r.params.Penalties[o]
+	r.scores.Add(key, s)
+	if s.value < r.params.BlacklistThreshold {
+		return false
+	}
+	r.blacklist[key] = now.Add(r.params.BlacklistDuration)
+	r.scores.Remove(key) // start afresh once no longer blacklisted
+	return true
+}
+
+func (r *reputations) Score(peerID id.ID) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.decayedScore(peerID.String(), r.now()).value
+}
+
+func (r *reputations) Blacklisted(peerID id.ID) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.blacklisted(peerID.String(), r.now())
+}
+
+func (r *reputations) Save(ns storage.NamespaceStorer) error {
+	// hold lock until stored so concurrent saves can't overwrite a newer blacklist with an older
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	stored := &storage.Blacklist{
+		Peers: make([]*storage.BlacklistedPeer, 0, len(r.blacklist)),
+	}
+	for key, until := range r.blacklist {
+		if !r.blacklisted(key, now) {
+			continue
+		}
+		peerID, err := id.FromString(key)
+		if err != nil {
+			return err
+		}
+		stored.Peers = append(stored.Peers, &storage.BlacklistedPeer{
+			Id:    peerID.Bytes(),
+			Until: until.Unix(),
+		})
+	}
+	if len(stored.Peers) == 0 {
+		// nothing to store, and any previously stored peers have since expired
+		return nil
+	}
+
+	bytes, err := proto.Marshal(stored)
+	if err != nil {
+		return err
+	}
+	return ns.Store(blacklistKey, bytes)
+}
+
+// blacklisted returns whether the peer is blacklisted as of now, removing it from the blacklist
+// if it has expired.
+func (r *reputations) blacklisted(key string, now time.Time) bool {
+	until, in := r.blacklist[key]
+	if !in {
+		return false
+	}
+	if now.Before(until) {
+		return true
+	}
+	delete(r.blacklist, key)
+	return false
+}
+
+// decayedScore returns the peer's score decayed to now.
+func (r *reputations) decayedScore(key string, now time.Time) *score {
+	value, in := r.scores.Get(key)
+	if !in {
+		return &score{as: now}
+	}
+	s := value.(*score)
+	if elapsed := now.Sub(s.as); elapsed > 0 {
+		s.value *= math.Exp2(-elapsed.Seconds() / r.params.ScoreHalfLife.Seconds())
+		s.as = now
+	}
+	return s
+}
@@ -0,0 +1,121 @@
+package peer
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/drausin/libri/libri/common/db"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/common/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOffense_String(t *testing.T) {
+	for o := Offense(0); o < nOffenses; o++ {
+		assert.NotEmpty(t, o.String())
+	}
+}
+
+func TestReputations_Penalize_decay(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	params := NewDefaultReputationParameters()
+	reps := NewReputations(params).(*reputations)
+	now := time.Now()
+	reps.now = func() time.Time { return now }
+	peerID := id.NewPseudoRandom(rng)
+
+	assert.Zero(t, reps.Score(peerID))
+	assert.False(t, reps.Penalize(peerID, InvalidDocument))
+	assert.Equal(t, params.Penalties[InvalidDocument], reps.Score(peerID))
+
+	// score should halve after each half life
+	now = now.Add(params.ScoreHalfLife)
+	assert.InDelta(t, params.Penalties[InvalidDocument]/2, reps.Score(peerID), 1e-9)
+	now = now.Add(params.ScoreHalfLife)
+	assert.InDelta(t, params.Penalties[InvalidDocument]/4, reps.Score(peerID), 1e-9)
+	assert.False(t, reps.Blacklisted(peerID))
+
+	// other peers are unaffected
+	assert.Zero(t, reps.Score(id.NewPseudoRandom(rng)))
+}
+
+func TestReputations_Penalize_blacklist(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	params := NewDefaultReputationParameters()
+	reps := NewReputations(params).(*reputations)
+	now := time.Now()
+	reps.now = func() time.Time { return now }
+	peerID := id.NewPseudoRandom(rng)
+
+	nTimeouts := int(params.BlacklistThreshold / params.Penalties[Timeout])
+	for c := 0; c < nTimeouts-1; c++ {
+		assert.False(t, reps.Penalize(peerID, Timeout))
+		assert.False(t, reps.Blacklisted(peerID))
+	}
+	assert.True(t, reps.Penalize(peerID, Timeout))
+	assert.True(t, reps.Blacklisted(peerID))
+
+	// only newly blacklisted peers are indicated
+	assert.False(t, reps.Penalize(peerID, InvalidValue))
+	assert.True(t, reps.Blacklisted(peerID))
+
+	// blacklisting expires
+	now = now.Add(params.BlacklistDuration)
+	assert.False(t, reps.Blacklisted(peerID))
+	assert.Zero(t, reps.Score(peerID))
+}
+
+func TestReputations_SaveLoad(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	params := NewDefaultReputationParameters()
+	params.BlacklistThreshold = params.Penalties[InvalidValue]
+	reps1 := NewReputations(params)
+	blacklisted, penalized := id.NewPseudoRandom(rng), id.NewPseudoRandom(rng)
+	assert.True(t, reps1.Penalize(blacklisted, InvalidValue))
+	assert.False(t, reps1.Penalize(penalized, Timeout))
+
+	kvdb, cleanup, err := db.NewTempDirRocksDB()
+	defer cleanup()
+	defer kvdb.Close()
+	assert.Nil(t, err)
+	ssl := storage.NewServerSL(kvdb)
+
+	err = reps1.Save(ssl)
+	assert.Nil(t, err)
+
+	reps2, err := LoadReputations(ssl, params)
+	assert.Nil(t, err)
+	assert.True(t, reps2.Blacklisted(blacklisted))
+	assert.False(t, reps2.Blacklisted(penalized))
+	assert.Zero(t, reps2.Score(penalized))
+}
+
+func TestLoadReputations_err(t *testing.T) {
+	params := NewDefaultReputationParameters()
+
+	// simulates missing/not stored blacklist
+	reps1, err := LoadReputations(&fixedLoader{}, params)
+	assert.NotNil(t, reps1)
+	assert.Nil(t, err)
+
+	// simulates loading error
+	reps2, err := LoadReputations(&fixedLoader{err: errors.New("some load error")}, params)
+	assert.Nil(t, reps2)
+	assert.NotNil(t, err)
+
+	// simulates bad stored blacklist
+	reps3, err := LoadReputations(&fixedLoader{bytes: []byte("the wrong bytes")}, params)
+	assert.Nil(t, reps3)
+	assert.NotNil(t, err)
+}
+
+type fixedLoader struct {
+	bytes []byte
+	err   error
+}
+
+func (l *fixedLoader) Load(key []byte) ([]byte, error) {
+	return l.bytes, l.err
+}
@@ -36,15 +36,18 @@ func fromStoredQueryOutcomes(stored *storage.QueryOutcomes) *queryRecorder {
 	return &queryRecorder{
 		responses: fromStoredQueryTypeOutcomes(stored.Responses),
 		requests:  fromStoredQueryTypeOutcomes(stored.Requests),
+		lastSeen:  time.Unix(stored.LastSeen, int64(0)).UTC(),
 	}
 }
 
 // fromStoredQueryTypeOutcomes creates a queryTypeOutcomes from a storage.QueryTypeOutcomes.
 func fromStoredQueryTypeOutcomes(stored *storage.QueryTypeOutcomes) *queryTypeOutcomes {
 	return &queryTypeOutcomes{
-		earliest: time.Unix(stored.Earliest, int64(0)).UTC(),
-		latest:   time.Unix(stored.Latest, int64(0)).UTC(),
-		nQueries: stored.NQueries,
-		nErrors:  stored.NErrors,
+		earliest:  time.Unix(stored.Earliest, int64(0)).UTC(),
+		latest:    time.Unix(stored.Latest, int64(0)).UTC(),
+		nQueries:  stored.NQueries,
+		nErrors:   stored.NErrors,
+		latency:   time.Duration(stored.Latency),
+		errorRate: stored.ErrorRate,
 	}
 }
@@ -43,36 +43,50 @@ func TestToStoredAddress(t *testing.T) {
 
 func TestFromStoredQueryOutcomes(t *testing.T) {
 	now, nQueries, nErrors := time.Now().Unix(), uint64(2), uint64(1)
+	latency, errorRate := 10*time.Millisecond, 0.2
 	from := &storage.QueryOutcomes{
 		Responses: &storage.QueryTypeOutcomes{
-			Earliest: now,
-			Latest:   now,
-			NQueries: nQueries,
-			NErrors:  nErrors,
+			Earliest:  now,
+			Latest:    now,
+			NQueries:  nQueries,
+			NErrors:   nErrors,
+			Latency:   int64(latency),
+			ErrorRate: errorRate,
 		},
 		Requests: &storage.QueryTypeOutcomes{}, // all zeros
+		LastSeen: now,
 	}
 	to := fromStoredQueryOutcomes(from)
 	assert.Equal(t, time.Unix(now, 0).UTC(), to.responses.earliest)
 	assert.Equal(t, time.Unix(now, 0).UTC(), to.responses.latest)
 	assert.Equal(t, nQueries, to.responses.nQueries)
 	assert.Equal(t, nErrors, to.responses.nErrors)
+	assert.Equal(t, latency, to.responses.latency)
+	assert.Equal(t, errorRate, to.responses.errorRate)
+	assert.Equal(t, time.Unix(now, 0).UTC(), to.lastSeen)
 }
 
 func TestToStoredQueryOutcomes(t *testing.T) {
 	now, nQueries, nErrors := time.Now().UTC(), uint64(2), uint64(1)
+	latency, errorRate := 10*time.Millisecond, 0.2
 	qr := &queryRecorder{
 		responses: &queryTypeOutcomes{
-			earliest: now,
-			latest:   now,
-			nQueries: nQueries,
-			nErrors:  nErrors,
+			earliest:  now,
+			latest:    now,
+			nQueries:  nQueries,
+			nErrors:   nErrors,
+			latency:   latency,
+			errorRate: errorRate,
 		},
 		requests: &queryTypeOutcomes{}, // all zeros
+		lastSeen: now,
 	}
 	sqr := qr.ToStored()
 	assert.Equal(t, now.Unix(), sqr.Responses.Earliest)
 	assert.Equal(t, now.Unix(), sqr.Responses.Latest)
 	assert.Equal(t, nQueries, sqr.Responses.NQueries)
 	assert.Equal(t, nErrors, sqr.Responses.NErrors)
+	assert.Equal(t, int64(latency), sqr.Responses.Latency)
+	assert.Equal(t, errorRate, sqr.Responses.ErrorRate)
+	assert.Equal(t, now.Unix(), sqr.LastSeen)
 }
@@ -23,6 +23,7 @@ func NewTestPeer(rng *rand.Rand, idx int) Peer {
 	recorder.Record(Response, Success)
 	recorder.responses.latest = now
 	recorder.responses.earliest = now
+	recorder.lastSeen = now
 
 	return New(
 		id.NewPseudoRandom(rng),
@@ -72,8 +73,10 @@ func NewTestStoredPeer(rng *rand.Rand, idx int) *storage.Peer {
 				Latest:   now.Unix(),
 				NQueries: 1,
 				NErrors:  0,
+				Latency:  int64(time.Duration(idx+1) * time.Millisecond),
 			},
 			Requests: &storage.QueryTypeOutcomes{}, // everything will be zero
+			LastSeen: now.Unix(),
 		},
 	}
 }
@@ -90,6 +93,9 @@ func AssertPeersEqual(t *testing.T, sp *storage.Peer, p Peer) {
 	assert.Equal(t, sp.QueryOutcomes.Responses.Latest, prs.latest.Unix())
 	assert.Equal(t, sp.QueryOutcomes.Responses.NQueries, prs.nQueries)
 	assert.Equal(t, sp.QueryOutcomes.Responses.NErrors, prs.nErrors)
+	assert.Equal(t, sp.QueryOutcomes.Responses.Latency, int64(prs.latency))
+	assert.Equal(t, sp.QueryOutcomes.Responses.ErrorRate, prs.errorRate)
+	assert.Equal(t, sp.QueryOutcomes.LastSeen, p.Recorder().LastSeen().Unix())
 }
 
 // TestConnector mocks the peer.Connector interface. The Connect() method returns a fixed client
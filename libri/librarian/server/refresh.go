@@ -0,0 +1,116 @@
+package server
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/server/search"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// DefaultRefreshInterval is the default time since their latest lookup after which routing
+	// table buckets are refreshed.
+	DefaultRefreshInterval = 1 * time.Hour
+
+	// DefaultRefreshConcurrency is the default number of bucket refresh searches run at once.
+	DefaultRefreshConcurrency = uint(3)
+
+	// logging keys
+	logRefreshInterval    = "refresh_interval"
+	logRefreshConcurrency = "refresh_concurrency"
+	logNRefreshed         = "n_refreshed"
+)
+
+// RefreshParameters define how librarians refresh routing table buckets that haven't been looked
+// up recently.
+type RefreshParameters struct {
+	// Interval is the time since their latest lookup after which buckets are refreshed, which is
+	// also the time between checks for such buckets.
+	Interval time.Duration
+
+	// Concurrency is the number of bucket refresh searches run at once.
+	Concurrency uint
+}
+
+// NewDefaultRefreshParameters creates an instance with default parameters.
+func NewDefaultRefreshParameters() *RefreshParameters {
+	return &RefreshParameters{
+		Interval:    DefaultRefreshInterval,
+		Concurrency: DefaultRefreshConcurrency,
+	}
+}
+
+// MarshalLogObject converts the RefreshParameters into an object (which will become json) for
+// logging.
+func (p *RefreshParameters) MarshalLogObject(oe zapcore.ObjectEncoder) error {
+	oe.AddDuration(logRefreshInterval, p.Interval)
+	oe.AddUint(logRefreshConcurrency, p.Concurrency)
+	return nil
+}
+
+// refreshPeriodically refreshes untouched routing table buckets every refresh interval until the
+// librarian is stopped.
+func (l *Librarian) refreshPeriodically() {
+	seed := int64(binary.BigEndian.Uint64(l.selfID.ID().Bytes()[:8]))
+	rng := rand.New(rand.NewSource(seed))
+	ticker := time.NewTicker(l.config.Refresh.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			nRefreshed := l.refreshBuckets(rng)
+			l.logger.Debug("refreshed routing table buckets",
+				zap.Int(logNRefreshed, nRefreshed),
+				zap.Int(NumPeers, l.rt.NumPeers()),
+			)
+		}
+	}
+}
+
+// refreshBuckets searches for a random ID in each routing table bucket without a lookup in the
+// refresh interval, returning the number of buckets refreshed.
+func (l *Librarian) refreshBuckets(rng *rand.Rand) int {
+	targets := l.rt.Untouched(time.Now().Add(-l.config.Refresh.Interval), rng)
+	concurrency := l.config.Refresh.Concurrency
+	if concurrency == 0 {
+		concurrency = 1
+	}
+	slots := make(chan struct{}, concurrency)
+	wg := new(sync.WaitGroup)
+	for _, target := range targets {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(target id.ID) {
+			defer wg.Done()
+			l.refreshBucket(target)
+			<-slots
+		}(target)
+	}
+	wg.Wait()
+	return len(targets)
+}
+
+// refreshBucket searches for the target, adding the responding peers to the routing table.
+func (l *Librarian) refreshBucket(target id.ID) {
+	s := search.NewSearch(l.selfID, target, l.config.Search)
+	seeds := l.rt.Peak(target, s.Params.NClosestResponses)
+	err := l.searcher.Search(s, seeds)
+	l.penalizeSearchErrors(s.Result.Errored)
+	if err != nil {
+		l.logger.Debug("error refreshing bucket",
+			zap.String(logKey, id.Hex(target.Bytes())),
+			zap.Error(err),
+		)
+		return
+	}
+	for _, p := range s.Result.Responded {
+		l.rt.Push(p)
+	}
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/server/peer"
+	"github.com/drausin/libri/libri/librarian/server/search"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLibrarian_refreshBuckets(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	searchResult := search.NewInitialResult(id.NewPseudoRandom(rng),
+		search.NewDefaultParameters())
+	for _, p := range peer.NewTestPeers(rng, 4) {
+		searchResult.Responded[p.ID().String()] = p
+	}
+	l := newGetLibrarian(rng, searchResult, nil)
+	nBuckets, nPeers := l.rt.NumBuckets(), l.rt.NumPeers()
+
+	// all buckets are refreshed since none have had lookups
+	assert.Equal(t, nBuckets, l.refreshBuckets(rng))
+	assert.Equal(t, nPeers+len(searchResult.Responded), l.rt.NumPeers())
+	for _, p := range searchResult.Responded {
+		_, in := l.rt.Get(p.ID())
+		assert.True(t, in)
+	}
+
+	// no buckets are refreshed again since refreshing looked them up
+	assert.Zero(t, l.refreshBuckets(rng))
+
+	// all buckets are refreshed once the interval has passed
+	l.config.Refresh.Interval = -time.Minute
+	assert.Equal(t, l.rt.NumBuckets(), l.refreshBuckets(rng))
+}
+
+func TestLibrarian_refreshBuckets_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	l := newGetLibrarian(rng, nil, errors.New("some search error"))
+	nBuckets, nPeers := l.rt.NumBuckets(), l.rt.NumPeers()
+
+	// search errors don't add any peers
+	assert.Equal(t, nBuckets, l.refreshBuckets(rng))
+	assert.Equal(t, nPeers, l.rt.NumPeers())
+}
+
+func TestLibrarian_refreshPeriodically(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	searchResult := search.NewInitialResult(id.NewPseudoRandom(rng),
+		search.NewDefaultParameters())
+	newPeer := peer.NewTestPeer(rng, 0)
+	searchResult.Responded[newPeer.ID().String()] = newPeer
+	l := newGetLibrarian(rng, searchResult, nil)
+	l.config.Refresh.Interval = 10 * time.Millisecond
+	l.stop = make(chan struct{})
+
+	done := make(chan struct{})
+	go func() {
+		l.refreshPeriodically()
+		close(done)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	close(l.stop)
+	<-done
+
+	_, in := l.rt.Get(newPeer.ID())
+	assert.True(t, in)
+}
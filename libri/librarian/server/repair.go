@@ -0,0 +1,181 @@
+package server
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/drausin/libri/libri/librarian/client"
+	"github.com/drausin/libri/libri/librarian/server/peer"
+	"github.com/drausin/libri/libri/librarian/server/search"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// DefaultReadRepairEnabled is the default for whether librarians send found values to the
+	// closest peers that lacked them during Gets.
+	DefaultReadRepairEnabled = false
+
+	// DefaultReadRepairRate is the default sustained number of read repair Stores per second a
+	// librarian sends.
+	DefaultReadRepairRate = 5.0
+
+	// DefaultReadRepairBurst is the default maximum number of read repair Stores a librarian
+	// sends at once.
+	DefaultReadRepairBurst = uint(10)
+
+	// DefaultReadRepairTimeout is the default timeout for read repair Store queries.
+	DefaultReadRepairTimeout = 5 * time.Second
+
+	// logging keys
+	logReadRepairEnabled = "read_repair_enabled"
+	logReadRepairRate    = "read_repair_rate"
+	logReadRepairBurst   = "read_repair_burst"
+	logReadRepairTimeout = "read_repair_timeout"
+	logRepaired          = "n_repaired"
+	logRepairTargets     = "n_repair_targets"
+
+	repairStored  = "stored"
+	repairErrored = "errored"
+	repairLimited = "limited"
+)
+
+var readRepairs = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "libri",
+		Subsystem: "librarian",
+		Name:      "read_repairs_total",
+		Help:      "Read repair Stores to peers that lacked found values, by outcome.",
+	},
+	[]string{"outcome"},
+)
+
+func init() {
+	prometheus.MustRegister(readRepairs)
+}
+
+// ReadRepairParameters define how librarians repair missing replicas found during Gets.
+type ReadRepairParameters struct {
+	// Enabled is whether to send found values to the closest peers that lacked them.
+	Enabled bool
+
+	// Rate is the sustained number of read repair Stores per second to send.
+	Rate float64
+
+	// Burst is the maximum number of read repair Stores to send at once.
+	Burst uint
+
+	// Timeout is the timeout for read repair Store queries.
+	Timeout time.Duration
+}
+
+// NewDefaultReadRepairParameters creates an instance with default parameters.
+func NewDefaultReadRepairParameters() *ReadRepairParameters {
+	return &ReadRepairParameters{
+		Enabled: DefaultReadRepairEnabled,
+		Rate:    DefaultReadRepairRate,
+		Burst:   DefaultReadRepairBurst,
+		Timeout: DefaultReadRepairTimeout,
+	}
+}
+
+// MarshalLogObject converts the ReadRepairParameters into an object (which will become json) for
+// logging.
+func (p *ReadRepairParameters) MarshalLogObject(oe zapcore.ObjectEncoder) error {
+	oe.AddBool(logReadRepairEnabled, p.Enabled)
+	oe.AddFloat64(logReadRepairRate, p.Rate)
+	oe.AddUint(logReadRepairBurst, p.Burst)
+	oe.AddDuration(logReadRepairTimeout, p.Timeout)
+	return nil
+}
+
+// repairThrottle limits the rate of read repair Stores a librarian sends across all its Gets.
+type repairThrottle struct {
+	bucket *tokenBucket
+	mu     sync.Mutex
+	now    func() time.Time
+}
+
+func newRepairThrottle(params *ReadRepairParameters) *repairThrottle {
+	return &repairThrottle{
+		bucket: newTokenBucket(params.Rate, float64(params.Burst), time.Now()),
+		now:    time.Now,
+	}
+}
+
+// allow returns whether another read repair Store may be sent now.
+func (t *repairThrottle) allow() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.bucket.take(1, t.now())
+}
+
+// readRepairTargets returns the closest peers that responded to the search without its value and
+// are at least as close to the key as the closest peer that returned it. The search stops once it
+// finds the value, so farther peers are often seeds rather than the peers responsible for the key.
+func readRepairTargets(s *search.Search) []peer.Peer {
+	var maxDist *big.Int
+	for idStr, p := range s.Result.Responded {
+		if _, in := s.Result.Lacking[idStr]; in {
+			continue
+		}
+		if dist := s.Key.Distance(p.ID()); maxDist == nil || dist.Cmp(maxDist) < 0 {
+			maxDist = dist
+		}
+	}
+	if maxDist == nil {
+		return nil
+	}
+	targets := make([]peer.Peer, 0, len(s.Result.Lacking))
+	for _, p := range s.Result.Closest.Peers() {
+		if _, in := s.Result.Lacking[p.ID().String()]; !in {
+			continue
+		}
+		if s.Key.Distance(p.ID()).Cmp(maxDist) <= 0 {
+			targets = append(targets, p)
+		}
+	}
+	return targets
+}
+
+// readRepairAsync sends the search's found value to the closest peers that lacked it in the
+// background, logging the outcome.
+func (l *Librarian) readRepairAsync(s *search.Search, logger *zap.Logger) {
+	targets := readRepairTargets(s)
+	if len(targets) == 0 {
+		return
+	}
+	go func() {
+		nRepaired := l.readRepair(targets, s.Key, s.Result.Value, logger)
+		logger.Debug("finished read repair",
+			zap.Int(logRepairTargets, len(targets)),
+			zap.Int(logRepaired, nRepaired),
+		)
+	}()
+}
+
+// readRepair stores the value with each of the target peers the throttle allows, returning the
+// number of successful Stores.
+func (l *Librarian) readRepair(targets []peer.Peer, key id.ID, value *api.Document,
+	logger *zap.Logger) int {
+	nRepaired := 0
+	for _, p := range targets {
+		if !l.repairs.allow() {
+			readRepairs.WithLabelValues(repairLimited).Inc()
+			continue
+		}
+		rq := client.NewStoreRequest(l.selfID, key, value)
+		if err := l.storeTo(p, rq, l.config.ReadRepair.Timeout); err != nil {
+			readRepairs.WithLabelValues(repairErrored).Inc()
+			logger.Debug("error sending read repair", zap.Error(err))
+			continue
+		}
+		readRepairs.WithLabelValues(repairStored).Inc()
+		nRepaired++
+	}
+	return nRepaired
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
+	clogging "github.com/drausin/libri/libri/common/logging"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/drausin/libri/libri/librarian/server/peer"
+	"github.com/drausin/libri/libri/librarian/server/search"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepairThrottle_allow(t *testing.T) {
+	now := time.Unix(0, 0)
+	rt := newRepairThrottle(&ReadRepairParameters{Rate: 1, Burst: 2})
+	rt.bucket = newTokenBucket(1, 2, now)
+	rt.now = func() time.Time { return now }
+
+	// burst is allowed and then exhausted
+	assert.True(t, rt.allow())
+	assert.True(t, rt.allow())
+	assert.False(t, rt.allow())
+
+	// tokens refill at the rate
+	now = now.Add(time.Second)
+	assert.True(t, rt.allow())
+	assert.False(t, rt.allow())
+}
+
+func TestReadRepairTargets(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	key := id.NewPseudoRandom(rng)
+	s := search.NewSearch(ecid.NewPseudoRandom(rng), key, search.NewDefaultParameters())
+	closer1 := peer.New(flipBit(key, 10), "", peer.NewTestConnector(0))
+	closer2 := peer.New(flipBit(key, 11), "", peer.NewTestConnector(1))
+	holder := peer.New(flipBit(key, 20), "", peer.NewTestConnector(2))
+	farSeed := peer.New(flipBit(key, 200), "", peer.NewTestConnector(3))
+	notClosest := peer.New(flipBit(key, 12), "", peer.NewTestConnector(4))
+	err := s.Result.Closest.SafePushMany([]peer.Peer{closer1, closer2, holder, farSeed})
+	assert.Nil(t, err)
+	for _, p := range []peer.Peer{closer1, closer2, holder, farSeed, notClosest} {
+		s.Result.Responded[p.ID().String()] = p
+	}
+	for _, p := range []peer.Peer{closer1, farSeed, notClosest} {
+		s.Result.Lacking[p.ID().String()] = p
+	}
+
+	// only closest peers that lacked the value and are closer than the holder are targets, not
+	// far seeds
+	assert.Equal(t, []peer.Peer{closer1}, readRepairTargets(s))
+
+	// no targets without a peer that returned the value
+	delete(s.Result.Responded, holder.ID().String())
+	delete(s.Result.Responded, closer2.ID().String())
+	assert.Empty(t, readRepairTargets(s))
+
+	s.Result.Lacking = make(map[string]peer.Peer)
+	assert.Empty(t, readRepairTargets(s))
+}
+
+func TestLibrarian_readRepair(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	value, key := api.NewTestDocument(rng)
+	l := newPathCacheLibrarian(t, rng, nil, id.NewPseudoRandom(rng))
+	l.repairs = newRepairThrottle(NewDefaultReadRepairParameters())
+	targets := peer.NewTestPeers(rng, 3)
+	apiStorer := &fixedAPIStorer{requests: make(chan *api.StoreRequest, len(targets))}
+	l.storerCreator = &fixedStorerCreator{storer: apiStorer}
+	logger := clogging.NewDevInfoLogger()
+
+	nRepaired := l.readRepair(targets, key, value, logger)
+	assert.Equal(t, len(targets), nRepaired)
+	for range targets {
+		rq := <-apiStorer.requests
+		assert.False(t, rq.Cache)
+		assert.Equal(t, key.Bytes(), rq.Key)
+		assert.Equal(t, value, rq.Value)
+	}
+
+	// throttled repairs aren't sent
+	l.repairs = newRepairThrottle(&ReadRepairParameters{Rate: 1e-6, Burst: 1})
+	nRepaired = l.readRepair(targets, key, value, logger)
+	assert.Equal(t, 1, nRepaired)
+
+	// errored repairs aren't counted
+	l.repairs = newRepairThrottle(NewDefaultReadRepairParameters())
+	l.storerCreator = &fixedStorerCreator{
+		storer: &fixedAPIStorer{err: errors.New("some store error")},
+	}
+	nRepaired = l.readRepair(targets, key, value, logger)
+	assert.Zero(t, nRepaired)
+}
+
+func TestLibrarian_cacheAlongPath_readRepair(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	value, key := api.NewTestDocument(rng)
+	l := newPathCacheLibrarian(t, rng, nil, id.NewPseudoRandom(rng))
+	apiStorer := &fixedAPIStorer{requests: make(chan *api.StoreRequest, 1)}
+	l.storerCreator = &fixedStorerCreator{storer: apiStorer}
+
+	l.config.ReadRepair.Enabled = true
+
+	s := search.NewSearch(l.selfID, key, search.NewDefaultParameters())
+	s.Result.Value = value
+	lacking := peer.New(flipBit(key, 10), "", peer.NewTestConnector(0))
+	holder := peer.New(flipBit(key, 20), "", peer.NewTestConnector(1))
+	s.Result.Lacking[lacking.ID().String()] = lacking
+	s.Result.Responded[lacking.ID().String()] = lacking
+	s.Result.Responded[holder.ID().String()] = holder
+	err := s.Result.Closest.SafePush(lacking)
+	assert.Nil(t, err)
+
+	// peers getting read repairs don't also get cache copies
+	cachedTo, err := l.cacheAlongPath(s)
+	assert.Nil(t, err)
+	assert.Nil(t, cachedTo)
+
+	l.config.ReadRepair.Enabled = false
+	cachedTo, err = l.cacheAlongPath(s)
+	assert.Nil(t, err)
+	assert.Equal(t, lacking, cachedTo)
+	<-apiStorer.requests
+}
@@ -1,17 +1,90 @@
 package server
 
 import (
+	"container/list"
 	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/drausin/libri/libri/common/ecid"
 	"github.com/drausin/libri/libri/common/id"
 	"github.com/drausin/libri/libri/librarian/api"
 	"github.com/drausin/libri/libri/librarian/client"
 	"github.com/golang/protobuf/proto"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+const (
+	// DefaultMaxClockSkew is the default maximum difference between a request's timestamp and
+	// the local clock.
+	DefaultMaxClockSkew = 30 * time.Second
+
+	// DefaultMaxRequestsPerPeer is the default maximum number of recent request IDs remembered
+	// for each peer.
+	DefaultMaxRequestsPerPeer = 4096
+
+	// DefaultAllowUnstamped is the default setting for whether to accept requests without a
+	// timestamp from clients that predate them.
+	DefaultAllowUnstamped = false
+
+	// logging keys
+	logMaxClockSkew       = "max_clock_skew"
+	logMaxRequestsPerPeer = "max_requests_per_peer"
+	logAllowUnstamped     = "allow_unstamped"
+	logPeerPubKeyShort    = "peer_pub_key_short"
+)
+
+var (
+	// ErrStaleRequest indicates when a request's timestamp differs from the local clock by more
+	// than the max clock skew.
+	ErrStaleRequest = status.Error(codes.FailedPrecondition,
+		"request timestamp outside of allowed clock skew")
+
+	// ErrReplayedRequest indicates when a peer has already sent a request with the same ID.
+	ErrReplayedRequest = status.Error(codes.AlreadyExists, "request ID already seen")
+)
+
+// VerifyParameters define how requests are verified.
+type VerifyParameters struct {
+	// MaxClockSkew is the maximum difference between a request's timestamp and the local clock.
+	// Request IDs are remembered for at least twice this long.
+	MaxClockSkew time.Duration
+
+	// MaxRequestsPerPeer is the maximum number of recent request IDs remembered for each peer.
+	// A peer sending more requests than this within twice the max clock skew has its oldest IDs
+	// forgotten early.
+	MaxRequestsPerPeer uint
+
+	// AllowUnstamped is a temporary migration setting for whether to accept requests without a
+	// timestamp from clients that predate them, which will be removed once all clients send
+	// them. Replays of such requests are only rejected while their IDs are remembered, so each
+	// one accepted is logged.
+	AllowUnstamped bool
+}
+
+// NewDefaultVerifyParameters creates an instance with default parameters.
+func NewDefaultVerifyParameters() *VerifyParameters {
+	return &VerifyParameters{
+		MaxClockSkew:       DefaultMaxClockSkew,
+		MaxRequestsPerPeer: DefaultMaxRequestsPerPeer,
+		AllowUnstamped:     DefaultAllowUnstamped,
+	}
+}
+
+// MarshalLogObject converts the VerifyParameters into an object (which will become json) for
+// logging.
+func (p *VerifyParameters) MarshalLogObject(oe zapcore.ObjectEncoder) error {
+	oe.AddDuration(logMaxClockSkew, p.MaxClockSkew)
+	oe.AddUint(logMaxRequestsPerPeer, p.MaxRequestsPerPeer)
+	oe.AddBool(logAllowUnstamped, p.AllowUnstamped)
+	return nil
+}
+
 // RequestVerifier verifies requests by checking the signature in the context.
 type RequestVerifier interface {
 	Verify(ctx context.Context, msg proto.Message, meta *api.RequestMetadata) error
@@ -19,22 +92,42 @@ type RequestVerifier interface {
 
 type verifier struct {
 	sigVerifier client.Verifier
+	params      *VerifyParameters
+	logger      *zap.Logger
+
+	// recent request IDs seen from each peer, keyed by peer public key, each remembered for
+	// twice the max clock skew unless the peer's bound evicts it earlier
+	seen      map[string]*peerRequests
+	lastSweep time.Time
+	now       func() time.Time
+	mu        sync.Mutex
 }
 
 // NewRequestVerifier creates a new RequestVerifier instance.
-func NewRequestVerifier() RequestVerifier {
+func NewRequestVerifier(params *VerifyParameters, logger *zap.Logger) RequestVerifier {
 	return &verifier{
 		sigVerifier: client.NewVerifier(),
+		params:      params,
+		logger:      logger,
+		seen:        make(map[string]*peerRequests),
+		lastSweep:   time.Now(),
+		now:         time.Now,
 	}
 }
 
 func (rv *verifier) Verify(ctx context.Context, msg proto.Message,
 	meta *api.RequestMetadata) error {
-	encToken, err := client.FromSignatureContext(ctx)
+
+	// accept both compact signatures and JWTs from peers that predate them
+	var encToken string
+	compactSig, err := client.FromCompactSignatureContext(ctx)
+	if err == client.ErrContextMissingCompactSignature {
+		encToken, err = client.FromSignatureContext(ctx)
+	}
 	if err != nil {
 		return err
 	}
-	pubKey, err := ecid.FromPublicKeyBytes(meta.PubKey)
+	pubKey, err := ecid.ParsePublicKey(ecid.KeyType(meta.KeyType), meta.PubKey)
 	if err != nil {
 		return err
 	}
@@ -45,6 +138,101 @@ func (rv *verifier) Verify(ctx context.Context, msg proto.Message,
 		return fmt.Errorf("invalid RequestId length: %v; expected length %v",
 			len(meta.RequestId), id.Length)
 	}
+	if err := rv.checkTimestamp(meta.Timestamp, meta.PubKey); err != nil {
+		return err
+	}
+
+	if compactSig != nil {
+		err = rv.sigVerifier.VerifyCompact(compactSig, pubKey, msg)
+	} else {
+		err = rv.sigVerifier.Verify(encToken, pubKey, msg)
+	}
+	if err != nil {
+		return err
+	}
+
+	// only remember request IDs of verified requests so others can't fill the caches
+	return rv.checkNotReplayed(meta)
+}
+
+func (rv *verifier) checkTimestamp(timestamp int64, pubKey []byte) error {
+	if timestamp == 0 && rv.params.AllowUnstamped {
+		rv.logger.Warn("accepting unstamped request",
+			zap.String(logPeerPubKeyShort, id.ShortHex(pubKey)))
+		return nil
+	}
+	skew := rv.now().Sub(time.Unix(0, timestamp))
+	if skew > rv.params.MaxClockSkew || skew < -rv.params.MaxClockSkew {
+		return ErrStaleRequest
+	}
+	return nil
+}
+
+func (rv *verifier) checkNotReplayed(meta *api.RequestMetadata) error {
+	rv.mu.Lock()
+	defer rv.mu.Unlock()
+	now := rv.now()
+	expiry := now.Add(-2 * rv.params.MaxClockSkew)
+	if rv.lastSweep.Before(expiry) {
+		// forget peers without any recent requests
+		for peerKey, prs := range rv.seen {
+			prs.expire(expiry)
+			if prs.order.Len() == 0 {
+				delete(rv.seen, peerKey)
+			}
+		}
+		rv.lastSweep = now
+	}
+	prs, in := rv.seen[string(meta.PubKey)]
+	if !in {
+		prs = newPeerRequests()
+		rv.seen[string(meta.PubKey)] = prs
+	}
+	prs.expire(expiry)
+	requestID := string(meta.RequestId)
+	if _, in := prs.ids[requestID]; in {
+		return ErrReplayedRequest
+	}
+	prs.add(requestID, now, rv.params.MaxRequestsPerPeer)
+	return nil
+}
+
+// peerRequests holds the request IDs recently seen from a peer, oldest first.
+type peerRequests struct {
+	ids   map[string]*list.Element
+	order *list.List
+}
+
+type seenRequest struct {
+	id   string
+	seen time.Time
+}
+
+func newPeerRequests() *peerRequests {
+	return &peerRequests{
+		ids:   make(map[string]*list.Element),
+		order: list.New(),
+	}
+}
+
+// add remembers the request ID, first forgetting the oldest ones if already at the max.
+func (prs *peerRequests) add(requestID string, seen time.Time, max uint) {
+	for uint(prs.order.Len()) >= max && prs.order.Len() > 0 {
+		prs.remove(prs.order.Front())
+	}
+	prs.ids[requestID] = prs.order.PushBack(&seenRequest{id: requestID, seen: seen})
+}
+
+// expire forgets the request IDs seen before the given time.
+func (prs *peerRequests) expire(before time.Time) {
+	for front := prs.order.Front(); front != nil; front = prs.order.Front() {
+		if !front.Value.(*seenRequest).seen.Before(before) {
+			return
+		}
+		prs.remove(front)
+	}
+}
 
-	return rv.sigVerifier.Verify(encToken, pubKey, msg)
+func (prs *peerRequests) remove(e *list.Element) {
+	delete(prs.ids, prs.order.Remove(e).(*seenRequest).id)
 }
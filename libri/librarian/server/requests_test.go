@@ -1,43 +1,86 @@
 package server
 
 import (
-	"crypto/ecdsa"
+	"crypto"
 	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
 	"github.com/drausin/libri/libri/librarian/api"
 	"github.com/drausin/libri/libri/librarian/client"
 	"github.com/golang/protobuf/proto"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
 	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 )
 
 // alwaysSigVerifier implements the signature.Verifier interface but just blindly verifies
 // every signature.
 type alwaysSigVerifier struct{}
 
-func (asv *alwaysSigVerifier) Verify(encToken string, fromPubKey *ecdsa.PublicKey,
+func (asv *alwaysSigVerifier) Verify(encToken string, fromPubKey crypto.PublicKey,
+	m proto.Message) error {
+	return nil
+}
+
+func (asv *alwaysSigVerifier) VerifyCompact(sig []byte, fromPubKey crypto.PublicKey,
 	m proto.Message) error {
 	return nil
 }
 
 func TestRequestVerifier_Verify_ok(t *testing.T) {
-	rv := &verifier{
-		sigVerifier: &alwaysSigVerifier{},
-	}
+	rv := newTestRequestVerifier()
 
 	rng := rand.New(rand.NewSource(0))
 	ctx := client.NewIncomingSignatureContext(context.Background(), "dummy.signed.token")
 	meta := client.NewRequestMetadata(ecid.NewPseudoRandom(rng))
+	assert.Nil(t, rv.Verify(ctx, nil, meta))
+
+	for _, kt := range []ecid.KeyType{ecid.KeyTypeP256, ecid.KeyTypeEd25519} {
+		peerID, err := ecid.NewPseudoRandomIdentity(rng, kt)
+		assert.Nil(t, err)
+		meta = client.NewRequestMetadata(peerID)
+		assert.Nil(t, rv.Verify(ctx, nil, meta))
+	}
 
+	// check compact signature
+	ctx = client.NewIncomingCompactSignatureContext(context.Background(), []byte{1, 2, 3})
+	meta = client.NewRequestMetadata(ecid.NewPseudoRandom(rng))
 	assert.Nil(t, rv.Verify(ctx, nil, meta))
 }
 
+func TestRequestVerifier_Verify_signatureFormats(t *testing.T) {
+	rv := NewRequestVerifier(NewDefaultVerifyParameters(), zap.NewNop())
+	rng := rand.New(rand.NewSource(0))
+	peerID := ecid.NewPseudoRandom(rng)
+	rq := client.NewFindRequest(peerID, id.NewPseudoRandom(rng), 8)
+
+	// check server accepts both compact signatures and legacy JWTs
+	compactSig, err := client.NewSigner(peerID.Key()).SignCompact(rq)
+	assert.Nil(t, err)
+	ctx := client.NewIncomingCompactSignatureContext(context.Background(), compactSig)
+	assert.Nil(t, rv.Verify(ctx, rq, rq.Metadata))
+
+	rq = client.NewFindRequest(peerID, id.NewPseudoRandom(rng), 8)
+	encToken, err := client.NewJWTSigner(peerID.Key()).Sign(rq)
+	assert.Nil(t, err)
+	ctx = client.NewIncomingSignatureContext(context.Background(), encToken)
+	assert.Nil(t, rv.Verify(ctx, rq, rq.Metadata))
+
+	// check signature from another peer doesn't verify
+	rq = client.NewFindRequest(peerID, id.NewPseudoRandom(rng), 8)
+	otherSig, err := client.NewSigner(ecid.NewPseudoRandom(rng).Key()).SignCompact(rq)
+	assert.Nil(t, err)
+	ctx = client.NewIncomingCompactSignatureContext(context.Background(), otherSig)
+	assert.NotNil(t, rv.Verify(ctx, rq, rq.Metadata))
+}
+
 func TestRequestVerifier_Verify_err(t *testing.T) {
-	rv := &verifier{
-		sigVerifier: &alwaysSigVerifier{},
-	}
+	rv := newTestRequestVerifier()
 
 	assert.NotNil(t, rv.Verify(context.Background(), nil, nil)) // no signature in context
 
@@ -48,6 +91,11 @@ func TestRequestVerifier_Verify_err(t *testing.T) {
 		PubKey: []byte{255, 254, 253}, // bad pub key
 	}))
 
+	assert.NotNil(t, rv.Verify(ctx, nil, &api.RequestMetadata{
+		PubKey:  ecid.NewPseudoRandom(rng).PublicKeyBytes(),
+		KeyType: api.KeyType_ED25519, // pub key doesn't match key type
+	}))
+
 	assert.NotNil(t, rv.Verify(ctx, nil, &api.RequestMetadata{
 		PubKey:    ecid.NewPseudoRandom(rng).PublicKeyBytes(),
 		RequestId: nil, // can't be nil
@@ -58,3 +106,139 @@ func TestRequestVerifier_Verify_err(t *testing.T) {
 		RequestId: []byte{1, 2, 3}, // not 32 bytes
 	}))
 }
+
+func TestRequestVerifier_Verify_stale(t *testing.T) {
+	rv := newTestRequestVerifier()
+	rng := rand.New(rand.NewSource(0))
+	ctx := client.NewIncomingSignatureContext(context.Background(), "dummy.signed.token")
+	rv.params.AllowUnstamped = false
+	skew := rv.params.MaxClockSkew + time.Second
+
+	cases := []int64{
+		0, // missing timestamp
+		time.Now().Add(-skew).UnixNano(),
+		time.Now().Add(skew).UnixNano(),
+	}
+	for _, timestamp := range cases {
+		meta := client.NewRequestMetadata(ecid.NewPseudoRandom(rng))
+		meta.Timestamp = timestamp
+		err := rv.Verify(ctx, nil, meta)
+		assert.Equal(t, ErrStaleRequest, err)
+		assert.Equal(t, codes.FailedPrecondition, grpc.Code(err))
+	}
+
+	// check timestamp within skew is ok
+	meta := client.NewRequestMetadata(ecid.NewPseudoRandom(rng))
+	meta.Timestamp = time.Now().Add(-rv.params.MaxClockSkew / 2).UnixNano()
+	assert.Nil(t, rv.Verify(ctx, nil, meta))
+
+	// check missing timestamp is ok when allowing unstamped requests
+	rv.params.AllowUnstamped = true
+	meta = client.NewRequestMetadata(ecid.NewPseudoRandom(rng))
+	meta.Timestamp = 0
+	assert.Nil(t, rv.Verify(ctx, nil, meta))
+	assert.Equal(t, ErrReplayedRequest, rv.Verify(ctx, nil, meta))
+}
+
+func TestRequestVerifier_Verify_replayed(t *testing.T) {
+	rv := newTestRequestVerifier()
+	rng := rand.New(rand.NewSource(0))
+	ctx := client.NewIncomingSignatureContext(context.Background(), "dummy.signed.token")
+	peerID1, peerID2 := ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng)
+
+	meta1 := client.NewRequestMetadata(peerID1)
+	assert.Nil(t, rv.Verify(ctx, nil, meta1))
+	err := rv.Verify(ctx, nil, meta1)
+	assert.Equal(t, ErrReplayedRequest, err)
+	assert.Equal(t, codes.AlreadyExists, grpc.Code(err))
+
+	// check same request ID from another peer is ok
+	meta2 := client.NewRequestMetadata(peerID2)
+	meta2.RequestId = meta1.RequestId
+	assert.Nil(t, rv.Verify(ctx, nil, meta2))
+
+	// check request ID is still remembered after requests from many other peers
+	for c := 0; c < 2048; c++ {
+		assert.Nil(t, rv.Verify(ctx, nil, client.NewRequestMetadata(ecid.NewPseudoRandom(rng))))
+	}
+	assert.Equal(t, ErrReplayedRequest, rv.Verify(ctx, nil, meta1))
+}
+
+func TestRequestVerifier_checkNotReplayed_expiry(t *testing.T) {
+	rv := newTestRequestVerifier()
+	now := time.Now()
+	rv.lastSweep, rv.now = now, func() time.Time { return now }
+	rng := rand.New(rand.NewSource(0))
+	window := 2 * rv.params.MaxClockSkew
+
+	meta1 := client.NewRequestMetadata(ecid.NewPseudoRandom(rng))
+	assert.Nil(t, rv.checkNotReplayed(meta1))
+
+	// check request ID is remembered for twice the max clock skew
+	for _, elapsed := range []time.Duration{window / 2, window} {
+		now = now.Add(window / 2)
+		assert.Equal(t, ErrReplayedRequest, rv.checkNotReplayed(meta1), elapsed)
+	}
+
+	// and forgotten after
+	now = now.Add(time.Second)
+	assert.Nil(t, rv.checkNotReplayed(meta1))
+
+	// check peers without recent requests are forgotten after a long gap
+	meta2 := client.NewRequestMetadata(ecid.NewPseudoRandom(rng))
+	assert.Nil(t, rv.checkNotReplayed(meta2))
+	assert.Len(t, rv.seen, 2)
+	now = now.Add(2 * window)
+	assert.Nil(t, rv.checkNotReplayed(client.NewRequestMetadata(ecid.NewPseudoRandom(rng))))
+	assert.Len(t, rv.seen, 1)
+	assert.Nil(t, rv.checkNotReplayed(meta2))
+}
+
+func TestRequestVerifier_checkNotReplayed_maxPerPeer(t *testing.T) {
+	rv := newTestRequestVerifier()
+	rv.params.MaxRequestsPerPeer = 4
+	rng := rand.New(rand.NewSource(0))
+	peerID := ecid.NewPseudoRandom(rng)
+
+	metas := make([]*api.RequestMetadata, 6)
+	for i := range metas {
+		metas[i] = client.NewRequestMetadata(peerID)
+		assert.Nil(t, rv.checkNotReplayed(metas[i]))
+	}
+	assert.Len(t, rv.seen, 1)
+	assert.Equal(t, 4, rv.seen[string(peerID.PublicKeyBytes())].order.Len())
+
+	// check the most recent request IDs are still remembered
+	for _, meta := range metas[2:] {
+		assert.Equal(t, ErrReplayedRequest, rv.checkNotReplayed(meta))
+	}
+
+	// and the oldest have been forgotten
+	assert.Nil(t, rv.checkNotReplayed(metas[0]))
+
+	// check other peers' request IDs are not evicted by this peer's
+	other := client.NewRequestMetadata(ecid.NewPseudoRandom(rng))
+	assert.Nil(t, rv.checkNotReplayed(other))
+	for c := 0; c < 8; c++ {
+		assert.Nil(t, rv.checkNotReplayed(client.NewRequestMetadata(peerID)))
+	}
+	assert.Equal(t, ErrReplayedRequest, rv.checkNotReplayed(other))
+}
+
+func TestRequestVerifier_Verify_badSigNotRemembered(t *testing.T) {
+	rv := newTestRequestVerifier()
+	rng := rand.New(rand.NewSource(0))
+	ctx := client.NewIncomingSignatureContext(context.Background(), "dummy.signed.token")
+	meta := client.NewRequestMetadata(ecid.NewPseudoRandom(rng))
+
+	rv.sigVerifier = client.NewVerifier()
+	assert.NotNil(t, rv.Verify(ctx, nil, meta))
+	rv.sigVerifier = &alwaysSigVerifier{}
+	assert.Nil(t, rv.Verify(ctx, nil, meta))
+}
+
+func newTestRequestVerifier() *verifier {
+	rv := NewRequestVerifier(NewDefaultVerifyParameters(), zap.NewNop()).(*verifier)
+	rv.sigVerifier = &alwaysSigVerifier{}
+	return rv
+}
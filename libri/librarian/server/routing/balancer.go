@@ -26,10 +26,11 @@ var (
 )
 
 // NewClientBalancer returns a new client.Balancer that uses the routing tables's Sample()
-// method and returns a unique client on every Next() call.
-func NewClientBalancer(rt Table) client.SetBalancer {
+// method and returns a unique, non-blacklisted client on every Next() call.
+func NewClientBalancer(rt Table, reps peer.Reputations) client.SetBalancer {
 	return &tableSetBalancer{
 		rt:    rt,
+		reps:  reps,
 		rng:   rand.New(rand.NewSource(0)),
 		set:   make(map[string]struct{}),
 		cache: make([]peer.Peer, 0),
@@ -38,6 +39,7 @@ func NewClientBalancer(rt Table) client.SetBalancer {
 
 type tableSetBalancer struct {
 	rt    Table
+	reps  peer.Reputations
 	rng   *rand.Rand
 	set   map[string]struct{}
 	cache []peer.Peer
@@ -58,6 +60,11 @@ func (b *tableSetBalancer) AddNext() (api.LibrarianClient, id.ID, error) {
 		}
 		nextPeer := b.cache[0]
 		b.cache = b.cache[1:]
+		if b.reps.Blacklisted(nextPeer.ID()) {
+			// skip to next cached peer without waiting
+			b.mu.Unlock()
+			continue
+		}
 		if _, in := b.set[nextPeer.ID().String()]; !in {
 			// update current state & return connection to new peer
 			b.set[nextPeer.ID().String()] = struct{}{}
@@ -15,7 +15,8 @@ import (
 func TestTableUniqueBalancer_Next_ok(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	origLC := api.NewLibrarianClient(nil)
-	rt := NewEmpty(id.NewPseudoRandom(rng), NewDefaultParameters())
+	rt := NewEmpty(id.NewPseudoRandom(rng), NewDefaultParameters(),
+		peer.NewDefaultReputations())
 	rt.Push(
 		peer.New(
 			id.NewPseudoRandom(rng),
@@ -23,7 +24,7 @@ func TestTableUniqueBalancer_Next_ok(t *testing.T) {
 			&fixedConnector{connectLC: origLC},
 		),
 	)
-	csb := NewClientBalancer(rt)
+	csb := NewClientBalancer(rt, peer.NewDefaultReputations())
 
 	// check Next() returns inner LibrarianClient
 	lc, peerID, err := csb.AddNext()
@@ -54,8 +55,9 @@ func TestTableUniqueBalancer_Next_ok(t *testing.T) {
 
 func TestTableUniqueBalancer_Next_err(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
-	rt := NewEmpty(id.NewPseudoRandom(rng), NewDefaultParameters())
-	cb := NewClientBalancer(rt)
+	rt := NewEmpty(id.NewPseudoRandom(rng), NewDefaultParameters(),
+		peer.NewDefaultReputations())
+	cb := NewClientBalancer(rt, peer.NewDefaultReputations())
 
 	// check empty RT throws error
 	tableSampleRetryWait = 10 * time.Millisecond // just for test
@@ -79,10 +81,32 @@ func TestTableUniqueBalancer_Next_err(t *testing.T) {
 	assert.NotNil(t, peerID)
 }
 
+func TestTableUniqueBalancer_Next_blacklisted(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	rt := NewEmpty(id.NewPseudoRandom(rng), NewDefaultParameters(),
+		peer.NewDefaultReputations())
+	p := peer.New(
+		id.NewPseudoRandom(rng),
+		"test-peer-1",
+		&fixedConnector{connectLC: api.NewLibrarianClient(nil)},
+	)
+	rt.Push(p)
+	reps := &fixedReputations{blacklisted: map[string]bool{p.ID().String(): true}}
+	cb := NewClientBalancer(rt, reps)
+
+	// check blacklisted peer in table isn't returned
+	tableSampleRetryWait = 10 * time.Millisecond // just for test
+	lc, peerID, err := cb.AddNext()
+	assert.Equal(t, ErrNoNewClients, err)
+	assert.Nil(t, lc)
+	assert.Nil(t, peerID)
+}
+
 func TestRoutingTableBalancer_Remove(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	lc1 := api.NewLibrarianClient(nil)
-	rt := NewEmpty(id.NewPseudoRandom(rng), NewDefaultParameters())
+	rt := NewEmpty(id.NewPseudoRandom(rng), NewDefaultParameters(),
+		peer.NewDefaultReputations())
 	rt.Push(
 		peer.New(
 			id.NewPseudoRandom(rng),
@@ -90,7 +114,7 @@ func TestRoutingTableBalancer_Remove(t *testing.T) {
 			&fixedConnector{connectLC: lc1},
 		),
 	)
-	csb := NewClientBalancer(rt)
+	csb := NewClientBalancer(rt, peer.NewDefaultReputations())
 
 	lc2, peerID, err := csb.AddNext()
 	assert.Nil(t, err)
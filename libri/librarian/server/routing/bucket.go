@@ -1,6 +1,10 @@
 package routing
 
 import (
+	"math/big"
+	"math/rand"
+	"time"
+
 	"github.com/drausin/libri/libri/common/id"
 	"github.com/drausin/libri/libri/librarian/server/peer"
 )
@@ -33,20 +37,32 @@ type bucket struct {
 
 	// positions (i.e., indices) of each peer (keyed by ID string) in the heap.
 	positions map[string]int
+
+	// maximum number of peers in the replacement cache.
+	maxReplacements uint
+
+	// peers that didn't fit in the full bucket, ordered from least to most recently seen, to
+	// replace active peers when they're evicted.
+	replacements []peer.Peer
+
+	// latest time a lookup targeted an ID in the bucket
+	touched time.Time
 }
 
 // newFirstBucket creates a new instance of the first bucket (spanning the entire ID range)
-func newFirstBucket(maxActivePeers uint) *bucket {
+func newFirstBucket(maxActivePeers, maxReplacements uint) *bucket {
 	return &bucket{
-		depth:          0,
-		lowerBound:     id.LowerBound,
-		upperBound:     id.UpperBound,
-		idMass:         1.0,
-		idCumMass:      1.0,
-		maxActivePeers: maxActivePeers,
-		activePeers:    make([]peer.Peer, 0),
-		positions:      make(map[string]int),
-		containsSelf:   true,
+		depth:           0,
+		lowerBound:      id.LowerBound,
+		upperBound:      id.UpperBound,
+		idMass:          1.0,
+		idCumMass:       1.0,
+		maxActivePeers:  maxActivePeers,
+		activePeers:     make([]peer.Peer, 0),
+		positions:       make(map[string]int),
+		containsSelf:    true,
+		maxReplacements: maxReplacements,
+		replacements:    make([]peer.Peer, 0),
 	}
 }
 
@@ -94,6 +110,52 @@ func (b *bucket) Vacancy() bool {
 	return len(b.activePeers) < int(b.maxActivePeers)
 }
 
+// PushReplacement adds a peer to the end of the replacement cache as the most recently seen,
+// dropping the least recently seen if the cache is full.
+func (b *bucket) PushReplacement(p peer.Peer) {
+	if existing := b.RemoveReplacement(p.ID()); existing != nil && existing != p {
+		if err := existing.Merge(p); err != nil {
+			// should never happen
+			panic(err)
+		}
+		p = existing
+	}
+	b.replacements = append(b.replacements, p)
+	if uint(len(b.replacements)) > b.maxReplacements {
+		b.replacements = b.replacements[1:]
+	}
+}
+
+// PopReplacement removes and returns the most recently seen peer in the replacement cache, or
+// nil if it is empty.
+func (b *bucket) PopReplacement() peer.Peer {
+	if len(b.replacements) == 0 {
+		return nil
+	}
+	last := b.replacements[len(b.replacements)-1]
+	b.replacements = b.replacements[:len(b.replacements)-1]
+	return last
+}
+
+// RemoveReplacement removes and returns the peer with the given ID from the replacement cache, or
+// nil if it isn't there.
+func (b *bucket) RemoveReplacement(peerID id.ID) peer.Peer {
+	for i, p := range b.replacements {
+		if p.ID().Cmp(peerID) == 0 {
+			b.replacements = append(b.replacements[:i], b.replacements[i+1:]...)
+			return p
+		}
+	}
+	return nil
+}
+
+// RandomID returns a random ID within the bucket's ID range.
+func (b *bucket) RandomID(rng *rand.Rand) id.ID {
+	width := new(big.Int).Sub(b.upperBound.Int(), b.lowerBound.Int())
+	offset := new(big.Int).Rand(rng, width)
+	return id.FromInt(offset.Add(offset, b.lowerBound.Int()))
+}
+
 // Contains returns whether the bucket's ID range contains the target.
 func (b *bucket) Contains(target id.ID) bool {
 	return target.Cmp(b.lowerBound) >= 0 && target.Cmp(b.upperBound) < 0
@@ -11,7 +11,7 @@ import (
 
 func TestBucket_PushPop(t *testing.T) {
 	for n := 1; n <= 128; n *= 2 {
-		b := newFirstBucket(DefaultMaxActivePeers)
+		b := newFirstBucket(DefaultMaxActivePeers, DefaultMaxBucketReplacements)
 		rng := rand.New(rand.NewSource(int64(n)))
 		for _, p := range peer.NewTestPeers(rng, n) {
 			heap.Push(b, p)
@@ -26,7 +26,7 @@ func TestBucket_PushPop(t *testing.T) {
 }
 
 func TestBucket_Peak(t *testing.T) {
-	b := newFirstBucket(DefaultMaxActivePeers)
+	b := newFirstBucket(DefaultMaxActivePeers, DefaultMaxBucketReplacements)
 
 	// nothing to peak b/c bucket is empty
 	assert.Equal(t, 0, len(b.Peak(2)))
@@ -41,3 +41,46 @@ func TestBucket_Peak(t *testing.T) {
 	assert.Equal(t, 4, len(b.Peak(4)))
 	assert.Equal(t, 4, len(b.Peak(8)))
 }
+
+func TestBucket_Replacements(t *testing.T) {
+	b := newFirstBucket(DefaultMaxActivePeers, 4)
+	rng := rand.New(rand.NewSource(0))
+	ps := peer.NewTestPeers(rng, 6)
+
+	// nothing to pop b/c cache is empty
+	assert.Nil(t, b.PopReplacement())
+
+	// least recently seen replacements dropped beyond capacity
+	for _, p := range ps {
+		b.PushReplacement(p)
+	}
+	assert.Equal(t, ps[2:], b.replacements)
+
+	// re-pushed peer becomes most recently seen
+	b.PushReplacement(ps[3])
+	assert.Equal(t, []peer.Peer{ps[2], ps[4], ps[5], ps[3]}, b.replacements)
+
+	// re-pushed different instance of existing peer is merged into it
+	other := peer.New(ps[4].ID(), "", nil)
+	other.Recorder().Record(peer.Response, peer.Success)
+	b.PushReplacement(other)
+	assert.Equal(t, []peer.Peer{ps[2], ps[5], ps[3], ps[4]}, b.replacements)
+	assert.Equal(t, other.Recorder().LatestResponse(), ps[4].Recorder().LatestResponse())
+
+	assert.Equal(t, ps[5], b.RemoveReplacement(ps[5].ID()))
+	assert.Nil(t, b.RemoveReplacement(ps[0].ID()))
+	assert.Equal(t, ps[4], b.PopReplacement())
+	assert.Equal(t, ps[3], b.PopReplacement())
+	assert.Equal(t, ps[2], b.PopReplacement())
+	assert.Nil(t, b.PopReplacement())
+}
+
+func TestBucket_RandomID(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	rt, _, _ := NewTestWithPeers(rng, 128)
+	for _, b := range rt.(*table).buckets {
+		for c := 0; c < 8; c++ {
+			assert.True(t, b.Contains(b.RandomID(rng)))
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package routing
+
+import (
+	"sync"
+	"time"
+
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/drausin/libri/libri/librarian/client"
+	"github.com/drausin/libri/libri/librarian/server/peer"
+	"golang.org/x/net/context"
+)
+
+// Prober checks the liveness of peers in the routing table, evicting those that repeatedly don't
+// respond.
+type Prober interface {
+	// Probe pings the least recently seen peer in each bucket if it is stale, evicting it from
+	// the table if its latest MaxProbeFailures pings have failed. It returns the number of peers
+	// evicted.
+	Probe() int
+
+	// ProbeAll pings every peer in the table, evicting those whose latest MaxProbeFailures pings
+	// have failed. It returns the number of peers evicted.
+	ProbeAll() int
+
+	// Begin probes the table every probe interval until End is called.
+	Begin()
+
+	// End stops probing the table.
+	End()
+}
+
+type prober struct {
+	rt     Table
+	pc     client.PingerCreator
+	params *Parameters
+	end    chan struct{}
+
+	// number of consecutive failed pings of each peer, keyed by string encoding of the ID
+	failures map[string]*failedPings
+	mu       sync.Mutex
+}
+
+type failedPings struct {
+	peerID id.ID
+	n      uint
+}
+
+// NewProber creates a new Prober for the routing table.
+func NewProber(rt Table, pc client.PingerCreator, params *Parameters) Prober {
+	return &prober{
+		rt:       rt,
+		pc:       pc,
+		params:   params,
+		end:      make(chan struct{}),
+		failures: make(map[string]*failedPings),
+	}
+}
+
+func (p *prober) Probe() int {
+	return p.probe(p.rt.Stale(time.Now().Add(-p.params.StaleAfter)))
+}
+
+func (p *prober) ProbeAll() int {
+	return p.probe(p.rt.Peers())
+}
+
+// probe pings the peers, evicting those whose latest pings have all failed, and returns the
+// number evicted.
+func (p *prober) probe(peers []peer.Peer) int {
+	// ping concurrently but record outcomes afterwards, since recording changes how peers are
+	// ordered in their buckets
+	latencies, errs := make([]time.Duration, len(peers)), make([]error, len(peers))
+	wg := new(sync.WaitGroup)
+	for i, next := range peers {
+		wg.Add(1)
+		go func(i int, next peer.Peer) {
+			defer wg.Done()
+			latencies[i], errs[i] = p.ping(next)
+		}(i, next)
+	}
+	wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	nEvicted := 0
+	for i, next := range peers {
+		if latencies[i] > 0 {
+			next.Recorder().RecordLatency(latencies[i])
+		}
+		key := next.ID().String()
+		if errs[i] != nil {
+			next.Recorder().Record(peer.Response, peer.Error)
+			failed, in := p.failures[key]
+			if !in {
+				failed = &failedPings{peerID: next.ID()}
+				p.failures[key] = failed
+			}
+			failed.n++
+			if failed.n >= p.params.MaxProbeFailures {
+				delete(p.failures, key)
+				p.rt.Evict(next.ID())
+				nEvicted++
+			}
+			continue
+		}
+		delete(p.failures, key)
+		next.Recorder().Record(peer.Response, peer.Success)
+
+		// re-push so the peer's bucket reflects its latest response time
+		p.rt.Push(next)
+	}
+
+	// forget failures of peers removed from the table some other way
+	for key, failed := range p.failures {
+		if _, in := p.rt.Get(failed.peerID); !in {
+			delete(p.failures, key)
+		}
+	}
+	return nEvicted
+}
+
+func (p *prober) Begin() {
+	ticker := time.NewTicker(p.params.ProbeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.end:
+			return
+		case <-ticker.C:
+			p.Probe()
+		}
+	}
+}
+
+func (p *prober) End() {
+	select {
+	case <-p.end: // already closed
+	default:
+		close(p.end)
+	}
+}
+
+// ping pings the peer, returning the round-trip latency if the ping was sent.
+func (p *prober) ping(next peer.Peer) (time.Duration, error) {
+	pinger, err := p.pc.Create(next.Connector())
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), p.params.ProbeTimeout)
+	defer cancel()
+	start := time.Now()
+	_, err = pinger.Ping(ctx, &api.PingRequest{})
+	return time.Since(start), err
+}
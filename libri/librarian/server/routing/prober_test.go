@@ -0,0 +1,169 @@
+package routing
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/drausin/libri/libri/librarian/server/peer"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+func TestProber_Probe(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	rt, _, _ := NewTestWithPeers(rng, 256)
+	stale := rt.Stale(time.Now())
+	assert.True(t, len(stale) > 2)
+	pc := &fixedPingerCreator{
+		pingErrs: map[string]error{
+			stale[0].Connector().Address().String(): errors.New("some ping error"),
+			stale[1].Connector().Address().String(): errors.New("some ping error"),
+		},
+	}
+	params := NewDefaultParameters()
+	p := NewProber(rt, pc, params)
+
+	// check unresponsive peers aren't evicted until enough consecutive pings have failed
+	assert.Zero(t, p.Probe())
+	assert.Equal(t, len(stale), pc.nPings)
+	for c := uint(2); c < params.MaxProbeFailures; c++ {
+		assert.Zero(t, p.Probe())
+	}
+	for _, s := range stale[:2] {
+		_, in := rt.Get(s.ID())
+		assert.True(t, in)
+	}
+	nEvicted := p.Probe()
+	assert.Equal(t, 2, nEvicted)
+	assert.Empty(t, p.(*prober).failures)
+
+	// check unresponsive peers are evicted and responsive ones are no longer stale
+	for i, s := range stale {
+		_, in := rt.Get(s.ID())
+		assert.Equal(t, i >= 2, in)
+		assert.True(t, s.Recorder().Latency() > 0)
+	}
+	for _, s := range rt.Stale(time.Now()) {
+		for _, responsive := range stale[2:] {
+			assert.NotEqual(t, responsive.ID(), s.ID())
+		}
+	}
+
+	// check error creating pinger also evicts peer
+	pc = &fixedPingerCreator{createErr: errors.New("some create error")}
+	params.MaxProbeFailures = 1
+	p = NewProber(rt, pc, params)
+	nStale := len(rt.Stale(time.Now()))
+	assert.Equal(t, nStale, p.Probe())
+}
+
+func TestProber_Probe_recovered(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	rt, _, _ := NewTestWithPeers(rng, 8)
+	peers := rt.Peers()
+	addr := peers[0].Connector().Address().String()
+	pc := &fixedPingerCreator{pingErrs: map[string]error{addr: errors.New("some ping error")}}
+	params := NewDefaultParameters()
+	params.MaxProbeFailures = 2
+	p := NewProber(rt, pc, params)
+
+	// check a successful ping resets the peer's consecutive failures
+	assert.Zero(t, p.ProbeAll())
+	delete(pc.pingErrs, addr)
+	assert.Zero(t, p.ProbeAll())
+	assert.Empty(t, p.(*prober).failures)
+	pc.pingErrs[addr] = errors.New("some ping error")
+	assert.Zero(t, p.ProbeAll())
+	assert.Equal(t, 1, p.ProbeAll())
+
+	// check failures of peers removed from the table some other way are forgotten
+	addr = peers[1].Connector().Address().String()
+	pc.pingErrs[addr] = errors.New("some ping error")
+	assert.Zero(t, p.ProbeAll())
+	assert.Len(t, p.(*prober).failures, 1)
+	rt.Evict(peers[1].ID())
+	assert.Zero(t, p.ProbeAll())
+	assert.Empty(t, p.(*prober).failures)
+}
+
+func TestProber_ProbeAll(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	rt, _, _ := NewTestWithPeers(rng, 8)
+	peers := rt.Peers()
+	nPeers := len(peers)
+	pc := &fixedPingerCreator{
+		pingErrs: map[string]error{
+			peers[0].Connector().Address().String(): errors.New("some ping error"),
+		},
+	}
+	params := NewDefaultParameters()
+	params.MaxProbeFailures = 1
+	p := NewProber(rt, pc, params)
+
+	assert.Equal(t, 1, p.ProbeAll())
+	assert.Equal(t, nPeers, pc.nPings)
+	assert.Equal(t, nPeers-1, rt.NumPeers())
+	_, in := rt.Get(peers[0].ID())
+	assert.False(t, in)
+	assert.Empty(t, rt.Stale(time.Now().Add(-time.Minute)))
+}
+
+func TestProber_BeginEnd(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	rt, _, _ := NewTestWithPeers(rng, 64)
+	pc := &fixedPingerCreator{}
+	params := NewDefaultParameters()
+	params.ProbeInterval = 10 * time.Millisecond
+	p := NewProber(rt, pc, params)
+
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		p.Begin()
+	}()
+	time.Sleep(50 * time.Millisecond)
+	p.End()
+	p.End() // second End is a no-op
+	wg.Wait()
+
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	assert.True(t, pc.nPings > 0)
+}
+
+type fixedPingerCreator struct {
+	createErr error
+	pingErrs  map[string]error
+	nPings    int
+	mu        sync.Mutex
+}
+
+func (c *fixedPingerCreator) Create(conn peer.Connector) (api.Pinger, error) {
+	if c.createErr != nil {
+		return nil, c.createErr
+	}
+	return &fixedPinger{creator: c, err: c.pingErrs[conn.Address().String()]}, nil
+}
+
+type fixedPinger struct {
+	creator *fixedPingerCreator
+	err     error
+}
+
+func (p *fixedPinger) Ping(ctx context.Context, in *api.PingRequest, opts ...grpc.CallOption) (
+	*api.PingResponse, error) {
+	p.creator.mu.Lock()
+	p.creator.nPings++
+	p.creator.mu.Unlock()
+	time.Sleep(time.Millisecond)
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &api.PingResponse{Message: "pong"}, nil
+}
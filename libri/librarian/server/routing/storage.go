@@ -1,16 +1,40 @@
 package routing
 
 import (
+	"errors"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/drausin/libri/libri/common/ecid"
 	"github.com/drausin/libri/libri/common/id"
 	"github.com/drausin/libri/libri/common/storage"
 	"github.com/drausin/libri/libri/librarian/server/peer"
 	"github.com/golang/protobuf/proto"
 )
 
+const (
+	// flatTableVersion is the original stored table version, with only a flat list of peers.
+	flatTableVersion = uint32(0)
+
+	// bucketTableVersion is the stored table version with peers and state grouped by bucket.
+	bucketTableVersion = uint32(1)
+)
+
+var (
+	// ErrUnknownTableVersion indicates when a stored routing table has a version newer than
+	// those known.
+	ErrUnknownTableVersion = errors.New("unknown stored routing table version")
+
+	// ErrInvalidStoredBuckets indicates when the buckets of a stored routing table don't
+	// partition the ID space.
+	ErrInvalidStoredBuckets = errors.New("stored routing table buckets don't partition ID space")
+)
+
 var tableKey = []byte("RoutingTable")
 
 // Load retrieves the routing table form the KV DB.
-func Load(nl storage.NamespaceLoader, params *Parameters) (Table, error) {
+func Load(nl storage.NamespaceLoader, params *Parameters, reps peer.Reputations) (Table, error) {
 	bytes, err := nl.Load(tableKey)
 	if bytes == nil || err != nil {
 		return nil, err
@@ -20,38 +44,154 @@ func Load(nl storage.NamespaceLoader, params *Parameters) (Table, error) {
 	if err != nil {
 		return nil, err
 	}
-	return fromStored(stored, params), nil
+	return fromStored(stored, params, reps)
 }
 
-// Save stores a representation of the routing table to the KV DB.
+// Save stores a representation of the routing table to the KV DB. This method is concurrency
+// safe.
 func (rt *table) Save(ns storage.NamespaceStorer) error {
-	bytes, err := proto.Marshal(toStored(rt))
+	rt.mu.Lock()
+	stored, nChanges := toStored(rt), rt.nChanges
+	rt.mu.Unlock()
+
+	bytes, err := proto.Marshal(stored)
 	if err != nil {
 		return err
 	}
-	return ns.Store(tableKey, bytes)
+	if err := ns.Store(tableKey, bytes); err != nil {
+		return err
+	}
+
+	// keep any changes made while saving
+	rt.mu.Lock()
+	rt.nChanges -= nChanges
+	rt.mu.Unlock()
+	return nil
 }
 
 // fromStored returns a new Table instance from a StoredRoutingTable instance.
-func fromStored(stored *storage.RoutingTable, params *Parameters) Table {
-	peers := make([]peer.Peer, len(stored.Peers))
-	for i, sp := range stored.Peers {
-		peers[i] = peer.FromStored(sp)
+func fromStored(stored *storage.RoutingTable, params *Parameters, reps peer.Reputations) (
+	Table, error) {
+	switch stored.Version {
+	case flatTableVersion:
+		peers := make([]peer.Peer, len(stored.Peers))
+		for i, sp := range stored.Peers {
+			peers[i] = peer.FromStored(sp)
+		}
+		rt, _ := NewWithPeers(id.FromBytes(stored.SelfId), params, reps, peers)
+		rt.(*table).nChanges = 0
+		return rt, nil
+	case bucketTableVersion:
+		return fromStoredBuckets(stored, params, reps)
 	}
-	rt, _ := NewWithPeers(id.FromBytes(stored.SelfId), params, peers)
-	return rt
+	return nil, ErrUnknownTableVersion
 }
 
-// toStored creates a new StoredRoutingTable instance from the Table instance.
+// fromStoredBuckets returns a new Table instance with the buckets, peers, and replacements of a
+// bucketed StoredRoutingTable instance.
+func fromStoredBuckets(stored *storage.RoutingTable, params *Parameters, reps peer.Reputations) (
+	Table, error) {
+	rt := NewEmpty(id.FromBytes(stored.SelfId), params, reps).(*table)
+	buckets, err := fromStoredBucketBounds(rt.selfID, stored.Buckets, params)
+	if err != nil {
+		return nil, err
+	}
+	rt.buckets = buckets
+
+	for _, sb := range stored.Buckets {
+		for _, sp := range sb.Peers {
+			rt.push(peer.FromStored(sp), false)
+		}
+	}
+	for _, sb := range stored.Buckets {
+		for _, sp := range sb.Replacements {
+			p := peer.FromStored(sp)
+			if _, in := rt.peers[p.ID().String()]; in || reps.Blacklisted(p.ID()) {
+				continue
+			}
+			if !ecid.SolvesIDPuzzle(p.ID(), params.IDDifficulty) {
+				// replacements may later become active peers, so check them like pushed peers
+				continue
+			}
+			if b := rt.buckets[rt.bucketIndex(p.ID())]; !b.containsSelf {
+				b.PushReplacement(p)
+			}
+		}
+	}
+	return rt, nil
+}
+
+// fromStoredBucketBounds creates empty buckets from stored buckets, checking that they partition
+// the ID space.
+func fromStoredBucketBounds(selfID id.ID, stored []*storage.RoutingBucket, params *Parameters) (
+	[]*bucket, error) {
+	if len(stored) == 0 {
+		return nil, ErrInvalidStoredBuckets
+	}
+	buckets := make([]*bucket, len(stored))
+	lowerBound, idCumMass := id.LowerBound, 0.0
+	for i, sb := range stored {
+		if sb.Depth > id.Length*8 || id.FromBytes(sb.LowerBound).Cmp(lowerBound) != 0 {
+			return nil, ErrInvalidStoredBuckets
+		}
+		width := new(big.Int).Lsh(big.NewInt(1), uint(id.Length*8-sb.Depth))
+		if new(big.Int).Mod(lowerBound.Int(), width).Sign() != 0 {
+			// bucket must span a single bit prefix
+			return nil, ErrInvalidStoredBuckets
+		}
+		upper := new(big.Int).Add(lowerBound.Int(), width)
+		upperBound := id.UpperBound
+		if upper.BitLen() <= id.Length*8 {
+			upperBound = id.FromInt(upper)
+		}
+		idMass := math.Ldexp(1.0, -int(sb.Depth))
+		idCumMass += idMass
+		buckets[i] = &bucket{
+			depth:           uint(sb.Depth),
+			lowerBound:      lowerBound,
+			upperBound:      upperBound,
+			idMass:          idMass,
+			idCumMass:       idCumMass,
+			maxActivePeers:  params.MaxBucketPeers,
+			activePeers:     make([]peer.Peer, 0),
+			positions:       make(map[string]int),
+			maxReplacements: params.MaxBucketReplacements,
+			replacements:    make([]peer.Peer, 0),
+			touched:         time.Unix(sb.Touched, 0),
+		}
+		buckets[i].containsSelf = buckets[i].Contains(selfID)
+		lowerBound = upperBound
+	}
+	if lowerBound.Cmp(id.UpperBound) != 0 {
+		return nil, ErrInvalidStoredBuckets
+	}
+	return buckets, nil
+}
+
+// toStored creates a new StoredRoutingTable instance from the Table instance. Callers must hold
+// the table's lock if it is in use.
 func toStored(rt Table) *storage.RoutingTable {
-	storedPeers := make([]*storage.Peer, len(rt.(*table).peers))
-	i := 0
-	for _, p := range rt.(*table).peers {
-		storedPeers[i] = p.ToStored()
-		i++
+	storedBuckets := make([]*storage.RoutingBucket, len(rt.(*table).buckets))
+	for i, b := range rt.(*table).buckets {
+		storedBuckets[i] = &storage.RoutingBucket{
+			LowerBound:   b.lowerBound.Bytes(),
+			Depth:        uint32(b.depth),
+			Peers:        toStoredPeers(b.activePeers),
+			Replacements: toStoredPeers(b.replacements),
+			Touched:      b.touched.Unix(),
+		}
 	}
 	return &storage.RoutingTable{
-		SelfId: rt.SelfID().Bytes(),
-		Peers:  storedPeers,
+		SelfId:  rt.SelfID().Bytes(),
+		Version: bucketTableVersion,
+		Buckets: storedBuckets,
+	}
+}
+
+func toStoredPeers(peers []peer.Peer) []*storage.Peer {
+	stored := make([]*storage.Peer, len(peers))
+	for i, p := range peers {
+		stored[i] = p.ToStored()
 	}
+	return stored
 }
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/drausin/libri/libri/common/db"
+	"github.com/drausin/libri/libri/common/ecid"
 	"github.com/drausin/libri/libri/common/id"
 	"github.com/drausin/libri/libri/common/storage"
 	"github.com/drausin/libri/libri/librarian/server/peer"
@@ -15,13 +16,107 @@ import (
 
 func TestFromStored(t *testing.T) {
 	srt := newTestStoredTable(rand.New(rand.NewSource(0)), 128)
-	rt := fromStored(srt, NewDefaultParameters())
+	rt, err := fromStored(srt, NewDefaultParameters(), peer.NewDefaultReputations())
+	assert.Nil(t, err)
+	assert.Zero(t, rt.NumChanges())
 	assertRoutingTablesEqual(t, rt, srt)
 }
 
+func TestFromStored_buckets(t *testing.T) {
+	rt1, _, _ := NewTestWithPeers(rand.New(rand.NewSource(0)), 256)
+	rt1.Peak(id.FromInt64(0), 8)
+	srt := toStored(rt1)
+	rt2, err := fromStored(srt, NewDefaultParameters(), peer.NewDefaultReputations())
+	assert.Nil(t, err)
+	assert.Zero(t, rt2.NumChanges())
+	assertRoutingTablesEqual(t, rt2, srt)
+
+	// check bucket structure, replacements, and lookup times are kept
+	assert.Equal(t, rt1.NumPeers(), rt2.NumPeers())
+	assert.Equal(t, rt1.NumBuckets(), rt2.NumBuckets())
+	for i, b1 := range rt1.(*table).buckets {
+		b2 := rt2.(*table).buckets[i]
+		assert.Equal(t, b1.depth, b2.depth)
+		assert.Equal(t, b1.lowerBound, b2.lowerBound)
+		assert.Equal(t, b1.upperBound, b2.upperBound)
+		assert.Equal(t, b1.idMass, b2.idMass)
+		assert.Equal(t, b1.idCumMass, b2.idCumMass)
+		assert.Equal(t, b1.containsSelf, b2.containsSelf)
+		assert.Equal(t, b1.Len(), b2.Len())
+		assert.Equal(t, len(b1.replacements), len(b2.replacements))
+		for j, r1 := range b1.replacements {
+			assert.Equal(t, r1.ID(), b2.replacements[j].ID())
+		}
+		assert.Equal(t, b1.touched.Unix(), b2.touched.Unix())
+	}
+}
+
+func TestFromStored_bucketsIDPuzzle(t *testing.T) {
+	rt1, _, _ := NewTestWithPeers(rand.New(rand.NewSource(0)), 256)
+	srt := toStored(rt1)
+	params := NewDefaultParameters()
+	params.IDDifficulty = 1
+	rt2, err := fromStored(srt, params, peer.NewDefaultReputations())
+	assert.Nil(t, err)
+
+	// check active and replacement peers from an old or tampered table must solve the ID puzzle
+	nReplacements1, nReplacements2 := 0, 0
+	for i, b1 := range rt1.(*table).buckets {
+		b2 := rt2.(*table).buckets[i]
+		nReplacements1 += len(b1.replacements)
+		nReplacements2 += len(b2.replacements)
+		for _, p := range b2.activePeers {
+			assert.True(t, ecid.SolvesIDPuzzle(p.ID(), params.IDDifficulty))
+		}
+		for _, p := range b2.replacements {
+			assert.True(t, ecid.SolvesIDPuzzle(p.ID(), params.IDDifficulty))
+		}
+	}
+	assert.True(t, rt2.NumPeers() < rt1.NumPeers())
+	assert.True(t, nReplacements2 < nReplacements1)
+}
+
+func TestFromStored_err(t *testing.T) {
+	params, reps := NewDefaultParameters(), peer.NewDefaultReputations()
+	rt, _, _ := NewTestWithPeers(rand.New(rand.NewSource(0)), 256)
+	assert.True(t, rt.NumBuckets() > 2)
+
+	// unknown version
+	srt := toStored(rt)
+	srt.Version = bucketTableVersion + 1
+	_, err := fromStored(srt, params, reps)
+	assert.Equal(t, ErrUnknownTableVersion, err)
+
+	// no buckets
+	srt = toStored(rt)
+	srt.Buckets = nil
+	_, err = fromStored(srt, params, reps)
+	assert.Equal(t, ErrInvalidStoredBuckets, err)
+
+	// missing bucket
+	srt = toStored(rt)
+	srt.Buckets = srt.Buckets[1:]
+	_, err = fromStored(srt, params, reps)
+	assert.Equal(t, ErrInvalidStoredBuckets, err)
+
+	// buckets not spanning entire ID space
+	srt = toStored(rt)
+	srt.Buckets = srt.Buckets[:len(srt.Buckets)-1]
+	_, err = fromStored(srt, params, reps)
+	assert.Equal(t, ErrInvalidStoredBuckets, err)
+
+	// bucket not spanning a single bit prefix
+	srt = toStored(rt)
+	srt.Buckets[0].Depth = 0
+	_, err = fromStored(srt, params, reps)
+	assert.Equal(t, ErrInvalidStoredBuckets, err)
+}
+
 func TestToRoutingTable(t *testing.T) {
 	rt, _, _ := NewTestWithPeers(rand.New(rand.NewSource(0)), 128)
 	srt := toStored(rt)
+	assert.Equal(t, bucketTableVersion, srt.Version)
+	assert.Equal(t, rt.NumBuckets(), len(srt.Buckets))
 	assertRoutingTablesEqual(t, rt, srt)
 }
 
@@ -33,10 +128,12 @@ func TestRoutingTable_SaveLoad(t *testing.T) {
 	assert.Nil(t, err)
 	ssl := storage.NewServerSL(kvdb)
 
+	assert.NotZero(t, rt1.NumChanges())
 	err = rt1.Save(ssl)
 	assert.Nil(t, err)
+	assert.Zero(t, rt1.NumChanges())
 
-	rt2, err := Load(ssl, NewDefaultParameters())
+	rt2, err := Load(ssl, NewDefaultParameters(), peer.NewDefaultReputations())
 	assert.Nil(t, err)
 
 	// check that routing tables are the same
@@ -78,7 +175,11 @@ func newTestStoredTable(rng *rand.Rand, n int) *storage.RoutingTable {
 
 func assertRoutingTablesEqual(t *testing.T, rt Table, srt *storage.RoutingTable) {
 	assert.Equal(t, srt.SelfId, rt.SelfID().Bytes())
-	for _, sp := range srt.Peers {
+	storedPeers := srt.Peers
+	for _, sb := range srt.Buckets {
+		storedPeers = append(storedPeers, sb.Peers...)
+	}
+	for _, sp := range storedPeers {
 		spIDStr := id.FromBytes(sp.Id).String()
 		if toPeer, exists := rt.(*table).peers[spIDStr]; exists {
 			peer.AssertPeersEqual(t, sp, toPeer)
@@ -98,7 +199,7 @@ func (l *fixedLoader) Load(key []byte) ([]byte, error) {
 func TestLoad_err(t *testing.T) {
 
 	// simulates missing/not stored table
-	rt1, err := Load(&fixedLoader{}, NewDefaultParameters())
+	rt1, err := Load(&fixedLoader{}, NewDefaultParameters(), peer.NewDefaultReputations())
 	assert.Nil(t, rt1)
 	assert.Nil(t, err)
 
@@ -109,6 +210,7 @@ func TestLoad_err(t *testing.T) {
 			err:   errors.New("some random error"),
 		},
 		NewDefaultParameters(),
+		peer.NewDefaultReputations(),
 	)
 	assert.Nil(t, rt2)
 	assert.NotNil(t, err)
@@ -120,6 +222,7 @@ func TestLoad_err(t *testing.T) {
 			err:   nil,
 		},
 		NewDefaultParameters(),
+		peer.NewDefaultReputations(),
 	)
 	assert.Nil(t, rt3)
 	assert.NotNil(t, err)
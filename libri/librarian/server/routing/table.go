@@ -8,11 +8,13 @@ import (
 	"math/rand"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/drausin/libri/libri/common/ecid"
 	"github.com/drausin/libri/libri/common/id"
 	"github.com/drausin/libri/libri/common/storage"
 	"github.com/drausin/libri/libri/librarian/server/peer"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 const (
@@ -23,8 +25,71 @@ const (
 
 var (
 	DefaultMaxActivePeers = uint(20)
+
+	// DefaultPeakOversample is the default multiple of k closest peers Peak considers when
+	// choosing the fastest k.
+	DefaultPeakOversample = uint(2)
+
+	// DefaultMaxBucketReplacements is the default maximum number of peers in each bucket's
+	// replacement cache.
+	DefaultMaxBucketReplacements = uint(20)
+
+	// DefaultProbeInterval is the default time between probes of stale peers.
+	DefaultProbeInterval = 1 * time.Minute
+
+	// DefaultProbeTimeout is the default timeout for each ping of a stale peer.
+	DefaultProbeTimeout = 5 * time.Second
+
+	// DefaultStaleAfter is the default time since its latest response after which a peer is
+	// stale and probed.
+	DefaultStaleAfter = 15 * time.Minute
+
+	// DefaultMaxProbeFailures is the default number of consecutive failed pings after which a
+	// peer is evicted.
+	DefaultMaxProbeFailures = uint(3)
+
+	// DefaultCheckpointInterval is the default maximum time between saves of a changed table.
+	DefaultCheckpointInterval = 5 * time.Minute
+
+	// DefaultCheckpointChanges is the default number of peers added to or removed from the table
+	// since it was last saved that triggers an early save.
+	DefaultCheckpointChanges = uint(32)
+
+	// DefaultIDDifficulty is the default number of leading zero bits the SHA-256 hash of a peer's
+	// ID must have for the peer to be added to the table. Zero disables the ID puzzle.
+	DefaultIDDifficulty = uint(0)
+)
+
+const (
+	evictedBlacklisted  = "blacklisted"
+	evictedUnresponsive = "unresponsive"
 )
 
+var (
+	evictions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "libri",
+			Subsystem: "librarian",
+			Name:      "routing_evictions_total",
+			Help:      "Peers evicted from the routing table.",
+		},
+		[]string{"reason"},
+	)
+	replacements = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "libri",
+			Subsystem: "librarian",
+			Name:      "routing_replacements_total",
+			Help:      "Evicted peers replaced from bucket replacement caches.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(evictions)
+	prometheus.MustRegister(replacements)
+}
+
 // PushStatus indicates different outcomes when adding a peer to the routing table.
 type PushStatus int
 
@@ -47,20 +112,40 @@ type Table interface {
 	// SelfID returns the table's selfID.
 	SelfID() id.ID
 
-	// Push adds the peer into the appropriate bucket and returns an AddStatus result.
+	// Push adds the peer into the appropriate bucket and returns an AddStatus result. Blacklisted
+	// peers are dropped and removed from the table if already present. Peers whose IDs don't
+	// solve the ID puzzle are dropped. Peers dropped because their bucket is full are kept in the
+	// bucket's replacement cache.
 	Push(new peer.Peer) PushStatus
 
 	// Pop removes and returns the k peers in the bucket(s) closest to the given target.
 	Pop(target id.ID, k uint) []peer.Peer
 
 	// Peak returns the k peers in the bucket(s) closest to the given target by popping and then
-	// pushing them back into the table.
+	// pushing them back into the table. Among the closest peers at similar distances to the target,
+	// those with lower expected latencies are preferred. Blacklisted peers are excluded and removed
+	// from the table.
 	Peak(target id.ID, k uint) []peer.Peer
 
 	// Get returns the peer with the given ID or nil (if it doesn't exist) with a boolean
 	// indicator for whether the peer existed.
 	Get(peerID id.ID) (peer.Peer, bool)
 
+	// Evict removes the peer from the table, replacing it with the most recently seen peer in
+	// its bucket's replacement cache.
+	Evict(peerID id.ID)
+
+	// Stale returns the least recently seen peer in each bucket if it was last seen before the
+	// given time.
+	Stale(before time.Time) []peer.Peer
+
+	// Peers returns all the peers in the table.
+	Peers() []peer.Peer
+
+	// Untouched returns a random ID within each bucket that hasn't been the target of a Pop or
+	// Peak since the given time.
+	Untouched(before time.Time, rng *rand.Rand) []id.ID
+
 	// Sample returns k peers in the table sampled (approximately) uniformly from the ID space.
 	// Peers are sampled from buckets with probability proportional to the amount of ID
 	// space the bucket covers.
@@ -75,6 +160,10 @@ type Table interface {
 	// Disconnect disconnects all client connections.
 	Disconnect() error
 
+	// NumChanges returns the number of peers added to or removed from the table since it was
+	// last saved.
+	NumChanges() uint
+
 	// Save saves the table via the NamespaceStorer
 	Save(ns storage.NamespaceStorer) error
 }
@@ -84,11 +173,52 @@ type Parameters struct {
 
 	// MaxBucketPeers is the maximum number of peers in a bucket.
 	MaxBucketPeers uint
+
+	// MaxBucketReplacements is the maximum number of peers in each bucket's replacement cache.
+	MaxBucketReplacements uint
+
+	// ProbeInterval is the time between probes of stale peers.
+	ProbeInterval time.Duration
+
+	// ProbeTimeout is the timeout for each ping of a stale peer.
+	ProbeTimeout time.Duration
+
+	// StaleAfter is the time since its latest response after which a peer is stale and probed.
+	StaleAfter time.Duration
+
+	// MaxProbeFailures is the number of consecutive failed pings after which a peer is evicted,
+	// so brief network blips don't evict otherwise healthy peers.
+	MaxProbeFailures uint
+
+	// PeakOversample is the multiple of k closest peers Peak considers when choosing the k with
+	// the lowest expected latencies among those at similar distances to the target. A value of 1
+	// disables this preference.
+	PeakOversample uint
+
+	// CheckpointInterval is the maximum time between saves of a changed table.
+	CheckpointInterval time.Duration
+
+	// CheckpointChanges is the number of peers added to or removed from the table since it was
+	// last saved that triggers an early save.
+	CheckpointChanges uint
+
+	// IDDifficulty is the number of leading zero bits the SHA-256 hash of a peer's ID must have
+	// for the peer to be added to the table.
+	IDDifficulty uint
 }
 
 func NewDefaultParameters() *Parameters {
 	return &Parameters{
-		MaxBucketPeers: DefaultMaxActivePeers,
+		MaxBucketPeers:        DefaultMaxActivePeers,
+		MaxBucketReplacements: DefaultMaxBucketReplacements,
+		ProbeInterval:         DefaultProbeInterval,
+		ProbeTimeout:          DefaultProbeTimeout,
+		StaleAfter:            DefaultStaleAfter,
+		MaxProbeFailures:      DefaultMaxProbeFailures,
+		PeakOversample:        DefaultPeakOversample,
+		CheckpointInterval:    DefaultCheckpointInterval,
+		CheckpointChanges:     DefaultCheckpointChanges,
+		IDDifficulty:          DefaultIDDifficulty,
 	}
 }
 
@@ -105,24 +235,32 @@ type table struct {
 	// defines some aspects of behavior
 	params *Parameters
 
+	// excludes blacklisted peers
+	reps peer.Reputations
+
+	// number of peers added or removed since the table was last saved
+	nChanges uint
+
 	// manages pushes and pops
 	mu sync.Mutex
 }
 
 // NewEmpty creates a new routing table without peers.
-func NewEmpty(selfID id.ID, params *Parameters) Table {
-	firstBucket := newFirstBucket(params.MaxBucketPeers)
+func NewEmpty(selfID id.ID, params *Parameters, reps peer.Reputations) Table {
+	firstBucket := newFirstBucket(params.MaxBucketPeers, params.MaxBucketReplacements)
 	return &table{
 		selfID:  selfID,
 		peers:   make(map[string]peer.Peer),
 		buckets: []*bucket{firstBucket},
 		params:  params,
+		reps:    reps,
 	}
 }
 
 // NewWithPeers creates a new routing table with peers, returning it and the number of peers added.
-func NewWithPeers(selfID id.ID, params *Parameters, peers []peer.Peer) (Table, int) {
-	rt := NewEmpty(selfID, params)
+func NewWithPeers(selfID id.ID, params *Parameters, reps peer.Reputations, peers []peer.Peer) (
+	Table, int) {
+	rt := NewEmpty(selfID, params, reps)
 	nAdded := 0
 	for _, p := range peers {
 		if rt.Push(p) == Added {
@@ -136,7 +274,8 @@ func NewWithPeers(selfID id.ID, params *Parameters, peers []peer.Peer) (Table, i
 func NewTestWithPeers(rng *rand.Rand, n int) (Table, ecid.ID, int) {
 	peerID := ecid.NewPseudoRandom(rng)
 	params := NewDefaultParameters()
-	rt, nAdded := NewWithPeers(peerID.ID(), params, peer.NewTestPeers(rng, n))
+	rt, nAdded := NewWithPeers(peerID.ID(), params, peer.NewDefaultReputations(),
+		peer.NewTestPeers(rng, n))
 	return rt, peerID, nAdded
 }
 
@@ -156,10 +295,24 @@ func (rt *table) NumBuckets() int {
 // Push adds the peer into the appropriate bucket and returns the status of the push. This method
 // is concurrency-safe.
 func (rt *table) Push(new peer.Peer) PushStatus {
+	return rt.push(new, true)
+}
+
+// push adds the peer into the appropriate bucket, counting it as a change if it's added and
+// countChange is true.
+func (rt *table) push(new peer.Peer, countChange bool) PushStatus {
 	if rt.selfID.Cmp(new.ID()) == 0 {
 		// don't add self
 		return Dropped
 	}
+	if rt.reps.Blacklisted(new.ID()) {
+		rt.remove(new.ID(), evictedBlacklisted)
+		return Dropped
+	}
+	if !ecid.SolvesIDPuzzle(new.ID(), rt.params.IDDifficulty) {
+		// don't add peers with cheap IDs
+		return Dropped
+	}
 
 	rt.mu.Lock()
 	// get the bucket to insert into
@@ -193,8 +346,12 @@ func (rt *table) Push(new peer.Peer) PushStatus {
 
 	if insertBucket.Vacancy() {
 		// node isn't already in the bucket and there's vacancy, so add it
+		insertBucket.RemoveReplacement(new.ID())
 		heap.Push(insertBucket, new)
 		rt.peers[new.ID().String()] = new
+		if countChange {
+			rt.nChanges++
+		}
 		rt.mu.Unlock()
 		return Added
 	}
@@ -204,11 +361,12 @@ func (rt *table) Push(new peer.Peer) PushStatus {
 		// insert via (single) recursive call
 		rt.splitBucket(bucketIdx)
 		rt.mu.Unlock()
-		return rt.Push(new)
+		return rt.push(new, countChange)
 	}
 
-	// no vacancy in the bucket and it doesn't contain the self ID, so just drop new peer on
-	// the floor
+	// no vacancy in the bucket and it doesn't contain the self ID, so drop new peer into the
+	// bucket's replacement cache
+	insertBucket.PushReplacement(new)
 	rt.mu.Unlock()
 	return Dropped
 }
@@ -225,6 +383,9 @@ func (rt *table) Pop(target id.ID, k uint) []peer.Peer {
 
 	fwdIdx := rt.bucketIndex(target)
 	bkwdIdx := fwdIdx - 1
+	if fwdIdx < len(rt.buckets) {
+		rt.buckets[fwdIdx].touched = time.Now()
+	}
 
 	// loop until we've populated all the peers or we have no more buckets to draw from
 	next := make([]peer.Peer, k)
@@ -251,13 +412,35 @@ func (rt *table) Pop(target id.ID, k uint) []peer.Peer {
 // Peak returns the k peers in the bucket(s) closest to the given target by popping and then
 // pushing them back into the table. This method is concurrency safe.
 func (rt *table) Peak(target id.ID, k uint) []peer.Peer {
-	popped := rt.Pop(target, k)
+	nCandidates := k
+	if rt.params.PeakOversample > 1 {
+		nCandidates *= rt.params.PeakOversample
+	}
+	peaked := make([]peer.Peer, 0, nCandidates)
+	for uint(len(peaked)) < nCandidates {
+		popped := rt.Pop(target, nCandidates-uint(len(peaked)))
+		if len(popped) == 0 {
+			break
+		}
+		for _, p := range popped {
+			// leave blacklisted peers out of the table and pop others in their place
+			if !rt.reps.Blacklisted(p.ID()) {
+				peaked = append(peaked, p)
+			}
+		}
+	}
 
-	// add the peers back
-	for _, p := range popped {
-		rt.Push(p)
+	// add the peers back, which doesn't change the table
+	for _, p := range peaked {
+		rt.push(p, false)
+	}
+	if uint(len(peaked)) <= k {
+		return peaked
 	}
-	return popped
+
+	// keep the fastest of the candidates at similar distances to the target
+	sort.Sort(newLatencyAwarePeers(target, peaked))
+	return peaked[:k]
 }
 
 // Get returns the peer (if it exists) in the table with the given ID.
@@ -309,6 +492,87 @@ func (rt *table) Disconnect() error {
 	return nil
 }
 
+// Evict removes the unresponsive peer with the given ID from the table if it exists, replacing it
+// with the most recently seen peer in its bucket's replacement cache. This method is concurrency
+// safe.
+func (rt *table) Evict(peerID id.ID) {
+	rt.remove(peerID, evictedUnresponsive)
+}
+
+func (rt *table) Stale(before time.Time) []peer.Peer {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	stale := make([]peer.Peer, 0)
+	for _, b := range rt.buckets {
+		var leastRecent peer.Peer
+		for _, p := range b.activePeers {
+			if leastRecent == nil ||
+				p.Recorder().LastSeen().Before(leastRecent.Recorder().LastSeen()) {
+				leastRecent = p
+			}
+		}
+		if leastRecent != nil && leastRecent.Recorder().LastSeen().Before(before) {
+			stale = append(stale, leastRecent)
+		}
+	}
+	return stale
+}
+
+func (rt *table) Peers() []peer.Peer {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	peers := make([]peer.Peer, 0, len(rt.peers))
+	for _, p := range rt.peers {
+		peers = append(peers, p)
+	}
+	return peers
+}
+
+func (rt *table) NumChanges() uint {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return rt.nChanges
+}
+
+func (rt *table) Untouched(before time.Time, rng *rand.Rand) []id.ID {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	targets := make([]id.ID, 0)
+	for _, b := range rt.buckets {
+		if b.touched.Before(before) {
+			targets = append(targets, b.RandomID(rng))
+		}
+	}
+	return targets
+}
+
+// remove removes the peer from the table and its bucket's replacement cache, replacing it with
+// the most recently seen non-blacklisted peer in the replacement cache.
+func (rt *table) remove(peerID id.ID, reason string) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	removeBucket := rt.buckets[rt.bucketIndex(peerID)]
+	removeBucket.RemoveReplacement(peerID)
+	pHeapIdx, exists := removeBucket.positions[peerID.String()]
+	if !exists {
+		return
+	}
+	heap.Remove(removeBucket, pHeapIdx)
+	delete(rt.peers, peerID.String())
+	rt.nChanges++
+	evictions.WithLabelValues(reason).Inc()
+
+	for next := removeBucket.PopReplacement(); next != nil; next = removeBucket.PopReplacement() {
+		if !rt.reps.Blacklisted(next.ID()) {
+			heap.Push(removeBucket, next)
+			rt.peers[next.ID().String()] = next
+			rt.nChanges++
+			replacements.Inc()
+			return
+		}
+	}
+}
+
 // Len returns the current number of buckets in the routing table.
 func (rt *table) Len() int {
 	return len(rt.buckets)
@@ -388,30 +652,37 @@ func (rt *table) splitBucket(bucketIdx int) {
 
 	// create the new buckets
 	left := &bucket{
-		depth:          current.depth + 1,
-		lowerBound:     current.lowerBound,
-		upperBound:     middle,
-		idMass:         newIdMass,
-		idCumMass:      current.idCumMass - newIdMass,
-		maxActivePeers: current.maxActivePeers,
-		activePeers:    make([]peer.Peer, 0),
-		positions:      make(map[string]int),
+		depth:           current.depth + 1,
+		lowerBound:      current.lowerBound,
+		upperBound:      middle,
+		idMass:          newIdMass,
+		idCumMass:       current.idCumMass - newIdMass,
+		maxActivePeers:  current.maxActivePeers,
+		activePeers:     make([]peer.Peer, 0),
+		positions:       make(map[string]int),
+		maxReplacements: current.maxReplacements,
+		replacements:    make([]peer.Peer, 0),
+		touched:         current.touched,
 	}
 	left.containsSelf = left.Contains(rt.selfID)
 
 	right := &bucket{
-		depth:          current.depth + 1,
-		lowerBound:     middle,
-		upperBound:     current.upperBound,
-		idMass:         newIdMass,
-		idCumMass:      current.idCumMass,
-		maxActivePeers: current.maxActivePeers,
-		activePeers:    make([]peer.Peer, 0),
-		positions:      make(map[string]int),
+		depth:           current.depth + 1,
+		lowerBound:      middle,
+		upperBound:      current.upperBound,
+		idMass:          newIdMass,
+		idCumMass:       current.idCumMass,
+		maxActivePeers:  current.maxActivePeers,
+		activePeers:     make([]peer.Peer, 0),
+		positions:       make(map[string]int),
+		maxReplacements: current.maxReplacements,
+		replacements:    make([]peer.Peer, 0),
+		touched:         current.touched,
 	}
 	right.containsSelf = right.Contains(rt.selfID)
 
-	// fill the buckets with existing peers
+	// fill the buckets with existing peers; the current bucket has no replacements to move since
+	// it contains the self ID and so splits instead of caching peers when full
 	for _, p := range current.activePeers {
 		if left.Contains(p.ID()) {
 			heap.Push(left, p)
@@ -426,6 +697,48 @@ func (rt *table) splitBucket(bucketIdx int) {
 	sort.Sort(rt)                          // but we let Sort handle moving it back there
 }
 
+// latencyAwarePeers sorts peers by the number of leading zero bits in their distances to a target
+// and then by their expected latencies, so faster peers come before slower ones at similar
+// distances.
+type latencyAwarePeers struct {
+	peers     []peer.Peer
+	distances []*big.Int
+	latencies []time.Duration
+}
+
+func newLatencyAwarePeers(target id.ID, ps []peer.Peer) *latencyAwarePeers {
+	lap := &latencyAwarePeers{
+		peers:     ps,
+		distances: make([]*big.Int, len(ps)),
+		latencies: make([]time.Duration, len(ps)),
+	}
+	for i, p := range ps {
+		lap.distances[i] = p.ID().Distance(target)
+		lap.latencies[i] = p.Recorder().ExpectedLatency()
+	}
+	return lap
+}
+
+func (lap *latencyAwarePeers) Len() int {
+	return len(lap.peers)
+}
+
+func (lap *latencyAwarePeers) Less(i, j int) bool {
+	if bi, bj := lap.distances[i].BitLen(), lap.distances[j].BitLen(); bi != bj {
+		return bi < bj
+	}
+	if lap.latencies[i] != lap.latencies[j] {
+		return lap.latencies[i] < lap.latencies[j]
+	}
+	return lap.distances[i].Cmp(lap.distances[j]) < 0
+}
+
+func (lap *latencyAwarePeers) Swap(i, j int) {
+	lap.peers[i], lap.peers[j] = lap.peers[j], lap.peers[i]
+	lap.distances[i], lap.distances[j] = lap.distances[j], lap.distances[i]
+	lap.latencies[i], lap.latencies[j] = lap.latencies[j], lap.latencies[i]
+}
+
 // splitLowerBound extends a lower bound one bit deeper with a 1 bit, thereby splitting
 // the domain implied by the current lower bound and depth
 // e.g.,
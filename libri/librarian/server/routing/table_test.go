@@ -7,8 +7,11 @@ import (
 	"sort"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/drausin/libri/libri/common/ecid"
 	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/common/storage"
 	"github.com/drausin/libri/libri/librarian/server/peer"
 	"github.com/stretchr/testify/assert"
 )
@@ -26,7 +29,8 @@ func TestTable_NewWithPeers_concurrent(t *testing.T) {
 	rng := rand.New(rand.NewSource(int64(0)))
 	concurrency := 4
 	for n := concurrency; n <= 256; n *= 2 {
-		rt := NewEmpty(id.NewPseudoRandom(rng), NewDefaultParameters())
+		rt := NewEmpty(id.NewPseudoRandom(rng), NewDefaultParameters(),
+			peer.NewDefaultReputations())
 		var wg sync.WaitGroup
 		for i := 0; i < concurrency; i++ {
 			wg.Add(1)
@@ -227,6 +231,276 @@ func TestTable_Peak(t *testing.T) {
 	}
 }
 
+func TestTable_Push_blacklisted(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	rt, _, _ := NewTestWithPeers(rng, 8)
+	existing := rt.Peak(id.NewPseudoRandom(rng), 1)[0]
+	newPeer := peer.NewTestPeer(rng, 8)
+	rt.(*table).reps = &fixedReputations{
+		blacklisted: map[string]bool{
+			existing.ID().String(): true,
+			newPeer.ID().String():  true,
+		},
+	}
+
+	// check blacklisted existing peer is removed from the table
+	assert.Equal(t, Dropped, rt.Push(existing))
+	assert.Equal(t, 7, rt.NumPeers())
+	_, in := rt.Get(existing.ID())
+	assert.False(t, in)
+
+	// check blacklisted new peer isn't added
+	assert.Equal(t, Dropped, rt.Push(newPeer))
+	assert.Equal(t, 7, rt.NumPeers())
+}
+
+func TestTable_Push_idPuzzle(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	params := NewDefaultParameters()
+	params.IDDifficulty = 2
+	rt := NewEmpty(id.NewPseudoRandom(rng), params, peer.NewDefaultReputations())
+
+	// check only peers with IDs solving the puzzle are added
+	nSolved := 0
+	for _, p := range peer.NewTestPeers(rng, 16) {
+		solved := ecid.IDDifficulty(p.ID()) >= params.IDDifficulty
+		if solved {
+			nSolved++
+			assert.Equal(t, Added, rt.Push(p))
+		} else {
+			assert.Equal(t, Dropped, rt.Push(p))
+		}
+	}
+	assert.True(t, nSolved > 0)
+	assert.Equal(t, nSolved, rt.NumPeers())
+}
+
+func TestTable_Push_replacement(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	rt, _, nAdded := NewTestWithPeers(rng, 256)
+	assert.True(t, nAdded < 256)
+
+	// check dropped peers are kept in replacement caches
+	nReplacements := 0
+	for _, b := range rt.(*table).buckets {
+		assert.True(t, uint(len(b.replacements)) <= b.maxReplacements)
+		if len(b.replacements) > 0 {
+			assert.False(t, b.Vacancy())
+			assert.False(t, b.containsSelf)
+		}
+		for _, p := range b.replacements {
+			_, in := rt.Get(p.ID())
+			assert.False(t, in)
+		}
+		nReplacements += len(b.replacements)
+	}
+	assert.True(t, nReplacements > 0)
+}
+
+func TestTable_Evict(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	rt, _, nAdded := NewTestWithPeers(rng, 256)
+	var b *bucket
+	for _, b = range rt.(*table).buckets {
+		if len(b.replacements) >= 2 {
+			break
+		}
+	}
+	nReplacements := len(b.replacements)
+	next1 := b.replacements[nReplacements-1]
+	next2 := b.replacements[nReplacements-2]
+
+	// check evicted peer is replaced by most recently seen replacement
+	evicted := b.activePeers[0]
+	rt.Evict(evicted.ID())
+	assert.Equal(t, nAdded, rt.NumPeers())
+	_, in := rt.Get(evicted.ID())
+	assert.False(t, in)
+	_, in = rt.Get(next1.ID())
+	assert.True(t, in)
+	assert.Equal(t, nReplacements-1, len(b.replacements))
+
+	// check blacklisted replacements are skipped
+	rt.(*table).reps = &fixedReputations{
+		blacklisted: map[string]bool{next2.ID().String(): true},
+	}
+	evicted = b.activePeers[0]
+	rt.Evict(evicted.ID())
+	assert.Equal(t, nAdded, rt.NumPeers())
+	_, in = rt.Get(next2.ID())
+	assert.False(t, in)
+	assert.Equal(t, nReplacements-3, len(b.replacements))
+
+	// check evicting peer not in table is a no-op
+	rt.Evict(evicted.ID())
+	assert.Equal(t, nAdded, rt.NumPeers())
+}
+
+func TestTable_Stale(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	rt, _, _ := NewTestWithPeers(rng, 64)
+
+	// no peers have responded yet, so each bucket's least recently seen peer is stale
+	stale := rt.Stale(time.Now())
+	assert.Equal(t, rt.NumBuckets(), len(stale))
+	assert.Empty(t, rt.Stale(time.Unix(0, 0)))
+
+	// check peers with recent responses aren't stale
+	for _, b := range rt.(*table).buckets {
+		for _, p := range b.activePeers {
+			p.Recorder().Record(peer.Response, peer.Success)
+		}
+	}
+	assert.Empty(t, rt.Stale(time.Now().Add(-time.Minute)))
+}
+
+func TestTable_Stale_lastSeen(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	rt, _, _ := NewTestWithPeers(rng, 8)
+	assert.Equal(t, 1, rt.NumBuckets())
+	peers := rt.(*table).buckets[0].activePeers
+
+	// peers seen only via errored responses are still stale
+	for i, p := range peers {
+		if i == 0 {
+			p.Recorder().Record(peer.Response, peer.Error)
+			continue
+		}
+		p.Recorder().Record(peer.Request, peer.Success)
+	}
+	stale := rt.Stale(time.Now().Add(-time.Minute))
+	assert.Len(t, stale, 1)
+	assert.Equal(t, peers[0].ID(), stale[0].ID())
+}
+
+func TestTable_Peers(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	rt, _, nAdded := NewTestWithPeers(rng, 64)
+	peers := rt.Peers()
+	assert.Len(t, peers, nAdded)
+	for _, p := range peers {
+		_, in := rt.Get(p.ID())
+		assert.True(t, in)
+	}
+}
+
+func TestTable_NumChanges(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	rt, _, nAdded := NewTestWithPeers(rng, 256)
+	assert.Equal(t, uint(nAdded), rt.NumChanges())
+
+	// existing and dropped peers aren't changes
+	for _, p := range rt.Peers() {
+		assert.Equal(t, Existed, rt.Push(p))
+	}
+	assert.Equal(t, uint(nAdded), rt.NumChanges())
+
+	// peaking doesn't change the table
+	rt.Peak(id.NewPseudoRandom(rng), 8)
+	assert.Equal(t, uint(nAdded), rt.NumChanges())
+
+	// evicting a peer removes it and adds its replacement
+	var b *bucket
+	for _, b = range rt.(*table).buckets {
+		if len(b.replacements) > 0 {
+			break
+		}
+	}
+	rt.Evict(b.activePeers[0].ID())
+	assert.Equal(t, uint(nAdded+2), rt.NumChanges())
+}
+
+func TestTable_Untouched(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	rt, _, _ := NewTestWithPeers(rng, 128)
+	assert.True(t, rt.NumBuckets() > 1)
+
+	// no lookups yet, so all buckets are untouched
+	targets := rt.Untouched(time.Now(), rng)
+	assert.Equal(t, rt.NumBuckets(), len(targets))
+	for i, target := range targets {
+		assert.Equal(t, i, rt.(*table).bucketIndex(target))
+	}
+
+	// check bucket targeted by Peak is touched
+	rt.Peak(targets[0], 1)
+	targets = rt.Untouched(time.Now().Add(-time.Minute), rng)
+	assert.Equal(t, rt.NumBuckets()-1, len(targets))
+	for _, target := range targets {
+		assert.NotEqual(t, 0, rt.(*table).bucketIndex(target))
+	}
+}
+
+func TestTable_Peak_blacklisted(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	rt, _, _ := NewTestWithPeers(rng, 16)
+	target, k := id.NewPseudoRandom(rng), uint(4)
+	closest := rt.Peak(target, k)
+	rt.(*table).reps = &fixedReputations{
+		blacklisted: map[string]bool{closest[0].ID().String(): true},
+	}
+
+	// check blacklisted peer is replaced by next closest and removed from the table
+	ps := rt.Peak(target, k)
+	assert.Equal(t, int(k), len(ps))
+	for _, p := range ps {
+		assert.NotEqual(t, closest[0].ID(), p.ID())
+	}
+	assert.Equal(t, 15, rt.NumPeers())
+	_, in := rt.Get(closest[0].ID())
+	assert.False(t, in)
+
+	// check we get all remaining peers when they're fewer than k
+	ps = rt.Peak(target, 32)
+	assert.Equal(t, 15, len(ps))
+}
+
+func TestTable_Peak_fast(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	rt, _, nAdded := NewTestWithPeers(rng, 64)
+	target, k := id.NewPseudoRandom(rng), uint(8)
+
+	// get candidates without any latency preference, sorted by distance since none have
+	// latencies yet, and make closer candidates slower
+	rt.(*table).params.PeakOversample = 1
+	candidates := rt.Peak(target, k*DefaultPeakOversample)
+	sort.Sort(newLatencyAwarePeers(target, candidates))
+	for i, p := range candidates {
+		p.Recorder().RecordLatency(time.Duration(len(candidates)-i) * time.Millisecond)
+	}
+
+	rt.(*table).params.PeakOversample = DefaultPeakOversample
+	ps := rt.Peak(target, k)
+	assert.Equal(t, int(k), len(ps))
+	assert.Equal(t, nAdded, rt.NumPeers())
+	peaked := make(map[string]bool)
+	for _, p := range ps {
+		peaked[p.ID().String()] = true
+	}
+
+	// check no unpeaked candidate is at a more similar distance or faster at the same one
+	for _, p := range ps {
+		pBits := p.ID().Distance(target).BitLen()
+		for _, q := range candidates {
+			if peaked[q.ID().String()] {
+				continue
+			}
+			qBits := q.ID().Distance(target).BitLen()
+			assert.True(t, pBits < qBits || (pBits == qBits &&
+				p.Recorder().ExpectedLatency() <= q.Recorder().ExpectedLatency()))
+		}
+	}
+
+	// check some faster peer replaced one of the k closest
+	nClosest := 0
+	for _, p := range candidates[:k] {
+		if peaked[p.ID().String()] {
+			nClosest++
+		}
+	}
+	assert.True(t, nClosest < int(k))
+}
+
 func TestTable_Peak_concurrent(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	rt, _, _ := NewTestWithPeers(rng, 256)
@@ -580,3 +854,23 @@ func checkPoppedPeers(t *testing.T, k uint, numActivePeers int, ps []peer.Peer,
 		seen[p.ID().String()] = struct{}{}
 	}
 }
+
+type fixedReputations struct {
+	blacklisted map[string]bool
+}
+
+func (r *fixedReputations) Penalize(peerID id.ID, o peer.Offense) bool {
+	return false
+}
+
+func (r *fixedReputations) Score(peerID id.ID) float64 {
+	return 0
+}
+
+func (r *fixedReputations) Blacklisted(peerID id.ID) bool {
+	return r.blacklisted[peerID.String()]
+}
+
+func (r *fixedReputations) Save(ns storage.NamespaceStorer) error {
+	return nil
+}
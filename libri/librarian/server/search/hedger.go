@@ -0,0 +1,68 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/drausin/libri/libri/librarian/server/peer"
+)
+
+// hedger decides when an outstanding query has become a straggler that should be hedged with a
+// query to another peer.
+type hedger struct {
+	// percentile of observed latencies after which a query is hedged, or zero to never hedge
+	percentile float64
+
+	// minimum time before a query is hedged
+	minDelay time.Duration
+
+	// observed query latencies, in ascending order
+	latencies []time.Duration
+}
+
+// newHedger creates a new hedger for a search with the given parameters, using the latencies
+// previously recorded for the seeds as the initial observations.
+func newHedger(params *Parameters, seeds []peer.Peer) *hedger {
+	h := &hedger{
+		percentile: params.HedgePercentile,
+		minDelay:   params.MinHedgeDelay,
+		latencies:  make([]time.Duration, 0, len(seeds)),
+	}
+	for _, seed := range seeds {
+		if latency := seed.Recorder().Latency(); latency > 0 {
+			h.observe(latency)
+		}
+	}
+	return h
+}
+
+// observe adds a query latency to those observed.
+func (h *hedger) observe(latency time.Duration) {
+	i := sort.Search(len(h.latencies), func(i int) bool { return h.latencies[i] > latency })
+	h.latencies = append(h.latencies, 0)
+	copy(h.latencies[i+1:], h.latencies[i:])
+	h.latencies[i] = latency
+}
+
+// enabled returns whether any queries are hedged.
+func (h *hedger) enabled() bool {
+	return h.percentile > 0
+}
+
+// delay returns the time after which an outstanding query is hedged.
+func (h *hedger) delay() time.Duration {
+	if len(h.latencies) == 0 {
+		return h.minDelay
+	}
+	i := int(math.Ceil(h.percentile*float64(len(h.latencies)))) - 1
+	if i < 0 {
+		i = 0
+	} else if i >= len(h.latencies) {
+		i = len(h.latencies) - 1
+	}
+	if h.latencies[i] < h.minDelay {
+		return h.minDelay
+	}
+	return h.latencies[i]
+}
@@ -0,0 +1,48 @@
+package search
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/drausin/libri/libri/librarian/server/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHedger(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	seeds := peer.NewTestPeers(rng, 4)
+	for i, seed := range seeds[:3] {
+		seed.Recorder().RecordLatency(time.Duration(3-i) * time.Second)
+	}
+	h := newHedger(NewDefaultParameters(), seeds)
+
+	// only seeds with recorded latencies are observed, in ascending order
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}, h.latencies)
+	assert.True(t, h.enabled())
+
+	params := NewDefaultParameters()
+	params.HedgePercentile = 0
+	assert.False(t, newHedger(params, seeds).enabled())
+}
+
+func TestHedger_delay(t *testing.T) {
+	h := &hedger{percentile: 0.9, minDelay: 10 * time.Millisecond}
+
+	// no observations
+	assert.Equal(t, h.minDelay, h.delay())
+
+	for i := 10; i > 0; i-- {
+		h.observe(time.Duration(i) * 100 * time.Millisecond)
+	}
+	assert.Equal(t, 900*time.Millisecond, h.delay())
+
+	h.percentile = 1.0
+	assert.Equal(t, time.Second, h.delay())
+
+	h.percentile = 0.01
+	assert.Equal(t, 100*time.Millisecond, h.delay())
+
+	h.minDelay = 200 * time.Millisecond
+	assert.Equal(t, h.minDelay, h.delay())
+}
@@ -3,6 +3,7 @@ package search
 import (
 	"container/heap"
 	"math/big"
+	"time"
 
 	"github.com/drausin/libri/libri/common/id"
 	"github.com/drausin/libri/libri/librarian/api"
@@ -21,7 +22,9 @@ type PeerDistanceHeap interface {
 	// SafePushMany pushed an array of peers.
 	SafePushMany([]peer.Peer) error
 
-	// PeakDistance returns the distance from the root of the heap to the target.
+	// PeakDistance returns the distance from the root of the heap to the target. For heaps
+	// preferring fast peers, where the root may not be the closest peer, it returns the minimum
+	// distance of any peer to the target.
 	PeakDistance() *big.Int
 
 	// PeakPeer returns (but does not remove) the the root of the heap.
@@ -43,7 +46,9 @@ type PeerDistanceHeap interface {
 	Capacity() int
 }
 
-// ClosestPeers is a min-heap of peers with the closest peer at the root.
+// ClosestPeers is a min-heap of peers with the closest peer at the root. When preferring fast
+// peers, the root is instead the peer with the lowest expected latency among those at a similar
+// distance (i.e., with the same number of leading zero bits) to the closest.
 type ClosestPeers interface {
 	PeerDistanceHeap
 }
@@ -64,6 +69,12 @@ type peerDistanceHeap struct {
 	// the distances of each peer to the target
 	distances []*big.Int
 
+	// the expected latencies of each peer when pushed onto the heap
+	latencies []time.Duration
+
+	// whether to order peers at similar distances by their expected latencies
+	preferFast bool
+
 	// set of IDStrs of the peers in the heap
 	ids map[string]struct{}
 
@@ -80,17 +91,25 @@ func newClosestPeers(target id.ID, capacity uint) ClosestPeers {
 		target:    target,
 		peers:     make([]peer.Peer, 0, int(capacity)+1),
 		distances: make([]*big.Int, 0, int(capacity)+1),
+		latencies: make([]time.Duration, 0, int(capacity)+1),
 		ids:       make(map[string]struct{}),
 		sign:      1,
 		capacity:  int(capacity),
 	}
 }
 
+func newFastClosestPeers(target id.ID, capacity uint) ClosestPeers {
+	cp := newClosestPeers(target, capacity).(*peerDistanceHeap)
+	cp.preferFast = true
+	return cp
+}
+
 func newFarthestPeers(target id.ID, capacity uint) FarthestPeers {
 	return &peerDistanceHeap{
 		target:    target,
 		peers:     make([]peer.Peer, 0, int(capacity)+1),
 		distances: make([]*big.Int, 0, int(capacity)+1),
+		latencies: make([]time.Duration, 0, int(capacity)+1),
 		ids:       make(map[string]struct{}),
 		sign:      -1,
 		capacity:  int(capacity),
@@ -98,7 +117,16 @@ func newFarthestPeers(target id.ID, capacity uint) FarthestPeers {
 }
 
 func (pdh *peerDistanceHeap) PeakDistance() *big.Int {
-	return pdh.distances[0]
+	if !pdh.preferFast {
+		return pdh.distances[0]
+	}
+	min := pdh.distances[0]
+	for _, d := range pdh.distances[1:] {
+		if d.Cmp(min) < 0 {
+			min = d
+		}
+	}
+	return min
 }
 
 func (pdh *peerDistanceHeap) PeakPeer() peer.Peer {
@@ -166,7 +194,17 @@ func (pdh *peerDistanceHeap) Capacity() int {
 }
 
 // Less returns whether peer i is closer (or farther in case of max heap) to the target than peer j.
+// When preferring fast peers, it instead returns whether peer i has a lower expected latency than
+// peer j if both have the same number of leading zero bits in their distances to the target.
 func (pdh *peerDistanceHeap) Less(i, j int) bool {
+	if pdh.preferFast {
+		if bi, bj := pdh.distances[i].BitLen(), pdh.distances[j].BitLen(); bi != bj {
+			return pdh.sign*(bi-bj) < 0
+		}
+		if pdh.latencies[i] != pdh.latencies[j] {
+			return pdh.latencies[i] < pdh.latencies[j]
+		}
+	}
 	return less(pdh.sign, pdh.distances[i], pdh.distances[j])
 }
 
@@ -174,11 +212,13 @@ func (pdh *peerDistanceHeap) Less(i, j int) bool {
 func (pdh *peerDistanceHeap) Swap(i, j int) {
 	pdh.peers[i], pdh.peers[j] = pdh.peers[j], pdh.peers[i]
 	pdh.distances[i], pdh.distances[j] = pdh.distances[j], pdh.distances[i]
+	pdh.latencies[i], pdh.latencies[j] = pdh.latencies[j], pdh.latencies[i]
 }
 
 func (pdh *peerDistanceHeap) Push(p interface{}) {
 	pdh.peers = append(pdh.peers, p.(peer.Peer))
 	pdh.distances = append(pdh.distances, pdh.Distance(p.(peer.Peer)))
+	pdh.latencies = append(pdh.latencies, p.(peer.Peer).Recorder().ExpectedLatency())
 	pdh.ids[p.(peer.Peer).ID().String()] = struct{}{}
 }
 
@@ -186,6 +226,7 @@ func (pdh *peerDistanceHeap) Pop() interface{} {
 	root := pdh.peers[len(pdh.peers)-1]
 	pdh.peers = pdh.peers[0 : len(pdh.peers)-1]
 	pdh.distances = pdh.distances[0 : len(pdh.distances)-1]
+	pdh.latencies = pdh.latencies[0 : len(pdh.latencies)-1]
 	delete(pdh.ids, root.ID().String())
 	return root
 }
@@ -4,6 +4,7 @@ import (
 	"container/heap"
 	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/drausin/libri/libri/common/id"
 	"github.com/drausin/libri/libri/librarian/server/peer"
@@ -123,3 +124,40 @@ func testPeerDistanceHeapSafePushCapacity(t *testing.T, rng *rand.Rand, pdh Peer
 		prevDistance = pdh.PeakDistance()
 	}
 }
+
+func TestFastClosestPeers_Heap(t *testing.T) {
+	rng := rand.New(rand.NewSource(int64(0)))
+	target := id.NewPseudoRandom(rng)
+	cp := newFastClosestPeers(target, 40)
+	ps := peer.NewTestPeers(rng, 40)
+	for i, p := range ps {
+		p.Recorder().RecordLatency(time.Duration(rng.Intn(100)+1) * time.Millisecond)
+		if i%4 == 0 {
+			p.Recorder().Record(peer.Response, peer.Error)
+		}
+	}
+	assert.Nil(t, cp.SafePushMany(ps))
+
+	// peak distance is still the closest distance of any peer
+	minDist := cp.Distance(ps[0])
+	for _, p := range ps[1:] {
+		if d := cp.Distance(p); d.Cmp(minDist) < 0 {
+			minDist = d
+		}
+	}
+	assert.Equal(t, minDist, cp.PeakDistance())
+
+	prev := heap.Pop(cp).(peer.Peer)
+	for cp.Len() > 0 {
+		next := heap.Pop(cp).(peer.Peer)
+		prevBits, nextBits := cp.Distance(prev).BitLen(), cp.Distance(next).BitLen()
+
+		// each subsequent peer should be farther away or similarly close and no faster
+		assert.True(t, prevBits <= nextBits)
+		if prevBits == nextBits {
+			assert.True(t, prev.Recorder().ExpectedLatency() <=
+				next.Recorder().ExpectedLatency())
+		}
+		prev = next
+	}
+}
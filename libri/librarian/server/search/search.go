@@ -20,19 +20,48 @@ const (
 	// DefaultNMaxErrors is the default maximum number of errors tolerated during a search.
 	DefaultNMaxErrors = uint(3)
 
-	// DefaultConcurrency is the default number of parallel search workers. Currently 1 for
-	// simplicity and because bumping to 3 doesn't seem to improve get performance at all.
-	DefaultConcurrency = uint(1)
+	// DefaultConcurrency is the default number of parallel queries (alpha) a search keeps
+	// outstanding, not counting stragglers that have been hedged.
+	DefaultConcurrency = uint(3)
 
 	// DefaultQueryTimeout is the timeout for each query to a peer.
 	DefaultQueryTimeout = 5 * time.Second
 
+	// DefaultNMatchingValues is the default number of peers that must return the same value
+	// before a search accepts it.
+	DefaultNMatchingValues = uint(1)
+
+	// DefaultPreferFastPeers is the default for whether searches query peers with lower expected
+	// latencies first among those at similar distances to the key.
+	DefaultPreferFastPeers = true
+
+	// DefaultNDisjointPaths is the default number of disjoint paths a search looks up the key
+	// along. A single path disables disjoint-path searches.
+	DefaultNDisjointPaths = uint(1)
+
+	// DefaultNAgreeingPaths is the default number of disjoint paths that must agree on the value
+	// or on each of the closest peers for the search to succeed.
+	DefaultNAgreeingPaths = uint(1)
+
+	// DefaultHedgePercentile is the default percentile of the query latencies observed during a
+	// search after which an outstanding query is hedged with a query to another peer.
+	DefaultHedgePercentile = 0.9
+
+	// DefaultMinHedgeDelay is the default minimum time a query is outstanding before it is hedged.
+	DefaultMinHedgeDelay = 50 * time.Millisecond
+
 	// logging keys
 	logKey               = "key"
 	logNClosestResponses = "n_closest_responses"
 	logNMaxErrors        = "n_max_errors"
 	logConcurrency       = "concurrency"
 	logTimeout           = "timeout"
+	logNMatchingValues   = "n_matching_values"
+	logPreferFastPeers   = "prefer_fast_peers"
+	logNDisjointPaths    = "n_disjoint_paths"
+	logNAgreeingPaths    = "n_agreeing_paths"
+	logHedgePercentile   = "hedge_percentile"
+	logMinHedgeDelay     = "min_hedge_delay"
 	logNClosest          = "n_closest"
 	logNUnqueried        = "n_unqueried"
 	logNResponded        = "n_responded"
@@ -55,11 +84,33 @@ type Parameters struct {
 	// maximum number of errors tolerated when querying peers during the search
 	NMaxErrors uint
 
-	// number of concurrent queries to use in search
+	// number of concurrent queries (alpha) to use in search, not counting hedged stragglers
 	Concurrency uint
 
 	// timeout for queries to individual peers
 	Timeout time.Duration
+
+	// number of peers that must return the same value before the search accepts it
+	NMatchingValues uint
+
+	// whether to query peers with lower expected latencies first among those at similar
+	// distances to the key
+	PreferFastPeers bool
+
+	// number of independent paths to look up the key along, none of which query the same peer
+	NDisjointPaths uint
+
+	// number of paths that must agree on the value or on each of the closest peers for the search
+	// to succeed
+	NAgreeingPaths uint
+
+	// percentile (in (0, 1]) of the query latencies observed during the search after which an
+	// outstanding query is considered a straggler and hedged with a query to the next-closest
+	// unqueried peer, without cancelling it; zero disables hedging
+	HedgePercentile float64
+
+	// minimum time a query is outstanding before it is hedged
+	MinHedgeDelay time.Duration
 }
 
 // NewDefaultParameters creates an instance with default parameters.
@@ -69,6 +120,12 @@ func NewDefaultParameters() *Parameters {
 		NMaxErrors:        DefaultNMaxErrors,
 		Concurrency:       DefaultConcurrency,
 		Timeout:           DefaultQueryTimeout,
+		NMatchingValues:   DefaultNMatchingValues,
+		PreferFastPeers:   DefaultPreferFastPeers,
+		NDisjointPaths:    DefaultNDisjointPaths,
+		NAgreeingPaths:    DefaultNAgreeingPaths,
+		HedgePercentile:   DefaultHedgePercentile,
+		MinHedgeDelay:     DefaultMinHedgeDelay,
 	}
 }
 
@@ -78,6 +135,12 @@ func (p *Parameters) MarshalLogObject(oe zapcore.ObjectEncoder) error {
 	oe.AddUint(logNMaxErrors, p.NMaxErrors)
 	oe.AddUint(logConcurrency, p.Concurrency)
 	oe.AddDuration(logTimeout, p.Timeout)
+	oe.AddUint(logNMatchingValues, p.NMatchingValues)
+	oe.AddBool(logPreferFastPeers, p.PreferFastPeers)
+	oe.AddUint(logNDisjointPaths, p.NDisjointPaths)
+	oe.AddUint(logNAgreeingPaths, p.NAgreeingPaths)
+	oe.AddFloat64(logHedgePercentile, p.HedgePercentile)
+	oe.AddDuration(logMinHedgeDelay, p.MinHedgeDelay)
 	return nil
 }
 
@@ -86,6 +149,12 @@ type Result struct {
 	// found value when looking for one, otherwise nil
 	Value *api.Document
 
+	// number of peers that have returned each (verified) value, keyed by the value's digest
+	valueCounts map[string]uint
+
+	// number of peers that must return the same value before it is found
+	nMatchingValues uint
+
 	// heap of the responding peers found closest to the target
 	Closest FarthestPeers
 
@@ -95,21 +164,36 @@ type Result struct {
 	// map of all peers that responded during search
 	Responded map[string]peer.Peer
 
+	// map of peers that responded during search without the value
+	Lacking map[string]peer.Peer
+
 	// Errored contains the errors received by each peer (via string representation of peer ID)
 	Errored map[string]error
 
+	// set of IDStrs of all peers returned by responding peers
+	reported map[string]struct{}
+
 	// fatal error that occurred during the search
 	FatalErr error
 }
 
 // NewInitialResult creates a new Result object for the beginning of a search.
 func NewInitialResult(key id.ID, params *Parameters) *Result {
+	nUnqueried := params.NClosestResponses * params.Concurrency
+	unqueried := newClosestPeers(key, nUnqueried)
+	if params.PreferFastPeers {
+		unqueried = newFastClosestPeers(key, nUnqueried)
+	}
 	return &Result{
-		Value:     nil,
-		Closest:   newFarthestPeers(key, params.NClosestResponses),
-		Unqueried: newClosestPeers(key, params.NClosestResponses*params.Concurrency),
-		Responded: make(map[string]peer.Peer),
-		Errored:   make(map[string]error),
+		Value:           nil,
+		valueCounts:     make(map[string]uint),
+		nMatchingValues: params.NMatchingValues,
+		Closest:         newFarthestPeers(key, params.NClosestResponses),
+		Unqueried:       unqueried,
+		Responded:       make(map[string]peer.Peer),
+		Lacking:         make(map[string]peer.Peer),
+		Errored:         make(map[string]error),
+		reported:        make(map[string]struct{}),
 	}
 }
 
@@ -154,18 +238,59 @@ type Search struct {
 	// parameters defining the search
 	Params *Parameters
 
-	// mutex used to synchronizes reads and writes to this instance
-	mu sync.Mutex
+	// index of the path claiming each peer (keyed by ID string) in a disjoint-path search, or nil
+	// for a single-path search
+	claims map[string]int
+
+	// index of the search's path in a disjoint-path search
+	path int
+
+	// mutex used to synchronizes reads and writes to this instance, shared by the paths of a
+	// disjoint-path search since their peers' recorders may be shared
+	mu *sync.Mutex
 }
 
 // NewSearch creates a new Search instance for a given target, search type, and search parameters.
-func NewSearch(selfID ecid.ID, key id.ID, params *Parameters) *Search {
+func NewSearch(selfID ecid.Identity, key id.ID, params *Parameters) *Search {
 	return &Search{
 		Key:     key,
 		Request: client.NewFindRequest(selfID, key, params.NClosestResponses),
 		Result:  NewInitialResult(key, params),
 		Params:  params,
+		mu:      new(sync.Mutex),
+	}
+}
+
+// newPathSearches creates a search for each of the disjoint paths of the given search.
+func newPathSearches(parent *Search) []*Search {
+	claims := make(map[string]int)
+	paths := make([]*Search, parent.Params.NDisjointPaths)
+	for i := range paths {
+		paths[i] = &Search{
+			Key:     parent.Key,
+			Request: parent.Request,
+			Result:  NewInitialResult(parent.Key, parent.Params),
+			Params:  parent.Params,
+			claims:  claims,
+			path:    i,
+			mu:      parent.mu,
+		}
 	}
+	return paths
+}
+
+// claim returns whether the search's path may query the peer, which is always the case unless
+// another path of a disjoint-path search has already claimed it. Callers must hold the search's
+// lock.
+func (s *Search) claim(p peer.Peer) bool {
+	if s.claims == nil {
+		return true
+	}
+	if claimant, in := s.claims[p.ID().String()]; in {
+		return claimant == s.path
+	}
+	s.claims[p.ID().String()] = s.path
+	return true
 }
 
 // MarshalLogObject converts the Search into an object (which will become json) for logging.
@@ -212,10 +337,19 @@ func (s *Search) Exhausted() bool {
 }
 
 // Finished returns whether the search has finished, either because it has found the target or
-// closest peers or errored or exhausted the list of peers to query. This operation is concurrency
-// safe.
+// closest peers or errored or exhausted the list of peers to query. A search that has received
+// values from fewer than the required number of peers keeps going after finding the closest
+// peers. This operation is concurrency safe.
 func (s *Search) Finished() bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	return s.FoundValue() || s.FoundClosestPeers() || s.Errored() || s.Exhausted()
+	return s.converged() || s.Exhausted()
+}
+
+// converged returns whether the search has found the target or closest peers or errored, so
+// responses from any outstanding queries are no longer needed. Callers must hold the search's
+// lock.
+func (s *Search) converged() bool {
+	return s.FoundValue() || (s.FoundClosestPeers() && len(s.Result.valueCounts) == 0) ||
+		s.Errored()
 }
@@ -20,6 +20,9 @@ func TestNewDefaultParameters(t *testing.T) {
 	assert.NotZero(t, p.NMaxErrors)
 	assert.NotZero(t, p.Concurrency)
 	assert.NotZero(t, p.Timeout)
+	assert.NotZero(t, p.NMatchingValues)
+	assert.NotZero(t, p.NDisjointPaths)
+	assert.NotZero(t, p.NAgreeingPaths)
 }
 
 func TestParameters_MarshalLogObject(t *testing.T) {
@@ -47,6 +50,44 @@ func TestSearch_MarshalLogObject(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestNewPathSearches(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	params := NewDefaultParameters()
+	params.NDisjointPaths = 3
+	parent := NewSearch(ecid.NewPseudoRandom(rng), id.NewPseudoRandom(rng), params)
+	paths := newPathSearches(parent)
+	assert.Len(t, paths, 3)
+	for i, path := range paths {
+		assert.Equal(t, parent.Key, path.Key)
+		assert.Equal(t, parent.Request, path.Request)
+		assert.Equal(t, i, path.path)
+		assert.Equal(t, parent.mu, path.mu)
+		assert.True(t, parent.Result != path.Result)
+	}
+}
+
+func TestSearch_claim(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	p1, p2 := peer.NewTestPeer(rng, 0), peer.NewTestPeer(rng, 1)
+
+	// single-path searches may query any peer
+	single := NewSearch(ecid.NewPseudoRandom(rng), id.NewPseudoRandom(rng),
+		NewDefaultParameters())
+	assert.True(t, single.claim(p1))
+	assert.True(t, single.claim(p1))
+
+	// disjoint paths may only query the peers they claim first
+	params := NewDefaultParameters()
+	params.NDisjointPaths = 2
+	paths := newPathSearches(NewSearch(ecid.NewPseudoRandom(rng), id.NewPseudoRandom(rng),
+		params))
+	assert.True(t, paths[0].claim(p1))
+	assert.True(t, paths[0].claim(p1))
+	assert.False(t, paths[1].claim(p1))
+	assert.True(t, paths[1].claim(p2))
+	assert.False(t, paths[0].claim(p2))
+}
+
 func TestSearch_FoundClosestPeers(t *testing.T) {
 	// target = 0 makes it easy to compute XOR distance manually
 	rng := rand.New(rand.NewSource(0))
@@ -3,14 +3,17 @@ package search
 import (
 	"bytes"
 	"container/heap"
+	"crypto/sha256"
 	"errors"
 	"sync"
 	"time"
 
 	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/common/storage"
 	"github.com/drausin/libri/libri/librarian/api"
 	"github.com/drausin/libri/libri/librarian/client"
 	"github.com/drausin/libri/libri/librarian/server/peer"
+	"github.com/golang/protobuf/proto"
 )
 
 const searcherFindRetryTimeout = 100 * time.Millisecond
@@ -18,11 +21,20 @@ const searcherFindRetryTimeout = 100 * time.Millisecond
 var (
 	// ErrTooManyFindErrors indicates when a search has encountered too many Find request errors.
 	ErrTooManyFindErrors = errors.New("too many Find errors")
+
+	// ErrInvalidValue indicates when a peer returns a value that doesn't match the search key.
+	ErrInvalidValue = errors.New("found value does not match search key")
+
+	// ErrTooFewAgreeingPaths indicates when too few paths of a disjoint-path search agree on the
+	// value or find the closest peers.
+	ErrTooFewAgreeingPaths = errors.New("too few disjoint search paths agree")
 )
 
 // Searcher executes searches for particular keys.
 type Searcher interface {
-	// Search executes a search from a list of seeds.
+	// Search executes a search from a list of seeds. When the search has more than one disjoint
+	// path, the seeds are split among independent searches along each path, which never query
+	// the same peer.
 	Search(search *Search, seeds []peer.Peer) error
 }
 
@@ -35,11 +47,23 @@ type searcher struct {
 
 	// processes the find query responses from the peers
 	rp ResponseProcessor
+
+	// ensures found values match the search key
+	kvc storage.KeyValueChecker
+
+	// ensures found Pointer values match the search key
+	pkvc storage.KeyValueChecker
 }
 
 // NewSearcher returns a new Searcher with the given Querier and ResponseProcessor.
 func NewSearcher(s client.Signer, c client.FinderCreator, rp ResponseProcessor) Searcher {
-	return &searcher{signer: s, finderCreator: c, rp: rp}
+	return &searcher{
+		signer:        s,
+		finderCreator: c,
+		rp:            rp,
+		kvc:           storage.NewHashKeyValueChecker(),
+		pkvc:          storage.NewPointerKeyValueChecker(),
+	}
 }
 
 // NewDefaultSearcher creates a new Searcher with default sub-object instantiations.
@@ -52,83 +76,327 @@ func NewDefaultSearcher(signer client.Signer) Searcher {
 }
 
 func (s *searcher) Search(search *Search, seeds []peer.Peer) error {
+	if search.Params.NDisjointPaths > 1 {
+		s.searchDisjoint(search, seeds)
+	} else {
+		s.searchPath(search, seeds)
+	}
+	return search.Result.FatalErr
+}
+
+// searchPath searches from the seeds along a single path, keeping the search's concurrency
+// (alpha) of queries outstanding. Queries outstanding for longer than the hedge delay are
+// stragglers and are hedged by querying the next-closest unqueried peer without waiting for
+// them. The search ends as soon as it has found the value or errored, even if stragglers are
+// still outstanding. A search converging on the closest peers first waits for the responses from
+// any peers closer than them, since they may have the value.
+func (s *searcher) searchPath(search *Search, seeds []peer.Peer) {
 	if err := search.Result.Unqueried.SafePushMany(seeds); err != nil {
 		panic(err) // should never happen
 	}
+	search.mu.Lock()
+	hedge := newHedger(search.Params, seeds)
+	search.mu.Unlock()
+
+	outcomes, done := make(chan *queryOutcome), make(chan struct{})
+	defer close(done)
+	outstanding := make(map[string]*outstandingQuery)
+	nActive, maxOutstanding := uint(0), 2*search.Params.Concurrency
+	for {
+		search.mu.Lock()
+		converged := search.converged()
+		if converged && !awaitingCloser(search, outstanding) {
+			search.mu.Unlock()
+			return
+		}
+		for !converged && nActive < search.Params.Concurrency &&
+			uint(len(outstanding)) < maxOutstanding {
+			next := nextUnqueried(search, outstanding)
+			if next == nil {
+				break
+			}
+			outstanding[next.ID().String()] = &outstandingQuery{peer: next, start: time.Now()}
+			nActive++
+			go s.queryAsync(search, next, outcomes, done)
+		}
+		hedgeDelay := hedge.delay()
+		search.mu.Unlock()
+		if len(outstanding) == 0 {
+			// exhausted all unqueried peers or converged
+			return
+		}
+
+		var hedgeTimer *time.Timer
+		var hedgeTimeout <-chan time.Time
+		if wait, ok := nextHedge(outstanding, hedgeDelay); ok && hedge.enabled() {
+			hedgeTimer = time.NewTimer(wait)
+			hedgeTimeout = hedgeTimer.C
+		}
+		select {
+		case outcome := <-outcomes:
+			idStr := outcome.peer.ID().String()
+			if !outstanding[idStr].hedged {
+				nActive--
+			}
+			delete(outstanding, idStr)
+			search.mu.Lock()
+			s.processOutcome(search, outcome, hedge)
+			search.mu.Unlock()
+		case <-hedgeTimeout:
+			for _, q := range outstanding {
+				if !q.hedged && time.Since(q.start) >= hedgeDelay {
+					q.hedged = true
+					nActive--
+				}
+			}
+		}
+		if hedgeTimer != nil {
+			hedgeTimer.Stop()
+		}
+	}
+}
+
+// searchDisjoint searches along each of the search's disjoint paths and then merges their
+// results, accepting a value or closest peers only when enough paths agree on them.
+func (s *searcher) searchDisjoint(search *Search, seeds []peer.Peer) {
+	paths := newPathSearches(search)
+
+	// deal seeds out to the paths from closest to farthest so each gets some close ones
+	sortedSeeds := newClosestPeers(search.Key, uint(len(seeds)))
+	if err := sortedSeeds.SafePushMany(seeds); err != nil {
+		panic(err) // should never happen
+	}
+	pathSeeds := make([][]peer.Peer, len(paths))
+	for i := 0; sortedSeeds.Len() > 0; i++ {
+		next := heap.Pop(sortedSeeds).(peer.Peer)
+		pathSeeds[i%len(paths)] = append(pathSeeds[i%len(paths)], next)
+	}
 
 	var wg sync.WaitGroup
-	for c := uint(0); c < search.Params.Concurrency; c++ {
+	for i, path := range paths {
 		wg.Add(1)
-		go s.searchWork(search, &wg)
+		go func(path *Search, seeds []peer.Peer) {
+			defer wg.Done()
+			s.searchPath(path, seeds)
+		}(path, pathSeeds[i])
 	}
 	wg.Wait()
 
-	return search.Result.FatalErr
+	search.mu.Lock()
+	defer search.mu.Unlock()
+	mergePaths(search, paths)
 }
 
-func (s *searcher) searchWork(search *Search, wg *sync.WaitGroup) {
-	defer wg.Done()
-	for !search.Finished() {
+// mergePaths merges the results of the disjoint paths into the search's result. A value is
+// accepted when enough paths found it. Otherwise, only the closest peers that enough paths agree
+// on are kept, where a path agrees on a peer when the peer is among its closest or was returned
+// to it by one of the peers it queried. Peers a path was led to by an attacker that has eclipsed
+// it are thus only kept if the honest paths also find them.
+func mergePaths(search *Search, paths []*Search) {
+	result := search.Result
+	pathValues := make(map[string]*api.Document)
+	var closestPaths []*Search
+	for _, path := range paths {
+		for idStr, p := range path.Result.Responded {
+			result.Responded[idStr] = p
+		}
+		for idStr, p := range path.Result.Lacking {
+			result.Lacking[idStr] = p
+		}
+		for idStr, err := range path.Result.Errored {
+			result.Errored[idStr] = err
+		}
+		if path.FoundValue() {
+			digest, err := valueDigest(path.Result.Value)
+			if err != nil {
+				panic(err) // should never happen since values are already checked
+			}
+			result.valueCounts[digest]++
+			pathValues[digest] = path.Result.Value
+		} else if path.FoundClosestPeers() && path.Result.FatalErr == nil {
+			closestPaths = append(closestPaths, path)
+		}
+	}
 
-		// get next peer to query
-		search.mu.Lock()
+	for digest, count := range result.valueCounts {
+		if count >= search.Params.NAgreeingPaths {
+			result.Value = pathValues[digest]
+			return
+		}
+	}
+	if uint(len(closestPaths)) < search.Params.NAgreeingPaths {
+		result.FatalErr = ErrTooFewAgreeingPaths
+		return
+	}
+	for _, path := range closestPaths {
+		for _, p := range path.Result.Closest.Peers() {
+			if nAgreeingPaths(p, closestPaths) < search.Params.NAgreeingPaths {
+				continue
+			}
+			if err := result.Closest.SafePush(p); err != nil {
+				panic(err) // should never happen
+			}
+		}
+	}
+	if result.Closest.Len() == 0 {
+		result.FatalErr = ErrTooFewAgreeingPaths
+	}
+}
+
+// nAgreeingPaths returns the number of paths that have the peer among their closest peers or
+// were returned it by a peer they queried.
+func nAgreeingPaths(p peer.Peer, paths []*Search) uint {
+	idStr, n := p.ID().String(), uint(0)
+	for _, path := range paths {
+		_, reported := path.Result.reported[idStr]
+		if reported || path.Result.Closest.In(p.ID()) {
+			n++
+		}
+	}
+	return n
+}
+
+// queryOutcome is the outcome of querying a peer during a search.
+type queryOutcome struct {
+	peer     peer.Peer
+	response *api.FindResponse
+	err      error
+	latency  time.Duration
+}
+
+// outstandingQuery is a query sent to a peer that hasn't yet responded.
+type outstandingQuery struct {
+	peer   peer.Peer
+	start  time.Time
+	hedged bool
+}
+
+// nextUnqueried pops the next peer to query from the search's unqueried peers, skipping those
+// already queried or being queried, or nil if there are none. Callers must hold the search's
+// lock.
+func nextUnqueried(search *Search, outstanding map[string]*outstandingQuery) peer.Peer {
+	for search.Result.Unqueried.Len() > 0 {
 		next := heap.Pop(search.Result.Unqueried).(peer.Peer)
 		nextIDStr := next.ID().String()
 		if _, in := search.Result.Responded[nextIDStr]; in {
-			search.mu.Unlock()
 			continue
 		}
 		if _, in := search.Result.Errored[nextIDStr]; in {
-			search.mu.Unlock()
 			continue
 		}
-		search.mu.Unlock()
-
-		// do the query
-		response, err := s.query(next.Connector(), search)
-		if err != nil {
-			// if we had an issue querying, skip to next peer
-			search.mu.Lock()
-			search.Result.Errored[nextIDStr] = err
-			next.Recorder().Record(peer.Response, peer.Error)
-			if search.Errored() {
-				search.Result.FatalErr = ErrTooManyFindErrors
-			}
-			search.mu.Unlock()
+		if _, in := outstanding[nextIDStr]; in {
 			continue
 		}
-		search.mu.Lock()
-		next.Recorder().Record(peer.Response, peer.Success)
-		search.mu.Unlock()
+		if !search.claim(next) {
+			// another disjoint path is querying the peer
+			continue
+		}
+		return next
+	}
+	return nil
+}
 
-		// process the heap's response
-		search.mu.Lock()
-		err = s.rp.Process(response, search.Result)
-		search.mu.Unlock()
-		if err != nil {
-			search.mu.Lock()
-			search.Result.FatalErr = err
-			search.mu.Unlock()
-			return
+// awaitingCloser returns whether the search is waiting on a query to a peer closer to the key than
+// the farthest of the closest peers found. Callers must hold the search's lock.
+func awaitingCloser(search *Search, outstanding map[string]*outstandingQuery) bool {
+	if search.FoundValue() || search.Errored() || search.Result.Closest.Len() == 0 {
+		return false
+	}
+	maxDist := search.Result.Closest.PeakDistance()
+	for _, q := range outstanding {
+		if search.Key.Distance(q.peer.ID()).Cmp(maxDist) < 0 {
+			return true
 		}
+	}
+	return false
+}
 
-		// add to heap of closest responded peers
-		search.mu.Lock()
-		err = search.Result.Closest.SafePush(next)
-		search.mu.Unlock()
-		if err != nil {
-			panic(err) // should never happen
+// nextHedge returns the time until the next outstanding query that hasn't been hedged becomes a
+// straggler and whether there is such a query.
+func nextHedge(outstanding map[string]*outstandingQuery, hedgeDelay time.Duration) (
+	time.Duration, bool) {
+	found, earliest := false, time.Time{}
+	for _, q := range outstanding {
+		if !q.hedged && (!found || q.start.Before(earliest)) {
+			found, earliest = true, q.start
 		}
+	}
+	if !found {
+		return 0, false
+	}
+	if wait := hedgeDelay - time.Since(earliest); wait > 0 {
+		return wait, true
+	}
+	return 0, true
+}
 
-		// add next peer to set of peers that responded
+// queryAsync queries the peer and sends the outcome to the search, or just records it with the
+// peer if the search has already ended.
+func (s *searcher) queryAsync(
+	search *Search, next peer.Peer, outcomes chan<- *queryOutcome, done <-chan struct{},
+) {
+	start := time.Now()
+	response, err := s.query(next.Connector(), search)
+	outcome := &queryOutcome{
+		peer:     next,
+		response: response,
+		err:      err,
+		latency:  time.Since(start),
+	}
+	select {
+	case outcomes <- outcome:
+	case <-done:
 		search.mu.Lock()
-		if _, in := search.Result.Responded[nextIDStr]; !in {
-			search.Result.Responded[nextIDStr] = next
-		}
+		recordOutcome(outcome)
 		search.mu.Unlock()
 	}
 }
 
+// recordOutcome records the query outcome with the peer that was queried. Callers must hold the
+// search's lock.
+func recordOutcome(outcome *queryOutcome) {
+	if outcome.err != nil {
+		outcome.peer.Recorder().Record(peer.Response, peer.Error)
+	} else {
+		outcome.peer.Recorder().Record(peer.Response, peer.Success)
+	}
+	outcome.peer.Recorder().RecordLatency(outcome.latency)
+}
+
+// processOutcome updates the search result with the outcome of querying a peer. Callers must hold
+// the search's lock.
+func (s *searcher) processOutcome(search *Search, outcome *queryOutcome, hedge *hedger) {
+	recordOutcome(outcome)
+	nextIDStr := outcome.peer.ID().String()
+	if outcome.err != nil {
+		search.Result.Errored[nextIDStr] = outcome.err
+		if search.Errored() {
+			search.Result.FatalErr = ErrTooManyFindErrors
+		}
+		return
+	}
+	hedge.observe(outcome.latency)
+
+	// process the heap's response
+	if err := s.rp.Process(outcome.response, search.Result); err != nil {
+		search.Result.FatalErr = err
+		return
+	}
+
+	// add to heap of closest responded peers
+	if err := search.Result.Closest.SafePush(outcome.peer); err != nil {
+		panic(err) // should never happen
+	}
+
+	// add next peer to set of peers that responded
+	if _, in := search.Result.Responded[nextIDStr]; !in {
+		search.Result.Responded[nextIDStr] = outcome.peer
+	}
+	if outcome.response.Value == nil {
+		search.Result.Lacking[nextIDStr] = outcome.peer
+	}
+}
+
 func (s *searcher) query(pConn peer.Connector, search *Search) (*api.FindResponse, error) {
 	findClient, err := s.finderCreator.Create(pConn)
 	if err != nil {
@@ -139,7 +407,7 @@ func (s *searcher) query(pConn peer.Connector, search *Search) (*api.FindRespons
 	if err != nil {
 		return nil, err
 	}
-	retryFindClient := client.NewRetryFinder(findClient, searcherFindRetryTimeout)
+	retryFindClient := client.NewRetryFinder(findClient, s.signer, searcherFindRetryTimeout)
 	rp, err := retryFindClient.Find(ctx, search.Request)
 	cancel()
 	if err != nil {
@@ -148,10 +416,31 @@ func (s *searcher) query(pConn peer.Connector, search *Search) (*api.FindRespons
 	if !bytes.Equal(rp.Metadata.RequestId, search.Request.Metadata.RequestId) {
 		return nil, client.ErrUnexpectedRequestID
 	}
+	if rp.Value != nil {
+		if err := s.checkValue(search.Key, rp.Value); err != nil {
+			return nil, err
+		}
+	}
 
 	return rp, nil
 }
 
+// checkValue returns ErrInvalidValue if the value doesn't match the key.
+func (s *searcher) checkValue(key id.ID, value *api.Document) error {
+	valueBytes, err := proto.Marshal(value)
+	if err != nil {
+		return err
+	}
+	kvc := s.kvc
+	if _, ok := value.Contents.(*api.Document_Pointer); ok {
+		kvc = s.pkvc
+	}
+	if err := kvc.Check(key.Bytes(), valueBytes); err != nil {
+		return ErrInvalidValue
+	}
+	return nil
+}
+
 // ResponseProcessor handles an api.FindResponse
 type ResponseProcessor interface {
 	// Process handles an api.FindResponse, adding newly discovered peers to the unqueried
@@ -168,11 +457,19 @@ func NewResponseProcessor(f peer.Fromer) ResponseProcessor {
 	return &responseProcessor{fromer: f}
 }
 
-// Process processes an api.FindResponse, updating the result with the newly found peers.
+// Process processes an api.FindResponse, updating the result with the newly found peers or value.
+// A value is only found once the required number of peers have returned it.
 func (frp *responseProcessor) Process(rp *api.FindResponse, result *Result) error {
 	if rp.Value != nil {
 		// response has value we're searching for
-		result.Value = rp.Value
+		digest, err := valueDigest(rp.Value)
+		if err != nil {
+			return err
+		}
+		result.valueCounts[digest]++
+		if result.valueCounts[digest] >= result.nMatchingValues {
+			result.Value = rp.Value
+		}
 		return nil
 	}
 
@@ -180,6 +477,7 @@ func (frp *responseProcessor) Process(rp *api.FindResponse, result *Result) erro
 		// response has peer addresses close to key
 		for _, pa := range rp.Peers {
 			newID := id.FromBytes(pa.PeerId)
+			result.reported[newID.String()] = struct{}{}
 			if !result.Closest.In(newID) && !result.Unqueried.In(newID) {
 				// only add discovered peers that we haven't already seen
 				newPeer := frp.fromer.FromAPI(pa)
@@ -194,3 +492,15 @@ func (frp *responseProcessor) Process(rp *api.FindResponse, result *Result) erro
 	// invalid response
 	return errors.New("FindResponse contains neither value nor peer addresses")
 }
+
+// valueDigest returns the SHA-256 hash of the marshaled value, on which peers returning values
+// must agree. Unlike a value's search key, which for Pointers is that of their author and name,
+// different versions of a Pointer have different digests.
+func valueDigest(value *api.Document) (string, error) {
+	valueBytes, err := proto.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256(valueBytes)
+	return string(digest[:]), nil
+}
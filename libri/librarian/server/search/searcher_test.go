@@ -1,10 +1,13 @@
 package search
 
 import (
+	"bytes"
 	"container/heap"
 	"errors"
 	"fmt"
+	"math/big"
 	"math/rand"
+	"sync"
 	"testing"
 	"time"
 
@@ -14,6 +17,8 @@ import (
 	"github.com/drausin/libri/libri/librarian/client"
 	"github.com/drausin/libri/libri/librarian/server/peer"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
 )
 
 func TestNewDefaultSearcher(t *testing.T) {
@@ -56,6 +61,7 @@ func TestSearcher_Search_ok(t *testing.T) {
 		assert.Equal(t, 0, len(search.Result.Errored))
 		assert.Equal(t, int(nClosestResponses), search.Result.Closest.Len())
 		assert.True(t, search.Result.Closest.Len() <= len(search.Result.Responded))
+		assert.Equal(t, search.Result.Responded, search.Result.Lacking)
 
 		// build set of closest peers by iteratively looking at all of them
 		expectedClosestsPeers := make(map[string]struct{})
@@ -77,6 +83,232 @@ func TestSearcher_Search_ok(t *testing.T) {
 	}
 }
 
+func TestSearcher_Search_disjoint(t *testing.T) {
+	n, nClosestResponses := 64, uint(4)
+	rng := rand.New(rand.NewSource(int64(n)))
+	peers, peersMap, selfPeerIdxs, selfID := NewTestPeers(rng, n)
+	key := id.NewPseudoRandom(rng)
+	searcherImpl := NewTestSearcher(peersMap)
+	fc := &countingFinderCreator{
+		inner:    searcherImpl.(*searcher).finderCreator,
+		nQueries: make(map[string]int),
+	}
+	searcherImpl.(*searcher).finderCreator = fc
+
+	params := &Parameters{
+		NClosestResponses: nClosestResponses,
+		NMaxErrors:        DefaultNMaxErrors,
+		Concurrency:       1,
+		Timeout:           DefaultQueryTimeout,
+		NMatchingValues:   1,
+		NDisjointPaths:    3,
+		NAgreeingPaths:    2,
+	}
+	search := NewSearch(selfID, key, params)
+	err := searcherImpl.Search(search, NewTestSeeds(peers, selfPeerIdxs))
+
+	assert.Nil(t, err)
+	assert.True(t, search.FoundClosestPeers())
+	assert.False(t, search.FoundValue())
+	assert.Equal(t, int(nClosestResponses), search.Result.Closest.Len())
+	for _, p := range search.Result.Closest.Peers() {
+		_, in := search.Result.Responded[p.ID().String()]
+		assert.True(t, in)
+	}
+
+	// check no peer was queried by more than one path
+	for _, nQueries := range fc.nQueries {
+		assert.Equal(t, 1, nQueries)
+	}
+	assert.Equal(t, len(search.Result.Responded), len(fc.nQueries))
+
+	// check too few agreeing paths errors
+	params.NAgreeingPaths = params.NDisjointPaths + 1
+	search = NewSearch(selfID, key, params)
+	err = searcherImpl.Search(search, NewTestSeeds(peers, selfPeerIdxs))
+	assert.Equal(t, ErrTooFewAgreeingPaths, err)
+	assert.Zero(t, search.Result.Closest.Len())
+}
+
+func TestSearcher_Search_disjointValue(t *testing.T) {
+	n := 32
+	rng := rand.New(rand.NewSource(int64(n)))
+	peers, peersMap, selfPeerIdxs, selfID := NewTestPeers(rng, n)
+	value, key := api.NewTestDocument(rng)
+	searcherImpl := NewTestSearcher(peersMap)
+	searcherImpl.(*searcher).finderCreator = &TestFinderCreator{
+		finder: &fixedFinder{value: value},
+	}
+
+	params := NewDefaultParameters()
+	params.NDisjointPaths, params.NAgreeingPaths = 3, 2
+	search := NewSearch(selfID, key, params)
+	err := searcherImpl.Search(search, NewTestSeeds(peers, selfPeerIdxs))
+
+	assert.Nil(t, err)
+	assert.True(t, search.FoundValue())
+	assert.Equal(t, value, search.Result.Value)
+	assert.Empty(t, search.Result.Lacking)
+}
+
+func TestMergePaths_poisonedPath(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	key := id.NewPseudoRandom(rng)
+	params := NewDefaultParameters()
+	params.NClosestResponses, params.NDisjointPaths, params.NAgreeingPaths = 4, 3, 2
+	nearKey := func(dist int64) peer.Peer {
+		return peer.New(id.FromInt(new(big.Int).Xor(key.Int(), big.NewInt(dist))), "", nil)
+	}
+	newPaths := func() (*Search, []*Search) {
+		search := NewSearch(ecid.NewPseudoRandom(rng), key, params)
+		paths := newPathSearches(search)
+		for i, path := range paths {
+			for j := 0; j < int(params.NClosestResponses); j++ {
+				// honest paths find peers farther than those the poisoned path is led to
+				dist := int64(1<<20 + i*64 + j)
+				if i == 2 {
+					dist = int64(1 + j)
+				}
+				p := nearKey(dist)
+				err := path.Result.Closest.SafePush(p)
+				assert.Nil(t, err)
+				path.Result.Responded[p.ID().String()] = p
+			}
+		}
+		return search, paths
+	}
+
+	// honest paths return each other's closest peers, while the eclipsed path only hears of
+	// the attacker's peers
+	search, paths := newPaths()
+	for _, p := range append(paths[0].Result.Closest.Peers(), paths[1].Result.Closest.Peers()...) {
+		paths[0].Result.reported[p.ID().String()] = struct{}{}
+		paths[1].Result.reported[p.ID().String()] = struct{}{}
+	}
+	for _, p := range paths[2].Result.Closest.Peers() {
+		paths[2].Result.reported[p.ID().String()] = struct{}{}
+	}
+	mergePaths(search, paths)
+	assert.Nil(t, search.Result.FatalErr)
+	assert.Equal(t, int(params.NClosestResponses), search.Result.Closest.Len())
+	for _, p := range search.Result.Closest.Peers() {
+		assert.False(t, paths[2].Result.Closest.In(p.ID()))
+	}
+
+	// no peers agreed on by enough paths fails the search
+	search, paths = newPaths()
+	mergePaths(search, paths)
+	assert.Equal(t, ErrTooFewAgreeingPaths, search.Result.FatalErr)
+	assert.Zero(t, search.Result.Closest.Len())
+}
+
+func TestMergePaths_pointerVersions(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	params := NewDefaultParameters()
+	params.NDisjointPaths, params.NAgreeingPaths = 3, 2
+	version1, version2 := newTestPointerVersions(rng)
+	key := api.GetPointerKey(version1.GetPointer().AuthorPublicKey, version1.GetPointer().Name)
+
+	// check paths returning different versions of a pointer don't agree on it
+	search := NewSearch(ecid.NewPseudoRandom(rng), key, params)
+	paths := newPathSearches(search)
+	paths[0].Result.Value, paths[1].Result.Value = version1, version2
+	mergePaths(search, paths)
+	assert.False(t, search.FoundValue())
+
+	// check paths returning the same version do
+	search = NewSearch(ecid.NewPseudoRandom(rng), key, params)
+	paths = newPathSearches(search)
+	paths[0].Result.Value, paths[1].Result.Value, paths[2].Result.Value =
+		version1, version2, version2
+	mergePaths(search, paths)
+	assert.Equal(t, version2, search.Result.Value)
+}
+
+func TestSearcher_Search_hedge(t *testing.T) {
+	n, nClosestResponses := 32, uint(4)
+	rng := rand.New(rand.NewSource(0))
+	peers, peersMap, selfPeerIdxs, selfID := NewTestPeers(rng, n)
+	key := id.NewPseudoRandom(rng)
+	seeds := NewTestSeeds(peers, selfPeerIdxs)
+
+	// closest seed to the key is very slow to respond but isn't one of the closest peers to it
+	sortedSeeds := newClosestPeers(key, uint(len(seeds)))
+	err := sortedSeeds.SafePushMany(seeds)
+	assert.Nil(t, err)
+	slowPeer, slowLatency := sortedSeeds.PeakPeer(), 2*time.Second
+	closest := newFarthestPeers(key, nClosestResponses)
+	err = closest.SafePushMany(peers)
+	assert.Nil(t, err)
+	assert.False(t, closest.In(slowPeer.ID()))
+	searcherImpl := NewTestSearcher(peersMap)
+	searcherImpl.(*searcher).finderCreator = &slowFinderCreator{
+		inner:    searcherImpl.(*searcher).finderCreator,
+		slowID:   slowPeer.ID(),
+		slowness: slowLatency,
+	}
+
+	params := &Parameters{
+		NClosestResponses: nClosestResponses,
+		NMaxErrors:        DefaultNMaxErrors,
+		Concurrency:       1,
+		Timeout:           DefaultQueryTimeout,
+		HedgePercentile:   DefaultHedgePercentile,
+		MinHedgeDelay:     10 * time.Millisecond,
+	}
+	search := NewSearch(selfID, key, params)
+	start := time.Now()
+	err = searcherImpl.Search(search, seeds)
+
+	// search finishes without waiting for the slow peer
+	assert.Nil(t, err)
+	assert.True(t, time.Since(start) < slowLatency/2)
+	assert.True(t, search.FoundClosestPeers())
+	search.mu.Lock()
+	_, in := search.Result.Responded[slowPeer.ID().String()]
+	search.mu.Unlock()
+	assert.False(t, in)
+}
+
+func TestAwaitingCloser(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	key := id.NewPseudoRandom(rng)
+	search := NewSearch(ecid.NewPseudoRandom(rng), key, NewDefaultParameters())
+	sorted := newClosestPeers(key, 4)
+	for c := 0; c < 4; c++ {
+		err := sorted.SafePush(peer.NewTestPeer(rng, c))
+		assert.Nil(t, err)
+	}
+
+	// closest peers heap pops closest first
+	closer := heap.Pop(sorted).(peer.Peer)
+	for c := 0; c < 2; c++ {
+		err := search.Result.Closest.SafePush(heap.Pop(sorted).(peer.Peer))
+		assert.Nil(t, err)
+	}
+	farther := heap.Pop(sorted).(peer.Peer)
+
+	// nothing outstanding
+	outstanding := make(map[string]*outstandingQuery)
+	assert.False(t, awaitingCloser(search, outstanding))
+
+	// only outstanding query is to a farther peer
+	outstanding[farther.ID().String()] = &outstandingQuery{peer: farther}
+	assert.False(t, awaitingCloser(search, outstanding))
+
+	// outstanding query to a closer peer
+	outstanding[closer.ID().String()] = &outstandingQuery{peer: closer}
+	assert.True(t, awaitingCloser(search, outstanding))
+
+	// closer query is a straggler
+	outstanding[closer.ID().String()].hedged = true
+	assert.True(t, awaitingCloser(search, outstanding))
+
+	// search has found the value
+	search.Result.Value, _ = api.NewTestDocument(rng)
+	assert.False(t, awaitingCloser(search, outstanding))
+}
+
 func TestSearcher_Search_queryErr(t *testing.T) {
 	searcherImpl, search, selfPeerIdxs, peers := newTestSearch()
 	seeds := NewTestSeeds(peers, selfPeerIdxs)
@@ -110,6 +342,29 @@ func (erp *errResponseProcessor) Process(rp *api.FindResponse, result *Result) e
 	return errors.New("some fatal processing error")
 }
 
+func TestSearcher_Search_invalidValue(t *testing.T) {
+	searcherImpl, search, selfPeerIdxs, peers := newTestSearch()
+	seeds := NewTestSeeds(peers, selfPeerIdxs)
+
+	// all peers return a value that doesn't match the key
+	value, _ := api.NewTestDocument(rand.New(rand.NewSource(0)))
+	searcherImpl.(*searcher).finderCreator = &TestFinderCreator{
+		finder: &fixedFinder{value: value},
+	}
+
+	// do the search!
+	err := searcherImpl.Search(search, seeds)
+
+	// checks
+	assert.Equal(t, ErrTooManyFindErrors, err)
+	assert.True(t, search.Errored())
+	assert.False(t, search.FoundValue())
+	for _, err := range search.Result.Errored {
+		assert.Equal(t, ErrInvalidValue, err)
+	}
+	assert.Equal(t, 0, len(search.Result.Responded))
+}
+
 func TestSearcher_Search_rpErr(t *testing.T) {
 	searcherImpl, search, selfPeerIdxs, peers := newTestSearch()
 	seeds := NewTestSeeds(peers, selfPeerIdxs)
@@ -168,6 +423,38 @@ func TestSearcher_query_ok(t *testing.T) {
 	assert.Nil(t, rp.Value)
 }
 
+func TestSearcher_query_value(t *testing.T) {
+	rng := rand.New(rand.NewSource(int64(0)))
+	peerID := ecid.NewPseudoRandom(rng)
+	value, key := api.NewTestDocument(rng)
+	pointer := api.NewTestPointer(rng)
+	pointerValue := &api.Document{Contents: &api.Document_Pointer{Pointer: pointer}}
+	pointerKey := api.GetPointerKey(pointer.AuthorPublicKey, pointer.Name)
+	connClient := &peer.TestConnector{}
+	s := NewSearcher(&client.TestNoOpSigner{}, &TestFinderCreator{}, nil).(*searcher)
+
+	// check value matching key is returned
+	s.finderCreator = &TestFinderCreator{finder: &fixedFinder{value: value}}
+	rp, err := s.query(connClient, NewSearch(peerID, key, &Parameters{}))
+	assert.Nil(t, err)
+	assert.Equal(t, value, rp.Value)
+
+	// check pointer value matching key is returned
+	s.finderCreator = &TestFinderCreator{finder: &fixedFinder{value: pointerValue}}
+	rp, err = s.query(connClient, NewSearch(peerID, pointerKey, &Parameters{}))
+	assert.Nil(t, err)
+	assert.Equal(t, pointerValue, rp.Value)
+
+	// check values not matching key error
+	rp, err = s.query(connClient, NewSearch(peerID, key, &Parameters{}))
+	assert.Equal(t, ErrInvalidValue, err)
+	assert.Nil(t, rp)
+	s.finderCreator = &TestFinderCreator{finder: &fixedFinder{value: value}}
+	rp, err = s.query(connClient, NewSearch(peerID, id.NewPseudoRandom(rng), &Parameters{}))
+	assert.Equal(t, ErrInvalidValue, err)
+	assert.Nil(t, rp)
+}
+
 func TestSearcher_query_err(t *testing.T) {
 	rng := rand.New(rand.NewSource(int64(0)))
 	connClient := &peer.TestConnector{}
@@ -233,6 +520,64 @@ func TestResponseProcessor_Process_Value(t *testing.T) {
 	assert.Equal(t, value, result.Value)
 }
 
+func TestResponseProcessor_Process_matchingValues(t *testing.T) {
+	rng := rand.New(rand.NewSource(int64(0)))
+	params := NewDefaultParameters()
+	params.NMatchingValues = 2
+	rp := NewResponseProcessor(peer.NewFromer())
+	search := NewSearch(ecid.NewPseudoRandom(rng), id.NewPseudoRandom(rng), params)
+	err := search.Result.Unqueried.SafePush(peer.NewTestPeer(rng, 0))
+	assert.Nil(t, err)
+	value1, _ := api.NewTestDocument(rng)
+	value2, _ := api.NewTestDocument(rng)
+
+	// check value isn't found after first peer returns it
+	err = rp.Process(&api.FindResponse{Value: value1}, search.Result)
+	assert.Nil(t, err)
+	assert.False(t, search.FoundValue())
+
+	// check search isn't finished even with closest peers while value is pending
+	for c := uint(0); c < params.NClosestResponses; c++ {
+		err = search.Result.Closest.SafePush(peer.NewTestPeer(rng, int(c)))
+		assert.Nil(t, err)
+	}
+	assert.False(t, search.Finished())
+
+	// check different value doesn't count toward first
+	err = rp.Process(&api.FindResponse{Value: value2}, search.Result)
+	assert.Nil(t, err)
+	assert.False(t, search.FoundValue())
+
+	// check value is found once enough peers have returned it
+	err = rp.Process(&api.FindResponse{Value: value1}, search.Result)
+	assert.Nil(t, err)
+	assert.True(t, search.FoundValue())
+	assert.Equal(t, value1, search.Result.Value)
+	assert.True(t, search.Finished())
+}
+
+func TestResponseProcessor_Process_pointerVersions(t *testing.T) {
+	rng := rand.New(rand.NewSource(int64(0)))
+	params := NewDefaultParameters()
+	params.NMatchingValues = 2
+	rp := NewResponseProcessor(peer.NewFromer())
+	version1, version2 := newTestPointerVersions(rng)
+	key := api.GetPointerKey(version1.GetPointer().AuthorPublicKey, version1.GetPointer().Name)
+	search := NewSearch(ecid.NewPseudoRandom(rng), key, params)
+
+	// check different versions of a pointer don't count toward each other
+	err := rp.Process(&api.FindResponse{Value: version1}, search.Result)
+	assert.Nil(t, err)
+	err = rp.Process(&api.FindResponse{Value: version2}, search.Result)
+	assert.Nil(t, err)
+	assert.False(t, search.FoundValue())
+
+	// check version is found once enough peers have returned it
+	err = rp.Process(&api.FindResponse{Value: version2}, search.Result)
+	assert.Nil(t, err)
+	assert.Equal(t, version2, search.Result.Value)
+}
+
 func TestResponseProcessor_Process_Addresses(t *testing.T) {
 	rng := rand.New(rand.NewSource(int64(0)))
 
@@ -309,3 +654,67 @@ func newPeerAddresses(rng *rand.Rand, n int) []*api.PeerAddress {
 	}
 	return peerAddresses
 }
+
+type countingFinderCreator struct {
+	inner    client.FinderCreator
+	nQueries map[string]int
+	mu       sync.Mutex
+}
+
+func (c *countingFinderCreator) Create(pConn peer.Connector) (api.Finder, error) {
+	c.mu.Lock()
+	c.nQueries[id.FromBytes(pConn.(*peer.TestConnector).APISelf.PeerId).String()]++
+	c.mu.Unlock()
+	return c.inner.Create(pConn)
+}
+
+type slowFinderCreator struct {
+	inner    client.FinderCreator
+	slowID   id.ID
+	slowness time.Duration
+}
+
+func (c *slowFinderCreator) Create(pConn peer.Connector) (api.Finder, error) {
+	f, err := c.inner.Create(pConn)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(pConn.(*peer.TestConnector).APISelf.PeerId, c.slowID.Bytes()) {
+		return f, nil
+	}
+	return &slowFinder{inner: f, slowness: c.slowness}, nil
+}
+
+type slowFinder struct {
+	inner    api.Finder
+	slowness time.Duration
+}
+
+func (f *slowFinder) Find(ctx context.Context, rq *api.FindRequest, opts ...grpc.CallOption) (
+	*api.FindResponse, error) {
+	select {
+	case <-time.After(f.slowness):
+		return f.inner.Find(ctx, rq, opts...)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// newTestPointerVersions returns two versions of the same pointer, with different targets.
+func newTestPointerVersions(rng *rand.Rand) (*api.Document, *api.Document) {
+	authorKey := ecid.NewPseudoRandom(rng)
+	versions := make([]*api.Document, 2)
+	for i := range versions {
+		pointer := &api.Pointer{
+			AuthorPublicKey: authorKey.PublicKeyBytes(),
+			Name:            "some pointer name",
+			TargetKey:       api.RandBytes(rng, api.DocumentKeyLength),
+			Sequence:        uint64(i + 1),
+		}
+		if err := api.SignPointer(pointer, authorKey.Key()); err != nil {
+			panic(err)
+		}
+		versions[i] = &api.Document{Contents: &api.Document_Pointer{Pointer: pointer}}
+	}
+	return versions[0], versions[1]
+}
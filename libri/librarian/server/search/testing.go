@@ -35,6 +35,7 @@ func (c *TestFinderCreator) Create(pConn peer.Connector) (api.Finder, error) {
 
 type fixedFinder struct {
 	addresses []*api.PeerAddress
+	value     *api.Document
 	requestID []byte
 	err       error
 }
@@ -51,6 +52,7 @@ func (f *fixedFinder) Find(ctx context.Context, rq *api.FindRequest, opts ...grp
 	return &api.FindResponse{
 		Metadata: &api.ResponseMetadata{RequestId: requestID},
 		Peers:    f.addresses,
+		Value:    f.value,
 	}, nil
 }
 
@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"math/rand"
+	"sync"
 
 	"net/http"
 
@@ -30,7 +31,7 @@ import (
 // Librarian is the main service of a single peer in the peer to peer network.
 type Librarian struct {
 	// SelfID is the random 256-bit identification number of this node in the hash table
-	selfID ecid.ID
+	selfID ecid.Identity
 
 	// Config holds the configuration parameters of the server
 	config *Config
@@ -47,6 +48,12 @@ type Librarian struct {
 	// executes stores for key/value
 	storer store.Storer
 
+	// creates api.Storers for sending cache copies along lookup paths and read repairs
+	storerCreator client.StorerCreator
+
+	// limits the rate of read repairs
+	repairs *repairThrottle
+
 	// manages subscriptions from other peers
 	subscribeFrom subscribe.From
 
@@ -59,6 +66,12 @@ type Librarian struct {
 	// verifies requests from peers
 	rqv RequestVerifier
 
+	// limits request rates from peers and bytes stored for authors
+	limiter Limiter
+
+	// scores peers by their misbehavior and blacklists the worst
+	reps peer.Reputations
+
 	// key-value store DB used for all external storage
 	db db.KVDB
 
@@ -68,12 +81,19 @@ type Librarian struct {
 	// SL for p2p stored documents
 	documentSL storage.DocumentSL
 
+	// cache copies of documents sent by peers along their lookup paths, kept separate from
+	// documentSL
+	documentCache storage.DocumentCache
+
 	// ensures keys are valid
 	kc storage.Checker
 
 	// ensures keys and values are valid
 	kvc storage.KeyValueChecker
 
+	// ensures Pointer keys and values are valid
+	pkvc storage.KeyValueChecker
+
 	// creates new peers
 	fromer peer.Fromer
 
@@ -83,6 +103,9 @@ type Librarian struct {
 	// routing table of peers
 	rt routing.Table
 
+	// evicts unresponsive peers from the routing table
+	prober routing.Prober
+
 	// logger for this instance
 	logger *zap.Logger
 
@@ -94,6 +117,15 @@ type Librarian struct {
 
 	// receives graceful stop signal
 	stop chan struct{}
+
+	// tracks the routing table checkpointing goroutine so Close can wait for it to finish
+	checkpointing sync.WaitGroup
+
+	// tracks the cache sweeping goroutine so Close can wait for it to finish
+	sweeping sync.WaitGroup
+
+	// tracks the bucket refreshing goroutine so Close can wait for it to finish
+	refreshing sync.WaitGroup
 }
 
 const (
@@ -108,21 +140,36 @@ func NewLibrarian(config *Config, logger *zap.Logger) (*Librarian, error) {
 		return nil, err
 	}
 	serverSL := storage.NewServerSL(rdb)
-	documentSL := storage.NewDocumentSLD(rdb)
+	documentSL, err := NewHotCache(storage.NewDocumentSLD(rdb), config.HotCache)
+	if err != nil {
+		return nil, err
+	}
+	documentCache, err := storage.LoadDocumentCache(rdb, config.PathCache.MaxBytes,
+		config.PathCache.MaxDocuments)
+	if err != nil {
+		logger.Error("error loading document cache", zap.Error(err))
+		return nil, err
+	}
 
 	// get peer ID and immediately save it so subsequent restarts have it
-	peerID, err := loadOrCreatePeerID(logger, serverSL)
+	peerID, err := loadOrCreatePeerID(logger, serverSL, config.KeyType,
+		config.Routing.IDDifficulty)
 	if err != nil {
 		return nil, err
 	}
 	selfLogger := logger.With(zap.String(logSelfIDShort, id.ShortHex(peerID.Bytes())))
 
-	rt, err := loadOrCreateRoutingTable(selfLogger, serverSL, peerID, config.Routing)
+	reps, err := peer.LoadReputations(serverSL, config.Reputation)
+	if err != nil {
+		logger.Error("error loading peer blacklist", zap.Error(err))
+		return nil, err
+	}
+	rt, err := loadOrCreateRoutingTable(selfLogger, serverSL, peerID, config.Routing, reps)
 	if err != nil {
 		return nil, err
 	}
 
-	signer := client.NewSigner(peerID.Key())
+	signer := client.NewFormatSigner(peerID.Signer(), config.SignatureFormat)
 	searcher := search.NewDefaultSearcher(signer)
 	newPubs := make(chan *subscribe.KeyedPub, newPublicationsSlack)
 
@@ -130,7 +177,7 @@ func NewLibrarian(config *Config, logger *zap.Logger) (*Librarian, error) {
 	if err != nil {
 		return nil, err
 	}
-	clientBalancer := routing.NewClientBalancer(rt)
+	clientBalancer := routing.NewClientBalancer(rt, reps)
 	subscribeTo := subscribe.NewTo(config.SubscribeTo, selfLogger, peerID, clientBalancer, signer,
 		recentPubs, newPubs)
 
@@ -139,24 +186,32 @@ func NewLibrarian(config *Config, logger *zap.Logger) (*Librarian, error) {
 	metrics := &http.Server{Addr: config.LocalMetricsAddr.String(), Handler: metricsSM}
 
 	return &Librarian{
-		selfID:        peerID,
-		config:        config,
-		apiSelf:       peer.FromAddress(peerID.ID(), config.PublicName, config.PublicAddr),
-		introducer:    introduce.NewDefaultIntroducer(signer, peerID.ID()),
+		selfID:  peerID,
+		config:  config,
+		apiSelf: peer.FromAddress(peerID.ID(), config.PublicName, config.PublicAddr),
+		introducer: introduce.NewDefaultIntroducer(signer, peerID.ID(),
+			config.Routing.IDDifficulty),
 		searcher:      searcher,
 		storer:        store.NewStorer(signer, searcher, client.NewStorerCreator()),
+		storerCreator: client.NewStorerCreator(),
+		repairs:       newRepairThrottle(config.ReadRepair),
 		subscribeFrom: subscribe.NewFrom(config.SubscribeFrom, logger, newPubs),
 		subscribeTo:   subscribeTo,
 		RecentPubs:    recentPubs,
-		rqv:           NewRequestVerifier(),
+		rqv:           NewRequestVerifier(config.Verify, selfLogger),
+		limiter:       NewLimiter(config.Limits),
+		reps:          reps,
 		db:            rdb,
 		serverSL:      serverSL,
 		documentSL:    documentSL,
+		documentCache: documentCache,
 		kc:            storage.NewExactLengthChecker(storage.EntriesKeyLength),
 		kvc:           storage.NewHashKeyValueChecker(),
+		pkvc:          storage.NewPointerKeyValueChecker(),
 		fromer:        peer.NewFromer(),
 		signer:        signer,
 		rt:            rt,
+		prober:        routing.NewProber(rt, client.NewPingerCreator(), config.Routing),
 		logger:        selfLogger,
 		health:        health.NewServer(),
 		metrics:       metrics,
@@ -166,6 +221,7 @@ func NewLibrarian(config *Config, logger *zap.Logger) (*Librarian, error) {
 
 var (
 	errBadPeerIDSig           = errors.New("stated client peer ID does not match signature")
+	errUnsolvedIDPuzzle       = errors.New("peer ID does not solve required ID puzzle")
 	errSearchErr              = errors.New("error encountered during search")
 	errSearchExhausted        = errors.New("search exhausted closest peers")
 	errSearchUnexpectedResult = errors.New("unexpected search result")
@@ -190,10 +246,16 @@ func (l *Librarian) Introduce(ctx context.Context, rq *api.IntroduceRequest) (
 	if err != nil {
 		return nil, logAndReturnErr(logger, "error checking request", err)
 	}
+	if err := l.limiter.AllowRequest(requesterID, IntroduceEndpoint); err != nil {
+		return nil, logAndReturnErr(logger, "rate limit exceeded", err)
+	}
 	requester := l.fromer.FromAPI(rq.Self)
 	if requester.ID().Cmp(requesterID) != 0 {
 		return nil, logAndReturnErr(logger, "error matching peer ID to signature", errBadPeerIDSig)
 	}
+	if !ecid.SolvesIDPuzzle(requesterID, l.config.Routing.IDDifficulty) {
+		return nil, logAndReturnErr(logger, "peer ID too easy", errUnsolvedIDPuzzle)
+	}
 	l.record(requesterID, peer.Request, peer.Success)
 
 	// add peer to routing table (if space)
@@ -204,9 +266,10 @@ func (l *Librarian) Introduce(ctx context.Context, rq *api.IntroduceRequest) (
 	peers := l.rt.Sample(uint(rq.NumPeers), rand.New(rand.NewSource(seed)))
 
 	rp := &api.IntroduceResponse{
-		Metadata: l.NewResponseMetadata(rq.Metadata),
-		Self:     l.apiSelf,
-		Peers:    peer.ToAPIs(peers),
+		Metadata:     l.NewResponseMetadata(rq.Metadata),
+		Self:         l.apiSelf,
+		Peers:        peer.ToAPIs(peers),
+		IdDifficulty: uint32(l.config.Routing.IDDifficulty),
 	}
 	logger.Info("introduced", introduceResponseFields(rp)...)
 	return rp, nil
@@ -221,6 +284,9 @@ func (l *Librarian) Find(ctx context.Context, rq *api.FindRequest) (*api.FindRes
 	if err != nil {
 		return nil, logAndReturnErr(logger, "check request error", err)
 	}
+	if err := l.limiter.AllowRequest(requesterID, FindEndpoint); err != nil {
+		return nil, logAndReturnErr(logger, "rate limit exceeded", err)
+	}
 	l.record(requesterID, peer.Request, peer.Success)
 
 	value, err := l.documentSL.Load(id.FromBytes(rq.Key))
@@ -228,6 +294,13 @@ func (l *Librarian) Find(ctx context.Context, rq *api.FindRequest) (*api.FindRes
 		// something went wrong during load
 		return nil, logAndReturnErr(logger, "error loading document", err)
 	}
+	if value == nil {
+		// fall back to any cache copy we have
+		value, err = l.documentCache.Load(id.FromBytes(rq.Key))
+		if err != nil {
+			return nil, logAndReturnErr(logger, "error loading cached document", err)
+		}
+	}
 
 	// we have the value, so return it
 	if value != nil {
@@ -250,7 +323,8 @@ func (l *Librarian) Find(ctx context.Context, rq *api.FindRequest) (*api.FindRes
 	return rp, nil
 }
 
-// Store stores the value.
+// Store stores the value, or keeps a cache copy of it for a while if the request is marked as a
+// cache copy.
 func (l *Librarian) Store(ctx context.Context, rq *api.StoreRequest) (
 	*api.StoreResponse, error) {
 	logger := l.logger.With(rqMetadataFields(rq.Metadata)...)
@@ -260,8 +334,26 @@ func (l *Librarian) Store(ctx context.Context, rq *api.StoreRequest) (
 	if err != nil {
 		return nil, logAndReturnErr(logger, "error checking request", err)
 	}
+	if rq.Cache {
+		if err := l.checkCacheable(rq.Value); err != nil {
+			return nil, logAndReturnErr(logger, "error checking cache copy", err)
+		}
+	}
+	if err := l.checkStoreLimits(requesterID, rq.Value); err != nil {
+		return nil, logAndReturnErr(logger, "limit exceeded", err)
+	}
 	l.record(requesterID, peer.Request, peer.Success)
 
+	if rq.Cache {
+		if err := l.cacheDocument(id.FromBytes(rq.Key), rq.Value); err != nil {
+			return nil, logAndReturnErr(logger, "error caching document", err)
+		}
+		rp := &api.StoreResponse{
+			Metadata: l.NewResponseMetadata(rq.Metadata),
+		}
+		l.logger.Debug("cached", storeResponseFields(rq, rp)...)
+		return rp, nil
+	}
 	if err := l.documentSL.Store(id.FromBytes(rq.Key), rq.Value); err != nil {
 		return nil, logAndReturnErr(logger, "error storing document", err)
 	}
@@ -287,12 +379,17 @@ func (l *Librarian) Get(ctx context.Context, rq *api.GetRequest) (*api.GetRespon
 		logger.Error("error checking request", zap.Error(err))
 		return nil, err
 	}
+	if err := l.limiter.AllowRequest(requesterID, GetEndpoint); err != nil {
+		return nil, logAndReturnErr(logger, "rate limit exceeded", err)
+	}
 	l.record(requesterID, peer.Request, peer.Success)
 
 	key := id.FromBytes(rq.Key)
-	s := search.NewSearch(l.selfID, key, l.config.Search)
+	s := search.NewSearch(l.selfID, key, l.getSearchParameters(rq))
 	seeds := l.rt.Peak(key, s.Params.NClosestResponses)
-	if err = l.searcher.Search(s, seeds); err != nil {
+	err = l.searcher.Search(s, seeds)
+	l.penalizeSearchErrors(s.Result.Errored)
+	if err != nil {
 		return nil, logAndReturnErr(logger, "error searching", err)
 	}
 
@@ -307,6 +404,12 @@ func (l *Librarian) Get(ctx context.Context, rq *api.GetRequest) (*api.GetRespon
 			Value:    s.Result.Value,
 		}
 		logger.Info("got value", getResponseFields(rq, rp)...)
+		if l.config.ReadRepair.Enabled {
+			l.readRepairAsync(s, logger)
+		}
+		if l.config.PathCache.Enabled {
+			l.cacheAlongPathAsync(s, logger)
+		}
 		return rp, nil
 	}
 	if s.FoundClosestPeers() {
@@ -336,6 +439,9 @@ func (l *Librarian) Put(ctx context.Context, rq *api.PutRequest) (*api.PutRespon
 	if err != nil {
 		return nil, logAndReturnErr(logger, "error checking request", err)
 	}
+	if err := l.checkLimits(requesterID, PutEndpoint, rq.Value); err != nil {
+		return nil, logAndReturnErr(logger, "limit exceeded", err)
+	}
 	l.record(requesterID, peer.Request, peer.Success)
 
 	key := id.FromBytes(rq.Key)
@@ -344,10 +450,12 @@ func (l *Librarian) Put(ctx context.Context, rq *api.PutRequest) (*api.PutRespon
 		key,
 		rq.Value,
 		l.config.Search,
-		l.config.Store,
+		l.putStoreParameters(rq),
 	)
 	seeds := l.rt.Peak(key, s.Search.Params.NClosestResponses)
-	if err = l.storer.Store(s, seeds); err != nil {
+	err = l.storer.Store(s, seeds)
+	l.penalizeSearchErrors(s.Search.Result.Errored)
+	if err != nil {
 		return nil, logFieldsAndReturnErr(logger, errStoreErr, storeDetailFields(s))
 	}
 	for _, p := range s.Result.Responded {
@@ -385,10 +493,14 @@ func (l *Librarian) Put(ctx context.Context, rq *api.PutRequest) (*api.PutRespon
 func (l *Librarian) Subscribe(rq *api.SubscribeRequest, from api.Librarian_SubscribeServer) error {
 	logger := l.logger.With(rqMetadataFields(rq.Metadata)...)
 	logger.Debug("received subscribe request")
-	if _, err := l.checkRequest(from.Context(), rq, rq.Metadata); err != nil {
+	requesterID, err := l.checkRequest(from.Context(), rq, rq.Metadata)
+	if err != nil {
 		logger.Error("error checking request", zap.Error(err))
 		return err
 	}
+	if err := l.limiter.AllowRequest(requesterID, SubscribeEndpoint); err != nil {
+		return logAndReturnErr(logger, "rate limit exceeded", err)
+	}
 	authorFilter, err := subscribe.FromAPI(rq.Subscription.AuthorPublicKeys)
 	if err != nil {
 		return logAndReturnErr(logger, "error decoding author filter", err)
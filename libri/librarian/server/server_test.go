@@ -8,6 +8,7 @@ import (
 	"math/rand"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/drausin/libri/libri/common/db"
 	"github.com/drausin/libri/libri/common/ecid"
@@ -24,6 +25,8 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 )
 
@@ -92,12 +95,15 @@ func TestLibrarian_Introduce_ok(t *testing.T) {
 		config: &Config{
 			PublicName: peerName,
 			LocalAddr:  publicAddr,
+			Routing:    routing.NewDefaultParameters(),
 		},
 		apiSelf: peer.FromAddress(serverID.ID(), peerName, publicAddr),
 		fromer:  peer.NewFromer(),
 		selfID:  serverID,
 		rt:      rt,
 		rqv:     &alwaysRequestVerifier{},
+		limiter: NewLimiter(NewDefaultLimitParameters()),
+		reps:    peer.NewDefaultReputations(),
 		logger:  clogging.NewDevInfoLogger(),
 	}
 
@@ -125,6 +131,7 @@ func TestLibrarian_Introduce_ok(t *testing.T) {
 	assert.Equal(t, serverID.ID().Bytes(), rp.Self.PeerId)
 	assert.Equal(t, peerName, rp.Self.PeerName)
 	assert.Equal(t, int(numPeers), len(rp.Peers))
+	assert.Equal(t, uint32(routing.DefaultIDDifficulty), rp.IdDifficulty)
 }
 
 func TestLibrarian_Introduce_checkRequestErr(t *testing.T) {
@@ -147,10 +154,12 @@ func TestLibrarian_Introduce_peerIDErr(t *testing.T) {
 	rt, _, _ := routing.NewTestWithPeers(rng, 0)
 
 	lib := &Librarian{
-		fromer: peer.NewFromer(),
-		rt:     rt,
-		rqv:    &alwaysRequestVerifier{},
-		logger: clogging.NewDevInfoLogger(),
+		fromer:  peer.NewFromer(),
+		rt:      rt,
+		rqv:     &alwaysRequestVerifier{},
+		limiter: NewLimiter(NewDefaultLimitParameters()),
+		reps:    peer.NewDefaultReputations(),
+		logger:  clogging.NewDevInfoLogger(),
 	}
 
 	clientID, clientPeerIdx := ecid.NewPseudoRandom(rng), 1
@@ -174,6 +183,38 @@ func TestLibrarian_Introduce_peerIDErr(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestLibrarian_Introduce_idPuzzleErr(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	rt, _, _ := routing.NewTestWithPeers(rng, 0)
+	routingParams := routing.NewDefaultParameters()
+	routingParams.IDDifficulty = 1
+
+	lib := &Librarian{
+		config:  &Config{Routing: routingParams},
+		fromer:  peer.NewFromer(),
+		rt:      rt,
+		rqv:     &alwaysRequestVerifier{},
+		limiter: NewLimiter(NewDefaultLimitParameters()),
+		reps:    peer.NewDefaultReputations(),
+		logger:  clogging.NewDevInfoLogger(),
+	}
+
+	clientID := ecid.NewPseudoRandom(rng)
+	for ecid.SolvesIDPuzzle(clientID.ID(), routingParams.IDDifficulty) {
+		clientID = ecid.NewPseudoRandom(rng)
+	}
+	client1 := peer.New(clientID.ID(), "client", peer.NewTestConnector(1))
+	rq := &api.IntroduceRequest{
+		Metadata: newTestRequestMetadata(rng, clientID),
+		Self:     client1.ToAPI(),
+	}
+	rp, err := lib.Introduce(nil, rq)
+
+	assert.Nil(t, rp)
+	assert.Equal(t, errUnsolvedIDPuzzle, err)
+	assert.Equal(t, 0, lib.rt.NumPeers())
+}
+
 func TestLibrarian_Find(t *testing.T) {
 	kvdb, cleanup, err := db.NewTempDirRocksDB()
 	defer cleanup()
@@ -188,12 +229,15 @@ func TestLibrarian_Find(t *testing.T) {
 			rng := rand.New(rand.NewSource(int64(s)))
 			rt, peerID, nAdded := routing.NewTestWithPeers(rng, n)
 			l := &Librarian{
-				selfID:     peerID,
-				documentSL: storage.NewDocumentSLD(kvdb),
-				kc:         storage.NewExactLengthChecker(storage.EntriesKeyLength),
-				rt:         rt,
-				rqv:        &alwaysRequestVerifier{},
-				logger:     clogging.NewDevInfoLogger(),
+				selfID:        peerID,
+				documentSL:    storage.NewDocumentSLD(kvdb),
+				documentCache: newTestDocumentCache(t, kvdb),
+				kc:            storage.NewExactLengthChecker(storage.EntriesKeyLength),
+				rt:            rt,
+				rqv:           &alwaysRequestVerifier{},
+				limiter:       NewLimiter(NewDefaultLimitParameters()),
+				reps:          peer.NewDefaultReputations(),
+				logger:        clogging.NewDevInfoLogger(),
 			}
 
 			numClosest := uint32(routing.DefaultMaxActivePeers)
@@ -248,6 +292,8 @@ func TestLibrarian_Find_present(t *testing.T) {
 		rt:         rt,
 		kc:         storage.NewExactLengthChecker(storage.EntriesKeyLength),
 		rqv:        &alwaysRequestVerifier{},
+		limiter:    NewLimiter(NewDefaultLimitParameters()),
+		reps:       peer.NewDefaultReputations(),
 		logger:     clogging.NewDevInfoLogger(),
 	}
 
@@ -274,6 +320,44 @@ func TestLibrarian_Find_present(t *testing.T) {
 	assert.Equal(t, rq.Metadata.RequestId, rp.Metadata.RequestId)
 }
 
+func TestLibrarian_Find_cached(t *testing.T) {
+	rng := rand.New(rand.NewSource(int64(0)))
+	rt, peerID, _ := routing.NewTestWithPeers(rng, 64)
+	kvdb, cleanup, err := db.NewTempDirRocksDB()
+	defer cleanup()
+	defer kvdb.Close()
+	assert.Nil(t, err)
+
+	l := &Librarian{
+		selfID:        peerID,
+		documentSL:    storage.NewDocumentSLD(kvdb),
+		documentCache: newTestDocumentCache(t, kvdb),
+		rt:            rt,
+		kc:            storage.NewExactLengthChecker(storage.EntriesKeyLength),
+		rqv:           &alwaysRequestVerifier{},
+		limiter:       NewLimiter(NewDefaultLimitParameters()),
+		reps:          peer.NewDefaultReputations(),
+		logger:        clogging.NewDevInfoLogger(),
+	}
+
+	// create key-value and cache
+	value, key := api.NewTestDocument(rng)
+	err = l.documentCache.Cache(key, value, time.Now().Add(time.Hour))
+	assert.Nil(t, err)
+
+	rq := &api.FindRequest{
+		Metadata: newTestRequestMetadata(rng, l.selfID),
+		Key:      key.Bytes(),
+		NumPeers: uint32(routing.DefaultMaxActivePeers),
+	}
+	rp, err := l.Find(nil, rq)
+	assert.Nil(t, err)
+
+	// we should get back the cached value
+	assert.Equal(t, value, rp.Value)
+	assert.Nil(t, rp.Peers)
+}
+
 func TestLibrarian_Find_missing(t *testing.T) {
 	rng := rand.New(rand.NewSource(int64(0)))
 	rt, peerID, nAdded := routing.NewTestWithPeers(rng, 64)
@@ -283,14 +367,17 @@ func TestLibrarian_Find_missing(t *testing.T) {
 	assert.Nil(t, err)
 
 	l := &Librarian{
-		selfID:     peerID,
-		rt:         rt,
-		db:         kvdb,
-		serverSL:   storage.NewServerSL(kvdb),
-		documentSL: storage.NewDocumentSLD(kvdb),
-		kc:         storage.NewExactLengthChecker(storage.EntriesKeyLength),
-		rqv:        &alwaysRequestVerifier{},
-		logger:     clogging.NewDevInfoLogger(),
+		selfID:        peerID,
+		rt:            rt,
+		db:            kvdb,
+		serverSL:      storage.NewServerSL(kvdb),
+		documentSL:    storage.NewDocumentSLD(kvdb),
+		documentCache: newTestDocumentCache(t, kvdb),
+		kc:            storage.NewExactLengthChecker(storage.EntriesKeyLength),
+		rqv:           &alwaysRequestVerifier{},
+		limiter:       NewLimiter(NewDefaultLimitParameters()),
+		reps:          peer.NewDefaultReputations(),
+		logger:        clogging.NewDevInfoLogger(),
 	}
 
 	// make request
@@ -321,6 +408,21 @@ func TestLibrarian_Find_checkRequestError(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestLibrarian_Find_limitError(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	l := &Librarian{
+		kc:      storage.NewExactLengthChecker(storage.EntriesKeyLength),
+		rqv:     &alwaysRequestVerifier{},
+		limiter: &fixedLimiter{requestErr: ErrRateLimited},
+		reps:    peer.NewDefaultReputations(),
+		logger:  clogging.NewDevInfoLogger(),
+	}
+	rq := client.NewFindRequest(ecid.NewPseudoRandom(rng), id.NewPseudoRandom(rng), 8)
+	rp, err := l.Find(nil, rq)
+	assert.Nil(t, rp)
+	assert.Equal(t, ErrRateLimited, err)
+}
+
 func TestLibrarian_Store_ok(t *testing.T) {
 	rng := rand.New(rand.NewSource(int64(0)))
 	rt, peerID, _ := routing.NewTestWithPeers(rng, 64)
@@ -339,6 +441,8 @@ func TestLibrarian_Store_ok(t *testing.T) {
 		kc:          storage.NewExactLengthChecker(storage.EntriesKeyLength),
 		kvc:         storage.NewHashKeyValueChecker(),
 		rqv:         &alwaysRequestVerifier{},
+		limiter:     NewLimiter(NewDefaultLimitParameters()),
+		reps:        peer.NewDefaultReputations(),
 		logger:      clogging.NewDevInfoLogger(),
 	}
 
@@ -361,10 +465,54 @@ func TestLibrarian_Store_ok(t *testing.T) {
 	assert.Equal(t, rq.Metadata.RequestId, rp.Metadata.RequestId)
 }
 
-func newTestRequestMetadata(rng *rand.Rand, peerID ecid.ID) *api.RequestMetadata {
+func TestLibrarian_Store_cache(t *testing.T) {
+	rng := rand.New(rand.NewSource(int64(0)))
+	kvdb, cleanup, err := db.NewTempDirRocksDB()
+	defer cleanup()
+	defer kvdb.Close()
+	assert.Nil(t, err)
+
+	value, key := api.NewTestDocument(rng)
+	l := newPathCacheLibrarian(t, rng, kvdb, key)
+	l.subscribeTo = &fixedTo{sendErr: errors.New("should not send publication")}
+	l.kc = storage.NewExactLengthChecker(storage.EntriesKeyLength)
+	l.kvc = storage.NewHashKeyValueChecker()
+	l.rqv = &alwaysRequestVerifier{}
+	l.limiter = NewLimiter(NewDefaultLimitParameters())
+	l.reps = peer.NewDefaultReputations()
+
+	rq := client.NewStoreRequest(ecid.NewPseudoRandom(rng), key, value)
+	rq.Cache = true
+	rp, err := l.Store(nil, rq)
+	assert.Nil(t, err)
+	assert.Equal(t, rq.Metadata.RequestId, rp.Metadata.RequestId)
+
+	// value is cached but not stored as a primary replica
+	cached, err := l.documentCache.Load(key)
+	assert.Nil(t, err)
+	assert.Equal(t, value, cached)
+	stored, err := l.documentSL.Load(key)
+	assert.Nil(t, err)
+	assert.Nil(t, stored)
+
+	// cache copies larger than the cache capacity are rejected
+	l.config.PathCache.MaxBytes = 16
+	rp, err = l.Store(nil, rq)
+	assert.Nil(t, rp)
+	assert.Equal(t, errCacheCopyTooLarge, err)
+
+	// cache copies are rejected when path caching is disabled
+	l.config.PathCache.Enabled = false
+	rp, err = l.Store(nil, rq)
+	assert.Nil(t, rp)
+	assert.Equal(t, errPathCacheDisabled, err)
+}
+
+func newTestRequestMetadata(rng *rand.Rand, peerID ecid.Identity) *api.RequestMetadata {
 	return &api.RequestMetadata{
 		RequestId: id.NewPseudoRandom(rng).Bytes(),
 		PubKey:    peerID.PublicKeyBytes(),
+		KeyType:   api.KeyType(peerID.KeyType()),
 	}
 }
 
@@ -392,6 +540,51 @@ func (*errDocStorerLoader) Load(key id.ID) (*api.Document, error) {
 	return nil, errors.New("some load error")
 }
 
+func TestLibrarian_Store_limitError(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	value, key := api.NewTestDocument(rng)
+	limiters := []Limiter{
+		&fixedLimiter{requestErr: ErrRateLimited},
+		&fixedLimiter{bytesErr: ErrQuotaExceeded},
+	}
+	for _, limiter := range limiters {
+		rt, _, _ := routing.NewTestWithPeers(rng, 0)
+		l := &Librarian{
+			rt:      rt,
+			kc:      storage.NewExactLengthChecker(storage.EntriesKeyLength),
+			kvc:     storage.NewHashKeyValueChecker(),
+			rqv:     &alwaysRequestVerifier{},
+			limiter: limiter,
+			reps:    peer.NewDefaultReputations(),
+			logger:  clogging.NewDevInfoLogger(),
+		}
+		rq := client.NewStoreRequest(ecid.NewPseudoRandom(rng), key, value)
+		rp, err := l.Store(nil, rq)
+		assert.Nil(t, rp)
+		assert.Equal(t, codes.ResourceExhausted, grpc.Code(err))
+	}
+}
+
+func TestLibrarian_checkStoreLimits(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	value, _ := api.NewTestDocument(rng)
+	rt, _, _ := routing.NewTestWithPeers(rng, 0)
+	limiter := &fixedLimiter{}
+	l := &Librarian{rt: rt, limiter: limiter}
+	librarianID, otherID := id.NewPseudoRandom(rng), id.NewPseudoRandom(rng)
+	rt.Push(peer.New(librarianID, "", peer.NewTestConnector(0)))
+
+	// check Stores from librarians in routing table have own budget but are charged author quota
+	assert.Nil(t, l.checkStoreLimits(librarianID, value))
+	assert.Equal(t, []Endpoint{LibrarianStoreEndpoint}, limiter.endpoints)
+	assert.Equal(t, proto.Size(value), limiter.nBytes)
+
+	// check Stores from others are charged as usual
+	assert.Nil(t, l.checkStoreLimits(otherID, value))
+	assert.Equal(t, []Endpoint{LibrarianStoreEndpoint, StoreEndpoint}, limiter.endpoints)
+	assert.Equal(t, 2*proto.Size(value), limiter.nBytes)
+}
+
 func TestLibrarian_Store_storeError(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	rt, peerID, _ := routing.NewTestWithPeers(rng, 64)
@@ -401,6 +594,8 @@ func TestLibrarian_Store_storeError(t *testing.T) {
 		kc:         storage.NewExactLengthChecker(storage.EntriesKeyLength),
 		kvc:        storage.NewHashKeyValueChecker(),
 		rqv:        &alwaysRequestVerifier{},
+		limiter:    NewLimiter(NewDefaultLimitParameters()),
+		reps:       peer.NewDefaultReputations(),
 		documentSL: &errDocStorerLoader{},
 		logger:     clogging.NewDevInfoLogger(),
 	}
@@ -446,6 +641,76 @@ func TestLibrarian_Get_FoundValue(t *testing.T) {
 	assert.Equal(t, rq.Metadata.RequestId, rp.Metadata.RequestId)
 }
 
+func TestLibrarian_Get_pathCache(t *testing.T) {
+	rng := rand.New(rand.NewSource(int64(0)))
+	value, key := api.NewTestDocument(rng)
+	peerID := ecid.NewPseudoRandom(rng)
+
+	// create mock search result where the value has been found but a peer lacked it
+	foundValueResult := search.NewInitialResult(key, search.NewDefaultParameters())
+	foundValueResult.Value = value
+	lacking := peer.NewTestPeer(rng, 0)
+	foundValueResult.Lacking[lacking.ID().String()] = lacking
+
+	l := newGetLibrarian(rng, foundValueResult, nil)
+	l.config.PathCache.Enabled = true
+	l.signer = client.NewSigner(l.selfID.Signer())
+	apiStorer := &fixedAPIStorer{requests: make(chan *api.StoreRequest, 1)}
+	l.storerCreator = &fixedStorerCreator{storer: apiStorer}
+	rq := client.NewGetRequest(peerID, key)
+
+	rp, err := l.Get(nil, rq)
+	assert.Nil(t, err)
+	assert.Equal(t, value, rp.Value)
+
+	// cache copy is sent to the lacking peer
+	select {
+	case storeRq := <-apiStorer.requests:
+		assert.True(t, storeRq.Cache)
+		assert.Equal(t, key.Bytes(), storeRq.Key)
+	case <-time.After(time.Second):
+		assert.Fail(t, "cache copy not sent")
+	}
+}
+
+func TestLibrarian_Get_readRepair(t *testing.T) {
+	rng := rand.New(rand.NewSource(int64(0)))
+	value, key := api.NewTestDocument(rng)
+	peerID := ecid.NewPseudoRandom(rng)
+
+	// create mock search result where the value has been found but a closest peer lacked it
+	foundValueResult := search.NewInitialResult(key, search.NewDefaultParameters())
+	foundValueResult.Value = value
+	lacking := peer.New(flipBit(key, 10), "", peer.NewTestConnector(0))
+	holder := peer.New(flipBit(key, 20), "", peer.NewTestConnector(1))
+	foundValueResult.Lacking[lacking.ID().String()] = lacking
+	foundValueResult.Responded[lacking.ID().String()] = lacking
+	foundValueResult.Responded[holder.ID().String()] = holder
+	err := foundValueResult.Closest.SafePush(lacking)
+	assert.Nil(t, err)
+
+	l := newGetLibrarian(rng, foundValueResult, nil)
+	l.config.ReadRepair.Enabled = true
+	l.signer = client.NewSigner(l.selfID.Signer())
+	apiStorer := &fixedAPIStorer{requests: make(chan *api.StoreRequest, 1)}
+	l.storerCreator = &fixedStorerCreator{storer: apiStorer}
+	rq := client.NewGetRequest(peerID, key)
+
+	rp, err := l.Get(nil, rq)
+	assert.Nil(t, err)
+	assert.Equal(t, value, rp.Value)
+
+	// repair is sent to the lacking peer
+	select {
+	case storeRq := <-apiStorer.requests:
+		assert.False(t, storeRq.Cache)
+		assert.Equal(t, key.Bytes(), storeRq.Key)
+		assert.Equal(t, value, storeRq.Value)
+	case <-time.After(time.Second):
+		assert.Fail(t, "read repair not sent")
+	}
+}
+
 func TestLibrarian_Get_FoundClosestPeers(t *testing.T) {
 	rng := rand.New(rand.NewSource(int64(0)))
 	key, peerID := id.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng)
@@ -545,8 +810,11 @@ func newGetLibrarian(rng *rand.Rand, searchResult *search.Result, searchErr erro
 			result: searchResult,
 			err:    searchErr,
 		},
-		rqv:    &alwaysRequestVerifier{},
-		logger: clogging.NewDevInfoLogger(),
+		rqv:     &alwaysRequestVerifier{},
+		limiter: NewLimiter(NewDefaultLimitParameters()),
+		repairs: newRepairThrottle(NewDefaultReadRepairParameters()),
+		reps:    peer.NewDefaultReputations(),
+		logger:  clogging.NewDevInfoLogger(),
 	}
 }
 
@@ -668,8 +936,10 @@ func TestLibrarian_Subscribe_ok(t *testing.T) {
 			new:  newPubs,
 			done: done,
 		},
-		rqv:    &alwaysRequestVerifier{},
-		logger: clogging.NewDevInfoLogger(),
+		rqv:     &alwaysRequestVerifier{},
+		limiter: NewLimiter(NewDefaultLimitParameters()),
+		reps:    peer.NewDefaultReputations(),
+		logger:  clogging.NewDevInfoLogger(),
 	}
 
 	// create subscription that should cover both the author and reader filter code branches
@@ -740,10 +1010,13 @@ func TestLibrarian_Subscribe_err(t *testing.T) {
 	}
 
 	// check request error bubbles up
+	reps := peer.NewDefaultReputations()
 	l1 := &Librarian{
-		rqv:    &neverRequestVerifier{},
-		rt:     routing.NewEmpty(selfID.ID(), routing.NewDefaultParameters()),
-		logger: clogging.NewDevInfoLogger(),
+		rqv:     &neverRequestVerifier{},
+		limiter: NewLimiter(NewDefaultLimitParameters()),
+		reps:    reps,
+		rt:      routing.NewEmpty(selfID.ID(), routing.NewDefaultParameters(), reps),
+		logger:  clogging.NewDevInfoLogger(),
 	}
 	err = l1.Subscribe(rq, from)
 	assert.NotNil(t, err)
@@ -754,8 +1027,10 @@ func TestLibrarian_Subscribe_err(t *testing.T) {
 	sub2.AuthorPublicKeys.Encoded = nil // will trigger error
 	rq2 := client.NewSubscribeRequest(ecid.NewPseudoRandom(rng), sub2)
 	l2 := &Librarian{
-		rqv:    &alwaysRequestVerifier{},
-		logger: clogging.NewDevInfoLogger(),
+		rqv:     &alwaysRequestVerifier{},
+		limiter: NewLimiter(NewDefaultLimitParameters()),
+		reps:    peer.NewDefaultReputations(),
+		logger:  clogging.NewDevInfoLogger(),
 	}
 	err = l2.Subscribe(rq2, from)
 	assert.NotNil(t, err)
@@ -766,8 +1041,10 @@ func TestLibrarian_Subscribe_err(t *testing.T) {
 	sub3.ReaderPublicKeys.Encoded = nil // will trigger error
 	rq3 := client.NewSubscribeRequest(ecid.NewPseudoRandom(rng), sub3)
 	l3 := &Librarian{
-		rqv:    &alwaysRequestVerifier{},
-		logger: clogging.NewDevInfoLogger(),
+		rqv:     &alwaysRequestVerifier{},
+		limiter: NewLimiter(NewDefaultLimitParameters()),
+		reps:    peer.NewDefaultReputations(),
+		logger:  clogging.NewDevInfoLogger(),
 	}
 	err = l3.Subscribe(rq3, from)
 	assert.NotNil(t, err)
@@ -781,8 +1058,10 @@ func TestLibrarian_Subscribe_err(t *testing.T) {
 		subscribeFrom: &fixedFrom{
 			err: subscribe.ErrNotAcceptingNewSubscriptions,
 		},
-		rqv:    &alwaysRequestVerifier{},
-		logger: clogging.NewDevInfoLogger(),
+		rqv:     &alwaysRequestVerifier{},
+		limiter: NewLimiter(NewDefaultLimitParameters()),
+		reps:    peer.NewDefaultReputations(),
+		logger:  clogging.NewDevInfoLogger(),
 	}
 	err = l4.Subscribe(rq4, from)
 	assert.Equal(t, subscribe.ErrNotAcceptingNewSubscriptions, err)
@@ -798,8 +1077,10 @@ func TestLibrarian_Subscribe_err(t *testing.T) {
 			new:  newPubs,
 			done: make(chan struct{}),
 		},
-		rqv:    &alwaysRequestVerifier{},
-		logger: clogging.NewDevInfoLogger(),
+		rqv:     &alwaysRequestVerifier{},
+		limiter: NewLimiter(NewDefaultLimitParameters()),
+		reps:    peer.NewDefaultReputations(),
+		logger:  clogging.NewDevInfoLogger(),
 	}
 	from5 := &fixedLibrarianSubscribeServer{
 		err: errors.New("some Subscribe error"),
@@ -900,7 +1181,9 @@ func newPutLibrarian(rng *rand.Rand, storeResult *store.Result, searchErr error)
 			result: storeResult,
 			err:    searchErr,
 		},
-		rqv:    &alwaysRequestVerifier{},
-		logger: clogging.NewDevInfoLogger(),
+		rqv:     &alwaysRequestVerifier{},
+		limiter: NewLimiter(NewDefaultLimitParameters()),
+		reps:    peer.NewDefaultReputations(),
+		logger:  clogging.NewDevInfoLogger(),
 	}
 }
@@ -3,9 +3,11 @@ package server
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/drausin/libri/libri/common/ecid"
 	"github.com/drausin/libri/libri/common/storage"
+	"github.com/drausin/libri/libri/librarian/server/peer"
 	"github.com/drausin/libri/libri/librarian/server/routing"
 	"github.com/golang/protobuf/proto"
 	"go.uber.org/zap"
@@ -16,18 +18,36 @@ const (
 	// LoggerPeerID is a peer ID.
 	LoggerPeerID = "peerId"
 
+	// LoggerKeyType is an ID key type.
+	LoggerKeyType = "keyType"
+
+	// LoggerIDDifficulty is a peer ID puzzle difficulty.
+	LoggerIDDifficulty = "idDifficulty"
+
+	// LoggerNetworkIDDifficulty is the peer ID puzzle difficulty advertised by the network.
+	LoggerNetworkIDDifficulty = "networkIdDifficulty"
+
 	// NumPeers is a number of peers.
 	NumPeers = "numPeers"
 
 	// NumBuckets is a number of routing table buckets.
 	NumBuckets = "numBuckets"
+
+	// NumChanges is a number of routing table changes.
+	NumChanges = "numChanges"
 )
 
+// checkpointCheckPeriod is the maximum time between checks for whether the routing table should
+// be checkpointed.
+const checkpointCheckPeriod = 10 * time.Second
+
 var (
 	peerIDKey = []byte("PeerID")
 )
 
-func loadOrCreatePeerID(logger *zap.Logger, nsl storage.NamespaceSL) (ecid.ID, error) {
+func loadOrCreatePeerID(
+	logger *zap.Logger, nsl storage.NamespaceSL, keyType ecid.KeyType, idDifficulty uint,
+) (ecid.Identity, error) {
 	bytes, err := nsl.Load(peerIDKey)
 	if err != nil {
 		logger.Error("error loading peer ID", zap.Error(err))
@@ -45,17 +65,28 @@ func loadOrCreatePeerID(logger *zap.Logger, nsl storage.NamespaceSL) (ecid.ID, e
 			logger.Error("error deserializing peer ID keys", zap.Error(err))
 			return nil, err
 		}
+		if !ecid.SolvesIDPuzzle(peerID.ID(), idDifficulty) {
+			logger.Error("loaded peer ID does not solve ID puzzle",
+				zap.String(LoggerPeerID, peerID.String()),
+				zap.Uint(LoggerIDDifficulty, idDifficulty),
+			)
+			return nil, errUnsolvedIDPuzzle
+		}
 		logger.Info("loaded exsting peer ID", zap.String(LoggerPeerID, peerID.String()))
 		return peerID, nil
 	}
 
-	// return new PeerID
-	peerID := ecid.NewRandom()
-	logger.Info("created new peer ID", zap.String(LoggerPeerID, peerID.String()))
+	// return new PeerID, generating keys until its ID solves the puzzle
+	peerID, err := ecid.NewRandomPuzzleIdentity(keyType, idDifficulty)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("created new peer ID", zap.String(LoggerPeerID, peerID.String()),
+		zap.Stringer(LoggerKeyType, keyType), zap.Uint(LoggerIDDifficulty, idDifficulty))
 	return peerID, savePeerID(nsl, peerID)
 }
 
-func savePeerID(ns storage.NamespaceStorer, peerID ecid.ID) error {
+func savePeerID(ns storage.NamespaceStorer, peerID ecid.Identity) error {
 	bytes, err := proto.Marshal(ecid.ToStored(peerID))
 	if err != nil {
 		return err
@@ -63,9 +94,9 @@ func savePeerID(ns storage.NamespaceStorer, peerID ecid.ID) error {
 	return ns.Store(peerIDKey, bytes)
 }
 
-func loadOrCreateRoutingTable(logger *zap.Logger, nl storage.NamespaceLoader, selfID ecid.ID,
-	params *routing.Parameters) (routing.Table, error) {
-	rt, err := routing.Load(nl, params)
+func loadOrCreateRoutingTable(logger *zap.Logger, nl storage.NamespaceLoader, selfID ecid.Identity,
+	params *routing.Parameters, reps peer.Reputations) (routing.Table, error) {
+	rt, err := routing.Load(nl, params, reps)
 	if err != nil {
 		logger.Error("error loading routing table", zap.Error(err))
 		return nil, err
@@ -87,5 +118,49 @@ func loadOrCreateRoutingTable(logger *zap.Logger, nl storage.NamespaceLoader, se
 	}
 
 	defer logger.Info("created new routing table")
-	return routing.NewEmpty(selfID.ID(), params), nil
+	return routing.NewEmpty(selfID.ID(), params, reps), nil
+}
+
+// checkpointPeriodically saves the routing table whenever it has changed enough since its last
+// save or has changed at all within the checkpoint interval, until the librarian is stopped.
+func (l *Librarian) checkpointPeriodically() {
+	period := checkpointCheckPeriod
+	if l.config.Routing.CheckpointInterval < period {
+		period = l.config.Routing.CheckpointInterval
+	}
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	lastSaved := time.Now()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			if l.maybeCheckpoint(lastSaved) {
+				lastSaved = time.Now()
+			}
+		}
+	}
+}
+
+// maybeCheckpoint saves the routing table if it has at least the checkpoint number of changes or
+// has any changes and was last saved more than the checkpoint interval ago, returning whether it
+// was saved.
+func (l *Librarian) maybeCheckpoint(lastSaved time.Time) bool {
+	nChanges := l.rt.NumChanges()
+	params := l.config.Routing
+	if nChanges == 0 ||
+		(nChanges < params.CheckpointChanges && time.Since(lastSaved) < params.CheckpointInterval) {
+		return false
+	}
+	if err := l.rt.Save(l.serverSL); err != nil {
+		l.logger.Error("error checkpointing routing table", zap.Error(err))
+		return false
+	}
+	l.logger.Debug("checkpointed routing table",
+		zap.Uint(NumChanges, nChanges),
+		zap.Int(NumPeers, l.rt.NumPeers()),
+		zap.Int(NumBuckets, l.rt.NumBuckets()),
+	)
+	return true
 }
@@ -3,12 +3,14 @@ package server
 import (
 	"math/rand"
 	"testing"
+	"time"
 
 	"errors"
 
 	"github.com/drausin/libri/libri/common/ecid"
 	clogging "github.com/drausin/libri/libri/common/logging"
 	"github.com/drausin/libri/libri/common/storage"
+	"github.com/drausin/libri/libri/librarian/server/peer"
 	"github.com/drausin/libri/libri/librarian/server/routing"
 	"github.com/golang/protobuf/proto"
 	"github.com/stretchr/testify/assert"
@@ -17,34 +19,66 @@ import (
 func TestLoadOrCreatePeerID_ok(t *testing.T) {
 
 	// create new peer ID
-	id1, err := loadOrCreatePeerID(clogging.NewDevInfoLogger(), &fixedStorerLoader{})
+	id1, err := loadOrCreatePeerID(clogging.NewDevInfoLogger(), &fixedStorerLoader{},
+		ecid.KeyTypeSecp256k1, 0)
 	assert.NotNil(t, id1)
 	assert.Nil(t, err)
 
+	// create new peer ID with another key type
+	id3, err := loadOrCreatePeerID(clogging.NewDevInfoLogger(), &fixedStorerLoader{},
+		ecid.KeyTypeEd25519, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, ecid.KeyTypeEd25519, id3.KeyType())
+
 	// load existing
 	rng := rand.New(rand.NewSource(0))
 	peerID2 := ecid.NewPseudoRandom(rng)
 	bytes, err := proto.Marshal(ecid.ToStored(peerID2))
 	assert.Nil(t, err)
 
-	id2, err := loadOrCreatePeerID(clogging.NewDevInfoLogger(), &fixedStorerLoader{loadBytes: bytes})
+	id2, err := loadOrCreatePeerID(clogging.NewDevInfoLogger(),
+		&fixedStorerLoader{loadBytes: bytes}, ecid.KeyTypeSecp256k1, 0)
 
 	assert.Equal(t, peerID2, id2)
 	assert.Nil(t, err)
+
+	// create new peer ID solving ID puzzle
+	id4, err := loadOrCreatePeerID(clogging.NewDevInfoLogger(), &fixedStorerLoader{},
+		ecid.KeyTypeSecp256k1, 4)
+	assert.Nil(t, err)
+	assert.True(t, ecid.SolvesIDPuzzle(id4.ID(), 4))
 }
 
 func TestLoadOrCreatePeerID_err(t *testing.T) {
 	id1, err := loadOrCreatePeerID(clogging.NewDevInfoLogger(), &fixedStorerLoader{
 		loadErr: errors.New("some load error"),
-	})
+	}, ecid.KeyTypeSecp256k1, 0)
 	assert.Nil(t, id1)
 	assert.NotNil(t, err)
 
 	id2, err := loadOrCreatePeerID(clogging.NewDevInfoLogger(), &fixedStorerLoader{
 		loadBytes: []byte("the wrong bytes"),
-	})
+	}, ecid.KeyTypeSecp256k1, 0)
 	assert.Nil(t, id2)
 	assert.NotNil(t, err)
+
+	id3, err := loadOrCreatePeerID(clogging.NewDevInfoLogger(), &fixedStorerLoader{},
+		ecid.KeyType(-1), 0)
+	assert.Equal(t, ecid.ErrUnknownKeyType, err)
+	assert.Nil(t, id3)
+
+	// loaded peer ID doesn't solve ID puzzle
+	rng := rand.New(rand.NewSource(0))
+	peerID4 := ecid.NewPseudoRandom(rng)
+	for ecid.SolvesIDPuzzle(peerID4.ID(), 1) {
+		peerID4 = ecid.NewPseudoRandom(rng)
+	}
+	bytes, err := proto.Marshal(ecid.ToStored(peerID4))
+	assert.Nil(t, err)
+	id4, err := loadOrCreatePeerID(clogging.NewDevInfoLogger(),
+		&fixedStorerLoader{loadBytes: bytes}, ecid.KeyTypeSecp256k1, 1)
+	assert.Equal(t, errUnsolvedIDPuzzle, err)
+	assert.Nil(t, id4)
 }
 
 func TestSavePeerID(t *testing.T) {
@@ -67,14 +101,14 @@ func TestLoadOrCreateRoutingTable_ok(t *testing.T) {
 		loadBytes: bytes,
 	}
 	rt1, err := loadOrCreateRoutingTable(clogging.NewDevInfoLogger(), fullLoader, selfID1,
-		routing.NewDefaultParameters())
+		routing.NewDefaultParameters(), peer.NewDefaultReputations())
 	assert.Equal(t, selfID1.ID(), rt1.SelfID())
 	assert.Nil(t, err)
 
 	// create new RT
 	selfID2 := ecid.NewPseudoRandom(rng)
 	rt2, err := loadOrCreateRoutingTable(clogging.NewDevInfoLogger(), &fixedStorerLoader{}, selfID2,
-		routing.NewDefaultParameters())
+		routing.NewDefaultParameters(), peer.NewDefaultReputations())
 	assert.Equal(t, selfID2.ID(), rt2.SelfID())
 	assert.Nil(t, err)
 }
@@ -88,7 +122,7 @@ func TestLoadOrCreateRoutingTable_loadErr(t *testing.T) {
 	}
 
 	rt1, err := loadOrCreateRoutingTable(clogging.NewDevInfoLogger(), errLoader, selfID,
-		routing.NewDefaultParameters())
+		routing.NewDefaultParameters(), peer.NewDefaultReputations())
 	assert.Nil(t, rt1)
 	assert.NotNil(t, err)
 }
@@ -110,11 +144,70 @@ func TestLoadOrCreateRoutingTable_selfIDErr(t *testing.T) {
 	// error with conflicting/different selfID
 	selfID2 := ecid.NewPseudoRandom(rng)
 	rt1, err := loadOrCreateRoutingTable(clogging.NewDevInfoLogger(), fullLoader, selfID2,
-		routing.NewDefaultParameters())
+		routing.NewDefaultParameters(), peer.NewDefaultReputations())
 	assert.Nil(t, rt1)
 	assert.NotNil(t, err)
 }
 
+func TestLibrarian_maybeCheckpoint(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	rt, _, nAdded := routing.NewTestWithPeers(rng, 8)
+	l := &Librarian{
+		config:   NewDefaultConfig(),
+		rt:       rt,
+		serverSL: &fixedStorerLoader{},
+		logger:   clogging.NewDevInfoLogger(),
+	}
+	l.config.Routing.CheckpointChanges = uint(nAdded + 1)
+
+	// too few changes since a recent save
+	assert.False(t, l.maybeCheckpoint(time.Now()))
+	assert.Equal(t, uint(nAdded), rt.NumChanges())
+
+	// enough changes
+	l.config.Routing.CheckpointChanges = uint(nAdded)
+	assert.True(t, l.maybeCheckpoint(time.Now()))
+	assert.Zero(t, rt.NumChanges())
+
+	// no changes since last save
+	assert.False(t, l.maybeCheckpoint(time.Now().Add(-time.Hour)))
+
+	// few changes but last save was long ago
+	rt.Push(peer.NewTestPeer(rng, nAdded))
+	assert.False(t, l.maybeCheckpoint(time.Now()))
+	assert.True(t, l.maybeCheckpoint(time.Now().Add(-l.config.Routing.CheckpointInterval)))
+
+	// save error
+	rt.Push(peer.NewTestPeer(rng, nAdded+1))
+	l.serverSL = &fixedStorerLoader{storeErr: errors.New("some store error")}
+	assert.False(t, l.maybeCheckpoint(time.Now().Add(-l.config.Routing.CheckpointInterval)))
+	assert.Equal(t, uint(1), rt.NumChanges())
+}
+
+func TestLibrarian_checkpointPeriodically(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	rt, _, _ := routing.NewTestWithPeers(rng, 8)
+	l := &Librarian{
+		config:   NewDefaultConfig(),
+		rt:       rt,
+		serverSL: &fixedStorerLoader{},
+		logger:   clogging.NewDevInfoLogger(),
+		stop:     make(chan struct{}),
+	}
+	l.config.Routing.CheckpointInterval = 10 * time.Millisecond
+
+	done := make(chan struct{})
+	go func() {
+		l.checkpointPeriodically()
+		close(done)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	close(l.stop)
+	<-done
+
+	assert.Zero(t, rt.NumChanges())
+}
+
 type fixedStorerLoader struct {
 	loadBytes []byte
 	loadErr   error
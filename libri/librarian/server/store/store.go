@@ -159,7 +159,7 @@ type Store struct {
 
 // NewStore creates a new Store instance for a given target, search type, and search parameters.
 func NewStore(
-	peerID ecid.ID,
+	peerID ecid.Identity,
 	key id.ID,
 	value *api.Document,
 	searchParams *search.Parameters,
@@ -199,9 +199,12 @@ func (s *Store) Stored() bool {
 	return uint(len(s.Result.Responded)) >= s.Params.NReplicas
 }
 
-// Exists returns whether the value already exists (and the search has found it).
+// Exists returns whether the value already exists (and the search has found it). A found Pointer
+// with a smaller sequence number than the one being stored does not count as existing, since it
+// should be replaced.
 func (s *Store) Exists() bool {
-	return s.Result.Search.Value != nil
+	return s.Result.Search.Value != nil &&
+		!api.IsNewerPointer(s.Request.Value, s.Result.Search.Value)
 }
 
 // Errored returns whether the store has encountered too many errors when querying the peers.
@@ -87,6 +87,37 @@ func TestStore_Stored(t *testing.T) {
 	assert.True(t, store.Finished())
 }
 
+func TestStore_Exists(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID := ecid.NewPseudoRandom(rng)
+	value, key := api.NewTestDocument(rng)
+	store := NewStore(peerID, key, value, &ssearch.Parameters{}, NewDefaultParameters())
+	store.Result = NewInitialResult(store.Search.Result)
+
+	// value not found yet
+	assert.False(t, store.Exists())
+
+	// value found by search
+	store.Result.Search.Value = value
+	assert.True(t, store.Exists())
+
+	// older pointer found by search doesn't count as existing
+	oldPointer, newPointer := api.NewTestPointer(rng), api.NewTestPointer(rng)
+	newPointer.Sequence = oldPointer.Sequence + 1
+	store = NewStore(peerID, key, &api.Document{
+		Contents: &api.Document_Pointer{Pointer: newPointer},
+	}, &ssearch.Parameters{}, NewDefaultParameters())
+	store.Result = NewInitialResult(store.Search.Result)
+	store.Result.Search.Value = &api.Document{
+		Contents: &api.Document_Pointer{Pointer: oldPointer},
+	}
+	assert.False(t, store.Exists())
+
+	// same or newer pointer found by search counts as existing
+	store.Result.Search.Value = store.Request.Value
+	assert.True(t, store.Exists())
+}
+
 func TestStore_Errored(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	peerID, key := ecid.NewPseudoRandom(rng), id.NewPseudoRandom(rng)
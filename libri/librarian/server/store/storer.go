@@ -41,8 +41,8 @@ func NewStorer(signer client.Signer, searcher search.Searcher, c client.StorerCr
 }
 
 // NewDefaultStorer creates a new Storer with default Searcher and StoreQuerier instances.
-func NewDefaultStorer(peerID ecid.ID) Storer {
-	signer := client.NewSigner(peerID.Key())
+func NewDefaultStorer(peerID ecid.Identity) Storer {
+	signer := client.NewSigner(peerID.Signer())
 	return NewStorer(
 		signer,
 		search.NewDefaultSearcher(signer),
@@ -86,15 +86,22 @@ func (s *storer) storeWork(store *Store, wg *sync.WaitGroup) {
 		store.mu.Unlock()
 
 		// do the query
-		if _, err := s.query(next.Connector(), store); err != nil {
+		start := time.Now()
+		_, err := s.query(next.Connector(), store)
+		latency := time.Since(start)
+		if err != nil {
 			// if we had an issue querying, skip to next peer
 			store.wrapLock(func() {
 				store.Result.Errors = append(store.Result.Errors, err)
 				next.Recorder().Record(peer.Response, peer.Error)
+				next.Recorder().RecordLatency(latency)
 			})
 			continue
 		}
-		store.wrapLock(func() { next.Recorder().Record(peer.Response, peer.Success) })
+		store.wrapLock(func() {
+			next.Recorder().Record(peer.Response, peer.Success)
+			next.Recorder().RecordLatency(latency)
+		})
 
 		// add to slice of responded peers
 		store.wrapLock(func() {
@@ -113,7 +120,7 @@ func (s *storer) query(pConn peer.Connector, store *Store) (*api.StoreResponse,
 	if err != nil {
 		return nil, err
 	}
-	retryStoreClient := client.NewRetryStorer(storeClient, storerStoreRetryTimeout)
+	retryStoreClient := client.NewRetryStorer(storeClient, s.signer, storerStoreRetryTimeout)
 	rp, err := retryStoreClient.Store(ctx, store.Request)
 	cancel()
 	if err != nil {